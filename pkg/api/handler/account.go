@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountUseCase services.AccountUseCase
+	subHandler     helper.SubHandler
+}
+
+func NewAccountHandler(useCase services.AccountUseCase) *AccountHandler {
+	return &AccountHandler{
+		accountUseCase: useCase,
+	}
+}
+
+// ExportData godoc
+//
+//	@Summary		Export my data
+//	@Description	Download the profile, addresses, orders, wallet history, ratings and wishlist of the user. The format is json by default, zip returns an archive with one json file per section.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Produce		json
+//	@Produce		application/zip
+//	@Param			format	query		string	false	"json or zip"
+//	@Success		200		{object}	response.Response{data=response.AccountExport}
+//	@Failure		400		{object}	response.Response	"Failed, format must be json or zip"
+//	@Failure		500		{object}	response.Response	"Failed to export data"
+//	@Router			/profile/export [get]
+func (ah *AccountHandler) ExportData(c *gin.Context) {
+	userID, _ := helper.GetIDFromContext(c)
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		export, err := ah.accountUseCase.ExportData(c.Request.Context(), userID)
+		if err != nil {
+			response := response.ResponseMessage(statusInternalServerError, "Failed to export data", nil, err.Error())
+			c.JSON(statusInternalServerError, response)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="device-mart-export-%d.json"`, userID))
+		response := response.ResponseMessage(statusOK, "Success", export, nil)
+		c.JSON(statusOK, response)
+
+	case "zip":
+		var archive bytes.Buffer
+		if err := ah.accountUseCase.WriteExportArchive(c.Request.Context(), userID, &archive); err != nil {
+			response := response.ResponseMessage(statusInternalServerError, "Failed to export data", nil, err.Error())
+			c.JSON(statusInternalServerError, response)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="device-mart-export-%d-%s.zip"`, userID, time.Now().Format("20060102")))
+		c.Data(statusOK, "application/zip", archive.Bytes())
+
+	default:
+		response := response.ResponseMessage(statusBadRequest, "Failed, format must be json or zip", nil, nil)
+		c.JSON(statusBadRequest, response)
+	}
+}
+
+// RequestDeletion godoc
+//
+//	@Summary		Delete my account
+//	@Description	Schedule the account for deletion after the grace period, it can be cancelled until then. The account is anonymised, the orders are kept without the personal details.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.DeleteAccount	true	"Current password and an optional reason"
+//	@Success		202		{object}	response.Response{data=response.AccountDeletion}
+//	@Failure		400		{object}	response.Response	"Failed to bind request"
+//	@Failure		401		{object}	response.Response	"Failed, password is not matching"
+//	@Failure		409		{object}	response.Response	"Failed, account deletion is already scheduled"
+//	@Failure		409		{object}	response.Response	"Failed, account has open orders"
+//	@Failure		500		{object}	response.Response	"Failed to schedule account deletion"
+//	@Router			/profile/delete-account [post]
+func (ah *AccountHandler) RequestDeletion(c *gin.Context) {
+	var body request.DeleteAccount
+	if !ah.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+	deletion, err := ah.accountUseCase.RequestDeletion(c.Request.Context(), userID, body)
+	if err == usecase.ErrPasswordMismatch {
+		response := response.ResponseMessage(statusUnauthorized, "Failed, password is not matching", nil, err.Error())
+		c.JSON(statusUnauthorized, response)
+		return
+	}
+	if err == usecase.ErrDeletionPending {
+		response := response.ResponseMessage(statusConflict, "Failed, account deletion is already scheduled", nil, err.Error())
+		c.JSON(statusConflict, response)
+		return
+	}
+	if err == usecase.ErrOpenOrders {
+		response := response.ResponseMessage(statusConflict, "Failed, account has open orders", nil, err.Error())
+		c.JSON(statusConflict, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to schedule account deletion", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusAccepted, "Success, account deletion scheduled", deletion, nil)
+	c.JSON(statusAccepted, response)
+}
+
+// GetDeletion godoc
+//
+//	@Summary		Account deletion status
+//	@Description	Get the latest account deletion request of the user.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.AccountDeletion}
+//	@Failure		400	{object}	response.Response	"Failed, no account deletion requested"
+//	@Failure		500	{object}	response.Response	"Failed to get account deletion"
+//	@Router			/profile/delete-account [get]
+func (ah *AccountHandler) GetDeletion(c *gin.Context) {
+	userID, _ := helper.GetIDFromContext(c)
+
+	deletion, err := ah.accountUseCase.GetDeletion(c.Request.Context(), userID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no account deletion requested", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get account deletion", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", deletion, nil)
+	c.JSON(statusOK, response)
+}
+
+// CancelDeletion godoc
+//
+//	@Summary		Cancel account deletion
+//	@Description	Cancel the scheduled deletion of the account.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.AccountDeletion}
+//	@Failure		400	{object}	response.Response	"Failed, no account deletion is scheduled"
+//	@Failure		500	{object}	response.Response	"Failed to cancel account deletion"
+//	@Router			/profile/delete-account/cancel [post]
+func (ah *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, _ := helper.GetIDFromContext(c)
+
+	deletion, err := ah.accountUseCase.CancelDeletion(c.Request.Context(), userID)
+	if err == usecase.ErrNoPendingDeletion {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no account deletion is scheduled", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to cancel account deletion", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, account deletion cancelled", deletion, nil)
+	c.JSON(statusOK, response)
+}
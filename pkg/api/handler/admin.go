@@ -2,9 +2,12 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"github.com/gin-gonic/gin"
 )
@@ -22,18 +25,47 @@ func NewAdminHandler(useCase services.AdminUseCase) *AdminHandler {
 // ListUsers	godoc
 //
 //	@Summary		View all users
-//	@Description	List of all users
+//	@Description	List the users, the latest sign up first. Search matches part of the name, email or phone.
 //	@Tags			admin user management
 //	@Security		Bearer
-//	@Param			page	query	int	true	"Page number"				default(1)
-//	@Param			count	query	int	true	"Number of items per page"	default(10)
+//	@Param			page		query	int		true	"Page number"				default(1)
+//	@Param			count		query	int		true	"Number of items per page"	default(10)
+//	@Param			search		query	string	false	"Part of the name, email or phone"
+//	@Param			blocked		query	bool	false	"Only the blocked or the active users"
+//	@Param			has_orders	query	bool	false	"Only the users with or without orders"
+//	@Param			from		query	string	false	"Signed up on or after, YYYY-MM-DD"
+//	@Param			to			query	string	false	"Signed up on or before, YYYY-MM-DD"
 //	@Produce		json
 //	@Success		200										{object}	response.Response{data=[]response.UserData}	"Success"
+//	@Failure		400										{object}	response.Response							"Failed to retrieve page info"
+//	@Failure		400										{object}	response.Response							"Failed, dates must be YYYY-MM-DD"
+//	@Failure		400										{object}	response.Response							"Failed, blocked and has_orders must be true or false"
 //	@Failure		500										{object}	response.Response							"Failed to fetch users"
 //	@Router			/admin/user-management/view-all-users	[get]
 func (ah *AdminHandler) DisplayAllUsers(c *gin.Context) {
+	page, count, ok := ah.subHandler.GetPageNCount(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
 
-	ListOfUsersData, err := ah.adminUseCase.GetAllUserData()
+	filter := request.UserFilter{
+		Search: c.Query("search"),
+		From:   from,
+		To:     to,
+	}
+	if filter.Blocked, ok = queryBool(c, "blocked"); !ok {
+		return
+	}
+	if filter.HasOrders, ok = queryBool(c, "has_orders"); !ok {
+		return
+	}
+
+	ListOfUsersData, err := ah.adminUseCase.GetAllUserData(c.Request.Context(), filter, page, count)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to fetch users", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -63,7 +95,7 @@ func (ah *AdminHandler) BlockUser(c *gin.Context) {
 		return
 	}
 
-	err := ah.adminUseCase.BlockUserByID(userID)
+	err := ah.adminUseCase.BlockUserByID(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to block user", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -92,7 +124,7 @@ func (ah *AdminHandler) UnblockUser(c *gin.Context) {
 		return
 	}
 
-	err := ah.adminUseCase.UnBlockUserByID(userID)
+	err := ah.adminUseCase.UnBlockUserByID(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to unblock user", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -102,3 +134,191 @@ func (ah *AdminHandler) UnblockUser(c *gin.Context) {
 	response := response.ResponseMessage(200, "Success, user has been unblocked", nil, nil)
 	c.JSON(http.StatusOK, response)
 }
+
+// queryBool reads an optional true or false query param, nil when it is not set.
+func queryBool(c *gin.Context, name string) (*bool, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		response := response.ResponseMessage(statusBadRequest, "Failed, blocked and has_orders must be true or false", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// FindUsersByName godoc
+//
+//	@Summary		Find users by name
+//	@Description	Users whose name contains the given text, ignoring the case.
+//	@Tags			admin user management
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			name	query		string	true	"Part of the name"
+//	@Success		200		{object}	response.Response{data=[]response.UserData}
+//	@Failure		400		{object}	response.Response	"Failed, name is required"
+//	@Failure		500		{object}	response.Response	"Failed to find users"
+//	@Router			/admin/user-management/find-users [get]
+func (ah *AdminHandler) FindUsersByName(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		response := response.ResponseMessage(statusBadRequest, "Failed, name is required", nil, nil)
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	users, err := ah.adminUseCase.FindUsersByName(c.Request.Context(), name)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to find users", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", users, nil)
+	c.JSON(statusOK, response)
+}
+
+// GetCustomer godoc
+//
+//	@Summary		Customer details
+//	@Description	The user with the addresses, orders, wallet balance and history, referrals, ratings, admin notes and account deletion.
+//	@Tags			admin user management
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			userID	path		int	true	"User ID"
+//	@Success		200		{object}	response.Response{data=response.Customer}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		400		{object}	response.Response	"Failed, no user with this id"
+//	@Failure		500		{object}	response.Response	"Failed to get customer"
+//	@Router			/admin/user-management/users/{userID} [get]
+func (ah *AdminHandler) GetCustomer(c *gin.Context) {
+	userID, ok := ah.subHandler.ParamInt(c, "userID")
+	if !ok {
+		return
+	}
+
+	customer, err := ah.adminUseCase.GetCustomer(c.Request.Context(), userID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no user with this id", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get customer", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", customer, nil)
+	c.JSON(statusOK, response)
+}
+
+// AddNote godoc
+//
+//	@Summary		Add admin note
+//	@Description	Add a note on the account of the user, only the admin sees it.
+//	@Tags			admin user management
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int					true	"User ID"
+//	@Param			body	body		request.AdminNote	true	"Note"
+//	@Success		201		{object}	response.Response{data=response.AdminNote}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		400		{object}	response.Response	"Failed, no user with this id"
+//	@Failure		500		{object}	response.Response	"Failed to add note"
+//	@Router			/admin/user-management/users/{userID}/notes [post]
+func (ah *AdminHandler) AddNote(c *gin.Context) {
+	userID, ok := ah.subHandler.ParamInt(c, "userID")
+	if !ok {
+		return
+	}
+
+	var body request.AdminNote
+	if !ah.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	note, err := ah.adminUseCase.AddNote(c.Request.Context(), userID, body.Note)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no user with this id", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to add note", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusCreated, "Success, note added", note, nil)
+	c.JSON(statusCreated, response)
+}
+
+// GetNotes godoc
+//
+//	@Summary		Admin notes
+//	@Description	The admin notes on the account of the user, the latest first.
+//	@Tags			admin user management
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			userID	path		int	true	"User ID"
+//	@Success		200		{object}	response.Response{data=[]response.AdminNote}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		500		{object}	response.Response	"Failed to get notes"
+//	@Router			/admin/user-management/users/{userID}/notes [get]
+func (ah *AdminHandler) GetNotes(c *gin.Context) {
+	userID, ok := ah.subHandler.ParamInt(c, "userID")
+	if !ok {
+		return
+	}
+
+	notes, err := ah.adminUseCase.GetNotes(c.Request.Context(), userID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get notes", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", notes, nil)
+	c.JSON(statusOK, response)
+}
+
+// DeleteNote godoc
+//
+//	@Summary		Delete admin note
+//	@Description	Delete an admin note.
+//	@Tags			admin user management
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			noteID	path		int					true	"Note ID"
+//	@Success		200		{object}	response.Response	"Success, note deleted"
+//	@Failure		400		{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		400		{object}	response.Response	"Failed, no note with this id"
+//	@Failure		500		{object}	response.Response	"Failed to delete note"
+//	@Router			/admin/user-management/notes/{noteID} [delete]
+func (ah *AdminHandler) DeleteNote(c *gin.Context) {
+	noteID, ok := ah.subHandler.ParamInt(c, "noteID")
+	if !ok {
+		return
+	}
+
+	err := ah.adminUseCase.DeleteNote(c.Request.Context(), noteID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no note with this id", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to delete note", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, note deleted", nil, nil)
+	c.JSON(statusOK, response)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditUseCase services.AuditUseCase
+	subHandler   helper.SubHandler
+}
+
+func NewAuditHandler(useCase services.AuditUseCase) *AuditHandler {
+	return &AuditHandler{
+		auditUseCase: useCase,
+	}
+}
+
+// auditFilter reads the audit log filter from the query.
+func auditFilter(c *gin.Context) (request.AuditLogFilter, bool) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return request.AuditLogFilter{}, false
+	}
+
+	return request.AuditLogFilter{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		From:       from,
+		To:         to,
+	}, true
+}
+
+// ListAuditLogs godoc
+//
+//	@Summary		Audit log
+//	@Description	The mutating admin requests with the state of the changed entity before and after, the latest first.
+//	@Tags			admin audit
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			page		query		int		true	"Page number"
+//	@Param			count		query		int		true	"Count of items per page"
+//	@Param			actor		query		string	false	"Admin that made the request, e.g. admin:username"
+//	@Param			action		query		string	false	"Part of the method and route, e.g. block-product"
+//	@Param			entity_type	query		string	false	"user, product, category, coupon, order, promotion, scheduled_price, loyalty_rule, login_lockout, admin_note or domain_event"
+//	@Param			entity_id	query		string	false	"Entity ID"
+//	@Param			from		query		string	false	"Start date, YYYY-MM-DD"
+//	@Param			to			query		string	false	"End date inclusive, YYYY-MM-DD"
+//	@Success		200			{object}	response.Response{data=[]response.AuditLog}
+//	@Failure		400			{object}	response.Response	"Failed to retrieve page info"
+//	@Failure		400			{object}	response.Response	"Failed, dates must be YYYY-MM-DD"
+//	@Failure		500			{object}	response.Response	"Failed to get audit logs"
+//	@Router			/admin/audit-logs [get]
+func (ah *AuditHandler) ListAuditLogs(c *gin.Context) {
+	page, count, ok := ah.subHandler.GetPageNCount(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	logs, err := ah.auditUseCase.GetAuditLogs(c.Request.Context(), filter, page, count)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get audit logs", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", logs, nil)
+	c.JSON(statusOK, response)
+}
+
+// ExportAuditLogs godoc
+//
+//	@Summary		Export audit log
+//	@Description	Download every audit log matching the filter as csv, the latest first.
+//	@Tags			admin audit
+//	@Security		Bearer
+//	@Produce		text/csv
+//	@Param			actor		query		string	false	"Admin that made the request, e.g. admin:username"
+//	@Param			action		query		string	false	"Part of the method and route, e.g. block-product"
+//	@Param			entity_type	query		string	false	"Entity type"
+//	@Param			entity_id	query		string	false	"Entity ID"
+//	@Param			from		query		string	false	"Start date, YYYY-MM-DD"
+//	@Param			to			query		string	false	"End date inclusive, YYYY-MM-DD"
+//	@Success		200			{file}		file
+//	@Failure		400			{object}	response.Response	"Failed, dates must be YYYY-MM-DD"
+//	@Router			/admin/audit-logs/export [get]
+func (ah *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-log-%s.csv"`, time.Now().Format("20060102")))
+	c.Status(statusOK)
+	clearWriteDeadline(c)
+
+	// the csv is streamed, an error after the first rows can only end the download
+	if err := ah.auditUseCase.WriteAuditCSV(c.Request.Context(), filter, c.Writer); err != nil {
+		helper.LoggerFrom(c.Request.Context()).Error("audit log export ended", "error", err)
+		c.Abort()
+	}
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/api/middleware"
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
 	request "github.com/anazibinurasheed/project-device-mart/pkg/util/request"
@@ -16,31 +18,85 @@ import (
 
 type AuthHandler struct {
 	authUseCase services.AuthUseCase
+	cartUseCase services.CartUseCase
+	oidcUseCase services.OIDCUseCase
 	token       middleware.TokenManager
 	subHandler  helper.SubHandler
 }
 
-func NewAuthHandler(useCase services.AuthUseCase) *AuthHandler {
+func NewAuthHandler(useCase services.AuthUseCase, cartUseCase services.CartUseCase, oidcUseCase services.OIDCUseCase) *AuthHandler {
 	return &AuthHandler{
 		authUseCase: useCase,
+		cartUseCase: cartUseCase,
+		oidcUseCase: oidcUseCase,
 	}
 }
 
+// mergeGuestCart moves the guest cart of the request's cart token into the user's
+// cart. A failed merge is logged and never fails the login or sign up.
+func (a *AuthHandler) mergeGuestCart(c *gin.Context, userID int) {
+	cartToken := c.Request.Header.Get(middleware.CartTokenHeader)
+	if cartToken == "" {
+		return
+	}
+
+	cartID, err := helper.ParseCartToken(cartToken)
+	if err != nil {
+		helper.LoggerFrom(c.Request.Context()).Warn("guest cart not merged", "error", err)
+		return
+	}
+
+	err = a.cartUseCase.MergeGuestCart(c.Request.Context(), userID, cartID)
+	if err != nil {
+		helper.LoggerFrom(c.Request.Context()).Warn("guest cart not merged", "error", err)
+	}
+}
+
+// loginLocked responds with the wait time when the login is refused because of the
+// previous failed logins.
+func loginLocked(c *gin.Context, err error) bool {
+	lockedErr, ok := err.(*usecase.LoginLockedError)
+	if !ok {
+		return false
+	}
+
+	c.Header("Retry-After", fmt.Sprint(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+	response := response.ResponseMessage(statusTooManyRequests, "Failed, too many failed logins", nil, err.Error())
+	c.JSON(statusTooManyRequests, response)
+	return true
+}
+
+// passwordRefused responds with the reason when the new password is refused by the
+// password policy.
+func passwordRefused(c *gin.Context, err error) bool {
+	policyErr, ok := err.(*usecase.PasswordPolicyError)
+	if !ok {
+		return false
+	}
+
+	response := response.ResponseMessage(statusBadRequest, "Failed, password does not meet the password policy", nil, policyErr.Reason)
+	c.JSON(statusBadRequest, response)
+	return true
+}
+
 var contact = helper.NewPhone()
 
+const otpExpiry = 3 * time.Minute
+
 // AdminLogin godoc.
 //
 //	@Summary		Admin Login
-//	@Description	Admin can login using username and password.
+//	@Description	Admin can login using username and password. The admin must use two factor authentication, the response is a challenge for /admin/login/2fa. On the first login the challenge carries the enrolment, add the provisioning uri to an authenticator app and send its code.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
-//	@Param			body	body		request.AdminLogin						true	"Admin login credentials"
-//	@Success		200		{object}	response.Response{data=response.Token}	"Login success"
-//	@Failure		400		{object}	response.Response						"Failed to bind JSON inputs from request"
-//	@Failure		400		{object}	response.Response						"Failed, input does not meet validation criteria"
-//	@Failure		401		{object}	response.Response						"Invalid credentials"
-//	@Failure		500		{object}	response.Response						"Failed to generate token"
+//	@Param			body	body		request.AdminLogin										true	"Admin login credentials"
+//	@Success		202		{object}	response.Response{data=response.TwoFactorChallenge}	"Password accepted, two factor code required"
+//	@Failure		400		{object}	response.Response										"Failed to bind JSON inputs from request"
+//	@Failure		400		{object}	response.Response										"Failed, input does not meet validation criteria"
+//	@Failure		401		{object}	response.Response										"Invalid credentials"
+//	@Failure		429		{object}	response.Response										"Failed, too many failed logins"
+//	@Failure		500		{object}	response.Response										"Failed to start two factor challenge"
 //	@Router			/admin/login [post]
 func (a *AuthHandler) AdminLogin(c *gin.Context) {
 	var body request.AdminLogin
@@ -48,13 +104,54 @@ func (a *AuthHandler) AdminLogin(c *gin.Context) {
 		return
 	}
 
-	err := a.authUseCase.AdminLogin(body)
+	body.IP = c.ClientIP()
+	err := a.authUseCase.AdminLogin(c.Request.Context(), body)
+	if loginLocked(c, err) {
+		return
+	}
 	if err != nil {
 		response := response.ResponseMessage(statusUnauthorized, "Invalid credentials", nil, err.Error())
 		c.JSON(statusUnauthorized, response)
 		return
 	}
 
+	challenge, err := a.authUseCase.AdminLoginChallenge(c.Request.Context())
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to start two factor challenge", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusAccepted, "Password accepted, two factor code required", challenge, nil)
+	c.JSON(statusAccepted, response)
+}
+
+// AdminTwoFactorLogin godoc.
+//
+//	@Summary		Admin Login, two factor step
+//	@Description	Completes the admin login with the challenge of /admin/login and the code of the authenticator app or a recovery code. When the challenge is an enrolment the recovery codes are returned, they are shown only once.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.TwoFactorLogin									true	"Challenge and code"
+//	@Success		200		{object}	response.Response{data=response.TwoFactorLogin}		"Login success"
+//	@Failure		400		{object}	response.Response										"Failed to bind JSON inputs from request"
+//	@Failure		401		{object}	response.Response										"Failed, invalid two factor code"
+//	@Failure		429		{object}	response.Response										"Failed, too many failed logins"
+//	@Failure		500		{object}	response.Response										"Failed to generate token"
+//	@Router			/admin/login/2fa [post]
+func (a *AuthHandler) AdminTwoFactorLogin(c *gin.Context) {
+	var body request.TwoFactorLogin
+	if !a.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	body.IP = c.ClientIP()
+	recoveryCodes, err := a.authUseCase.VerifyAdminTwoFactor(c.Request.Context(), body)
+	if twoFactorFailed(c, err) {
+		return
+	}
+
 	TokenString, err := a.token.GenerateAdminToken()
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to generate token", nil, err.Error())
@@ -63,9 +160,9 @@ func (a *AuthHandler) AdminLogin(c *gin.Context) {
 	}
 
 	a.token.SetTokenHeader(c, TokenString)
-	token := &response.Token{Tkn: TokenString}
+	data := response.TwoFactorLogin{Token: TokenString, RecoveryCodes: recoveryCodes}
 
-	response := response.ResponseMessage(statusOK, "Login success", token, nil)
+	response := response.ResponseMessage(statusOK, "Login success", data, nil)
 	c.JSON(statusOK, response)
 }
 
@@ -87,7 +184,7 @@ func (a *AuthHandler) SendOTP(c *gin.Context) {
 		return
 	}
 
-	phone, err := a.authUseCase.ValidateSignUpRequest(body)
+	phone, err := a.authUseCase.ValidateSignUpRequest(c.Request.Context(), body)
 	if err != nil {
 		response := response.ResponseMessage(statusBadRequest, "Failed", nil, err.Error())
 		c.JSON(statusBadRequest, response)
@@ -97,13 +194,8 @@ func (a *AuthHandler) SendOTP(c *gin.Context) {
 	uuid := helper.GenerateUniqueID()
 	contact.Set(uuid, fmt.Sprint(phone))
 
-	go contact.Clean(uuid)
-
-	go func() {
-		time.Sleep(65 * time.Second)
-		contact.Print(uuid)
-		fmt.Println(contact)
-	}()
+	// the phone is kept in the memory of this process, so is its cleanup
+	time.AfterFunc(otpExpiry, func() { contact.Clean(uuid) })
 
 	data := response.Uuid{Uuid: uuid}
 	response := response.ResponseMessage(statusOK, "Success, otp sended.The otp will be expire within 3 minute.", data, nil)
@@ -113,7 +205,7 @@ func (a *AuthHandler) SendOTP(c *gin.Context) {
 // VerifyOTP godoc
 //
 //	@Summary		Verify sign up  OTP
-//	@Description	Validates the provided OTP for a phone number. Provide the accurate uuid and otp = 0000(predefined).
+//	@Description	Validates the provided OTP for a phone number. Provide the uuid returned by send otp and the otp sent to the phone, 0000 when OTP_DEV_MODE is true.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
@@ -132,18 +224,15 @@ func (a *AuthHandler) VerifyOTP(c *gin.Context) {
 	}
 
 	phone, ok, _ := contact.Get(body.UUID)
-	if ok {
-		contact.Verified(body.UUID, phone)
-	}
-
 	if !ok {
 		response := response.ResponseMessage(statusInternalServerError, "OTP expired", nil, "unable to find phone number")
 		c.JSON(statusInternalServerError, response)
 		return
 	}
 
+	// the phone is only verified by an approved otp, any other outcome clears a
+	// previous verification of the uuid
 	status, err := helper.CheckOtp(phone, body.Otp)
-
 	if err != nil {
 		contact.NotVerified(body.UUID, phone)
 		response := response.ResponseMessage(statusBadRequest, "Failed to verify otp", nil, err.Error())
@@ -151,11 +240,13 @@ func (a *AuthHandler) VerifyOTP(c *gin.Context) {
 		return
 	}
 
-	if status == "incorrect" {
+	if status != "approved" {
+		contact.NotVerified(body.UUID, phone)
 		response := response.ResponseMessage(statusBadRequest, "Incorrect otp", nil, nil)
 		c.JSON(statusBadRequest, response)
 		return
 	}
+	contact.Verified(body.UUID, phone)
 
 	data := response.Uuid{
 		Uuid: body.UUID,
@@ -167,11 +258,12 @@ func (a *AuthHandler) VerifyOTP(c *gin.Context) {
 // UserSignUp is the handler function for user sign-up.
 //
 //	@Summary		User Sign-Up after otp validation
-//	@Description	Creates a new user account.
+//	@Description	Creates a new user account. The guest cart of the X-Cart-Token header is merged into the new account.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
-//	@Param			body	body		request.SignUpData	true	"User Sign-Up Data"
+//	@Param			body			body		request.SignUpData	true	"User Sign-Up Data"
+//	@Param			X-Cart-Token	header		string				false	"Signed cart token of the guest cart"
 //	@Success		201		{object}	response.Response	"Success, account created"
 //
 //	@Failure		400		{object}	response.Response	"Failed to bind JSON inputs from request"
@@ -209,7 +301,10 @@ func (u *AuthHandler) UserSignUp(c *gin.Context) {
 
 	body.Phone = phone
 
-	err = u.authUseCase.SignUp(body)
+	userData, err := u.authUseCase.SignUp(c.Request.Context(), body)
+	if passwordRefused(c, err) {
+		return
+	}
 	if err != nil {
 		response := response.ResponseMessage(400, "Failed", nil, err.Error())
 		c.JSON(http.StatusBadRequest, response)
@@ -217,6 +312,7 @@ func (u *AuthHandler) UserSignUp(c *gin.Context) {
 	}
 
 	contact.Delete(body.Uuid)
+	u.mergeGuestCart(c, userData.ID)
 
 	response := response.ResponseMessage(statusCreated, "Success, account created", nil, nil)
 	c.JSON(statusCreated, response)
@@ -225,14 +321,17 @@ func (u *AuthHandler) UserSignUp(c *gin.Context) {
 // UserLogin godoc
 //
 //	@Summary		User login data, verify it and send otp
-//	@Description	Logs in a user and sends an OTP for verification.
+//	@Description	Logs in a user with the phone number or the email address and the password. When the user enabled two factor authentication the response is a challenge for /login/2fa.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
-//	@Param			body	body		request.LoginData	true	"User login data"
+//	@Param			body			body		request.LoginData	true	"User login data"
+//	@Param			X-Cart-Token	header		string				false	"Signed cart token of the guest cart"
 //	@Success		200		{object}	response.Response
+//	@Success		202		{object}	response.Response{data=response.TwoFactorChallenge}	"Password accepted, two factor code required"
 //	@Failure		400		{object}	response.Response
 //	@Failure		401		{object}	response.Response
+//	@Failure		429		{object}	response.Response	"Failed, too many failed logins"
 //	@Failure		500		{object}	response.Response
 //	@Router			/login [post]
 func (uh *AuthHandler) UserLogin(c *gin.Context) {
@@ -243,14 +342,160 @@ func (uh *AuthHandler) UserLogin(c *gin.Context) {
 		return
 	}
 
-	UserData, err := uh.authUseCase.ValidateUserLoginCredentials(body)
+	body.IP = c.ClientIP()
+	UserData, err := uh.authUseCase.ValidateUserLoginCredentials(c.Request.Context(), body)
+	if loginLocked(c, err) {
+		return
+	}
 	if err != nil {
 		response := response.ResponseMessage(401, "Failed", nil, err.Error())
 		c.JSON(http.StatusUnauthorized, response)
 		return
 	}
 
-	TokenString, err := uh.token.GenerateUserToken(UserData.ID)
+	challenge, required, err := uh.authUseCase.UserLoginChallenge(c.Request.Context(), UserData.ID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to start two factor challenge", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+	if required {
+		response := response.ResponseMessage(statusAccepted, "Password accepted, two factor code required", challenge, nil)
+		c.JSON(statusAccepted, response)
+		return
+	}
+
+	uh.issueUserToken(c, UserData.ID)
+}
+
+// UserTwoFactorLogin godoc
+//
+//	@Summary		User login, two factor step
+//	@Description	Completes the login with the challenge of /login and the code of the authenticator app or a recovery code.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body			body		request.TwoFactorLogin	true	"Challenge and code"
+//	@Param			X-Cart-Token	header		string					false	"Signed cart token of the guest cart"
+//	@Success		200				{object}	response.Response		"Login success"
+//	@Failure		400				{object}	response.Response		"Failed to bind JSON inputs from request"
+//	@Failure		401				{object}	response.Response		"Failed, invalid two factor code"
+//	@Failure		429				{object}	response.Response		"Failed, too many failed logins"
+//	@Failure		500				{object}	response.Response		"Failed to generate jwt token"
+//	@Router			/login/2fa [post]
+func (uh *AuthHandler) UserTwoFactorLogin(c *gin.Context) {
+	var body request.TwoFactorLogin
+	if !uh.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	body.IP = c.ClientIP()
+	userID, err := uh.authUseCase.VerifyUserTwoFactor(c.Request.Context(), body)
+	if twoFactorFailed(c, err) {
+		return
+	}
+
+	uh.issueUserToken(c, userID)
+}
+
+// ListLoginProviders godoc
+//
+//	@Summary		Social login providers
+//	@Description	Lists the configured OpenID Connect providers, start a login with /auth/oidc/{provider}/login.
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=[]string}
+//	@Router			/auth/oidc/providers [get]
+func (a *AuthHandler) ListLoginProviders(c *gin.Context) {
+	response := response.ResponseMessage(statusOK, "Success", a.oidcUseCase.Providers(c.Request.Context()), nil)
+	c.JSON(statusOK, response)
+}
+
+// OIDCLogin godoc
+//
+//	@Summary		Social login
+//	@Description	Redirects to the OpenID Connect provider to log in with the authorization code flow and PKCE. The provider redirects back to the callback.
+//	@Tags			auth
+//	@Produce		json
+//	@Param			provider	path		string				true	"Provider name"
+//	@Param			login_hint	query		string				false	"Email suggested to the provider"
+//	@Success		302			{string}	string				"Redirect to the provider"
+//	@Failure		400			{object}	response.Response	"Failed, unknown login provider"
+//	@Failure		500			{object}	response.Response	"Failed to start social login"
+//	@Router			/auth/oidc/{provider}/login [get]
+func (a *AuthHandler) OIDCLogin(c *gin.Context) {
+	loginURL, err := a.oidcUseCase.LoginURL(c.Request.Context(), c.Param("provider"), c.Query("login_hint"))
+	if err == usecase.ErrUnknownProvider {
+		response := response.ResponseMessage(statusBadRequest, "Failed, unknown login provider", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to start social login", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	c.Redirect(http.StatusFound, loginURL)
+}
+
+// OIDCCallback godoc
+//
+//	@Summary		Social login callback
+//	@Description	Completes the social login, the provider account is linked to the user with the same verified email or a new account is created. When the user enabled two factor authentication the response is a challenge for /login/2fa.
+//	@Tags			auth
+//	@Produce		json
+//	@Param			provider		path		string	true	"Provider name"
+//	@Param			code			query		string	true	"Authorization code"
+//	@Param			state			query		string	true	"State of the login"
+//	@Param			X-Cart-Token	header		string	false	"Signed cart token of the guest cart"
+//	@Success		200				{object}	response.Response	"Login success"
+//	@Success		202				{object}	response.Response{data=response.TwoFactorChallenge}	"Two factor code required"
+//	@Failure		400				{object}	response.Response	"Failed, social login refused"
+//	@Failure		401				{object}	response.Response	"Failed, invalid or expired login, start again"
+//	@Failure		500				{object}	response.Response	"Failed to complete social login"
+//	@Router			/auth/oidc/{provider}/callback [get]
+func (a *AuthHandler) OIDCCallback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		response := response.ResponseMessage(statusBadRequest, "Failed, social login refused", nil, providerErr+" "+c.Query("error_description"))
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	userData, err := a.oidcUseCase.Callback(c.Request.Context(), c.Param("provider"), c.Query("code"), c.Query("state"))
+	switch {
+	case err == usecase.ErrInvalidToken:
+		response := response.ResponseMessage(statusUnauthorized, "Failed, invalid or expired login, start again", nil, err.Error())
+		c.JSON(statusUnauthorized, response)
+		return
+	case err == usecase.ErrUnknownProvider || err == usecase.ErrProviderEmailUnverified || err == usecase.ErrAccountEmailUnverified:
+		response := response.ResponseMessage(statusBadRequest, "Failed, social login refused", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	case err != nil:
+		response := response.ResponseMessage(statusInternalServerError, "Failed to complete social login", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	challenge, required, err := a.authUseCase.UserLoginChallenge(c.Request.Context(), userData.ID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to start two factor challenge", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+	if required {
+		response := response.ResponseMessage(statusAccepted, "Two factor code required", challenge, nil)
+		c.JSON(statusAccepted, response)
+		return
+	}
+
+	a.issueUserToken(c, userData.ID)
+}
+
+// issueUserToken responds with the access token of the logged in user.
+func (uh *AuthHandler) issueUserToken(c *gin.Context, userID int) {
+	TokenString, err := uh.token.GenerateUserToken(userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to generate jwt token", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -258,6 +503,7 @@ func (uh *AuthHandler) UserLogin(c *gin.Context) {
 	}
 
 	uh.token.SetTokenHeader(c, TokenString)
+	uh.mergeGuestCart(c, userID)
 
 	response := response.ResponseMessage(200, "Login success", gin.H{"token": TokenString}, nil)
 
@@ -278,3 +524,131 @@ func (ah *AuthHandler) Logout(c *gin.Context) {
 	response := response.ResponseMessage(statusAccepted, "Log out, success", nil, nil)
 	c.JSON(statusAccepted, response)
 }
+
+// VerifyEmail godoc
+//
+//	@Summary		Verify email address
+//	@Description	Verifies the email address of the user, using the signed link sent to the email.
+//	@Tags			auth
+//	@Produce		json
+//	@Param			token	query		string				true	"Verification token from the email"
+//	@Success		200		{object}	response.Response	"Success, email verified"
+//	@Failure		400		{object}	response.Response	"Failed, invalid or expired link"
+//	@Failure		500		{object}	response.Response	"Failed to verify email"
+//	@Router			/verify-email [get]
+func (a *AuthHandler) VerifyEmail(c *gin.Context) {
+	err := a.authUseCase.VerifyEmail(c.Request.Context(), c.Query("token"))
+	if err == usecase.ErrInvalidToken {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid or expired link", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to verify email", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, email verified", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// SendEmailVerification godoc
+//
+//	@Summary		Resend email verification
+//	@Description	Sends a new verification link to the email address of the user.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response	"Success, verification email sent"
+//	@Failure		409	{object}	response.Response	"Email already verified"
+//	@Failure		500	{object}	response.Response	"Failed to send verification email"
+//	@Router			/profile/verify-email [post]
+func (a *AuthHandler) SendEmailVerification(c *gin.Context) {
+	userID, _ := helper.GetIDFromContext(c)
+
+	err := a.authUseCase.SendEmailVerification(c.Request.Context(), userID)
+	if err == usecase.ErrEmailAlreadyVerified {
+		response := response.ResponseMessage(statusConflict, "Email already verified", nil, err.Error())
+		c.JSON(statusConflict, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to send verification email", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, verification email sent", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// ForgotPassword godoc
+//
+//	@Summary		Forgot password
+//	@Description	Sends a password reset link to the email address, or an otp to the phone number with a challenge token in the response. The response is the same whether an account exists or not.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.ForgotPassword	true	"Email or phone"
+//	@Success		200		{object}	response.Response{data=response.PasswordResetChallenge}	"Success, if an account exists the reset instructions are sent"
+//	@Failure		400		{object}	response.Response		"Failed to bind JSON inputs from request"
+//	@Failure		500		{object}	response.Response		"Failed to send reset instructions"
+//	@Router			/forgot-password [post]
+func (a *AuthHandler) ForgotPassword(c *gin.Context) {
+	var body request.ForgotPassword
+	if !a.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	challenge, err := a.authUseCase.ForgotPassword(c.Request.Context(), body)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to send reset instructions", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, if an account exists the reset instructions are sent", challenge, nil)
+	c.JSON(statusOK, response)
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset password
+//	@Description	Sets a new password using the token of the reset link, or the challenge token of forgot password and the otp. Failed otps are throttled like the logins.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.ResetPassword	true	"Reset token or challenge token and otp, and the new password"
+//	@Success		200		{object}	response.Response		"Success, password changed"
+//	@Failure		400		{object}	response.Response		"Failed to bind JSON inputs from request"
+//	@Failure		400		{object}	response.Response		"Failed, invalid or expired reset request"
+//	@Failure		400		{object}	response.Response		"Failed, password does not meet the password policy"
+//	@Failure		429		{object}	response.Response		"Failed, too many failed logins"
+//	@Failure		500		{object}	response.Response		"Failed to reset password"
+//	@Router			/reset-password [post]
+func (a *AuthHandler) ResetPassword(c *gin.Context) {
+	var body request.ResetPassword
+	if !a.subHandler.BindRequest(c, &body) {
+		return
+	}
+	body.IP = c.ClientIP()
+
+	err := a.authUseCase.ResetPassword(c.Request.Context(), body)
+	if passwordRefused(c, err) || loginLocked(c, err) {
+		return
+	}
+	switch {
+	case err == usecase.ErrInvalidToken || err == usecase.ErrPasswordMismatch:
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid or expired reset request", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	case err != nil:
+		response := response.ResponseMessage(statusInternalServerError, "Failed to reset password", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, password changed", nil, nil)
+	c.JSON(statusOK, response)
+}
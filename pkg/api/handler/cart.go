@@ -4,14 +4,17 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"github.com/gin-gonic/gin"
 )
 
 type CartHandler struct {
 	cartUseCase services.CartUseCase
+	subHandler  helper.SubHandler
 }
 
 func NewCartHandler(useCase services.CartUseCase) *CartHandler {
@@ -20,6 +23,18 @@ func NewCartHandler(useCase services.CartUseCase) *CartHandler {
 	}
 }
 
+// quantityRefused responds with bad request when the product can't be added in
+// that quantity.
+func quantityRefused(c *gin.Context, err error) bool {
+	if err != usecase.ErrMaxPerOrder && err != usecase.ErrNotEnoughStock && err != usecase.ErrNoRecord {
+		return false
+	}
+
+	response := response.ResponseMessage(statusBadRequest, "Failed", nil, err.Error())
+	c.JSON(statusBadRequest, response)
+	return true
+}
+
 // AddToCart is the handler function for adding a product to the cart.
 //
 //	@Summary		Add product to cart
@@ -42,8 +57,11 @@ func (ch *CartHandler) AddToCart(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err = ch.cartUseCase.AddToCart(userID, productID)
+	err = ch.cartUseCase.AddToCart(c.Request.Context(), userID, productID)
 	if err != nil {
+		if quantityRefused(c, err) {
+			return
+		}
 		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
@@ -69,7 +87,7 @@ func (ch *CartHandler) ViewCart(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	CartItems, err := ch.cartUseCase.ViewCart(userID)
+	CartItems, err := ch.cartUseCase.ViewCart(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -103,8 +121,11 @@ func (ch *CartHandler) IncrementQuantity(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err = ch.cartUseCase.IncrementQuantity(userID, productID)
+	err = ch.cartUseCase.IncrementQuantity(c.Request.Context(), userID, productID)
 	if err != nil {
+		if quantityRefused(c, err) {
+			return
+		}
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
@@ -136,7 +157,7 @@ func (ch *CartHandler) DecrementQuantity(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err = ch.cartUseCase.DecrementQuantity(userID, productID)
+	err = ch.cartUseCase.DecrementQuantity(c.Request.Context(), userID, productID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -169,7 +190,7 @@ func (ch *CartHandler) RemoveFromCart(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err = ch.cartUseCase.RemoveFromCart(userID, productID)
+	err = ch.cartUseCase.RemoveFromCart(c.Request.Context(), userID, productID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -179,3 +200,314 @@ func (ch *CartHandler) RemoveFromCart(c *gin.Context) {
 	response := response.ResponseMessage(200, "Success", nil, nil)
 	c.JSON(http.StatusOK, response)
 }
+
+// UpdateCart godoc
+//
+//	@Summary		Set cart quantities
+//	@Description	Sets the quantity of several cart lines in one request, a zero quantity removes the line. Nothing changes when any line is not in the cart or is over the stock or the limit per order.
+//	@Tags			cart
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.UpdateCart	true	"Cart quantities"
+//	@Success		200		{object}	response.Response
+//	@Failure		400		{object}	response.Response{data=[]response.CartIssue}	"Failed, quantities not updated"
+//	@Failure		500		{object}	response.Response
+//	@Router			/cart [put]
+func (ch *CartHandler) UpdateCart(c *gin.Context) {
+	var body request.UpdateCart
+	if !ch.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+
+	issues, err := ch.cartUseCase.UpdateCartQuantities(c.Request.Context(), userID, body.Items)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+	if len(issues) != 0 {
+		response := response.ResponseMessage(statusBadRequest, "Failed, quantities not updated", issues, nil)
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, cart updated", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// SaveForLater godoc
+//
+//	@Summary		Save for later
+//	@Description	Moves a product from the cart to the wishlist.
+//	@Tags			cart
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			productID	path		int	true	"Product ID"
+//	@Success		200			{object}	response.Response
+//	@Failure		400			{object}	response.Response	"Failed, product is not in the cart"
+//	@Failure		500			{object}	response.Response
+//	@Router			/cart/save-for-later/{productID} [post]
+func (ch *CartHandler) SaveForLater(c *gin.Context) {
+	productID, ok := ch.subHandler.ParamInt(c, "productID")
+	if !ok {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+
+	err := ch.cartUseCase.SaveForLater(c.Request.Context(), userID, productID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, product is not in the cart", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, saved for later", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// MoveToCart godoc
+//
+//	@Summary		Move to cart
+//	@Description	Moves a product from the wishlist to the cart.
+//	@Tags			cart
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			productID	path		int	true	"Product ID"
+//	@Success		200			{object}	response.Response
+//	@Failure		400			{object}	response.Response	"Failed, product is not in the wishlist or not available"
+//	@Failure		500			{object}	response.Response
+//	@Router			/wishlist/move-to-cart/{productID} [post]
+func (ch *CartHandler) MoveToCart(c *gin.Context) {
+	productID, ok := ch.subHandler.ParamInt(c, "productID")
+	if !ok {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+
+	err := ch.cartUseCase.MoveToCart(c.Request.Context(), userID, productID)
+	if quantityRefused(c, err) {
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, moved to cart", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// ViewGuestCart godoc
+//
+//	@Summary		View guest cart
+//	@Description	Retrieves the guest cart of a visitor. A new cart token is returned in the X-Cart-Token header when the request has none.
+//	@Tags			guest cart
+//	@Produce		json
+//	@Param			X-Cart-Token	header		string	false	"Signed cart token"
+//	@Success		200				{object}	response.Response{data=response.CartItems}
+//	@Failure		500				{object}	response.Response
+//	@Router			/guest-cart [get]
+func (ch *CartHandler) ViewGuestCart(c *gin.Context) {
+	cartID := helper.GetCartIDFromContext(c)
+
+	CartItems, err := ch.cartUseCase.ViewGuestCart(c.Request.Context(), cartID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", CartItems, nil)
+	c.JSON(statusOK, response)
+}
+
+// AddToGuestCart godoc
+//
+//	@Summary		Add product to guest cart
+//	@Description	Adds a product to the guest cart of a visitor.
+//	@Tags			guest cart
+//	@Produce		json
+//	@Param			X-Cart-Token	header		string	false	"Signed cart token"
+//	@Param			productID		path		int		true	"Product ID"
+//	@Success		201				{object}	response.Response
+//	@Failure		400				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/guest-cart/add/{productID} [post]
+func (ch *CartHandler) AddToGuestCart(c *gin.Context) {
+	productID, ok := ch.subHandler.ParamInt(c, "productID")
+	if !ok {
+		return
+	}
+
+	err := ch.cartUseCase.AddToGuestCart(c.Request.Context(), helper.GetCartIDFromContext(c), productID)
+	if err != nil {
+		if quantityRefused(c, err) {
+			return
+		}
+		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusCreated, "Success", nil, nil)
+	c.JSON(statusCreated, response)
+}
+
+// IncrementGuestQuantity godoc
+//
+//	@Summary		Increment product quantity in guest cart
+//	@Description	Increments the quantity of a product in the guest cart.
+//	@Tags			guest cart
+//	@Produce		json
+//	@Param			X-Cart-Token	header		string	true	"Signed cart token"
+//	@Param			productID		path		int		true	"Product ID"
+//	@Success		200				{object}	response.Response
+//	@Failure		400				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/guest-cart/{productID}/increment [put]
+func (ch *CartHandler) IncrementGuestQuantity(c *gin.Context) {
+	productID, ok := ch.subHandler.ParamInt(c, "productID")
+	if !ok {
+		return
+	}
+
+	err := ch.cartUseCase.IncrementGuestQuantity(c.Request.Context(), helper.GetCartIDFromContext(c), productID)
+	if err != nil {
+		if quantityRefused(c, err) {
+			return
+		}
+		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// DecrementGuestQuantity godoc
+//
+//	@Summary		Decrement product quantity in guest cart
+//	@Description	Decrements the quantity of a product in the guest cart.
+//	@Tags			guest cart
+//	@Produce		json
+//	@Param			X-Cart-Token	header		string	true	"Signed cart token"
+//	@Param			productID		path		int		true	"Product ID"
+//	@Success		200				{object}	response.Response
+//	@Failure		400				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/guest-cart/{productID}/decrement [put]
+func (ch *CartHandler) DecrementGuestQuantity(c *gin.Context) {
+	productID, ok := ch.subHandler.ParamInt(c, "productID")
+	if !ok {
+		return
+	}
+
+	err := ch.cartUseCase.DecrementGuestQuantity(c.Request.Context(), helper.GetCartIDFromContext(c), productID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// RemoveFromGuestCart godoc
+//
+//	@Summary		Remove product from guest cart
+//	@Description	Removes a product from the guest cart.
+//	@Tags			guest cart
+//	@Produce		json
+//	@Param			X-Cart-Token	header		string	true	"Signed cart token"
+//	@Param			productID		path		int		true	"Product ID"
+//	@Success		200				{object}	response.Response
+//	@Failure		400				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/guest-cart/remove/{productID} [delete]
+func (ch *CartHandler) RemoveFromGuestCart(c *gin.Context) {
+	productID, ok := ch.subHandler.ParamInt(c, "productID")
+	if !ok {
+		return
+	}
+
+	err := ch.cartUseCase.RemoveFromGuestCart(c.Request.Context(), helper.GetCartIDFromContext(c), productID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// ApplyGuestCoupon godoc
+//
+//	@Summary		Apply coupon on guest cart
+//	@Description	Applies a coupon on the guest cart, it is carried over to the user's cart on login or sign up.
+//	@Tags			guest cart
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Cart-Token	header		string				true	"Signed cart token"
+//	@Param			code			body		request.ApplyCoupon	true	"Coupon code"
+//	@Success		200				{object}	response.Response
+//	@Failure		400				{object}	response.Response
+//	@Router			/guest-cart/coupon/apply [post]
+func (ch *CartHandler) ApplyGuestCoupon(c *gin.Context) {
+	var body request.ApplyCoupon
+	if !ch.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	err := ch.cartUseCase.ApplyGuestCoupon(c.Request.Context(), helper.GetCartIDFromContext(c), body.Code)
+	if err != nil {
+		response := response.ResponseMessage(statusBadRequest, "Failed to apply coupon", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, coupon applied", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// RemoveGuestCoupon godoc
+//
+//	@Summary		Remove coupon from guest cart
+//	@Description	Removes an applied coupon from the guest cart.
+//	@Tags			guest cart
+//	@Produce		json
+//	@Param			X-Cart-Token	header		string	true	"Signed cart token"
+//	@Param			couponID		path		int		true	"Coupon ID"
+//	@Success		200				{object}	response.Response
+//	@Failure		400				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/guest-cart/coupon/remove/{couponID} [delete]
+func (ch *CartHandler) RemoveGuestCoupon(c *gin.Context) {
+	couponID, ok := ch.subHandler.ParamInt(c, "couponID")
+	if !ok {
+		return
+	}
+
+	err := ch.cartUseCase.RemoveGuestCoupon(c.Request.Context(), helper.GetCartIDFromContext(c), couponID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to remove coupon", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, coupon removed", nil, nil)
+	c.JSON(statusOK, response)
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize is the largest product import file, 10 MB.
+const maxImportFileSize = 10 << 20
+
+// catalogFormats are the content types of the product export by format.
+var catalogFormats = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"json": "application/json; charset=utf-8",
+}
+
+type CatalogHandler struct {
+	catalogUseCase services.CatalogUseCase
+	subHandler     helper.SubHandler
+}
+
+func NewCatalogHandler(useCase services.CatalogUseCase) *CatalogHandler {
+	return &CatalogHandler{
+		catalogUseCase: useCase,
+	}
+}
+
+// ImportProducts godoc
+//
+//	@Summary		Bulk import products
+//	@Description	Import products from a csv or json file in the columns of the export. A product with an existing sku is updated, the others are created with the sku or one made from the name. The category is found by name and images are http urls, separated by | in csv. The rows are saved in the background, follow the progress and the row errors with the import id. A dry run only validates the rows.
+//	@Tags			admin product management
+//	@Security		Bearer
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			file	formData	file	true	"csv or json file, at most 10 MB and 10000 products"
+//	@Param			format	query		string	false	"csv or json, defaults to the file extension"
+//	@Param			dry_run	query		bool	false	"Validate the rows without saving them"
+//	@Success		202		{object}	response.Response{data=response.ProductImport}	"Accepted, product import queued"
+//	@Failure		400		{object}	response.Response	"Failed, invalid import file"
+//	@Failure		500		{object}	response.Response	"Failed to import products"
+//	@Router			/admin/product/import [post]
+func (ch *CatalogHandler) ImportProducts(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid import file", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if file.Size > maxImportFileSize {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid import file", nil, "the file is larger than 10 MB")
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(file.Filename), "."))
+	}
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			response := response.ResponseMessage(statusBadRequest, "Failed, dry_run must be true or false", nil, err.Error())
+			c.JSON(statusBadRequest, response)
+			return
+		}
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid import file", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	defer content.Close()
+
+	productImport, err := ch.catalogUseCase.ImportProducts(c.Request.Context(), format, file.Filename, content, dryRun)
+	if errors.Is(err, usecase.ErrInvalidImportFile) {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid import file", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to import products", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusAccepted, "Accepted, product import queued", productImport, nil)
+	c.JSON(statusAccepted, response)
+}
+
+// GetProductImport godoc
+//
+//	@Summary		Product import status
+//	@Description	The progress of a product import with the created, updated and failed rows and the error of each failed row.
+//	@Tags			admin product management
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			importID	path		int	true	"Import ID"
+//	@Success		200			{object}	response.Response{data=response.ProductImport}
+//	@Failure		400			{object}	response.Response	"Failed, no import with this id"
+//	@Failure		500			{object}	response.Response	"Failed to get product import"
+//	@Router			/admin/product/imports/{importID} [get]
+func (ch *CatalogHandler) GetProductImport(c *gin.Context) {
+	importID, ok := ch.subHandler.ParamInt(c, "importID")
+	if !ok {
+		return
+	}
+
+	productImport, err := ch.catalogUseCase.GetProductImport(c.Request.Context(), importID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no import with this id", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get product import", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", productImport, nil)
+	c.JSON(statusOK, response)
+}
+
+// ListProductImports godoc
+//
+//	@Summary		Product imports
+//	@Description	The product imports with their progress, the latest first.
+//	@Tags			admin product management
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			page	query		int	true	"Page number"
+//	@Param			count	query		int	true	"Count of items per page"
+//	@Success		200		{object}	response.Response{data=[]response.ProductImport}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve page info"
+//	@Failure		500		{object}	response.Response	"Failed to get product imports"
+//	@Router			/admin/product/imports [get]
+func (ch *CatalogHandler) ListProductImports(c *gin.Context) {
+	page, count, ok := ch.subHandler.GetPageNCount(c)
+	if !ok {
+		return
+	}
+
+	imports, err := ch.catalogUseCase.GetProductImports(c.Request.Context(), page, count)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get product imports", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", imports, nil)
+	c.JSON(statusOK, response)
+}
+
+// ExportProducts godoc
+//
+//	@Summary		Bulk export products
+//	@Description	Download every product as csv or json in the columns of the import, with the category name.
+//	@Tags			admin product management
+//	@Security		Bearer
+//	@Produce		text/csv
+//	@Produce		json
+//	@Param			format	query		string	false	"csv (default) or json"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	response.Response	"Failed, format must be csv or json"
+//	@Router			/admin/product/export [get]
+func (ch *CatalogHandler) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	contentType, ok := catalogFormats[format]
+	if !ok {
+		response := response.ResponseMessage(statusBadRequest, "Failed, format must be csv or json", nil, nil)
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="products-%s.%s"`, time.Now().Format("20060102"), format))
+	c.Status(statusOK)
+	clearWriteDeadline(c)
+
+	// the products are streamed, an error after the first rows can only end the download
+	if err := ch.catalogUseCase.ExportProducts(c.Request.Context(), format, c.Writer); err != nil {
+		helper.LoggerFrom(c.Request.Context()).Error("product export ended", "format", format, "error", err)
+		c.Abort()
+	}
+}
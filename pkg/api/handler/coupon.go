@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
@@ -43,7 +45,12 @@ func (ch *CouponHandler) CreateCoupon(c *gin.Context) {
 		return
 	}
 
-	err := ch.coupenUseCase.CreateCoupons(body)
+	err := ch.coupenUseCase.CreateCoupons(c.Request.Context(), body)
+	if errors.Is(err, usecase.ErrInvalidCouponRule) {
+		response := response.ResponseMessage(statusBadRequest, "Failed, input does not meet validation criteria", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to create coupon", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -81,7 +88,12 @@ func (ch *CouponHandler) UpdateCoupon(c *gin.Context) {
 		return
 	}
 
-	err := ch.coupenUseCase.UpdateCoupon(body, couponID)
+	err := ch.coupenUseCase.UpdateCoupon(c.Request.Context(), body, couponID)
+	if errors.Is(err, usecase.ErrInvalidCouponRule) {
+		response := response.ResponseMessage(statusBadRequest, "Failed, input does not meet validation criteria", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to update coupon", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -111,7 +123,7 @@ func (ch *CouponHandler) BlockCoupon(c *gin.Context) {
 		return
 	}
 
-	err := ch.coupenUseCase.BlockCoupon(couponID)
+	err := ch.coupenUseCase.BlockCoupon(c.Request.Context(), couponID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to block coupon", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -140,7 +152,7 @@ func (ch *CouponHandler) UnBlockCoupon(c *gin.Context) {
 		return
 	}
 
-	err := ch.coupenUseCase.UnBlockCoupon(couponID)
+	err := ch.coupenUseCase.UnBlockCoupon(c.Request.Context(), couponID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -162,7 +174,7 @@ func (ch *CouponHandler) UnBlockCoupon(c *gin.Context) {
 //	@Failure		500	{object}	response.Response
 //	@Router			/admin/promotions/all-coupons  [get]
 func (ch *CouponHandler) ListOutAllCouponsToAdmin(c *gin.Context) {
-	Coupons, err := ch.coupenUseCase.ViewAllCoupons()
+	Coupons, err := ch.coupenUseCase.ViewAllCoupons(c.Request.Context())
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to fetch coupons", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -196,7 +208,7 @@ func (ch *CouponHandler) ApplyCoupon(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err := ch.coupenUseCase.ProcessApplyCoupon(body.Code, userID)
+	err := ch.coupenUseCase.ProcessApplyCoupon(c.Request.Context(), body.Code, userID)
 	if err != nil {
 		response := response.ResponseMessage(403, "Failed", nil, err.Error())
 		c.JSON(http.StatusForbidden, response)
@@ -207,6 +219,44 @@ func (ch *CouponHandler) ApplyCoupon(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// EvaluateCoupon godoc
+//
+//	@Summary		Evaluate coupon
+//	@Description	Dry run the coupon against the user's cart and explain which rules pass or fail, without applying it.
+//	@Tags			coupon
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			code	body		request.ApplyCoupon	true	"Coupon code"
+//	@Success		200		{object}	response.Response{data=response.CouponEvaluation}
+//	@Failure		400		{object}	response.Response
+//	@Failure		404		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/coupon/evaluate [post]
+func (ch *CouponHandler) EvaluateCoupon(c *gin.Context) {
+	var body request.ApplyCoupon
+	if !ch.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+
+	evaluation, err := ch.coupenUseCase.EvaluateCoupon(c.Request.Context(), body.Code, userID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(http.StatusNotFound, "Coupon not found", nil, err.Error())
+		c.JSON(http.StatusNotFound, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to evaluate coupon", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", evaluation, nil)
+	c.JSON(statusOK, response)
+}
+
 // ListOutAvailableCouponsToUser godoc
 //
 //	@Summary		List available coupons for the user
@@ -220,7 +270,7 @@ func (ch *CouponHandler) ApplyCoupon(c *gin.Context) {
 func (ch *CouponHandler) ListOutAvailableCouponsToUser(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 
-	AvailabeCoupons, err := ch.coupenUseCase.ListOutAvailableCouponsToUser(userID)
+	AvailabeCoupons, err := ch.coupenUseCase.ListOutAvailableCouponsToUser(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -258,7 +308,7 @@ func (ch *CouponHandler) RemoveAppliedCoupon(c *gin.Context) {
 	}
 
 	userID, _ := helper.GetIDFromContext(c)
-	err = ch.coupenUseCase.RemoveFromCouponTracking(couponID, userID)
+	err = ch.coupenUseCase.RemoveFromCouponTracking(c.Request.Context(), couponID, userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
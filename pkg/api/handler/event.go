@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type EventHandler struct {
+	eventUseCase services.EventUseCase
+	subHandler   helper.SubHandler
+}
+
+func NewEventHandler(useCase services.EventUseCase) *EventHandler {
+	return &EventHandler{
+		eventUseCase: useCase,
+	}
+}
+
+// ListDeadEvents godoc
+//
+//	@Summary		Dead-lettered events
+//	@Description	List the domain events whose subscribers kept failing, with the last error.
+//	@Tags			events
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			page	query		int	true	"Page number"
+//	@Param			count	query		int	true	"Count of items per page"
+//	@Success		200		{object}	response.Response{data=[]response.DomainEvent}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve page info"
+//	@Failure		500		{object}	response.Response	"Failed to get dead events"
+//	@Router			/admin/events/dead-letters [get]
+func (eh *EventHandler) ListDeadEvents(c *gin.Context) {
+	page, count, ok := eh.subHandler.GetPageNCount(c)
+	if !ok {
+		return
+	}
+
+	events, err := eh.eventUseCase.GetDeadEvents(c.Request.Context(), page, count)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get dead events", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", events, nil)
+	c.JSON(statusOK, response)
+}
+
+// RetryDeadEvent godoc
+//
+//	@Summary		Retry dead-lettered event
+//	@Description	Put a dead-lettered event back in the outbox, only the subscribers that failed run again.
+//	@Tags			events
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			eventID	path		int					true	"Event ID"
+//	@Success		200		{object}	response.Response	"Success, event queued for retry"
+//	@Failure		400		{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		400		{object}	response.Response	"Failed, no dead event with this id"
+//	@Failure		500		{object}	response.Response	"Failed to retry event"
+//	@Router			/admin/events/{eventID}/retry [post]
+func (eh *EventHandler) RetryDeadEvent(c *gin.Context) {
+	eventID, ok := eh.subHandler.ParamInt(c, "eventID")
+	if !ok {
+		return
+	}
+
+	err := eh.eventUseCase.RetryDeadEvent(c.Request.Context(), eventID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no dead event with this id", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to retry event", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, event queued for retry", nil, nil)
+	c.JSON(statusOK, response)
+}
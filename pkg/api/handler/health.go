@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+// healthCheckTimeout bounds the checks so a hung database fails the probe.
+const healthCheckTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	healthUseCase services.HealthUseCase
+	draining      int32
+}
+
+func NewHealthHandler(useCase services.HealthUseCase) *HealthHandler {
+	return &HealthHandler{
+		healthUseCase: useCase,
+	}
+}
+
+// Drain fails the readiness check from now on, so the load balancer stops sending
+// requests while the server shuts down.
+func (hh *HealthHandler) Drain() {
+	atomic.StoreInt32(&hh.draining, 1)
+}
+
+// Healthz godoc
+//
+//	@Summary		Liveness check
+//	@Description	The process is up. Postgres is checked by the readiness check only, so a database outage takes the pods out of the load balancer without restarting them.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.Health}
+//	@Router			/healthz [get]
+func (hh *HealthHandler) Healthz(c *gin.Context) {
+	health := hh.healthUseCase.Live(c.Request.Context())
+	response := response.ResponseMessage(statusOK, "Success", health, nil)
+	c.JSON(statusOK, response)
+}
+
+// Readyz godoc
+//
+//	@Summary		Readiness check
+//	@Description	The service takes requests, Postgres is reachable and the migrations are applied. Fails once the server starts shutting down.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.Health}
+//	@Failure		503	{object}	response.Response{data=response.Health}	"Failed, service is not ready"
+//	@Router			/readyz [get]
+func (hh *HealthHandler) Readyz(c *gin.Context) {
+	if atomic.LoadInt32(&hh.draining) == 1 {
+		health := response.Health{Status: "draining", Checks: map[string]string{}}
+		response := response.ResponseMessage(statusServiceUnavailable, "Failed, service is shutting down", health, nil)
+		c.JSON(statusServiceUnavailable, response)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	health, err := hh.healthUseCase.Ready(ctx)
+	if err != nil {
+		response := response.ResponseMessage(statusServiceUnavailable, "Failed, service is not ready", health, err.Error())
+		c.JSON(statusServiceUnavailable, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", health, nil)
+	c.JSON(statusOK, response)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+// jobStatuses are the statuses the jobs can be listed by.
+var jobStatuses = map[string]bool{
+	"":          true,
+	"queued":    true,
+	"running":   true,
+	"completed": true,
+	"failed":    true,
+}
+
+type JobHandler struct {
+	jobUseCase services.JobUseCase
+	subHandler helper.SubHandler
+}
+
+func NewJobHandler(useCase services.JobUseCase) *JobHandler {
+	return &JobHandler{
+		jobUseCase: useCase,
+	}
+}
+
+// ListJobs godoc
+//
+//	@Summary		Background jobs
+//	@Description	List the background jobs with their attempts and last error, the latest first. The scheduled jobs are queued again after each run.
+//	@Tags			jobs
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			status	query		string	false	"queued, running, completed or failed"
+//	@Param			type	query		string	false	"Job type"
+//	@Param			page	query		int		true	"Page number"
+//	@Param			count	query		int		true	"Count of items per page"
+//	@Success		200		{object}	response.Response{data=[]response.Job}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve page info"
+//	@Failure		400		{object}	response.Response	"Failed, status must be queued, running, completed or failed"
+//	@Failure		500		{object}	response.Response	"Failed to get jobs"
+//	@Router			/admin/jobs [get]
+func (jh *JobHandler) ListJobs(c *gin.Context) {
+	page, count, ok := jh.subHandler.GetPageNCount(c)
+	if !ok {
+		return
+	}
+
+	filter := request.JobFilter{
+		Status: c.Query("status"),
+		Type:   c.Query("type"),
+	}
+	if !jobStatuses[filter.Status] {
+		response := response.ResponseMessage(statusBadRequest, "Failed, status must be queued, running, completed or failed", nil, nil)
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	jobs, err := jh.jobUseCase.GetJobs(c.Request.Context(), filter, page, count)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get jobs", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", jobs, nil)
+	c.JSON(statusOK, response)
+}
+
+// GetJobStats godoc
+//
+//	@Summary		Background job stats
+//	@Description	The count of the jobs of each type by status.
+//	@Tags			jobs
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=[]response.JobStats}
+//	@Failure		500	{object}	response.Response	"Failed to get job stats"
+//	@Router			/admin/jobs/stats [get]
+func (jh *JobHandler) GetJobStats(c *gin.Context) {
+	stats, err := jh.jobUseCase.GetJobStats(c.Request.Context())
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get job stats", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", stats, nil)
+	c.JSON(statusOK, response)
+}
+
+// RetryJob godoc
+//
+//	@Summary		Retry failed job
+//	@Description	Queue a failed job again with fresh attempts.
+//	@Tags			jobs
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			jobID	path		int	true	"Job ID"
+//	@Success		200		{object}	response.Response{data=response.Job}	"Success, job queued for retry"
+//	@Failure		400		{object}	response.Response						"Failed to retrieve param from URL"
+//	@Failure		400		{object}	response.Response						"Failed, no failed job with this id"
+//	@Failure		500		{object}	response.Response						"Failed to retry job"
+//	@Router			/admin/jobs/{jobID}/retry [post]
+func (jh *JobHandler) RetryJob(c *gin.Context) {
+	jobID, ok := jh.subHandler.ParamInt(c, "jobID")
+	if !ok {
+		return
+	}
+
+	job, err := jh.jobUseCase.RetryJob(c.Request.Context(), jobID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no failed job with this id", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to retry job", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, job queued for retry", job, nil)
+	c.JSON(statusOK, response)
+}
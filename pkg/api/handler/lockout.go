@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type LockoutHandler struct {
+	lockoutUseCase services.LockoutUseCase
+	subHandler     helper.SubHandler
+}
+
+func NewLockoutHandler(useCase services.LockoutUseCase) *LockoutHandler {
+	return &LockoutHandler{
+		lockoutUseCase: useCase,
+	}
+}
+
+// ListLockouts godoc
+//
+//	@Summary		Login lockouts
+//	@Description	List the accounts and IPs with failed logins, the latest failure first. Set active to true to list only the ones locked at the moment.
+//	@Tags			auth
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			page	query		int		true	"Page number"
+//	@Param			count	query		int		true	"Count of items per page"
+//	@Param			active	query		bool	false	"Only the locked accounts and IPs"
+//	@Success		200		{object}	response.Response{data=[]response.LoginLockout}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve page info"
+//	@Failure		500		{object}	response.Response	"Failed to get login lockouts"
+//	@Router			/admin/lockouts [get]
+func (lh *LockoutHandler) ListLockouts(c *gin.Context) {
+	page, count, ok := lh.subHandler.GetPageNCount(c)
+	if !ok {
+		return
+	}
+
+	lockouts, err := lh.lockoutUseCase.GetLockouts(c.Request.Context(), c.Query("active") == "true", page, count)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get login lockouts", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", lockouts, nil)
+	c.JSON(statusOK, response)
+}
+
+// ClearLockout godoc
+//
+//	@Summary		Clear login lockout
+//	@Description	Remove the failed logins of an account or IP, it can log in again right away.
+//	@Tags			auth
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			lockoutID	path		int					true	"Lockout ID"
+//	@Success		200			{object}	response.Response	"Success, lockout cleared"
+//	@Failure		400			{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		400			{object}	response.Response	"Failed, no lockout with this id"
+//	@Failure		500			{object}	response.Response	"Failed to clear lockout"
+//	@Router			/admin/lockouts/{lockoutID} [delete]
+func (lh *LockoutHandler) ClearLockout(c *gin.Context) {
+	lockoutID, ok := lh.subHandler.ParamInt(c, "lockoutID")
+	if !ok {
+		return
+	}
+
+	err := lh.lockoutUseCase.ClearLockout(c.Request.Context(), lockoutID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no lockout with this id", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to clear lockout", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, lockout cleared", nil, nil)
+	c.JSON(statusOK, response)
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type LoyaltyHandler struct {
+	loyaltyUseCase services.LoyaltyUseCase
+	subHandler     helper.SubHandler
+}
+
+func NewLoyaltyHandler(useCase services.LoyaltyUseCase) *LoyaltyHandler {
+	return &LoyaltyHandler{
+		loyaltyUseCase: useCase,
+	}
+}
+
+// GetPointsHistory godoc
+//
+//	@Summary		Loyalty points history
+//	@Description	Get the loyalty points balance and the points earned, redeemed, refunded, reversed and expired.
+//	@Tags			loyalty
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			page	query		int	true	"Page number"
+//	@Param			count	query		int	true	"Count of items per page"
+//	@Success		200		{object}	response.Response{data=response.LoyaltyHistory}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve page info"
+//	@Failure		500		{object}	response.Response	"Failed to get points history"
+//	@Router			/loyalty/history [get]
+func (lh *LoyaltyHandler) GetPointsHistory(c *gin.Context) {
+	page, count, ok := lh.subHandler.GetPageNCount(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+	history, err := lh.loyaltyUseCase.GetPointsHistory(c.Request.Context(), userID, page, count)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get points history", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", history, nil)
+	c.JSON(statusOK, response)
+}
+
+// ApplyPoints godoc
+//
+//	@Summary		Redeem loyalty points
+//	@Description	Pay part of the cart with loyalty points. The points are spent when the order is placed, up to half of the discounted subtotal.
+//	@Tags			loyalty
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.ApplyPoints	true	"Points to redeem"
+//	@Success		200		{object}	response.Response	"Success, points applied"
+//	@Failure		400		{object}	response.Response	"Failed to bind JSON inputs from request"
+//	@Failure		400		{object}	response.Response	"Failed, not enough loyalty points"
+//	@Failure		500		{object}	response.Response	"Failed to apply points"
+//	@Router			/loyalty/apply [post]
+func (lh *LoyaltyHandler) ApplyPoints(c *gin.Context) {
+	var body request.ApplyPoints
+	if !lh.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+	err := lh.loyaltyUseCase.ApplyPoints(c.Request.Context(), userID, body.Points)
+	if err == usecase.ErrInsufficientPoints {
+		response := response.ResponseMessage(statusBadRequest, "Failed, not enough loyalty points", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to apply points", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, points applied", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// RemoveAppliedPoints godoc
+//
+//	@Summary		Stop redeeming loyalty points
+//	@Description	Remove the loyalty points applied on the cart.
+//	@Tags			loyalty
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response	"Success, points removed"
+//	@Failure		500	{object}	response.Response	"Failed to remove points"
+//	@Router			/loyalty/remove [delete]
+func (lh *LoyaltyHandler) RemoveAppliedPoints(c *gin.Context) {
+	userID, _ := helper.GetIDFromContext(c)
+	err := lh.loyaltyUseCase.RemoveAppliedPoints(c.Request.Context(), userID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to remove points", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, points removed", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// CreateLoyaltyRule godoc
+//
+//	@Summary		Create loyalty rule
+//	@Description	Create a rule for the points earned per 100 spent on a delivered order line, for a category or every category, from a spend tier.
+//	@Tags			loyalty
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.LoyaltyRule	true	"Loyalty rule"
+//	@Success		201		{object}	response.Response{data=response.LoyaltyRule}
+//	@Failure		400		{object}	response.Response	"Failed to bind JSON inputs from request"
+//	@Failure		400		{object}	response.Response	"Failed, referenced category not found"
+//	@Failure		500		{object}	response.Response	"Failed to create loyalty rule"
+//	@Router			/admin/loyalty/create-rule [post]
+func (lh *LoyaltyHandler) CreateLoyaltyRule(c *gin.Context) {
+	var body request.LoyaltyRule
+	if !lh.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	rule, err := lh.loyaltyUseCase.CreateLoyaltyRule(c.Request.Context(), body)
+	if err == usecase.ErrCategoryNotFound {
+		response := response.ResponseMessage(statusBadRequest, "Failed, referenced category not found", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to create loyalty rule", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusCreated, "Success, created loyalty rule", rule, nil)
+	c.JSON(statusCreated, response)
+}
+
+// UpdateLoyaltyRule godoc
+//
+//	@Summary		Update loyalty rule
+//	@Description	Update the existing loyalty rule by id.
+//	@Tags			loyalty
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			ruleID	path		int					true	"Rule ID"
+//	@Param			body	body		request.LoyaltyRule	true	"Loyalty rule"
+//	@Success		200		{object}	response.Response	"Success, loyalty rule updated"
+//	@Failure		400		{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		400		{object}	response.Response	"Failed, rule or referenced category not found"
+//	@Failure		500		{object}	response.Response	"Failed to update loyalty rule"
+//	@Router			/admin/loyalty/update-rule/{ruleID} [put]
+func (lh *LoyaltyHandler) UpdateLoyaltyRule(c *gin.Context) {
+	var body request.LoyaltyRule
+	if !lh.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	ruleID, ok := lh.subHandler.ParamInt(c, "ruleID")
+	if !ok {
+		return
+	}
+
+	err := lh.loyaltyUseCase.UpdateLoyaltyRule(c.Request.Context(), ruleID, body)
+	if err == usecase.ErrCategoryNotFound || err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, rule or referenced category not found", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to update loyalty rule", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, loyalty rule updated", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// ListLoyaltyRules godoc
+//
+//	@Summary		List loyalty rules
+//	@Description	List every loyalty rule.
+//	@Tags			loyalty
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=[]response.LoyaltyRule}
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/loyalty/all-rules [get]
+func (lh *LoyaltyHandler) ListLoyaltyRules(c *gin.Context) {
+	rules, err := lh.loyaltyUseCase.ListLoyaltyRules(c.Request.Context())
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to fetch loyalty rules", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", rules, nil)
+	c.JSON(statusOK, response)
+}
+
+// BlockLoyaltyRule godoc
+//
+//	@Summary		Block loyalty rule
+//	@Description	Stop the loyalty rule from applying.
+//	@Tags			loyalty
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			ruleID	path		int					true	"Rule ID"
+//	@Success		200		{object}	response.Response	"Success, loyalty rule blocked"
+//	@Failure		400		{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		500		{object}	response.Response	"Failed to block loyalty rule"
+//	@Router			/admin/loyalty/block-rule/{ruleID} [put]
+func (lh *LoyaltyHandler) BlockLoyaltyRule(c *gin.Context) {
+	ruleID, ok := lh.subHandler.ParamInt(c, "ruleID")
+	if !ok {
+		return
+	}
+
+	err := lh.loyaltyUseCase.BlockLoyaltyRule(c.Request.Context(), ruleID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to block loyalty rule", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, loyalty rule blocked", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// UnBlockLoyaltyRule godoc
+//
+//	@Summary		Unblock loyalty rule
+//	@Description	Let the blocked loyalty rule apply again.
+//	@Tags			loyalty
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			ruleID	path		int					true	"Rule ID"
+//	@Success		200		{object}	response.Response	"Success, loyalty rule unblocked"
+//	@Failure		400		{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		500		{object}	response.Response	"Failed to unblock loyalty rule"
+//	@Router			/admin/loyalty/unblock-rule/{ruleID} [put]
+func (lh *LoyaltyHandler) UnBlockLoyaltyRule(c *gin.Context) {
+	ruleID, ok := lh.subHandler.ParamInt(c, "ruleID")
+	if !ok {
+		return
+	}
+
+	err := lh.loyaltyUseCase.UnBlockLoyaltyRule(c.Request.Context(), ruleID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to unblock loyalty rule", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, loyalty rule unblocked", nil, nil)
+	c.JSON(statusOK, response)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationUseCase services.NotificationUseCase
+	subHandler          helper.SubHandler
+}
+
+func NewNotificationHandler(useCase services.NotificationUseCase) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUseCase: useCase,
+	}
+}
+
+// GetPreferences godoc
+//
+//	@Summary		Notification preferences
+//	@Description	Get the channels the user gets order notifications on.
+//	@Tags			profile
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.NotificationPreference}
+//	@Failure		500	{object}	response.Response	"Failed to get notification preferences"
+//	@Router			/profile/notifications [get]
+func (nh *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, _ := helper.GetIDFromContext(c)
+	preference, err := nh.notificationUseCase.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get notification preferences", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", preference, nil)
+	c.JSON(statusOK, response)
+}
+
+// UpdatePreferences godoc
+//
+//	@Summary		Update notification preferences
+//	@Description	Turn the email and sms order notifications on or off.
+//	@Tags			profile
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.NotificationPreference	true	"Channels to get notifications on"
+//	@Success		200		{object}	response.Response{data=response.NotificationPreference}
+//	@Failure		400		{object}	response.Response	"Failed to bind JSON inputs from request"
+//	@Failure		500		{object}	response.Response	"Failed to update notification preferences"
+//	@Router			/profile/notifications [put]
+func (nh *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var body request.NotificationPreference
+	if !nh.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+	preference, err := nh.notificationUseCase.UpdatePreferences(c.Request.Context(), userID, body)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to update notification preferences", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, notification preferences updated", preference, nil)
+	c.JSON(statusOK, response)
+}
+
+// GetNotifications godoc
+//
+//	@Summary		Notification history
+//	@Description	List the notifications sent to the user with their delivery status.
+//	@Tags			profile
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			page	query		int	true	"Page number"
+//	@Param			count	query		int	true	"Count of items per page"
+//	@Success		200		{object}	response.Response{data=[]response.Notification}
+//	@Failure		400		{object}	response.Response	"Failed to retrieve page info"
+//	@Failure		500		{object}	response.Response	"Failed to get notifications"
+//	@Router			/profile/notifications/history [get]
+func (nh *NotificationHandler) GetNotifications(c *gin.Context) {
+	page, count, ok := nh.subHandler.GetPageNCount(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+	notifications, err := nh.notificationUseCase.GetNotifications(c.Request.Context(), userID, page, count)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get notifications", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", notifications, nil)
+	c.JSON(statusOK, response)
+}
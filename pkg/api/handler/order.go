@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -23,10 +23,23 @@ func NewOrderHandler(useCase services.OrderUseCase) *OrderHandler {
 	}
 }
 
+// cartChanged responds with the cart issues when the order is refused because the
+// cart changed since the user reviewed it.
+func cartChanged(c *gin.Context, err error) bool {
+	validationErr, ok := err.(*usecase.CartValidationError)
+	if !ok {
+		return false
+	}
+
+	response := response.ResponseMessage(statusConflict, "Failed, cart changed, review the cart", validationErr.Issues, err.Error())
+	c.JSON(statusConflict, response)
+	return true
+}
+
 // CheckOutPage is the handler function for displaying the checkout details.
 //
 //	@Summary		Checkout page
-//	@Description	Displays the checkout details for the current user.
+//	@Description	Displays the checkout details for the current user. Items that became unavailable are removed and every change since they were added is listed in issues.
 //	@Tags			checkout
 //	@Security		Bearer
 //	@Produce		json
@@ -36,7 +49,7 @@ func NewOrderHandler(useCase services.OrderUseCase) *OrderHandler {
 func (oh *OrderHandler) CheckOutPage(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 
-	CheckOutDetails, err := oh.orderUseCase.CheckOutDetails(userID)
+	CheckOutDetails, err := oh.orderUseCase.CheckOutDetails(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed.", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -57,13 +70,17 @@ func (oh *OrderHandler) CheckOutPage(c *gin.Context) {
 //	@Produce		json
 //	@Success		200	{object}	response.Response
 //	@Failure		500	{object}	response.Response
+//	@Failure		409	{object}	response.Response{data=[]response.CartIssue}	"Failed, cart changed"
 //	@Router			/payment/cod-confirm [post]
 func (oh *OrderHandler) ConfirmCodDelivery(c *gin.Context) {
 
 	UserID, _ := helper.GetIDFromContext(c)
-	err := oh.orderUseCase.ConfirmedOrder(UserID, 1) //1 is for  payment cash on delivery
+	err := oh.orderUseCase.ConfirmedOrder(c.Request.Context(), UserID, 1) //1 is for  payment cash on delivery
 
 	if err != nil {
+		if cartChanged(c, err) {
+			return
+		}
 		response := response.ResponseMessage(500, "Failed.", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
@@ -83,11 +100,15 @@ func (oh *OrderHandler) ConfirmCodDelivery(c *gin.Context) {
 //	@Produce		json
 //	@Success		200
 //	@Failure		500	{object}	response.Response
+//	@Failure		409	{object}	response.Response{data=[]response.CartIssue}	"Failed, cart changed"
 //	@Router			/payment/online [get]
 func (oh *OrderHandler) GetOnlinePayment(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
-	PaymentDetails, err := oh.orderUseCase.GetRazorPayDetails(userID)
+	PaymentDetails, err := oh.orderUseCase.GetRazorPayDetails(c.Request.Context(), userID)
 	if err != nil {
+		if cartChanged(c, err) {
+			return
+		}
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
@@ -102,7 +123,7 @@ func (oh *OrderHandler) GetOnlinePayment(c *gin.Context) {
 	paymentDetails := response.PaymentDetails{
 		Username:        PaymentDetails.Username,
 		RazorPayOrderID: PaymentDetails.RazorPayOrderID,
-		Amount:          PaymentDetails.Amount * 100,
+		Amount:          PaymentDetails.Amount,
 	}
 
 	response := response.ResponseMessage(statusOK, "success", paymentDetails, nil)
@@ -121,6 +142,7 @@ func (oh *OrderHandler) GetOnlinePayment(c *gin.Context) {
 //	@Failure		400		{object}	response.Response
 //	@Failure		403		{object}	response.Response
 //	@Failure		500		{object}	response.Response
+//	@Failure		409		{object}	response.Response{data=[]response.CartIssue}	"Failed, cart changed"
 //	@Router			/payment/online/process [post]
 func (oh *OrderHandler) ProcessOnlinePayment(c *gin.Context) {
 	var body request.VerifyPayment
@@ -132,7 +154,7 @@ func (oh *OrderHandler) ProcessOnlinePayment(c *gin.Context) {
 
 	userId, _ := helper.GetIDFromContext(c)
 
-	err := oh.orderUseCase.VerifyRazorPayPayment(body.Signature, body.RazorpayOrderID, body.RazorPayPaymentID)
+	err := oh.orderUseCase.VerifyRazorPayPayment(c.Request.Context(), body.Signature, body.RazorpayOrderID, body.RazorPayPaymentID)
 	if err != nil {
 		response := response.ResponseMessage(403, "Failed", nil, err.Error())
 		c.JSON(http.StatusForbidden, response)
@@ -140,8 +162,11 @@ func (oh *OrderHandler) ProcessOnlinePayment(c *gin.Context) {
 
 	}
 
-	err = oh.orderUseCase.ConfirmedOrder(userId, 2) //2 is referring payment method razorpay(online)
+	err = oh.orderUseCase.ConfirmedOrder(c.Request.Context(), userId, 2) //2 is referring payment method razorpay(online)
 	if err != nil {
+		if cartChanged(c, err) {
+			return
+		}
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
@@ -180,7 +205,7 @@ func (oh *OrderHandler) UserOrderHistory(c *gin.Context) {
 
 	userId, _ := helper.GetIDFromContext(c)
 
-	orderHistory, err := oh.orderUseCase.GetUserOrderHistory(userId, page, count)
+	orderHistory, err := oh.orderUseCase.GetUserOrderHistory(c.Request.Context(), userId, page, count)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -218,7 +243,7 @@ func (oh *OrderHandler) GetOrderManagementPage(c *gin.Context) {
 		return
 	}
 
-	OrderManagementPageDatas, err := oh.orderUseCase.GetOrderManagement(page, count)
+	OrderManagementPageDatas, err := oh.orderUseCase.GetOrderManagement(c.Request.Context(), page, count)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -256,7 +281,7 @@ func (oh *OrderHandler) GetAllOrderOverViewPage(c *gin.Context) {
 		return
 	}
 
-	AllOrders, err := oh.orderUseCase.AllOrderOverView(page, count)
+	AllOrders, err := oh.orderUseCase.AllOrderOverView(c.Request.Context(), page, count)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -287,17 +312,14 @@ func (oh *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response)
 		return
 	}
-	fmt.Println(orderID)
 	statusID, err := strconv.Atoi(c.Param("statusID"))
-	fmt.Println(orderID, statusID)
 
 	if err != nil {
 		response := response.ResponseMessage(400, "Invalid entry", nil, err.Error())
 		c.JSON(http.StatusBadRequest, response)
 		return
 	}
-	fmt.Println(orderID, statusID)
-	err = oh.orderUseCase.UpdateOrderStatus(statusID, orderID)
+	err = oh.orderUseCase.UpdateOrderStatus(c.Request.Context(), statusID, orderID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -313,12 +335,14 @@ func (oh *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 //
 //	@Summary		Cancel an order
 //	@Description	Cancel the order. For online payments, the amount will be added to the user's wallet. For cash on delivery orders,will be marked as cancelled.
-//	@Description	If the user has used a coupon for the order, the discount amount will be recalculated based on the percentage used and deducted from the refunding amount.
+//	@Description	The coupon discount stored on the order at checkout is deducted from the refunding amount.
+//	@Description	Pass qty to cancel only part of the quantity, the discount is split between the cancelled and the remaining items.
 //	@Tags			user orders
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
 //	@Param			orderID	path		int	true	"Order ID"
+//	@Param			qty		query		int	false	"Quantity to cancel, above zero, whole order when omitted"
 //	@Success		200		{object}	response.Response
 //	@Failure		400		{object}	response.Response
 //	@Failure		500		{object}	response.Response
@@ -331,7 +355,23 @@ func (oh *OrderHandler) CancelOrder(c *gin.Context) {
 		return
 	}
 
-	err = oh.orderUseCase.OrderCancellation(orderID)
+	// without qty the whole line is cancelled
+	qty := math.MaxInt
+	if value, ok := c.GetQuery("qty"); ok {
+		qty, err = strconv.Atoi(value)
+		if err != nil {
+			response := response.ResponseMessage(400, "Invalid entry", nil, err.Error())
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	err = oh.orderUseCase.PartialOrderCancellation(c.Request.Context(), orderID, qty)
+	if err == usecase.ErrInvalidQuantity || err == usecase.ErrNotCancellable {
+		response := response.ResponseMessage(400, "Failed", nil, err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -346,8 +386,7 @@ func (oh *OrderHandler) CancelOrder(c *gin.Context) {
 //
 //	@Summary		Return order
 //	@Description	Return the order if the order is valid for return.Amount will be added to the user's wallet.
-//	@Description	If the user has used a coupon for the order, the discount amount will be recalculated based
-//	@Description	on the percentage used and deducted from the refunding amount.
+//	@Description	The coupon discount stored on the order at checkout is deducted from the refunding amount.
 //	@Security		Bearer
 //	@Tags			user orders
 //	@Accept			json
@@ -364,7 +403,7 @@ func (oh *OrderHandler) ReturnOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response)
 		return
 	}
-	err = oh.orderUseCase.ProcessReturnRequest(orderID)
+	err = oh.orderUseCase.ProcessReturnRequest(c.Request.Context(), orderID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -394,7 +433,7 @@ func (oh *OrderHandler) CreateInvoice(c *gin.Context) {
 		return
 	}
 
-	invoiceDetails, err := oh.orderUseCase.CreateInvoice(orderID)
+	invoiceDetails, err := oh.orderUseCase.CreateInvoice(c.Request.Context(), orderID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -419,7 +458,7 @@ func (oh *OrderHandler) CreateInvoice(c *gin.Context) {
 func (oh *OrderHandler) CreateUserWallet(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 
-	err := oh.orderUseCase.CreateUserWallet(userID)
+	err := oh.orderUseCase.CreateUserWallet(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -443,7 +482,7 @@ func (oh *OrderHandler) CreateUserWallet(c *gin.Context) {
 func (oh *OrderHandler) ViewUserWallet(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 
-	Wallet, err := oh.orderUseCase.GetUserWallet(userID)
+	Wallet, err := oh.orderUseCase.GetUserWallet(c.Request.Context(), userID)
 
 	if err == usecase.ErrNoWallet {
 		response := response.ResponseMessage(204, "user does not have wallet", nil, err.Error())
@@ -474,13 +513,12 @@ func (od *OrderHandler) WebhookHandler(c *gin.Context) {
 	switch eventType {
 
 	case "payment.authorized":
-		fmt.Println(eventType)
 		paymentID, _ := eventData["razorpay_payment_id"].(string)
-		fmt.Println("Payment authorized for Payment ID:", paymentID)
+		helper.LoggerFrom(c.Request.Context()).Info("payment authorized", "payment_id", paymentID)
 		// Payment is authorized, handle success case here
 		c.JSON(http.StatusOK, gin.H{"message": "Payment authorized"})
 	case "payment.failed":
-		fmt.Println(eventType)
+		helper.LoggerFrom(c.Request.Context()).Warn("payment failed")
 
 		// Payment failed, handle failure case here
 		c.JSON(http.StatusOK, gin.H{"message": "Payment failed"})
@@ -500,18 +538,27 @@ func (od *OrderHandler) WebhookHandler(c *gin.Context) {
 //	@Success		200	{object}	response.Response
 //	@Failure		400	{object}	response.Response
 //	@Failure		500	{object}	response.Response
+//	@Failure		409	{object}	response.Response{data=[]response.CartIssue}	"Failed, cart changed"
 //	@Router			/payment/wallet [post]
 func (od *OrderHandler) PayUsingWallet(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
-	err := od.orderUseCase.ValidateWalletPayment(userID)
+	err := od.orderUseCase.ValidateWalletPayment(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(400, "Failed", nil, err.Error())
 		c.JSON(http.StatusBadRequest, response)
 		return
 	}
 
-	err = od.orderUseCase.ConfirmedOrder(userID, 3) // 3 refers wallet payment
+	err = od.orderUseCase.ConfirmedOrder(c.Request.Context(), userID, 3) // 3 refers wallet payment
 	if err != nil {
+		if cartChanged(c, err) {
+			return
+		}
+		if err == usecase.ErrInsufficientBalance {
+			response := response.ResponseMessage(400, "Failed", nil, err.Error())
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
@@ -534,7 +581,7 @@ func (od *OrderHandler) PayUsingWallet(c *gin.Context) {
 func (od *OrderHandler) WalletTransactionHistory(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 
-	walletHistory, err := od.orderUseCase.GetWalletHistory(userID)
+	walletHistory, err := od.orderUseCase.GetWalletHistory(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to get wallet history", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -557,7 +604,7 @@ func (od *OrderHandler) WalletTransactionHistory(c *gin.Context) {
 //	@Failure		500	{object}	response.Response									"Failed to generate the sales report"
 //	@Router			/admin/sales-report [get]
 func (od *OrderHandler) MonthlySalesReport(c *gin.Context) {
-	salesReport, err := od.orderUseCase.MonthlySalesReport()
+	salesReport, err := od.orderUseCase.MonthlySalesReport(c.Request.Context())
 
 	if err == usecase.ErrNoOrders {
 		response := response.ResponseMessage(statusOK, "No orders created yet", salesReport, nil)
@@ -41,7 +41,7 @@ func (p *ProductHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := p.productUseCase.CreateCategory(body)
+	category, err := p.productUseCase.CreateCategory(c.Request.Context(), body)
 	if err != nil {
 		statusCode, msg := statusInternalServerError, "Failed to create category"
 
@@ -77,7 +77,7 @@ func (p *ProductHandler) ReadAllCategories(c *gin.Context) {
 		return
 	}
 
-	categories, err := p.productUseCase.ReadAllCategories(page, count)
+	categories, err := p.productUseCase.ReadAllCategories(c.Request.Context(), page, count)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to retrieve categories", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -109,7 +109,7 @@ func (p *ProductHandler) Categories(c *gin.Context) {
 		return
 	}
 
-	categories, err := p.productUseCase.ReadAllCategories(page, count)
+	categories, err := p.productUseCase.ReadAllCategories(c.Request.Context(), page, count)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to retrieve categories", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -147,7 +147,7 @@ func (p *ProductHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	err := p.productUseCase.UpdateCategoryByID(categoryID, body)
+	err := p.productUseCase.UpdateCategoryByID(c.Request.Context(), categoryID, body)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to update category", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -176,7 +176,7 @@ func (ph *ProductHandler) BlockCategory(c *gin.Context) {
 		return
 	}
 
-	err := ph.productUseCase.BlockCategoryByID(categoryID)
+	err := ph.productUseCase.BlockCategoryByID(c.Request.Context(), categoryID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to block category", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -206,7 +206,7 @@ func (ph *ProductHandler) UnBlockCategory(c *gin.Context) {
 		return
 	}
 
-	err := ph.productUseCase.UnBlockCategoryByID(categoryID)
+	err := ph.productUseCase.UnBlockCategoryByID(c.Request.Context(), categoryID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to block category", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -248,7 +248,7 @@ func (ph *ProductHandler) CreateProduct(c *gin.Context) {
 
 	body.CategoryID = categoryID
 
-	product, err := ph.productUseCase.CreateProduct(body)
+	product, err := ph.productUseCase.CreateProduct(c.Request.Context(), body)
 
 	if err != nil {
 		func() {
@@ -292,7 +292,7 @@ func (ph *ProductHandler) ShowProductsToAdmin(c *gin.Context) {
 		return
 	}
 
-	products, err := ph.productUseCase.DisplayAllProductsToAdmin(page, count)
+	products, err := ph.productUseCase.DisplayAllProductsToAdmin(c.Request.Context(), page, count)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to fetch products", nil, err.Error())
 		c.JSON(http.StatusServiceUnavailable, response)
@@ -331,7 +331,7 @@ func (ph *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	err := ph.productUseCase.UpdateProductByID(productID, body)
+	err := ph.productUseCase.UpdateProductByID(c.Request.Context(), productID, body)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed update product", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -361,7 +361,7 @@ func (ph *ProductHandler) BlockProduct(c *gin.Context) {
 		return
 	}
 
-	err := ph.productUseCase.BlockProductByID(productID)
+	err := ph.productUseCase.BlockProductByID(c.Request.Context(), productID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to block product", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -390,7 +390,7 @@ func (ph *ProductHandler) UnBlockProduct(c *gin.Context) {
 		return
 	}
 
-	err := ph.productUseCase.UnBlockProductByID(productID)
+	err := ph.productUseCase.UnBlockProductByID(c.Request.Context(), productID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to unblock product", nil, err)
 		c.JSON(statusInternalServerError, response)
@@ -423,7 +423,7 @@ func (ph *ProductHandler) DisplayAllProductsToUser(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	products, err := ph.productUseCase.DisplayAllProductsToUser(userID, page, count)
+	products, err := ph.productUseCase.DisplayAllProductsToUser(c.Request.Context(), userID, page, count)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to retrieve products", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -453,7 +453,7 @@ func (pd *ProductHandler) ViewIndividualProduct(c *gin.Context) {
 	}
 
 	userID, _ := helper.GetIDFromContext(c)
-	product, err := pd.productUseCase.ViewIndividualProduct(userID, productID)
+	product, err := pd.productUseCase.ViewIndividualProduct(c.Request.Context(), userID, productID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "Failed to fetch product", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -491,7 +491,7 @@ func (pd *ProductHandler) ValidateRatingRequest(c *gin.Context) {
 		return
 	}
 
-	err := pd.productUseCase.ValidateProductRatingRequest(userID, productID)
+	err := pd.productUseCase.ValidateProductRatingRequest(c.Request.Context(), userID, productID)
 	if err != nil {
 
 		status, msg := statusInternalServerError, "Failed to validate the rating request"
@@ -553,7 +553,7 @@ func (pd *ProductHandler) AddProductRating(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err = pd.productUseCase.InsertNewProductRating(userID, productID, body)
+	err = pd.productUseCase.InsertNewProductRating(c.Request.Context(), userID, productID, body)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to add rating", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -596,7 +596,7 @@ func (ph *ProductHandler) SearchProducts(c *gin.Context) {
 
 	search := c.Query("search")
 
-	Products, err := ph.productUseCase.SearchProducts(search, page, count)
+	Products, err := ph.productUseCase.SearchProducts(c.Request.Context(), search, page, count)
 	if err != nil {
 		response := response.ResponseMessage(403, "Failed", nil, err.Error())
 		c.JSON(http.StatusForbidden, response)
@@ -645,7 +645,7 @@ func (ph *ProductHandler) ListProductsByCategoryUser(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	Products, err := ph.productUseCase.GetProductsByCategoryUser(userID, categoryID, page, count)
+	Products, err := ph.productUseCase.GetProductsByCategoryUser(c.Request.Context(), userID, categoryID, page, count)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusBadRequest, response)
@@ -692,7 +692,7 @@ func (ph *ProductHandler) ListProductsByCategoryAdmin(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response)
 	}
 
-	Products, err := ph.productUseCase.GetProductsByCategoryAdmin(categoryID, page, count)
+	Products, err := ph.productUseCase.GetProductsByCategoryAdmin(c.Request.Context(), categoryID, page, count)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusBadRequest, response)
@@ -738,7 +738,7 @@ func (ad *ProductHandler) UploadCategoryImage(c *gin.Context) {
 	}
 
 	// Upload the file to specific dst.
-	err = ad.productUseCase.UploadCategoryImage(files, categoryID)
+	err = ad.productUseCase.UploadCategoryImage(c.Request.Context(), files, categoryID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "failed to upload files", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -785,7 +785,7 @@ func (ad *ProductHandler) UploadProductImages(c *gin.Context) {
 	}
 
 	// Upload the file to specific dst.
-	err = ad.productUseCase.UploadProductImage(files, productID)
+	err = ad.productUseCase.UploadProductImage(c.Request.Context(), files, productID)
 	if err != nil {
 		response := response.ResponseMessage(statusInternalServerError, "failed to upload file", nil, err.Error())
 		c.JSON(statusInternalServerError, response)
@@ -816,7 +816,7 @@ func (ph *ProductHandler) AddToWishList(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err = ph.productUseCase.AddToWishList(userID, productID)
+	err = ph.productUseCase.AddToWishList(c.Request.Context(), userID, productID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -847,7 +847,7 @@ func (ph *ProductHandler) RemoveFromWishList(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err = ph.productUseCase.RemoveFromWishList(userID, productID)
+	err = ph.productUseCase.RemoveFromWishList(c.Request.Context(), userID, productID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -885,7 +885,7 @@ func (ph *ProductHandler) ShowWishListProducts(c *gin.Context) {
 		return
 	}
 
-	products, err := ph.productUseCase.ShowWishListProducts(userID, page, count)
+	products, err := ph.productUseCase.ShowWishListProducts(c.Request.Context(), userID, page, count)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	promotionUseCase services.PromotionUseCase
+	subHandler       helper.SubHandler
+}
+
+func NewPromotionHandler(useCase services.PromotionUseCase) *PromotionHandler {
+	return &PromotionHandler{
+		promotionUseCase: useCase,
+	}
+}
+
+// CreatePromotion godoc
+//
+//	@Summary		Create promotion
+//	@Description	Create an automatic promotion, a category wide sale or a flash sale, applied without a coupon code between its start and end time.
+//	@Tags			promotions
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.Promotion	true	"Promotion details"
+//	@Success		201		{object}	response.Response{data=response.Promotion}
+//	@Failure		400		{object}	response.Response	"Failed to bind JSON inputs from request"
+//	@Failure		400		{object}	response.Response	"Failed, referenced category or product not found"
+//	@Failure		500		{object}	response.Response	"Failed to create promotion"
+//	@Router			/admin/promotions/create-promotion [post]
+func (ph *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var body request.Promotion
+	if !ph.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	promotion, err := ph.promotionUseCase.CreatePromotion(c.Request.Context(), body)
+	if err == usecase.ErrCategoryNotFound || err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, referenced category or product not found", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to create promotion", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusCreated, "Success, created new promotion", promotion, nil)
+	c.JSON(statusCreated, response)
+}
+
+// UpdatePromotion godoc
+//
+//	@Summary		Update promotion
+//	@Description	Update the existing promotion by id.
+//	@Tags			promotions
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			promotionID	path		int					true	"Promotion ID"
+//	@Param			body		body		request.Promotion	true	"Promotion details"
+//	@Success		200			{object}	response.Response	"Success, promotion updated"
+//	@Failure		400			{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		400			{object}	response.Response	"Failed, referenced category or product not found"
+//	@Failure		500			{object}	response.Response	"Failed to update promotion"
+//	@Router			/admin/promotions/update-promotion/{promotionID} [put]
+func (ph *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	var body request.Promotion
+	if !ph.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	promotionID, ok := ph.subHandler.ParamInt(c, "promotionID")
+	if !ok {
+		return
+	}
+
+	err := ph.promotionUseCase.UpdatePromotion(c.Request.Context(), promotionID, body)
+	if err == usecase.ErrCategoryNotFound || err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, referenced category or product not found", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to update promotion", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, promotion updated", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// ListPromotions godoc
+//
+//	@Summary		List promotions
+//	@Description	List out every promotion with its active state.
+//	@Tags			promotions
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=[]response.Promotion}
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/promotions/all-promotions [get]
+func (ph *PromotionHandler) ListPromotions(c *gin.Context) {
+	promotions, err := ph.promotionUseCase.ListPromotions(c.Request.Context())
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to fetch promotions", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", promotions, nil)
+	c.JSON(statusOK, response)
+}
+
+// BlockPromotion godoc
+//
+//	@Summary		Block promotion
+//	@Description	Stop the promotion from applying.
+//	@Tags			promotions
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			promotionID	path		int					true	"Promotion ID"
+//	@Success		200			{object}	response.Response	"Success, promotion blocked"
+//	@Failure		400			{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		500			{object}	response.Response	"Failed to block promotion"
+//	@Router			/admin/promotions/block-promotion/{promotionID} [put]
+func (ph *PromotionHandler) BlockPromotion(c *gin.Context) {
+	promotionID, ok := ph.subHandler.ParamInt(c, "promotionID")
+	if !ok {
+		return
+	}
+
+	err := ph.promotionUseCase.BlockPromotion(c.Request.Context(), promotionID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to block promotion", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, promotion blocked", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// UnBlockPromotion godoc
+//
+//	@Summary		Unblock promotion
+//	@Description	Let the blocked promotion apply again within its start and end time.
+//	@Tags			promotions
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			promotionID	path		int					true	"Promotion ID"
+//	@Success		200			{object}	response.Response	"Success, promotion unblocked"
+//	@Failure		400			{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		500			{object}	response.Response	"Failed to unblock promotion"
+//	@Router			/admin/promotions/unblock-promotion/{promotionID} [put]
+func (ph *PromotionHandler) UnBlockPromotion(c *gin.Context) {
+	promotionID, ok := ph.subHandler.ParamInt(c, "promotionID")
+	if !ok {
+		return
+	}
+
+	err := ph.promotionUseCase.UnBlockPromotion(c.Request.Context(), promotionID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to unblock promotion", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, promotion unblocked", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// SchedulePriceChange godoc
+//
+//	@Summary		Schedule price change
+//	@Description	Schedule a new price for a product. The price applies at starts_at and, when ends_at is given, the previous price is restored at ends_at.
+//	@Tags			promotions
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.ScheduledPrice	true	"Price change"
+//	@Success		201		{object}	response.Response{data=response.ScheduledPrice}
+//	@Failure		400		{object}	response.Response	"Failed to bind JSON inputs from request"
+//	@Failure		400		{object}	response.Response	"Failed, product not found"
+//	@Failure		500		{object}	response.Response	"Failed to schedule price change"
+//	@Router			/admin/promotions/schedule-price [post]
+func (ph *PromotionHandler) SchedulePriceChange(c *gin.Context) {
+	var body request.ScheduledPrice
+	if !ph.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	schedule, err := ph.promotionUseCase.SchedulePriceChange(c.Request.Context(), body)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, product not found", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to schedule price change", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusCreated, "Success, price change scheduled", schedule, nil)
+	c.JSON(statusCreated, response)
+}
+
+// ListScheduledPrices godoc
+//
+//	@Summary		List scheduled prices
+//	@Description	List out the scheduled price changes with their status.
+//	@Tags			promotions
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=[]response.ScheduledPrice}
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/promotions/scheduled-prices [get]
+func (ph *PromotionHandler) ListScheduledPrices(c *gin.Context) {
+	schedules, err := ph.promotionUseCase.ListScheduledPrices(c.Request.Context())
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to fetch scheduled prices", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", schedules, nil)
+	c.JSON(statusOK, response)
+}
+
+// CancelScheduledPrice godoc
+//
+//	@Summary		Cancel scheduled price
+//	@Description	Cancel a scheduled price change that has not started yet.
+//	@Tags			promotions
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			scheduleID	path		int					true	"Schedule ID"
+//	@Success		200			{object}	response.Response	"Success, price change cancelled"
+//	@Failure		400			{object}	response.Response	"Failed to retrieve param from URL"
+//	@Failure		400			{object}	response.Response	"Failed, no pending price change"
+//	@Failure		500			{object}	response.Response
+//	@Router			/admin/promotions/scheduled-prices/{scheduleID} [delete]
+func (ph *PromotionHandler) CancelScheduledPrice(c *gin.Context) {
+	scheduleID, ok := ph.subHandler.ParamInt(c, "scheduleID")
+	if !ok {
+		return
+	}
+
+	err := ph.promotionUseCase.CancelScheduledPrice(c.Request.Context(), scheduleID)
+	if err == usecase.ErrNoRecord {
+		response := response.ResponseMessage(statusBadRequest, "Failed, no pending price change", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to cancel price change", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, price change cancelled", nil, nil)
+	c.JSON(statusOK, response)
+}
@@ -31,11 +31,15 @@ func NewRazorpayHandler(razorpayUseCase services.RazorpayUseCase, orderUseCase s
 //	@Produce		json
 //	@Success		200
 //	@Failure		500	{object}	response.Response
+//	@Failure		409	{object}	response.Response{data=[]response.CartIssue}	"Failed, cart changed"
 //	@Router			/payment/online [get]
 func (oh *RazorpayHandler) GetOnlinePayment(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
-	PaymentDetails, err := oh.razorpayUseCase.GetRazorPayDetails(userID)
+	PaymentDetails, err := oh.razorpayUseCase.GetRazorPayDetails(c.Request.Context(), userID)
 	if err != nil {
+		if cartChanged(c, err) {
+			return
+		}
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
@@ -50,7 +54,7 @@ func (oh *RazorpayHandler) GetOnlinePayment(c *gin.Context) {
 	paymentDetails := response.PaymentDetails{
 		Username:        PaymentDetails.Username,
 		RazorPayOrderID: PaymentDetails.RazorPayOrderID,
-		Amount:          PaymentDetails.Amount * 100,
+		Amount:          PaymentDetails.Amount,
 	}
 
 	response := response.ResponseMessage(statusOK, "success", paymentDetails, nil)
@@ -69,6 +73,7 @@ func (oh *RazorpayHandler) GetOnlinePayment(c *gin.Context) {
 //	@Failure		400		{object}	response.Response
 //	@Failure		403		{object}	response.Response
 //	@Failure		500		{object}	response.Response
+//	@Failure		409		{object}	response.Response{data=[]response.CartIssue}	"Failed, cart changed"
 //	@Router			/payment/online/process [post]
 func (oh *RazorpayHandler) ProcessOnlinePayment(c *gin.Context) {
 	var body request.VerifyPayment
@@ -80,7 +85,7 @@ func (oh *RazorpayHandler) ProcessOnlinePayment(c *gin.Context) {
 
 	userId, _ := helper.GetIDFromContext(c)
 
-	err := oh.razorpayUseCase.VerifyRazorPayPayment(body.Signature, body.RazorpayOrderID, body.RazorPayPaymentID)
+	err := oh.razorpayUseCase.VerifyRazorPayPayment(c.Request.Context(), body.Signature, body.RazorpayOrderID, body.RazorPayPaymentID)
 	if err != nil {
 		response := response.ResponseMessage(403, "Failed", nil, err.Error())
 		c.JSON(http.StatusForbidden, response)
@@ -88,8 +93,11 @@ func (oh *RazorpayHandler) ProcessOnlinePayment(c *gin.Context) {
 
 	}
 
-	err = oh.orderUseCase.ConfirmedOrder(userId, 2) //2 is referring payment method razorpay(online)
+	err = oh.orderUseCase.ConfirmedOrder(c.Request.Context(), userId, 2) //2 is referring payment method razorpay(online)
 	if err != nil {
+		if cartChanged(c, err) {
+			return
+		}
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
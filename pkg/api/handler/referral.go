@@ -15,6 +15,9 @@ type ReferralHandler struct {
 	helper.SubHandler
 }
 
+// deviceIDHeader carries the client device id used by the referral fraud checks.
+const deviceIDHeader = "X-Device-ID"
+
 // for wire
 func NewReferralHandler(useCase services.ReferralUseCase) *ReferralHandler {
 	return &ReferralHandler{
@@ -29,12 +32,13 @@ func NewReferralHandler(useCase services.ReferralUseCase) *ReferralHandler {
 //	@Tags			referral
 //	@Security		Bearer
 //	@Produce		json
-//	@Success		200	{object}	response.Response	"Success."
-//	@Failure		500	{object}	response.Response	"Failed."
+//	@Param			X-Device-ID	header		string				false	"Client device id"
+//	@Success		200			{object}	response.Response	"Success."
+//	@Failure		500			{object}	response.Response	"Failed."
 //	@Router			/referral/get-code [get]
 func (rh *ReferralHandler) GetReferralCode(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
-	referralCode, err := rh.referralUseCase.GetUserReferralCode(userID)
+	referralCode, err := rh.referralUseCase.GetUserReferralCode(c.Request.Context(), userID, c.GetHeader(deviceIDHeader))
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed.", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -49,14 +53,15 @@ func (rh *ReferralHandler) GetReferralCode(c *gin.Context) {
 // ApplyReferralCode godoc
 //
 //	@Summary		Apply referral code for referral bonus.
-//	@Description	Apply a  referral code  to get wallet money,
+//	@Description	Apply a  referral code  to get wallet money, the bonus is credited to both wallets after the first delivered order. A user can claim only one code, before the first order.
 //	@Tags			referral
 //	@Security		Bearer
 //	@Accept			json
 //	@Produce		json
 //	@Param			referral code	body	request.Referral	true	"Referral code to apply"
+//	@Param			X-Device-ID		header	string				false	"Client device id"
 //	@Security		ApiKeyAuth
-//	@Success		200	{object}	response.Response	"Success, bonus amount updated in wallet."
+//	@Success		200	{object}	response.Response	"Success, referral claimed. Bonus will be credited after your first delivered order."
 //	@Failure		400	{object}	response.Response	"Invalid Input."
 //	@Failure		403	{object}	response.Response	"Failed."
 //	@Failure		500	{object}	response.Response	"Failed."
@@ -71,7 +76,8 @@ func (rh *ReferralHandler) ApplyReferralCode(c *gin.Context) {
 
 	
 	userID, _ := helper.GetIDFromContext(c)
-	codeOwnerID, err := rh.referralUseCase.VerifyReferralCode(body.Code, userID)
+	deviceID := c.GetHeader(deviceIDHeader)
+	codeOwnerID, err := rh.referralUseCase.VerifyReferralCode(c.Request.Context(), body.Code, userID, deviceID)
 
 	if err != nil {
 		response := response.ResponseMessage(400, "Failed.", nil, err.Error())
@@ -79,7 +85,7 @@ func (rh *ReferralHandler) ApplyReferralCode(c *gin.Context) {
 		return
 	}
 
-	err = rh.referralUseCase.ClaimReferralBonus(userID, codeOwnerID)
+	err = rh.referralUseCase.ClaimReferralBonus(c.Request.Context(), userID, codeOwnerID, deviceID)
 
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed.", nil, err.Error())
@@ -87,7 +93,82 @@ func (rh *ReferralHandler) ApplyReferralCode(c *gin.Context) {
 		return
 	}
 
-	response := response.ResponseMessage(200, "Success, bonus amount updated in wallet.", nil, nil)
+	response := response.ResponseMessage(200, "Success, referral claimed. Bonus will be credited after your first delivered order.", nil, nil)
 	c.JSON(http.StatusOK, response)
 
 }
+
+// GetReferralStats godoc
+//
+//	@Summary		Referral stats
+//	@Description	Get the referrals made with the code of the current user and the rewards earned.
+//	@Tags			referral
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.ReferralStats}
+//	@Failure		500	{object}	response.Response	"Failed to get referral stats"
+//	@Router			/referral/stats [get]
+func (rh *ReferralHandler) GetReferralStats(c *gin.Context) {
+	userID, _ := helper.GetIDFromContext(c)
+	stats, err := rh.referralUseCase.GetReferralStats(c.Request.Context(), userID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get referral stats", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", stats, nil)
+	c.JSON(statusOK, response)
+}
+
+// GetReferralProgramme godoc
+//
+//	@Summary		Referral programme
+//	@Description	Get the reward amounts of the referral programme.
+//	@Tags			referral
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.ReferralProgramme}
+//	@Failure		500	{object}	response.Response	"Failed to get referral programme"
+//	@Router			/admin/referral/programme [get]
+func (rh *ReferralHandler) GetReferralProgramme(c *gin.Context) {
+	programme, err := rh.referralUseCase.GetReferralProgramme(c.Request.Context())
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get referral programme", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", programme, nil)
+	c.JSON(statusOK, response)
+}
+
+// UpdateReferralProgramme godoc
+//
+//	@Summary		Update referral programme
+//	@Description	Update the reward amounts of the referral programme. Existing claims keep the rewards they were claimed with.
+//	@Tags			referral
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.ReferralProgramme	true	"Referral programme"
+//	@Success		200		{object}	response.Response{data=response.ReferralProgramme}
+//	@Failure		400		{object}	response.Response	"Failed to bind JSON inputs from request"
+//	@Failure		500		{object}	response.Response	"Failed to update referral programme"
+//	@Router			/admin/referral/programme [put]
+func (rh *ReferralHandler) UpdateReferralProgramme(c *gin.Context) {
+	var body request.ReferralProgramme
+	if !rh.BindRequest(c, &body) {
+		return
+	}
+
+	programme, err := rh.referralUseCase.UpdateReferralProgramme(c.Request.Context(), body)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to update referral programme", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, referral programme updated", programme, nil)
+	c.JSON(statusOK, response)
+}
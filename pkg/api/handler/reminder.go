@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+type ReminderHandler struct {
+	reminderUseCase services.ReminderUseCase
+}
+
+func NewReminderHandler(useCase services.ReminderUseCase) *ReminderHandler {
+	return &ReminderHandler{
+		reminderUseCase: useCase,
+	}
+}
+
+// Unsubscribe godoc
+//
+//	@Summary		Unsubscribe from cart reminders
+//	@Description	Stop the abandoned cart reminders, using the signed link sent in the reminder.
+//	@Tags			cart
+//	@Produce		json
+//	@Param			token	query		string				true	"Unsubscribe token from the reminder"
+//	@Success		200		{object}	response.Response	"Success, unsubscribed from cart reminders"
+//	@Failure		400		{object}	response.Response	"Failed, invalid or expired link"
+//	@Failure		500		{object}	response.Response	"Failed to unsubscribe"
+//	@Router			/cart-reminders/unsubscribe [get]
+func (rh *ReminderHandler) Unsubscribe(c *gin.Context) {
+	err := rh.reminderUseCase.Unsubscribe(c.Request.Context(), c.Query("token"))
+	if err == usecase.ErrInvalidToken {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid or expired link", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to unsubscribe", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, unsubscribed from cart reminders", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// GetAbandonedCartReport godoc
+//
+//	@Summary		Abandoned cart report
+//	@Description	Carts abandoned in the period with their value, the carts recovered by an order and the recovery rate. Defaults to the last 30 days.
+//	@Tags			sales-report
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			from	query		string	false	"Start date, YYYY-MM-DD"
+//	@Param			to		query		string	false	"End date inclusive, YYYY-MM-DD"
+//	@Success		200		{object}	response.Response{data=response.AbandonedCartReport}
+//	@Failure		400		{object}	response.Response	"Failed, dates must be YYYY-MM-DD"
+//	@Failure		500		{object}	response.Response	"Failed to get abandoned cart report"
+//	@Router			/admin/reports/abandoned-carts [get]
+func (rh *ReminderHandler) GetAbandonedCartReport(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := rh.reminderUseCase.GetAbandonedCartReport(c.Request.Context(), from, to)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get abandoned cart report", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", report, nil)
+	c.JSON(statusOK, response)
+}
+
+// parseDateRange reads the optional from and to dates of the query, to is
+// moved to the end of its day. It responds with 400 when a date is invalid.
+func parseDateRange(c *gin.Context) (from, to time.Time, ok bool) {
+	var err error
+	if value := c.Query("from"); value != "" {
+		from, err = time.ParseInLocation(dateLayout, value, time.Local)
+	}
+	if value := c.Query("to"); err == nil && value != "" {
+		to, err = time.ParseInLocation(dateLayout, value, time.Local)
+		to = to.AddDate(0, 0, 1)
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusBadRequest, "Failed, dates must be YYYY-MM-DD", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
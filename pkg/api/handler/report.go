@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	reportUseCase services.ReportUseCase
+}
+
+func NewReportHandler(useCase services.ReportUseCase) *ReportHandler {
+	return &ReportHandler{
+		reportUseCase: useCase,
+	}
+}
+
+// GetSalesAnalytics godoc
+//
+//	@Summary		Sales analytics
+//	@Description	Net revenue, orders, average order value and units of the period per day, week or month, with the top products, categories and brands, the payment method mix, coupon effectiveness and new versus returning customers. Defaults to the last 30 days by day.
+//	@Tags			sales-report
+//	@Security		Bearer
+//	@Produce		json
+//	@Param			from		query		string	false	"Start date, YYYY-MM-DD"
+//	@Param			to			query		string	false	"End date inclusive, YYYY-MM-DD"
+//	@Param			granularity	query		string	false	"day, week or month"
+//	@Param			top			query		int		false	"Entries in the top rankings, default 5, at most 50"
+//	@Success		200			{object}	response.Response{data=response.SalesAnalytics}
+//	@Failure		400			{object}	response.Response	"Failed, invalid report period"
+//	@Failure		500			{object}	response.Response	"Failed to get sales analytics"
+//	@Router			/admin/reports/sales [get]
+func (rh *ReportHandler) GetSalesAnalytics(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	report := request.SalesReport{
+		From:        from,
+		To:          to,
+		Granularity: c.Query("granularity"),
+	}
+	if value := c.Query("top"); value != "" {
+		top, err := strconv.Atoi(value)
+		if err != nil {
+			response := response.ResponseMessage(statusBadRequest, "Failed, top must be a number", nil, err.Error())
+			c.JSON(statusBadRequest, response)
+			return
+		}
+		report.Top = top
+	}
+
+	analytics, err := rh.reportUseCase.GetSalesAnalytics(c.Request.Context(), report)
+	if err == usecase.ErrInvalidGranularity || err == usecase.ErrInvalidPeriod {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid report period", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get sales analytics", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", analytics, nil)
+	c.JSON(statusOK, response)
+}
+
+// ExportSales godoc
+//
+//	@Summary		Export sales, tax or refunds report
+//	@Description	Download the sales, tax or refunds report of the period as csv, xlsx or pdf, a summary followed by every order line. Defaults to the last 30 days. The tax report splits the tax included in the prices per shipping state, the refunds report lists the payments refunded for orders cancelled or returned in the period.
+//	@Tags			sales-report
+//	@Security		Bearer
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Produce		application/pdf
+//	@Param			report	query		string	false	"sales (default), tax or refunds"
+//	@Param			format	query		string	false	"csv (default), xlsx or pdf"
+//	@Param			from	query		string	false	"Start date, YYYY-MM-DD"
+//	@Param			to		query		string	false	"End date inclusive, YYYY-MM-DD"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	response.Response	"Failed, invalid export"
+//	@Router			/admin/reports/sales/export [get]
+func (rh *ReportHandler) ExportSales(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	export := request.SalesExport{
+		Report: c.DefaultQuery("report", "sales"),
+		Format: c.DefaultQuery("format", "csv"),
+		From:   from,
+		To:     to,
+	}
+	contentType, ok := helper.ReportFormats[export.Format]
+	if !ok {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid export", nil, "format must be csv, xlsx or pdf")
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if !salesExportReports[export.Report] {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid export", nil, usecase.ErrInvalidReport.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		response := response.ResponseMessage(statusBadRequest, "Failed, invalid export", nil, usecase.ErrInvalidPeriod.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-report-%s.%s"`, export.Report, time.Now().Format("20060102"), export.Format))
+	c.Status(statusOK)
+	clearWriteDeadline(c)
+
+	// the report is streamed, an error after the first rows can only end the download
+	if err := rh.reportUseCase.ExportSales(c.Request.Context(), export, c.Writer); err != nil {
+		helper.LoggerFrom(c.Request.Context()).Error("sales export ended", "report", export.Report, "format", export.Format, "error", err)
+		c.Abort()
+	}
+}
+
+// salesExportReports are the reports ExportSales can download.
+var salesExportReports = map[string]bool{"sales": true, "tax": true, "refunds": true}
+
+// clearWriteDeadline lifts the HTTP_WRITE_TIMEOUT of a streamed download, a
+// large export would otherwise be cut off once the timeout passes.
+func clearWriteDeadline(c *gin.Context) {
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		helper.LoggerFrom(c.Request.Context()).Warn("write deadline not cleared", "error", err)
+	}
+}
@@ -10,4 +10,6 @@ const (
 	statusConflict            = http.StatusConflict
 	statusBadRequest          = http.StatusBadRequest
 	statusCreated             = http.StatusCreated
+	statusTooManyRequests     = http.StatusTooManyRequests
+	statusServiceUnavailable  = http.StatusServiceUnavailable
 )
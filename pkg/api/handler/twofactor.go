@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	request "github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUseCase services.TwoFactorUseCase
+	subHandler       helper.SubHandler
+}
+
+func NewTwoFactorHandler(useCase services.TwoFactorUseCase) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorUseCase: useCase,
+	}
+}
+
+// twoFactorFailed writes the response of a failed two factor step, it returns false
+// when there is no error.
+func twoFactorFailed(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	if loginLocked(c, err) {
+		return true
+	}
+
+	switch err {
+	case usecase.ErrInvalidToken:
+		response := response.ResponseMessage(statusUnauthorized, "Failed, invalid or expired challenge, log in again", nil, err.Error())
+		c.JSON(statusUnauthorized, response)
+	case usecase.ErrInvalidTwoFactorCode:
+		response := response.ResponseMessage(statusUnauthorized, "Failed, invalid two factor code", nil, err.Error())
+		c.JSON(statusUnauthorized, response)
+	case usecase.ErrTwoFactorEnabled:
+		response := response.ResponseMessage(statusConflict, "Failed, two factor authentication is already enabled", nil, err.Error())
+		c.JSON(statusConflict, response)
+	case usecase.ErrNoRecord:
+		response := response.ResponseMessage(statusBadRequest, "Failed, no pending two factor enrolment", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+	case usecase.ErrTwoFactorNotEnabled, usecase.ErrTwoFactorRequired:
+		response := response.ResponseMessage(statusBadRequest, "Failed", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+	default:
+		response := response.ResponseMessage(statusInternalServerError, "Failed to verify two factor code", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+	}
+	return true
+}
+
+// userSubject is the two factor subject of the logged in user.
+func userSubject(c *gin.Context) string {
+	userID, _ := helper.GetIDFromContext(c)
+	return usecase.UserSubject(userID)
+}
+
+// GetTwoFactorStatus godoc
+//
+//	@Summary		Two factor status
+//	@Description	Tells if two factor authentication is enabled and how many recovery codes are left.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.TwoFactorStatus}
+//	@Failure		500	{object}	response.Response	"Failed to get two factor status"
+//	@Router			/profile/2fa [get]
+func (th *TwoFactorHandler) GetTwoFactorStatus(c *gin.Context) {
+	th.status(c, userSubject(c))
+}
+
+// GetAdminTwoFactorStatus godoc
+//
+//	@Summary		Admin two factor status
+//	@Description	Tells when the admin enrolled and how many recovery codes are left.
+//	@Tags			auth
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.TwoFactorStatus}
+//	@Failure		500	{object}	response.Response	"Failed to get two factor status"
+//	@Router			/admin/2fa [get]
+func (th *TwoFactorHandler) GetAdminTwoFactorStatus(c *gin.Context) {
+	th.status(c, usecase.AdminSubject)
+}
+
+func (th *TwoFactorHandler) status(c *gin.Context, subject string) {
+	status, err := th.twoFactorUseCase.GetStatus(c.Request.Context(), subject)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to get two factor status", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success", status, nil)
+	c.JSON(statusOK, response)
+}
+
+// BeginEnrolment godoc
+//
+//	@Summary		Start two factor enrolment
+//	@Description	Returns a new secret and its provisioning uri, show the uri as a QR code to add the account to an authenticator app. The enrolment is enabled by /profile/2fa/enable.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=response.TwoFactorEnrolment}
+//	@Failure		409	{object}	response.Response	"Failed, two factor authentication is already enabled"
+//	@Failure		500	{object}	response.Response	"Failed to start two factor enrolment"
+//	@Router			/profile/2fa/enroll [post]
+func (th *TwoFactorHandler) BeginEnrolment(c *gin.Context) {
+	enrolment, err := th.twoFactorUseCase.BeginEnrolment(c.Request.Context(), userSubject(c))
+	if err == usecase.ErrTwoFactorEnabled {
+		twoFactorFailed(c, err)
+		return
+	}
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to start two factor enrolment", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, add the account to the authenticator app", enrolment, nil)
+	c.JSON(statusOK, response)
+}
+
+// EnableTwoFactor godoc
+//
+//	@Summary		Enable two factor authentication
+//	@Description	Completes the enrolment with a code of the authenticator app. The recovery codes are returned, they are shown only once.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.TwoFactorCode	true	"Code of the authenticator app"
+//	@Success		200		{object}	response.Response{data=response.RecoveryCodes}
+//	@Failure		400		{object}	response.Response	"Failed, no pending two factor enrolment"
+//	@Failure		401		{object}	response.Response	"Failed, invalid two factor code"
+//	@Failure		429		{object}	response.Response	"Failed, too many failed logins"
+//	@Router			/profile/2fa/enable [post]
+func (th *TwoFactorHandler) EnableTwoFactor(c *gin.Context) {
+	var body request.TwoFactorCode
+	if !th.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	codes, err := th.twoFactorUseCase.Enable(c.Request.Context(), userSubject(c), body.Code, c.ClientIP())
+	if twoFactorFailed(c, err) {
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, two factor authentication enabled", response.RecoveryCodes{Codes: codes}, nil)
+	c.JSON(statusOK, response)
+}
+
+// DisableTwoFactor godoc
+//
+//	@Summary		Disable two factor authentication
+//	@Description	Removes the two factor authentication of the user, confirmed by a code of the app or a recovery code.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.TwoFactorCode	true	"Code of the authenticator app or a recovery code"
+//	@Success		200		{object}	response.Response	"Success, two factor authentication disabled"
+//	@Failure		400		{object}	response.Response	"Failed"
+//	@Failure		401		{object}	response.Response	"Failed, invalid two factor code"
+//	@Failure		429		{object}	response.Response	"Failed, too many failed logins"
+//	@Router			/profile/2fa/disable [post]
+func (th *TwoFactorHandler) DisableTwoFactor(c *gin.Context) {
+	var body request.TwoFactorCode
+	if !th.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	err := th.twoFactorUseCase.Disable(c.Request.Context(), userSubject(c), body.Code, c.ClientIP())
+	if twoFactorFailed(c, err) {
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, two factor authentication disabled", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replaces all the recovery codes of the user, confirmed by a code of the app or a recovery code.
+//	@Tags			user profile
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.TwoFactorCode	true	"Code of the authenticator app or a recovery code"
+//	@Success		200		{object}	response.Response{data=response.RecoveryCodes}
+//	@Failure		400		{object}	response.Response	"Failed"
+//	@Failure		401		{object}	response.Response	"Failed, invalid two factor code"
+//	@Failure		429		{object}	response.Response	"Failed, too many failed logins"
+//	@Router			/profile/2fa/recovery-codes [post]
+func (th *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	th.regenerate(c, userSubject(c))
+}
+
+// RegenerateAdminRecoveryCodes godoc
+//
+//	@Summary		Regenerate admin recovery codes
+//	@Description	Replaces all the recovery codes of the admin, confirmed by a code of the app or a recovery code.
+//	@Tags			auth
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.TwoFactorCode	true	"Code of the authenticator app or a recovery code"
+//	@Success		200		{object}	response.Response{data=response.RecoveryCodes}
+//	@Failure		401		{object}	response.Response	"Failed, invalid two factor code"
+//	@Failure		429		{object}	response.Response	"Failed, too many failed logins"
+//	@Router			/admin/2fa/recovery-codes [post]
+func (th *TwoFactorHandler) RegenerateAdminRecoveryCodes(c *gin.Context) {
+	th.regenerate(c, usecase.AdminSubject)
+}
+
+func (th *TwoFactorHandler) regenerate(c *gin.Context, subject string) {
+	var body request.TwoFactorCode
+	if !th.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	codes, err := th.twoFactorUseCase.RegenerateRecoveryCodes(c.Request.Context(), subject, body.Code, c.ClientIP())
+	if twoFactorFailed(c, err) {
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, recovery codes replaced", response.RecoveryCodes{Codes: codes}, nil)
+	c.JSON(statusOK, response)
+}
@@ -37,7 +37,7 @@ var passwordManager = helper.NewPasswordManager()
 //	@Failure		500	{object}	response.Response
 //	@Router			/profile/add-address [get]
 func (u *UserHandler) GetAddAddressPage(c *gin.Context) {
-	listOfStates, err := u.userUseCase.DisplayListOfStates()
+	listOfStates, err := u.userUseCase.DisplayListOfStates(c.Request.Context())
 	if err != nil {
 		response := response.ResponseMessage(500, "No states found", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -71,7 +71,7 @@ func (uh *UserHandler) AddAddress(c *gin.Context) {
 
 	userId, _ := helper.GetIDFromContext(c)
 
-	err := uh.userUseCase.AddNewAddress(userId, body)
+	err := uh.userUseCase.AddNewAddress(c.Request.Context(), userId, body)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to add address", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -113,7 +113,7 @@ func (uh *UserHandler) UpdateAddress(c *gin.Context) {
 
 	userId, _ := helper.GetIDFromContext(c)
 
-	err = uh.userUseCase.UpdateUserAddress(body, addressID, userId)
+	err = uh.userUseCase.UpdateUserAddress(c.Request.Context(), body, addressID, userId)
 	if err != nil {
 		response := response.ResponseMessage(500, "Update address failed", nil, err.Error())
 		c.JSON(http.StatusBadRequest, response)
@@ -143,7 +143,7 @@ func (uh *UserHandler) DeleteAddress(c *gin.Context) {
 		return
 	}
 
-	err = uh.userUseCase.DeleteUserAddress(addressID)
+	err = uh.userUseCase.DeleteUserAddress(c.Request.Context(), addressID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to delete address", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -167,7 +167,7 @@ func (uh *UserHandler) DeleteAddress(c *gin.Context) {
 func (uh *UserHandler) GetAllAddresses(c *gin.Context) {
 	userId, _ := helper.GetIDFromContext(c)
 
-	ListOfAddresses, err := uh.userUseCase.GetUserAddresses(userId)
+	ListOfAddresses, err := uh.userUseCase.GetUserAddresses(c.Request.Context(), userId)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -191,7 +191,7 @@ func (uh *UserHandler) GetAllAddresses(c *gin.Context) {
 func (uh *UserHandler) Profile(c *gin.Context) {
 	userId, _ := helper.GetIDFromContext(c)
 
-	UserProfile, err := uh.userUseCase.GetProfile(userId)
+	UserProfile, err := uh.userUseCase.GetProfile(c.Request.Context(), userId)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -226,7 +226,7 @@ func (uh *UserHandler) ChangePasswordRequest(c *gin.Context) {
 
 	userId, _ := helper.GetIDFromContext(c)
 
-	err := uh.userUseCase.CheckUserOldPassword(body, userId)
+	err := uh.userUseCase.CheckUserOldPassword(c.Request.Context(), body, userId)
 	if err != nil {
 		response := response.ResponseMessage(400, "Failed to change user password", nil, err.Error())
 		c.JSON(http.StatusBadRequest, response)
@@ -265,7 +265,10 @@ func (uh *UserHandler) ChangePassword(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 	ok := passwordManager.Check(body.UUID, userID)
 
-	err := uh.userUseCase.ChangeUserPassword(body, userID, c)
+	err := uh.userUseCase.ChangeUserPassword(c.Request.Context(), body, userID, c)
+	if ok && passwordRefused(c, err) {
+		return
+	}
 	if err != nil || !ok {
 		var errr interface{}
 		if !ok {
@@ -307,7 +310,7 @@ func (uh *UserHandler) SetDefaultAddress(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err = uh.userUseCase.SetDefaultAddress(userID, addressID)
+	err = uh.userUseCase.SetDefaultAddress(c.Request.Context(), userID, addressID)
 
 	if err == usecase.ErrNoAddress {
 		response := response.ResponseMessage(400, "user don't have an address ", nil, err.Error())
@@ -349,7 +352,7 @@ func (uh *UserHandler) EditUserName(c *gin.Context) {
 
 	userID, _ := helper.GetIDFromContext(c)
 
-	err := uh.userUseCase.UpdateUserName(body.Name, userID)
+	err := uh.userUseCase.UpdateUserName(c.Request.Context(), body.Name, userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to update username", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -6,6 +6,7 @@ import (
 	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +14,7 @@ import (
 type WalletHandler struct {
 	walletUseCase services.WalletUseCase
 	orderUseCase  services.OrderUseCase
+	subHandler    helper.SubHandler
 }
 
 func NewWalletHandler(walletUseCase services.WalletUseCase,
@@ -37,7 +39,7 @@ func NewWalletHandler(walletUseCase services.WalletUseCase,
 func (oh *WalletHandler) CreateUserWallet(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 
-	err := oh.walletUseCase.CreateUserWallet(userID)
+	err := oh.walletUseCase.CreateUserWallet(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -62,7 +64,7 @@ func (oh *WalletHandler) CreateUserWallet(c *gin.Context) {
 func (oh *WalletHandler) ViewUserWallet(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 
-	Wallet, err := oh.walletUseCase.GetUserWallet(userID)
+	Wallet, err := oh.walletUseCase.GetUserWallet(c.Request.Context(), userID)
 
 	if err == usecase.ErrNoWallet {
 		response := response.ResponseMessage(204, "user does not have wallet", nil, err.Error())
@@ -89,18 +91,22 @@ func (oh *WalletHandler) ViewUserWallet(c *gin.Context) {
 //	@Success		200	{object}	response.Response
 //	@Failure		400	{object}	response.Response
 //	@Failure		500	{object}	response.Response
+//	@Failure		409	{object}	response.Response{data=[]response.CartIssue}	"Failed, cart changed"
 //	@Router			/payment/wallet [post]
 func (od *WalletHandler) PayUsingWallet(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
-	err := od.orderUseCase.ValidateWalletPayment(userID)
+	err := od.orderUseCase.ValidateWalletPayment(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(400, "Failed", nil, err.Error())
 		c.JSON(http.StatusBadRequest, response)
 		return
 	}
 
-	err = od.orderUseCase.ConfirmedOrder(userID, 3) // 3 refers wallet payment
+	err = od.orderUseCase.ConfirmedOrder(c.Request.Context(), userID, 3) // 3 refers wallet payment
 	if err != nil {
+		if cartChanged(c, err) {
+			return
+		}
 		response := response.ResponseMessage(500, "Failed", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
 		return
@@ -110,6 +116,60 @@ func (od *WalletHandler) PayUsingWallet(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// ApplyWallet godoc
+//
+//	@Summary		Use wallet for part of the order
+//	@Description	Pay part of the cart from the wallet and the rest online or on delivery. Amount zero uses as much of the balance as needed.
+//	@Tags			checkout
+//	@Security		Bearer
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		request.ApplyWallet	true	"Wallet amount"
+//	@Success		200		{object}	response.Response	"Success, wallet applied"
+//	@Failure		400		{object}	response.Response	"Failed to bind JSON inputs from request"
+//	@Failure		400		{object}	response.Response	"Failed to apply wallet"
+//	@Router			/wallet/apply [post]
+func (od *WalletHandler) ApplyWallet(c *gin.Context) {
+	var body request.ApplyWallet
+	if !od.subHandler.BindRequest(c, &body) {
+		return
+	}
+
+	userID, _ := helper.GetIDFromContext(c)
+	err := od.walletUseCase.ApplyWallet(c.Request.Context(), userID, body.Amount)
+	if err != nil {
+		response := response.ResponseMessage(statusBadRequest, "Failed to apply wallet", nil, err.Error())
+		c.JSON(statusBadRequest, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, wallet applied", nil, nil)
+	c.JSON(statusOK, response)
+}
+
+// RemoveAppliedWallet godoc
+//
+//	@Summary		Stop using wallet for the order
+//	@Description	Remove the wallet amount applied on the cart.
+//	@Tags			checkout
+//	@Security		Bearer
+//	@Produce		json
+//	@Success		200	{object}	response.Response	"Success, wallet removed"
+//	@Failure		500	{object}	response.Response	"Failed to remove wallet"
+//	@Router			/wallet/remove [delete]
+func (od *WalletHandler) RemoveAppliedWallet(c *gin.Context) {
+	userID, _ := helper.GetIDFromContext(c)
+	err := od.walletUseCase.RemoveAppliedWallet(c.Request.Context(), userID)
+	if err != nil {
+		response := response.ResponseMessage(statusInternalServerError, "Failed to remove wallet", nil, err.Error())
+		c.JSON(statusInternalServerError, response)
+		return
+	}
+
+	response := response.ResponseMessage(statusOK, "Success, wallet removed", nil, nil)
+	c.JSON(statusOK, response)
+}
+
 // WalletTransactionHistory godoc
 //
 //	@Summary		User wallet transaction history
@@ -123,7 +183,7 @@ func (od *WalletHandler) PayUsingWallet(c *gin.Context) {
 func (od *WalletHandler) WalletTransactionHistory(c *gin.Context) {
 	userID, _ := helper.GetIDFromContext(c)
 
-	walletHistory, err := od.walletUseCase.GetWalletHistory(userID)
+	walletHistory, err := od.walletUseCase.GetWalletHistory(c.Request.Context(), userID)
 	if err != nil {
 		response := response.ResponseMessage(500, "Failed to get wallet history", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, response)
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/config"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuditedResponse is the most of a response kept to read the created entity from.
+const maxAuditedResponse = 64 << 10
+
+// AuditMiddleware records the mutating admin requests in the audit log.
+type AuditMiddleware struct {
+	auditUseCase services.AuditUseCase
+}
+
+// NewAuditMiddleware creates a new instance of the audit middleware.
+func NewAuditMiddleware(useCase services.AuditUseCase) *AuditMiddleware {
+	return &AuditMiddleware{auditUseCase: useCase}
+}
+
+// auditWriter keeps a copy of the start of the response.
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	if room := maxAuditedResponse - w.body.Len(); room > 0 {
+		if len(data) < room {
+			room = len(data)
+		}
+		w.body.Write(data[:room])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Audit records the request with the entity it targets before and after the
+// handler. Requests that only read are not recorded. A request that creates an
+// entity has no entity param, the data of its response is recorded as the after state.
+func (a *AuditMiddleware) Audit(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+
+	var body []byte
+	if strings.HasPrefix(c.ContentType(), "application/json") && c.Request.Body != nil {
+		body, _ = io.ReadAll(c.Request.Body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	params := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		params[param.Key] = param.Value
+	}
+	entityType, entityID := a.auditUseCase.EntityFromParams(params)
+	before := a.auditUseCase.Snapshot(c.Request.Context(), entityType, entityID)
+
+	writer := &auditWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	c.Next()
+
+	after := ""
+	if entityType != "" {
+		after = a.auditUseCase.Snapshot(c.Request.Context(), entityType, entityID)
+	} else {
+		after = a.auditUseCase.RedactBody(responseData(writer.body.Bytes()))
+	}
+
+	err := a.auditUseCase.Record(c.Request.Context(), request.AuditLog{
+		Actor:       adminActor(),
+		Action:      c.Request.Method + " " + c.FullPath(),
+		Path:        c.Request.URL.Path,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Before:      before,
+		After:       after,
+		RequestBody: a.auditUseCase.RedactBody(body),
+		StatusCode:  writer.Status(),
+		IP:          c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		helper.LoggerFrom(c.Request.Context()).Error("audit log not recorded", "error", err)
+	}
+}
+
+// adminActor names the admin account of the request, there is one admin
+// configured with ADMIN and ADMINPASS.
+func adminActor() string {
+	if username := config.GetAdminCredentials().AdminUsername; username != "" {
+		return "admin:" + username
+	}
+	return "admin"
+}
+
+// responseData returns the data of a json response when it is an object.
+func responseData(body []byte) []byte {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(body, &envelope) != nil || !bytes.HasPrefix(bytes.TrimSpace(envelope.Data), []byte("{")) {
+		return nil
+	}
+	return envelope.Data
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/config"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 )
@@ -47,8 +49,8 @@ func (a *AuthMiddleware) tokenExpired(c *gin.Context) {
 }
 
 // Todo
-func (a *AuthMiddleware) checkIsBlockedUser(userID int) (ok bool) {
-	userData, err := a.userUseCase.FindUserById(userID)
+func (a *AuthMiddleware) checkIsBlockedUser(ctx context.Context, userID int) (ok bool) {
+	userData, err := a.userUseCase.FindUserById(ctx, userID)
 	if err != nil {
 		return false
 	}
@@ -90,7 +92,13 @@ func (a *AuthMiddleware) tokenAuth(c *gin.Context, role string) bool {
 			return false
 		}
 
-		c.Set("userID", fmt.Sprint(claims["userID"]))
+		userID := fmt.Sprint(claims["userID"])
+		c.Set("userID", userID)
+
+		// later logs of the request name the account
+		ctx := c.Request.Context()
+		logger := helper.LoggerFrom(ctx).With("role", role, "user_id", userID)
+		c.Request = c.Request.WithContext(helper.WithLogger(ctx, logger))
 
 		return true
 	}
@@ -130,3 +138,27 @@ func (a *AuthMiddleware) AdminAuthRequired(c *gin.Context) {
 		return
 	}
 }
+
+// CartTokenHeader carries the signed cart token of a guest cart.
+const CartTokenHeader = "X-Cart-Token"
+
+// GuestCart identifies the visitor's guest cart by the signed cart token. A new
+// token is issued in the response header when the request has none or it is invalid.
+func (a *AuthMiddleware) GuestCart(c *gin.Context) {
+	cartID, err := helper.ParseCartToken(c.Request.Header.Get(CartTokenHeader))
+	if err != nil {
+		var token string
+		token, cartID, err = helper.GenerateCartToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				statusCode: http.StatusInternalServerError,
+				message:    "Failed to generate cart token",
+			})
+			c.Abort()
+			return
+		}
+		c.Header(CartTokenHeader, token)
+	}
+
+	c.Set("cartID", cartID)
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMiddleware gives every request a correlation id and a logger and
+// writes the access log.
+type RequestMiddleware struct {
+	logger *slog.Logger
+}
+
+// NewRequestMiddleware creates a new instance of the request middleware.
+func NewRequestMiddleware(logger *slog.Logger) *RequestMiddleware {
+	return &RequestMiddleware{logger: logger}
+}
+
+// Request keeps the X-Request-ID sent by the client or generates one, echoes it
+// in the response and puts a logger with the request id in the request
+// context, the handlers, use cases and repositories log through it. One line is
+// logged when the request is done, the query with the sensitive params redacted.
+func (r *RequestMiddleware) Request(c *gin.Context) {
+	start := time.Now()
+
+	requestID := c.Request.Header.Get(helper.RequestIDHeader)
+	if !helper.ValidRequestID(requestID) {
+		requestID = helper.NewRequestID()
+	}
+	c.Header(helper.RequestIDHeader, requestID)
+
+	logger := r.logger.With("request_id", requestID)
+	ctx := helper.WithRequestID(c.Request.Context(), requestID)
+	c.Request = c.Request.WithContext(helper.WithLogger(ctx, logger))
+
+	c.Next()
+
+	status := c.Writer.Status()
+	level := slog.LevelInfo
+	switch {
+	case status >= 500:
+		level = slog.LevelError
+	case status >= 400:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.Request.URL.Path),
+		slog.String("route", c.FullPath()),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(start)),
+		slog.Int("size", max(c.Writer.Size(), 0)),
+		slog.String("ip", c.ClientIP()),
+		slog.String("user_agent", c.Request.UserAgent()),
+	}
+	if query := helper.RedactQuery(c.Request.URL.Query()); query != "" {
+		attrs = append(attrs, slog.String("query", query))
+	}
+	if userID := c.GetString("userID"); userID != "" {
+		attrs = append(attrs, slog.String("user_id", userID))
+	}
+	if errs := c.Errors.String(); errs != "" {
+		attrs = append(attrs, slog.String("error", errs))
+	}
+	logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+}
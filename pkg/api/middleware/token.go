@@ -1,8 +1,6 @@
 package middleware
 
 import (
-	"fmt"
-
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
 	"github.com/gin-gonic/gin"
 )
@@ -36,7 +34,6 @@ func (t *TokenManager) GenerateUserToken(userID int) (token string, err error) {
 func (t *TokenManager) SetTokenHeader(c *gin.Context, token string) {
 	key := "Authorization"
 	c.Request.Header.Set(key, token)
-	fmt.Println(c.Request.Header.Get(key))
 }
 
 func (t *TokenManager) RemoveToken(c *gin.Context) {
@@ -8,11 +8,12 @@ import (
 )
 
 func AdminRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, adminHandler *handler.AdminHandler,
-	productHandler *handler.ProductHandler, authHandler *handler.AuthHandler, cartHandler *handler.CartHandler, orderHandler *handler.OrderHandler, couponHandler *handler.CouponHandler, referralHandler *handler.ReferralHandler, auth *middleware.AuthMiddleware) {
+	productHandler *handler.ProductHandler, authHandler *handler.AuthHandler, cartHandler *handler.CartHandler, orderHandler *handler.OrderHandler, couponHandler *handler.CouponHandler, referralHandler *handler.ReferralHandler, auth *middleware.AuthMiddleware, promotionHandler *handler.PromotionHandler, loyaltyHandler *handler.LoyaltyHandler, reminderHandler *handler.ReminderHandler, eventHandler *handler.EventHandler, lockoutHandler *handler.LockoutHandler, twoFactorHandler *handler.TwoFactorHandler, audit *middleware.AuditMiddleware, auditHandler *handler.AuditHandler, reportHandler *handler.ReportHandler, catalogHandler *handler.CatalogHandler, jobHandler *handler.JobHandler) {
 
 	router.POST("/login", authHandler.AdminLogin)
+	router.POST("/login/2fa", authHandler.AdminTwoFactorLogin)
 
-	router.Use(auth.AdminAuthRequired)
+	router.Use(auth.AdminAuthRequired, audit.Audit)
 	{
 
 		category := router.Group("/category")
@@ -37,6 +38,10 @@ func AdminRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, admi
 			products.PUT("/block-product/:productID", productHandler.BlockProduct)
 			products.PUT("/unblock-product/:productID", productHandler.UnBlockProduct)
 			products.GET("/category/:categoryID", productHandler.ListProductsByCategoryAdmin)
+			products.POST("/import", catalogHandler.ImportProducts)
+			products.GET("/imports", catalogHandler.ListProductImports)
+			products.GET("/imports/:importID", catalogHandler.GetProductImport)
+			products.GET("/export", catalogHandler.ExportProducts)
 
 		}
 
@@ -47,6 +52,61 @@ func AdminRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, admi
 			coupon.GET("/all-coupons", couponHandler.ListOutAllCouponsToAdmin)
 			coupon.PUT("/block-coupon/:couponID", couponHandler.BlockCoupon)
 			coupon.PUT("/unblock-coupon/:couponID", couponHandler.UnBlockCoupon)
+
+			coupon.POST("/create-promotion", promotionHandler.CreatePromotion)
+			coupon.PUT("/update-promotion/:promotionID", promotionHandler.UpdatePromotion)
+			coupon.GET("/all-promotions", promotionHandler.ListPromotions)
+			coupon.PUT("/block-promotion/:promotionID", promotionHandler.BlockPromotion)
+			coupon.PUT("/unblock-promotion/:promotionID", promotionHandler.UnBlockPromotion)
+			coupon.POST("/schedule-price", promotionHandler.SchedulePriceChange)
+			coupon.GET("/scheduled-prices", promotionHandler.ListScheduledPrices)
+			coupon.DELETE("/scheduled-prices/:scheduleID", promotionHandler.CancelScheduledPrice)
+		}
+
+		referral := router.Group("/referral")
+		{
+			referral.GET("/programme", referralHandler.GetReferralProgramme)
+			referral.PUT("/programme", referralHandler.UpdateReferralProgramme)
+		}
+
+		loyalty := router.Group("/loyalty")
+		{
+			loyalty.POST("/create-rule", loyaltyHandler.CreateLoyaltyRule)
+			loyalty.PUT("/update-rule/:ruleID", loyaltyHandler.UpdateLoyaltyRule)
+			loyalty.GET("/all-rules", loyaltyHandler.ListLoyaltyRules)
+			loyalty.PUT("/block-rule/:ruleID", loyaltyHandler.BlockLoyaltyRule)
+			loyalty.PUT("/unblock-rule/:ruleID", loyaltyHandler.UnBlockLoyaltyRule)
+		}
+
+		events := router.Group("/events")
+		{
+			events.GET("/dead-letters", eventHandler.ListDeadEvents)
+			events.POST("/:eventID/retry", eventHandler.RetryDeadEvent)
+		}
+
+		jobs := router.Group("/jobs")
+		{
+			jobs.GET("", jobHandler.ListJobs)
+			jobs.GET("/stats", jobHandler.GetJobStats)
+			jobs.POST("/:jobID/retry", jobHandler.RetryJob)
+		}
+
+		lockouts := router.Group("/lockouts")
+		{
+			lockouts.GET("", lockoutHandler.ListLockouts)
+			lockouts.DELETE("/:lockoutID", lockoutHandler.ClearLockout)
+		}
+
+		auditLogs := router.Group("/audit-logs")
+		{
+			auditLogs.GET("", auditHandler.ListAuditLogs)
+			auditLogs.GET("/export", auditHandler.ExportAuditLogs)
+		}
+
+		twoFactor := router.Group("/2fa")
+		{
+			twoFactor.GET("", twoFactorHandler.GetAdminTwoFactorStatus)
+			twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateAdminRecoveryCodes)
 		}
 
 		userManagement := router.Group("/user-management")
@@ -54,6 +114,11 @@ func AdminRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, admi
 			userManagement.GET("/view-all-users", adminHandler.DisplayAllUsers)
 			userManagement.PUT("/block-user/:userID", adminHandler.BlockUser)
 			userManagement.PUT("/unblock-user/:userID", adminHandler.UnblockUser)
+			userManagement.GET("/find-users", adminHandler.FindUsersByName)
+			userManagement.GET("/users/:userID", adminHandler.GetCustomer)
+			userManagement.GET("/users/:userID/notes", adminHandler.GetNotes)
+			userManagement.POST("/users/:userID/notes", adminHandler.AddNote)
+			userManagement.DELETE("/notes/:noteID", adminHandler.DeleteNote)
 
 		}
 
@@ -65,6 +130,9 @@ func AdminRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, admi
 
 		}
 		router.GET("/sales-report", orderHandler.MonthlySalesReport)
+		router.GET("/reports/abandoned-carts", reminderHandler.GetAbandonedCartReport)
+		router.GET("/reports/sales", reportHandler.GetSalesAnalytics)
+		router.GET("/reports/sales/export", reportHandler.ExportSales)
 
 	}
 }
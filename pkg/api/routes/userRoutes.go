@@ -8,15 +8,35 @@ import (
 
 func UserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, adminHandler *handler.AdminHandler,
 	productHandler *handler.ProductHandler, authHandler *handler.AuthHandler, cartHandler *handler.CartHandler, orderHandler *handler.OrderHandler, couponHandler *handler.CouponHandler, referralHandler *handler.ReferralHandler, auth *middleware.AuthMiddleware,
-	walletHandler *handler.WalletHandler, razorpayHandler *handler.RazorpayHandler,
+	walletHandler *handler.WalletHandler, razorpayHandler *handler.RazorpayHandler, loyaltyHandler *handler.LoyaltyHandler, reminderHandler *handler.ReminderHandler, notificationHandler *handler.NotificationHandler,
+	twoFactorHandler *handler.TwoFactorHandler, accountHandler *handler.AccountHandler,
 ) {
 
 	router.POST("/send-otp", authHandler.SendOTP)
 	router.POST("/verify-otp", authHandler.VerifyOTP)
 	router.POST("/sign-up", authHandler.UserSignUp)
 	router.POST("/login", authHandler.UserLogin)
+	router.POST("/login/2fa", authHandler.UserTwoFactorLogin)
+	router.GET("/auth/oidc/providers", authHandler.ListLoginProviders)
+	router.GET("/auth/oidc/:provider/login", authHandler.OIDCLogin)
+	router.GET("/auth/oidc/:provider/callback", authHandler.OIDCCallback)
 	router.POST("/logout", authHandler.Logout)
+	router.GET("/verify-email", authHandler.VerifyEmail)
+	router.POST("/forgot-password", authHandler.ForgotPassword)
+	router.POST("/reset-password", authHandler.ResetPassword)
 	router.POST("/webhook", orderHandler.WebhookHandler)
+	router.GET("/cart-reminders/unsubscribe", reminderHandler.Unsubscribe)
+
+	guestCart := router.Group("/guest-cart", auth.GuestCart)
+	{
+		guestCart.GET("", cartHandler.ViewGuestCart)
+		guestCart.POST("/add/:productID", cartHandler.AddToGuestCart)
+		guestCart.PUT("/:productID/increment", cartHandler.IncrementGuestQuantity)
+		guestCart.PUT("/:productID/decrement", cartHandler.DecrementGuestQuantity)
+		guestCart.DELETE("/remove/:productID", cartHandler.RemoveFromGuestCart)
+		guestCart.POST("/coupon/apply", cartHandler.ApplyGuestCoupon)
+		guestCart.DELETE("/coupon/remove/:couponID", cartHandler.RemoveGuestCoupon)
+	}
 
 	// Authentication middleware
 	router.Use(auth.UserAuthRequired)
@@ -33,12 +53,26 @@ func UserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, admin
 			profile.POST("/edit-username", userHandler.EditUserName)
 			profile.POST("/verify-password", userHandler.ChangePasswordRequest)
 			profile.POST("/change-password", userHandler.ChangePassword)
+			profile.POST("/verify-email", authHandler.SendEmailVerification)
+			profile.GET("/2fa", twoFactorHandler.GetTwoFactorStatus)
+			profile.POST("/2fa/enroll", twoFactorHandler.BeginEnrolment)
+			profile.POST("/2fa/enable", twoFactorHandler.EnableTwoFactor)
+			profile.POST("/2fa/disable", twoFactorHandler.DisableTwoFactor)
+			profile.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+			profile.GET("/notifications", notificationHandler.GetPreferences)
+			profile.PUT("/notifications", notificationHandler.UpdatePreferences)
+			profile.GET("/notifications/history", notificationHandler.GetNotifications)
+			profile.GET("/export", accountHandler.ExportData)
+			profile.GET("/delete-account", accountHandler.GetDeletion)
+			profile.POST("/delete-account", accountHandler.RequestDeletion)
+			profile.POST("/delete-account/cancel", accountHandler.CancelDeletion)
 		}
 
 		referral := router.Group("/referral")
 		{
 			referral.GET("/get-code", referralHandler.GetReferralCode)
 			referral.POST("/claim", referralHandler.ApplyReferralCode)
+			referral.GET("/stats", referralHandler.GetReferralStats)
 		}
 
 		wallet := router.Group("/wallet")
@@ -46,6 +80,15 @@ func UserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, admin
 			wallet.GET("", walletHandler.ViewUserWallet)
 			wallet.POST("/create", walletHandler.CreateUserWallet)
 			wallet.GET("/history", walletHandler.WalletTransactionHistory)
+			wallet.POST("/apply", walletHandler.ApplyWallet)
+			wallet.DELETE("/remove", walletHandler.RemoveAppliedWallet)
+		}
+
+		loyalty := router.Group("/loyalty")
+		{
+			loyalty.GET("/history", loyaltyHandler.GetPointsHistory)
+			loyalty.POST("/apply", loyaltyHandler.ApplyPoints)
+			loyalty.DELETE("/remove", loyaltyHandler.RemoveAppliedPoints)
 		}
 
 		category := router.Group("/category")
@@ -69,21 +112,25 @@ func UserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, admin
 			wishlist.GET("/", productHandler.ShowWishListProducts)
 			wishlist.POST("/add/:productID", productHandler.AddToWishList)
 			wishlist.DELETE("/remove/:productID", productHandler.RemoveFromWishList)
+			wishlist.POST("/move-to-cart/:productID", cartHandler.MoveToCart)
 		}
 
 		cart := router.Group("/cart")
 		{
 			cart.GET("/", cartHandler.ViewCart)
+			cart.PUT("/", cartHandler.UpdateCart)
 			cart.POST("/add/:productID", cartHandler.AddToCart)
 			cart.PUT("/:productID/increment", cartHandler.IncrementQuantity)
 			cart.PUT("/:productID/decrement", cartHandler.DecrementQuantity)
 			cart.DELETE("/remove/:productID", cartHandler.RemoveFromCart)
+			cart.POST("/save-for-later/:productID", cartHandler.SaveForLater)
 		}
 
 		coupon := router.Group("/coupon")
 		{
 			coupon.GET("/available", couponHandler.ListOutAvailableCouponsToUser)
 			coupon.POST("/apply", couponHandler.ApplyCoupon)
+			coupon.POST("/evaluate", couponHandler.EvaluateCoupon)
 			coupon.DELETE("/remove/:couponID", couponHandler.RemoveAppliedCoupon)
 		}
 
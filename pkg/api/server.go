@@ -1,12 +1,22 @@
 package api
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/anazibinurasheed/project-device-mart/api/docs"
 	"github.com/anazibinurasheed/project-device-mart/pkg/api/handler"
 	"github.com/anazibinurasheed/project-device-mart/pkg/api/middleware"
 	"github.com/anazibinurasheed/project-device-mart/pkg/api/routes"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/oidcmock"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"  // swagger embed files
@@ -32,29 +42,100 @@ import (
 //	@host		localhost:3000
 //	@BasePath	/api/v1
 
+// ServerTimeouts are the timeouts of the http server. Shutdown is how long the
+// requests in flight and the running background jobs get to finish on SIGTERM,
+// Drain how long the server keeps taking requests with the readiness check
+// failing, until the load balancer stops sending them.
+type ServerTimeouts struct {
+	Read     time.Duration
+	Write    time.Duration
+	Idle     time.Duration
+	Shutdown time.Duration
+	Drain    time.Duration
+}
+
 type ServerHTTP struct {
-	engine *gin.Engine
+	engine   *gin.Engine
+	jobs     services.JobUseCase
+	health   *handler.HealthHandler
+	logger   *slog.Logger
+	timeouts ServerTimeouts
 }
 
-func NewServerHTTP(userHandler *handler.UserHandler, adminHandler *handler.AdminHandler, productHandler *handler.ProductHandler, commonHandler *handler.AuthHandler, cartHandler *handler.CartHandler, orderHandler *handler.OrderHandler, couponHandler *handler.CouponHandler, referralHandler *handler.ReferralHandler, auth *middleware.AuthMiddleware, walletHandler *handler.WalletHandler, razorpayHandler *handler.RazorpayHandler) *ServerHTTP {
+func NewServerHTTP(userHandler *handler.UserHandler, adminHandler *handler.AdminHandler, productHandler *handler.ProductHandler, commonHandler *handler.AuthHandler, cartHandler *handler.CartHandler, orderHandler *handler.OrderHandler, couponHandler *handler.CouponHandler, referralHandler *handler.ReferralHandler, auth *middleware.AuthMiddleware, walletHandler *handler.WalletHandler, razorpayHandler *handler.RazorpayHandler, promotionHandler *handler.PromotionHandler, loyaltyHandler *handler.LoyaltyHandler, reminderHandler *handler.ReminderHandler, notificationHandler *handler.NotificationHandler, eventHandler *handler.EventHandler, lockoutHandler *handler.LockoutHandler, twoFactorHandler *handler.TwoFactorHandler, accountHandler *handler.AccountHandler, audit *middleware.AuditMiddleware, auditHandler *handler.AuditHandler, reportHandler *handler.ReportHandler, catalogHandler *handler.CatalogHandler, jobHandler *handler.JobHandler, jobs services.JobUseCase, healthHandler *handler.HealthHandler, request *middleware.RequestMiddleware, logger *slog.Logger, timeouts ServerTimeouts, trustedProxies []string, oidcMock *oidcmock.Provider) (*ServerHTTP, error) {
 
 	router := gin.New()
-	router.Use(gin.Logger())
+	// ClientIP reads X-Forwarded-For only from these proxies, with none it is the
+	// peer address so the login lockout and the audit log can't be spoofed
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES :%s", err)
+	}
+	router.Use(request.Request)
 	router.GET("/swagger/*any", swagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/healthz", healthHandler.Healthz)
+	router.GET("/readyz", healthHandler.Readyz)
 
 	router.LoadHTMLGlob("web/template/*.html")
 
-	routes.UserRoutes(router.Group("/api/v1"), userHandler, adminHandler, productHandler, commonHandler, cartHandler, orderHandler, couponHandler, referralHandler, auth, walletHandler, razorpayHandler)
+	// local OpenID Connect provider for development, enabled by OIDC_MOCK
+	if oidcMock != nil {
+		oidcMock.Register(router.Group("/oidc-mock"))
+	}
 
-	routes.AdminRoutes(router.Group("/api/v1/admin"), userHandler, adminHandler, productHandler, commonHandler, cartHandler, orderHandler, couponHandler, referralHandler, auth)
+	routes.UserRoutes(router.Group("/api/v1"), userHandler, adminHandler, productHandler, commonHandler, cartHandler, orderHandler, couponHandler, referralHandler, auth, walletHandler, razorpayHandler, loyaltyHandler, reminderHandler, notificationHandler, twoFactorHandler, accountHandler)
+
+	routes.AdminRoutes(router.Group("/api/v1/admin"), userHandler, adminHandler, productHandler, commonHandler, cartHandler, orderHandler, couponHandler, referralHandler, auth, promotionHandler, loyaltyHandler, reminderHandler, eventHandler, lockoutHandler, twoFactorHandler, audit, auditHandler, reportHandler, catalogHandler, jobHandler)
 
 	return &ServerHTTP{
 
-		engine: router,
-	}
+		engine:   router,
+		jobs:     jobs,
+		health:   healthHandler,
+		logger:   logger,
+		timeouts: timeouts,
+	}, nil
 }
 
+// Start runs the background jobs and the server until SIGINT or SIGTERM. On the
+// signal the readiness check fails and the server keeps serving for the drain
+// delay, then stops accepting connections and waits for the requests in flight,
+// then the running jobs, up to the shutdown timeout.
 func (s *ServerHTTP) Start(port string) {
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           s.engine,
+		ReadHeaderTimeout: s.timeouts.Read,
+		ReadTimeout:       s.timeouts.Read,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+	}
 
-	log.Fatal(s.engine.Run(":" + port))
+	s.jobs.Start()
+
+	go func() {
+		s.logger.Info("server started", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("server stopped", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	s.logger.Info("shutting down", "signal", sig.String(), "timeout", s.timeouts.Shutdown)
+
+	s.health.Drain()
+	time.Sleep(s.timeouts.Drain)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Shutdown)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		s.logger.Error("requests in flight not drained", "error", err)
+	}
+	if err := s.jobs.Stop(ctx); err != nil {
+		s.logger.Error("running jobs not finished", "error", err)
+	}
+	s.logger.Info("server stopped")
 }
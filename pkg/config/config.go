@@ -10,25 +10,61 @@ import (
 //config package is to load configurations from .env file
 
 type Config struct {
-	PORT               string `mapstructure:"PORT"`
-	DBHost             string `mapstructure:"DB_HOST"`
-	DBName             string `mapstructure:"DB_NAME"`
-	DBUser             string `mapstructure:"DB_USER"`
-	DBPort             string `mapstructure:"DB_PORT"`
-	DBPassword         string `mapstructure:"DB_PASSWORD"`
-	AdminUsername      string `mapstructure:"ADMIN"`
-	AdminPassword      string `mapstructure:"ADMINPASS"`
-	JwtSecret          string `mapstructure:"JWT_SECRET"`
-	TwilioAccountSid   string `mapstructure:"TWILIO_ACCOUNT_SID"`
-	TwilioAuthToken    string `mapstructure:"TWILIO_AUTH_TOKEN"`
-	TwilioServiceSid   string `mapstructure:"VERIFY_SERVICE_SID"`
-	RazorPayKeyId      string `mapstructure:"RAZORPAY_KEY_ID"`
-	RazorPayKeySecret  string `mapstructure:"RAZORPAY_KEY_SECRET"`
-	AWSRegion          string `mapstructure:"AWS_REGION"`
-	AWSAccessKeyID     string `mapstructure:"AWS_ACCESS_KEY_ID"`
-	AWSSecretAccessKey string `mapstructure:"AWS_SECRET_ACCESS_KEY"`
-	S3BucketName       string `mapstructure:"S3_BUCKET_NAME"`
-	S3BucketMediaPath  string `mapstructure:"S3_BUCKET_MEDIA_PATH"`
+	PORT                 string  `mapstructure:"PORT"`
+	DBHost               string  `mapstructure:"DB_HOST"`
+	DBName               string  `mapstructure:"DB_NAME"`
+	DBUser               string  `mapstructure:"DB_USER"`
+	DBPort               string  `mapstructure:"DB_PORT"`
+	DBPassword           string  `mapstructure:"DB_PASSWORD"`
+	AdminUsername        string  `mapstructure:"ADMIN"`
+	AdminPassword        string  `mapstructure:"ADMINPASS"`
+	JwtSecret            string  `mapstructure:"JWT_SECRET"`
+	TwilioAccountSid     string  `mapstructure:"TWILIO_ACCOUNT_SID"`
+	TwilioAuthToken      string  `mapstructure:"TWILIO_AUTH_TOKEN"`
+	TwilioServiceSid     string  `mapstructure:"VERIFY_SERVICE_SID"`
+	RazorPayKeyId        string  `mapstructure:"RAZORPAY_KEY_ID"`
+	RazorPayKeySecret    string  `mapstructure:"RAZORPAY_KEY_SECRET"`
+	AWSRegion            string  `mapstructure:"AWS_REGION"`
+	AWSAccessKeyID       string  `mapstructure:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey   string  `mapstructure:"AWS_SECRET_ACCESS_KEY"`
+	S3BucketName         string  `mapstructure:"S3_BUCKET_NAME"`
+	S3BucketMediaPath    string  `mapstructure:"S3_BUCKET_MEDIA_PATH"`
+	BaseURL              string  `mapstructure:"BASE_URL"`     // public url used in links sent to users
+	EmailSender          string  `mapstructure:"EMAIL_SENDER"` // log (default), file, smtp or capture
+	SMSSender            string  `mapstructure:"SMS_SENDER"`   // log (default), file or twilio
+	NotifyFile           string  `mapstructure:"NOTIFY_FILE"`  // file the file sender writes to
+	SMTPHost             string  `mapstructure:"SMTP_HOST"`
+	SMTPPort             string  `mapstructure:"SMTP_PORT"`
+	SMTPUsername         string  `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword         string  `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom             string  `mapstructure:"SMTP_FROM"`
+	TwilioFromNumber     string  `mapstructure:"TWILIO_FROM_NUMBER"`
+	CartReminderAfter    string  `mapstructure:"CART_REMINDER_AFTER"`    // idle periods of the reminder sequence, e.g. 1h,24h,72h
+	PasswordMinLength    int     `mapstructure:"PASSWORD_MIN_LENGTH"`    // default 8
+	BreachedPasswordFile string  `mapstructure:"BREACHED_PASSWORD_FILE"` // one password per line, default web/security/breached-passwords.txt
+	LoginFreeAttempts    int     `mapstructure:"LOGIN_FREE_ATTEMPTS"`    // failed logins before the backoff, default 3
+	LoginMaxAttempts     int     `mapstructure:"LOGIN_MAX_ATTEMPTS"`     // failed logins before the lockout, default 10
+	LoginLockout         string  `mapstructure:"LOGIN_LOCKOUT"`          // lockout duration, default 30m
+	TOTPIssuer           string  `mapstructure:"TOTP_ISSUER"`            // name shown in the authenticator apps, default Device Mart
+	TwoFactorKey         string  `mapstructure:"TWO_FACTOR_KEY"`         // encrypts the totp secrets, defaults to JWT_SECRET
+	GoogleClientID       string  `mapstructure:"GOOGLE_CLIENT_ID"`       // enables the google login
+	GoogleClientSecret   string  `mapstructure:"GOOGLE_CLIENT_SECRET"`
+	OIDCMock             string  `mapstructure:"OIDC_MOCK"`              // true serves a local mock provider at BASE_URL/oidc-mock
+	AccountDeletionGrace string  `mapstructure:"ACCOUNT_DELETION_GRACE"` // time before a deleted account is anonymised, default 720h
+	SalesTaxRate         float64 `mapstructure:"SALES_TAX_RATE"`         // percent of tax included in the prices, default 18
+	ShippingFee          float32 `mapstructure:"SHIPPING_FEE"`           // charged on the carts below FREE_SHIPPING_ABOVE, default 0
+	FreeShippingAbove    float32 `mapstructure:"FREE_SHIPPING_ABOVE"`    // subtotal from which the shipping is free, 0 charges every cart
+	JobStore             string  `mapstructure:"JOB_STORE"`              // postgres (default) or memory, memory jobs are lost on restart
+	JobWorkers           int     `mapstructure:"JOB_WORKERS"`            // background jobs run at once, default 4
+	HTTPReadTimeout      string  `mapstructure:"HTTP_READ_TIMEOUT"`      // default 15s
+	HTTPWriteTimeout     string  `mapstructure:"HTTP_WRITE_TIMEOUT"`     // default 2m, the report exports are streamed in the response
+	HTTPIdleTimeout      string  `mapstructure:"HTTP_IDLE_TIMEOUT"`      // default 2m
+	ShutdownTimeout      string  `mapstructure:"SHUTDOWN_TIMEOUT"`       // time for the requests and jobs to finish on SIGTERM, default 25s
+	ShutdownDrainDelay   string  `mapstructure:"SHUTDOWN_DRAIN_DELAY"`   // time serving with the readiness check failing before the shutdown, default 15s
+	TrustedProxies       string  `mapstructure:"TRUSTED_PROXIES"`        // comma separated proxy addresses or CIDRs trusted for X-Forwarded-For, default none
+	LogFormat            string  `mapstructure:"LOG_FORMAT"`             // json (default) or text
+	LogLevel             string  `mapstructure:"LOG_LEVEL"`              // debug, info (default), warn or error
+	OTPDevMode           string  `mapstructure:"OTP_DEV_MODE"`           // true skips twilio and accepts the otp 0000, never in production
 }
 
 type AdminCredentials struct {
@@ -45,6 +81,20 @@ var (
 		"RAZORPAY_KEY_ID", "RAZORPAY_KEY_SECRET", "AWS_REGION", "AWS_ACCESS_KEY_ID",
 
 		"AWS_SECRET_ACCESS_KEY", "S3_BUCKET_CODENATION", "S3_BUCKET_CHAT_MEDIA_PATH",
+
+		"BASE_URL", "EMAIL_SENDER", "SMS_SENDER", "CART_REMINDER_AFTER",
+
+		"NOTIFY_FILE", "SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD", "SMTP_FROM", "TWILIO_FROM_NUMBER",
+
+		"PASSWORD_MIN_LENGTH", "BREACHED_PASSWORD_FILE", "LOGIN_FREE_ATTEMPTS", "LOGIN_MAX_ATTEMPTS", "LOGIN_LOCKOUT", "TOTP_ISSUER", "TWO_FACTOR_KEY",
+
+		"GOOGLE_CLIENT_ID", "GOOGLE_CLIENT_SECRET", "OIDC_MOCK", "ACCOUNT_DELETION_GRACE", "SALES_TAX_RATE",
+
+		"SHIPPING_FEE", "FREE_SHIPPING_ABOVE",
+
+		"JOB_STORE", "JOB_WORKERS", "HTTP_READ_TIMEOUT", "HTTP_WRITE_TIMEOUT", "HTTP_IDLE_TIMEOUT", "SHUTDOWN_TIMEOUT", "SHUTDOWN_DRAIN_DELAY", "TRUSTED_PROXIES",
+
+		"LOG_FORMAT", "LOG_LEVEL", "OTP_DEV_MODE",
 	}
 
 	config Config
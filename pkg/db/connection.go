@@ -14,31 +14,61 @@ import (
 // for feature isolation
 var dbInstance *gorm.DB
 
+// models are the tables kept in sync with the domain by AutoMigrate.
+var models = []interface{}{
+	&domain.User{},
+	&domain.Category{},
+	&domain.Product{},
+	&domain.Addresses{},
+	&domain.State{},
+	&domain.Cart{},
+	&domain.GuestCart{},
+	&domain.GuestCoupon{},
+	&domain.AbandonedCart{},
+	&domain.CartReminder{},
+	&domain.ReminderUnsubscribe{},
+	&domain.Notification{},
+	&domain.NotificationPreference{},
+	&domain.DomainEvent{},
+	&domain.EventDelivery{},
+	&domain.LoginLockout{},
+	&domain.TwoFactor{},
+	&domain.RecoveryCode{},
+	&domain.OIDCLoginState{},
+	&domain.UserIdentity{},
+	&domain.AccountDeletion{},
+	&domain.AdminNote{},
+	&domain.AuditLog{},
+	&domain.ProductImport{},
+	&domain.Job{},
+	&domain.PaymentMethod{},
+	&domain.OrderLine{},
+	&domain.OrderStatus{},
+	&domain.Rating{},
+	&domain.Coupon{},
+	&domain.CouponEligibility{},
+	&domain.CouponTracking{},
+	&domain.Wallet{},
+	&domain.Referral{},
+	&domain.ReferralProgramme{},
+	&domain.ReferralClaim{},
+	&domain.WalletTransactionHistory{},
+	&domain.AppliedWallet{},
+	&domain.OrderPayment{},
+	&domain.OrderLineCoupon{},
+	&domain.Wishlist{},
+	&domain.Promotion{},
+	&domain.ScheduledPrice{},
+	&domain.LoyaltyRule{},
+	&domain.LoyaltyTransaction{},
+	&domain.AppliedPoints{},
+}
+
 func ConnectToDatabase(cfg config.Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s ", cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
-	db, dbErr := gorm.Open(postgres.Open(dsn), &gorm.Config{SkipDefaultTransaction: true})
-
-	db.Debug()
-
-	if err := db.AutoMigrate(
-
-		&domain.User{},
-		&domain.Category{},
-		&domain.Product{},
-		&domain.Addresses{},
-		&domain.State{},
-		&domain.Cart{},
-		&domain.PaymentMethod{},
-		&domain.OrderLine{},
-		&domain.OrderStatus{},
-		&domain.Rating{},
-		&domain.Coupon{},
-		&domain.CouponTracking{},
-		&domain.Wallet{},
-		&domain.Referral{},
-		&domain.WalletTransactionHistory{},
-		&domain.Wishlist{},
-	); err != nil {
+	db, dbErr := gorm.Open(postgres.Open(dsn), &gorm.Config{SkipDefaultTransaction: true, Logger: queryLogger{}})
+
+	if err := db.AutoMigrate(models...); err != nil {
 		log.Fatal("Failed to connect with DB", err)
 		return nil, err
 	}
@@ -50,3 +80,17 @@ func ConnectToDatabase(cfg config.Config) (*gorm.DB, error) {
 func GetDBInstance() *gorm.DB {
 	return dbInstance
 }
+
+// MigratedTables returns the names of the tables AutoMigrate creates, for checking
+// the migration state of the database.
+func MigratedTables(db *gorm.DB) ([]string, error) {
+	tables := make([]string, 0, len(models))
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("Failed to parse model %T :%s", model, err)
+		}
+		tables = append(tables, stmt.Schema.Table)
+	}
+	return tables, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQuery is the duration after which a query is logged as a warning.
+const slowQuery = 200 * time.Millisecond
+
+// queryLogger writes the gorm logs through the logger of the query context, so
+// the queries of a request carry its request id. The queries are logged with
+// their placeholders, the values can be passwords, otps or tokens.
+type queryLogger struct{}
+
+func (queryLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return queryLogger{}
+}
+
+func (queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	helper.LoggerFrom(ctx).Info(fmt.Sprintf(msg, args...))
+}
+
+func (queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	helper.LoggerFrom(ctx).Warn(fmt.Sprintf(msg, args...))
+}
+
+func (queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	helper.LoggerFrom(ctx).Error(fmt.Sprintf(msg, args...))
+}
+
+func (queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	logger := helper.LoggerFrom(ctx)
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		logger.Error("query failed", "sql", sql, "rows", rows, "duration", elapsed, "error", err)
+	case elapsed > slowQuery:
+		sql, rows := fc()
+		logger.Warn("slow query", "sql", sql, "rows", rows, "duration", elapsed)
+	case logger.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		logger.Debug("query", "sql", sql, "rows", rows, "duration", elapsed)
+	}
+}
+
+// ParamsFilter keeps the values out of the logged sql.
+func (queryLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,22 @@
+package di
+
+import (
+	"fmt"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/config"
+	"github.com/anazibinurasheed/project-device-mart/pkg/repo"
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"gorm.io/gorm"
+)
+
+// newJobRepository returns the job store of JOB_STORE, the jobs table by default.
+// The memory store keeps the jobs of a single instance until it stops.
+func newJobRepository(cfg config.Config, DB *gorm.DB) (interfaces.JobRepository, error) {
+	switch cfg.JobStore {
+	case "", "postgres":
+		return repo.NewJobRepository(DB), nil
+	case "memory":
+		return repo.NewMemoryJobRepository(), nil
+	}
+	return nil, fmt.Errorf("invalid JOB_STORE %q: must be postgres or memory", cfg.JobStore)
+}
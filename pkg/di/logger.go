@@ -0,0 +1,21 @@
+package di
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/config"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+)
+
+// newLogger builds the logger from LOG_FORMAT and LOG_LEVEL and makes it the
+// default, the log package and the code without a request context write
+// through it too.
+func newLogger(cfg config.Config) (*slog.Logger, error) {
+	logger, err := helper.NewLogger(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return logger, nil
+}
@@ -0,0 +1,32 @@
+package di
+
+import (
+	"github.com/anazibinurasheed/project-device-mart/pkg/config"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/oidcmock"
+)
+
+// newOIDCProviders returns the configured social login providers, with OIDC_MOCK
+// the local mock provider is served at BASE_URL/oidc-mock and added as "mock".
+func newOIDCProviders(cfg config.Config) ([]*helper.OIDCProvider, *oidcmock.Provider, error) {
+	callbackURL := func(name string) string {
+		return cfg.BaseURL + "/api/v1/auth/oidc/" + name + "/callback"
+	}
+
+	var providers []*helper.OIDCProvider
+	if cfg.GoogleClientID != "" {
+		providers = append(providers, helper.NewOIDCProvider("google", "https://accounts.google.com",
+			cfg.GoogleClientID, cfg.GoogleClientSecret, callbackURL("google")))
+	}
+
+	if cfg.OIDCMock != "true" {
+		return providers, nil, nil
+	}
+
+	mock, err := oidcmock.NewProvider(cfg.BaseURL + "/oidc-mock")
+	if err != nil {
+		return nil, nil, err
+	}
+	providers = append(providers, helper.NewOIDCProvider("mock", mock.Issuer, "device-mart", "", callbackURL("mock")))
+	return providers, mock, nil
+}
@@ -0,0 +1,67 @@
+package di
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/api"
+	"github.com/anazibinurasheed/project-device-mart/pkg/config"
+)
+
+// newServerTimeouts reads the HTTP_*_TIMEOUT, SHUTDOWN_TIMEOUT and
+// SHUTDOWN_DRAIN_DELAY durations, an empty one takes its default. Keep the drain
+// delay above the time the readiness probe takes to fail, and the delay plus the
+// shutdown timeout below the grace period of the pod so the requests in flight
+// are drained before it is killed.
+func newServerTimeouts(cfg config.Config) (api.ServerTimeouts, error) {
+	timeouts := api.ServerTimeouts{
+		Read:     15 * time.Second,
+		Write:    2 * time.Minute,
+		Idle:     2 * time.Minute,
+		Shutdown: 25 * time.Second,
+		Drain:    15 * time.Second,
+	}
+
+	for _, timeout := range []struct {
+		name  string
+		value string
+		field *time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", cfg.HTTPReadTimeout, &timeouts.Read},
+		{"HTTP_WRITE_TIMEOUT", cfg.HTTPWriteTimeout, &timeouts.Write},
+		{"HTTP_IDLE_TIMEOUT", cfg.HTTPIdleTimeout, &timeouts.Idle},
+		{"SHUTDOWN_TIMEOUT", cfg.ShutdownTimeout, &timeouts.Shutdown},
+	} {
+		if timeout.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(timeout.value)
+		if err != nil || duration <= 0 {
+			return api.ServerTimeouts{}, fmt.Errorf("invalid %s %q: must be a positive duration", timeout.name, timeout.value)
+		}
+		*timeout.field = duration
+	}
+
+	// zero turns the delay off, for a server with no load balancer in front
+	if cfg.ShutdownDrainDelay != "" {
+		drain, err := time.ParseDuration(cfg.ShutdownDrainDelay)
+		if err != nil || drain < 0 {
+			return api.ServerTimeouts{}, fmt.Errorf("invalid SHUTDOWN_DRAIN_DELAY %q: must be a duration", cfg.ShutdownDrainDelay)
+		}
+		timeouts.Drain = drain
+	}
+	return timeouts, nil
+}
+
+// newTrustedProxies reads the comma separated TRUSTED_PROXIES, the addresses or
+// CIDRs of the load balancers in front of the server. Empty trusts no proxy.
+func newTrustedProxies(cfg config.Config) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(cfg.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
@@ -1,5 +1,5 @@
-// // //go:build wireinject
-// // // +build wireinject
+//go:build wireinject
+// +build wireinject
 
 package di
 
@@ -18,9 +18,14 @@ package di
 
 // 	wire.Build(
 
+// 		newLogger,
 // 		db.ConnectToDatabase,
+// 		db.MigratedTables,
+// 		newServerTimeouts,
 
 // 		middleware.NewAuthMiddleware,
+// 		middleware.NewAuditMiddleware,
+// 		middleware.NewRequestMiddleware,
 
 // 		handler.NewAdminHandler,
 
@@ -42,6 +47,20 @@ package di
 
 // 		handler.NewRazorpayHandler,
 
+// 		handler.NewPromotionHandler,
+// 		handler.NewLoyaltyHandler,
+// 		handler.NewReminderHandler,
+// 		handler.NewNotificationHandler,
+// 		handler.NewEventHandler,
+// 		handler.NewLockoutHandler,
+// 		handler.NewAccountHandler,
+// 		handler.NewAuditHandler,
+// 		handler.NewReportHandler,
+// 		handler.NewCatalogHandler,
+// 		handler.NewJobHandler,
+// 		handler.NewHealthHandler,
+// 		handler.NewTwoFactorHandler,
+
 // 		usecase.NewAdminUseCase,
 
 // 		usecase.NewUserUseCase,
@@ -62,6 +81,21 @@ package di
 
 // 		usecase.NewReferralUseCase,
 
+// 		usecase.NewPromotionUseCase,
+// 		usecase.NewLoyaltyUseCase,
+// 		usecase.NewReminderUseCase,
+// 		usecase.NewNotificationUseCase,
+// 		usecase.NewEventUseCase,
+// 		usecase.NewLockoutUseCase,
+// 		usecase.NewAccountUseCase,
+// 		usecase.NewAuditUseCase,
+// 		usecase.NewReportUseCase,
+// 		usecase.NewCatalogUseCase,
+// 		usecase.NewJobUseCase,
+// 		usecase.NewHealthUseCase,
+// 		usecase.NewTwoFactorUseCase,
+// 		usecase.NewOIDCUseCase,
+
 // 		repo.NewAdminRepository,
 
 // 		repo.NewUserRepository,
@@ -78,6 +112,22 @@ package di
 
 // 		repo.NewReferralRepository,
 
+// 		repo.NewPromotionRepository,
+// 		repo.NewLoyaltyRepository,
+// 		repo.NewReminderRepository,
+// 		repo.NewNotificationRepository,
+// 		repo.NewEventRepository,
+// 		repo.NewLockoutRepository,
+// 		repo.NewAccountRepository,
+// 		repo.NewAuditRepository,
+// 		repo.NewReportRepository,
+// 		repo.NewCatalogRepository,
+// 		newJobRepository,
+// 		repo.NewHealthRepository,
+// 		repo.NewTwoFactorRepository,
+// 		repo.NewOIDCRepository,
+// 		repo.NewTransactor,
+
 // 		repo.NewWalletRepository,
 
 // 		api.NewServerHTTP)
@@ -7,6 +7,8 @@
 package di
 
 import (
+	"time"
+
 	"github.com/anazibinurasheed/project-device-mart/pkg/api"
 	"github.com/anazibinurasheed/project-device-mart/pkg/api/handler"
 	"github.com/anazibinurasheed/project-device-mart/pkg/api/middleware"
@@ -14,46 +16,171 @@ import (
 	"github.com/anazibinurasheed/project-device-mart/pkg/db"
 	"github.com/anazibinurasheed/project-device-mart/pkg/repo"
 	"github.com/anazibinurasheed/project-device-mart/pkg/usecase"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
 )
 
 // Injectors from wire.go:
 
 func InitializeAPI(cfg config.Config) (*api.ServerHTTP, error) {
+	logger, err := newLogger(cfg)
+	if err != nil {
+		return nil, err
+	}
 	gormDB, err := db.ConnectToDatabase(cfg)
 	if err != nil {
 		return nil, err
 	}
+	passwordPolicy, err := helper.NewPasswordPolicy(cfg.PasswordMinLength, cfg.BreachedPasswordFile)
+	if err != nil {
+		return nil, err
+	}
+	loginLockout, err := time.ParseDuration(cfg.LoginLockout)
+	if err != nil && cfg.LoginLockout != "" {
+		return nil, err
+	}
 	userRepository := repo.NewUserRepository(gormDB)
-	userUseCase := usecase.NewUserUseCase(userRepository)
+	userUseCase := usecase.NewUserUseCase(userRepository, passwordPolicy)
 	userHandler := handler.NewUserHandler(userUseCase)
 	adminRepository := repo.NewAdminRepository(gormDB)
 	adminRepository.SetupDB()
-	adminUseCase := usecase.NewAdminUseCase(adminRepository, userRepository)
-	adminHandler := handler.NewAdminHandler(adminUseCase)
 	productRepository := repo.NewProductRepository(gormDB)
 	orderRepository := repo.NewOrderRepository(gormDB)
-	productUseCase := usecase.NewProductUseCase(productRepository, orderRepository)
+	promotionRepository := repo.NewPromotionRepository(gormDB)
+	productUseCase := usecase.NewProductUseCase(productRepository, orderRepository, promotionRepository)
 	productHandler := handler.NewProductHandler(productUseCase)
-	authUseCase := usecase.NewCommonUseCase(userRepository, adminRepository)
-	authHandler := handler.NewAuthHandler(authUseCase)
+	transactor := repo.NewTransactor(gormDB)
+	eventRepository := repo.NewEventRepository(gormDB)
+	eventUseCase := usecase.NewEventUseCase(eventRepository)
+	jobRepository, err := newJobRepository(cfg, gormDB)
+	if err != nil {
+		return nil, err
+	}
+	jobUseCase := usecase.NewJobUseCase(jobRepository, cfg.JobWorkers, logger)
+	err = jobUseCase.Schedule("event-dispatch", "@every 5s", eventUseCase.DispatchEvents)
+	if err != nil {
+		return nil, err
+	}
+	emailSender, err := helper.NewSender(helper.ChannelEmail, cfg.EmailSender)
+	if err != nil {
+		return nil, err
+	}
+	lockoutRepository := repo.NewLockoutRepository(gormDB)
+	lockoutUseCase := usecase.NewLockoutUseCase(lockoutRepository, transactor, helper.NewLockoutPolicy(cfg.LoginFreeAttempts, cfg.LoginMaxAttempts, loginLockout))
+	twoFactorRepository := repo.NewTwoFactorRepository(gormDB)
+	twoFactorUseCase := usecase.NewTwoFactorUseCase(twoFactorRepository, userRepository, lockoutUseCase)
+	authUseCase := usecase.NewCommonUseCase(userRepository, adminRepository, transactor, lockoutUseCase, twoFactorUseCase, passwordPolicy, emailSender, cfg.BaseURL)
+	authUseCase.Subscribe(eventUseCase)
 	cartRepository := repo.NewCartRepository(gormDB)
 	couponRepository := repo.NewCouponRepository(gormDB)
-	cartUseCase := usecase.NewCartUseCase(cartRepository, couponRepository)
+	loyaltyRepository := repo.NewLoyaltyRepository(gormDB)
+	walletRepository := repo.NewWalletRepository(gormDB)
+	cartUseCase := usecase.NewCartUseCase(cartRepository, couponRepository, orderRepository, promotionRepository, loyaltyRepository, walletRepository, productRepository, helper.ShippingPolicy{Fee: cfg.ShippingFee, FreeAbove: cfg.FreeShippingAbove})
+	oidcProviders, oidcMock, err := newOIDCProviders(cfg)
+	if err != nil {
+		return nil, err
+	}
+	oidcRepository := repo.NewOIDCRepository(gormDB)
+	oidcUseCase := usecase.NewOIDCUseCase(oidcRepository, userRepository, transactor, oidcProviders)
+	authHandler := handler.NewAuthHandler(authUseCase, cartUseCase, oidcUseCase)
 	cartHandler := handler.NewCartHandler(cartUseCase)
 	paymentRepository := repo.NewPaymentRepository(gormDB)
-	orderUseCase := usecase.NewOrderUseCase(userRepository, cartUseCase, paymentRepository, orderRepository, couponRepository, productRepository)
+	referralRepository := repo.NewReferralRepository(gormDB)
+	accountRepository := repo.NewAccountRepository(gormDB)
+	adminUseCase := usecase.NewAdminUseCase(adminRepository, userRepository, accountRepository, orderRepository, referralRepository)
+	adminHandler := handler.NewAdminHandler(adminUseCase)
+	referralUseCase := usecase.NewReferralUseCase(referralRepository, orderRepository, userRepository)
+	referralUseCase.Subscribe(eventUseCase)
+	loyaltyUseCase := usecase.NewLoyaltyUseCase(loyaltyRepository, productRepository)
+	loyaltyUseCase.Subscribe(eventUseCase)
+	err = jobUseCase.Schedule("loyalty-expiry", "@hourly", loyaltyUseCase.ExpirePoints)
+	if err != nil {
+		return nil, err
+	}
+	smsSender, err := helper.NewSender(helper.ChannelSMS, cfg.SMSSender)
+	if err != nil {
+		return nil, err
+	}
+	reminderSteps, err := helper.ParseDurations(cfg.CartReminderAfter, []time.Duration{time.Hour, 24 * time.Hour, 72 * time.Hour})
+	if err != nil {
+		return nil, err
+	}
+	reminderRepository := repo.NewReminderRepository(gormDB)
+	senders := []helper.Sender{emailSender, smsSender}
+	reminderUseCase := usecase.NewReminderUseCase(reminderRepository, userRepository, senders, reminderSteps, cfg.BaseURL)
+	reminderUseCase.Subscribe(eventUseCase)
+	err = jobUseCase.Schedule("cart-reminders", "*/15 * * * *", reminderUseCase.SendCartReminders)
+	if err != nil {
+		return nil, err
+	}
+	notificationRepository := repo.NewNotificationRepository(gormDB)
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepository, userRepository, productRepository, senders)
+	notificationUseCase.Subscribe(eventUseCase)
+	err = jobUseCase.Schedule("notification-dispatch", "@every 30s", notificationUseCase.DispatchNotifications)
+	if err != nil {
+		return nil, err
+	}
+	orderUseCase := usecase.NewOrderUseCase(userRepository, cartUseCase, paymentRepository, orderRepository, couponRepository, productRepository, loyaltyUseCase, walletRepository, eventRepository, transactor)
 	orderHandler := handler.NewOrderHandler(orderUseCase)
-	couponUseCase := usecase.NewCouponUseCase(couponRepository)
+	couponUseCase := usecase.NewCouponUseCase(couponRepository, cartRepository, orderRepository)
+	err = jobUseCase.Schedule("coupon-expiry", "@hourly", couponUseCase.RemoveExpiredCoupons)
+	if err != nil {
+		return nil, err
+	}
 	couponHandler := handler.NewCouponHandler(couponUseCase)
-	referralRepository := repo.NewReferralRepository(gormDB)
-	referralUseCase := usecase.NewReferralUseCase(referralRepository, orderRepository)
 	referralHandler := handler.NewReferralHandler(referralUseCase)
 	authMiddleware := middleware.NewAuthMiddleware(userUseCase)
-	walletRepository := repo.NewWalletRepository(gormDB)
 	walletUseCase := usecase.NewWalletUseCase(walletRepository, orderRepository, cartUseCase)
 	walletHandler := handler.NewWalletHandler(walletUseCase, orderUseCase)
 	razorpayUseCase := usecase.NewRazorpayUseCase(paymentRepository, cartUseCase, userRepository)
 	razorpayHandler := handler.NewRazorpayHandler(razorpayUseCase, orderUseCase)
-	serverHTTP := api.NewServerHTTP(userHandler, adminHandler, productHandler, authHandler, cartHandler, orderHandler, couponHandler, referralHandler, authMiddleware, walletHandler, razorpayHandler)
+	promotionUseCase := usecase.NewPromotionUseCase(promotionRepository, productRepository, transactor)
+	err = jobUseCase.Schedule("promotion-scheduler", "* * * * *", promotionUseCase.RunScheduler)
+	if err != nil {
+		return nil, err
+	}
+	promotionHandler := handler.NewPromotionHandler(promotionUseCase)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyUseCase)
+	reminderHandler := handler.NewReminderHandler(reminderUseCase)
+	notificationHandler := handler.NewNotificationHandler(notificationUseCase)
+	eventHandler := handler.NewEventHandler(eventUseCase)
+	lockoutHandler := handler.NewLockoutHandler(lockoutUseCase)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorUseCase)
+	accountGrace, err := time.ParseDuration(cfg.AccountDeletionGrace)
+	if err != nil && cfg.AccountDeletionGrace != "" {
+		return nil, err
+	}
+	accountUseCase := usecase.NewAccountUseCase(accountRepository, userRepository, transactor, emailSender, accountGrace)
+	err = jobUseCase.Schedule("account-deletion", "@hourly", accountUseCase.DeleteDueAccounts)
+	if err != nil {
+		return nil, err
+	}
+	accountHandler := handler.NewAccountHandler(accountUseCase)
+	auditRepository := repo.NewAuditRepository(gormDB)
+	auditUseCase := usecase.NewAuditUseCase(auditRepository)
+	auditMiddleware := middleware.NewAuditMiddleware(auditUseCase)
+	auditHandler := handler.NewAuditHandler(auditUseCase)
+	reportRepository := repo.NewReportRepository(gormDB)
+	reportUseCase := usecase.NewReportUseCase(reportRepository, cfg.SalesTaxRate)
+	reportHandler := handler.NewReportHandler(reportUseCase)
+	catalogRepository := repo.NewCatalogRepository(gormDB)
+	catalogUseCase := usecase.NewCatalogUseCase(catalogRepository, productRepository, jobUseCase)
+	catalogHandler := handler.NewCatalogHandler(catalogUseCase)
+	jobHandler := handler.NewJobHandler(jobUseCase)
+	migratedTables, err := db.MigratedTables(gormDB)
+	if err != nil {
+		return nil, err
+	}
+	healthRepository := repo.NewHealthRepository(gormDB)
+	healthUseCase := usecase.NewHealthUseCase(healthRepository, migratedTables)
+	healthHandler := handler.NewHealthHandler(healthUseCase)
+	serverTimeouts, err := newServerTimeouts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	requestMiddleware := middleware.NewRequestMiddleware(logger)
+	serverHTTP, err := api.NewServerHTTP(userHandler, adminHandler, productHandler, authHandler, cartHandler, orderHandler, couponHandler, referralHandler, authMiddleware, walletHandler, razorpayHandler, promotionHandler, loyaltyHandler, reminderHandler, notificationHandler, eventHandler, lockoutHandler, twoFactorHandler, accountHandler, auditMiddleware, auditHandler, reportHandler, catalogHandler, jobHandler, jobUseCase, healthHandler, requestMiddleware, logger, serverTimeouts, newTrustedProxies(cfg), oidcMock)
+	if err != nil {
+		return nil, err
+	}
 	return serverHTTP, nil
 }
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// AccountDeletion is a request of the user to close the account. The account is
+// anonymised once ScheduledFor passes, unless the user cancels it before.
+type AccountDeletion struct {
+	ID           uint   `gorm:"not null;primaryKey"`
+	UserID       uint   `gorm:"not null;index"`
+	User         User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Status       string `gorm:"not null;default:pending;index"` // pending, cancelled or completed
+	Reason       string
+	RequestedAt  time.Time `gorm:"not null"`
+	ScheduledFor time.Time `gorm:"not null"`
+	ClosedAt     *time.Time
+}
@@ -1,7 +1,12 @@
 package domain
 
-// this table will not migrated because it is created for follow the same pattern .
-type Admin struct {
-	UserName string `json:"username" gorm:"not null" binding:"required,min=3,max=25"`
-	Password string `json:"password" gorm:"not null" binding:"required"`
+import "time"
+
+// AdminNote is a note the admin keeps on a customer account, only shown to the admin.
+type AdminNote struct {
+	ID        uint      `gorm:"not null;primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	User      User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Note      string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
 }
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// AuditLog records a mutating admin request with the state of the entity it
+// changed before and after the request.
+type AuditLog struct {
+	ID          uint   `gorm:"not null;primaryKey"`
+	Actor       string `gorm:"not null;index"`
+	Action      string `gorm:"not null;index"` // method and route, e.g. PUT /api/v1/admin/product/block-product/:productID
+	Path        string `gorm:"not null"`
+	EntityType  string `gorm:"index"`
+	EntityID    string `gorm:"index"`
+	Before      string `gorm:"type:jsonb"`
+	After       string `gorm:"type:jsonb"`
+	Changes     string `gorm:"type:jsonb"` // fields that differ, {"field": {"before": .., "after": ..}}
+	RequestBody string `gorm:"type:jsonb"` // secrets redacted
+	StatusCode  int    `gorm:"not null"`
+	IP          string
+	UserAgent   string
+	CreatedAt   time.Time `gorm:"not null;index"`
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type Cart struct {
 	ID        uint    `gorm:"not null;primaryKey"`
 	UserID    uint    `gorm:"not null"`
@@ -8,4 +10,25 @@ type Cart struct {
 	Product   Product `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Qty       int     `gorm:"not null"`
 	// CouponID  int
+	AddedPrice int       `gorm:"not null;default:0"` // product price when it was added, to detect price changes
+	CreatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+// GuestCart is a cart line of a visitor, identified by the id carried in the signed cart token.
+type GuestCart struct {
+	ID        uint      `gorm:"not null;primaryKey"`
+	CartID    string    `gorm:"not null;index"`
+	ProductID uint      `gorm:"not null"`
+	Product   Product   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Qty       int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// GuestCoupon is a coupon applied on a guest cart, carried over to the user on login.
+type GuestCoupon struct {
+	ID       uint   `gorm:"not null;primaryKey"`
+	CartID   string `gorm:"not null;index"`
+	CouponID uint   `gorm:"not null"`
+	Coupon   Coupon `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// ProductImport is a bulk product import processed in the background. The parsed
+// rows are kept until the import is processed, the row errors make up the
+// validation report.
+type ProductImport struct {
+	ID            uint   `gorm:"primaryKey;unique;autoIncrement;not null"`
+	Format        string `gorm:"not null"` // csv or json
+	FileName      string
+	DryRun        bool   `gorm:"default:false"`           // validates the rows without saving them
+	Status        string `gorm:"not null;default:queued"` // queued, running, completed or failed
+	Rows          string `gorm:"type:jsonb"`              // the rows to process, cleared once processed
+	TotalRows     int    `gorm:"not null;default:0"`
+	ProcessedRows int    `gorm:"not null;default:0"`
+	Created       int    `gorm:"not null;default:0"`
+	Updated       int    `gorm:"not null;default:0"`
+	Failed        int    `gorm:"not null;default:0"`
+	Errors        string `gorm:"type:jsonb"`
+	Error         string // why a failed import stopped
+	CreatedAt     time.Time
+	StartedAt     *time.Time
+	FinishedAt    *time.Time
+}
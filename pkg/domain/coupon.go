@@ -6,15 +6,45 @@ type Coupon struct {
 	ID                uint      `gorm:"primaryKey,unique,not null"`
 	Code              string    `gorm:"unique,not null"`
 	CouponName        string    `gorm:"not null"`
+	RuleType          string    `gorm:"not null;default:percent"` // percent, flat, buy_x_get_y or free_shipping
 	MinOrderValue     float64   `gorm:"not null"`
 	DiscountPercent   float64   `gorm:"not null"`
 	DiscountMaxAmount float64   `gorm:"not null"`
+	DiscountAmount    float64   `gorm:"default:0"`
+	BuyQuantity       int       `gorm:"default:0"`
+	GetQuantity       int       `gorm:"default:0"`
+	FirstOrderOnly    bool      `gorm:"default:false"`
+	UserSegment       string    // empty for everyone, else new, returning or high_value
+	UsageLimit        int       `gorm:"default:0"` // 0 means unlimited
+	PerUserLimit      int       `gorm:"default:1"`
+	IsStackable       bool      `gorm:"default:false"`
 	ValidFrom         time.Time `gorm:"not null"`
 	ValidTill         time.Time `gorm:"not null"`
 	ValidDays         int       `gorm:"not null"`
 	IsBlocked         bool      `gorm:"default:false"`
 }
 
+// CouponEligibility restricts a coupon to a category, product or brand.
+// A coupon without any eligibility rows applies to the whole cart.
+type CouponEligibility struct {
+	ID       uint   `gorm:"primaryKey,unique,not null"`
+	CouponID uint   `gorm:"not null"`
+	Coupon   Coupon `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Scope    string `gorm:"not null"` // category, product or brand
+	Value    string `gorm:"not null"`
+}
+
+// OrderLineCoupon is the share of a line discount given by one of the coupons
+// stacked on the order, the discount of a line is the sum of its rows.
+type OrderLineCoupon struct {
+	ID          uint      `gorm:"primaryKey,unique,not null"`
+	OrderLineID uint      `gorm:"not null"`
+	OrderLine   OrderLine `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CouponID    uint      `gorm:"not null"`
+	Coupon      Coupon    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Discount    float32   `gorm:"not null"`
+}
+
 type CouponTracking struct {
 	ID       uint   `gorm:"primaryKey,unique,not null"`
 	CouponID int    `gorm:"not null"`
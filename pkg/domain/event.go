@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// DomainEvent is an event in the outbox, written in the transaction of the change
+// it records and delivered to the subscribers by the dispatcher. An event whose
+// subscribers keep failing is dead-lettered.
+type DomainEvent struct {
+	ID            uint   `gorm:"not null;primaryKey"`
+	Type          string `gorm:"not null;index"`
+	AggregateID   uint   `gorm:"not null"` // id of the order or the user the event is about
+	Payload       string `gorm:"type:jsonb;not null"`
+	Status        string `gorm:"not null;default:pending;index"` // pending, processed or dead
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time `gorm:"not null"`
+	ProcessedAt   *time.Time
+}
+
+// EventDelivery records a subscriber that handled the event, so a retry only
+// runs the subscribers that failed.
+type EventDelivery struct {
+	ID            uint        `gorm:"not null;primaryKey"`
+	DomainEventID uint        `gorm:"not null;uniqueIndex:idx_event_subscriber"`
+	DomainEvent   DomainEvent `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Subscriber    string      `gorm:"not null;uniqueIndex:idx_event_subscriber"`
+	DeliveredAt   time.Time   `gorm:"not null"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Job is a unit of background work run by the job workers. A job with a unique
+// key is enqueued once while it is queued or running, scheduled jobs use the
+// name of the schedule so a restart or a second instance does not run them twice.
+type Job struct {
+	ID          uint    `gorm:"not null;primaryKey"`
+	Type        string  `gorm:"not null;index"`
+	Payload     string  `gorm:"type:jsonb;not null;default:'{}'"`
+	Schedule    string  // cron spec of a recurring job
+	UniqueKey   *string `gorm:"uniqueIndex:idx_jobs_active_unique_key,where:status = 'queued' OR status = 'running'"`
+	Status      string  `gorm:"not null;default:queued;index"` // queued, running, completed or failed
+	Attempts    int     `gorm:"not null;default:0"`
+	MaxAttempts int     `gorm:"not null;default:5"`
+	LastError   string
+	RunAt       time.Time `gorm:"not null;index"`
+	LockedUntil *time.Time
+	CreatedAt   time.Time `gorm:"not null"`
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// LoginLockout counts the failed logins of an account or of a client IP, the
+// logins of the key are refused until LockedUntil.
+type LoginLockout struct {
+	ID            uint   `gorm:"not null;primaryKey"`
+	LockKey       string `gorm:"not null;uniqueIndex"` // e.g. user:12, admin:root or ip:10.0.0.1
+	Kind          string `gorm:"not null"`             // account or ip
+	Failures      int    `gorm:"not null;default:0"`
+	LockedUntil   *time.Time
+	LastFailureAt time.Time `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+}
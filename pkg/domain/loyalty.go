@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// LoyaltyRule sets how many points are earned per 100 spent on a delivered order line.
+// A rule without a category applies to every category.
+type LoyaltyRule struct {
+	ID            uint    `gorm:"primaryKey;unique;autoIncrement;not null"`
+	Name          string  `gorm:"not null"`
+	CategoryID    uint    `gorm:"default:0"`
+	MinSpend      float32 `gorm:"default:0"` // spend tier, the order line amount from which the rule applies
+	PointsPercent float32 `gorm:"not null"`
+	IsBlocked     bool    `gorm:"default:false"`
+}
+
+// LoyaltyTransaction is an entry of the points ledger. Earn and refund entries are
+// lots that are consumed oldest expiry first, RemainingPoints is what is left of the lot.
+type LoyaltyTransaction struct {
+	ID              uint   `gorm:"primaryKey;unique;autoIncrement;not null"`
+	UserID          uint   `gorm:"not null"`
+	User            User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	OrderID         uint   `gorm:"default:0"`
+	Points          int    `gorm:"not null"` // negative for redeem, expire and reverse
+	RemainingPoints int    `gorm:"default:0"`
+	Type            string `gorm:"not null"` // earn, redeem, refund, reverse or expire
+	ExpiresAt       *time.Time
+	CreatedAt       time.Time `gorm:"not null"`
+}
+
+// AppliedPoints are the points the user chose to redeem on the current cart.
+type AppliedPoints struct {
+	ID     uint `gorm:"primaryKey;unique;autoIncrement;not null"`
+	UserID uint `gorm:"not null;unique"`
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Points int  `gorm:"not null"`
+}
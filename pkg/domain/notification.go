@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Notification is a message in the outbox, sent by the dispatcher and retried
+// with a backoff until it is sent or runs out of attempts.
+type Notification struct {
+	ID            uint   `gorm:"not null;primaryKey"`
+	UserID        uint   `gorm:"not null;index"`
+	User          User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Event         string `gorm:"not null"`
+	Channel       string `gorm:"not null"`
+	Recipient     string `gorm:"not null"`
+	Subject       string `gorm:"not null"`
+	Body          string `gorm:"not null"`
+	Status        string `gorm:"not null;default:pending;index"` // pending, sent or failed
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time `gorm:"not null"`
+	SentAt        *time.Time
+}
+
+// NotificationPreference holds the channels the user gets notifications on,
+// users without one get every channel.
+type NotificationPreference struct {
+	ID        uint      `gorm:"not null;primaryKey"`
+	UserID    uint      `gorm:"not null;unique"`
+	User      User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Email     bool      `gorm:"not null;default:true"`
+	SMS       bool      `gorm:"not null;default:true"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
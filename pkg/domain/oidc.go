@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// OIDCLoginState is a social login started by the user and not yet completed, it
+// keeps the PKCE verifier and the nonce on the server until the callback.
+type OIDCLoginState struct {
+	ID           uint      `gorm:"not null;primaryKey"`
+	State        string    `gorm:"not null;uniqueIndex"`
+	Provider     string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"not null;index"`
+}
+
+// UserIdentity links a user to the account of a social login provider.
+type UserIdentity struct {
+	ID        uint   `gorm:"not null;primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Provider  string `gorm:"not null;uniqueIndex:idx_provider_subject"`
+	Subject   string `gorm:"not null;uniqueIndex:idx_provider_subject"`
+	Email     string
+	CreatedAt time.Time `gorm:"not null"`
+}
@@ -9,7 +9,7 @@ type OrderStatus struct {
 
 type OrderLine struct {
 	ID              uint          `gorm:"not null;primaryKey"`
-	UserID          uint          `gorm:"not null"`
+	UserID          uint          `gorm:"not null;index"`
 	User            User          `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	AddressesID     uint          `gorm:"not null"`
 	Addresses       Addresses     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
@@ -20,8 +20,10 @@ type OrderLine struct {
 	OrderStatusId   int           `gorm:"not null"`
 	Qty             int           `gorm:"not null"`
 	Price           float32       `gorm:"not null"`
+	Discount        float32       `gorm:"default:0"` // coupon discount allocated to the whole line
+	PointsRedeemed  int           `gorm:"default:0"` // loyalty points paid for the whole line
 	CouponID        uint
-	CreatedAt       time.Time
+	CreatedAt       time.Time     `gorm:"index"` // the sales reports read order lines by date
 	UpdatedAt       time.Time
 }
 
@@ -1,7 +1,23 @@
 package domain
 
+import "time"
+
 // changed int to uint
 type PaymentMethod struct {
 	ID         uint   `gorm:"primaryKey;AutoIncrement;unique"`
 	MethodName string `gorm:"not null;unique"`
 }
+
+// OrderPayment is one tender used to pay an order line, an order line paid
+// partly from the wallet has a wallet payment and an online or COD payment.
+type OrderPayment struct {
+	ID              uint          `gorm:"primaryKey;unique;autoIncrement;not null"`
+	OrderLineID     uint          `gorm:"not null"`
+	OrderLine       OrderLine     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	PaymentMethodID int           `gorm:"not null"`
+	PaymentMethod   PaymentMethod `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Amount          float32       `gorm:"not null"`
+	IsRefunded      bool          `gorm:"default:false"`
+	IsShipping      bool          `gorm:"default:false"` // the tender of the shipping fee
+	CreatedAt       time.Time     `gorm:"not null"`
+}
@@ -14,11 +14,14 @@ type Product struct {
 	Category           Category `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Brand              string
 	Price              int    `gorm:"not null"`
-	SKU                string `gorm:"not null"`
+	MRP                int    `gorm:"default:0"`      // shown struck through when above the selling price
+	SKU                string `gorm:"not null;index"` // the bulk import updates the products by sku
 	ProductName        string `gorm:"not null"`
 	ProductDescription string `gorm:"not null"`
 	Images             JSONB
 	IsBlocked          bool `gorm:"default:false"`
+	Stock              int  `gorm:"not null;default:0"`
+	MaxPerOrder        int  `gorm:"not null;default:0"` // units allowed in one order, zero uses the store default
 }
 
 type Rating struct {
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Promotion is an automatic discount applied to matching products without a coupon code.
+// A promotion without a product and category applies store wide.
+type Promotion struct {
+	ID              uint      `gorm:"primaryKey;unique;autoIncrement;not null"`
+	Name            string    `gorm:"not null"`
+	Type            string    `gorm:"not null"` // category_sale or flash_sale
+	CategoryID      uint      `gorm:"default:0"`
+	ProductID       uint      `gorm:"default:0"`
+	DiscountPercent float64   `gorm:"not null"`
+	StartsAt        time.Time `gorm:"not null"`
+	EndsAt          time.Time `gorm:"not null"`
+	IsActive        bool      `gorm:"default:false"` // maintained by the scheduler
+	IsBlocked       bool      `gorm:"default:false"`
+}
+
+// ScheduledPrice changes the price of a product at StartsAt and, when EndsAt is set,
+// restores the previous price at EndsAt.
+type ScheduledPrice struct {
+	ID            uint      `gorm:"primaryKey;unique;autoIncrement;not null"`
+	ProductID     uint      `gorm:"not null"`
+	Product       Product   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Price         int       `gorm:"not null"`
+	MRP           int       `gorm:"default:0"`
+	StartsAt      time.Time `gorm:"not null"`
+	EndsAt        *time.Time
+	Status        string `gorm:"not null;default:pending"` // pending, active, completed or cancelled
+	PreviousPrice int    `gorm:"default:0"`
+	PreviousMRP   int    `gorm:"default:0"`
+}
@@ -1,8 +1,36 @@
 package domain
 
+import "time"
+
 type Referral struct {
-	ID     uint   `gorm:"not null,unique,primaryKey"`
-	UserID uint   `gorm:"not null,unique"`
-	User   User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Code   string `gorm:"not null,unique"`
+	ID       uint   `gorm:"not null,unique,primaryKey"`
+	UserID   uint   `gorm:"not null,unique"`
+	User     User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Code     string `gorm:"not null,unique"`
+	DeviceID string `gorm:"default:''"` // device the owner fetched the code from
+}
+
+// ReferralProgramme holds the reward amounts, the latest row is the one in use.
+type ReferralProgramme struct {
+	ID             uint      `gorm:"primaryKey;unique;autoIncrement;not null"`
+	ReferrerReward float32   `gorm:"not null"`
+	RefereeReward  float32   `gorm:"not null"`
+	IsActive       bool      `gorm:"default:true"`
+	CreatedAt      time.Time `gorm:"not null"`
+}
+
+// ReferralClaim is a referral code claimed by a new user. The rewards are copied
+// from the programme at claim time and credited after the referee's first delivered order.
+type ReferralClaim struct {
+	ID             uint      `gorm:"primaryKey;unique;autoIncrement;not null"`
+	ReferralID     uint      `gorm:"not null"`
+	Referral       Referral  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ReferrerID     uint      `gorm:"not null"`
+	RefereeID      uint      `gorm:"not null;unique"` // one claim per user
+	ReferrerReward float32   `gorm:"not null"`
+	RefereeReward  float32   `gorm:"not null"`
+	Status         string    `gorm:"not null;default:pending"` // pending or released
+	DeviceID       string    `gorm:"default:''"`
+	ClaimedAt      time.Time `gorm:"not null"`
+	ReleasedAt     *time.Time
 }
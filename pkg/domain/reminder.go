@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// AbandonedCart follows a cart left idle from its first reminder until the user
+// orders it (recovered) or empties it (lost).
+type AbandonedCart struct {
+	ID             uint      `gorm:"not null;primaryKey"`
+	UserID         uint      `gorm:"not null;index"`
+	User           User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CartValue      float32   `gorm:"not null"`
+	ItemCount      int       `gorm:"not null"`
+	LastActivity   time.Time `gorm:"not null"`
+	RemindersSent  int       `gorm:"not null;default:0"`
+	Status         string    `gorm:"not null;default:open"` // open, recovered or lost
+	RecoveredValue float32   `gorm:"not null;default:0"`
+	CreatedAt      time.Time `gorm:"not null"`
+	ClosedAt       *time.Time
+}
+
+// CartReminder is a reminder of an abandoned cart sent over one channel.
+type CartReminder struct {
+	ID              uint          `gorm:"not null;primaryKey"`
+	AbandonedCartID uint          `gorm:"not null;index"`
+	AbandonedCart   AbandonedCart `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Step            int           `gorm:"not null"`
+	Channel         string        `gorm:"not null"`
+	Status          string        `gorm:"not null"` // sent or failed
+	Error           string
+	SentAt          time.Time `gorm:"not null"`
+}
+
+// ReminderUnsubscribe opts the user out of the cart reminders.
+type ReminderUnsubscribe struct {
+	ID        uint      `gorm:"not null;primaryKey"`
+	UserID    uint      `gorm:"not null;unique"`
+	User      User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CreatedAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// TwoFactor is the TOTP enrolment of an account, the subject is user:<id> for the
+// users and admin:<username> for the admin. The secret is stored encrypted.
+type TwoFactor struct {
+	ID           uint   `gorm:"not null;primaryKey"`
+	Subject      string `gorm:"not null;uniqueIndex"`
+	Secret       string `gorm:"not null"`
+	Enabled      bool   `gorm:"not null;default:false"`
+	LastUsedStep int64  `gorm:"not null;default:0"` // time step of the last accepted code, codes are single use
+	EnabledAt    *time.Time
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+// RecoveryCode is a one time code that replaces the totp code when the device is
+// lost, only its sha256 is stored.
+type RecoveryCode struct {
+	ID          uint      `gorm:"not null;primaryKey"`
+	TwoFactorID uint      `gorm:"not null;index"`
+	TwoFactor   TwoFactor `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CodeHash    string    `gorm:"not null"`
+	UsedAt      *time.Time
+	CreatedAt   time.Time `gorm:"not null"`
+}
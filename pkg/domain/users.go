@@ -5,15 +5,17 @@ import (
 )
 
 type User struct {
-	ID        uint   `gorm:"primaryKey;unique;autoIncrement;not null"`
-	UserName  string `gorm:"not null"`
-	Email     string `gorm:"not null"`
-	Phone     int    `gorm:"not null"`
-	Password  string `gorm:"not null"`
-	IsAdmin   bool   `gorm:"default:false"`
-	IsBlocked bool   `gorm:"default:false"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              uint   `gorm:"primaryKey;unique;autoIncrement;not null"`
+	UserName        string `gorm:"not null"`
+	Email           string `gorm:"not null"`
+	EmailVerified   bool   `gorm:"default:false"`
+	EmailVerifiedAt *time.Time
+	Phone           int    `gorm:"not null"`
+	Password        string `gorm:"not null"`
+	IsAdmin         bool   `gorm:"default:false"`
+	IsBlocked       bool   `gorm:"default:false"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 type Addresses struct {
@@ -17,3 +17,12 @@ type WalletTransactionHistory struct {
 	Amount          float32   `gorm:"not null"`
 	TransactionType string    `gorm:"not null"` // "credit" or "debit"
 }
+
+// AppliedWallet is the wallet amount the user chose to pay the current cart with,
+// the rest is paid online or on delivery. Zero uses as much of the balance as needed.
+type AppliedWallet struct {
+	ID     uint    `gorm:"primaryKey;unique;autoIncrement;not null"`
+	UserID uint    `gorm:"not null;unique"`
+	User   User    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Amount float32 `gorm:"default:0"`
+}
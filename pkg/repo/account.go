@@ -0,0 +1,164 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type accountDatabase struct {
+	DB *gorm.DB
+}
+
+func NewAccountRepository(DB *gorm.DB) interfaces.AccountRepository {
+	return &accountDatabase{
+		DB: DB,
+	}
+}
+
+func (ad *accountDatabase) GetExportOrders(ctx context.Context, userID int) ([]response.ExportOrder, error) {
+	var orders = make([]response.ExportOrder, 0)
+	query := `SELECT
+    o.id AS order_id,
+    o.product_id,
+    p.product_name,
+    o.qty,
+    o.price,
+    o.discount,
+    o.points_redeemed,
+    s.status AS order_status,
+    m.method_name AS payment_method,
+    CONCAT(a.name, ', ', a.locality, ', ', a.address_line, ', ', a.district, ', ', states.name, ', ', a.landmark, ', ', a.pincode, ', ', a.phone_number, ', ', a.alternative_phone) AS delivery_address,
+    o.created_at
+FROM
+    order_lines o
+INNER JOIN products p ON o.product_id = p.id
+INNER JOIN order_statuses s ON o.order_status_id = s.id
+INNER JOIN payment_methods m ON o.payment_method_id = m.id
+INNER JOIN addresses a ON o.addresses_id = a.id
+INNER JOIN states states ON a.state_id = states.id
+WHERE o.user_id = $1
+ORDER BY o.id;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID).Scan(&orders).Error
+	return orders, err
+}
+
+func (ad *accountDatabase) GetExportRatings(ctx context.Context, userID int) ([]response.ExportRating, error) {
+	var ratings = make([]response.ExportRating, 0)
+	query := `SELECT r.id, r.product_id, p.product_name, r.rating, r.description FROM ratings r
+	INNER JOIN products p ON r.product_id = p.id WHERE r.user_id = $1 ORDER BY r.id;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID).Scan(&ratings).Error
+	return ratings, err
+}
+
+func (ad *accountDatabase) GetExportWishlist(ctx context.Context, userID int) ([]response.ExportWishlistItem, error) {
+	var wishlist = make([]response.ExportWishlistItem, 0)
+	query := `SELECT w.product_id, p.product_name, p.brand, p.price FROM wishlists w
+	INNER JOIN products p ON w.product_id = p.id WHERE w.user_id = $1 ORDER BY w.id;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID).Scan(&wishlist).Error
+	return wishlist, err
+}
+
+func (ad *accountDatabase) GetWalletBalance(ctx context.Context, userID int) (float32, error) {
+	var balance float32
+	query := `SELECT COALESCE(SUM(amount),0) FROM wallets WHERE user_id = $1;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID).Scan(&balance).Error
+	return balance, err
+}
+
+func (ad *accountDatabase) GetWalletHistory(ctx context.Context, userID int) ([]response.WalletTransactionHistory, error) {
+	var history = make([]response.WalletTransactionHistory, 0)
+	query := `SELECT * FROM wallet_transaction_histories WHERE user_id = $1 ORDER BY transaction_time;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID).Scan(&history).Error
+	return history, err
+}
+
+// CountOpenOrders counts the orders of the user that are not delivered, cancelled or returned yet.
+func (ad *accountDatabase) CountOpenOrders(ctx context.Context, userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM order_lines o INNER JOIN order_statuses s ON o.order_status_id = s.id
+	WHERE o.user_id = $1 AND s.status NOT IN ('Delivered','Cancelled','Returned');`
+	err := ad.DB.WithContext(ctx).Raw(query, userID).Scan(&count).Error
+	return count, err
+}
+
+func (ad *accountDatabase) InsertDeletion(ctx context.Context, userID int, reason string, requestedAt, scheduledFor time.Time) (response.AccountDeletion, error) {
+	var deletion response.AccountDeletion
+	query := `INSERT INTO account_deletions (user_id,status,reason,requested_at,scheduled_for) VALUES ($1,'pending',$2,$3,$4) RETURNING *;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID, reason, requestedAt, scheduledFor).Scan(&deletion).Error
+	return deletion, err
+}
+
+func (ad *accountDatabase) FindLatestDeletion(ctx context.Context, userID int) (response.AccountDeletion, error) {
+	var deletion response.AccountDeletion
+	query := `SELECT * FROM account_deletions WHERE user_id = $1 ORDER BY id DESC FETCH FIRST 1 ROW ONLY;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID).Scan(&deletion).Error
+	return deletion, err
+}
+
+// CloseDeletion moves a pending deletion to status, it returns an empty row when
+// the deletion is no longer pending.
+func (ad *accountDatabase) CloseDeletion(ctx context.Context, deletionID int, status string, closedAt time.Time) (response.AccountDeletion, error) {
+	var deletion response.AccountDeletion
+	query := `UPDATE account_deletions SET status = $2, closed_at = $3 WHERE id = $1 AND status = 'pending' RETURNING *;`
+	err := ad.DB.WithContext(ctx).Raw(query, deletionID, status, closedAt).Scan(&deletion).Error
+	return deletion, err
+}
+
+func (ad *accountDatabase) GetDueDeletions(ctx context.Context, now time.Time) ([]response.AccountDeletion, error) {
+	var deletions = make([]response.AccountDeletion, 0)
+	query := `SELECT * FROM account_deletions WHERE status = 'pending' AND scheduled_for <= $1 ORDER BY scheduled_for;`
+	err := ad.DB.WithContext(ctx).Raw(query, now).Scan(&deletions).Error
+	return deletions, err
+}
+
+// AnonymiseUser keeps the user row the orders point to but removes everything that
+// identifies the person, the account can no longer log in.
+func (ad *accountDatabase) AnonymiseUser(ctx context.Context, userID int, email, password string, now time.Time) error {
+	query := `UPDATE users SET user_name = 'Deleted user', email = $2, email_verified = false, email_verified_at = NULL,
+	phone = 0, password = $3, is_blocked = true, updated_at = $4 WHERE id = $1;`
+	return ad.DB.WithContext(ctx).Exec(query, userID, email, password, now).Error
+}
+
+// AnonymiseOrderAddresses blanks the contact details of the addresses orders were
+// delivered to. The pincode, district and state stay for the tax records.
+func (ad *accountDatabase) AnonymiseOrderAddresses(ctx context.Context, userID int) error {
+	query := `UPDATE addresses SET name = 'Deleted user', phone_number = '', alternative_phone = '', locality = '',
+	address_line = '', landmark = '', is_default = false
+	WHERE user_id = $1 AND id IN (SELECT addresses_id FROM order_lines WHERE user_id = $1);`
+	return ad.DB.WithContext(ctx).Exec(query, userID).Error
+}
+
+// DeletePersonalData removes the rows of the user that no order, payment or
+// ledger depends on. Used coupon trackings are kept as they count towards the
+// coupon usage limits, and the referral code is replaced so nobody can claim it
+// while the claims already made stay in place.
+func (ad *accountDatabase) DeletePersonalData(ctx context.Context, userID int, twoFactorSubject string) error {
+	queries := []string{
+		`DELETE FROM addresses WHERE user_id = $1 AND id NOT IN (SELECT addresses_id FROM order_lines WHERE user_id = $1);`,
+		`DELETE FROM carts WHERE user_id = $1;`,
+		`DELETE FROM applied_wallets WHERE user_id = $1;`,
+		`DELETE FROM applied_points WHERE user_id = $1;`,
+		`DELETE FROM coupon_trackings WHERE user_id = $1 AND is_used = false;`,
+		`DELETE FROM referral_claims WHERE referee_id = $1 AND status = 'pending';`,
+		`UPDATE referrals SET code = CONCAT('deleted-', md5(random()::text)), device_id = '' WHERE user_id = $1;`,
+		`DELETE FROM wishlists WHERE user_id = $1;`,
+		`DELETE FROM ratings WHERE user_id = $1;`,
+		`DELETE FROM abandoned_carts WHERE user_id = $1;`,
+		`DELETE FROM reminder_unsubscribes WHERE user_id = $1;`,
+		`DELETE FROM notifications WHERE user_id = $1;`,
+		`DELETE FROM notification_preferences WHERE user_id = $1;`,
+		`DELETE FROM user_identities WHERE user_id = $1;`,
+	}
+	for _, query := range queries {
+		if err := ad.DB.WithContext(ctx).Exec(query, userID).Error; err != nil {
+			return err
+		}
+	}
+
+	query := `DELETE FROM two_factors WHERE subject = $1;`
+	return ad.DB.WithContext(ctx).Exec(query, twoFactorSubject).Error
+}
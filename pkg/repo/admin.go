@@ -1,10 +1,14 @@
 package repo
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/config"
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"gorm.io/gorm"
 )
@@ -20,7 +24,7 @@ func NewAdminRepository(DB *gorm.DB) interfaces.AdminRepository {
 
 }
 
-func (ad *adminDatabase) FindAdminCredentials() (config.AdminCredentials, error) {
+func (ad *adminDatabase) FindAdminCredentials(ctx context.Context) (config.AdminCredentials, error) {
 	var adminCredentials = config.GetAdminCredentials()
 	if adminCredentials.AdminUsername == "" || adminCredentials.AdminPassword == "" {
 		return adminCredentials, fmt.Errorf("failed to fetch admin credentials")
@@ -29,38 +33,80 @@ func (ad *adminDatabase) FindAdminCredentials() (config.AdminCredentials, error)
 	return adminCredentials, nil
 }
 
-func (ad *adminDatabase) FetchAllUserData() ([]response.UserData, error) {
-	var ListOfAllUsers = make([]response.UserData, 0)
-	query := "SELECT Id,user_name ,email,phone ,is_blocked FROM users ORDER BY Id"
-	err := ad.DB.Raw(query).Scan(&ListOfAllUsers).Error
-	return ListOfAllUsers, err
+// GetUsers lists the users matching the filter, the newest sign up first.
+func (ad *adminDatabase) GetUsers(ctx context.Context, filter request.UserFilter, startIndex, endIndex int) ([]response.UserData, error) {
+	var ListOfUsers = make([]response.UserData, 0)
+
+	query := `SELECT u.id, u.user_name, u.email, u.email_verified, u.phone, u.is_blocked, u.created_at FROM users u
+	WHERE ($1 = '' OR u.user_name ILIKE $2 OR u.email ILIKE $2 OR CAST(u.phone AS TEXT) LIKE $2)
+	AND ($3::boolean IS NULL OR u.is_blocked = $3)
+	AND ($4::boolean IS NULL OR EXISTS (SELECT 1 FROM order_lines o WHERE o.user_id = u.id) = $4)
+	AND ($5::timestamptz IS NULL OR u.created_at >= $5)
+	AND ($6::timestamptz IS NULL OR u.created_at < $6)
+	ORDER BY u.created_at DESC, u.id DESC OFFSET $7 FETCH NEXT $8 ROW ONLY;`
+	err := ad.DB.WithContext(ctx).Raw(query, filter.Search, containsPattern(filter.Search), filter.Blocked, filter.HasOrders, timeOrNull(filter.From), timeOrNull(filter.To), startIndex, endIndex).Scan(&ListOfUsers).Error
+	return ListOfUsers, err
 }
 
-func (ad *adminDatabase) BlockUserByID(userID int) error {
+func (ad *adminDatabase) BlockUserByID(ctx context.Context, userID int) error {
 	var BlockedUser response.UserData
 	status := true
 	query := "UPDATE Users SET Is_blocked =$1  WHERE Id =$2 RETURNING *"
-	err := ad.DB.Raw(query, status, userID).Scan(&BlockedUser).Error
-	fmt.Println(BlockedUser)
+	err := ad.DB.WithContext(ctx).Raw(query, status, userID).Scan(&BlockedUser).Error
 	return err
 }
 
-func (ad *adminDatabase) UnblockUserByID(userID int) error {
+func (ad *adminDatabase) UnblockUserByID(ctx context.Context, userID int) error {
 	var BlockedUser response.UserData
 	status := false
 	query := "UPDATE Users SET Is_blocked =$1 WHERE id =$2 RETURNING *"
-	err := ad.DB.Raw(query, status, userID).Scan(&BlockedUser).Error
-	fmt.Println(BlockedUser)
+	err := ad.DB.WithContext(ctx).Raw(query, status, userID).Scan(&BlockedUser).Error
 	return err
 }
 
-func (ad *adminDatabase) FindUsersByName(name string) ([]response.UserData, error) {
-	var users []response.UserData
-	query := "SELECT * FROM Users WHERE User_name ILIKE `%$1%`"
-	err := ad.DB.Raw(query, name).Scan(&users).Error
+func (ad *adminDatabase) FindUsersByName(ctx context.Context, name string) ([]response.UserData, error) {
+	var users = make([]response.UserData, 0)
+	query := `SELECT * FROM users WHERE user_name ILIKE $1 ORDER BY user_name, id;`
+	err := ad.DB.WithContext(ctx).Raw(query, containsPattern(name)).Scan(&users).Error
 	return users, err
 }
 
+// containsPattern is the LIKE pattern matching text anywhere in a value, the
+// wildcards in text match themselves.
+func containsPattern(text string) string {
+	text = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+	return "%" + text + "%"
+}
+
+// timeOrNull passes a zero time to the query as NULL.
+func timeOrNull(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (ad *adminDatabase) InsertAdminNote(ctx context.Context, userID int, note string, createdAt time.Time) (response.AdminNote, error) {
+	var adminNote response.AdminNote
+	query := `INSERT INTO admin_notes (user_id,note,created_at) VALUES ($1,$2,$3) RETURNING *;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID, note, createdAt).Scan(&adminNote).Error
+	return adminNote, err
+}
+
+func (ad *adminDatabase) GetAdminNotes(ctx context.Context, userID int) ([]response.AdminNote, error) {
+	var notes = make([]response.AdminNote, 0)
+	query := `SELECT * FROM admin_notes WHERE user_id = $1 ORDER BY created_at DESC, id DESC;`
+	err := ad.DB.WithContext(ctx).Raw(query, userID).Scan(&notes).Error
+	return notes, err
+}
+
+func (ad *adminDatabase) DeleteAdminNote(ctx context.Context, noteID int) (response.AdminNote, error) {
+	var adminNote response.AdminNote
+	query := `DELETE FROM admin_notes WHERE id = $1 RETURNING *;`
+	err := ad.DB.WithContext(ctx).Raw(query, noteID).Scan(&adminNote).Error
+	return adminNote, err
+}
+
 func (ad *adminDatabase) SetupDB() {
 	// ad.DropTable()
 	ad.InsertOrderStatus()
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+// auditTables are the tables of the entities the admin routes change.
+var auditTables = map[string]string{
+	"user":            "users",
+	"product":         "products",
+	"category":        "categories",
+	"coupon":          "coupons",
+	"order":           "order_lines",
+	"promotion":       "promotions",
+	"scheduled_price": "scheduled_prices",
+	"loyalty_rule":    "loyalty_rules",
+	"login_lockout":   "login_lockouts",
+	"admin_note":      "admin_notes",
+	"domain_event":    "domain_events",
+}
+
+type auditDatabase struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepository(DB *gorm.DB) interfaces.AuditRepository {
+	return &auditDatabase{
+		DB: DB,
+	}
+}
+
+func (ad *auditDatabase) InsertAuditLog(ctx context.Context, log request.AuditLog) (response.AuditLog, error) {
+	var auditLog response.AuditLog
+	query := `INSERT INTO audit_logs (actor,action,path,entity_type,entity_id,before,after,changes,request_body,status_code,ip,user_agent,created_at)
+	VALUES ($1,$2,$3,$4,$5,NULLIF($6,'')::jsonb,NULLIF($7,'')::jsonb,NULLIF($8,'')::jsonb,NULLIF($9,'')::jsonb,$10,$11,$12,$13) RETURNING *;`
+	err := ad.DB.WithContext(ctx).Raw(query, log.Actor, log.Action, log.Path, log.EntityType, log.EntityID, log.Before, log.After, log.Changes, log.RequestBody, log.StatusCode, log.IP, log.UserAgent, log.CreatedAt).Scan(&auditLog).Error
+	return auditLog, err
+}
+
+// SnapshotEntity returns the row of the entity as json without the password,
+// empty when the entity type is unknown or the row doesn't exist.
+func (ad *auditDatabase) SnapshotEntity(ctx context.Context, entityType, entityID string) (string, error) {
+	table, ok := auditTables[entityType]
+	if !ok {
+		return "", nil
+	}
+
+	var snapshot string
+	query := fmt.Sprintf(`SELECT (row_to_json(t)::jsonb - 'password')::text FROM %s t WHERE t.id::text = $1;`, table)
+	err := ad.DB.WithContext(ctx).Raw(query, entityID).Scan(&snapshot).Error
+	return snapshot, err
+}
+
+// GetAuditLogs lists the logs matching the filter, the latest first. A beforeID
+// above zero only lists the logs older than it, for reading the log in batches.
+func (ad *auditDatabase) GetAuditLogs(ctx context.Context, filter request.AuditLogFilter, beforeID, startIndex, endIndex int) ([]response.AuditLog, error) {
+	var logs = make([]response.AuditLog, 0)
+	query := `SELECT id, actor, action, path, entity_type, entity_id, COALESCE(before::text,'') AS before, COALESCE(after::text,'') AS after,
+	COALESCE(changes::text,'') AS changes, COALESCE(request_body::text,'') AS request_body, status_code, ip, user_agent, created_at
+	FROM audit_logs
+	WHERE ($1 = '' OR actor = $1)
+	AND ($2 = '' OR action ILIKE $3)
+	AND ($4 = '' OR entity_type = $4)
+	AND ($5 = '' OR entity_id = $5)
+	AND ($6::timestamptz IS NULL OR created_at >= $6)
+	AND ($7::timestamptz IS NULL OR created_at < $7)
+	AND ($8 = 0 OR id < $8)
+	ORDER BY id DESC OFFSET $9 FETCH NEXT $10 ROW ONLY;`
+	err := ad.DB.WithContext(ctx).Raw(query, filter.Actor, filter.Action, containsPattern(filter.Action), filter.EntityType, filter.EntityID,
+		timeOrNull(filter.From), timeOrNull(filter.To), beforeID, startIndex, endIndex).Scan(&logs).Error
+	return logs, err
+}
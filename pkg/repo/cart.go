@@ -1,6 +1,9 @@
 package repo
 
 import (
+	"context"
+	"time"
+
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"gorm.io/gorm"
@@ -16,70 +19,191 @@ func NewCartRepository(DB *gorm.DB) interfaces.CartRepository {
 	}
 }
 
-func (cd *cartDatabase) AddToCart(userID int, ProductID int) (response.Cart, error) {
+func (cd *cartDatabase) AddToCart(ctx context.Context, userID int, ProductID int) (response.Cart, error) {
 	var CartItem response.Cart
 	qty := 1
-	query := `INSERT INTO carts (user_id,product_id,qty)VALUES($1,$2,$3) RETURNING *;`
-	err := cd.DB.Raw(query, userID, ProductID, qty).Scan(&CartItem).Error
+	query := `INSERT INTO carts (user_id,product_id,qty,added_price,created_at,updated_at) SELECT $1,$2,$3,price,$4,$4 FROM products WHERE id = $2 RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, userID, ProductID, qty, time.Now()).Scan(&CartItem).Error
 
 	return CartItem, err
 
 }
 
-func (cd *cartDatabase) ViewCart(userID int) ([]response.Cart, error) {
+func (cd *cartDatabase) ViewCart(ctx context.Context, userID int) ([]response.Cart, error) {
 	var CartItem = make([]response.Cart, 0)
 
-	query := `SELECT c.id , c.product_id, c.qty,p.product_name , p.brand, p.price, p.images FROM carts c INNER JOIN products p ON c.product_id = p.id WHERE c.user_id = $1 `
-	err := cd.DB.Raw(query, userID).Scan(&CartItem).Error
+	query := `SELECT c.id , c.product_id, p.category_id, c.qty,p.product_name , p.brand, p.price, p.mrp, p.images FROM carts c INNER JOIN products p ON c.product_id = p.id WHERE c.user_id = $1 `
+	err := cd.DB.WithContext(ctx).Raw(query, userID).Scan(&CartItem).Error
 
 	return CartItem, err
 
 }
 
-func (cd *cartDatabase) RemoveFromCart(userID int, productID int) (response.Cart, error) {
+func (cd *cartDatabase) RemoveFromCart(ctx context.Context, userID int, productID int) (response.Cart, error) {
 	var CartItem response.Cart
 
 	query := `DELETE FROM carts WHERE user_id = $1 AND product_id =$2 RETURNING * ; `
-	err := cd.DB.Raw(query, userID, productID).Scan(&CartItem).Error
+	err := cd.DB.WithContext(ctx).Raw(query, userID, productID).Scan(&CartItem).Error
 
 	return CartItem, err
 
 }
 
-func (cd *cartDatabase) IncrementQuantity(qty int, userID int, productID int) (response.Cart, error) {
+func (cd *cartDatabase) IncrementQuantity(ctx context.Context, qty int, userID int, productID int) (response.Cart, error) {
 	var CartItem response.Cart
 
-	query := `UPDATE carts SET qty = $1 WHERE user_id = $2 AND product_id =$3 RETURNING * ; `
-	err := cd.DB.Raw(query, qty, userID, productID).Scan(&CartItem).Error
+	query := `UPDATE carts SET qty = $1, updated_at = $4 WHERE user_id = $2 AND product_id =$3 RETURNING * ; `
+	err := cd.DB.WithContext(ctx).Raw(query, qty, userID, productID, time.Now()).Scan(&CartItem).Error
 
 	return CartItem, err
 
 }
 
-func (cd *cartDatabase) DecrementQuantity(qty int, userID int, productID int) (response.Cart, error) {
+func (cd *cartDatabase) DecrementQuantity(ctx context.Context, qty int, userID int, productID int) (response.Cart, error) {
+	var CartItem response.Cart
+
+	query := `UPDATE carts SET qty = $1, updated_at = $4 WHERE user_id = $2 AND product_id =$3 RETURNING * ; `
+	err := cd.DB.WithContext(ctx).Raw(query, qty, userID, productID, time.Now()).Scan(&CartItem).Error
+
+	return CartItem, err
+
+}
+func (cd *cartDatabase) UpdateQuantity(ctx context.Context, qty int, userID int, productID int) (response.Cart, error) {
 	var CartItem response.Cart
 
-	query := `UPDATE carts SET qty = $1 WHERE user_id = $2 AND product_id =$3 RETURNING * ; `
-	err := cd.DB.Raw(query, qty, userID, productID).Scan(&CartItem).Error
+	query := `UPDATE carts SET qty = $1, updated_at = $4 WHERE user_id = $2 AND product_id =$3 RETURNING * ; `
+	err := cd.DB.WithContext(ctx).Raw(query, qty, userID, productID, time.Now()).Scan(&CartItem).Error
 
 	return CartItem, err
 
 }
-func (cd *cartDatabase) GetCartItem(userID int, productID int) (response.Cart, error) {
+func (cd *cartDatabase) GetCartItem(ctx context.Context, userID int, productID int) (response.Cart, error) {
 	var CartItem response.Cart
 
 	query := `SELECT * FROM carts WHERE user_id = $1 AND product_id = $2 ; `
-	err := cd.DB.Raw(query, userID, productID).Scan(&CartItem).Error
+	err := cd.DB.WithContext(ctx).Raw(query, userID, productID).Scan(&CartItem).Error
 
 	return CartItem, err
 
 }
-func (cd *cartDatabase) DeleteCart(userID int) (response.Cart, error) {
+func (cd *cartDatabase) DeleteCart(ctx context.Context, userID int) (response.Cart, error) {
 	var DeletedCart response.Cart
 
 	query := `DELETE FROM carts WHERE user_id = $1 RETURNING *; `
-	err := cd.DB.Raw(query, userID).Scan(&DeletedCart).Error
+	err := cd.DB.WithContext(ctx).Raw(query, userID).Scan(&DeletedCart).Error
 
 	return DeletedCart, err
 
 }
+
+// GetCartAvailability returns the cart lines with the current state of their product
+// and category, used to validate the cart before checkout.
+func (cd *cartDatabase) GetCartAvailability(ctx context.Context, userID int) ([]response.CartAvailability, error) {
+	var CartItems = make([]response.CartAvailability, 0)
+
+	query := `SELECT c.product_id, p.product_name, p.category_id, c.qty, c.added_price, p.price, p.stock, p.max_per_order, p.is_blocked, ct.is_blocked AS category_blocked
+	FROM carts c INNER JOIN products p ON c.product_id = p.id INNER JOIN categories ct ON p.category_id = ct.id
+	WHERE c.user_id = $1 ORDER BY c.id ;`
+	err := cd.DB.WithContext(ctx).Raw(query, userID).Scan(&CartItems).Error
+
+	return CartItems, err
+}
+
+func (cd *cartDatabase) UpdateAddedPrice(ctx context.Context, userID, productID, price int) error {
+	query := `UPDATE carts SET added_price = $1 WHERE user_id = $2 AND product_id = $3 ;`
+	return cd.DB.WithContext(ctx).Exec(query, price, userID, productID).Error
+}
+
+func (cd *cartDatabase) AddToGuestCart(ctx context.Context, cartID string, productID int) (response.Cart, error) {
+	var CartItem response.Cart
+	qty := 1
+	query := `INSERT INTO guest_carts (cart_id,product_id,qty,created_at)VALUES($1,$2,$3,$4) RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, cartID, productID, qty, time.Now()).Scan(&CartItem).Error
+
+	return CartItem, err
+}
+
+func (cd *cartDatabase) ViewGuestCart(ctx context.Context, cartID string) ([]response.Cart, error) {
+	var CartItem = make([]response.Cart, 0)
+
+	query := `SELECT c.id , c.product_id, p.category_id, c.qty,p.product_name , p.brand, p.price, p.mrp, p.images FROM guest_carts c INNER JOIN products p ON c.product_id = p.id WHERE c.cart_id = $1 `
+	err := cd.DB.WithContext(ctx).Raw(query, cartID).Scan(&CartItem).Error
+
+	return CartItem, err
+}
+
+func (cd *cartDatabase) GetGuestCartItem(ctx context.Context, cartID string, productID int) (response.Cart, error) {
+	var CartItem response.Cart
+
+	query := `SELECT * FROM guest_carts WHERE cart_id = $1 AND product_id = $2 ; `
+	err := cd.DB.WithContext(ctx).Raw(query, cartID, productID).Scan(&CartItem).Error
+
+	return CartItem, err
+}
+
+func (cd *cartDatabase) UpdateGuestCartQuantity(ctx context.Context, qty int, cartID string, productID int) (response.Cart, error) {
+	var CartItem response.Cart
+
+	query := `UPDATE guest_carts SET qty = $1 WHERE cart_id = $2 AND product_id =$3 RETURNING * ; `
+	err := cd.DB.WithContext(ctx).Raw(query, qty, cartID, productID).Scan(&CartItem).Error
+
+	return CartItem, err
+}
+
+func (cd *cartDatabase) RemoveFromGuestCart(ctx context.Context, cartID string, productID int) (response.Cart, error) {
+	var CartItem response.Cart
+
+	query := `DELETE FROM guest_carts WHERE cart_id = $1 AND product_id =$2 RETURNING * ; `
+	err := cd.DB.WithContext(ctx).Raw(query, cartID, productID).Scan(&CartItem).Error
+
+	return CartItem, err
+}
+
+func (cd *cartDatabase) DeleteGuestCart(ctx context.Context, cartID string) error {
+	query := `DELETE FROM guest_carts WHERE cart_id = $1 ;`
+	err := cd.DB.WithContext(ctx).Exec(query, cartID).Error
+	if err != nil {
+		return err
+	}
+
+	query = `DELETE FROM guest_coupons WHERE cart_id = $1 ;`
+	return cd.DB.WithContext(ctx).Exec(query, cartID).Error
+}
+
+func (cd *cartDatabase) AddGuestCoupon(ctx context.Context, cartID string, couponID int) (response.GuestCoupon, error) {
+	var GuestCoupon response.GuestCoupon
+
+	query := `INSERT INTO guest_coupons (cart_id,coupon_id)VALUES($1,$2) RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, cartID, couponID).Scan(&GuestCoupon).Error
+
+	return GuestCoupon, err
+}
+
+func (cd *cartDatabase) GetGuestCoupons(ctx context.Context, cartID string) ([]response.GuestCoupon, error) {
+	var GuestCoupons = make([]response.GuestCoupon, 0)
+
+	query := `SELECT * FROM guest_coupons WHERE cart_id = $1 ORDER BY id ;`
+	err := cd.DB.WithContext(ctx).Raw(query, cartID).Scan(&GuestCoupons).Error
+
+	return GuestCoupons, err
+}
+
+func (cd *cartDatabase) RemoveGuestCoupon(ctx context.Context, cartID string, couponID int) (response.GuestCoupon, error) {
+	var GuestCoupon response.GuestCoupon
+
+	query := `DELETE FROM guest_coupons WHERE cart_id = $1 AND coupon_id = $2 RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, cartID, couponID).Scan(&GuestCoupon).Error
+
+	return GuestCoupon, err
+}
+
+func (cd *cartDatabase) RemoveGuestCoupons(ctx context.Context, cartID string) error {
+	query := `DELETE FROM guest_coupons WHERE cart_id = $1 ;`
+	return cd.DB.WithContext(ctx).Exec(query, cartID).Error
+}
+
+// RemoveExpiredGuestCoupons removes the coupons applied on guest carts that expired or were blocked.
+func (cd *cartDatabase) RemoveExpiredGuestCoupons(ctx context.Context, now time.Time) error {
+	query := `DELETE FROM guest_coupons g USING coupons c WHERE c.id = g.coupon_id AND (c.valid_till < $1 OR c.is_blocked = true);`
+	return cd.DB.WithContext(ctx).Exec(query, now).Error
+}
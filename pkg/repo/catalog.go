@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/domain"
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type catalogDatabase struct {
+	DB *gorm.DB
+}
+
+func NewCatalogRepository(DB *gorm.DB) interfaces.CatalogRepository {
+	return &catalogDatabase{
+		DB: DB,
+	}
+}
+
+// productImportColumns are the columns of an import without its rows.
+const productImportColumns = `id, format, file_name, dry_run, status, total_rows, processed_rows,
+	created, updated, failed, errors, error, created_at, started_at, finished_at`
+
+func (cd *catalogDatabase) InsertProductImport(ctx context.Context, productImport domain.ProductImport) (response.ProductImport, error) {
+	var inserted response.ProductImport
+	query := `INSERT INTO product_imports (format, file_name, dry_run, status, rows, total_rows, processed_rows, created, updated, failed, errors, created_at)
+	VALUES ($1, $2, $3, 'queued', $4::jsonb, $5, $6, 0, 0, $7, NULLIF($8,'')::jsonb, $9)
+	RETURNING ` + productImportColumns + ` ;`
+	err := cd.DB.WithContext(ctx).Raw(query, productImport.Format, productImport.FileName, productImport.DryRun, productImport.Rows,
+		productImport.TotalRows, productImport.ProcessedRows, productImport.Failed, productImport.Errors, productImport.CreatedAt).Scan(&inserted).Error
+	return inserted, err
+}
+
+func (cd *catalogDatabase) FindProductImport(ctx context.Context, importID int) (response.ProductImport, error) {
+	var productImport response.ProductImport
+	query := `SELECT ` + productImportColumns + ` FROM product_imports WHERE id = $1 ;`
+	err := cd.DB.WithContext(ctx).Raw(query, importID).Scan(&productImport).Error
+	return productImport, err
+}
+
+func (cd *catalogDatabase) GetProductImports(ctx context.Context, startIndex, endIndex int) ([]response.ProductImport, error) {
+	var imports = make([]response.ProductImport, 0)
+	query := `SELECT ` + productImportColumns + ` FROM product_imports ORDER BY id DESC OFFSET $1 FETCH NEXT $2 ROW ONLY ;`
+	err := cd.DB.WithContext(ctx).Raw(query, startIndex, endIndex).Scan(&imports).Error
+	return imports, err
+}
+
+func (cd *catalogDatabase) GetProductImportRows(ctx context.Context, importID int) (string, error) {
+	var rows string
+	query := `SELECT COALESCE(rows::text,'[]') FROM product_imports WHERE id = $1 ;`
+	err := cd.DB.WithContext(ctx).Raw(query, importID).Scan(&rows).Error
+	return rows, err
+}
+
+// StartProductImport only starts a queued import, the returned import has no id
+// when it is not queued.
+func (cd *catalogDatabase) StartProductImport(ctx context.Context, importID int, now time.Time) (response.ProductImport, error) {
+	var started response.ProductImport
+	query := `UPDATE product_imports SET status = 'running', started_at = $2 WHERE id = $1 AND status = 'queued'
+	RETURNING ` + productImportColumns + ` ;`
+	err := cd.DB.WithContext(ctx).Raw(query, importID, now).Scan(&started).Error
+	return started, err
+}
+
+func (cd *catalogDatabase) UpdateProductImportProgress(ctx context.Context, productImport response.ProductImport) error {
+	query := `UPDATE product_imports SET processed_rows = $1, created = $2, updated = $3, failed = $4 WHERE id = $5 ;`
+	return cd.DB.WithContext(ctx).Exec(query, productImport.ProcessedRows, productImport.Created, productImport.Updated,
+		productImport.Failed, productImport.ID).Error
+}
+
+// FinishProductImport saves the counts and the report of an import and clears
+// its rows.
+func (cd *catalogDatabase) FinishProductImport(ctx context.Context, productImport response.ProductImport, now time.Time) error {
+	query := `UPDATE product_imports SET status = $1, processed_rows = $2, created = $3, updated = $4, failed = $5,
+	errors = NULLIF($6,'')::jsonb, error = $7, rows = NULL, finished_at = $8 WHERE id = $9 ;`
+	return cd.DB.WithContext(ctx).Exec(query, productImport.Status, productImport.ProcessedRows, productImport.Created, productImport.Updated,
+		productImport.Failed, productImport.RowErrors, productImport.Error, now, productImport.ID).Error
+}
+
+func (cd *catalogDatabase) FindCategoryByName(ctx context.Context, name string) (response.Category, error) {
+	var category response.Category
+	query := `SELECT * FROM categories WHERE LOWER(category_name) = LOWER($1) ;`
+	err := cd.DB.WithContext(ctx).Raw(query, name).Scan(&category).Error
+	return category, err
+}
+
+func (cd *catalogDatabase) FindProductBySKU(ctx context.Context, sku string) (response.Product, error) {
+	var product response.Product
+	query := `SELECT * FROM products WHERE sku = $1 ORDER BY id LIMIT 1 ;`
+	err := cd.DB.WithContext(ctx).Raw(query, sku).Scan(&product).Error
+	return product, err
+}
+
+func (cd *catalogDatabase) CreateProduct(ctx context.Context, product request.Product) (response.Product, error) {
+	var created response.Product
+	query := `INSERT INTO products (category_id, product_name, price, mrp, product_description, brand, sku, is_blocked, stock, max_per_order, images)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING * ;`
+	err := cd.DB.WithContext(ctx).Raw(query, product.CategoryID, product.ProductName, product.Price, product.MRP, product.ProductDescription,
+		product.Brand, product.SKU, product.IsBlocked, product.Stock, product.MaxPerOrder, productImages(product)).Scan(&created).Error
+	return created, err
+}
+
+// UpdateProduct replaces the product with the imported one, the images stay
+// when the import has none.
+func (cd *catalogDatabase) UpdateProduct(ctx context.Context, productID int, product request.Product) error {
+	query := `UPDATE products SET category_id = $1, product_name = $2, price = $3, mrp = $4, product_description = $5,
+	brand = $6, is_blocked = $7, stock = $8, max_per_order = $9, images = COALESCE($10::jsonb, images) WHERE id = $11 ;`
+	return cd.DB.WithContext(ctx).Exec(query, product.CategoryID, product.ProductName, product.Price, product.MRP, product.ProductDescription,
+		product.Brand, product.IsBlocked, product.Stock, product.MaxPerOrder, productImages(product), productID).Error
+}
+
+// productImages is nil for a product without images so that the column is null.
+func productImages(product request.Product) interface{} {
+	if product.Images == nil {
+		return nil
+	}
+	return product.Images
+}
+
+// GetCatalogProducts reads the products with the ID after afterID, in the order
+// of the ID, for the export to stream them in batches.
+func (cd *catalogDatabase) GetCatalogProducts(ctx context.Context, afterID, limit int) ([]response.CatalogProduct, error) {
+	var products = make([]response.CatalogProduct, 0)
+	query := `SELECT p.id, p.sku, p.product_name, p.product_description, c.category_name AS category, p.brand,
+	p.price, p.mrp, p.stock, p.max_per_order, p.images, p.is_blocked
+	FROM products p
+	INNER JOIN categories c ON p.category_id = c.id
+	WHERE p.id > $1
+	ORDER BY p.id
+	LIMIT $2 ;`
+	err := cd.DB.WithContext(ctx).Raw(query, afterID, limit).Scan(&products).Error
+	return products, err
+}
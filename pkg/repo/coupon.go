@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"context"
 	"time"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
@@ -19,132 +20,205 @@ func NewCouponRepository(DB *gorm.DB) interfaces.CouponRepository {
 	}
 }
 
-func (cd *couponDatabase) CreateCoupon(couponData request.Coupon) (response.Coupon, error) {
+func (cd *couponDatabase) CreateCoupon(ctx context.Context, couponData request.Coupon) (response.Coupon, error) {
 	var InsertedCoupon response.Coupon
 
-	query := `INSERT INTO coupons (coupon_name,code,min_order_value,discount_percent,discount_max_amount,valid_till,valid_from,valid_days)VALUES($1,$2,$3,$4,$5,$6,$7,$8) RETURNING *;`
-	err := cd.DB.Raw(query, couponData.CouponName, couponData.Code, couponData.MinOrderValue, couponData.DiscountPercent, couponData.DiscountMaxAmount, couponData.ValidTill, couponData.ValidFrom, couponData.ValidityDays).Scan(&InsertedCoupon).Error
+	query := `INSERT INTO coupons (coupon_name,code,rule_type,min_order_value,discount_percent,discount_max_amount,discount_amount,buy_quantity,get_quantity,first_order_only,user_segment,usage_limit,per_user_limit,is_stackable,valid_till,valid_from,valid_days)VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17) RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, couponData.CouponName, couponData.Code, couponData.RuleType, couponData.MinOrderValue, couponData.DiscountPercent, couponData.DiscountMaxAmount, couponData.DiscountAmount, couponData.BuyQuantity, couponData.GetQuantity, couponData.FirstOrderOnly, couponData.UserSegment, couponData.UsageLimit, couponData.PerUserLimit, couponData.IsStackable, couponData.ValidTill, couponData.ValidFrom, couponData.ValidityDays).Scan(&InsertedCoupon).Error
 
 	return InsertedCoupon, err
 }
 
-func (cd *couponDatabase) UpdateCouponDetails(couponData request.Coupon) (response.Coupon, error) {
+func (cd *couponDatabase) UpdateCouponDetails(ctx context.Context, couponData request.Coupon) (response.Coupon, error) {
 	var UpdatedCoupon response.Coupon
-	query := `UPDATE coupons SET coupon_name = $1 ,code = $2 ,min_order_value = $3 ,discount_percent = $4 ,discount_max_amount = $5,valid_till= $6 ,valid_from= $7,valid_days = $8 WHERE id = $9  RETURNING *;`
-	err := cd.DB.Raw(query, couponData.CouponName, couponData.Code, couponData.MinOrderValue, couponData.DiscountPercent, couponData.DiscountMaxAmount, couponData.ValidTill, couponData.ValidFrom, couponData.ValidityDays, couponData.ID).Scan(&UpdatedCoupon).Error
+	query := `UPDATE coupons SET coupon_name = $1 ,code = $2 ,rule_type = $3 ,min_order_value = $4 ,discount_percent = $5 ,discount_max_amount = $6 ,discount_amount = $7 ,buy_quantity = $8 ,get_quantity = $9 ,first_order_only = $10 ,user_segment = $11 ,usage_limit = $12 ,per_user_limit = $13 ,is_stackable = $14 ,valid_till= $15 ,valid_from= $16,valid_days = $17 WHERE id = $18  RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, couponData.CouponName, couponData.Code, couponData.RuleType, couponData.MinOrderValue, couponData.DiscountPercent, couponData.DiscountMaxAmount, couponData.DiscountAmount, couponData.BuyQuantity, couponData.GetQuantity, couponData.FirstOrderOnly, couponData.UserSegment, couponData.UsageLimit, couponData.PerUserLimit, couponData.IsStackable, couponData.ValidTill, couponData.ValidFrom, couponData.ValidityDays, couponData.ID).Scan(&UpdatedCoupon).Error
 
 	return UpdatedCoupon, err
 }
-func (cd *couponDatabase) BlockCouponByID(couponID int) (response.Coupon, error) {
+func (cd *couponDatabase) BlockCouponByID(ctx context.Context, couponID int) (response.Coupon, error) {
 	var BlockedCoupon response.Coupon
 	IsBlocked := true
 	query := `UPDATE coupons SET Is_blocked = $1 WHERE id = $2 RETURNING *;`
-	err := cd.DB.Raw(query, IsBlocked, couponID).Scan(&BlockedCoupon).Error
+	err := cd.DB.WithContext(ctx).Raw(query, IsBlocked, couponID).Scan(&BlockedCoupon).Error
 
 	return BlockedCoupon, err
 }
 
-func (cd *couponDatabase) UnblockCouponByID(couponID int) (response.Coupon, error) {
+func (cd *couponDatabase) UnblockCouponByID(ctx context.Context, couponID int) (response.Coupon, error) {
 	var BlockedCoupon response.Coupon
 	IsBlocked := false
 	query := `UPDATE coupons SET Is_blocked = $1 WHERE id = $2 RETURNING *;`
-	err := cd.DB.Raw(query, IsBlocked, couponID).Scan(&BlockedCoupon).Error
+	err := cd.DB.WithContext(ctx).Raw(query, IsBlocked, couponID).Scan(&BlockedCoupon).Error
 	return BlockedCoupon, err
 }
 
-func (cd *couponDatabase) GetAllCoupons() ([]response.Coupon, error) {
+func (cd *couponDatabase) GetAllCoupons(ctx context.Context) ([]response.Coupon, error) {
 	var Coupons = make([]response.Coupon, 0)
 
 	query := `SELECT * FROM coupons ORDER BY id DESC`
-	err := cd.DB.Raw(query).Scan(&Coupons).Error
+	err := cd.DB.WithContext(ctx).Raw(query).Scan(&Coupons).Error
 
 	return Coupons, err
 }
 
-func (cd *couponDatabase) FindCouponByCode(couponCode string) (response.Coupon, error) {
+func (cd *couponDatabase) FindCouponByCode(ctx context.Context, couponCode string) (response.Coupon, error) {
 	var Coupon response.Coupon
 	query := `SELECT * FROM coupons WHERE code = $1 ;`
-	err := cd.DB.Raw(query, couponCode).Scan(&Coupon).Error
+	err := cd.DB.WithContext(ctx).Raw(query, couponCode).Scan(&Coupon).Error
 
 	return Coupon, err
 
 }
 
-func (cd *couponDatabase) FindCouponByID(couponID int) (response.Coupon, error) {
+func (cd *couponDatabase) FindCouponByID(ctx context.Context, couponID int) (response.Coupon, error) {
 	var Coupon response.Coupon
 	query := `SELECT * FROM coupons WHERE id = $1 ;`
-	err := cd.DB.Raw(query, couponID).Scan(&Coupon).Error
+	err := cd.DB.WithContext(ctx).Raw(query, couponID).Scan(&Coupon).Error
 
 	return Coupon, err
 
 }
 
-func (cd *couponDatabase) AddCouponTracking(userID, couponID int) (response.CouponTracking, error) {
+func (cd *couponDatabase) AddCouponTracking(ctx context.Context, userID, couponID int) (response.CouponTracking, error) {
 	var TrackedCoupon response.CouponTracking
 
 	query := `INSERT INTO coupon_trackings (coupon_id,user_id)VALUES($1,$2) RETURNING * ;`
-	err := cd.DB.Raw(query, couponID, userID).Scan(&TrackedCoupon).Error
+	err := cd.DB.WithContext(ctx).Raw(query, couponID, userID).Scan(&TrackedCoupon).Error
 	return TrackedCoupon, err
 }
 
-func (cd *couponDatabase) UpdateCouponUsage(userID int) (response.CouponTracking, error) {
+func (cd *couponDatabase) UpdateCouponUsage(ctx context.Context, userID int) (response.CouponTracking, error) {
 	var UpdatedCouponTracking response.CouponTracking
 	IsUsed := true
 	query := `UPDATE coupon_trackings SET is_used = $1 WHERE user_id = $2 AND is_used = false RETURNING * ;`
-	err := cd.DB.Raw(query, IsUsed, userID).Scan(&UpdatedCouponTracking).Error
+	err := cd.DB.WithContext(ctx).Raw(query, IsUsed, userID).Scan(&UpdatedCouponTracking).Error
 	return UpdatedCouponTracking, err
 }
 
-func (cd *couponDatabase) FindTrackingCoupon(userID, couponID int) (response.CouponTracking, error) {
+func (cd *couponDatabase) FindTrackingCoupon(ctx context.Context, userID, couponID int) (response.CouponTracking, error) {
 	var coupon response.CouponTracking
 	query := `SELECT * FROM coupon_trackings WHERE coupon_id = $1 AND user_id = $2;`
-	err := cd.DB.Raw(query, couponID, userID).Scan(&coupon).Error
+	err := cd.DB.WithContext(ctx).Raw(query, couponID, userID).Scan(&coupon).Error
 	return coupon, err
 }
 
-func (cd *couponDatabase) CheckAppliedCoupon(userID int) (response.CouponTracking, error) {
+func (cd *couponDatabase) CheckAppliedCoupon(ctx context.Context, userID int) (response.CouponTracking, error) {
 	var AppliedCoupon response.CouponTracking
 	IsUsed := false
 	query := `SELECT * FROM coupon_trackings WHERE user_id = $1 AND is_used = $2;`
-	err := cd.DB.Raw(query, userID, IsUsed).Scan(&AppliedCoupon).Error
+	err := cd.DB.WithContext(ctx).Raw(query, userID, IsUsed).Scan(&AppliedCoupon).Error
 	return AppliedCoupon, err
 }
 
 // func (cd *couponDatabase) FindAppliedCouponByUserID(userID int) (response.CouponTracking, error) {
 // 	var Coupon response.CouponTracking
 // 	query := `SELECT * FROM coupon_trackings WHERE user_id = $1 AND is_used !=true ;`
-// 	err := cd.DB.Raw(query, userID).Scan(&Coupon).Error
+// 	err := cd.DB.WithContext(ctx).Raw(query, userID).Scan(&Coupon).Error
 
 // 	return Coupon, err
 
 // }
 
-func (cd *couponDatabase) ChangeUserCoupon(couponID, userID int) (response.CouponTracking, error) {
+func (cd *couponDatabase) ChangeUserCoupon(ctx context.Context, couponID, userID int) (response.CouponTracking, error) {
 	var ChangedCoupon response.CouponTracking
 	query := `UPDATE coupon_trackings SET coupon_id = $1 WHERE user_id = $2 RETURNING *;`
-	err := cd.DB.Raw(query, couponID, userID).Scan(&ChangedCoupon).Error
+	err := cd.DB.WithContext(ctx).Raw(query, couponID, userID).Scan(&ChangedCoupon).Error
 	return ChangedCoupon, err
 }
 
-func (cd *couponDatabase) GetAvailableCouponsForUser(userID int, currentTime time.Time) ([]response.Coupon, error) {
+func (cd *couponDatabase) GetAvailableCouponsForUser(ctx context.Context, userID int, currentTime time.Time) ([]response.Coupon, error) {
 	var Coupons = make([]response.Coupon, 0)
-	query := `SELECT *
+	query := `SELECT c.*
 	FROM coupons c
-	LEFT JOIN coupon_trackings ct ON c.id = ct.coupon_id AND ct.user_id = $1
 	WHERE c.is_blocked = false
 	  AND c.valid_till >$2
-	  AND (ct.is_used = false OR ct.is_used IS NULL);`
-	err := cd.DB.Raw(query, userID, currentTime).Scan(&Coupons).Error
+	  AND (c.usage_limit = 0 OR (SELECT COUNT(*) FROM coupon_trackings t WHERE t.coupon_id = c.id AND t.is_used = true) < c.usage_limit)
+	  AND (c.per_user_limit = 0 OR (SELECT COUNT(*) FROM coupon_trackings t WHERE t.coupon_id = c.id AND t.user_id = $1 AND t.is_used = true) < c.per_user_limit)
+	ORDER BY c.id DESC;`
+	err := cd.DB.WithContext(ctx).Raw(query, userID, currentTime).Scan(&Coupons).Error
 
 	return Coupons, err
 
 }
 
-func (cd *couponDatabase) RemoveCouponFromTracking(couponID, userID int) (response.CouponTracking, error) {
+func (cd *couponDatabase) RemoveCouponFromTracking(ctx context.Context, couponID, userID int) (response.CouponTracking, error) {
 	var RemovedCoupon response.CouponTracking
-	query := `DELETE FROM coupon_trackings WHERE coupon_id = $1 AND user_id = $2 RETURNING *;`
-	err := cd.DB.Raw(query, couponID, userID).Scan(&RemovedCoupon).Error
+	query := `DELETE FROM coupon_trackings WHERE coupon_id = $1 AND user_id = $2 AND is_used = false RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, couponID, userID).Scan(&RemovedCoupon).Error
 
 	return RemovedCoupon, err
 
 }
+
+func (cd *couponDatabase) GetAppliedCoupons(ctx context.Context, userID int) ([]response.CouponTracking, error) {
+	var AppliedCoupons = make([]response.CouponTracking, 0)
+	query := `SELECT * FROM coupon_trackings WHERE user_id = $1 AND is_used = false ORDER BY id;`
+	err := cd.DB.WithContext(ctx).Raw(query, userID).Scan(&AppliedCoupons).Error
+	return AppliedCoupons, err
+}
+
+func (cd *couponDatabase) RemoveAppliedCoupons(ctx context.Context, userID int) error {
+	query := `DELETE FROM coupon_trackings WHERE user_id = $1 AND is_used = false;`
+	return cd.DB.WithContext(ctx).Exec(query, userID).Error
+}
+
+// RemoveExpiredCouponTrackings removes the applied coupons that expired before they were used.
+func (cd *couponDatabase) RemoveExpiredCouponTrackings(ctx context.Context, now time.Time) error {
+	query := `DELETE FROM coupon_trackings t USING coupons c WHERE c.id = t.coupon_id AND t.is_used = false AND c.valid_till < $1;`
+	return cd.DB.WithContext(ctx).Exec(query, now).Error
+}
+
+func (cd *couponDatabase) CountCouponUsage(ctx context.Context, couponID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM coupon_trackings WHERE coupon_id = $1 AND is_used = true;`
+	err := cd.DB.WithContext(ctx).Raw(query, couponID).Scan(&count).Error
+	return count, err
+}
+
+func (cd *couponDatabase) CountUserCouponUsage(ctx context.Context, userID, couponID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM coupon_trackings WHERE coupon_id = $1 AND user_id = $2 AND is_used = true;`
+	err := cd.DB.WithContext(ctx).Raw(query, couponID, userID).Scan(&count).Error
+	return count, err
+}
+
+func (cd *couponDatabase) InsertCouponEligibility(ctx context.Context, couponID int, scope, value string) (response.CouponEligibility, error) {
+	var Eligibility response.CouponEligibility
+	query := `INSERT INTO coupon_eligibilities (coupon_id,scope,value)VALUES($1,$2,$3) RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, couponID, scope, value).Scan(&Eligibility).Error
+	return Eligibility, err
+}
+
+func (cd *couponDatabase) DeleteCouponEligibility(ctx context.Context, couponID int) error {
+	query := `DELETE FROM coupon_eligibilities WHERE coupon_id = $1;`
+	return cd.DB.WithContext(ctx).Exec(query, couponID).Error
+}
+
+func (cd *couponDatabase) GetCouponEligibility(ctx context.Context, couponID int) ([]response.CouponEligibility, error) {
+	var Eligibility = make([]response.CouponEligibility, 0)
+	query := `SELECT * FROM coupon_eligibilities WHERE coupon_id = $1 ORDER BY id;`
+	err := cd.DB.WithContext(ctx).Raw(query, couponID).Scan(&Eligibility).Error
+	return Eligibility, err
+}
+
+func (cd *couponDatabase) InsertOrderLineCoupon(ctx context.Context, orderLineID, couponID int, discount float32) (response.OrderLineCoupon, error) {
+	var LineCoupon response.OrderLineCoupon
+	query := `INSERT INTO order_line_coupons (order_line_id,coupon_id,discount)VALUES($1,$2,$3) RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, orderLineID, couponID, discount).Scan(&LineCoupon).Error
+	return LineCoupon, err
+}
+
+func (cd *couponDatabase) GetOrderLineCoupons(ctx context.Context, orderLineID int) ([]response.OrderLineCoupon, error) {
+	var LineCoupons = make([]response.OrderLineCoupon, 0)
+	query := `SELECT * FROM order_line_coupons WHERE order_line_id = $1 ORDER BY id;`
+	err := cd.DB.WithContext(ctx).Raw(query, orderLineID).Scan(&LineCoupons).Error
+	return LineCoupons, err
+}
+
+func (cd *couponDatabase) UpdateOrderLineCouponDiscount(ctx context.Context, id int, discount float32) (response.OrderLineCoupon, error) {
+	var LineCoupon response.OrderLineCoupon
+	query := `UPDATE order_line_coupons SET discount = $1 WHERE id = $2 RETURNING *;`
+	err := cd.DB.WithContext(ctx).Raw(query, discount, id).Scan(&LineCoupon).Error
+	return LineCoupon, err
+}
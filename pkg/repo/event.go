@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type eventDatabase struct {
+	DB *gorm.DB
+}
+
+func NewEventRepository(DB *gorm.DB) interfaces.EventRepository {
+	return &eventDatabase{
+		DB: DB,
+	}
+}
+
+func (ed *eventDatabase) InsertEvent(ctx context.Context, event request.DomainEvent, now time.Time) (response.DomainEvent, error) {
+	var insertedEvent response.DomainEvent
+	query := `INSERT INTO domain_events (type,aggregate_id,payload,next_attempt_at,created_at)VALUES($1,$2,$3,$4,$5) RETURNING *;`
+	err := ed.DB.WithContext(ctx).Raw(query, event.Type, event.AggregateID, event.Payload, event.NextAttemptAt, now).Scan(&insertedEvent).Error
+	return insertedEvent, err
+}
+
+// ClaimDueEvents takes the pending events due by now in the order they were
+// published and holds them until leaseUntil.
+func (ed *eventDatabase) ClaimDueEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]response.DomainEvent, error) {
+	var events = make([]response.DomainEvent, 0)
+	query := `UPDATE domain_events SET next_attempt_at = $1 WHERE id IN (
+	SELECT id FROM domain_events WHERE status = 'pending' AND next_attempt_at <= $2 ORDER BY id LIMIT $3 FOR UPDATE SKIP LOCKED)
+	RETURNING *;`
+	err := ed.DB.WithContext(ctx).Raw(query, leaseUntil, now, limit).Scan(&events).Error
+	return events, err
+}
+
+func (ed *eventDatabase) MarkEventProcessed(ctx context.Context, eventID int, attempts int, now time.Time) error {
+	query := `UPDATE domain_events SET status = 'processed' ,attempts = $1 ,last_error = '' ,processed_at = $2 WHERE id = $3;`
+	return ed.DB.WithContext(ctx).Exec(query, attempts, now, eventID).Error
+}
+
+func (ed *eventDatabase) MarkEventFailed(ctx context.Context, eventID, attempts int, lastError, status string, nextAttemptAt time.Time) error {
+	query := `UPDATE domain_events SET status = $1 ,attempts = $2 ,last_error = $3 ,next_attempt_at = $4 WHERE id = $5;`
+	return ed.DB.WithContext(ctx).Exec(query, status, attempts, lastError, nextAttemptAt, eventID).Error
+}
+
+func (ed *eventDatabase) GetEventDeliveries(ctx context.Context, eventID int) ([]string, error) {
+	var subscribers = make([]string, 0)
+	query := `SELECT subscriber FROM event_deliveries WHERE domain_event_id = $1;`
+	err := ed.DB.WithContext(ctx).Raw(query, eventID).Scan(&subscribers).Error
+	return subscribers, err
+}
+
+func (ed *eventDatabase) InsertEventDelivery(ctx context.Context, eventID int, subscriber string, now time.Time) error {
+	query := `INSERT INTO event_deliveries (domain_event_id,subscriber,delivered_at)VALUES($1,$2,$3) ON CONFLICT (domain_event_id,subscriber) DO NOTHING;`
+	return ed.DB.WithContext(ctx).Exec(query, eventID, subscriber, now).Error
+}
+
+func (ed *eventDatabase) GetEventsByStatus(ctx context.Context, status string, startIndex, endIndex int) ([]response.DomainEvent, error) {
+	var events = make([]response.DomainEvent, 0)
+	query := `SELECT * FROM domain_events WHERE status = $1 ORDER BY id DESC OFFSET $2 FETCH NEXT $3 ROW ONLY;`
+	err := ed.DB.WithContext(ctx).Raw(query, status, startIndex, endIndex).Scan(&events).Error
+	return events, err
+}
+
+// RequeueEvent puts a dead-lettered event back in the outbox with fresh attempts.
+func (ed *eventDatabase) RequeueEvent(ctx context.Context, eventID int, now time.Time) (response.DomainEvent, error) {
+	var event response.DomainEvent
+	query := `UPDATE domain_events SET status = 'pending' ,attempts = 0 ,next_attempt_at = $1 WHERE id = $2 AND status = 'dead' RETURNING *;`
+	err := ed.DB.WithContext(ctx).Raw(query, now, eventID).Scan(&event).Error
+	return event, err
+}
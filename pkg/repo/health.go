@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"context"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"gorm.io/gorm"
+)
+
+type healthDatabase struct {
+	DB *gorm.DB
+}
+
+func NewHealthRepository(DB *gorm.DB) interfaces.HealthRepository {
+	return &healthDatabase{
+		DB: DB,
+	}
+}
+
+func (hd *healthDatabase) Ping(ctx context.Context) error {
+	sqlDB, err := hd.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// CountTables counts the tables of the list that exist in the current schema.
+func (hd *healthDatabase) CountTables(ctx context.Context, tables []string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name IN ?;`
+	err := hd.DB.WithContext(ctx).Raw(query, tables).Scan(&count).Error
+	return count, err
+}
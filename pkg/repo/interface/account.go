@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type AccountRepository interface {
+	GetExportOrders(ctx context.Context, userID int) ([]response.ExportOrder, error)
+	GetExportRatings(ctx context.Context, userID int) ([]response.ExportRating, error)
+	GetExportWishlist(ctx context.Context, userID int) ([]response.ExportWishlistItem, error)
+	GetWalletBalance(ctx context.Context, userID int) (float32, error)
+	GetWalletHistory(ctx context.Context, userID int) ([]response.WalletTransactionHistory, error)
+	CountOpenOrders(ctx context.Context, userID int) (int, error)
+
+	InsertDeletion(ctx context.Context, userID int, reason string, requestedAt, scheduledFor time.Time) (response.AccountDeletion, error)
+	FindLatestDeletion(ctx context.Context, userID int) (response.AccountDeletion, error)
+	CloseDeletion(ctx context.Context, deletionID int, status string, closedAt time.Time) (response.AccountDeletion, error)
+	GetDueDeletions(ctx context.Context, now time.Time) ([]response.AccountDeletion, error)
+
+	AnonymiseUser(ctx context.Context, userID int, email, password string, now time.Time) error
+	AnonymiseOrderAddresses(ctx context.Context, userID int) error
+	DeletePersonalData(ctx context.Context, userID int, twoFactorSubject string) error
+}
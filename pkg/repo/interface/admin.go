@@ -1,7 +1,11 @@
 package interfaces
 
 import (
+	"context"
+	"time"
+
 	"github.com/anazibinurasheed/project-device-mart/pkg/config"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
 
@@ -15,11 +19,14 @@ import (
 // }
 
 type AdminRepository interface {
-	FindAdminCredentials() (config.AdminCredentials, error)
-	FetchAllUserData() ([]response.UserData, error)
-	BlockUserByID(userID int) error
-	UnblockUserByID(userID int) error
-	FindUsersByName(name string) ([]response.UserData, error)
+	FindAdminCredentials(ctx context.Context) (config.AdminCredentials, error)
+	GetUsers(ctx context.Context, filter request.UserFilter, startIndex, endIndex int) ([]response.UserData, error)
+	BlockUserByID(ctx context.Context, userID int) error
+	UnblockUserByID(ctx context.Context, userID int) error
+	FindUsersByName(ctx context.Context, name string) ([]response.UserData, error)
+	InsertAdminNote(ctx context.Context, userID int, note string, createdAt time.Time) (response.AdminNote, error)
+	GetAdminNotes(ctx context.Context, userID int) ([]response.AdminNote, error)
+	DeleteAdminNote(ctx context.Context, noteID int) (response.AdminNote, error)
 	SetupDB()
 
 }
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type AuditRepository interface {
+	InsertAuditLog(ctx context.Context, log request.AuditLog) (response.AuditLog, error)
+	SnapshotEntity(ctx context.Context, entityType, entityID string) (string, error)
+	GetAuditLogs(ctx context.Context, filter request.AuditLogFilter, beforeID, startIndex, endIndex int) ([]response.AuditLog, error)
+}
@@ -1,13 +1,33 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type CartRepository interface {
-	AddToCart(userID int, ProductID int) (response.Cart, error)
-	ViewCart(userID int) ([]response.Cart, error)
-	RemoveFromCart(userID int, productID int) (response.Cart, error)
-	IncrementQuantity(qty int, userID int, productID int) (response.Cart, error)
-	DecrementQuantity(qty int, userID int, productID int) (response.Cart, error)
-	GetCartItem(userID int, productID int) (response.Cart, error)
-	DeleteCart(userID int) (response.Cart, error)
+	AddToCart(ctx context.Context, userID int, ProductID int) (response.Cart, error)
+	ViewCart(ctx context.Context, userID int) ([]response.Cart, error)
+	RemoveFromCart(ctx context.Context, userID int, productID int) (response.Cart, error)
+	IncrementQuantity(ctx context.Context, qty int, userID int, productID int) (response.Cart, error)
+	DecrementQuantity(ctx context.Context, qty int, userID int, productID int) (response.Cart, error)
+	UpdateQuantity(ctx context.Context, qty int, userID int, productID int) (response.Cart, error)
+	GetCartItem(ctx context.Context, userID int, productID int) (response.Cart, error)
+	DeleteCart(ctx context.Context, userID int) (response.Cart, error)
+	GetCartAvailability(ctx context.Context, userID int) ([]response.CartAvailability, error)
+	UpdateAddedPrice(ctx context.Context, userID, productID, price int) error
+
+	AddToGuestCart(ctx context.Context, cartID string, productID int) (response.Cart, error)
+	ViewGuestCart(ctx context.Context, cartID string) ([]response.Cart, error)
+	GetGuestCartItem(ctx context.Context, cartID string, productID int) (response.Cart, error)
+	UpdateGuestCartQuantity(ctx context.Context, qty int, cartID string, productID int) (response.Cart, error)
+	RemoveFromGuestCart(ctx context.Context, cartID string, productID int) (response.Cart, error)
+	DeleteGuestCart(ctx context.Context, cartID string) error
+	AddGuestCoupon(ctx context.Context, cartID string, couponID int) (response.GuestCoupon, error)
+	GetGuestCoupons(ctx context.Context, cartID string) ([]response.GuestCoupon, error)
+	RemoveGuestCoupon(ctx context.Context, cartID string, couponID int) (response.GuestCoupon, error)
+	RemoveGuestCoupons(ctx context.Context, cartID string) error
+	RemoveExpiredGuestCoupons(ctx context.Context, now time.Time) error
 }
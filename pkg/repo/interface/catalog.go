@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/domain"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type CatalogRepository interface {
+	InsertProductImport(ctx context.Context, productImport domain.ProductImport) (response.ProductImport, error)
+	FindProductImport(ctx context.Context, importID int) (response.ProductImport, error)
+	GetProductImports(ctx context.Context, startIndex, endIndex int) ([]response.ProductImport, error)
+	GetProductImportRows(ctx context.Context, importID int) (string, error)
+	StartProductImport(ctx context.Context, importID int, now time.Time) (response.ProductImport, error)
+	UpdateProductImportProgress(ctx context.Context, productImport response.ProductImport) error
+	FinishProductImport(ctx context.Context, productImport response.ProductImport, now time.Time) error
+
+	FindCategoryByName(ctx context.Context, name string) (response.Category, error)
+	FindProductBySKU(ctx context.Context, sku string) (response.Product, error)
+	CreateProduct(ctx context.Context, product request.Product) (response.Product, error)
+	UpdateProduct(ctx context.Context, productID int, product request.Product) error
+	GetCatalogProducts(ctx context.Context, afterID, limit int) ([]response.CatalogProduct, error)
+}
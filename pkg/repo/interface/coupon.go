@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"context"
 	"time"
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
@@ -8,26 +9,39 @@ import (
 )
 
 type CouponRepository interface {
-	CreateCoupon(couponData request.Coupon) (response.Coupon, error)
-	GetAllCoupons() ([]response.Coupon, error)
-	BlockCouponByID(couponID int) (response.Coupon, error)
-	UnblockCouponByID(couponID int) (response.Coupon, error)
-	UpdateCouponDetails(couponData request.Coupon) (response.Coupon, error)
-	FindCouponByCode(couponCode string) (response.Coupon, error)
-	FindCouponByID(couponID int) (response.Coupon, error)
-	AddCouponTracking(userID, couponID int) (response.CouponTracking, error)
-	UpdateCouponUsage(userID int) (response.CouponTracking, error)
-
-	FindTrackingCoupon(userID, couponID int) (response.CouponTracking, error)
+	CreateCoupon(ctx context.Context, couponData request.Coupon) (response.Coupon, error)
+	GetAllCoupons(ctx context.Context) ([]response.Coupon, error)
+	BlockCouponByID(ctx context.Context, couponID int) (response.Coupon, error)
+	UnblockCouponByID(ctx context.Context, couponID int) (response.Coupon, error)
+	UpdateCouponDetails(ctx context.Context, couponData request.Coupon) (response.Coupon, error)
+	FindCouponByCode(ctx context.Context, couponCode string) (response.Coupon, error)
+	FindCouponByID(ctx context.Context, couponID int) (response.Coupon, error)
+	AddCouponTracking(ctx context.Context, userID, couponID int) (response.CouponTracking, error)
+	UpdateCouponUsage(ctx context.Context, userID int) (response.CouponTracking, error)
+
+	FindTrackingCoupon(ctx context.Context, userID, couponID int) (response.CouponTracking, error)
 	//actual check applied coupon
 	//CheckAppliedCoupon(userID int) (response.CouponTracking, error)
 
 	//the findappliedcoupon is this name changed
-	CheckAppliedCoupon(userID int) (response.CouponTracking, error)
+	CheckAppliedCoupon(ctx context.Context, userID int) (response.CouponTracking, error)
+
+	ChangeUserCoupon(ctx context.Context, couponID, userID int) (response.CouponTracking, error)
+	GetAvailableCouponsForUser(ctx context.Context, userID int, currentTime time.Time) ([]response.Coupon, error)
+	RemoveCouponFromTracking(ctx context.Context, couponID, userID int) (response.CouponTracking, error)
+	GetAppliedCoupons(ctx context.Context, userID int) ([]response.CouponTracking, error)
+	RemoveAppliedCoupons(ctx context.Context, userID int) error
+	RemoveExpiredCouponTrackings(ctx context.Context, now time.Time) error
+	CountCouponUsage(ctx context.Context, couponID int) (int, error)
+	CountUserCouponUsage(ctx context.Context, userID, couponID int) (int, error)
+
+	InsertCouponEligibility(ctx context.Context, couponID int, scope, value string) (response.CouponEligibility, error)
+	DeleteCouponEligibility(ctx context.Context, couponID int) error
+	GetCouponEligibility(ctx context.Context, couponID int) ([]response.CouponEligibility, error)
 
-	ChangeUserCoupon(couponID, userID int) (response.CouponTracking, error)
-	GetAvailableCouponsForUser(userID int, currentTime time.Time) ([]response.Coupon, error)
-	RemoveCouponFromTracking(couponID, userID int) (response.CouponTracking, error)
+	InsertOrderLineCoupon(ctx context.Context, orderLineID, couponID int, discount float32) (response.OrderLineCoupon, error)
+	GetOrderLineCoupons(ctx context.Context, orderLineID int) ([]response.OrderLineCoupon, error)
+	UpdateOrderLineCouponDiscount(ctx context.Context, id int, discount float32) (response.OrderLineCoupon, error)
 }
 
 // type CouponRepository interface {
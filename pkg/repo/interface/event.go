@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type EventRepository interface {
+	InsertEvent(ctx context.Context, event request.DomainEvent, now time.Time) (response.DomainEvent, error)
+	ClaimDueEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]response.DomainEvent, error)
+	MarkEventProcessed(ctx context.Context, eventID int, attempts int, now time.Time) error
+	MarkEventFailed(ctx context.Context, eventID, attempts int, lastError, status string, nextAttemptAt time.Time) error
+
+	GetEventDeliveries(ctx context.Context, eventID int) ([]string, error)
+	InsertEventDelivery(ctx context.Context, eventID int, subscriber string, now time.Time) error
+
+	GetEventsByStatus(ctx context.Context, status string, startIndex, endIndex int) ([]response.DomainEvent, error)
+	RequeueEvent(ctx context.Context, eventID int, now time.Time) (response.DomainEvent, error)
+}
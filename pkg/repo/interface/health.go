@@ -0,0 +1,8 @@
+package interfaces
+
+import "context"
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	CountTables(ctx context.Context, tables []string) (int, error)
+}
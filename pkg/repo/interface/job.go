@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type JobRepository interface {
+	InsertJob(ctx context.Context, job request.Job, now time.Time) (response.Job, error)
+	ClaimJob(ctx context.Context, now, leaseUntil time.Time) (response.Job, error)
+	ExtendJobLease(ctx context.Context, jobID int, leaseUntil time.Time) error
+	CompleteJob(ctx context.Context, jobID int, now time.Time) error
+	FailJob(ctx context.Context, jobID int, status, lastError string, runAt, now time.Time) error
+	RescheduleJob(ctx context.Context, jobID int, lastError string, runAt, now time.Time) error
+
+	GetJobs(ctx context.Context, filter request.JobFilter, startIndex, endIndex int) ([]response.Job, error)
+	GetJobStats(ctx context.Context) ([]response.JobStats, error)
+	RetryJob(ctx context.Context, jobID int, now time.Time) (response.Job, error)
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type LockoutRepository interface {
+	FindLockout(ctx context.Context, lockKey string) (response.LoginLockout, error)
+	ReserveLoginAttempt(ctx context.Context, lockKey, kind string, now, forgetBefore time.Time) (response.LoginLockout, error)
+	ReleaseLoginAttempt(ctx context.Context, lockKey string) error
+	SetLockedUntil(ctx context.Context, lockoutID int, lockedUntil time.Time) error
+	DeleteLockout(ctx context.Context, lockKey string) error
+	DeleteLockoutByID(ctx context.Context, lockoutID int) (response.LoginLockout, error)
+	GetLockouts(ctx context.Context, activeOnly bool, now time.Time, startIndex, endIndex int) ([]response.LoginLockout, error)
+}
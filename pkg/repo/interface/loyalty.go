@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type LoyaltyRepository interface {
+	CreateLoyaltyRule(ctx context.Context, rule request.LoyaltyRule) (response.LoyaltyRule, error)
+	UpdateLoyaltyRule(ctx context.Context, ruleID int, rule request.LoyaltyRule) (response.LoyaltyRule, error)
+	GetAllLoyaltyRules(ctx context.Context) ([]response.LoyaltyRule, error)
+	BlockLoyaltyRule(ctx context.Context, ruleID int, isBlocked bool) (response.LoyaltyRule, error)
+
+	InsertLoyaltyTransaction(ctx context.Context, transaction request.LoyaltyTransaction) (response.LoyaltyTransaction, error)
+	FindLoyaltyTransaction(ctx context.Context, orderID int, transactionType string) (response.LoyaltyTransaction, error)
+	GetLoyaltyHistory(ctx context.Context, userID, startIndex, endIndex int) ([]response.LoyaltyTransaction, error)
+	GetLoyaltyBalance(ctx context.Context, userID int, now time.Time) (int, error)
+	GetPointLots(ctx context.Context, userID int, now time.Time) ([]response.LoyaltyTransaction, error)
+	GetExpiredPointLots(ctx context.Context, now time.Time) ([]response.LoyaltyTransaction, error)
+	UpdateRemainingPoints(ctx context.Context, transactionID, remainingPoints int) (response.LoyaltyTransaction, error)
+
+	FindAppliedPoints(ctx context.Context, userID int) (response.AppliedPoints, error)
+	SetAppliedPoints(ctx context.Context, userID, points int) (response.AppliedPoints, error)
+	RemoveAppliedPoints(ctx context.Context, userID int) error
+}
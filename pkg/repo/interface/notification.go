@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type NotificationRepository interface {
+	InsertNotification(ctx context.Context, notification request.OutboxNotification, now time.Time) (response.Notification, error)
+	ClaimDueNotifications(ctx context.Context, now, leaseUntil time.Time, limit int) ([]response.Notification, error)
+	MarkNotificationSent(ctx context.Context, notificationID int, now time.Time) error
+	MarkNotificationFailed(ctx context.Context, notificationID, attempts int, lastError, status string, nextAttemptAt time.Time) error
+	GetUserNotifications(ctx context.Context, userID, startIndex, endIndex int) ([]response.Notification, error)
+
+	FindNotificationPreference(ctx context.Context, userID int) (response.NotificationPreference, bool, error)
+	UpsertNotificationPreference(ctx context.Context, userID int, preference request.NotificationPreference, now time.Time) (response.NotificationPreference, error)
+}
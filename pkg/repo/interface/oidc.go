@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type OIDCRepository interface {
+	InsertLoginState(ctx context.Context, state response.OIDCLoginState) (response.OIDCLoginState, error)
+	ConsumeLoginState(ctx context.Context, state string) (response.OIDCLoginState, error)
+	DeleteLoginStatesBefore(ctx context.Context, before time.Time) error
+
+	FindIdentity(ctx context.Context, provider, subject string) (response.UserIdentity, error)
+	InsertIdentity(ctx context.Context, identity response.UserIdentity) (response.UserIdentity, error)
+}
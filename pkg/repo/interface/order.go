@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"context"
 	"time"
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
@@ -8,30 +9,34 @@ import (
 )
 
 type OrderRepository interface {
-	GetUserOrderHistory(userID, startIndex, endIndex int) ([]response.Orders, error)
-	InsertOrder(request.NewOrder) (response.OrderLine, error)
-	ChangeOrderStatusByID(statusID int, orderID int) (response.OrderLine, error)
-	FindOrderByUserIDAndProductID(userID, productID int) (response.OrderLine, error)
-	FindOrderStatusByID(statusID int) (string, error)
-	GetAllOrderData(startIndex, endIndex int) ([]response.Orders, error)
-	FindOrderByID(orderID int) (response.OrderLine, error)
-	FindOrdersBoughtUsingCoupon(couponID int) ([]response.OrderLine, error)
-	InitializeNewUserWallet(userID int) (response.Wallet, error)
-	FindUserWalletByID(userID int) (response.Wallet, error)
-	UpdateUserWalletBalance(userID int, amount float32) (response.Wallet, error)
-	GetStatusReturned() (response.OrderStatus, error)
-	GetStatusCancelled() (response.OrderStatus, error)
-	GetStatusPending() (response.OrderStatus, error)
-	GetOrderStatuses() ([]response.OrderStatus, error)
-	GetInvoiceDataByID(orderID int) (response.Orders, error)
+	GetUserOrderHistory(ctx context.Context, userID, startIndex, endIndex int) ([]response.Orders, error)
+	InsertOrder(ctx context.Context, order request.NewOrder) (response.OrderLine, error)
+	ChangeOrderStatusByID(ctx context.Context, statusID int, orderID int) (response.OrderLine, error)
+	FindOrderByUserIDAndProductID(ctx context.Context, userID, productID int) (response.OrderLine, error)
+	FindOrderStatusByID(ctx context.Context, statusID int) (string, error)
+	GetAllOrderData(ctx context.Context, startIndex, endIndex int) ([]response.Orders, error)
+	FindOrderByID(ctx context.Context, orderID int) (response.OrderLine, error)
+	UpdateOrderQuantity(ctx context.Context, orderID, qty int, discount float32, pointsRedeemed int) (response.OrderLine, error)
+	InitializeNewUserWallet(ctx context.Context, userID int) (response.Wallet, error)
+	FindUserWalletByID(ctx context.Context, userID int) (response.Wallet, error)
+	UpdateUserWalletBalance(ctx context.Context, userID int, amount float32) (response.Wallet, error)
+	// AdjustUserWalletBalance adds amount to the balance in one statement, a debit
+	// of a negative amount returns no wallet when the balance does not cover it.
+	AdjustUserWalletBalance(ctx context.Context, userID int, amount float32) (response.Wallet, error)
+	GetStatusReturned(ctx context.Context) (response.OrderStatus, error)
+	GetStatusCancelled(ctx context.Context) (response.OrderStatus, error)
+	GetStatusPending(ctx context.Context) (response.OrderStatus, error)
+	GetOrderStatuses(ctx context.Context) ([]response.OrderStatus, error)
+	GetInvoiceDataByID(ctx context.Context, orderID int) (response.Orders, error)
+	GetUserOrderStats(ctx context.Context, userID int) (response.UserOrderStats, error)
 
-	UpdateWalletTransactionHistory(update request.WalletTransactionHistory) (response.WalletTransactionHistory, error)
-	GetWalletHistoryByUserID(userID int) ([]response.WalletTransactionHistory, error)
+	UpdateWalletTransactionHistory(ctx context.Context, update request.WalletTransactionHistory) (response.WalletTransactionHistory, error)
+	GetWalletHistoryByUserID(ctx context.Context, userID int) ([]response.WalletTransactionHistory, error)
 
-	TopSellingProduct(startDate, endDate time.Time) (response.TopSelling, error)
-	GetTotalSaleCount(startDate, endDate time.Time) (int, error)
-	GetAverageOrderValue(startDate, endDate time.Time) (float32, error)
-	GetTotalRevenue(returnID int, startDate, endDate time.Time) ([]response.OrderLine, error)
+	TopSellingProduct(ctx context.Context, startDate, endDate time.Time) (response.TopSelling, error)
+	GetTotalSaleCount(ctx context.Context, startDate, endDate time.Time) (int, error)
+	GetAverageOrderValue(ctx context.Context, startDate, endDate time.Time) (float32, error)
+	GetTotalRevenue(ctx context.Context, returnID int, startDate, endDate time.Time) ([]response.OrderLine, error)
 }
 
 // GetUserOrderHistory
@@ -48,6 +53,7 @@ type OrderRepository interface {
 // InitializeNewUserWallet
 // FindUserWalletByID
 // UpdateUserWalletBalance
+// AdjustUserWalletBalance
 // GetReturnedOrderStatus
 // GetInvoiceDataByID
 // UpdateWalletTransactionHistoryEntry
@@ -1,10 +1,19 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type PaymentRepository interface {
-	GetPaymentMethods() ([]response.PaymentMethod, error)
-	GetPaymentMethodCodId() (int, error)
-	GetPaymentMethodRazorpayId() (int, error)
-	FindPaymentMethodById(methodID int) (response.PaymentMethod, error)
+	GetPaymentMethods(ctx context.Context) ([]response.PaymentMethod, error)
+	GetPaymentMethodCodId(ctx context.Context) (int, error)
+	GetPaymentMethodRazorpayId(ctx context.Context) (int, error)
+	FindPaymentMethodById(ctx context.Context, methodID int) (response.PaymentMethod, error)
+
+	InsertOrderPayment(ctx context.Context, payment request.OrderPayment) (response.OrderPayment, error)
+	GetOrderPayments(ctx context.Context, orderLineID int) ([]response.OrderPayment, error)
+	UpdateOrderPaymentAmount(ctx context.Context, paymentID int, amount float32) (response.OrderPayment, error)
+	MarkOrderPaymentRefunded(ctx context.Context, paymentID int) (response.OrderPayment, error)
 }
@@ -1,41 +1,46 @@
 package interfaces
 
 import (
+	"context"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
 
 type ProductRepository interface {
-	CreateCategory(category request.Category) (response.Category, error)
-	ReadCategory(startIndex int, endIndex int) ([]response.Category, error)
-	UpdateCategory(categoryID int, category request.Category) error
-	BlockCategoryByID(categoryID int) error
-	UnBlockCategoryByID(categoryID int) error
-	FindCategoryByName(name string) (response.Category, error)
-	FindCategoryByID(categoryID int) (response.Category, error)
+	CreateCategory(ctx context.Context, category request.Category) (response.Category, error)
+	ReadCategory(ctx context.Context, startIndex int, endIndex int) ([]response.Category, error)
+	UpdateCategory(ctx context.Context, categoryID int, category request.Category) error
+	BlockCategoryByID(ctx context.Context, categoryID int) error
+	UnBlockCategoryByID(ctx context.Context, categoryID int) error
+	FindCategoryByName(ctx context.Context, name string) (response.Category, error)
+	FindCategoryByID(ctx context.Context, categoryID int) (response.Category, error)
 
-	CreateProduct(product request.Product) (response.Product, error)
-	ViewAllProductsToAdmin(startIndex, endIndex int) ([]response.Product, error)
-	ViewAllProductsToUser(userID, startIndex, endIndex int) ([]response.Product, error)
-	UpdateProduct(productID int, product request.UpdateProduct) error
-	BlockProduct(productID int) error
-	UnblockProduct(productID int) error
-	FindProductByName(paramName string) (response.Product, error)
-	FindProductByID(productID int) (response.Product, error)
-	ViewIndividualProduct(userID, productID int) (response.Product, error)
+	CreateProduct(ctx context.Context, product request.Product) (response.Product, error)
+	ViewAllProductsToAdmin(ctx context.Context, startIndex, endIndex int) ([]response.Product, error)
+	ViewAllProductsToUser(ctx context.Context, userID, startIndex, endIndex int) ([]response.Product, error)
+	UpdateProduct(ctx context.Context, productID int, product request.UpdateProduct) error
+	UpdateProductPrice(ctx context.Context, productID, price, mrp int) error
+	DecrementStock(ctx context.Context, productID, qty int) (response.Product, error)
+	IncrementStock(ctx context.Context, productID, qty int) error
+	BlockProduct(ctx context.Context, productID int) error
+	UnblockProduct(ctx context.Context, productID int) error
+	FindProductByName(ctx context.Context, paramName string) (response.Product, error)
+	FindProductByID(ctx context.Context, productID int) (response.Product, error)
+	ViewIndividualProduct(ctx context.Context, userID, productID int) (response.Product, error)
 
-	FindUserRatingOnProduct(userID, productID int) (response.Rating, error)
-	InsertProductRating(rating request.Rating) error
-	GetProductReviews(productID int) ([]response.Rating, error)
-	SearchProducts(search string, startIndex, endIndex int) ([]response.Product, error)
-	GetProductsByCategoryAdmin(categoryID, startIndex, endIndex int) ([]response.Product, error)
+	FindUserRatingOnProduct(ctx context.Context, userID, productID int) (response.Rating, error)
+	InsertProductRating(ctx context.Context, rating request.Rating) error
+	GetProductReviews(ctx context.Context, productID int) ([]response.Rating, error)
+	SearchProducts(ctx context.Context, search string, startIndex, endIndex int) ([]response.Product, error)
+	GetProductsByCategoryAdmin(ctx context.Context, categoryID, startIndex, endIndex int) ([]response.Product, error)
 
-	GetProductsByCategoryUser(userID, categoryID, startIndex, endIndex int) ([]response.Product, error)
+	GetProductsByCategoryUser(ctx context.Context, userID, categoryID, startIndex, endIndex int) ([]response.Product, error)
 
-	InsertCategoryIMG(urls interface{}, categoryID int) error
-	InsertProductIMG(urls interface{}, productID int) error
+	InsertCategoryIMG(ctx context.Context, urls interface{}, categoryID int) error
+	InsertProductIMG(ctx context.Context, urls interface{}, productID int) error
 
-	AddToWishList(userID, productID int) error
-	RemoveFromWishList(userID, productID int) error
-	ShowWishListProducts(userID, page, count int) ([]response.Product, error)
+	AddToWishList(ctx context.Context, userID, productID int) error
+	RemoveFromWishList(ctx context.Context, userID, productID int) error
+	IsWishListed(ctx context.Context, userID, productID int) (bool, error)
+	ShowWishListProducts(ctx context.Context, userID, page, count int) ([]response.Product, error)
 }
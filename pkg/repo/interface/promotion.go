@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type PromotionRepository interface {
+	CreatePromotion(ctx context.Context, promotion request.Promotion) (response.Promotion, error)
+	UpdatePromotion(ctx context.Context, promotionID int, promotion request.Promotion) (response.Promotion, error)
+	GetAllPromotions(ctx context.Context) ([]response.Promotion, error)
+	ChangePromotionBlockStatus(ctx context.Context, promotionID int, isBlocked bool) (response.Promotion, error)
+	GetActivePromotions(ctx context.Context, currentTime time.Time) ([]response.Promotion, error)
+	RefreshPromotionStatus(ctx context.Context, currentTime time.Time) error
+
+	InsertScheduledPrice(ctx context.Context, schedule request.ScheduledPrice) (response.ScheduledPrice, error)
+	GetScheduledPrices(ctx context.Context) ([]response.ScheduledPrice, error)
+	CancelScheduledPrice(ctx context.Context, scheduleID int) (response.ScheduledPrice, error)
+	GetDueScheduledPrices(ctx context.Context, currentTime time.Time) ([]response.ScheduledPrice, error)
+	GetEndedScheduledPrices(ctx context.Context, currentTime time.Time) ([]response.ScheduledPrice, error)
+	ActivateScheduledPrice(ctx context.Context, scheduleID int) (response.ScheduledPrice, error)
+	FindActiveScheduledPrice(ctx context.Context, productID int) (response.ScheduledPrice, error)
+	UpdateActiveBasePrice(ctx context.Context, productID, price, mrp int) error
+	CompleteScheduledPrice(ctx context.Context, scheduleID int) (response.ScheduledPrice, error)
+}
@@ -1,9 +1,26 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type ReferralRepository interface {
-	InsertNewReferralCode(userID int, referralCode string) (response.Referral, error)
-	FindReferralCodeByCode(referralCode string) (response.Referral, error)
-	FindReferralCodeByUserID(userID int) (response.Referral, error)
+	InsertNewReferralCode(ctx context.Context, userID int, referralCode string) (response.Referral, error)
+	FindReferralCodeByCode(ctx context.Context, referralCode string) (response.Referral, error)
+	FindReferralCodeByUserID(ctx context.Context, userID int) (response.Referral, error)
+	UpdateReferralDevice(ctx context.Context, referralID int, deviceID string) (response.Referral, error)
+
+	GetReferralProgramme(ctx context.Context) (response.ReferralProgramme, error)
+	InsertReferralProgramme(ctx context.Context, programme request.ReferralProgramme) (response.ReferralProgramme, error)
+
+	InsertReferralClaim(ctx context.Context, claim request.ReferralClaim) (response.ReferralClaim, error)
+	FindReferralClaimByRefereeID(ctx context.Context, refereeID int) (response.ReferralClaim, error)
+	GetReferralClaimsByUserID(ctx context.Context, userID int) ([]response.ReferralClaim, error)
+	CountReferralClaimsByDevice(ctx context.Context, deviceID string) (int, error)
+	ReleaseReferralClaim(ctx context.Context, claimID int, releasedAt time.Time) (response.ReferralClaim, error)
+	GetReferralStats(ctx context.Context, referrerID int) (response.ReferralStats, error)
 }
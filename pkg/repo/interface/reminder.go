@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type ReminderRepository interface {
+	GetIdleCarts(ctx context.Context, idleSince time.Time) ([]response.IdleCart, error)
+
+	FindOpenAbandonedCart(ctx context.Context, userID int) (response.AbandonedCart, error)
+	InsertAbandonedCart(ctx context.Context, cart response.IdleCart, now time.Time) (response.AbandonedCart, error)
+	UpdateAbandonedCart(ctx context.Context, abandonedCartID int, cart response.IdleCart) (response.AbandonedCart, error)
+	UpdateRemindersSent(ctx context.Context, abandonedCartID, remindersSent int) error
+	InsertCartReminder(ctx context.Context, reminder response.CartReminder) (response.CartReminder, error)
+	CloseEmptyAbandonedCarts(ctx context.Context, now time.Time) error
+	MarkCartRecovered(ctx context.Context, userID int, value float32, now time.Time) error
+
+	IsUnsubscribed(ctx context.Context, userID int) (bool, error)
+	Unsubscribe(ctx context.Context, userID int, now time.Time) error
+
+	GetAbandonedCartReport(ctx context.Context, from, to time.Time) (response.AbandonedCartReport, error)
+}
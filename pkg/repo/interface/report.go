@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type ReportRepository interface {
+	GetSalesSummary(ctx context.Context, from, to time.Time, pointValue float32) (response.SalesSummary, error)
+	GetSalesSeries(ctx context.Context, from, to time.Time, pointValue float32, granularity string) ([]response.SalesPeriod, error)
+	GetTopSales(ctx context.Context, dimension string, from, to time.Time, pointValue float32, limit int) ([]response.SalesRank, error)
+	GetPaymentMix(ctx context.Context, from, to time.Time, pointValue float32) ([]response.PaymentMix, error)
+	GetCouponPerformance(ctx context.Context, from, to time.Time, pointValue float32) ([]response.CouponPerformance, error)
+	GetCustomerSegments(ctx context.Context, from, to time.Time, pointValue float32) ([]response.CustomerSegment, error)
+	GetSalesLines(ctx context.Context, from, to time.Time, pointValue float32, afterID, limit int) ([]response.SalesLine, error)
+	GetTaxByState(ctx context.Context, from, to time.Time, pointValue float32) ([]response.TaxSummary, error)
+	GetRefundLines(ctx context.Context, from, to time.Time, afterID, limit int) ([]response.RefundLine, error)
+	GetRefundSummary(ctx context.Context, from, to time.Time) ([]response.RefundSummary, error)
+}
@@ -0,0 +1,26 @@
+package interfaces
+
+import "context"
+
+// TxRepositories are the repositories bound to one database transaction.
+type TxRepositories struct {
+	User      UserRepository
+	Order     OrderRepository
+	Payment   PaymentRepository
+	Product   ProductRepository
+	Coupon    CouponRepository
+	Wallet    WalletRepository
+	Event     EventRepository
+	OIDC      OIDCRepository
+	Account   AccountRepository
+	Cart      CartRepository
+	Loyalty   LoyaltyRepository
+	Promotion PromotionRepository
+	Lockout   LockoutRepository
+}
+
+type Transactor interface {
+	// Transaction runs fn in a database transaction, committed when fn returns
+	// nil and rolled back otherwise.
+	Transaction(ctx context.Context, fn func(repos TxRepositories) error) error
+}
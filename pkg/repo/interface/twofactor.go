@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type TwoFactorRepository interface {
+	FindTwoFactor(ctx context.Context, subject string) (response.TwoFactor, error)
+	UpsertPendingTwoFactor(ctx context.Context, subject, secret string, now time.Time) (response.TwoFactor, error)
+	EnableTwoFactor(ctx context.Context, twoFactorID int, now time.Time) error
+	UseTimeStep(ctx context.Context, twoFactorID int, step int64) (bool, error)
+	DeleteTwoFactor(ctx context.Context, twoFactorID int) error
+
+	ReplaceRecoveryCodes(ctx context.Context, twoFactorID int, codeHashes []string, now time.Time) error
+	UseRecoveryCode(ctx context.Context, twoFactorID int, codeHash string, now time.Time) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, twoFactorID int) (int, error)
+}
@@ -1,6 +1,9 @@
 package interfaces
 
 import (
+	"context"
+	"time"
+
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
@@ -24,20 +27,21 @@ import (
 //		FindAddressByAddressID(addressID int) (response.Address, error)
 //	}
 type UserRepository interface {
-	CreateUser(user request.SignUpData) (response.UserData, error)
-	FindUserByPhone(phone int) (response.UserData, error)
-	FindUserByEmail(email string) (response.UserData, error)
-	FindUserByID(id int) (response.UserData, error)
-	ReadCategories() ([]response.Category, error)
-	AddAddress(userID int, address request.Address) (response.Address, error)
-	GetListOfStates() ([]response.States, error)
-	FindDefaultAddress(userID int) (response.Address, error)
-	SetDefaultAddressStatus(status bool, addressID, userID int) (response.Address, error)
-	GetAllUserAddresses(userID int) ([]response.Address, error)
-	UpdateAddress(address request.Address, addressID, userID int) (response.Address, error)
-	DeleteAddress(addressID int) (response.Address, error)
-	ChangePassword(userID int, newPassword string) error
-	FindUserAddress(userID int) (response.Address, error)
-	UpdateUserName(name string, userID int) (response.UserData, error)
-	FindAddressByID(addressID int) (response.Address, error)
+	CreateUser(ctx context.Context, user request.SignUpData) (response.UserData, error)
+	FindUserByPhone(ctx context.Context, phone int) (response.UserData, error)
+	FindUserByEmail(ctx context.Context, email string) (response.UserData, error)
+	FindUserByID(ctx context.Context, id int) (response.UserData, error)
+	ReadCategories(ctx context.Context) ([]response.Category, error)
+	AddAddress(ctx context.Context, userID int, address request.Address) (response.Address, error)
+	GetListOfStates(ctx context.Context) ([]response.States, error)
+	FindDefaultAddress(ctx context.Context, userID int) (response.Address, error)
+	SetDefaultAddressStatus(ctx context.Context, status bool, addressID, userID int) (response.Address, error)
+	GetAllUserAddresses(ctx context.Context, userID int) ([]response.Address, error)
+	UpdateAddress(ctx context.Context, address request.Address, addressID, userID int) (response.Address, error)
+	DeleteAddress(ctx context.Context, addressID int) (response.Address, error)
+	ChangePassword(ctx context.Context, userID int, newPassword string) error
+	MarkEmailVerified(ctx context.Context, userID int, verifiedAt time.Time) error
+	FindUserAddress(ctx context.Context, userID int) (response.Address, error)
+	UpdateUserName(ctx context.Context, name string, userID int) (response.UserData, error)
+	FindAddressByID(ctx context.Context, addressID int) (response.Address, error)
 }
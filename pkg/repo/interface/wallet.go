@@ -1,7 +1,15 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type WalletRepository interface {
-	InsertIntoWallet(userID int, amount float32) (response.Wallet, error)
+	InsertIntoWallet(ctx context.Context, userID int, amount float32) (response.Wallet, error)
+
+	FindAppliedWallet(ctx context.Context, userID int) (response.AppliedWallet, error)
+	SetAppliedWallet(ctx context.Context, userID int, amount float32) (response.AppliedWallet, error)
+	RemoveAppliedWallet(ctx context.Context, userID int) error
 }
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type jobDatabase struct {
+	DB *gorm.DB
+}
+
+func NewJobRepository(DB *gorm.DB) interfaces.JobRepository {
+	return &jobDatabase{
+		DB: DB,
+	}
+}
+
+// InsertJob queues the job. A job whose unique key is held by a queued or running
+// job is not inserted and the returned job has no id.
+func (jd *jobDatabase) InsertJob(ctx context.Context, job request.Job, now time.Time) (response.Job, error) {
+	var insertedJob response.Job
+	query := `INSERT INTO jobs (type,payload,schedule,unique_key,max_attempts,run_at,created_at)VALUES($1,$2,$3,$4,$5,$6,$7)
+	ON CONFLICT (unique_key) WHERE status = 'queued' OR status = 'running' DO NOTHING RETURNING *;`
+	err := jd.DB.WithContext(ctx).Raw(query, job.Type, job.Payload, job.Schedule, job.UniqueKey, job.MaxAttempts, job.RunAt, now).Scan(&insertedJob).Error
+	return insertedJob, err
+}
+
+// ClaimJob takes the queued job due first, or a running job whose worker lost its
+// lease, and holds it until leaseUntil. The returned job has no id when none is due.
+func (jd *jobDatabase) ClaimJob(ctx context.Context, now, leaseUntil time.Time) (response.Job, error) {
+	var job response.Job
+	query := `UPDATE jobs SET status = 'running' ,attempts = attempts + 1 ,started_at = $1 ,locked_until = $2 ,finished_at = NULL WHERE id = (
+	SELECT id FROM jobs WHERE (status = 'queued' AND run_at <= $1) OR (status = 'running' AND locked_until < $1)
+	ORDER BY run_at, id LIMIT 1 FOR UPDATE SKIP LOCKED)
+	RETURNING *;`
+	err := jd.DB.WithContext(ctx).Raw(query, now, leaseUntil).Scan(&job).Error
+	return job, err
+}
+
+func (jd *jobDatabase) ExtendJobLease(ctx context.Context, jobID int, leaseUntil time.Time) error {
+	query := `UPDATE jobs SET locked_until = $1 WHERE id = $2 AND status = 'running';`
+	return jd.DB.WithContext(ctx).Exec(query, leaseUntil, jobID).Error
+}
+
+func (jd *jobDatabase) CompleteJob(ctx context.Context, jobID int, now time.Time) error {
+	query := `UPDATE jobs SET status = 'completed' ,last_error = '' ,locked_until = NULL ,finished_at = $1 WHERE id = $2;`
+	return jd.DB.WithContext(ctx).Exec(query, now, jobID).Error
+}
+
+// FailJob queues the job again at runAt, or marks it failed when the status is failed.
+func (jd *jobDatabase) FailJob(ctx context.Context, jobID int, status, lastError string, runAt, now time.Time) error {
+	query := `UPDATE jobs SET status = $1 ,last_error = $2 ,run_at = $3 ,locked_until = NULL ,finished_at = $4 WHERE id = $5;`
+	return jd.DB.WithContext(ctx).Exec(query, status, lastError, runAt, now, jobID).Error
+}
+
+// RescheduleJob queues a recurring job for its next run with fresh attempts.
+func (jd *jobDatabase) RescheduleJob(ctx context.Context, jobID int, lastError string, runAt, now time.Time) error {
+	query := `UPDATE jobs SET status = 'queued' ,attempts = 0 ,last_error = $1 ,run_at = $2 ,locked_until = NULL ,finished_at = $3 WHERE id = $4;`
+	return jd.DB.WithContext(ctx).Exec(query, lastError, runAt, now, jobID).Error
+}
+
+func (jd *jobDatabase) GetJobs(ctx context.Context, filter request.JobFilter, startIndex, endIndex int) ([]response.Job, error) {
+	var jobs = make([]response.Job, 0)
+	query := `SELECT * FROM jobs WHERE ($1 = '' OR status = $1) AND ($2 = '' OR type = $2)
+	ORDER BY id DESC OFFSET $3 FETCH NEXT $4 ROW ONLY;`
+	err := jd.DB.WithContext(ctx).Raw(query, filter.Status, filter.Type, startIndex, endIndex).Scan(&jobs).Error
+	return jobs, err
+}
+
+func (jd *jobDatabase) GetJobStats(ctx context.Context) ([]response.JobStats, error) {
+	var stats = make([]response.JobStats, 0)
+	query := `SELECT type, status, COUNT(*) AS count FROM jobs GROUP BY type, status ORDER BY type, status;`
+	err := jd.DB.WithContext(ctx).Raw(query).Scan(&stats).Error
+	return stats, err
+}
+
+// RetryJob queues a failed job again with fresh attempts. The returned job has no
+// id when the job is not failed, or another job with its unique key is active.
+func (jd *jobDatabase) RetryJob(ctx context.Context, jobID int, now time.Time) (response.Job, error) {
+	var job response.Job
+	query := `UPDATE jobs SET status = 'queued' ,attempts = 0 ,run_at = $1 ,finished_at = NULL WHERE id = $2 AND status = 'failed'
+	AND (unique_key IS NULL OR NOT EXISTS (SELECT 1 FROM jobs j WHERE j.unique_key = jobs.unique_key AND (j.status = 'queued' OR j.status = 'running')))
+	RETURNING *;`
+	err := jd.DB.WithContext(ctx).Raw(query, now, jobID).Scan(&job).Error
+	return job, err
+}
@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+// jobMemory keeps the jobs of a single process in memory, for tests and for
+// running without the jobs table. The jobs are lost on restart.
+type jobMemory struct {
+	mu     sync.Mutex
+	lastID uint
+	jobs   []*response.Job
+}
+
+func NewMemoryJobRepository() interfaces.JobRepository {
+	return &jobMemory{}
+}
+
+func jobActive(job *response.Job) bool {
+	return job.Status == "queued" || job.Status == "running"
+}
+
+// activeKey reports whether a queued or running job other than the given one
+// holds the unique key.
+func (jm *jobMemory) activeKey(uniqueKey *string, jobID uint) bool {
+	if uniqueKey == nil {
+		return false
+	}
+	for _, job := range jm.jobs {
+		if job.ID != jobID && job.UniqueKey != nil && *job.UniqueKey == *uniqueKey && jobActive(job) {
+			return true
+		}
+	}
+	return false
+}
+
+func (jm *jobMemory) find(jobID int) *response.Job {
+	for _, job := range jm.jobs {
+		if int(job.ID) == jobID {
+			return job
+		}
+	}
+	return nil
+}
+
+func (jm *jobMemory) InsertJob(ctx context.Context, job request.Job, now time.Time) (response.Job, error) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	if jm.activeKey(job.UniqueKey, 0) {
+		return response.Job{}, nil
+	}
+
+	jm.lastID++
+	insertedJob := &response.Job{
+		ID:          jm.lastID,
+		Type:        job.Type,
+		Payload:     job.Payload,
+		Schedule:    job.Schedule,
+		UniqueKey:   job.UniqueKey,
+		Status:      "queued",
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+		CreatedAt:   now,
+	}
+	jm.jobs = append(jm.jobs, insertedJob)
+	return *insertedJob, nil
+}
+
+func (jm *jobMemory) ClaimJob(ctx context.Context, now, leaseUntil time.Time) (response.Job, error) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	var due *response.Job
+	for _, job := range jm.jobs {
+		ready := job.Status == "queued" && !job.RunAt.After(now)
+		expired := job.Status == "running" && job.LockedUntil != nil && job.LockedUntil.Before(now)
+		if !ready && !expired {
+			continue
+		}
+		if due == nil || job.RunAt.Before(due.RunAt) {
+			due = job
+		}
+	}
+	if due == nil {
+		return response.Job{}, nil
+	}
+
+	due.Status = "running"
+	due.Attempts++
+	due.StartedAt = &now
+	due.LockedUntil = &leaseUntil
+	due.FinishedAt = nil
+	return *due, nil
+}
+
+func (jm *jobMemory) ExtendJobLease(ctx context.Context, jobID int, leaseUntil time.Time) error {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	if job := jm.find(jobID); job != nil && job.Status == "running" {
+		job.LockedUntil = &leaseUntil
+	}
+	return nil
+}
+
+func (jm *jobMemory) CompleteJob(ctx context.Context, jobID int, now time.Time) error {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	if job := jm.find(jobID); job != nil {
+		job.Status = "completed"
+		job.LastError = ""
+		job.LockedUntil = nil
+		job.FinishedAt = &now
+	}
+	return nil
+}
+
+func (jm *jobMemory) FailJob(ctx context.Context, jobID int, status, lastError string, runAt, now time.Time) error {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	if job := jm.find(jobID); job != nil {
+		job.Status = status
+		job.LastError = lastError
+		job.RunAt = runAt
+		job.LockedUntil = nil
+		job.FinishedAt = &now
+	}
+	return nil
+}
+
+func (jm *jobMemory) RescheduleJob(ctx context.Context, jobID int, lastError string, runAt, now time.Time) error {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	if job := jm.find(jobID); job != nil {
+		job.Status = "queued"
+		job.Attempts = 0
+		job.LastError = lastError
+		job.RunAt = runAt
+		job.LockedUntil = nil
+		job.FinishedAt = &now
+	}
+	return nil
+}
+
+func (jm *jobMemory) GetJobs(ctx context.Context, filter request.JobFilter, startIndex, endIndex int) ([]response.Job, error) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	var jobs = make([]response.Job, 0)
+	for i := len(jm.jobs) - 1; i >= 0; i-- {
+		job := jm.jobs[i]
+		if (filter.Status == "" || job.Status == filter.Status) && (filter.Type == "" || job.Type == filter.Type) {
+			jobs = append(jobs, *job)
+		}
+	}
+
+	if startIndex >= len(jobs) {
+		return make([]response.Job, 0), nil
+	}
+	jobs = jobs[startIndex:]
+	if endIndex < len(jobs) {
+		jobs = jobs[:endIndex]
+	}
+	return jobs, nil
+}
+
+func (jm *jobMemory) GetJobStats(ctx context.Context) ([]response.JobStats, error) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	counts := make(map[[2]string]int)
+	for _, job := range jm.jobs {
+		counts[[2]string{job.Type, job.Status}]++
+	}
+
+	var stats = make([]response.JobStats, 0, len(counts))
+	for key, count := range counts {
+		stats = append(stats, response.JobStats{Type: key[0], Status: key[1], Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Type != stats[j].Type {
+			return stats[i].Type < stats[j].Type
+		}
+		return stats[i].Status < stats[j].Status
+	})
+	return stats, nil
+}
+
+func (jm *jobMemory) RetryJob(ctx context.Context, jobID int, now time.Time) (response.Job, error) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	job := jm.find(jobID)
+	if job == nil || job.Status != "failed" || jm.activeKey(job.UniqueKey, job.ID) {
+		return response.Job{}, nil
+	}
+
+	job.Status = "queued"
+	job.Attempts = 0
+	job.RunAt = now
+	job.FinishedAt = nil
+	return *job, nil
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type lockoutDatabase struct {
+	DB *gorm.DB
+}
+
+func NewLockoutRepository(DB *gorm.DB) interfaces.LockoutRepository {
+	return &lockoutDatabase{
+		DB: DB,
+	}
+}
+
+func (ld *lockoutDatabase) FindLockout(ctx context.Context, lockKey string) (response.LoginLockout, error) {
+	var lockout response.LoginLockout
+	query := `SELECT * FROM login_lockouts WHERE lock_key = $1;`
+	err := ld.DB.WithContext(ctx).Raw(query, lockKey).Scan(&lockout).Error
+	return lockout, err
+}
+
+// ReserveLoginAttempt counts a login attempt of the key before its credentials are
+// checked, the count starts over when the last failure is older than forgetBefore.
+// Nothing is counted and the zero lockout is returned while the key is locked. In
+// a transaction the row stays locked until the commit, so a concurrent attempt
+// sees the count and the lock of this one.
+func (ld *lockoutDatabase) ReserveLoginAttempt(ctx context.Context, lockKey, kind string, now, forgetBefore time.Time) (response.LoginLockout, error) {
+	var lockout response.LoginLockout
+	query := `INSERT INTO login_lockouts (lock_key,kind,failures,last_failure_at,created_at) VALUES ($1,$2,1,$3,$3)
+	ON CONFLICT (lock_key) DO UPDATE SET
+	failures = CASE WHEN login_lockouts.last_failure_at < $4 THEN 1 ELSE login_lockouts.failures + 1 END,
+	last_failure_at = $3
+	WHERE login_lockouts.locked_until IS NULL OR login_lockouts.locked_until <= $3
+	RETURNING *;`
+	err := ld.DB.WithContext(ctx).Raw(query, lockKey, kind, now, forgetBefore).Scan(&lockout).Error
+	return lockout, err
+}
+
+// ReleaseLoginAttempt takes back the attempt reserved for a login that succeeded.
+func (ld *lockoutDatabase) ReleaseLoginAttempt(ctx context.Context, lockKey string) error {
+	query := `UPDATE login_lockouts SET failures = GREATEST(failures - 1, 0) WHERE lock_key = $1;`
+	return ld.DB.WithContext(ctx).Exec(query, lockKey).Error
+}
+
+func (ld *lockoutDatabase) SetLockedUntil(ctx context.Context, lockoutID int, lockedUntil time.Time) error {
+	query := `UPDATE login_lockouts SET locked_until = $2 WHERE id = $1;`
+	return ld.DB.WithContext(ctx).Exec(query, lockoutID, lockedUntil).Error
+}
+
+func (ld *lockoutDatabase) DeleteLockout(ctx context.Context, lockKey string) error {
+	query := `DELETE FROM login_lockouts WHERE lock_key = $1;`
+	return ld.DB.WithContext(ctx).Exec(query, lockKey).Error
+}
+
+func (ld *lockoutDatabase) DeleteLockoutByID(ctx context.Context, lockoutID int) (response.LoginLockout, error) {
+	var lockout response.LoginLockout
+	query := `DELETE FROM login_lockouts WHERE id = $1 RETURNING *;`
+	err := ld.DB.WithContext(ctx).Raw(query, lockoutID).Scan(&lockout).Error
+	return lockout, err
+}
+
+// GetLockouts lists the keys with failed logins, activeOnly keeps the keys whose
+// logins are refused at the moment.
+func (ld *lockoutDatabase) GetLockouts(ctx context.Context, activeOnly bool, now time.Time, startIndex, endIndex int) ([]response.LoginLockout, error) {
+	var lockouts = make([]response.LoginLockout, 0)
+	query := `SELECT * FROM login_lockouts WHERE ($1 = false OR locked_until > $2)
+	ORDER BY last_failure_at DESC OFFSET $3 FETCH NEXT $4 ROW ONLY;`
+	err := ld.DB.WithContext(ctx).Raw(query, activeOnly, now, startIndex, endIndex).Scan(&lockouts).Error
+	return lockouts, err
+}
@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type loyaltyDatabase struct {
+	DB *gorm.DB
+}
+
+func NewLoyaltyRepository(DB *gorm.DB) interfaces.LoyaltyRepository {
+	return &loyaltyDatabase{
+		DB: DB,
+	}
+}
+
+func (ld *loyaltyDatabase) CreateLoyaltyRule(ctx context.Context, rule request.LoyaltyRule) (response.LoyaltyRule, error) {
+	var insertedRule response.LoyaltyRule
+	query := `INSERT INTO loyalty_rules (name,category_id,min_spend,points_percent)VALUES($1,$2,$3,$4) RETURNING *;`
+	err := ld.DB.WithContext(ctx).Raw(query, rule.Name, rule.CategoryID, rule.MinSpend, rule.PointsPercent).Scan(&insertedRule).Error
+	return insertedRule, err
+}
+
+func (ld *loyaltyDatabase) UpdateLoyaltyRule(ctx context.Context, ruleID int, rule request.LoyaltyRule) (response.LoyaltyRule, error) {
+	var updatedRule response.LoyaltyRule
+	query := `UPDATE loyalty_rules SET name = $1 ,category_id = $2 ,min_spend = $3 ,points_percent = $4 WHERE id = $5 RETURNING *;`
+	err := ld.DB.WithContext(ctx).Raw(query, rule.Name, rule.CategoryID, rule.MinSpend, rule.PointsPercent, ruleID).Scan(&updatedRule).Error
+	return updatedRule, err
+}
+
+func (ld *loyaltyDatabase) GetAllLoyaltyRules(ctx context.Context) ([]response.LoyaltyRule, error) {
+	var rules = make([]response.LoyaltyRule, 0)
+	query := `SELECT * FROM loyalty_rules ORDER BY id DESC;`
+	err := ld.DB.WithContext(ctx).Raw(query).Scan(&rules).Error
+	return rules, err
+}
+
+func (ld *loyaltyDatabase) BlockLoyaltyRule(ctx context.Context, ruleID int, isBlocked bool) (response.LoyaltyRule, error) {
+	var rule response.LoyaltyRule
+	query := `UPDATE loyalty_rules SET is_blocked = $1 WHERE id = $2 RETURNING *;`
+	err := ld.DB.WithContext(ctx).Raw(query, isBlocked, ruleID).Scan(&rule).Error
+	return rule, err
+}
+
+func (ld *loyaltyDatabase) InsertLoyaltyTransaction(ctx context.Context, transaction request.LoyaltyTransaction) (response.LoyaltyTransaction, error) {
+	var insertedTransaction response.LoyaltyTransaction
+	query := `INSERT INTO loyalty_transactions (user_id,order_id,points,remaining_points,type,expires_at,created_at)VALUES($1,$2,$3,$4,$5,$6,$7) RETURNING *;`
+	err := ld.DB.WithContext(ctx).Raw(query, transaction.UserID, transaction.OrderID, transaction.Points, transaction.RemainingPoints, transaction.Type, transaction.ExpiresAt, time.Now()).Scan(&insertedTransaction).Error
+	return insertedTransaction, err
+}
+
+func (ld *loyaltyDatabase) FindLoyaltyTransaction(ctx context.Context, orderID int, transactionType string) (response.LoyaltyTransaction, error) {
+	var transaction response.LoyaltyTransaction
+	query := `SELECT * FROM loyalty_transactions WHERE order_id = $1 AND type = $2 ORDER BY id LIMIT 1;`
+	err := ld.DB.WithContext(ctx).Raw(query, orderID, transactionType).Scan(&transaction).Error
+	return transaction, err
+}
+
+func (ld *loyaltyDatabase) GetLoyaltyHistory(ctx context.Context, userID, startIndex, endIndex int) ([]response.LoyaltyTransaction, error) {
+	var history = make([]response.LoyaltyTransaction, 0)
+	query := `SELECT * FROM loyalty_transactions WHERE user_id = $1 ORDER BY id DESC OFFSET $2 FETCH NEXT $3 ROW ONLY;`
+	err := ld.DB.WithContext(ctx).Raw(query, userID, startIndex, endIndex).Scan(&history).Error
+	return history, err
+}
+
+func (ld *loyaltyDatabase) GetLoyaltyBalance(ctx context.Context, userID int, now time.Time) (int, error) {
+	var balance int
+	query := `SELECT COALESCE(SUM(remaining_points),0) FROM loyalty_transactions WHERE user_id = $1 AND remaining_points > 0 AND expires_at > $2;`
+	err := ld.DB.WithContext(ctx).Raw(query, userID, now).Scan(&balance).Error
+	return balance, err
+}
+
+func (ld *loyaltyDatabase) GetPointLots(ctx context.Context, userID int, now time.Time) ([]response.LoyaltyTransaction, error) {
+	var lots = make([]response.LoyaltyTransaction, 0)
+	query := `SELECT * FROM loyalty_transactions WHERE user_id = $1 AND remaining_points > 0 AND expires_at > $2 ORDER BY expires_at, id;`
+	err := ld.DB.WithContext(ctx).Raw(query, userID, now).Scan(&lots).Error
+	return lots, err
+}
+
+func (ld *loyaltyDatabase) GetExpiredPointLots(ctx context.Context, now time.Time) ([]response.LoyaltyTransaction, error) {
+	var lots = make([]response.LoyaltyTransaction, 0)
+	query := `SELECT * FROM loyalty_transactions WHERE remaining_points > 0 AND expires_at <= $1 ORDER BY id;`
+	err := ld.DB.WithContext(ctx).Raw(query, now).Scan(&lots).Error
+	return lots, err
+}
+
+func (ld *loyaltyDatabase) UpdateRemainingPoints(ctx context.Context, transactionID, remainingPoints int) (response.LoyaltyTransaction, error) {
+	var updatedTransaction response.LoyaltyTransaction
+	query := `UPDATE loyalty_transactions SET remaining_points = $1 WHERE id = $2 RETURNING *;`
+	err := ld.DB.WithContext(ctx).Raw(query, remainingPoints, transactionID).Scan(&updatedTransaction).Error
+	return updatedTransaction, err
+}
+
+func (ld *loyaltyDatabase) FindAppliedPoints(ctx context.Context, userID int) (response.AppliedPoints, error) {
+	var applied response.AppliedPoints
+	query := `SELECT * FROM applied_points WHERE user_id = $1;`
+	err := ld.DB.WithContext(ctx).Raw(query, userID).Scan(&applied).Error
+	return applied, err
+}
+
+func (ld *loyaltyDatabase) SetAppliedPoints(ctx context.Context, userID, points int) (response.AppliedPoints, error) {
+	var applied response.AppliedPoints
+	query := `INSERT INTO applied_points (user_id,points)VALUES($1,$2)
+	ON CONFLICT (user_id) DO UPDATE SET points = EXCLUDED.points RETURNING *;`
+	err := ld.DB.WithContext(ctx).Raw(query, userID, points).Scan(&applied).Error
+	return applied, err
+}
+
+func (ld *loyaltyDatabase) RemoveAppliedPoints(ctx context.Context, userID int) error {
+	query := `DELETE FROM applied_points WHERE user_id = $1;`
+	return ld.DB.WithContext(ctx).Exec(query, userID).Error
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type notificationDatabase struct {
+	DB *gorm.DB
+}
+
+func NewNotificationRepository(DB *gorm.DB) interfaces.NotificationRepository {
+	return &notificationDatabase{
+		DB: DB,
+	}
+}
+
+func (nd *notificationDatabase) InsertNotification(ctx context.Context, notification request.OutboxNotification, now time.Time) (response.Notification, error) {
+	var insertedNotification response.Notification
+	query := `INSERT INTO notifications (user_id,event,channel,recipient,subject,body,next_attempt_at,created_at)VALUES($1,$2,$3,$4,$5,$6,$7,$8) RETURNING *;`
+	err := nd.DB.WithContext(ctx).Raw(query, notification.UserID, notification.Event, notification.Channel, notification.Recipient, notification.Subject, notification.Body, notification.NextAttemptAt, now).Scan(&insertedNotification).Error
+	return insertedNotification, err
+}
+
+// ClaimDueNotifications takes the pending notifications due by now and holds them
+// until leaseUntil, so a notification is not picked again while it is being sent.
+func (nd *notificationDatabase) ClaimDueNotifications(ctx context.Context, now, leaseUntil time.Time, limit int) ([]response.Notification, error) {
+	var notifications = make([]response.Notification, 0)
+	query := `UPDATE notifications SET next_attempt_at = $1 WHERE id IN (
+	SELECT id FROM notifications WHERE status = 'pending' AND next_attempt_at <= $2 ORDER BY next_attempt_at, id LIMIT $3 FOR UPDATE SKIP LOCKED)
+	RETURNING *;`
+	err := nd.DB.WithContext(ctx).Raw(query, leaseUntil, now, limit).Scan(&notifications).Error
+	return notifications, err
+}
+
+func (nd *notificationDatabase) MarkNotificationSent(ctx context.Context, notificationID int, now time.Time) error {
+	query := `UPDATE notifications SET status = 'sent' ,attempts = attempts + 1 ,last_error = '' ,sent_at = $1 WHERE id = $2;`
+	return nd.DB.WithContext(ctx).Exec(query, now, notificationID).Error
+}
+
+func (nd *notificationDatabase) MarkNotificationFailed(ctx context.Context, notificationID, attempts int, lastError, status string, nextAttemptAt time.Time) error {
+	query := `UPDATE notifications SET status = $1 ,attempts = $2 ,last_error = $3 ,next_attempt_at = $4 WHERE id = $5;`
+	return nd.DB.WithContext(ctx).Exec(query, status, attempts, lastError, nextAttemptAt, notificationID).Error
+}
+
+func (nd *notificationDatabase) GetUserNotifications(ctx context.Context, userID, startIndex, endIndex int) ([]response.Notification, error) {
+	var notifications = make([]response.Notification, 0)
+	query := `SELECT * FROM notifications WHERE user_id = $1 ORDER BY id DESC OFFSET $2 FETCH NEXT $3 ROW ONLY;`
+	err := nd.DB.WithContext(ctx).Raw(query, userID, startIndex, endIndex).Scan(&notifications).Error
+	return notifications, err
+}
+
+// FindNotificationPreference returns the preference of the user and whether the user has set one.
+func (nd *notificationDatabase) FindNotificationPreference(ctx context.Context, userID int) (response.NotificationPreference, bool, error) {
+	var preferences = make([]response.NotificationPreference, 0)
+	query := `SELECT email, sms FROM notification_preferences WHERE user_id = $1;`
+	err := nd.DB.WithContext(ctx).Raw(query, userID).Scan(&preferences).Error
+	if err != nil || len(preferences) == 0 {
+		return response.NotificationPreference{}, false, err
+	}
+	return preferences[0], true, nil
+}
+
+func (nd *notificationDatabase) UpsertNotificationPreference(ctx context.Context, userID int, preference request.NotificationPreference, now time.Time) (response.NotificationPreference, error) {
+	var updatedPreference response.NotificationPreference
+	query := `INSERT INTO notification_preferences (user_id,email,sms,updated_at)VALUES($1,$2,$3,$4)
+	ON CONFLICT (user_id) DO UPDATE SET email = EXCLUDED.email ,sms = EXCLUDED.sms ,updated_at = EXCLUDED.updated_at
+	RETURNING email, sms;`
+	err := nd.DB.WithContext(ctx).Raw(query, userID, preference.Email, preference.SMS, now).Scan(&updatedPreference).Error
+	return updatedPreference, err
+}
@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type oidcDatabase struct {
+	DB *gorm.DB
+}
+
+func NewOIDCRepository(DB *gorm.DB) interfaces.OIDCRepository {
+	return &oidcDatabase{
+		DB: DB,
+	}
+}
+
+func (od *oidcDatabase) InsertLoginState(ctx context.Context, state response.OIDCLoginState) (response.OIDCLoginState, error) {
+	var insertedState response.OIDCLoginState
+	query := `INSERT INTO oidc_login_states (state,provider,nonce,code_verifier,created_at) VALUES ($1,$2,$3,$4,$5) RETURNING *;`
+	err := od.DB.WithContext(ctx).Raw(query, state.State, state.Provider, state.Nonce, state.CodeVerifier, state.CreatedAt).Scan(&insertedState).Error
+	return insertedState, err
+}
+
+// ConsumeLoginState deletes and returns the state, a state can be used only once.
+func (od *oidcDatabase) ConsumeLoginState(ctx context.Context, state string) (response.OIDCLoginState, error) {
+	var loginState response.OIDCLoginState
+	query := `DELETE FROM oidc_login_states WHERE state = $1 RETURNING *;`
+	err := od.DB.WithContext(ctx).Raw(query, state).Scan(&loginState).Error
+	return loginState, err
+}
+
+func (od *oidcDatabase) DeleteLoginStatesBefore(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM oidc_login_states WHERE created_at < $1;`
+	return od.DB.WithContext(ctx).Exec(query, before).Error
+}
+
+func (od *oidcDatabase) FindIdentity(ctx context.Context, provider, subject string) (response.UserIdentity, error) {
+	var identity response.UserIdentity
+	query := `SELECT * FROM user_identities WHERE provider = $1 AND subject = $2;`
+	err := od.DB.WithContext(ctx).Raw(query, provider, subject).Scan(&identity).Error
+	return identity, err
+}
+
+func (od *oidcDatabase) InsertIdentity(ctx context.Context, identity response.UserIdentity) (response.UserIdentity, error) {
+	var insertedIdentity response.UserIdentity
+	query := `INSERT INTO user_identities (user_id,provider,subject,email,created_at) VALUES ($1,$2,$3,$4,$5) RETURNING *;`
+	err := od.DB.WithContext(ctx).Raw(query, identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt).Scan(&insertedIdentity).Error
+	return insertedIdentity, err
+}
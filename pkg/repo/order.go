@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"context"
 	"time"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
@@ -19,35 +20,35 @@ func NewOrderRepository(DB *gorm.DB) interfaces.OrderRepository {
 	}
 }
 
-func (od *orderDatabase) InsertOrder(order request.NewOrder) (response.OrderLine, error) {
+func (od *orderDatabase) InsertOrder(ctx context.Context, order request.NewOrder) (response.OrderLine, error) {
 	var NewOrderLine response.OrderLine
-	query := `INSERT INTO order_lines (user_id,product_id,addresses_id,qty,price,payment_method_id,order_status_id,coupon_id,created_at,updated_at)VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING * ;`
-	err := od.DB.Raw(query, order.UserID, order.ProductID, order.AddressID, order.Qty, order.Price, order.PaymentMethodID, order.OrderStatusID, order.CouponID, order.CreatedAt, order.UpdatedAt).Scan(&NewOrderLine).Error
+	query := `INSERT INTO order_lines (user_id,product_id,addresses_id,qty,price,discount,points_redeemed,payment_method_id,order_status_id,coupon_id,created_at,updated_at)VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING * ;`
+	err := od.DB.WithContext(ctx).Raw(query, order.UserID, order.ProductID, order.AddressID, order.Qty, order.Price, order.Discount, order.PointsRedeemed, order.PaymentMethodID, order.OrderStatusID, order.CouponID, order.CreatedAt, order.UpdatedAt).Scan(&NewOrderLine).Error
 	return NewOrderLine, err
 }
 
-func (od *orderDatabase) GetStatusPending() (response.OrderStatus, error) {
+func (od *orderDatabase) GetStatusPending(ctx context.Context) (response.OrderStatus, error) {
 	var OrderStatus response.OrderStatus
 	query := `SELECT * FROM order_statuses WHERE status = 'Pending';`
-	err := od.DB.Raw(query).Scan(&OrderStatus).Error
+	err := od.DB.WithContext(ctx).Raw(query).Scan(&OrderStatus).Error
 	return OrderStatus, err
 }
 
-func (od *orderDatabase) GetStatusCancelled() (response.OrderStatus, error) {
+func (od *orderDatabase) GetStatusCancelled(ctx context.Context) (response.OrderStatus, error) {
 	var OrderStatus response.OrderStatus
 	query := `SELECT * FROM order_statuses WHERE status = 'Cancelled';`
-	err := od.DB.Raw(query).Scan(&OrderStatus).Error
+	err := od.DB.WithContext(ctx).Raw(query).Scan(&OrderStatus).Error
 	return OrderStatus, err
 }
 
-func (od *orderDatabase) GetStatusReturned() (response.OrderStatus, error) {
+func (od *orderDatabase) GetStatusReturned(ctx context.Context) (response.OrderStatus, error) {
 	var OrderStatus response.OrderStatus
 	query := `SELECT * FROM order_statuses WHERE status = 'Returned';`
-	err := od.DB.Raw(query).Scan(&OrderStatus).Error
+	err := od.DB.WithContext(ctx).Raw(query).Scan(&OrderStatus).Error
 	return OrderStatus, err
 }
 
-func (od *orderDatabase) GetUserOrderHistory(userID, startIndex, endIndex int) ([]response.Orders, error) {
+func (od *orderDatabase) GetUserOrderHistory(ctx context.Context, userID, startIndex, endIndex int) ([]response.Orders, error) {
 	var OrderHistory = make([]response.Orders, 0)
 	query := `SELECT
     o.id AS order_id,
@@ -70,11 +71,11 @@ INNER JOIN addresses a ON o.addresses_id = a.id
 INNER JOIN states states ON a.state_id = states.id
 WHERE o.user_id = $1
 ORDER BY o.id DESC OFFSET $2 FETCH NEXT $3 ROW ONLY;`
-	err := od.DB.Raw(query, userID, startIndex, endIndex).Scan(&OrderHistory).Error
+	err := od.DB.WithContext(ctx).Raw(query, userID, startIndex, endIndex).Scan(&OrderHistory).Error
 	return OrderHistory, err
 }
 
-func (od *orderDatabase) GetInvoiceDataByID(orderID int) (response.Orders, error) {
+func (od *orderDatabase) GetInvoiceDataByID(ctx context.Context, orderID int) (response.Orders, error) {
 	var OrderData response.Orders
 	query := `SELECT
     o.id AS order_id,
@@ -97,12 +98,12 @@ INNER JOIN addresses a ON o.addresses_id = a.id
 INNER JOIN states states ON a.state_id = states.id
 WHERE  o.id = $1;`
 
-	err := od.DB.Raw(query, orderID).Scan(&OrderData).Error
+	err := od.DB.WithContext(ctx).Raw(query, orderID).Scan(&OrderData).Error
 
 	return OrderData, err
 }
 
-func (od *orderDatabase) GetAllOrderData(startIndex, endIndex int) ([]response.Orders, error) {
+func (od *orderDatabase) GetAllOrderData(ctx context.Context, startIndex, endIndex int) ([]response.Orders, error) {
 	var OrderHistory = make([]response.Orders, 0)
 	query := `SELECT
     o.id AS order_id,
@@ -125,100 +126,109 @@ INNER JOIN addresses a ON o.addresses_id = a.id
 INNER JOIN states states ON a.state_id = states.id
 ORDER BY o.id DESC OFFSET $1 FETCH NEXT $2 ROW ONLY;
 `
-	err := od.DB.Raw(query, startIndex, endIndex).Scan(&OrderHistory).Error
+	err := od.DB.WithContext(ctx).Raw(query, startIndex, endIndex).Scan(&OrderHistory).Error
 
 	return OrderHistory, err
 }
 
-func (od *orderDatabase) GetOrderStatuses() ([]response.OrderStatus, error) {
+func (od *orderDatabase) GetOrderStatuses(ctx context.Context) ([]response.OrderStatus, error) {
 	var OrderStatus = make([]response.OrderStatus, 0)
 	query := `SELECT * FROM order_statuses ORDER BY id;`
-	err := od.DB.Raw(query).Scan(&OrderStatus).Error
+	err := od.DB.WithContext(ctx).Raw(query).Scan(&OrderStatus).Error
 	return OrderStatus, err
 
 }
 
-func (od *orderDatabase) ChangeOrderStatusByID(statusID int, orderID int) (response.OrderLine, error) {
+func (od *orderDatabase) ChangeOrderStatusByID(ctx context.Context, statusID int, orderID int) (response.OrderLine, error) {
 	var UpdatedOrder response.OrderLine
 	query := `UPDATE order_lines SET order_status_id = $1 WHERE id = $2 RETURNING * ;`
-	err := od.DB.Raw(query, statusID, orderID).Scan(&UpdatedOrder).Error
+	err := od.DB.WithContext(ctx).Raw(query, statusID, orderID).Scan(&UpdatedOrder).Error
 	return UpdatedOrder, err
 }
 
-func (od *orderDatabase) FindOrderByUserIDAndProductID(userID, productID int) (response.OrderLine, error) {
+func (od *orderDatabase) FindOrderByUserIDAndProductID(ctx context.Context, userID, productID int) (response.OrderLine, error) {
 	var OrderData response.OrderLine
 
 	query := `SELECT * FROM order_lines WHERE user_id= $1 AND product_id=$2 ;`
-	err := od.DB.Raw(query, userID, productID).Scan(&OrderData).Error
+	err := od.DB.WithContext(ctx).Raw(query, userID, productID).Scan(&OrderData).Error
 	return OrderData, err
 }
 
-func (od *orderDatabase) FindOrderStatusByID(statusID int) (string, error) {
+func (od *orderDatabase) FindOrderStatusByID(ctx context.Context, statusID int) (string, error) {
 	var status string
 	query := `SELECT status FROM order_statuses WHERE id = $1 ;`
-	err := od.DB.Raw(query, statusID).Scan(&status).Error
+	err := od.DB.WithContext(ctx).Raw(query, statusID).Scan(&status).Error
 
 	return status, err
 }
 
-func (od *orderDatabase) FindOrderByID(orderID int) (response.OrderLine, error) {
+func (od *orderDatabase) FindOrderByID(ctx context.Context, orderID int) (response.OrderLine, error) {
 	var Order response.OrderLine
 	query := `SELECT * FROM order_lines WHERE id = $1 ;`
-	err := od.DB.Raw(query, orderID).Scan(&Order).Error
+	err := od.DB.WithContext(ctx).Raw(query, orderID).Scan(&Order).Error
 	return Order, err
 }
 
-func (od *orderDatabase) FindOrdersBoughtUsingCoupon(couponID int) ([]response.OrderLine, error) {
-	var Orders = make([]response.OrderLine, 0)
-	query := `SELECT * FROM order_lines WHERE coupon_id = $1 ; `
-	err := od.DB.Raw(query, couponID).Scan(&Orders).Error
-	return Orders, err
+func (od *orderDatabase) UpdateOrderQuantity(ctx context.Context, orderID, qty int, discount float32, pointsRedeemed int) (response.OrderLine, error) {
+	var UpdatedOrder response.OrderLine
+	query := `UPDATE order_lines SET qty = $1 ,discount = $2 ,points_redeemed = $3 ,updated_at = $4 WHERE id = $5 RETURNING * ;`
+	err := od.DB.WithContext(ctx).Raw(query, qty, discount, pointsRedeemed, time.Now(), orderID).Scan(&UpdatedOrder).Error
+	return UpdatedOrder, err
 }
 
-func (od *orderDatabase) InitializeNewUserWallet(userID int) (response.Wallet, error) {
+func (od *orderDatabase) InitializeNewUserWallet(ctx context.Context, userID int) (response.Wallet, error) {
 	var NewWallet response.Wallet
 
 	query := `INSERT INTO wallets (user_id,amount)VALUES($1,$2) RETURNING *;`
-	err := od.DB.Raw(query, userID, 0).Scan(&NewWallet).Error
+	err := od.DB.WithContext(ctx).Raw(query, userID, 0).Scan(&NewWallet).Error
 	return NewWallet, err
 }
 
-func (od *orderDatabase) FindUserWalletByID(userID int) (response.Wallet, error) {
+func (od *orderDatabase) FindUserWalletByID(ctx context.Context, userID int) (response.Wallet, error) {
 	var Wallet response.Wallet
 
 	query := `SELECT * FROM wallets WHERE user_id = $1 ;`
-	err := od.DB.Raw(query, userID).Scan(&Wallet).Error
+	err := od.DB.WithContext(ctx).Raw(query, userID).Scan(&Wallet).Error
 	return Wallet, err
 }
 
-func (od *orderDatabase) UpdateUserWalletBalance(userID int, amount float32) (response.Wallet, error) {
+func (od *orderDatabase) UpdateUserWalletBalance(ctx context.Context, userID int, amount float32) (response.Wallet, error) {
 	var UpdatedWallet response.Wallet
 
 	query := `UPDATE wallets SET amount = $1 WHERE user_id = $2 RETURNING *;`
-	err := od.DB.Raw(query, amount, userID).Scan(&UpdatedWallet).Error
+	err := od.DB.WithContext(ctx).Raw(query, amount, userID).Scan(&UpdatedWallet).Error
+	return UpdatedWallet, err
+}
+
+func (od *orderDatabase) AdjustUserWalletBalance(ctx context.Context, userID int, amount float32) (response.Wallet, error) {
+	var UpdatedWallet response.Wallet
+
+	query := `UPDATE wallets SET amount = amount + $1 WHERE user_id = $2 AND amount + $1 >= 0 RETURNING *;`
+	err := od.DB.WithContext(ctx).Raw(query, amount, userID).Scan(&UpdatedWallet).Error
 	return UpdatedWallet, err
 }
-func (od *orderDatabase) UpdateWalletTransactionHistory(update request.WalletTransactionHistory) (response.WalletTransactionHistory, error) {
+
+func (od *orderDatabase) UpdateWalletTransactionHistory(ctx context.Context, update request.WalletTransactionHistory) (response.WalletTransactionHistory, error) {
 
 	var updatedHistory response.WalletTransactionHistory
 
 	query := `INSERT INTO wallet_transaction_histories(transaction_time,user_id,amount,transaction_type) 
 	VALUES($1,$2,$3,$4) RETURNING *;`
 
-	err := od.DB.Raw(query, update.TransactionTime, update.UserID, update.Amount, update.TransactionType).Scan(&updatedHistory).Error
+	err := od.DB.WithContext(ctx).Raw(query, update.TransactionTime, update.UserID, update.Amount, update.TransactionType).Scan(&updatedHistory).Error
 
 	return updatedHistory, err
 }
 
-func (od *orderDatabase) GetWalletHistoryByUserID(userID int) ([]response.WalletTransactionHistory, error) {
+func (od *orderDatabase) GetWalletHistoryByUserID(ctx context.Context, userID int) ([]response.WalletTransactionHistory, error) {
 
 	var walletHistory = make([]response.WalletTransactionHistory, 0)
 	query := `SELECT * FROM wallet_transaction_histories WHERE user_id = $1 ;`
-	err := od.DB.Raw(query, userID).Scan(&walletHistory).Error
+	err := od.DB.WithContext(ctx).Raw(query, userID).Scan(&walletHistory).Error
 	return walletHistory, err
 }
 
-func (o *orderDatabase) TopSellingProduct(startDate, endDate time.Time) (response.TopSelling, error) {
+func (o *orderDatabase) TopSellingProduct(ctx context.Context, startDate, endDate time.Time) (response.TopSelling, error) {
 
 	var data response.TopSelling
 	query := ` SELECT product_id, SUM(qty) AS quantity
@@ -227,37 +237,47 @@ func (o *orderDatabase) TopSellingProduct(startDate, endDate time.Time) (respons
 	ORDER BY quantity DESC
 	LIMIT 1;`
 
-	err := o.DB.Raw(query, startDate, endDate).Scan(&data).Error
+	err := o.DB.WithContext(ctx).Raw(query, startDate, endDate).Scan(&data).Error
 	return data, err
 
 }
 
-func (o *orderDatabase) GetTotalSaleCount(startDate, endDate time.Time) (int, error) {
+func (o *orderDatabase) GetTotalSaleCount(ctx context.Context, startDate, endDate time.Time) (int, error) {
 
 	var count int
 	query := `SELECT COUNT(*) AS count
 	FROM order_lines
 	WHERE created_at >= $1 AND created_at <= $2;`
 
-	err := o.DB.Raw(query, startDate, endDate).Scan(&count).Error
+	err := o.DB.WithContext(ctx).Raw(query, startDate, endDate).Scan(&count).Error
 	return count, err
 
 }
 
-func (o *orderDatabase) GetAverageOrderValue(startDate, endDate time.Time) (float32, error) {
+func (o *orderDatabase) GetAverageOrderValue(ctx context.Context, startDate, endDate time.Time) (float32, error) {
 
 	var avg float32
-	query := `SELECT AVG(qty * price) AS average_order_value
+	query := `SELECT AVG(qty * price - discount) AS average_order_value
 	FROM order_lines WHERE created_at >= $1 AND created_at <= $2 ;`
 
-	err := o.DB.Raw(query, startDate, endDate).Scan(&avg).Error
+	err := o.DB.WithContext(ctx).Raw(query, startDate, endDate).Scan(&avg).Error
 	return avg, err
 }
 
-func (o *orderDatabase) GetTotalRevenue(status int, startDate, endDate time.Time) ([]response.OrderLine, error) {
+func (o *orderDatabase) GetTotalRevenue(ctx context.Context, status int, startDate, endDate time.Time) ([]response.OrderLine, error) {
 	var data = make([]response.OrderLine, 0)
 	query := `SELECT * from order_lines WHERE created_at >= $1 AND created_at <= $2 AND order_status_id != $3 ;`
 
-	err := o.DB.Raw(query, startDate, endDate, status).Scan(&data).Error
+	err := o.DB.WithContext(ctx).Raw(query, startDate, endDate, status).Scan(&data).Error
 	return data, err
 }
+
+func (od *orderDatabase) GetUserOrderStats(ctx context.Context, userID int) (response.UserOrderStats, error) {
+	var stats response.UserOrderStats
+	query := `SELECT COUNT(o.id) AS order_count, COALESCE(SUM(o.price * o.qty),0) AS lifetime_spend
+	FROM order_lines o
+	INNER JOIN order_statuses s ON o.order_status_id = s.id
+	WHERE o.user_id = $1 AND s.status NOT IN ('Cancelled','Returned');`
+	err := od.DB.WithContext(ctx).Raw(query, userID).Scan(&stats).Error
+	return stats, err
+}
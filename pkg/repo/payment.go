@@ -1,7 +1,11 @@
 package repo
 
 import (
+	"context"
+	"time"
+
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"gorm.io/gorm"
 )
@@ -16,33 +20,64 @@ func NewPaymentRepository(DB *gorm.DB) interfaces.PaymentRepository {
 	}
 }
 
-func (pd *paymentDatabase) GetPaymentMethods() ([]response.PaymentMethod, error) {
+func (pd *paymentDatabase) GetPaymentMethods(ctx context.Context) ([]response.PaymentMethod, error) {
 	var PaymentMethods = make([]response.PaymentMethod, 0)
 	query := `SELECT * FROM payment_methods;`
-	err := pd.DB.Raw(query).Scan(&PaymentMethods).Error
+	err := pd.DB.WithContext(ctx).Raw(query).Scan(&PaymentMethods).Error
 
 	return PaymentMethods, err
 }
 
-func (pd *paymentDatabase) GetPaymentMethodCodId() (int, error) {
+func (pd *paymentDatabase) GetPaymentMethodCodId(ctx context.Context) (int, error) {
 	var CodId int
 	query := `SELECT id FROM  payment_methods WHERE method_name = cash on delivery ;`
-	err := pd.DB.Raw(query).Scan(&CodId).Error
+	err := pd.DB.WithContext(ctx).Raw(query).Scan(&CodId).Error
 	return CodId, err
 }
 
-func (pd *paymentDatabase) GetPaymentMethodRazorpayId() (int, error) {
+func (pd *paymentDatabase) GetPaymentMethodRazorpayId(ctx context.Context) (int, error) {
 	var RazorpayId int
 	query := `SELECT id FROM  payment_methods WHERE method_name = online payment ;`
-	err := pd.DB.Raw(query).Scan(&RazorpayId).Error
+	err := pd.DB.WithContext(ctx).Raw(query).Scan(&RazorpayId).Error
 	return RazorpayId, err
 
 }
 
-func (pd *paymentDatabase) FindPaymentMethodById(methodID int) (response.PaymentMethod, error) {
+func (pd *paymentDatabase) FindPaymentMethodById(ctx context.Context, methodID int) (response.PaymentMethod, error) {
 	var PaymentMethod response.PaymentMethod
 	query := `SELECT * FROM  payment_methods WHERE id = $1 ;`
-	err := pd.DB.Raw(query, methodID).Scan(&PaymentMethod).Error
+	err := pd.DB.WithContext(ctx).Raw(query, methodID).Scan(&PaymentMethod).Error
 	return PaymentMethod, err
 
 }
+
+func (pd *paymentDatabase) InsertOrderPayment(ctx context.Context, payment request.OrderPayment) (response.OrderPayment, error) {
+	var insertedPayment response.OrderPayment
+	query := `INSERT INTO order_payments (order_line_id,payment_method_id,amount,is_shipping,created_at)VALUES($1,$2,$3,$4,$5) RETURNING * ;`
+	err := pd.DB.WithContext(ctx).Raw(query, payment.OrderLineID, payment.PaymentMethodID, payment.Amount, payment.IsShipping, time.Now()).Scan(&insertedPayment).Error
+	return insertedPayment, err
+}
+
+func (pd *paymentDatabase) GetOrderPayments(ctx context.Context, orderLineID int) ([]response.OrderPayment, error) {
+	var payments = make([]response.OrderPayment, 0)
+	query := `SELECT p.*, m.method_name AS payment_method FROM order_payments p
+	INNER JOIN payment_methods m ON p.payment_method_id = m.id
+	WHERE p.order_line_id = $1 ORDER BY p.id ;`
+	err := pd.DB.WithContext(ctx).Raw(query, orderLineID).Scan(&payments).Error
+	return payments, err
+}
+
+func (pd *paymentDatabase) UpdateOrderPaymentAmount(ctx context.Context, paymentID int, amount float32) (response.OrderPayment, error) {
+	var updatedPayment response.OrderPayment
+	query := `UPDATE order_payments SET amount = $1 WHERE id = $2 RETURNING * ;`
+	err := pd.DB.WithContext(ctx).Raw(query, amount, paymentID).Scan(&updatedPayment).Error
+	return updatedPayment, err
+}
+
+// MarkOrderPaymentRefunded only updates a payment that is not refunded yet.
+func (pd *paymentDatabase) MarkOrderPaymentRefunded(ctx context.Context, paymentID int) (response.OrderPayment, error) {
+	var refundedPayment response.OrderPayment
+	query := `UPDATE order_payments SET is_refunded = true WHERE id = $1 AND is_refunded = false RETURNING * ;`
+	err := pd.DB.WithContext(ctx).Raw(query, paymentID).Scan(&refundedPayment).Error
+	return refundedPayment, err
+}
@@ -1,7 +1,7 @@
 package repo
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/domain"
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
@@ -18,146 +18,164 @@ func NewProductRepository(DB *gorm.DB) interfaces.ProductRepository {
 	return &productDatabase{DB: DB}
 }
 
-func (pd *productDatabase) CreateCategory(category request.Category) (response.Category, error) {
+func (pd *productDatabase) CreateCategory(ctx context.Context, category request.Category) (response.Category, error) {
 	var result response.Category
 	query := `INSERT INTO Categories (Category_Name) VALUES($1) RETURNING *;`
-	err := pd.DB.Raw(query, category.CategoryName).Scan(&result).Error
+	err := pd.DB.WithContext(ctx).Raw(query, category.CategoryName).Scan(&result).Error
 
 	return result, err
 }
 
-func (pd *productDatabase) ReadCategory(startIndex int, endIndex int) ([]response.Category, error) {
+func (pd *productDatabase) ReadCategory(ctx context.Context, startIndex int, endIndex int) ([]response.Category, error) {
 	var ListOfAllCategories = make([]response.Category, 0)
 
 	query := `SELECT * FROM Categories ORDER BY category_name OFFSET $1 FETCH NEXT $2 ROW ONLY ;`
 
-	err := pd.DB.Raw(query, startIndex, endIndex).Scan(&ListOfAllCategories).Error
-	fmt.Println(ListOfAllCategories)
+	err := pd.DB.WithContext(ctx).Raw(query, startIndex, endIndex).Scan(&ListOfAllCategories).Error
 	return ListOfAllCategories, err
 }
 
-func (pd *productDatabase) UpdateCategory(categoryID int, category request.Category) error {
+func (pd *productDatabase) UpdateCategory(ctx context.Context, categoryID int, category request.Category) error {
 	query := `UPDATE Categories SET Category_Name = $1 WHERE ID = $2 ;`
-	return pd.DB.Exec(query, category.CategoryName, categoryID).Error
+	return pd.DB.WithContext(ctx).Exec(query, category.CategoryName, categoryID).Error
 }
 
-func (pd *productDatabase) BlockCategoryByID(categoryID int) error {
+func (pd *productDatabase) BlockCategoryByID(ctx context.Context, categoryID int) error {
 	query := `Update Categories SET Is_blocked = $1 WHERE ID = $2`
-	err := pd.DB.Exec(query, true, categoryID).Error
+	err := pd.DB.WithContext(ctx).Exec(query, true, categoryID).Error
 
 	return err
 }
 
-func (pd *productDatabase) UnBlockCategoryByID(categoryID int) error {
+func (pd *productDatabase) UnBlockCategoryByID(ctx context.Context, categoryID int) error {
 	query := `Update Categories SET Is_blocked = $1 WHERE ID = $2`
-	err := pd.DB.Exec(query, false, categoryID).Error
+	err := pd.DB.WithContext(ctx).Exec(query, false, categoryID).Error
 	return err
 
 }
 
-func (pd *productDatabase) FindCategoryByName(name string) (response.Category, error) {
+func (pd *productDatabase) FindCategoryByName(ctx context.Context, name string) (response.Category, error) {
 	var ResultOfFinding response.Category
 	query := `SELECT * FROM Categories WHERE Category_Name = $1`
-	err := pd.DB.Raw(query, name).Scan(&ResultOfFinding).Error
+	err := pd.DB.WithContext(ctx).Raw(query, name).Scan(&ResultOfFinding).Error
 	return ResultOfFinding, err
 }
 
-func (pd *productDatabase) FindCategoryByID(categoryID int) (response.Category, error) {
+func (pd *productDatabase) FindCategoryByID(ctx context.Context, categoryID int) (response.Category, error) {
 	var ResultOfFinding response.Category
 	query := `SELECT * FROM Categories WHERE Id = $1 `
-	err := pd.DB.Raw(query, categoryID).Scan(&ResultOfFinding).Error
+	err := pd.DB.WithContext(ctx).Raw(query, categoryID).Scan(&ResultOfFinding).Error
 	return ResultOfFinding, err
 }
 
-func (pd *productDatabase) CreateProduct(product request.Product) (response.Product, error) {
+func (pd *productDatabase) CreateProduct(ctx context.Context, product request.Product) (response.Product, error) {
 	var result response.Product
-	query := `INSERT INTO Products (Category_ID,Product_Name,Price,Product_Description, Brand,Sku,is_blocked) Values($1,$2,$3,$4,$5,$6,$7) returning *;`
-	err := pd.DB.Raw(query, product.CategoryID, product.ProductName, product.Price, product.ProductDescription, product.Brand, product.SKU, product.IsBlocked).Scan(&result).Error
+	query := `INSERT INTO Products (Category_ID,Product_Name,Price,Mrp,Product_Description, Brand,Sku,is_blocked,stock,max_per_order) Values($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) returning *;`
+	err := pd.DB.WithContext(ctx).Raw(query, product.CategoryID, product.ProductName, product.Price, product.MRP, product.ProductDescription, product.Brand, product.SKU, product.IsBlocked, product.Stock, product.MaxPerOrder).Scan(&result).Error
 	return result, err
 }
 
-func (pd *productDatabase) ViewAllProductsToAdmin(startIndex, endIndex int) ([]response.Product, error) {
+func (pd *productDatabase) ViewAllProductsToAdmin(ctx context.Context, startIndex, endIndex int) ([]response.Product, error) {
 	ListOfAllProducts := []response.Product{}
 	query := `SELECT * FROM Products OFFSET $1 FETCH NEXT $2 ROW ONLY ;`
-	err := pd.DB.Raw(query, startIndex, endIndex).Scan(&ListOfAllProducts).Error
+	err := pd.DB.WithContext(ctx).Raw(query, startIndex, endIndex).Scan(&ListOfAllProducts).Error
 	return ListOfAllProducts, err
 }
 
-func (pd *productDatabase) UpdateProduct(productID int, updations request.UpdateProduct) error {
-	query := `Update Products SET Category_ID = $1 ,Product_Name = $2 ,Product_Description = $3 , Price = $4  WHERE ID = $5`
-	err := pd.DB.Exec(query, updations.CategoryID, updations.ProductName, updations.ProductDescription, updations.Price, productID).Error
+func (pd *productDatabase) UpdateProduct(ctx context.Context, productID int, updations request.UpdateProduct) error {
+	query := `Update Products SET Category_ID = $1 ,Product_Name = $2 ,Product_Description = $3 , Price = $4 , Mrp = COALESCE(NULLIF($5,0), Mrp) , Stock = $6 , Max_Per_Order = $7 WHERE ID = $8`
+	err := pd.DB.WithContext(ctx).Exec(query, updations.CategoryID, updations.ProductName, updations.ProductDescription, updations.Price, updations.MRP, updations.Stock, updations.MaxPerOrder, productID).Error
 	return err
 }
 
-func (pd *productDatabase) BlockProduct(productID int) error {
+func (pd *productDatabase) UpdateProductPrice(ctx context.Context, productID, price, mrp int) error {
+	query := `UPDATE Products SET Price = $1 , Mrp = $2 WHERE ID = $3;`
+	return pd.DB.WithContext(ctx).Exec(query, price, mrp, productID).Error
+}
+
+// DecrementStock takes qty units from the product stock, nothing is taken when
+// the stock is short and the returned product has no id.
+func (pd *productDatabase) DecrementStock(ctx context.Context, productID, qty int) (response.Product, error) {
+	var product response.Product
+	query := `UPDATE Products SET Stock = Stock - $1 WHERE ID = $2 AND Stock >= $1 RETURNING *;`
+	err := pd.DB.WithContext(ctx).Raw(query, qty, productID).Scan(&product).Error
+	return product, err
+}
+
+func (pd *productDatabase) IncrementStock(ctx context.Context, productID, qty int) error {
+	query := `UPDATE Products SET Stock = Stock + $1 WHERE ID = $2;`
+	return pd.DB.WithContext(ctx).Exec(query, qty, productID).Error
+}
+
+func (pd *productDatabase) BlockProduct(ctx context.Context, productID int) error {
 	status := true
 	query := `UPDATE Products SET Is_Blocked = $1 WHERE ID = $2;`
-	err := pd.DB.Exec(query, status, productID).Error
+	err := pd.DB.WithContext(ctx).Exec(query, status, productID).Error
 	return err
 }
 
-func (pd *productDatabase) UnblockProduct(productID int) error {
+func (pd *productDatabase) UnblockProduct(ctx context.Context, productID int) error {
 	status := false
 	query := `UPDATE Products SET is_blocked = $1 WHERE id = $2 RETURNING *;`
-	err := pd.DB.Exec(query, status, productID).Error
+	err := pd.DB.WithContext(ctx).Exec(query, status, productID).Error
 	return err
 }
 
-func (pd *productDatabase) FindProductByName(productName string) (response.Product, error) {
+func (pd *productDatabase) FindProductByName(ctx context.Context, productName string) (response.Product, error) {
 	var product response.Product
 	query := `SELECT * FROM Products WHERE Product_name = $1  FETCH FIRST 1 ROW ONLY`
-	err := pd.DB.Raw(query, productName).Scan(&product).Error
+	err := pd.DB.WithContext(ctx).Raw(query, productName).Scan(&product).Error
 	return product, err
 }
 
-func (pd *productDatabase) ViewIndividualProduct(userID, productID int) (response.Product, error) {
+func (pd *productDatabase) ViewIndividualProduct(ctx context.Context, userID, productID int) (response.Product, error) {
 	var product response.Product
 	query := `SELECT p.*, EXISTS (SELECT 1 FROM wishlists WHERE user_id = $1 AND product_id = $2) AS is_wishlisted
 	FROM products p WHERE P.id = $2 FETCH FIRST 1 ROW ONLY`
-	err := pd.DB.Raw(query, userID, productID).Scan(&product).Error
+	err := pd.DB.WithContext(ctx).Raw(query, userID, productID).Scan(&product).Error
 	return product, err
 }
 
-func (pd *productDatabase) ViewAllProductsToUser(userID, startIndex, endIndex int) ([]response.Product, error) {
+func (pd *productDatabase) ViewAllProductsToUser(ctx context.Context, userID, startIndex, endIndex int) ([]response.Product, error) {
 	ListOfAllProducts := []response.Product{}
 	query := `SELECT p.*, EXISTS (SELECT 1 FROM wishlists WHERE user_id = $1 AND product_id = p.id) AS is_wishlisted
 	FROM products p OFFSET $2 FETCH NEXT $3 ROW ONLY`
-	err := pd.DB.Raw(query, userID, startIndex, endIndex).Scan(&ListOfAllProducts).Error
+	err := pd.DB.WithContext(ctx).Raw(query, userID, startIndex, endIndex).Scan(&ListOfAllProducts).Error
 	return ListOfAllProducts, err
 }
 
-func (pd *productDatabase) FindProductByID(productID int) (response.Product, error) {
+func (pd *productDatabase) FindProductByID(ctx context.Context, productID int) (response.Product, error) {
 	var Product response.Product
 	query := "SELECT * FROM Products WHERE Id = $1  FETCH FIRST 1 ROW ONLY"
-	err := pd.DB.Raw(query, productID).Scan(&Product).Error
+	err := pd.DB.WithContext(ctx).Raw(query, productID).Scan(&Product).Error
 	return Product, err
 }
 
-func (pd *productDatabase) FindUserRatingOnProduct(userID, productID int) (response.Rating, error) {
+func (pd *productDatabase) FindUserRatingOnProduct(ctx context.Context, userID, productID int) (response.Rating, error) {
 	var Rating response.Rating
 
 	query := `SELECT r.id,r.rating,r.description,u.user_name FROM ratings r 
 	INNER JOIN users u ON r.user_id = u.id WHERE r.user_id = $1 AND r.product_id = $2;`
-	err := pd.DB.Raw(query, userID, productID).Scan(&Rating).Error
+	err := pd.DB.WithContext(ctx).Raw(query, userID, productID).Scan(&Rating).Error
 	return Rating, err
 }
 
-func (pd *productDatabase) GetProductReviews(productID int) ([]response.Rating, error) {
+func (pd *productDatabase) GetProductReviews(ctx context.Context, productID int) ([]response.Rating, error) {
 	var Ratings = make([]response.Rating, 0)
 
 	query := `SELECT r.id,r.rating,r.description,u.user_name FROM ratings r INNER JOIN users u ON u.id = r.user_id WHERE r.product_id =$1;`
-	err := pd.DB.Raw(query, productID).Scan(&Ratings).Error
+	err := pd.DB.WithContext(ctx).Raw(query, productID).Scan(&Ratings).Error
 	return Ratings, err
 }
 
-func (pd *productDatabase) InsertProductRating(rating request.Rating) error {
+func (pd *productDatabase) InsertProductRating(ctx context.Context, rating request.Rating) error {
 
-	err := pd.DB.Create(&rating).Error
+	err := pd.DB.WithContext(ctx).Create(&rating).Error
 	return err
 
 }
 
-func (pd *productDatabase) SearchProducts(search string, startIndex, endIndex int) ([]response.Product, error) {
+func (pd *productDatabase) SearchProducts(ctx context.Context, search string, startIndex, endIndex int) ([]response.Product, error) {
 	var Products = make([]response.Product, 0)
 	query := `SELECT *
 	FROM products
@@ -165,66 +183,73 @@ func (pd *productDatabase) SearchProducts(search string, startIndex, endIndex in
 		  brand ILIKE $1 OFFSET  $2 FETCH NEXT $3 ROW ONLY ;`
 	search = search + "%"
 
-	err := pd.DB.Raw(query, search, startIndex, endIndex).Scan(&Products).Error
+	err := pd.DB.WithContext(ctx).Raw(query, search, startIndex, endIndex).Scan(&Products).Error
 	return Products, err
 }
 
-func (pd *productDatabase) GetProductsByCategoryAdmin(categoryID, startIndex, endIndex int) ([]response.Product, error) {
+func (pd *productDatabase) GetProductsByCategoryAdmin(ctx context.Context, categoryID, startIndex, endIndex int) ([]response.Product, error) {
 	var Products = make([]response.Product, 0)
 
 	query := `SELECT *
 	FROM products
 	WHERE category_id = $1 OFFSET  $2 FETCH NEXT $3 ROW ONLY ;`
 
-	err := pd.DB.Raw(query, categoryID, startIndex, endIndex).Scan(&Products).Error
+	err := pd.DB.WithContext(ctx).Raw(query, categoryID, startIndex, endIndex).Scan(&Products).Error
 	return Products, err
 
 }
 
-func (pd *productDatabase) GetProductsByCategoryUser(userID, categoryID, startIndex, endIndex int) ([]response.Product, error) {
+func (pd *productDatabase) GetProductsByCategoryUser(ctx context.Context, userID, categoryID, startIndex, endIndex int) ([]response.Product, error) {
 	var Products = make([]response.Product, 0)
 
 	query := `SELECT p.*, EXISTS (SELECT 1 FROM wishlists WHERE user_id = $1 AND product_id = p.id) AS is_wishlisted
 	FROM products p where category_id = $2 OFFSET $3 FETCH NEXT $4 ROW ONLY`
 
-	err := pd.DB.Raw(query, userID, categoryID, startIndex, endIndex).Scan(&Products).Error
+	err := pd.DB.WithContext(ctx).Raw(query, userID, categoryID, startIndex, endIndex).Scan(&Products).Error
 	return Products, err
 
 }
 
-func (pd *productDatabase) InsertCategoryIMG(urls interface{}, categoryID int) error {
+func (pd *productDatabase) InsertCategoryIMG(ctx context.Context, urls interface{}, categoryID int) error {
 
 	images := domain.NewJsonB()
 	images["urls"] = urls
 	query := `update categories set images = $1 where id = $2;`
-	return pd.DB.Exec(query, images, categoryID).Error
+	return pd.DB.WithContext(ctx).Exec(query, images, categoryID).Error
 }
 
-func (pd *productDatabase) InsertProductIMG(urls interface{}, productID int) error {
+func (pd *productDatabase) InsertProductIMG(ctx context.Context, urls interface{}, productID int) error {
 
 	images := domain.NewJsonB()
 	images["urls"] = urls
 	query := `update products set images = $1 where id = $2;`
-	return pd.DB.Exec(query, images, productID).Error
+	return pd.DB.WithContext(ctx).Exec(query, images, productID).Error
 }
 
 // wishlist
-func (pd *productDatabase) AddToWishList(userID, productID int) error {
+func (pd *productDatabase) AddToWishList(ctx context.Context, userID, productID int) error {
 
 	query := `insert into wishlists (user_id, product_id) values($1, $2)`
-	return pd.DB.Exec(query, userID, productID).Error
+	return pd.DB.WithContext(ctx).Exec(query, userID, productID).Error
 }
 
-func (pd *productDatabase) RemoveFromWishList(userID, productID int) error {
+func (pd *productDatabase) RemoveFromWishList(ctx context.Context, userID, productID int) error {
 
 	query := `delete from wishlists where product_id = $1 and user_id = $2;`
-	return pd.DB.Exec(query, productID, userID).Error
+	return pd.DB.WithContext(ctx).Exec(query, productID, userID).Error
+}
+
+func (pd *productDatabase) IsWishListed(ctx context.Context, userID, productID int) (bool, error) {
+	var exists bool
+	query := `select exists (select 1 from wishlists where user_id = $1 and product_id = $2);`
+	err := pd.DB.WithContext(ctx).Raw(query, userID, productID).Scan(&exists).Error
+	return exists, err
 }
 
-func (pd *productDatabase) ShowWishListProducts(userID, page, count int) ([]response.Product, error) {
+func (pd *productDatabase) ShowWishListProducts(ctx context.Context, userID, page, count int) ([]response.Product, error) {
 
 	products := []response.Product{}
 	query := `select p.* from products p inner join wishlists w on w.product_id = p.id where w.user_id = $1 offset $2 fetch next $3 row only `
-	err := pd.DB.Raw(query, userID, page, count).Scan(&products).Error
+	err := pd.DB.WithContext(ctx).Raw(query, userID, page, count).Scan(&products).Error
 	return products, err
 }
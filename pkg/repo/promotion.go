@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type promotionDatabase struct {
+	DB *gorm.DB
+}
+
+func NewPromotionRepository(DB *gorm.DB) interfaces.PromotionRepository {
+	return &promotionDatabase{
+		DB: DB,
+	}
+}
+
+func (pd *promotionDatabase) CreatePromotion(ctx context.Context, promotion request.Promotion) (response.Promotion, error) {
+	var InsertedPromotion response.Promotion
+	query := `INSERT INTO promotions (name,type,category_id,product_id,discount_percent,starts_at,ends_at)VALUES($1,$2,$3,$4,$5,$6,$7) RETURNING *;`
+	err := pd.DB.WithContext(ctx).Raw(query, promotion.Name, promotion.Type, promotion.CategoryID, promotion.ProductID, promotion.DiscountPercent, promotion.StartsAt, promotion.EndsAt).Scan(&InsertedPromotion).Error
+	return InsertedPromotion, err
+}
+
+func (pd *promotionDatabase) UpdatePromotion(ctx context.Context, promotionID int, promotion request.Promotion) (response.Promotion, error) {
+	var UpdatedPromotion response.Promotion
+	query := `UPDATE promotions SET name = $1 ,type = $2 ,category_id = $3 ,product_id = $4 ,discount_percent = $5 ,starts_at = $6 ,ends_at = $7 WHERE id = $8 RETURNING *;`
+	err := pd.DB.WithContext(ctx).Raw(query, promotion.Name, promotion.Type, promotion.CategoryID, promotion.ProductID, promotion.DiscountPercent, promotion.StartsAt, promotion.EndsAt, promotionID).Scan(&UpdatedPromotion).Error
+	return UpdatedPromotion, err
+}
+
+func (pd *promotionDatabase) GetAllPromotions(ctx context.Context) ([]response.Promotion, error) {
+	var Promotions = make([]response.Promotion, 0)
+	query := `SELECT * FROM promotions ORDER BY id DESC;`
+	err := pd.DB.WithContext(ctx).Raw(query).Scan(&Promotions).Error
+	return Promotions, err
+}
+
+func (pd *promotionDatabase) ChangePromotionBlockStatus(ctx context.Context, promotionID int, isBlocked bool) (response.Promotion, error) {
+	var Promotion response.Promotion
+	query := `UPDATE promotions SET is_blocked = $1 ,is_active = is_active AND NOT $1 WHERE id = $2 RETURNING *;`
+	err := pd.DB.WithContext(ctx).Raw(query, isBlocked, promotionID).Scan(&Promotion).Error
+	return Promotion, err
+}
+
+func (pd *promotionDatabase) GetActivePromotions(ctx context.Context, currentTime time.Time) ([]response.Promotion, error) {
+	var Promotions = make([]response.Promotion, 0)
+	query := `SELECT * FROM promotions WHERE is_active = true AND is_blocked = false AND starts_at <= $1 AND ends_at > $1;`
+	err := pd.DB.WithContext(ctx).Raw(query, currentTime).Scan(&Promotions).Error
+	return Promotions, err
+}
+
+func (pd *promotionDatabase) RefreshPromotionStatus(ctx context.Context, currentTime time.Time) error {
+	query := `UPDATE promotions SET is_active = (is_blocked = false AND starts_at <= $1 AND ends_at > $1)
+	WHERE is_active != (is_blocked = false AND starts_at <= $1 AND ends_at > $1);`
+	return pd.DB.WithContext(ctx).Exec(query, currentTime).Error
+}
+
+func (pd *promotionDatabase) InsertScheduledPrice(ctx context.Context, schedule request.ScheduledPrice) (response.ScheduledPrice, error) {
+	var ScheduledPrice response.ScheduledPrice
+	query := `INSERT INTO scheduled_prices (product_id,price,mrp,starts_at,ends_at)VALUES($1,$2,$3,$4,$5) RETURNING *;`
+	err := pd.DB.WithContext(ctx).Raw(query, schedule.ProductID, schedule.Price, schedule.MRP, schedule.StartsAt, schedule.EndsAt).Scan(&ScheduledPrice).Error
+	return ScheduledPrice, err
+}
+
+func (pd *promotionDatabase) GetScheduledPrices(ctx context.Context) ([]response.ScheduledPrice, error) {
+	var ScheduledPrices = make([]response.ScheduledPrice, 0)
+	query := `SELECT * FROM scheduled_prices ORDER BY starts_at DESC;`
+	err := pd.DB.WithContext(ctx).Raw(query).Scan(&ScheduledPrices).Error
+	return ScheduledPrices, err
+}
+
+func (pd *promotionDatabase) CancelScheduledPrice(ctx context.Context, scheduleID int) (response.ScheduledPrice, error) {
+	var ScheduledPrice response.ScheduledPrice
+	query := `UPDATE scheduled_prices SET status = 'cancelled' WHERE id = $1 AND status = 'pending' RETURNING *;`
+	err := pd.DB.WithContext(ctx).Raw(query, scheduleID).Scan(&ScheduledPrice).Error
+	return ScheduledPrice, err
+}
+
+func (pd *promotionDatabase) GetDueScheduledPrices(ctx context.Context, currentTime time.Time) ([]response.ScheduledPrice, error) {
+	var ScheduledPrices = make([]response.ScheduledPrice, 0)
+	query := `SELECT * FROM scheduled_prices WHERE status = 'pending' AND starts_at <= $1 ORDER BY starts_at;`
+	err := pd.DB.WithContext(ctx).Raw(query, currentTime).Scan(&ScheduledPrices).Error
+	return ScheduledPrices, err
+}
+
+func (pd *promotionDatabase) GetEndedScheduledPrices(ctx context.Context, currentTime time.Time) ([]response.ScheduledPrice, error) {
+	var ScheduledPrices = make([]response.ScheduledPrice, 0)
+	query := `SELECT * FROM scheduled_prices WHERE status = 'active' AND ends_at IS NOT NULL AND ends_at <= $1 ORDER BY ends_at;`
+	err := pd.DB.WithContext(ctx).Raw(query, currentTime).Scan(&ScheduledPrices).Error
+	return ScheduledPrices, err
+}
+
+// ActivateScheduledPrice marks the schedule active and stores the base price it
+// restores when it ends, the base of a temporary schedule already running on the
+// product or else the price of the locked product row.
+func (pd *promotionDatabase) ActivateScheduledPrice(ctx context.Context, scheduleID int) (response.ScheduledPrice, error) {
+	var ScheduledPrice response.ScheduledPrice
+	query := `WITH product AS (
+		SELECT p.id, p.price, p.mrp FROM products p INNER JOIN scheduled_prices s ON s.product_id = p.id WHERE s.id = $1 FOR UPDATE OF p
+	), base AS (
+		SELECT a.previous_price, a.previous_mrp FROM scheduled_prices a, product
+		WHERE a.product_id = product.id AND a.status = 'active' AND a.ends_at IS NOT NULL ORDER BY a.starts_at LIMIT 1
+	)
+	UPDATE scheduled_prices SET status = 'active' ,
+		previous_price = COALESCE((SELECT previous_price FROM base), (SELECT price FROM product)) ,
+		previous_mrp = COALESCE((SELECT previous_mrp FROM base), (SELECT mrp FROM product))
+	WHERE id = $1 AND status = 'pending' RETURNING *;`
+	err := pd.DB.WithContext(ctx).Raw(query, scheduleID).Scan(&ScheduledPrice).Error
+	return ScheduledPrice, err
+}
+
+// FindActiveScheduledPrice returns the temporary schedule of the product that
+// started last and is still running.
+func (pd *promotionDatabase) FindActiveScheduledPrice(ctx context.Context, productID int) (response.ScheduledPrice, error) {
+	var ScheduledPrice response.ScheduledPrice
+	query := `SELECT * FROM scheduled_prices WHERE product_id = $1 AND status = 'active' AND ends_at IS NOT NULL ORDER BY starts_at DESC LIMIT 1;`
+	err := pd.DB.WithContext(ctx).Raw(query, productID).Scan(&ScheduledPrice).Error
+	return ScheduledPrice, err
+}
+
+// UpdateActiveBasePrice changes the base price the running temporary schedules
+// of the product restore.
+func (pd *promotionDatabase) UpdateActiveBasePrice(ctx context.Context, productID, price, mrp int) error {
+	query := `UPDATE scheduled_prices SET previous_price = $1 ,previous_mrp = $2 WHERE product_id = $3 AND status = 'active' AND ends_at IS NOT NULL;`
+	return pd.DB.WithContext(ctx).Exec(query, price, mrp, productID).Error
+}
+
+func (pd *promotionDatabase) CompleteScheduledPrice(ctx context.Context, scheduleID int) (response.ScheduledPrice, error) {
+	var ScheduledPrice response.ScheduledPrice
+	query := `UPDATE scheduled_prices SET status = 'completed' WHERE id = $1 RETURNING *;`
+	err := pd.DB.WithContext(ctx).Raw(query, scheduleID).Scan(&ScheduledPrice).Error
+	return ScheduledPrice, err
+}
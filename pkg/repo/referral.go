@@ -1,7 +1,11 @@
 package repo
 
 import (
+	"context"
+	"time"
+
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"gorm.io/gorm"
 )
@@ -16,23 +20,95 @@ func NewReferralRepository(DB *gorm.DB) interfaces.ReferralRepository {
 	}
 }
 
-func (rd *referralDatabase) InsertNewReferralCode(userID int, referralCode string) (response.Referral, error) {
+func (rd *referralDatabase) InsertNewReferralCode(ctx context.Context, userID int, referralCode string) (response.Referral, error) {
 	var insertedDetails response.Referral
 	query := `INSERT INTO referrals (user_id,code)VALUES($1,$2) RETURNING * ;`
-	err := rd.DB.Raw(query, userID, referralCode).Scan(&insertedDetails).Error
+	err := rd.DB.WithContext(ctx).Raw(query, userID, referralCode).Scan(&insertedDetails).Error
 	return insertedDetails, err
 }
 
-func (rd *referralDatabase) FindReferralCodeByCode(referralCode string) (response.Referral, error) {
+func (rd *referralDatabase) FindReferralCodeByCode(ctx context.Context, referralCode string) (response.Referral, error) {
 	var referralDetails response.Referral
 	query := `SELECT * FROM referrals WHERE code = $1 ;`
-	err := rd.DB.Raw(query, referralCode).Scan(&referralDetails).Error
+	err := rd.DB.WithContext(ctx).Raw(query, referralCode).Scan(&referralDetails).Error
 	return referralDetails, err
 }
 
-func (rd *referralDatabase) FindReferralCodeByUserID(userID int) (response.Referral, error) {
+func (rd *referralDatabase) FindReferralCodeByUserID(ctx context.Context, userID int) (response.Referral, error) {
 	var referralDetails response.Referral
 	query := `SELECT * FROM referrals WHERE user_id = $1 ;`
-	err := rd.DB.Raw(query, userID).Scan(&referralDetails).Error
+	err := rd.DB.WithContext(ctx).Raw(query, userID).Scan(&referralDetails).Error
+	return referralDetails, err
+}
+
+func (rd *referralDatabase) UpdateReferralDevice(ctx context.Context, referralID int, deviceID string) (response.Referral, error) {
+	var referralDetails response.Referral
+	query := `UPDATE referrals SET device_id = $1 WHERE id = $2 RETURNING * ;`
+	err := rd.DB.WithContext(ctx).Raw(query, deviceID, referralID).Scan(&referralDetails).Error
 	return referralDetails, err
 }
+
+func (rd *referralDatabase) GetReferralProgramme(ctx context.Context) (response.ReferralProgramme, error) {
+	var programme response.ReferralProgramme
+	query := `SELECT * FROM referral_programmes ORDER BY id DESC LIMIT 1 ;`
+	err := rd.DB.WithContext(ctx).Raw(query).Scan(&programme).Error
+	return programme, err
+}
+
+func (rd *referralDatabase) InsertReferralProgramme(ctx context.Context, programme request.ReferralProgramme) (response.ReferralProgramme, error) {
+	var insertedProgramme response.ReferralProgramme
+	query := `INSERT INTO referral_programmes (referrer_reward,referee_reward,is_active,created_at)VALUES($1,$2,$3,$4) RETURNING * ;`
+	err := rd.DB.WithContext(ctx).Raw(query, programme.ReferrerReward, programme.RefereeReward, programme.IsActive, time.Now()).Scan(&insertedProgramme).Error
+	return insertedProgramme, err
+}
+
+func (rd *referralDatabase) InsertReferralClaim(ctx context.Context, claim request.ReferralClaim) (response.ReferralClaim, error) {
+	var insertedClaim response.ReferralClaim
+	query := `INSERT INTO referral_claims (referral_id,referrer_id,referee_id,referrer_reward,referee_reward,status,device_id,claimed_at)
+	VALUES($1,$2,$3,$4,$5,'pending',$6,$7) RETURNING * ;`
+	err := rd.DB.WithContext(ctx).Raw(query, claim.ReferralID, claim.ReferrerID, claim.RefereeID, claim.ReferrerReward, claim.RefereeReward, claim.DeviceID, time.Now()).Scan(&insertedClaim).Error
+	return insertedClaim, err
+}
+
+func (rd *referralDatabase) FindReferralClaimByRefereeID(ctx context.Context, refereeID int) (response.ReferralClaim, error) {
+	var claim response.ReferralClaim
+	query := `SELECT * FROM referral_claims WHERE referee_id = $1 ;`
+	err := rd.DB.WithContext(ctx).Raw(query, refereeID).Scan(&claim).Error
+	return claim, err
+}
+
+func (rd *referralDatabase) CountReferralClaimsByDevice(ctx context.Context, deviceID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM referral_claims WHERE device_id = $1 ;`
+	err := rd.DB.WithContext(ctx).Raw(query, deviceID).Scan(&count).Error
+	return count, err
+}
+
+// ReleaseReferralClaim only moves a pending claim, so a claim is never released twice.
+func (rd *referralDatabase) ReleaseReferralClaim(ctx context.Context, claimID int, releasedAt time.Time) (response.ReferralClaim, error) {
+	var releasedClaim response.ReferralClaim
+	query := `UPDATE referral_claims SET status = 'released', released_at = $1 WHERE id = $2 AND status = 'pending' RETURNING * ;`
+	err := rd.DB.WithContext(ctx).Raw(query, releasedAt, claimID).Scan(&releasedClaim).Error
+	return releasedClaim, err
+}
+
+func (rd *referralDatabase) GetReferralStats(ctx context.Context, referrerID int) (response.ReferralStats, error) {
+	var stats response.ReferralStats
+	query := `SELECT COUNT(*) AS total_referrals,
+	COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+	COUNT(*) FILTER (WHERE status = 'released') AS released,
+	COALESCE(SUM(referrer_reward) FILTER (WHERE status = 'released'),0) AS total_earned,
+	COALESCE(SUM(referrer_reward) FILTER (WHERE status = 'pending'),0) AS pending_earnings
+	FROM referral_claims WHERE referrer_id = $1 ;`
+	err := rd.DB.WithContext(ctx).Raw(query, referrerID).Scan(&stats).Error
+	return stats, err
+}
+
+// GetReferralClaimsByUserID lists the claims the user made as the referee and
+// the claims of the user's code, the latest first.
+func (rd *referralDatabase) GetReferralClaimsByUserID(ctx context.Context, userID int) ([]response.ReferralClaim, error) {
+	var claims = make([]response.ReferralClaim, 0)
+	query := `SELECT * FROM referral_claims WHERE referrer_id = $1 OR referee_id = $1 ORDER BY claimed_at DESC ;`
+	err := rd.DB.WithContext(ctx).Raw(query, userID).Scan(&claims).Error
+	return claims, err
+}
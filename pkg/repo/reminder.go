@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type reminderDatabase struct {
+	DB *gorm.DB
+}
+
+func NewReminderRepository(DB *gorm.DB) interfaces.ReminderRepository {
+	return &reminderDatabase{
+		DB: DB,
+	}
+}
+
+// GetIdleCarts returns the carts of the users with no cart activity since idleSince.
+func (rd *reminderDatabase) GetIdleCarts(ctx context.Context, idleSince time.Time) ([]response.IdleCart, error) {
+	var carts = make([]response.IdleCart, 0)
+	query := `SELECT c.user_id, SUM(c.qty * p.price) AS cart_value, SUM(c.qty) AS item_count, MAX(c.updated_at) AS last_activity
+	FROM carts c INNER JOIN products p ON p.id = c.product_id
+	GROUP BY c.user_id HAVING MAX(c.updated_at) < $1 ORDER BY c.user_id;`
+	err := rd.DB.WithContext(ctx).Raw(query, idleSince).Scan(&carts).Error
+	return carts, err
+}
+
+func (rd *reminderDatabase) FindOpenAbandonedCart(ctx context.Context, userID int) (response.AbandonedCart, error) {
+	var cart response.AbandonedCart
+	query := `SELECT * FROM abandoned_carts WHERE user_id = $1 AND status = 'open' ORDER BY id DESC LIMIT 1;`
+	err := rd.DB.WithContext(ctx).Raw(query, userID).Scan(&cart).Error
+	return cart, err
+}
+
+func (rd *reminderDatabase) InsertAbandonedCart(ctx context.Context, cart response.IdleCart, now time.Time) (response.AbandonedCart, error) {
+	var insertedCart response.AbandonedCart
+	query := `INSERT INTO abandoned_carts (user_id,cart_value,item_count,last_activity,created_at)VALUES($1,$2,$3,$4,$5) RETURNING *;`
+	err := rd.DB.WithContext(ctx).Raw(query, cart.UserID, cart.CartValue, cart.ItemCount, cart.LastActivity, now).Scan(&insertedCart).Error
+	return insertedCart, err
+}
+
+// UpdateAbandonedCart refreshes the cart value, the reminders start over when
+// the user came back to the cart since the last reminder.
+func (rd *reminderDatabase) UpdateAbandonedCart(ctx context.Context, abandonedCartID int, cart response.IdleCart) (response.AbandonedCart, error) {
+	var updatedCart response.AbandonedCart
+	query := `UPDATE abandoned_carts SET cart_value = $1 ,item_count = $2 ,
+	reminders_sent = CASE WHEN last_activity < $3 THEN 0 ELSE reminders_sent END ,last_activity = $3
+	WHERE id = $4 RETURNING *;`
+	err := rd.DB.WithContext(ctx).Raw(query, cart.CartValue, cart.ItemCount, cart.LastActivity, abandonedCartID).Scan(&updatedCart).Error
+	return updatedCart, err
+}
+
+func (rd *reminderDatabase) UpdateRemindersSent(ctx context.Context, abandonedCartID, remindersSent int) error {
+	query := `UPDATE abandoned_carts SET reminders_sent = $1 WHERE id = $2;`
+	return rd.DB.WithContext(ctx).Exec(query, remindersSent, abandonedCartID).Error
+}
+
+func (rd *reminderDatabase) InsertCartReminder(ctx context.Context, reminder response.CartReminder) (response.CartReminder, error) {
+	var insertedReminder response.CartReminder
+	query := `INSERT INTO cart_reminders (abandoned_cart_id,step,channel,status,error,sent_at)VALUES($1,$2,$3,$4,$5,$6) RETURNING *;`
+	err := rd.DB.WithContext(ctx).Raw(query, reminder.AbandonedCartID, reminder.Step, reminder.Channel, reminder.Status, reminder.Error, reminder.SentAt).Scan(&insertedReminder).Error
+	return insertedReminder, err
+}
+
+// CloseEmptyAbandonedCarts marks the open carts the users emptied without ordering as lost.
+func (rd *reminderDatabase) CloseEmptyAbandonedCarts(ctx context.Context, now time.Time) error {
+	query := `UPDATE abandoned_carts a SET status = 'lost' ,closed_at = $1
+	WHERE a.status = 'open' AND NOT EXISTS (SELECT 1 FROM carts c WHERE c.user_id = a.user_id);`
+	return rd.DB.WithContext(ctx).Exec(query, now).Error
+}
+
+func (rd *reminderDatabase) MarkCartRecovered(ctx context.Context, userID int, value float32, now time.Time) error {
+	query := `UPDATE abandoned_carts SET status = 'recovered' ,recovered_value = $1 ,closed_at = $2 WHERE user_id = $3 AND status = 'open';`
+	return rd.DB.WithContext(ctx).Exec(query, value, now, userID).Error
+}
+
+func (rd *reminderDatabase) IsUnsubscribed(ctx context.Context, userID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM reminder_unsubscribes WHERE user_id = $1);`
+	err := rd.DB.WithContext(ctx).Raw(query, userID).Scan(&exists).Error
+	return exists, err
+}
+
+func (rd *reminderDatabase) Unsubscribe(ctx context.Context, userID int, now time.Time) error {
+	query := `INSERT INTO reminder_unsubscribes (user_id,created_at)VALUES($1,$2) ON CONFLICT (user_id) DO NOTHING;`
+	return rd.DB.WithContext(ctx).Exec(query, userID, now).Error
+}
+
+// GetAbandonedCartReport sums up the carts abandoned between from and to.
+func (rd *reminderDatabase) GetAbandonedCartReport(ctx context.Context, from, to time.Time) (response.AbandonedCartReport, error) {
+	var report response.AbandonedCartReport
+	query := `SELECT COUNT(*) AS abandoned_carts, COALESCE(SUM(cart_value),0) AS abandoned_value,
+	COUNT(*) FILTER (WHERE status = 'recovered') AS recovered_carts,
+	COALESCE(SUM(recovered_value) FILTER (WHERE status = 'recovered'),0) AS recovered_value,
+	COUNT(*) FILTER (WHERE status = 'lost') AS lost_carts,
+	COUNT(*) FILTER (WHERE status = 'open') AS open_carts,
+	(SELECT COUNT(*) FROM cart_reminders r INNER JOIN abandoned_carts ac ON ac.id = r.abandoned_cart_id
+	WHERE r.status = 'sent' AND ac.created_at >= $1 AND ac.created_at < $2) AS reminders_sent
+	FROM abandoned_carts WHERE created_at >= $1 AND created_at < $2;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to).Scan(&report).Error
+	return report, err
+}
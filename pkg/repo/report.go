@@ -0,0 +1,232 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+// salesLines are the order lines placed from $1 until $2 with their gross and
+// net amounts, $3 is the rupees a redeemed point is worth. Lost lines are the
+// cancelled and returned ones.
+const salesLines = `WITH lines AS (
+	SELECT o.id, o.user_id, o.product_id, o.coupon_id, o.addresses_id, o.payment_method_id,
+	o.qty, o.price, o.points_redeemed, o.created_at, o.updated_at, s.status,
+	s.status IN ('Cancelled','Returned') AS lost,
+	o.discount::numeric AS discount,
+	(o.points_redeemed * $3::numeric) AS points,
+	(o.price::numeric * o.qty) AS gross,
+	(o.price::numeric * o.qty - o.discount::numeric - o.points_redeemed * $3::numeric) AS net
+	FROM order_lines o
+	INNER JOIN order_statuses s ON o.order_status_id = s.id
+	WHERE o.created_at >= $1 AND o.created_at < $2
+)
+`
+
+// salesDimensions are the key and name columns of the top sales rankings.
+var salesDimensions = map[string][2]string{
+	"product":  {"p.id::text", "p.product_name"},
+	"category": {"c.id::text", "c.category_name"},
+	"brand":    {"p.brand", "p.brand"},
+}
+
+type reportDatabase struct {
+	DB *gorm.DB
+}
+
+func NewReportRepository(DB *gorm.DB) interfaces.ReportRepository {
+	return &reportDatabase{
+		DB: DB,
+	}
+}
+
+func (rd *reportDatabase) GetSalesSummary(ctx context.Context, from, to time.Time, pointValue float32) (response.SalesSummary, error) {
+	var summary response.SalesSummary
+	query := salesLines + `SELECT COUNT(*) AS placed_orders,
+	ROUND(COALESCE(SUM(gross),0),2) AS gross_sales,
+	COUNT(*) FILTER (WHERE NOT lost) AS orders,
+	COALESCE(SUM(qty) FILTER (WHERE NOT lost),0) AS units,
+	ROUND(COALESCE(SUM(discount) FILTER (WHERE NOT lost),0),2) AS discounts,
+	ROUND(COALESCE(SUM(points) FILTER (WHERE NOT lost),0),2) AS points_redeemed,
+	ROUND(COALESCE(SUM(net) FILTER (WHERE NOT lost),0),2) AS net_revenue,
+	COUNT(*) FILTER (WHERE status = 'Cancelled') AS cancelled_orders,
+	ROUND(COALESCE(SUM(net) FILTER (WHERE status = 'Cancelled'),0),2) AS cancelled_value,
+	COUNT(*) FILTER (WHERE status = 'Returned') AS returned_orders,
+	ROUND(COALESCE(SUM(net) FILTER (WHERE status = 'Returned'),0),2) AS returned_value,
+	COUNT(DISTINCT user_id) FILTER (WHERE NOT lost) AS customers
+	FROM lines;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, pointValue).Scan(&summary).Error
+	return summary, err
+}
+
+// GetSalesSeries sums up the sales per day, week or month, the periods without
+// orders are included with zeros.
+func (rd *reportDatabase) GetSalesSeries(ctx context.Context, from, to time.Time, pointValue float32, granularity string) ([]response.SalesPeriod, error) {
+	var series = make([]response.SalesPeriod, 0)
+	query := salesLines + `, periods AS (
+		SELECT generate_series(date_trunc($4, $1::timestamptz), $2::timestamptz - interval '1 microsecond', ('1 ' || $4)::interval) AS period
+	)
+	SELECT p.period,
+	COUNT(l.id) FILTER (WHERE NOT l.lost) AS orders,
+	COALESCE(SUM(l.qty) FILTER (WHERE NOT l.lost),0) AS units,
+	ROUND(COALESCE(SUM(l.gross),0),2) AS gross_sales,
+	ROUND(COALESCE(SUM(l.net) FILTER (WHERE NOT l.lost),0),2) AS net_revenue,
+	COUNT(l.id) FILTER (WHERE l.status = 'Cancelled') AS cancelled_orders,
+	COUNT(l.id) FILTER (WHERE l.status = 'Returned') AS returned_orders
+	FROM periods p
+	LEFT JOIN lines l ON date_trunc($4, l.created_at) = p.period
+	GROUP BY p.period
+	ORDER BY p.period;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, pointValue, granularity).Scan(&series).Error
+	return series, err
+}
+
+// GetTopSales ranks the products, categories or brands by the net revenue.
+func (rd *reportDatabase) GetTopSales(ctx context.Context, dimension string, from, to time.Time, pointValue float32, limit int) ([]response.SalesRank, error) {
+	columns, ok := salesDimensions[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unknown sales dimension %q", dimension)
+	}
+
+	var ranks = make([]response.SalesRank, 0)
+	query := salesLines + fmt.Sprintf(`SELECT %s AS key, %s AS name,
+	COUNT(*) AS orders, SUM(l.qty) AS units, ROUND(SUM(l.net),2) AS net_revenue
+	FROM lines l
+	INNER JOIN products p ON l.product_id = p.id
+	INNER JOIN categories c ON p.category_id = c.id
+	WHERE NOT l.lost
+	GROUP BY 1, 2
+	ORDER BY net_revenue DESC, units DESC
+	LIMIT $4;`, columns[0], columns[1])
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, pointValue, limit).Scan(&ranks).Error
+	return ranks, err
+}
+
+// GetPaymentMix sums up the payments of the orders per payment method, an
+// order paid partly from the wallet counts in both methods.
+func (rd *reportDatabase) GetPaymentMix(ctx context.Context, from, to time.Time, pointValue float32) ([]response.PaymentMix, error) {
+	var mix = make([]response.PaymentMix, 0)
+	query := salesLines + `SELECT m.method_name AS payment_method,
+	COUNT(DISTINCT l.id) AS orders, ROUND(SUM(op.amount::numeric),2) AS amount
+	FROM lines l
+	INNER JOIN order_payments op ON op.order_line_id = l.id
+	INNER JOIN payment_methods m ON op.payment_method_id = m.id
+	WHERE NOT l.lost AND NOT op.is_refunded
+	GROUP BY m.method_name
+	ORDER BY amount DESC;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, pointValue).Scan(&mix).Error
+	return mix, err
+}
+
+func (rd *reportDatabase) GetCouponPerformance(ctx context.Context, from, to time.Time, pointValue float32) ([]response.CouponPerformance, error) {
+	var coupons = make([]response.CouponPerformance, 0)
+	query := salesLines + `SELECT c.id AS coupon_id, c.code, c.coupon_name,
+	COUNT(*) AS orders, COUNT(DISTINCT l.user_id) AS customers, SUM(l.qty) AS units,
+	ROUND(SUM(l.discount),2) AS discount, ROUND(SUM(l.net),2) AS net_revenue
+	FROM lines l
+	INNER JOIN coupons c ON l.coupon_id = c.id
+	WHERE NOT l.lost
+	GROUP BY c.id, c.code, c.coupon_name
+	ORDER BY net_revenue DESC;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, pointValue).Scan(&coupons).Error
+	return coupons, err
+}
+
+// GetCustomerSegments splits the customers of the period by whether their first
+// order ever is in the period.
+func (rd *reportDatabase) GetCustomerSegments(ctx context.Context, from, to time.Time, pointValue float32) ([]response.CustomerSegment, error) {
+	var segments = make([]response.CustomerSegment, 0)
+	query := salesLines + `, first_orders AS (
+		SELECT user_id, MIN(created_at) AS first_order_at FROM order_lines
+		WHERE user_id IN (SELECT user_id FROM lines)
+		GROUP BY user_id
+	)
+	SELECT CASE WHEN f.first_order_at >= $1 THEN 'new' ELSE 'returning' END AS segment,
+	COUNT(DISTINCT l.user_id) AS customers, COUNT(*) AS orders, ROUND(SUM(l.net),2) AS net_revenue
+	FROM lines l
+	INNER JOIN first_orders f ON f.user_id = l.user_id
+	WHERE NOT l.lost
+	GROUP BY 1
+	ORDER BY 1;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, pointValue).Scan(&segments).Error
+	return segments, err
+}
+
+// GetSalesLines reads the order lines of the period with the ID after afterID,
+// in the order of the ID, for the exports to stream them in batches.
+func (rd *reportDatabase) GetSalesLines(ctx context.Context, from, to time.Time, pointValue float32, afterID, limit int) ([]response.SalesLine, error) {
+	var lines = make([]response.SalesLine, 0)
+	query := salesLines + `SELECT l.id AS order_id, l.created_at, l.status, l.user_id, u.user_name AS customer,
+	l.product_id, p.product_name, p.brand, c.category_name AS category, l.qty, l.price,
+	ROUND(l.gross,2) AS gross_sales, COALESCE(cp.code,'') AS coupon_code, ROUND(l.discount,2) AS discount,
+	ROUND(l.points,2) AS points_redeemed, ROUND(l.net,2) AS net_revenue, m.method_name AS payment_method,
+	COALESCE(st.name,'') AS state, COALESCE(a.pincode,'') AS pincode, l.lost
+	FROM lines l
+	INNER JOIN users u ON l.user_id = u.id
+	INNER JOIN products p ON l.product_id = p.id
+	INNER JOIN categories c ON p.category_id = c.id
+	INNER JOIN payment_methods m ON l.payment_method_id = m.id
+	LEFT JOIN coupons cp ON l.coupon_id = cp.id
+	LEFT JOIN addresses a ON l.addresses_id = a.id
+	LEFT JOIN states st ON a.state_id = st.id
+	WHERE l.id > $4
+	ORDER BY l.id
+	LIMIT $5;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, pointValue, afterID, limit).Scan(&lines).Error
+	return lines, err
+}
+
+// GetTaxByState sums up the net revenue per state the orders are shipped to,
+// the tax is worked out from the rate by the caller.
+func (rd *reportDatabase) GetTaxByState(ctx context.Context, from, to time.Time, pointValue float32) ([]response.TaxSummary, error) {
+	var states = make([]response.TaxSummary, 0)
+	query := salesLines + `SELECT COALESCE(st.name,'') AS state, COUNT(*) AS orders, ROUND(SUM(l.net),2) AS net_revenue
+	FROM lines l
+	LEFT JOIN addresses a ON l.addresses_id = a.id
+	LEFT JOIN states st ON a.state_id = st.id
+	WHERE NOT l.lost
+	GROUP BY 1
+	ORDER BY net_revenue DESC;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, pointValue).Scan(&states).Error
+	return states, err
+}
+
+// refundedPayments are the refunded payments of the order lines cancelled or
+// returned from $1 until $2.
+const refundedPayments = `FROM order_payments op
+	INNER JOIN order_lines o ON op.order_line_id = o.id
+	INNER JOIN order_statuses s ON o.order_status_id = s.id
+	INNER JOIN payment_methods m ON op.payment_method_id = m.id
+	INNER JOIN users u ON o.user_id = u.id
+	INNER JOIN products p ON o.product_id = p.id
+	WHERE op.is_refunded AND o.updated_at >= $1 AND o.updated_at < $2`
+
+// GetRefundLines reads the refunded payments of the period with the ID after
+// afterID, in the order of the ID.
+func (rd *reportDatabase) GetRefundLines(ctx context.Context, from, to time.Time, afterID, limit int) ([]response.RefundLine, error) {
+	var refunds = make([]response.RefundLine, 0)
+	query := `SELECT op.id AS payment_id, o.id AS order_id, o.updated_at AS refunded_at, s.status,
+	o.user_id, u.user_name AS customer, p.product_name, m.method_name AS payment_method,
+	ROUND(op.amount::numeric,2) AS amount
+	` + refundedPayments + `
+	AND op.id > $3
+	ORDER BY op.id
+	LIMIT $4;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to, afterID, limit).Scan(&refunds).Error
+	return refunds, err
+}
+
+func (rd *reportDatabase) GetRefundSummary(ctx context.Context, from, to time.Time) ([]response.RefundSummary, error) {
+	var summary = make([]response.RefundSummary, 0)
+	query := `SELECT s.status, m.method_name AS payment_method,
+	COUNT(*) AS refunds, ROUND(SUM(op.amount::numeric),2) AS amount
+	` + refundedPayments + `
+	GROUP BY s.status, m.method_name
+	ORDER BY s.status, amount DESC;`
+	err := rd.DB.WithContext(ctx).Raw(query, from, to).Scan(&summary).Error
+	return summary, err
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"gorm.io/gorm"
+)
+
+type transactor struct {
+	DB *gorm.DB
+}
+
+func NewTransactor(DB *gorm.DB) interfaces.Transactor {
+	return &transactor{
+		DB: DB,
+	}
+}
+
+func (t *transactor) Transaction(ctx context.Context, fn func(repos interfaces.TxRepositories) error) error {
+	return t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(interfaces.TxRepositories{
+			User:      NewUserRepository(tx),
+			Order:     NewOrderRepository(tx),
+			Payment:   NewPaymentRepository(tx),
+			Product:   NewProductRepository(tx),
+			Coupon:    NewCouponRepository(tx),
+			Wallet:    NewWalletRepository(tx),
+			Event:     NewEventRepository(tx),
+			OIDC:      NewOIDCRepository(tx),
+			Account:   NewAccountRepository(tx),
+			Cart:      NewCartRepository(tx),
+			Loyalty:   NewLoyaltyRepository(tx),
+			Promotion: NewPromotionRepository(tx),
+			Lockout:   NewLockoutRepository(tx),
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"gorm.io/gorm"
+)
+
+type twoFactorDatabase struct {
+	DB *gorm.DB
+}
+
+func NewTwoFactorRepository(DB *gorm.DB) interfaces.TwoFactorRepository {
+	return &twoFactorDatabase{
+		DB: DB,
+	}
+}
+
+func (td *twoFactorDatabase) FindTwoFactor(ctx context.Context, subject string) (response.TwoFactor, error) {
+	var twoFactor response.TwoFactor
+	query := `SELECT * FROM two_factors WHERE subject = $1;`
+	err := td.DB.WithContext(ctx).Raw(query, subject).Scan(&twoFactor).Error
+	return twoFactor, err
+}
+
+// UpsertPendingTwoFactor saves a new secret waiting for its first code, a pending
+// enrolment of the subject is replaced.
+func (td *twoFactorDatabase) UpsertPendingTwoFactor(ctx context.Context, subject, secret string, now time.Time) (response.TwoFactor, error) {
+	var twoFactor response.TwoFactor
+	query := `INSERT INTO two_factors (subject,secret,enabled,last_used_step,created_at) VALUES ($1,$2,false,0,$3)
+	ON CONFLICT (subject) DO UPDATE SET secret = $2, enabled = false, last_used_step = 0, enabled_at = NULL, created_at = $3
+	RETURNING *;`
+	err := td.DB.WithContext(ctx).Raw(query, subject, secret, now).Scan(&twoFactor).Error
+	return twoFactor, err
+}
+
+func (td *twoFactorDatabase) EnableTwoFactor(ctx context.Context, twoFactorID int, now time.Time) error {
+	query := `UPDATE two_factors SET enabled = true, enabled_at = $2 WHERE id = $1;`
+	return td.DB.WithContext(ctx).Exec(query, twoFactorID, now).Error
+}
+
+// UseTimeStep records the time step of an accepted code, false when a code of the
+// step or a later one was already used.
+func (td *twoFactorDatabase) UseTimeStep(ctx context.Context, twoFactorID int, step int64) (bool, error) {
+	result := td.DB.WithContext(ctx).Exec(`UPDATE two_factors SET last_used_step = $2 WHERE id = $1 AND last_used_step < $2;`, twoFactorID, step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (td *twoFactorDatabase) DeleteTwoFactor(ctx context.Context, twoFactorID int) error {
+	query := `DELETE FROM two_factors WHERE id = $1;`
+	return td.DB.WithContext(ctx).Exec(query, twoFactorID).Error
+}
+
+// ReplaceRecoveryCodes drops the previous codes of the enrolment and saves the new ones.
+func (td *twoFactorDatabase) ReplaceRecoveryCodes(ctx context.Context, twoFactorID int, codeHashes []string, now time.Time) error {
+	return td.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`DELETE FROM recovery_codes WHERE two_factor_id = $1;`, twoFactorID).Error
+		if err != nil {
+			return err
+		}
+
+		for _, codeHash := range codeHashes {
+			err = tx.Exec(`INSERT INTO recovery_codes (two_factor_id,code_hash,created_at) VALUES ($1,$2,$3);`, twoFactorID, codeHash, now).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UseRecoveryCode marks the code used, false when there is no unused code with the hash.
+func (td *twoFactorDatabase) UseRecoveryCode(ctx context.Context, twoFactorID int, codeHash string, now time.Time) (bool, error) {
+	result := td.DB.WithContext(ctx).Exec(`UPDATE recovery_codes SET used_at = $3 WHERE two_factor_id = $1 AND code_hash = $2 AND used_at IS NULL;`, twoFactorID, codeHash, now)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (td *twoFactorDatabase) CountUnusedRecoveryCodes(ctx context.Context, twoFactorID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE two_factor_id = $1 AND used_at IS NULL;`
+	err := td.DB.WithContext(ctx).Raw(query, twoFactorID).Scan(&count).Error
+	return count, err
+}
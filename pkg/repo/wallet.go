@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"context"
+
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"gorm.io/gorm"
@@ -16,31 +18,51 @@ func NewWalletRepository(DB *gorm.DB) interfaces.WalletRepository {
 	}
 }
 
-func (wd *walletDatabase) InsertIntoWallet(userID int, amount float32) (response.Wallet, error) {
+func (wd *walletDatabase) InsertIntoWallet(ctx context.Context, userID int, amount float32) (response.Wallet, error) {
 	var InsertedRecord response.Wallet
 
 	query := `INSERT INTO wallets (user_id,amount)VALUES($1,$2) RETURNING *;`
-	err := wd.DB.Raw(query, userID, amount).Scan(&InsertedRecord).Error
+	err := wd.DB.WithContext(ctx).Raw(query, userID, amount).Scan(&InsertedRecord).Error
 	return InsertedRecord, err
 }
 
+func (wd *walletDatabase) FindAppliedWallet(ctx context.Context, userID int) (response.AppliedWallet, error) {
+	var applied response.AppliedWallet
+	query := `SELECT * FROM applied_wallets WHERE user_id = $1;`
+	err := wd.DB.WithContext(ctx).Raw(query, userID).Scan(&applied).Error
+	return applied, err
+}
+
+func (wd *walletDatabase) SetAppliedWallet(ctx context.Context, userID int, amount float32) (response.AppliedWallet, error) {
+	var applied response.AppliedWallet
+	query := `INSERT INTO applied_wallets (user_id,amount)VALUES($1,$2)
+	ON CONFLICT (user_id) DO UPDATE SET amount = EXCLUDED.amount RETURNING *;`
+	err := wd.DB.WithContext(ctx).Raw(query, userID, amount).Scan(&applied).Error
+	return applied, err
+}
+
+func (wd *walletDatabase) RemoveAppliedWallet(ctx context.Context, userID int) error {
+	query := `DELETE FROM applied_wallets WHERE user_id = $1;`
+	return wd.DB.WithContext(ctx).Exec(query, userID).Error
+}
+
 // wishlist
-func (wd *walletDatabase) AddToWishList(userID, productID int) error {
+func (wd *walletDatabase) AddToWishList(ctx context.Context, userID, productID int) error {
 
 	query := `insert into wishlists (user_id, product_id) values($1, $2)`
-	return wd.DB.Exec(query, userID, productID).Error
+	return wd.DB.WithContext(ctx).Exec(query, userID, productID).Error
 }
 
-func (wd *walletDatabase) RemoveFromWishList(userID, productID int) error {
+func (wd *walletDatabase) RemoveFromWishList(ctx context.Context, userID, productID int) error {
 
 	query := `delete from wishlists where product_id = $1 and user_id = $2;`
-	return wd.DB.Exec(query, productID, userID).Error
+	return wd.DB.WithContext(ctx).Exec(query, productID, userID).Error
 }
 
-func (wd *walletDatabase) ShowWishListProducts(userID, page, count int) ([]response.Product, error) {
+func (wd *walletDatabase) ShowWishListProducts(ctx context.Context, userID, page, count int) ([]response.Product, error) {
 
 	products := []response.Product{}
 	query := `select p.* from products p inner join wishlists w on w.product_id = p.id where w.user_id = $1 offset $2 fetch next $3 row only `
-	err := wd.DB.Raw(query, userID, page, count).Scan(&products).Error
+	err := wd.DB.WithContext(ctx).Raw(query, userID, page, count).Scan(&products).Error
 	return products, err
 }
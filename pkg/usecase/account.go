@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	deletionPending   = "pending"
+	deletionCancelled = "cancelled"
+	deletionCompleted = "completed"
+
+	// used when ACCOUNT_DELETION_GRACE is not set
+	defaultDeletionGrace = 30 * 24 * time.Hour
+)
+
+var (
+	ErrDeletionPending   = errors.New("account deletion is already scheduled")
+	ErrNoPendingDeletion = errors.New("no account deletion is scheduled")
+	ErrOpenOrders        = errors.New("account has orders that are not delivered, cancelled or returned yet")
+)
+
+type accountUseCase struct {
+	accountRepo interfaces.AccountRepository
+	userRepo    interfaces.UserRepository
+	transactor  interfaces.Transactor
+	mailer      helper.Sender
+	grace       time.Duration
+}
+
+func NewAccountUseCase(accountRepo interfaces.AccountRepository, userRepo interfaces.UserRepository, transactor interfaces.Transactor, mailer helper.Sender, grace time.Duration) services.AccountUseCase {
+	if grace <= 0 {
+		grace = defaultDeletionGrace
+	}
+	return &accountUseCase{
+		accountRepo: accountRepo,
+		userRepo:    userRepo,
+		transactor:  transactor,
+		mailer:      mailer,
+		grace:       grace,
+	}
+}
+
+func (au *accountUseCase) ExportData(ctx context.Context, userID int) (response.AccountExport, error) {
+	userData, err := au.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return response.AccountExport{}, fmt.Errorf("Failed to find user : %s", err)
+	}
+	if userData.ID == 0 {
+		return response.AccountExport{}, fmt.Errorf("User not found")
+	}
+
+	export := response.AccountExport{
+		ExportedAt: time.Now(),
+		Profile:    userData,
+	}
+
+	if export.Addresses, err = au.userRepo.GetAllUserAddresses(ctx, userID); err != nil {
+		return response.AccountExport{}, fmt.Errorf("Failed to find user addresses :%s", err)
+	}
+	if export.Orders, err = au.accountRepo.GetExportOrders(ctx, userID); err != nil {
+		return response.AccountExport{}, fmt.Errorf("Failed to find user orders :%s", err)
+	}
+	if export.WalletBalance, err = au.accountRepo.GetWalletBalance(ctx, userID); err != nil {
+		return response.AccountExport{}, fmt.Errorf("Failed to find wallet balance :%s", err)
+	}
+	if export.WalletHistory, err = au.accountRepo.GetWalletHistory(ctx, userID); err != nil {
+		return response.AccountExport{}, fmt.Errorf("Failed to find wallet history :%s", err)
+	}
+	if export.Ratings, err = au.accountRepo.GetExportRatings(ctx, userID); err != nil {
+		return response.AccountExport{}, fmt.Errorf("Failed to find user ratings :%s", err)
+	}
+	if export.Wishlist, err = au.accountRepo.GetExportWishlist(ctx, userID); err != nil {
+		return response.AccountExport{}, fmt.Errorf("Failed to find user wishlist :%s", err)
+	}
+
+	return export, nil
+}
+
+func (au *accountUseCase) WriteExportArchive(ctx context.Context, userID int, w io.Writer) error {
+	export, err := au.ExportData(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"addresses.json", export.Addresses},
+		{"orders.json", export.Orders},
+		{"wallet.json", map[string]any{"balance": export.WalletBalance, "history": export.WalletHistory}},
+		{"ratings.json", export.Ratings},
+		{"wishlist.json", export.Wishlist},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to add %s to the archive :%s", file.name, err)
+		}
+
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return fmt.Errorf("Failed to write %s :%s", file.name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+func (au *accountUseCase) RequestDeletion(ctx context.Context, userID int, body request.DeleteAccount) (response.AccountDeletion, error) {
+	userData, err := au.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return response.AccountDeletion{}, fmt.Errorf("Failed to find user : %s", err)
+	}
+	if userData.ID == 0 {
+		return response.AccountDeletion{}, fmt.Errorf("User not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(body.Password)); err != nil {
+		return response.AccountDeletion{}, ErrPasswordMismatch
+	}
+
+	latest, err := au.accountRepo.FindLatestDeletion(ctx, userID)
+	if err != nil {
+		return response.AccountDeletion{}, fmt.Errorf("Failed to find account deletion :%s", err)
+	}
+	if latest.Status == deletionPending {
+		return response.AccountDeletion{}, ErrDeletionPending
+	}
+
+	openOrders, err := au.accountRepo.CountOpenOrders(ctx, userID)
+	if err != nil {
+		return response.AccountDeletion{}, fmt.Errorf("Failed to count open orders :%s", err)
+	}
+	if openOrders > 0 {
+		return response.AccountDeletion{}, ErrOpenOrders
+	}
+
+	now := time.Now()
+	deletion, err := au.accountRepo.InsertDeletion(ctx, userID, body.Reason, now, now.Add(au.grace))
+	if err != nil {
+		return response.AccountDeletion{}, fmt.Errorf("Failed to schedule account deletion :%s", err)
+	}
+	if deletion.ID == 0 {
+		return response.AccountDeletion{}, fmt.Errorf("Failed to verify account deletion")
+	}
+
+	if userData.Email != "" {
+		message := fmt.Sprintf("Hi %s,\n\nyour Device Mart account will be deleted on %s. Log in and cancel the deletion from your profile before then to keep it.",
+			userData.UserName, deletion.ScheduledFor.Format("02 Jan 2006"))
+		if err := au.mailer.Send(ctx, userData.Email, "Your account is scheduled for deletion", message); err != nil {
+			helper.LoggerFrom(ctx).Warn("account deletion mail not sent", "user_id", userData.ID, "error", err)
+		}
+	}
+
+	return deletion, nil
+}
+
+func (au *accountUseCase) GetDeletion(ctx context.Context, userID int) (response.AccountDeletion, error) {
+	deletion, err := au.accountRepo.FindLatestDeletion(ctx, userID)
+	if err != nil {
+		return response.AccountDeletion{}, fmt.Errorf("Failed to find account deletion :%s", err)
+	}
+	if deletion.ID == 0 {
+		return response.AccountDeletion{}, ErrNoRecord
+	}
+	return deletion, nil
+}
+
+func (au *accountUseCase) CancelDeletion(ctx context.Context, userID int) (response.AccountDeletion, error) {
+	latest, err := au.accountRepo.FindLatestDeletion(ctx, userID)
+	if err != nil {
+		return response.AccountDeletion{}, fmt.Errorf("Failed to find account deletion :%s", err)
+	}
+	if latest.Status != deletionPending {
+		return response.AccountDeletion{}, ErrNoPendingDeletion
+	}
+
+	deletion, err := au.accountRepo.CloseDeletion(ctx, int(latest.ID), deletionCancelled, time.Now())
+	if err != nil {
+		return response.AccountDeletion{}, fmt.Errorf("Failed to cancel account deletion :%s", err)
+	}
+	if deletion.ID == 0 {
+		return response.AccountDeletion{}, ErrNoPendingDeletion
+	}
+	return deletion, nil
+}
+
+func (au *accountUseCase) DeleteDueAccounts(ctx context.Context) error {
+	due, err := au.accountRepo.GetDueDeletions(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to find due account deletions :%s", err)
+	}
+
+	for _, deletion := range due {
+		if err := au.deleteAccount(ctx, deletion); err != nil {
+			helper.LoggerFrom(ctx).Error("account not deleted", "deletion_id", deletion.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// deleteAccount anonymises the user and the addresses of the orders, which are
+// kept for the accounts, and removes the rest of the personal data.
+func (au *accountUseCase) deleteAccount(ctx context.Context, deletion response.AccountDeletion) error {
+	userID := int(deletion.UserID)
+
+	// orders placed during the grace period still need the address, the
+	// deletion waits for them to finish
+	openOrders, err := au.accountRepo.CountOpenOrders(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to count open orders :%s", err)
+	}
+	if openOrders > 0 {
+		return nil
+	}
+
+	randomPassword, err := helper.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), 10)
+	if err != nil {
+		return fmt.Errorf("Failed to hash password :%s", err)
+	}
+
+	return au.transactor.Transaction(ctx, func(repos interfaces.TxRepositories) error {
+		now := time.Now()
+		closed, err := repos.Account.CloseDeletion(ctx, int(deletion.ID), deletionCompleted, now)
+		if err != nil {
+			return fmt.Errorf("Failed to complete account deletion :%s", err)
+		}
+		if closed.ID == 0 {
+			// cancelled since it was picked up
+			return nil
+		}
+
+		email := fmt.Sprintf("deleted-%d@deleted.invalid", userID)
+		if err := repos.Account.AnonymiseUser(ctx, userID, email, string(hashedPassword), now); err != nil {
+			return fmt.Errorf("Failed to anonymise user :%s", err)
+		}
+		if err := repos.Account.AnonymiseOrderAddresses(ctx, userID); err != nil {
+			return fmt.Errorf("Failed to anonymise order addresses :%s", err)
+		}
+		if err := repos.Account.DeletePersonalData(ctx, userID, UserSubject(userID)); err != nil {
+			return fmt.Errorf("Failed to delete personal data :%s", err)
+		}
+		return nil
+	})
+}
@@ -1,28 +1,41 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
 
 type adminUsecase struct {
-	adminRepo interfaces.AdminRepository
-	userRepo  interfaces.UserRepository
+	adminRepo    interfaces.AdminRepository
+	userRepo     interfaces.UserRepository
+	accountRepo  interfaces.AccountRepository
+	orderRepo    interfaces.OrderRepository
+	referralRepo interfaces.ReferralRepository
 }
 
-func NewAdminUseCase(adminUseCase interfaces.AdminRepository, userUseCase interfaces.UserRepository) services.AdminUseCase {
+func NewAdminUseCase(adminUseCase interfaces.AdminRepository, userUseCase interfaces.UserRepository, accountRepo interfaces.AccountRepository, orderRepo interfaces.OrderRepository, referralRepo interfaces.ReferralRepository) services.AdminUseCase {
 	return &adminUsecase{
-		adminRepo: adminUseCase,
-		userRepo:  userUseCase,
+		adminRepo:    adminUseCase,
+		userRepo:     userUseCase,
+		accountRepo:  accountRepo,
+		orderRepo:    orderRepo,
+		referralRepo: referralRepo,
 	}
 }
 
-func (ac *adminUsecase) GetAllUserData() ([]response.UserData, error) {
+func (ac *adminUsecase) GetAllUserData(ctx context.Context, filter request.UserFilter, page, count int) ([]response.UserData, error) {
+	filter.Search = strings.TrimSpace(filter.Search)
+	startIndex, endIndex := helper.Paginate(page, count)
 
-	listOfAllUserData, err := ac.adminRepo.FetchAllUserData()
+	listOfAllUserData, err := ac.adminRepo.GetUsers(ctx, filter, startIndex, endIndex)
 	if err != nil {
 		return []response.UserData{}, fmt.Errorf("Failed to get user data's :%s", err)
 	}
@@ -31,26 +44,120 @@ func (ac *adminUsecase) GetAllUserData() ([]response.UserData, error) {
 
 }
 
-func (ac *adminUsecase) BlockUserByID(ID int) error {
-	err := ac.adminRepo.BlockUserByID(ID)
+func (ac *adminUsecase) BlockUserByID(ctx context.Context, ID int) error {
+	err := ac.adminRepo.BlockUserByID(ctx, ID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (ac *adminUsecase) UnBlockUserByID(ID int) error {
-	err := ac.adminRepo.UnblockUserByID(ID)
+func (ac *adminUsecase) UnBlockUserByID(ctx context.Context, ID int) error {
+	err := ac.adminRepo.UnblockUserByID(ctx, ID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (ac *adminUsecase) FindUsersByName(name string) ([]response.UserData, error) {
-	user, err := ac.adminRepo.FindUsersByName(name)
+func (ac *adminUsecase) FindUsersByName(ctx context.Context, name string) ([]response.UserData, error) {
+	user, err := ac.adminRepo.FindUsersByName(ctx, strings.TrimSpace(name))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("Failed to find users :%s", err)
 	}
 	return user, nil
 }
+
+func (ac *adminUsecase) GetCustomer(ctx context.Context, userID int) (response.Customer, error) {
+	userData, err := ac.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find user : %s", err)
+	}
+	if userData.ID == 0 {
+		return response.Customer{}, ErrNoRecord
+	}
+
+	customer := response.Customer{
+		Profile: userData,
+	}
+
+	if customer.Addresses, err = ac.userRepo.GetAllUserAddresses(ctx, userID); err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find user addresses :%s", err)
+	}
+	if customer.OrderStats, err = ac.orderRepo.GetUserOrderStats(ctx, userID); err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find order stats :%s", err)
+	}
+	if customer.Orders, err = ac.accountRepo.GetExportOrders(ctx, userID); err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find user orders :%s", err)
+	}
+	if customer.WalletBalance, err = ac.accountRepo.GetWalletBalance(ctx, userID); err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find wallet balance :%s", err)
+	}
+	if customer.WalletHistory, err = ac.accountRepo.GetWalletHistory(ctx, userID); err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find wallet history :%s", err)
+	}
+
+	referral, err := ac.referralRepo.FindReferralCodeByUserID(ctx, userID)
+	if err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find referral code :%s", err)
+	}
+	customer.ReferralCode = referral.Code
+	if customer.ReferralClaims, err = ac.referralRepo.GetReferralClaimsByUserID(ctx, userID); err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find referral claims :%s", err)
+	}
+
+	if customer.Ratings, err = ac.accountRepo.GetExportRatings(ctx, userID); err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find user ratings :%s", err)
+	}
+	if customer.Notes, err = ac.adminRepo.GetAdminNotes(ctx, userID); err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find admin notes :%s", err)
+	}
+
+	deletion, err := ac.accountRepo.FindLatestDeletion(ctx, userID)
+	if err != nil {
+		return response.Customer{}, fmt.Errorf("Failed to find account deletion :%s", err)
+	}
+	if deletion.ID != 0 {
+		customer.Deletion = &deletion
+	}
+
+	return customer, nil
+}
+
+func (ac *adminUsecase) AddNote(ctx context.Context, userID int, note string) (response.AdminNote, error) {
+	userData, err := ac.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return response.AdminNote{}, fmt.Errorf("Failed to find user : %s", err)
+	}
+	if userData.ID == 0 {
+		return response.AdminNote{}, ErrNoRecord
+	}
+
+	adminNote, err := ac.adminRepo.InsertAdminNote(ctx, userID, strings.TrimSpace(note), time.Now())
+	if err != nil {
+		return response.AdminNote{}, fmt.Errorf("Failed to add note :%s", err)
+	}
+	if adminNote.ID == 0 {
+		return response.AdminNote{}, fmt.Errorf("Failed to verify added note")
+	}
+	return adminNote, nil
+}
+
+func (ac *adminUsecase) GetNotes(ctx context.Context, userID int) ([]response.AdminNote, error) {
+	notes, err := ac.adminRepo.GetAdminNotes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get notes :%s", err)
+	}
+	return notes, nil
+}
+
+func (ac *adminUsecase) DeleteNote(ctx context.Context, noteID int) error {
+	deleted, err := ac.adminRepo.DeleteAdminNote(ctx, noteID)
+	if err != nil {
+		return fmt.Errorf("Failed to delete note :%s", err)
+	}
+	if deleted.ID == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+const (
+	auditExportBatch = 500
+)
+
+// auditEntities maps the route params of the admin routes to the entity they
+// name, the first param found is the target of the request.
+var auditEntities = []struct {
+	param  string
+	entity string
+}{
+	{"userID", "user"},
+	{"orderID", "order"},
+	{"productID", "product"},
+	{"couponID", "coupon"},
+	{"promotionID", "promotion"},
+	{"scheduleID", "scheduled_price"},
+	{"ruleID", "loyalty_rule"},
+	{"lockoutID", "login_lockout"},
+	{"noteID", "admin_note"},
+	{"eventID", "domain_event"},
+	{"categoryID", "category"},
+}
+
+type auditUseCase struct {
+	auditRepo interfaces.AuditRepository
+}
+
+func NewAuditUseCase(auditRepo interfaces.AuditRepository) services.AuditUseCase {
+	return &auditUseCase{
+		auditRepo: auditRepo,
+	}
+}
+
+func (au *auditUseCase) EntityFromParams(params map[string]string) (string, string) {
+	for _, candidate := range auditEntities {
+		if id, ok := params[candidate.param]; ok {
+			return candidate.entity, id
+		}
+	}
+	return "", ""
+}
+
+func (au *auditUseCase) Snapshot(ctx context.Context, entityType, entityID string) string {
+	if entityType == "" {
+		return ""
+	}
+
+	snapshot, err := au.auditRepo.SnapshotEntity(ctx, entityType, entityID)
+	if err != nil {
+		helper.LoggerFrom(ctx).Warn("audit snapshot not taken", "entity_type", entityType, "entity_id", entityID, "error", err)
+		return ""
+	}
+	return snapshot
+}
+
+func (au *auditUseCase) RedactBody(body []byte) string {
+	var value any
+	if len(body) == 0 || json.Unmarshal(body, &value) != nil {
+		return ""
+	}
+
+	redactedBody, err := json.Marshal(helper.Redact(value))
+	if err != nil {
+		return ""
+	}
+	return string(redactedBody)
+}
+
+func (au *auditUseCase) Record(ctx context.Context, log request.AuditLog) error {
+	changes, err := diffSnapshots(log.Before, log.After)
+	if err != nil {
+		helper.LoggerFrom(ctx).Warn("audit changes not worked out", "error", err)
+	}
+	log.Changes = changes
+
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+
+	auditLog, err := au.auditRepo.InsertAuditLog(ctx, log)
+	if err != nil {
+		return fmt.Errorf("Failed to insert audit log :%s", err)
+	}
+	if auditLog.ID == 0 {
+		return fmt.Errorf("Failed to verify inserted audit log")
+	}
+	return nil
+}
+
+// diffSnapshots returns the fields that differ between the two json objects as
+// {"field": {"before": .., "after": ..}}, empty when one of them is missing or
+// nothing changed.
+func diffSnapshots(before, after string) (string, error) {
+	if before == "" || after == "" {
+		return "", nil
+	}
+
+	var beforeFields, afterFields map[string]any
+	if err := json.Unmarshal([]byte(before), &beforeFields); err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal([]byte(after), &afterFields); err != nil {
+		return "", err
+	}
+
+	changes := make(map[string]map[string]any)
+	for key, beforeValue := range beforeFields {
+		if afterValue := afterFields[key]; !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[key] = map[string]any{"before": beforeValue, "after": afterValue}
+		}
+	}
+	for key, afterValue := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = map[string]any{"before": nil, "after": afterValue}
+		}
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	diff, err := json.Marshal(changes)
+	return string(diff), err
+}
+
+func (au *auditUseCase) GetAuditLogs(ctx context.Context, filter request.AuditLogFilter, page, count int) ([]response.AuditLog, error) {
+	startIndex, endIndex := helper.Paginate(page, count)
+
+	logs, err := au.auditRepo.GetAuditLogs(ctx, filter, 0, startIndex, endIndex)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get audit logs :%s", err)
+	}
+	return logs, nil
+}
+
+func (au *auditUseCase) WriteAuditCSV(ctx context.Context, filter request.AuditLogFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"id", "created_at", "actor", "action", "path", "entity_type", "entity_id", "status_code", "ip", "user_agent", "changes", "before", "after", "request_body"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	beforeID := 0
+	for {
+		logs, err := au.auditRepo.GetAuditLogs(ctx, filter, beforeID, 0, auditExportBatch)
+		if err != nil {
+			return fmt.Errorf("Failed to get audit logs :%s", err)
+		}
+
+		for _, log := range logs {
+			record := []string{
+				strconv.Itoa(int(log.ID)),
+				log.CreatedAt.Format(time.RFC3339),
+				log.Actor,
+				log.Action,
+				log.Path,
+				log.EntityType,
+				log.EntityID,
+				strconv.Itoa(log.StatusCode),
+				log.IP,
+				log.UserAgent,
+				log.Changes,
+				log.Before,
+				log.After,
+				log.RequestBody,
+			}
+			for i := range record {
+				record[i] = helper.CSVSafe(record[i])
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if len(logs) < auditExportBatch {
+			return nil
+		}
+		beforeID = int(logs[len(logs)-1].ID)
+	}
+}
@@ -1,105 +1,190 @@
 package usecase
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	InvalidCredentials = errors.New("Invalid credentials")
+	InvalidCredentials      = errors.New("Invalid credentials")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+	ErrPasswordMismatch     = errors.New("Password is not matching")
 )
 
+// PasswordPolicyError is returned when a new password is refused by the password
+// policy, the reason tells the user what to change.
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Reason
+}
+
+// checkPassword validates the new password against the policy.
+func checkPassword(policy *helper.PasswordPolicy, password string) error {
+	if err := policy.Validate(password); err != nil {
+		return &PasswordPolicyError{Reason: err.Error()}
+	}
+	return nil
+}
+
 type authUseCase struct {
-	userRepo  interfaces.UserRepository
-	adminRepo interfaces.AdminRepository
+	userRepo         interfaces.UserRepository
+	adminRepo        interfaces.AdminRepository
+	transactor       interfaces.Transactor
+	lockoutUseCase   services.LockoutUseCase
+	twoFactorUseCase services.TwoFactorUseCase
+	passwordPolicy   *helper.PasswordPolicy
+	mailer           helper.Sender
+	baseURL          string
 }
 
-func NewCommonUseCase(userRepo interfaces.UserRepository, adminRepo interfaces.AdminRepository) services.AuthUseCase {
+// NewCommonUseCase takes the email sender as the mailer of the verification and
+// password reset emails, the links in them start with the base url.
+func NewCommonUseCase(userRepo interfaces.UserRepository, adminRepo interfaces.AdminRepository, transactor interfaces.Transactor,
+	lockoutUseCase services.LockoutUseCase, twoFactorUseCase services.TwoFactorUseCase, passwordPolicy *helper.PasswordPolicy, mailer helper.Sender, baseURL string) services.AuthUseCase {
 	return &authUseCase{
-		userRepo:  userRepo,
-		adminRepo: adminRepo,
+		userRepo:         userRepo,
+		adminRepo:        adminRepo,
+		transactor:       transactor,
+		lockoutUseCase:   lockoutUseCase,
+		twoFactorUseCase: twoFactorUseCase,
+		passwordPolicy:   passwordPolicy,
+		mailer:           mailer,
+		baseURL:          baseURL,
 	}
 
 }
 
-func (ac *authUseCase) AdminLogin(sudoData request.AdminLogin) error {
-	adminCredentials, err := ac.adminRepo.FindAdminCredentials()
+func (ac *authUseCase) AdminLogin(ctx context.Context, sudoData request.AdminLogin) error {
+	adminCredentials, err := ac.adminRepo.FindAdminCredentials(ctx)
 	if err != nil {
 		return err
 	}
 	if sudoData.Username == "" || sudoData.Password == "" {
 		return fmt.Errorf("Credentials is empty")
+	}
 
-	} else if adminCredentials.AdminUsername == sudoData.Username && adminCredentials.AdminPassword == sudoData.Password {
-		return nil
+	accountKey := lockKey("admin", sudoData.Username)
+	if err := ac.lockoutUseCase.ReserveLogin(ctx, accountKey, sudoData.IP); err != nil {
+		return err
+	}
 
-	} else {
-		return InvalidCredentials
+	if adminCredentials.AdminUsername == sudoData.Username && adminCredentials.AdminPassword == sudoData.Password {
+		return ac.lockoutUseCase.LoginSucceeded(ctx, accountKey, sudoData.IP)
 	}
+	return InvalidCredentials
 }
 
-func (cu *authUseCase) ValidateSignUpRequest(phone request.Phone) (int, error) {
-	userData, err := cu.userRepo.FindUserByPhone(phone.Phone)
+func (cu *authUseCase) ValidateSignUpRequest(ctx context.Context, phone request.Phone) (int, error) {
+	userData, err := cu.userRepo.FindUserByPhone(ctx, phone.Phone)
 	if err != nil {
 		return 0, err
 	}
 	if userData.ID != 0 {
 		return 0, fmt.Errorf("User already exist with this phone number")
 	}
-	//below code is necessary commented out because defined a predefined otp
-
-	// number := strconv.Itoa(phone.Phone)
-	// err = helper.SendOtp(number)
-	// if err != nil {
-	// 	return 0, fmt.Errorf("Failed to send otp%s", err)
-	// }
+	// with OTP_DEV_MODE no otp is sent and 0000 is accepted
+	number := strconv.Itoa(phone.Phone)
+	err = helper.SendOtp(number)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to send otp%s", err)
+	}
 
 	return phone.Phone, nil
 }
 
-func (u *authUseCase) SignUp(user request.SignUpData) error {
-	userData, err := u.userRepo.FindUserByPhone(user.Phone)
+func (u *authUseCase) SignUp(ctx context.Context, user request.SignUpData) (response.UserData, error) {
+	if err := checkPassword(u.passwordPolicy, user.Password); err != nil {
+		return response.UserData{}, err
+	}
+
+	userData, err := u.userRepo.FindUserByPhone(ctx, user.Phone)
 	if err != nil {
-		return fmt.Errorf("Failed to find user by phone :%s", err)
+		return response.UserData{}, fmt.Errorf("Failed to find user by phone :%s", err)
 	}
 	if userData.ID != 0 {
-		return fmt.Errorf("User already exist with this phone number")
+		return response.UserData{}, fmt.Errorf("User already exist with this phone number")
 	}
 
-	userData, err = u.userRepo.FindUserByEmail(user.Email)
+	userData, err = u.userRepo.FindUserByEmail(ctx, user.Email)
 	if err != nil {
-		return fmt.Errorf("Failed to find user by email :%s", err)
+		return response.UserData{}, fmt.Errorf("Failed to find user by email :%s", err)
 	}
 	if userData.ID != 0 {
-		return fmt.Errorf("User already exist with this email address")
+		return response.UserData{}, fmt.Errorf("User already exist with this email address")
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), 10)
 	if err != nil {
-		return fmt.Errorf("failed to generate hash from password :%s", err)
+		return response.UserData{}, fmt.Errorf("failed to generate hash from password :%s", err)
 	}
 
 	user.Password = string(hashedPassword)
 
-	if _, err := u.userRepo.CreateUser(user); err != nil {
-		return fmt.Errorf("Failed to save user on db, user sign up failed :%s", err)
+	// the user and the UserRegistered event are saved together
+	err = u.transactor.Transaction(ctx, func(repos interfaces.TxRepositories) error {
+		userData, err = repos.User.CreateUser(ctx, user)
+		if err != nil {
+			return fmt.Errorf("Failed to save user on db, user sign up failed :%s", err)
+		}
+
+		return publishEvent(ctx, repos.Event, EventUserRegistered, userData.ID, request.UserRegistered{
+			UserID:   userData.ID,
+			UserName: userData.UserName,
+			Email:    userData.Email,
+		})
+	})
+	if err != nil {
+		return response.UserData{}, err
 	}
 
-	return nil
+	return userData, nil
 }
 
-func (u *authUseCase) ValidateUserLoginCredentials(user request.LoginData) (response.UserData, error) {
-	userData, err := u.userRepo.FindUserByPhone(user.Phone)
+// ValidateUserLoginCredentials logs in with the email address when it is given,
+// else with the phone number. Every attempt is counted on the account, whichever
+// identifier is typed, and on the IP before the password is checked. Failed logins
+// delay the next ones, a LoginLockedError is returned while they are refused.
+func (u *authUseCase) ValidateUserLoginCredentials(ctx context.Context, user request.LoginData) (response.UserData, error) {
+	var (
+		userData response.UserData
+		err      error
+	)
+	switch {
+	case user.Email != "":
+		userData, err = u.userRepo.FindUserByEmail(ctx, user.Email)
+	case user.Phone != 0:
+		userData, err = u.userRepo.FindUserByPhone(ctx, user.Phone)
+	default:
+		return response.UserData{}, fmt.Errorf("Phone or email is required")
+	}
 	if err != nil {
 		return response.UserData{}, err
 	}
 
+	// an unknown account is counted on the IP only
+	var accountKey string
+	if userData.ID != 0 {
+		accountKey = lockKey("user", fmt.Sprint(userData.ID))
+	}
+	if err := u.lockoutUseCase.ReserveLogin(ctx, accountKey, user.IP); err != nil {
+		return response.UserData{}, err
+	}
+
 	if userData.ID == 0 {
 		return response.UserData{}, fmt.Errorf("User don't have an account")
 	}
@@ -112,7 +197,288 @@ func (u *authUseCase) ValidateUserLoginCredentials(user request.LoginData) (resp
 		return response.UserData{}, fmt.Errorf("incorrect password")
 	}
 
+	if err := u.lockoutUseCase.LoginSucceeded(ctx, accountKey, user.IP); err != nil {
+		return response.UserData{}, err
+	}
+
 	userData.Password = ""
 	return userData, nil
 
 }
+
+// AdminLoginChallenge returns the challenge of the second admin login step, the
+// admin must use two factor authentication and enrols on the first login.
+func (ac *authUseCase) AdminLoginChallenge(ctx context.Context) (response.TwoFactorChallenge, error) {
+	enabled, err := ac.twoFactorUseCase.IsEnabled(ctx, AdminSubject)
+	if err != nil {
+		return response.TwoFactorChallenge{}, err
+	}
+
+	if enabled {
+		token, err := helper.GenerateTwoFactorChallengeToken(AdminSubject, helper.TwoFactorVerify)
+		return response.TwoFactorChallenge{ChallengeToken: token}, err
+	}
+
+	enrolment, err := ac.twoFactorUseCase.BeginEnrolment(ctx, AdminSubject)
+	if err != nil {
+		return response.TwoFactorChallenge{}, err
+	}
+	token, err := helper.GenerateTwoFactorChallengeToken(AdminSubject, helper.TwoFactorEnrolment)
+	return response.TwoFactorChallenge{ChallengeToken: token, Enrolment: &enrolment}, err
+}
+
+// VerifyAdminTwoFactor checks the code of the admin challenge, the recovery codes
+// are returned when the code completed the enrolment.
+func (ac *authUseCase) VerifyAdminTwoFactor(ctx context.Context, body request.TwoFactorLogin) ([]string, error) {
+	subject, purpose, err := helper.ParseTwoFactorChallengeToken(body.ChallengeToken)
+	if err != nil || subject != AdminSubject {
+		return nil, ErrInvalidToken
+	}
+
+	if purpose == helper.TwoFactorEnrolment {
+		return ac.twoFactorUseCase.Enable(ctx, subject, body.Code, body.IP)
+	}
+	return nil, ac.twoFactorUseCase.Verify(ctx, subject, body.Code, body.IP)
+}
+
+// UserLoginChallenge returns the challenge of the second login step when the user
+// enabled two factor authentication.
+func (u *authUseCase) UserLoginChallenge(ctx context.Context, userID int) (response.TwoFactorChallenge, bool, error) {
+	subject := UserSubject(userID)
+	enabled, err := u.twoFactorUseCase.IsEnabled(ctx, subject)
+	if err != nil || !enabled {
+		return response.TwoFactorChallenge{}, false, err
+	}
+
+	token, err := helper.GenerateTwoFactorChallengeToken(subject, helper.TwoFactorVerify)
+	return response.TwoFactorChallenge{ChallengeToken: token}, true, err
+}
+
+// VerifyUserTwoFactor checks the code of the user challenge and returns the user id.
+func (u *authUseCase) VerifyUserTwoFactor(ctx context.Context, body request.TwoFactorLogin) (int, error) {
+	subject, purpose, err := helper.ParseTwoFactorChallengeToken(body.ChallengeToken)
+	if err != nil || purpose != helper.TwoFactorVerify {
+		return 0, ErrInvalidToken
+	}
+	userID, ok := subjectUserID(subject)
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+
+	if err := u.twoFactorUseCase.Verify(ctx, subject, body.Code, body.IP); err != nil {
+		return 0, err
+	}
+
+	userData, err := u.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to find user :%s", err)
+	}
+	if userData.IsBlocked {
+		return 0, fmt.Errorf("User has been blocked")
+	}
+	return userID, nil
+}
+
+// Subscribe sends the verification email to the newly registered users.
+func (u *authUseCase) Subscribe(bus services.EventUseCase) {
+	bus.Subscribe(EventUserRegistered, "email-verification", func(ctx context.Context, event response.DomainEvent) error {
+		var registered request.UserRegistered
+		if err := decodeEvent(event, &registered); err != nil {
+			return err
+		}
+		err := u.SendEmailVerification(ctx, registered.UserID)
+		if err == ErrEmailAlreadyVerified {
+			return nil
+		}
+		return err
+	})
+}
+
+func (u *authUseCase) SendEmailVerification(ctx context.Context, userID int) error {
+	userData, err := u.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to find user :%s", err)
+	}
+	if userData.ID == 0 {
+		return ErrNoRecord
+	}
+	if userData.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := helper.GenerateEmailVerificationToken(userData.ID, userData.Email)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below, the link expires in 24 hours.\n%s/api/v1/verify-email?token=%s\n",
+		userData.UserName, u.baseURL, token)
+	return u.mailer.Send(ctx, userData.Email, "Verify your email address", body)
+}
+
+// VerifyEmail marks the email of the token as verified, the token is refused if
+// the user's email address has changed since it was sent.
+func (u *authUseCase) VerifyEmail(ctx context.Context, token string) error {
+	userID, email, err := helper.ParseEmailVerificationToken(token)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	userData, err := u.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to find user :%s", err)
+	}
+	if userData.ID == 0 || userData.Email != email {
+		return ErrInvalidToken
+	}
+	if userData.EmailVerified {
+		return nil
+	}
+
+	err = u.userRepo.MarkEmailVerified(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to verify email :%s", err)
+	}
+	return nil
+}
+
+// ForgotPassword sends a password reset link to the email address or an otp to
+// the phone number. Unknown accounts are not reported, so the response does not
+// tell which emails and phones are registered: a phone reset always gets a
+// challenge token, for an unknown phone one that can never be completed.
+func (u *authUseCase) ForgotPassword(ctx context.Context, body request.ForgotPassword) (response.PasswordResetChallenge, error) {
+	switch {
+	case body.Email != "":
+		userData, err := u.userRepo.FindUserByEmail(ctx, body.Email)
+		if err != nil {
+			return response.PasswordResetChallenge{}, fmt.Errorf("Failed to find user by email :%s", err)
+		}
+		if userData.ID == 0 || userData.IsBlocked {
+			return response.PasswordResetChallenge{}, nil
+		}
+
+		token, err := helper.GeneratePasswordResetToken(userData.ID, passwordFingerprint(userData.Password))
+		if err != nil {
+			return response.PasswordResetChallenge{}, err
+		}
+
+		message := fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new password, the link expires in 30 minutes.\n%s/reset-password?token=%s\n\nIf you did not request it you can ignore this email.\n",
+			userData.UserName, u.baseURL, token)
+		return response.PasswordResetChallenge{}, u.mailer.Send(ctx, userData.Email, "Reset your password", message)
+
+	case body.Phone != 0:
+		userData, err := u.userRepo.FindUserByPhone(ctx, body.Phone)
+		if err != nil {
+			return response.PasswordResetChallenge{}, fmt.Errorf("Failed to find user by phone :%s", err)
+		}
+
+		var challenge string
+		if userData.ID == 0 || userData.IsBlocked {
+			decoy, err := helper.RandomToken(8)
+			if err != nil {
+				return response.PasswordResetChallenge{}, err
+			}
+			challenge, err = helper.GeneratePasswordResetChallenge(0, decoy)
+			if err != nil {
+				return response.PasswordResetChallenge{}, err
+			}
+			return response.PasswordResetChallenge{ChallengeToken: challenge}, nil
+		}
+
+		challenge, err = helper.GeneratePasswordResetChallenge(userData.ID, passwordFingerprint(userData.Password))
+		if err != nil {
+			return response.PasswordResetChallenge{}, err
+		}
+		err = helper.SendOtp(fmt.Sprint(userData.Phone))
+		if err != nil {
+			return response.PasswordResetChallenge{}, fmt.Errorf("Failed to send otp :%s", err)
+		}
+		return response.PasswordResetChallenge{ChallengeToken: challenge}, nil
+
+	default:
+		return response.PasswordResetChallenge{}, fmt.Errorf("Phone or email is required")
+	}
+}
+
+// ResetPassword sets the new password of the user of the reset token, or of the
+// challenge token when the otp sent to the phone is approved. The otp attempts
+// are throttled per account and ip like the logins, and the challenge expires
+// after 10 minutes and stops working once the password is changed.
+func (u *authUseCase) ResetPassword(ctx context.Context, body request.ResetPassword) error {
+	if body.NewPassword != body.ReNewPassword {
+		return ErrPasswordMismatch
+	}
+	if err := checkPassword(u.passwordPolicy, body.NewPassword); err != nil {
+		return err
+	}
+
+	var userData response.UserData
+	switch {
+	case body.Token != "":
+		userID, fingerprint, err := helper.ParsePasswordResetToken(body.Token)
+		if err != nil {
+			return ErrInvalidToken
+		}
+
+		userData, err = u.userRepo.FindUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("Failed to find user :%s", err)
+		}
+		// the fingerprint changes with the password, a used link stops working
+		if userData.ID == 0 || passwordFingerprint(userData.Password) != fingerprint {
+			return ErrInvalidToken
+		}
+
+	case body.ChallengeToken != "" && body.Otp != "":
+		userID, fingerprint, err := helper.ParsePasswordResetChallenge(body.ChallengeToken)
+		if err != nil {
+			return ErrInvalidToken
+		}
+
+		accountKey := lockKey("reset", fmt.Sprint(userID))
+		if err := u.lockoutUseCase.ReserveLogin(ctx, accountKey, body.IP); err != nil {
+			return err
+		}
+
+		userData, err = u.userRepo.FindUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("Failed to find user :%s", err)
+		}
+		if userData.ID == 0 || passwordFingerprint(userData.Password) != fingerprint {
+			return ErrInvalidToken
+		}
+
+		status, err := helper.CheckOtp(fmt.Sprint(userData.Phone), body.Otp)
+		if err != nil || status != "approved" {
+			return ErrInvalidToken
+		}
+		if err := u.lockoutUseCase.LoginSucceeded(ctx, accountKey, body.IP); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("Token or challenge token and otp are required")
+	}
+
+	if userData.IsBlocked {
+		return fmt.Errorf("User has been blocked")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.NewPassword), 10)
+	if err != nil {
+		return fmt.Errorf("failed to generate hash from password :%s", err)
+	}
+
+	err = u.userRepo.ChangePassword(ctx, userData.ID, string(hashedPassword))
+	if err != nil {
+		return fmt.Errorf("Failed to change password :%s", err)
+	}
+	return nil
+}
+
+// passwordFingerprint is a short digest of the password hash carried by the reset
+// tokens, the hash itself never leaves the server.
+func passwordFingerprint(hash string) string {
+	sum := sha256.Sum256([]byte(hash))
+	return hex.EncodeToString(sum[:8])
+}
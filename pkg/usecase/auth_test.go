@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (f *fakeUserRepo) ChangePassword(ctx context.Context, userID int, newPassword string) error {
+	user := f.users[userID]
+	user.Password = newPassword
+	f.users[userID] = user
+	return nil
+}
+
+var linkToken = regexp.MustCompile(`token=(\S+)`)
+
+// newAuthTest returns the use case with the emails kept by a capture sender and
+// one user with the password "old-password".
+func newAuthTest(t *testing.T) (*authUseCase, *fakeUserRepo, *helper.CaptureSender) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeUserRepo{users: map[int]response.UserData{
+		1: {ID: 1, UserName: "user", Email: "user@example.com", Password: string(hash)},
+	}}
+	mailer := helper.NewCaptureSender(helper.ChannelEmail)
+	useCase := NewCommonUseCase(users, nil, nil, nil, nil, &helper.PasswordPolicy{MinLength: 8}, mailer, "http://localhost:3000").(*authUseCase)
+	return useCase, users, mailer
+}
+
+// sentToken returns the token of the link in the latest email to the address.
+func sentToken(t *testing.T, mailer *helper.CaptureSender, to string) string {
+	t.Helper()
+
+	message, ok := mailer.Last(to)
+	if !ok {
+		t.Fatalf("no email sent to %s", to)
+	}
+	match := linkToken.FindStringSubmatch(message.Body)
+	if match == nil {
+		t.Fatalf("email %q has no link", message.Body)
+	}
+	return match[1]
+}
+
+func TestEmailVerificationLink(t *testing.T) {
+	useCase, users, mailer := newAuthTest(t)
+	ctx := context.Background()
+
+	if err := useCase.SendEmailVerification(ctx, 1); err != nil {
+		t.Fatalf("SendEmailVerification() error = %v", err)
+	}
+	token := sentToken(t, mailer, "user@example.com")
+
+	if err := useCase.VerifyEmail(ctx, token+"x"); err != ErrInvalidToken {
+		t.Errorf("VerifyEmail() with a tampered token error = %v, want %v", err, ErrInvalidToken)
+	}
+	if err := useCase.VerifyEmail(ctx, token); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if !users.users[1].EmailVerified {
+		t.Fatal("VerifyEmail() did not verify the email")
+	}
+
+	if err := useCase.SendEmailVerification(ctx, 1); err != ErrEmailAlreadyVerified {
+		t.Errorf("SendEmailVerification() of a verified email error = %v, want %v", err, ErrEmailAlreadyVerified)
+	}
+	if len(mailer.Messages()) != 1 {
+		t.Errorf("sent %d emails, want 1", len(mailer.Messages()))
+	}
+}
+
+func TestEmailVerificationLinkOfChangedEmail(t *testing.T) {
+	useCase, users, mailer := newAuthTest(t)
+	ctx := context.Background()
+
+	if err := useCase.SendEmailVerification(ctx, 1); err != nil {
+		t.Fatalf("SendEmailVerification() error = %v", err)
+	}
+	token := sentToken(t, mailer, "user@example.com")
+
+	user := users.users[1]
+	user.Email = "new@example.com"
+	users.users[1] = user
+
+	if err := useCase.VerifyEmail(ctx, token); err != ErrInvalidToken {
+		t.Errorf("VerifyEmail() error = %v, want %v", err, ErrInvalidToken)
+	}
+	if users.users[1].EmailVerified {
+		t.Error("the link of the old email verified the new email")
+	}
+}
+
+func TestPasswordResetLink(t *testing.T) {
+	useCase, users, mailer := newAuthTest(t)
+	ctx := context.Background()
+
+	_, err := useCase.ForgotPassword(ctx, request.ForgotPassword{Email: "unknown@example.com"})
+	if err != nil {
+		t.Fatalf("ForgotPassword() of an unknown email error = %v", err)
+	}
+	if len(mailer.Messages()) != 0 {
+		t.Fatalf("sent %d emails to an unknown address, want none", len(mailer.Messages()))
+	}
+
+	if _, err := useCase.ForgotPassword(ctx, request.ForgotPassword{Email: "user@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	token := sentToken(t, mailer, "user@example.com")
+
+	reset := request.ResetPassword{Token: token, NewPassword: "new-password", ReNewPassword: "new-password"}
+	if err := useCase.ResetPassword(ctx, reset); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(users.users[1].Password), []byte("new-password")) != nil {
+		t.Fatal("ResetPassword() did not change the password")
+	}
+
+	// the link stops working once the password is changed
+	reset.NewPassword, reset.ReNewPassword = "other-password", "other-password"
+	if err := useCase.ResetPassword(ctx, reset); err != ErrInvalidToken {
+		t.Errorf("second ResetPassword() error = %v, want %v", err, ErrInvalidToken)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(users.users[1].Password), []byte("new-password")) != nil {
+		t.Error("a used link changed the password again")
+	}
+}
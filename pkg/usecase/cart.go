@@ -1,77 +1,176 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
 
+var (
+	ErrMaxPerOrder    = errors.New("quantity is above the limit per order")
+	ErrNotEnoughStock = errors.New("not enough stock")
+)
+
+const defaultMaxPerOrder = 10
+
+const (
+	severityWarning = "warning"
+	severityError   = "error"
+
+	issueProductBlocked  = "product_blocked"
+	issueCategoryBlocked = "category_blocked"
+	issueOutOfStock      = "out_of_stock"
+	issueInsufficientQty = "insufficient_stock"
+	issuePriceChanged    = "price_changed"
+	issueMaxPerOrder     = "max_per_order_exceeded"
+	issueNotInCart       = "not_in_cart"
+)
+
 type CartUseCase struct {
-	cartRepo   interfaces.CartRepository
-	couponRepo interfaces.CouponRepository
+	cartRepo      interfaces.CartRepository
+	couponRepo    interfaces.CouponRepository
+	orderRepo     interfaces.OrderRepository
+	promotionRepo interfaces.PromotionRepository
+	loyaltyRepo   interfaces.LoyaltyRepository
+	walletRepo    interfaces.WalletRepository
+	productRepo   interfaces.ProductRepository
+
+	shipping helper.ShippingPolicy
 }
 
-func NewCartUseCase(cartUseCase interfaces.CartRepository, couponUseCase interfaces.CouponRepository) services.CartUseCase {
+func NewCartUseCase(cartUseCase interfaces.CartRepository, couponUseCase interfaces.CouponRepository, orderRepo interfaces.OrderRepository, promotionRepo interfaces.PromotionRepository, loyaltyRepo interfaces.LoyaltyRepository, walletRepo interfaces.WalletRepository, productRepo interfaces.ProductRepository, shipping helper.ShippingPolicy) services.CartUseCase {
 	return &CartUseCase{
-		cartRepo:   cartUseCase,
-		couponRepo: couponUseCase,
+		cartRepo:      cartUseCase,
+		couponRepo:    couponUseCase,
+		orderRepo:     orderRepo,
+		promotionRepo: promotionRepo,
+		loyaltyRepo:   loyaltyRepo,
+		walletRepo:    walletRepo,
+		productRepo:   productRepo,
+		shipping:      shipping,
 	}
 }
 
-func (cu *CartUseCase) AddToCart(userID, productID int) error {
-	cartItem, err := cu.cartRepo.GetCartItem(userID, productID)
+func (cu *CartUseCase) WithTransaction(repos interfaces.TxRepositories) services.CartUseCase {
+	tx := *cu
+	tx.cartRepo = repos.Cart
+	tx.couponRepo = repos.Coupon
+	tx.orderRepo = repos.Order
+	tx.promotionRepo = repos.Promotion
+	tx.loyaltyRepo = repos.Loyalty
+	tx.walletRepo = repos.Wallet
+	tx.productRepo = repos.Product
+	return &tx
+}
+
+// maxPerOrder is the number of units of the product allowed in one order.
+func maxPerOrder(limit int) int {
+	if limit <= 0 {
+		return defaultMaxPerOrder
+	}
+	return limit
+}
+
+// checkQuantity tells whether qty units of the product can be in one cart line,
+// limited by the product's max per order and by the stock.
+func (cu *CartUseCase) checkQuantity(ctx context.Context, productID, qty int) (response.Product, error) {
+	product, err := cu.productRepo.FindProductByID(ctx, productID)
+	if err != nil {
+		return response.Product{}, fmt.Errorf("Failed to find product :%s", err)
+	}
+	if product.ID == 0 || product.IsBlocked {
+		return product, ErrNoRecord
+	}
+	if qty > maxPerOrder(product.MaxPerOrder) {
+		return product, ErrMaxPerOrder
+	}
+	if qty > product.Stock {
+		return product, ErrNotEnoughStock
+	}
+	return product, nil
+}
+
+func (cu *CartUseCase) AddToCart(ctx context.Context, userID, productID int) error {
+	cartItem, err := cu.cartRepo.GetCartItem(ctx, userID, productID)
 	if err != nil {
 		return fmt.Errorf("Failed to add to cart :%s", err)
 	}
 
 	if cartItem.ID != 0 {
-		err = cu.IncrementQuantity(userID, productID)
-		if err != nil {
-			return fmt.Errorf("Failed to increment quantity :%s", err)
-		}
-		return nil
+		return cu.IncrementQuantity(ctx, userID, productID)
 	}
 
-	cartItem, err = cu.cartRepo.AddToCart(userID, productID)
+	product, err := cu.checkQuantity(ctx, productID, 1)
+	if err != nil {
+		return err
+	}
+
+	cartItem, err = cu.cartRepo.AddToCart(ctx, userID, productID)
 	if err != nil || cartItem.ID == 0 {
 		return fmt.Errorf("Add to cart failed:%s", err)
 	}
+	return cu.keepAddedPrice(ctx, userID, product)
+}
+
+// keepAddedPrice stores the price the product was added at, the promotional
+// price when a promotion is running, so ValidateCart only reports real changes.
+func (cu *CartUseCase) keepAddedPrice(ctx context.Context, userID int, product response.Product) error {
+	promotions, err := cu.promotionRepo.GetActivePromotions(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to fetch active promotions :%s", err)
+	}
+
+	price := helper.EffectivePrice(product.Price, int(product.ID), product.CategoryID, promotions)
+	err = cu.cartRepo.UpdateAddedPrice(ctx, userID, int(product.ID), price)
+	if err != nil {
+		return fmt.Errorf("Failed to update cart price :%s", err)
+	}
 	return nil
 }
 
-func (cu *CartUseCase) ViewCart(userID int) (response.CartItems, error) {
-	cart, err := cu.cartRepo.ViewCart(userID)
+func (cu *CartUseCase) ViewCart(ctx context.Context, userID int) (response.CartItems, error) {
+	cart, err := cu.cartRepo.ViewCart(ctx, userID)
 	if err != nil {
 		return response.CartItems{}, fmt.Errorf("Failed to fetch user cart :%s", err)
 	}
 
+	promotions, err := cu.promotionRepo.GetActivePromotions(ctx, time.Now())
+	if err != nil {
+		return response.CartItems{}, fmt.Errorf("Failed to fetch active promotions :%s", err)
+	}
+	helper.ApplyCartPromotions(cart, promotions)
+
 	var cartItems response.CartItems
 	for _, item := range cart {
 		cartItems.Cart = append(cartItems.Cart, item)
-		cartItems.Total += float32(item.Qty) * float32(item.Price)
+		cartItems.Subtotal += float32(item.Qty) * float32(item.Price)
+	}
+
+	if len(cartItems.Cart) != 0 {
+		cartItems.ShippingFee = cu.shipping.ShippingFee(cartItems.Subtotal)
 	}
 
-	couponDetails, err := cu.couponRepo.CheckAppliedCoupon(userID)
+	appliedCoupons, err := cu.couponRepo.GetAppliedCoupons(ctx, userID)
 	if err != nil {
 		return response.CartItems{}, fmt.Errorf("Failed to fetch coupon details")
 	}
 
-	var discountPrize float64
-
-	if couponDetails.ID != 0 && couponDetails.CouponID != 0 {
-		Coupon, err := cu.couponRepo.FindCouponByID(couponDetails.CouponID)
+	for _, applied := range appliedCoupons {
+		Coupon, err := cu.couponRepo.FindCouponByID(ctx, applied.CouponID)
 		if err != nil {
 			return response.CartItems{}, fmt.Errorf("Failed to find coupon :%s", err)
 		}
 
-		fmt.Println("coupon :", Coupon)
-
 		if !helper.IsCouponValid(Coupon.ValidTill) {
 
-			removedCoupon, err := cu.couponRepo.RemoveCouponFromTracking(Coupon.ID, userID)
+			removedCoupon, err := cu.couponRepo.RemoveCouponFromTracking(ctx, Coupon.ID, userID)
 			if err != nil {
 				return response.CartItems{}, err
 			}
@@ -79,25 +178,226 @@ func (cu *CartUseCase) ViewCart(userID int) (response.CartItems, error) {
 			if removedCoupon.ID == 0 {
 				return response.CartItems{}, fmt.Errorf("failed to verify removed coupon")
 			}
+			continue
+		}
+
+		evaluation, err := evaluateUserCoupon(ctx, cu.couponRepo, cu.orderRepo, userID, Coupon, cart)
+		if err != nil {
+			return response.CartItems{}, err
+		}
 
+		cartItems.AppliedCoupons = append(cartItems.AppliedCoupons, evaluation)
+		for i, line := range evaluation.Lines {
+			cartItems.Cart[i].Discount += line.Discount
+		}
+		if evaluation.FreeShipping {
+			cartItems.ShippingFee = 0
 		}
+	}
 
-		if cartItems.Total > float32(Coupon.MinOrderValue) {
-			discountPrize = (float64(cartItems.Total) * Coupon.DiscountPercent) / 100
-			if discountPrize > Coupon.DiscountMaxAmount {
-				discountPrize = Coupon.DiscountMaxAmount
-			}
+	// stacked coupons never take a line below zero
+	for i, item := range cartItems.Cart {
+		lineTotal := float32(item.Qty) * float32(item.Price)
+		if item.Discount > lineTotal {
+			cartItems.Cart[i].Discount = lineTotal
 		}
+		cartItems.Discount += cartItems.Cart[i].Discount
 	}
+	cartItems.Total = cartItems.Subtotal - cartItems.Discount + cartItems.ShippingFee
 
-	cartItems.Discount = float32(discountPrize)
-	cartItems.Total = cartItems.Total - float32(discountPrize)
+	err = cu.applyPoints(ctx, userID, &cartItems)
+	if err != nil {
+		return response.CartItems{}, err
+	}
 
-	return cartItems, err
+	err = cu.applyWallet(ctx, userID, &cartItems)
+	if err != nil {
+		return response.CartItems{}, err
+	}
+
+	return cartItems, nil
 }
 
-func (cu *CartUseCase) RemoveFromCart(userID, productID int) error {
-	cartItem, err := cu.cartRepo.RemoveFromCart(userID, productID)
+// applyWallet pays part of the cart from the wallet when the user applied it, the
+// payable rest is paid online or on delivery. The wallet share of every line is kept
+// so it can be refunded to the wallet.
+func (cu *CartUseCase) applyWallet(ctx context.Context, userID int, cartItems *response.CartItems) error {
+	cartItems.Payable = cartItems.Total
+	if len(cartItems.Cart) == 0 {
+		return nil
+	}
+
+	applied, err := cu.walletRepo.FindAppliedWallet(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch applied wallet :%s", err)
+	}
+	if applied.ID == 0 {
+		return nil
+	}
+
+	wallet, err := cu.orderRepo.FindUserWalletByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to find user wallet :%s", err)
+	}
+
+	amount := wallet.Amount
+	if applied.Amount > 0 && applied.Amount < amount {
+		amount = applied.Amount
+	}
+	if amount > cartItems.Total {
+		amount = cartItems.Total
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	weights := make([]int64, len(cartItems.Cart))
+	for i, item := range cartItems.Cart {
+		weights[i] = helper.ToPaise(float32(item.Qty)*float32(item.Price)-item.Discount) - helper.ToPaise(float32(item.PointsRedeemed*loyaltyPointValue))
+	}
+	for i, share := range helper.ApportionDiscount(helper.ToPaise(amount), weights) {
+		cartItems.Cart[i].WalletAmount = helper.FromPaise(share)
+	}
+
+	cartItems.WalletAmount = amount
+	cartItems.Payable = helper.FromPaise(helper.ToPaise(cartItems.Total) - helper.ToPaise(amount))
+	return nil
+}
+
+// applyPoints pays part of the cart with the points the user applied, limited by
+// the balance and by maxPointsRedeemPercent of the discounted subtotal.
+func (cu *CartUseCase) applyPoints(ctx context.Context, userID int, cartItems *response.CartItems) error {
+	if len(cartItems.Cart) == 0 {
+		return nil
+	}
+
+	applied, err := cu.loyaltyRepo.FindAppliedPoints(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch applied points :%s", err)
+	}
+	if applied.ID == 0 {
+		return nil
+	}
+
+	balance, err := cu.loyaltyRepo.GetLoyaltyBalance(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to get points balance :%s", err)
+	}
+
+	points := applied.Points
+	if points > balance {
+		points = balance
+	}
+	maxPoints := int((cartItems.Subtotal - cartItems.Discount) * maxPointsRedeemPercent / 100 / loyaltyPointValue)
+	if points > maxPoints {
+		points = maxPoints
+	}
+	if points <= 0 {
+		return nil
+	}
+
+	weights := make([]int64, len(cartItems.Cart))
+	for i, item := range cartItems.Cart {
+		weights[i] = helper.ToPaise(float32(item.Qty)*float32(item.Price) - item.Discount)
+	}
+	for i, share := range helper.ApportionDiscount(int64(points), weights) {
+		cartItems.Cart[i].PointsRedeemed = int(share)
+	}
+
+	cartItems.PointsApplied = points
+	cartItems.PointsValue = float32(points * loyaltyPointValue)
+	cartItems.Total -= cartItems.PointsValue
+	return nil
+}
+
+// ValidateCart checks every cart line against the current product. Lines whose product
+// or category got blocked or that went out of stock are removed, a quantity above the
+// stock is flagged as an error and a changed price is reported once and kept as the
+// new added price.
+func (cu *CartUseCase) ValidateCart(ctx context.Context, userID int) ([]response.CartIssue, error) {
+	lines, err := cu.cartRepo.GetCartAvailability(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch cart availability :%s", err)
+	}
+
+	promotions, err := cu.promotionRepo.GetActivePromotions(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch active promotions :%s", err)
+	}
+
+	issues := make([]response.CartIssue, 0)
+	for _, line := range lines {
+		issue := response.CartIssue{
+			ProductID:   line.ProductID,
+			ProductName: line.ProductName,
+		}
+
+		switch {
+		case line.IsBlocked:
+			issue.Code, issue.Message = issueProductBlocked, "Product is no longer available, removed from the cart"
+		case line.CategoryBlocked:
+			issue.Code, issue.Message = issueCategoryBlocked, "Product category is no longer available, removed from the cart"
+		case line.Stock <= 0:
+			issue.Code, issue.Message = issueOutOfStock, "Product is out of stock, removed from the cart"
+		}
+
+		if issue.Code != "" {
+			err = cu.RemoveFromCart(ctx, userID, int(line.ProductID))
+			if err != nil {
+				return nil, err
+			}
+			issue.Severity = severityWarning
+			issue.Removed = true
+			issues = append(issues, issue)
+			continue
+		}
+
+		if limit := maxPerOrder(line.MaxPerOrder); line.Qty > limit {
+			issues = append(issues, response.CartIssue{
+				ProductID:   line.ProductID,
+				ProductName: line.ProductName,
+				Code:        issueMaxPerOrder,
+				Severity:    severityError,
+				Message:     fmt.Sprintf("Only %d allowed per order, reduce the quantity", limit),
+				Available:   limit,
+			})
+		} else if line.Qty > line.Stock {
+			issue.Code, issue.Severity = issueInsufficientQty, severityError
+			issue.Message = fmt.Sprintf("Only %d left in stock, reduce the quantity", line.Stock)
+			issue.Available = line.Stock
+			issues = append(issues, issue)
+		}
+
+		// the price charged is the promotional one, as in ViewCart
+		price := helper.EffectivePrice(line.Price, int(line.ProductID), line.CategoryID, promotions)
+		if line.AddedPrice == price {
+			continue
+		}
+
+		// lines added before the price was kept take the current price silently
+		if line.AddedPrice != 0 {
+			issues = append(issues, response.CartIssue{
+				ProductID:   line.ProductID,
+				ProductName: line.ProductName,
+				Code:        issuePriceChanged,
+				Severity:    severityWarning,
+				Message:     fmt.Sprintf("Price changed from %d to %d", line.AddedPrice, price),
+				OldPrice:    line.AddedPrice,
+				NewPrice:    price,
+			})
+		}
+
+		err = cu.cartRepo.UpdateAddedPrice(ctx, userID, int(line.ProductID), price)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to update cart price :%s", err)
+		}
+	}
+
+	return issues, nil
+}
+
+func (cu *CartUseCase) RemoveFromCart(ctx context.Context, userID, productID int) error {
+	cartItem, err := cu.cartRepo.RemoveFromCart(ctx, userID, productID)
 	if err != nil {
 		return fmt.Errorf("Remove from cart failed :%s", err)
 	}
@@ -107,8 +407,8 @@ func (cu *CartUseCase) RemoveFromCart(userID, productID int) error {
 	return nil
 }
 
-func (cu *CartUseCase) IncrementQuantity(userID, productID int) error {
-	cartItem, err := cu.cartRepo.GetCartItem(userID, productID)
+func (cu *CartUseCase) IncrementQuantity(ctx context.Context, userID, productID int) error {
+	cartItem, err := cu.cartRepo.GetCartItem(ctx, userID, productID)
 	if err != nil || cartItem.ID == 0 {
 		return fmt.Errorf("Quantity updation failed :%s", err)
 	}
@@ -116,15 +416,20 @@ func (cu *CartUseCase) IncrementQuantity(userID, productID int) error {
 	qty := cartItem.Qty
 	newQty := qty + 1
 
-	cartItem, err = cu.cartRepo.IncrementQuantity(newQty, userID, productID)
+	_, err = cu.checkQuantity(ctx, productID, newQty)
+	if err != nil {
+		return err
+	}
+
+	cartItem, err = cu.cartRepo.IncrementQuantity(ctx, newQty, userID, productID)
 	if err != nil || cartItem.ID == 0 || newQty != cartItem.Qty {
 		return fmt.Errorf("Quantity updation failed : %s", err)
 	}
 	return nil
 }
 
-func (cu *CartUseCase) DecrementQuantity(userID, productID int) error {
-	cartItem, err := cu.cartRepo.GetCartItem(userID, productID)
+func (cu *CartUseCase) DecrementQuantity(ctx context.Context, userID, productID int) error {
+	cartItem, err := cu.cartRepo.GetCartItem(ctx, userID, productID)
 	if err != nil || cartItem.ID == 0 {
 		return fmt.Errorf("Quantity updation failed :%s", err)
 
@@ -135,15 +440,129 @@ func (cu *CartUseCase) DecrementQuantity(userID, productID int) error {
 	qty := cartItem.Qty
 	newQty := qty - 1
 
-	cartItem, err = cu.cartRepo.DecrementQuantity(newQty, userID, productID)
+	cartItem, err = cu.cartRepo.DecrementQuantity(ctx, newQty, userID, productID)
 	if err != nil || cartItem.ID == 0 || newQty != cartItem.Qty {
 		return fmt.Errorf("Quantity updation failed :%s", err)
 	}
 	return nil
 }
 
-func (cu *CartUseCase) DeleteUserCart(userID int) error {
-	_, err := cu.cartRepo.DeleteCart(userID)
+// UpdateCartQuantities sets the quantity of several cart lines, a zero quantity
+// removes the line. Every line is checked first and nothing changes when any line
+// is not in the cart or is over the stock or the limit per order.
+func (cu *CartUseCase) UpdateCartQuantities(ctx context.Context, userID int, items []request.CartQuantity) ([]response.CartIssue, error) {
+	issues := make([]response.CartIssue, 0)
+	for _, item := range items {
+		cartItem, err := cu.cartRepo.GetCartItem(ctx, userID, item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to find cart item :%s", err)
+		}
+		if cartItem.ID == 0 {
+			issues = append(issues, response.CartIssue{
+				ProductID: uint(item.ProductID),
+				Code:      issueNotInCart,
+				Severity:  severityError,
+				Message:   "Product is not in the cart",
+			})
+			continue
+		}
+		if item.Qty == 0 {
+			continue
+		}
+
+		product, err := cu.checkQuantity(ctx, item.ProductID, item.Qty)
+		issue := response.CartIssue{
+			ProductID:   uint(item.ProductID),
+			ProductName: product.ProductName,
+			Severity:    severityError,
+		}
+		switch err {
+		case nil:
+			continue
+		case ErrNoRecord:
+			issue.Code, issue.Message = issueProductBlocked, "Product is no longer available"
+		case ErrMaxPerOrder:
+			issue.Code, issue.Available = issueMaxPerOrder, maxPerOrder(product.MaxPerOrder)
+			issue.Message = fmt.Sprintf("Only %d allowed per order", issue.Available)
+		case ErrNotEnoughStock:
+			issue.Code, issue.Available = issueInsufficientQty, product.Stock
+			issue.Message = fmt.Sprintf("Only %d left in stock", product.Stock)
+		default:
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+
+	if len(issues) != 0 {
+		return issues, nil
+	}
+
+	for _, item := range items {
+		if item.Qty == 0 {
+			err := cu.RemoveFromCart(ctx, userID, item.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		cartItem, err := cu.cartRepo.UpdateQuantity(ctx, item.Qty, userID, item.ProductID)
+		if err != nil || cartItem.ID == 0 || cartItem.Qty != item.Qty {
+			return nil, fmt.Errorf("Quantity updation failed :%s", err)
+		}
+	}
+
+	return nil, nil
+}
+
+// SaveForLater moves a cart line to the user's wishlist.
+func (cu *CartUseCase) SaveForLater(ctx context.Context, userID, productID int) error {
+	cartItem, err := cu.cartRepo.GetCartItem(ctx, userID, productID)
+	if err != nil {
+		return fmt.Errorf("Failed to find cart item :%s", err)
+	}
+	if cartItem.ID == 0 {
+		return ErrNoRecord
+	}
+
+	wishListed, err := cu.productRepo.IsWishListed(ctx, userID, productID)
+	if err != nil {
+		return fmt.Errorf("Failed to find wishlist item :%s", err)
+	}
+	if !wishListed {
+		err = cu.productRepo.AddToWishList(ctx, userID, productID)
+		if err != nil {
+			return fmt.Errorf("Failed to add to wishlist :%s", err)
+		}
+	}
+
+	return cu.RemoveFromCart(ctx, userID, productID)
+}
+
+// MoveToCart moves a product from the user's wishlist to the cart.
+func (cu *CartUseCase) MoveToCart(ctx context.Context, userID, productID int) error {
+	wishListed, err := cu.productRepo.IsWishListed(ctx, userID, productID)
+	if err != nil {
+		return fmt.Errorf("Failed to find wishlist item :%s", err)
+	}
+	if !wishListed {
+		return ErrNoRecord
+	}
+
+	err = cu.AddToCart(ctx, userID, productID)
+	if err != nil {
+		return err
+	}
+
+	err = cu.productRepo.RemoveFromWishList(ctx, userID, productID)
+	if err != nil {
+		return fmt.Errorf("Failed to remove from wishlist :%s", err)
+	}
+	return nil
+}
+
+func (cu *CartUseCase) DeleteUserCart(ctx context.Context, userID int) error {
+	_, err := cu.cartRepo.DeleteCart(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete user cart  : %s", err)
 	}
@@ -151,3 +570,295 @@ func (cu *CartUseCase) DeleteUserCart(userID int) error {
 	return nil
 
 }
+
+func (cu *CartUseCase) AddToGuestCart(ctx context.Context, cartID string, productID int) error {
+	cartItem, err := cu.cartRepo.GetGuestCartItem(ctx, cartID, productID)
+	if err != nil {
+		return fmt.Errorf("Failed to add to cart :%s", err)
+	}
+
+	if cartItem.ID != 0 {
+		return cu.IncrementGuestQuantity(ctx, cartID, productID)
+	}
+
+	_, err = cu.checkQuantity(ctx, productID, 1)
+	if err != nil {
+		return err
+	}
+
+	cartItem, err = cu.cartRepo.AddToGuestCart(ctx, cartID, productID)
+	if err != nil || cartItem.ID == 0 {
+		return fmt.Errorf("Add to cart failed:%s", err)
+	}
+	return nil
+}
+
+// ViewGuestCart prices the guest cart like ViewCart. The applied coupons are
+// evaluated as for a customer without orders, points and wallet need a login.
+func (cu *CartUseCase) ViewGuestCart(ctx context.Context, cartID string) (response.CartItems, error) {
+	cart, err := cu.cartRepo.ViewGuestCart(ctx, cartID)
+	if err != nil {
+		return response.CartItems{}, fmt.Errorf("Failed to fetch guest cart :%s", err)
+	}
+
+	promotions, err := cu.promotionRepo.GetActivePromotions(ctx, time.Now())
+	if err != nil {
+		return response.CartItems{}, fmt.Errorf("Failed to fetch active promotions :%s", err)
+	}
+	helper.ApplyCartPromotions(cart, promotions)
+
+	var cartItems response.CartItems
+	for _, item := range cart {
+		cartItems.Cart = append(cartItems.Cart, item)
+		cartItems.Subtotal += float32(item.Qty) * float32(item.Price)
+	}
+
+	if len(cartItems.Cart) != 0 {
+		cartItems.ShippingFee = cu.shipping.ShippingFee(cartItems.Subtotal)
+	}
+
+	guestCoupons, err := cu.cartRepo.GetGuestCoupons(ctx, cartID)
+	if err != nil {
+		return response.CartItems{}, fmt.Errorf("Failed to fetch coupon details :%s", err)
+	}
+
+	for _, applied := range guestCoupons {
+		Coupon, err := cu.couponRepo.FindCouponByID(ctx, applied.CouponID)
+		if err != nil {
+			return response.CartItems{}, fmt.Errorf("Failed to find coupon :%s", err)
+		}
+
+		if Coupon.IsBlocked || !helper.IsCouponValid(Coupon.ValidTill) {
+			_, err := cu.cartRepo.RemoveGuestCoupon(ctx, cartID, applied.CouponID)
+			if err != nil {
+				return response.CartItems{}, fmt.Errorf("Failed to remove coupon :%s", err)
+			}
+			continue
+		}
+
+		evaluation, err := evaluateUserCoupon(ctx, cu.couponRepo, cu.orderRepo, 0, Coupon, cart)
+		if err != nil {
+			return response.CartItems{}, err
+		}
+
+		cartItems.AppliedCoupons = append(cartItems.AppliedCoupons, evaluation)
+		for i, line := range evaluation.Lines {
+			cartItems.Cart[i].Discount += line.Discount
+		}
+		if evaluation.FreeShipping {
+			cartItems.ShippingFee = 0
+		}
+	}
+
+	for i, item := range cartItems.Cart {
+		lineTotal := float32(item.Qty) * float32(item.Price)
+		if item.Discount > lineTotal {
+			cartItems.Cart[i].Discount = lineTotal
+		}
+		cartItems.Discount += cartItems.Cart[i].Discount
+	}
+	cartItems.Total = cartItems.Subtotal - cartItems.Discount + cartItems.ShippingFee
+	cartItems.Payable = cartItems.Total
+
+	return cartItems, nil
+}
+
+func (cu *CartUseCase) RemoveFromGuestCart(ctx context.Context, cartID string, productID int) error {
+	cartItem, err := cu.cartRepo.RemoveFromGuestCart(ctx, cartID, productID)
+	if err != nil {
+		return fmt.Errorf("Remove from cart failed :%s", err)
+	}
+	if cartItem.ID == 0 {
+		return fmt.Errorf("Failed to verify removed product")
+	}
+	return nil
+}
+
+func (cu *CartUseCase) IncrementGuestQuantity(ctx context.Context, cartID string, productID int) error {
+	cartItem, err := cu.cartRepo.GetGuestCartItem(ctx, cartID, productID)
+	if err != nil || cartItem.ID == 0 {
+		return fmt.Errorf("Quantity updation failed :%s", err)
+	}
+
+	newQty := cartItem.Qty + 1
+
+	_, err = cu.checkQuantity(ctx, productID, newQty)
+	if err != nil {
+		return err
+	}
+
+	cartItem, err = cu.cartRepo.UpdateGuestCartQuantity(ctx, newQty, cartID, productID)
+	if err != nil || cartItem.ID == 0 || newQty != cartItem.Qty {
+		return fmt.Errorf("Quantity updation failed : %s", err)
+	}
+	return nil
+}
+
+func (cu *CartUseCase) DecrementGuestQuantity(ctx context.Context, cartID string, productID int) error {
+	cartItem, err := cu.cartRepo.GetGuestCartItem(ctx, cartID, productID)
+	if err != nil || cartItem.ID == 0 {
+		return fmt.Errorf("Quantity updation failed :%s", err)
+
+	} else if cartItem.Qty == 1 {
+		return nil
+	}
+
+	newQty := cartItem.Qty - 1
+
+	cartItem, err = cu.cartRepo.UpdateGuestCartQuantity(ctx, newQty, cartID, productID)
+	if err != nil || cartItem.ID == 0 || newQty != cartItem.Qty {
+		return fmt.Errorf("Quantity updation failed :%s", err)
+	}
+	return nil
+}
+
+// ApplyGuestCoupon applies a coupon on the guest cart. Only the checks that do not
+// depend on the customer run here, the per user limits are checked on merge.
+func (cu *CartUseCase) ApplyGuestCoupon(ctx context.Context, cartID string, couponCode string) error {
+	coupon, err := cu.couponRepo.FindCouponByCode(ctx, couponCode)
+	if err != nil {
+		return fmt.Errorf("Failed to find coupon  :%s", err)
+	}
+	if coupon.IsBlocked || !helper.IsCouponValid(coupon.ValidTill) || coupon.ID == 0 {
+		return fmt.Errorf("coupon cant use ,invalid coupon")
+	}
+
+	totalUsed, err := cu.couponRepo.CountCouponUsage(ctx, coupon.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to count coupon usage :%s", err)
+	}
+	if coupon.UsageLimit > 0 && totalUsed >= coupon.UsageLimit {
+		return fmt.Errorf("Failed coupon usage limit reached")
+	}
+
+	guestCoupons, err := cu.cartRepo.GetGuestCoupons(ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch applied coupons :%s", err)
+	}
+
+	stack := coupon.IsStackable
+	for _, applied := range guestCoupons {
+		if applied.CouponID == coupon.ID {
+			return nil
+		}
+		previousCoupon, err := cu.couponRepo.FindCouponByID(ctx, applied.CouponID)
+		if err != nil {
+			return fmt.Errorf("Failed to find applied coupon :%s", err)
+		}
+		stack = stack && previousCoupon.IsStackable
+	}
+
+	if !stack && len(guestCoupons) != 0 {
+		err = cu.cartRepo.RemoveGuestCoupons(ctx, cartID)
+		if err != nil {
+			return fmt.Errorf("Failed to change coupon : %s", err)
+		}
+	}
+
+	guestCoupon, err := cu.cartRepo.AddGuestCoupon(ctx, cartID, coupon.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to apply coupon :%s", err)
+	}
+	if guestCoupon.ID == 0 {
+		return fmt.Errorf("Failed to verify applied coupon")
+	}
+	return nil
+}
+
+func (cu *CartUseCase) RemoveGuestCoupon(ctx context.Context, cartID string, couponID int) error {
+	removedCoupon, err := cu.cartRepo.RemoveGuestCoupon(ctx, cartID, couponID)
+	if err != nil {
+		return fmt.Errorf("Failed to remove coupon :%s", err)
+	}
+	if removedCoupon.ID == 0 {
+		return fmt.Errorf("Failed to verify the removed coupon")
+	}
+	return nil
+}
+
+// MergeGuestCart moves the guest cart into the user's cart after login or sign up.
+// When both carts hold a product the larger quantity is kept, so an item added again
+// as a guest is not counted twice. The quantity is checked like UpdateCartQuantities
+// and lowered to the stock and the limit per order, a product no longer sold or out
+// of stock is not carried over. Guest coupons are applied with the user's limits,
+// a coupon the user can't use is dropped. The guest cart is deleted afterwards.
+func (cu *CartUseCase) MergeGuestCart(ctx context.Context, userID int, cartID string) error {
+	guestCart, err := cu.cartRepo.ViewGuestCart(ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch guest cart :%s", err)
+	}
+
+	for _, item := range guestCart {
+		productID := int(item.ProductID)
+		cartItem, err := cu.cartRepo.GetCartItem(ctx, userID, productID)
+		if err != nil {
+			return fmt.Errorf("Failed to find cart item :%s", err)
+		}
+		if item.Qty <= cartItem.Qty {
+			continue
+		}
+
+		qty := item.Qty
+		product, err := cu.checkQuantity(ctx, productID, qty)
+		switch err {
+		case nil:
+		case ErrNoRecord:
+			helper.LoggerFrom(ctx).Warn("guest cart item not carried over", "product_id", productID, "error", err)
+			continue
+		case ErrMaxPerOrder, ErrNotEnoughStock:
+			qty = min(maxPerOrder(product.MaxPerOrder), product.Stock)
+			helper.LoggerFrom(ctx).Warn("guest cart quantity lowered", "product_id", productID, "qty", item.Qty, "allowed", qty)
+		default:
+			return err
+		}
+		if qty <= cartItem.Qty {
+			continue
+		}
+
+		if cartItem.ID == 0 {
+			cartItem, err = cu.cartRepo.AddToCart(ctx, userID, productID)
+			if err != nil || cartItem.ID == 0 {
+				return fmt.Errorf("Add to cart failed:%s", err)
+			}
+
+			err = cu.keepAddedPrice(ctx, userID, product)
+			if err != nil {
+				return err
+			}
+			if qty <= cartItem.Qty {
+				continue
+			}
+		}
+
+		cartItem, err = cu.cartRepo.IncrementQuantity(ctx, qty, userID, productID)
+		if err != nil || cartItem.ID == 0 || cartItem.Qty != qty {
+			return fmt.Errorf("Quantity updation failed : %s", err)
+		}
+	}
+
+	guestCoupons, err := cu.cartRepo.GetGuestCoupons(ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch guest coupons :%s", err)
+	}
+
+	for _, applied := range guestCoupons {
+		coupon, err := cu.couponRepo.FindCouponByID(ctx, applied.CouponID)
+		if err != nil {
+			return fmt.Errorf("Failed to find coupon :%s", err)
+		}
+		if coupon.ID == 0 || coupon.IsBlocked || !helper.IsCouponValid(coupon.ValidTill) {
+			continue
+		}
+
+		err = applyUserCoupon(ctx, cu.couponRepo, cu.orderRepo, userID, coupon)
+		if err != nil {
+			helper.LoggerFrom(ctx).Warn("guest coupon not carried over", "coupon", coupon.Code, "error", err)
+		}
+	}
+
+	err = cu.cartRepo.DeleteGuestCart(ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("Failed to delete guest cart :%s", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+// fakeCartRepo keeps the guest cart and the user's cart lines by product, only
+// the methods of the merge are implemented.
+type fakeCartRepo struct {
+	interfaces.CartRepository
+	guest        []response.Cart
+	lines        map[int]response.Cart
+	guestDeleted bool
+}
+
+func (f *fakeCartRepo) ViewGuestCart(ctx context.Context, cartID string) ([]response.Cart, error) {
+	return f.guest, nil
+}
+
+func (f *fakeCartRepo) GetCartItem(ctx context.Context, userID, productID int) (response.Cart, error) {
+	return f.lines[productID], nil
+}
+
+func (f *fakeCartRepo) AddToCart(ctx context.Context, userID, productID int) (response.Cart, error) {
+	line := response.Cart{ID: uint(len(f.lines) + 1), ProductID: uint(productID), Qty: 1}
+	f.lines[productID] = line
+	return line, nil
+}
+
+func (f *fakeCartRepo) IncrementQuantity(ctx context.Context, qty, userID, productID int) (response.Cart, error) {
+	line, ok := f.lines[productID]
+	if !ok {
+		return response.Cart{}, nil
+	}
+	line.Qty = qty
+	f.lines[productID] = line
+	return line, nil
+}
+
+func (f *fakeCartRepo) UpdateAddedPrice(ctx context.Context, userID, productID, price int) error {
+	line := f.lines[productID]
+	line.Price = price
+	f.lines[productID] = line
+	return nil
+}
+
+func (f *fakeCartRepo) GetGuestCoupons(ctx context.Context, cartID string) ([]response.GuestCoupon, error) {
+	return nil, nil
+}
+
+func (f *fakeCartRepo) DeleteGuestCart(ctx context.Context, cartID string) error {
+	f.guestDeleted = true
+	return nil
+}
+
+type fakeCatalogRepo struct {
+	interfaces.ProductRepository
+	products map[int]response.Product
+}
+
+func (f fakeCatalogRepo) FindProductByID(ctx context.Context, productID int) (response.Product, error) {
+	return f.products[productID], nil
+}
+
+type fakePromotionRepo struct {
+	interfaces.PromotionRepository
+}
+
+func (fakePromotionRepo) GetActivePromotions(ctx context.Context, currentTime time.Time) ([]response.Promotion, error) {
+	return nil, nil
+}
+
+func TestMergeGuestCart(t *testing.T) {
+	products := map[int]response.Product{
+		1: {ID: 1, Price: 500, Stock: 50},
+		2: {ID: 2, Price: 800, Stock: 3},
+		3: {ID: 3, Price: 300, Stock: 50, MaxPerOrder: 2},
+		4: {ID: 4, Price: 900, Stock: 50, IsBlocked: true},
+		5: {ID: 5, Price: 200, Stock: 0},
+		6: {ID: 6, Price: 700, Stock: 4},
+	}
+
+	testCases := []struct {
+		name     string
+		guestQty int
+		userQty  int // 0 when the user's cart does not have the product
+		want     int // 0 when the product must not be in the user's cart
+	}{
+		{name: "new product keeps the guest quantity", guestQty: 4, want: 4},
+		{name: "larger guest quantity is lowered to the stock", guestQty: 5, want: 3},
+		{name: "larger guest quantity is lowered to the limit per order", guestQty: 5, userQty: 1, want: 2},
+		{name: "blocked product is not carried over", guestQty: 1},
+		{name: "out of stock product is not carried over", guestQty: 2},
+		{name: "user's larger quantity is kept", guestQty: 2, userQty: 3, want: 3},
+	}
+
+	for i, tc := range testCases {
+		productID := i + 1
+		t.Run(tc.name, func(t *testing.T) {
+			cartRepo := &fakeCartRepo{
+				guest: []response.Cart{{ProductID: uint(productID), Qty: tc.guestQty}},
+				lines: map[int]response.Cart{},
+			}
+			if tc.userQty != 0 {
+				cartRepo.lines[productID] = response.Cart{ID: 1, ProductID: uint(productID), Qty: tc.userQty}
+			}
+			cu := NewCartUseCase(cartRepo, nil, nil, fakePromotionRepo{}, nil, nil, fakeCatalogRepo{products: products}, helper.ShippingPolicy{}).(*CartUseCase)
+
+			if err := cu.MergeGuestCart(context.Background(), 1, "guest"); err != nil {
+				t.Fatalf("MergeGuestCart() error = %v", err)
+			}
+
+			line, ok := cartRepo.lines[productID]
+			if tc.want == 0 {
+				if ok {
+					t.Errorf("cart has %d units of product %d, want none", line.Qty, productID)
+				}
+			} else if line.Qty != tc.want {
+				t.Errorf("cart qty = %d, want %d", line.Qty, tc.want)
+			}
+			if ok && tc.userQty == 0 && line.Price != products[productID].Price {
+				t.Errorf("added price = %d, want %d", line.Price, products[productID].Price)
+			}
+			if !cartRepo.guestDeleted {
+				t.Error("the guest cart was not deleted")
+			}
+		})
+	}
+}
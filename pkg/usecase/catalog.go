@@ -0,0 +1,584 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/domain"
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+const (
+	maxImportRows        = 10000
+	importProgressEvery  = 100
+	catalogExportBatch   = 500
+	productImportDone    = "completed"
+	productImportFailed  = "failed"
+	importImageSeparator = "|"
+	productImportJob     = "product-import"
+)
+
+// catalogColumns are the csv columns of the import and the export.
+var catalogColumns = []string{"sku", "product_name", "product_description", "category", "brand",
+	"price", "mrp", "stock", "max_per_order", "images", "is_blocked"}
+
+// requiredCatalogColumns must be in the header of an imported csv.
+var requiredCatalogColumns = []string{"product_name", "product_description", "category", "price"}
+
+type catalogUseCase struct {
+	catalogRepo interfaces.CatalogRepository
+	productRepo interfaces.ProductRepository
+	jobs        services.JobUseCase
+}
+
+func NewCatalogUseCase(catalogRepo interfaces.CatalogRepository, productRepo interfaces.ProductRepository, jobs services.JobUseCase) services.CatalogUseCase {
+	cu := &catalogUseCase{
+		catalogRepo: catalogRepo,
+		productRepo: productRepo,
+		jobs:        jobs,
+	}
+	jobs.Handle(productImportJob, func(ctx context.Context, job response.Job) error {
+		var payload request.ProductImportJob
+		if err := DecodeJob(job, &payload); err != nil {
+			return err
+		}
+		return cu.ProcessProductImport(ctx, payload.ImportID)
+	})
+	return cu
+}
+
+func (cu *catalogUseCase) ImportProducts(ctx context.Context, format, fileName string, file io.Reader, dryRun bool) (response.ProductImport, error) {
+	var rows []request.ProductImportRow
+	var rowErrors []response.ProductImportError
+	var err error
+	switch format {
+	case "csv":
+		rows, rowErrors, err = parseCatalogCSV(file)
+	case "json":
+		rows, rowErrors, err = parseCatalogJSON(file)
+	default:
+		return response.ProductImport{}, fmt.Errorf("%w : format must be csv or json", ErrInvalidImportFile)
+	}
+	if err != nil {
+		return response.ProductImport{}, err
+	}
+
+	total := len(rows) + len(rowErrors)
+	if total == 0 {
+		return response.ProductImport{}, fmt.Errorf("%w : the file has no products", ErrInvalidImportFile)
+	}
+	if total > maxImportRows {
+		return response.ProductImport{}, fmt.Errorf("%w : at most %d products can be imported at once", ErrInvalidImportFile, maxImportRows)
+	}
+
+	valid, invalid := validateCatalogRows(rows)
+	rowErrors = append(rowErrors, invalid...)
+	failedLines := make(map[int]bool)
+	for _, rowError := range rowErrors {
+		failedLines[rowError.Line] = true
+	}
+
+	encodedRows, err := json.Marshal(valid)
+	if err != nil {
+		return response.ProductImport{}, err
+	}
+	encodedErrors, err := encodeImportErrors(rowErrors)
+	if err != nil {
+		return response.ProductImport{}, err
+	}
+
+	productImport, err := cu.catalogRepo.InsertProductImport(ctx, domain.ProductImport{
+		Format:        format,
+		FileName:      fileName,
+		DryRun:        dryRun,
+		Rows:          string(encodedRows),
+		TotalRows:     total,
+		ProcessedRows: len(failedLines),
+		Failed:        len(failedLines),
+		Errors:        encodedErrors,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		return response.ProductImport{}, fmt.Errorf("Failed to save product import :%s", err)
+	}
+	if productImport.ID == 0 {
+		return response.ProductImport{}, fmt.Errorf("Failed to verify product import")
+	}
+
+	// the import only starts while queued, a second run of the job would do nothing
+	_, err = cu.jobs.Enqueue(ctx, productImportJob, request.ProductImportJob{ImportID: int(productImport.ID)}, request.JobOptions{
+		UniqueKey:   fmt.Sprintf("%s:%d", productImportJob, productImport.ID),
+		MaxAttempts: 1,
+	})
+	if err != nil {
+		return response.ProductImport{}, fmt.Errorf("Failed to queue product import :%s", err)
+	}
+
+	return withImportReport(productImport), nil
+}
+
+// ProcessProductImport saves the rows of a queued import, a row that can not be
+// saved is added to the report and the import goes on with the next row.
+func (cu *catalogUseCase) ProcessProductImport(ctx context.Context, importID int) error {
+	productImport, err := cu.catalogRepo.StartProductImport(ctx, importID, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to start product import :%s", err)
+	}
+	if productImport.ID == 0 {
+		return nil
+	}
+
+	var rows []request.ProductImportRow
+	encodedRows, err := cu.catalogRepo.GetProductImportRows(ctx, importID)
+	if err == nil {
+		err = json.Unmarshal([]byte(encodedRows), &rows)
+	}
+	if err != nil {
+		productImport.Status = productImportFailed
+		productImport.Error = fmt.Sprintf("Failed to read the rows :%s", err)
+		return cu.catalogRepo.FinishProductImport(ctx, productImport, time.Now())
+	}
+
+	var rowErrors []response.ProductImportError
+	if productImport.RowErrors != "" {
+		if err := json.Unmarshal([]byte(productImport.RowErrors), &rowErrors); err != nil {
+			return fmt.Errorf("Failed to read the import report :%s", err)
+		}
+	}
+
+	categories := make(map[string]response.Category)
+	for i, row := range rows {
+		created, rowError := cu.importProduct(ctx, row, productImport.DryRun, categories)
+		switch {
+		case rowError != nil:
+			rowErrors = append(rowErrors, *rowError)
+			productImport.Failed++
+		case created:
+			productImport.Created++
+		default:
+			productImport.Updated++
+		}
+		productImport.ProcessedRows++
+
+		if (i+1)%importProgressEvery == 0 {
+			if err := cu.catalogRepo.UpdateProductImportProgress(ctx, productImport); err != nil {
+				helper.LoggerFrom(ctx).Warn("product import progress not saved", "import_id", productImport.ID, "error", err)
+			}
+		}
+	}
+
+	productImport.Status = productImportDone
+	if productImport.RowErrors, err = encodeImportErrors(rowErrors); err != nil {
+		return err
+	}
+	if err := cu.catalogRepo.FinishProductImport(ctx, productImport, time.Now()); err != nil {
+		return fmt.Errorf("Failed to finish product import :%s", err)
+	}
+	return nil
+}
+
+// importProduct creates the product of the row or updates the product with its
+// sku, a dry run only checks it.
+func (cu *catalogUseCase) importProduct(ctx context.Context, row request.ProductImportRow, dryRun bool, categories map[string]response.Category) (bool, *response.ProductImportError) {
+	rowError := func(field, message string) *response.ProductImportError {
+		return &response.ProductImportError{Line: row.Line, SKU: row.SKU, Field: field, Message: message}
+	}
+
+	key := strings.ToLower(row.Category)
+	category, ok := categories[key]
+	if !ok {
+		var err error
+		category, err = cu.catalogRepo.FindCategoryByName(ctx, row.Category)
+		if err != nil {
+			return false, rowError("category", fmt.Sprintf("Failed to find category :%s", err))
+		}
+		categories[key] = category
+	}
+	if category.ID == 0 {
+		return false, rowError("category", fmt.Sprintf("no category named %q", row.Category))
+	}
+
+	existing, err := cu.catalogRepo.FindProductBySKU(ctx, row.SKU)
+	if err != nil {
+		return false, rowError("sku", fmt.Sprintf("Failed to find product :%s", err))
+	}
+	sameName, err := cu.productRepo.FindProductByName(ctx, row.ProductName)
+	if err != nil {
+		return false, rowError("product_name", fmt.Sprintf("Failed to find product :%s", err))
+	}
+	if sameName.ID != 0 && sameName.ID != existing.ID {
+		return false, rowError("product_name", fmt.Sprintf("the name is used by the product with sku %q", sameName.SKU))
+	}
+
+	product := request.Product{
+		CategoryID:         int(category.ID),
+		ProductName:        row.ProductName,
+		ProductDescription: row.ProductDescription,
+		Price:              row.Price,
+		MRP:                row.MRP,
+		Stock:              row.Stock,
+		MaxPerOrder:        row.MaxPerOrder,
+		SKU:                row.SKU,
+		Brand:              row.Brand,
+		IsBlocked:          row.IsBlocked,
+	}
+	if product.Brand == "" {
+		product.Brand = category.Category_Name
+	}
+	if len(row.Images) > 0 {
+		product.Images = domain.NewJsonB()
+		product.Images["urls"] = row.Images
+	}
+
+	if dryRun {
+		return existing.ID == 0, nil
+	}
+
+	if existing.ID != 0 {
+		if err := cu.catalogRepo.UpdateProduct(ctx, int(existing.ID), product); err != nil {
+			return false, rowError("", fmt.Sprintf("Failed to update product :%s", err))
+		}
+		return false, nil
+	}
+
+	created, err := cu.catalogRepo.CreateProduct(ctx, product)
+	if err != nil {
+		return false, rowError("", fmt.Sprintf("Failed to create product :%s", err))
+	}
+	if created.ID == 0 {
+		return false, rowError("", "Failed to verify product")
+	}
+	return true, nil
+}
+
+func (cu *catalogUseCase) GetProductImport(ctx context.Context, importID int) (response.ProductImport, error) {
+	productImport, err := cu.catalogRepo.FindProductImport(ctx, importID)
+	if err != nil {
+		return response.ProductImport{}, fmt.Errorf("Failed to find product import :%s", err)
+	}
+	if productImport.ID == 0 {
+		return response.ProductImport{}, ErrNoRecord
+	}
+	return withImportReport(productImport), nil
+}
+
+func (cu *catalogUseCase) GetProductImports(ctx context.Context, page, count int) ([]response.ProductImport, error) {
+	startIndex, endIndex := helper.Paginate(page, count)
+	imports, err := cu.catalogRepo.GetProductImports(ctx, startIndex, endIndex)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get product imports :%s", err)
+	}
+	for i := range imports {
+		imports[i] = withImportReport(imports[i])
+	}
+	return imports, nil
+}
+
+// withImportReport decodes the row errors and works out the progress.
+func withImportReport(productImport response.ProductImport) response.ProductImport {
+	productImport.Errors = make([]response.ProductImportError, 0)
+	if productImport.RowErrors != "" {
+		if err := json.Unmarshal([]byte(productImport.RowErrors), &productImport.Errors); err != nil {
+			slog.Warn("product import report not decoded", "import_id", productImport.ID, "error", err)
+		}
+	}
+	if productImport.TotalRows > 0 {
+		productImport.Progress = float32(productImport.ProcessedRows) * 100 / float32(productImport.TotalRows)
+	}
+	return productImport
+}
+
+func encodeImportErrors(rowErrors []response.ProductImportError) (string, error) {
+	if len(rowErrors) == 0 {
+		return "", nil
+	}
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+	encoded, err := json.Marshal(rowErrors)
+	return string(encoded), err
+}
+
+// parseCatalogCSV reads the rows of the csv by the names in its header, a value
+// that is not a number or a bool fails its row.
+func parseCatalogCSV(file io.Reader) ([]request.ProductImportRow, []response.ProductImportError, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w : %s", ErrInvalidImportFile, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range requiredCatalogColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("%w : the csv has no %s column", ErrInvalidImportFile, name)
+		}
+	}
+
+	var rows []request.ProductImportRow
+	var rowErrors []response.ProductImportError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w : %s", ErrInvalidImportFile, err)
+		}
+		if len(rows)+len(rowErrors) >= maxImportRows {
+			return nil, nil, fmt.Errorf("%w : at most %d products can be imported at once", ErrInvalidImportFile, maxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := request.ProductImportRow{
+			Line:               line,
+			SKU:                value("sku"),
+			ProductName:        value("product_name"),
+			ProductDescription: value("product_description"),
+			Category:           value("category"),
+			Brand:              value("brand"),
+		}
+		for _, image := range strings.Split(value("images"), importImageSeparator) {
+			if image = strings.TrimSpace(image); image != "" {
+				row.Images = append(row.Images, image)
+			}
+		}
+
+		var rowError *response.ProductImportError
+		numbers := []struct {
+			name  string
+			value *int
+		}{{"price", &row.Price}, {"mrp", &row.MRP}, {"stock", &row.Stock}, {"max_per_order", &row.MaxPerOrder}}
+		for _, number := range numbers {
+			text := value(number.name)
+			if text == "" {
+				continue
+			}
+			if *number.value, err = strconv.Atoi(text); err != nil && rowError == nil {
+				rowError = &response.ProductImportError{Line: line, SKU: row.SKU, Field: number.name, Message: "must be a whole number"}
+			}
+		}
+		if text := value("is_blocked"); text != "" {
+			if row.IsBlocked, err = strconv.ParseBool(text); err != nil && rowError == nil {
+				rowError = &response.ProductImportError{Line: line, SKU: row.SKU, Field: "is_blocked", Message: "must be true or false"}
+			}
+		}
+
+		if rowError != nil {
+			rowErrors = append(rowErrors, *rowError)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// parseCatalogJSON reads an array of products, a product with a value of the
+// wrong type fails its row.
+func parseCatalogJSON(file io.Reader) ([]request.ProductImportRow, []response.ProductImportError, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(file).Decode(&items); err != nil {
+		return nil, nil, fmt.Errorf("%w : %s", ErrInvalidImportFile, err)
+	}
+	if len(items) > maxImportRows {
+		return nil, nil, fmt.Errorf("%w : at most %d products can be imported at once", ErrInvalidImportFile, maxImportRows)
+	}
+
+	var rows []request.ProductImportRow
+	var rowErrors []response.ProductImportError
+	for i, item := range items {
+		var row request.ProductImportRow
+		if err := json.Unmarshal(item, &row); err != nil {
+			rowError := response.ProductImportError{Line: i + 1, Message: err.Error()}
+			var typeError *json.UnmarshalTypeError
+			if errors.As(err, &typeError) {
+				rowError.Field, rowError.Message = typeError.Field, "must be of type "+typeError.Type.String()
+			}
+			rowErrors = append(rowErrors, rowError)
+			continue
+		}
+
+		row.Line = i + 1
+		row.SKU = strings.TrimSpace(row.SKU)
+		row.ProductName = strings.TrimSpace(row.ProductName)
+		row.ProductDescription = strings.TrimSpace(row.ProductDescription)
+		row.Category = strings.TrimSpace(row.Category)
+		row.Brand = strings.TrimSpace(row.Brand)
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// validateCatalogRows checks the values of the rows and that a sku or product
+// name is only once in the file. A row without a sku gets the sku made from its
+// name, as a product created one at a time.
+func validateCatalogRows(rows []request.ProductImportRow) ([]request.ProductImportRow, []response.ProductImportError) {
+	valid := make([]request.ProductImportRow, 0, len(rows))
+	var rowErrors []response.ProductImportError
+	skus := make(map[string]int)
+	names := make(map[string]int)
+
+	for _, row := range rows {
+		if row.SKU == "" {
+			row.SKU = helper.MakeSKU(row.ProductName)
+		}
+
+		field, message := "", ""
+		switch {
+		case row.ProductName == "":
+			field, message = "product_name", "is required"
+		case row.ProductDescription == "":
+			field, message = "product_description", "is required"
+		case row.Category == "":
+			field, message = "category", "is required"
+		case row.Price <= 0:
+			field, message = "price", "must be more than zero"
+		case row.MRP < 0:
+			field, message = "mrp", "can not be negative"
+		case row.Stock < 0:
+			field, message = "stock", "can not be negative"
+		case row.MaxPerOrder < 0:
+			field, message = "max_per_order", "can not be negative"
+		case skus[row.SKU] != 0:
+			field, message = "sku", fmt.Sprintf("is also on line %d", skus[row.SKU])
+		case names[strings.ToLower(row.ProductName)] != 0:
+			field, message = "product_name", fmt.Sprintf("is also on line %d", names[strings.ToLower(row.ProductName)])
+		}
+		for _, image := range row.Images {
+			if message != "" {
+				break
+			}
+			if link, err := url.Parse(image); err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+				field, message = "images", fmt.Sprintf("%q is not an http url", image)
+			}
+		}
+
+		if message != "" {
+			rowErrors = append(rowErrors, response.ProductImportError{Line: row.Line, SKU: row.SKU, Field: field, Message: message})
+			continue
+		}
+		skus[row.SKU] = row.Line
+		names[strings.ToLower(row.ProductName)] = row.Line
+		valid = append(valid, row)
+	}
+	return valid, rowErrors
+}
+
+func (cu *catalogUseCase) ExportProducts(ctx context.Context, format string, w io.Writer) error {
+	var writeRow func(request.ProductImportRow) error
+	var end func() error
+
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(catalogColumns); err != nil {
+			return err
+		}
+		writeRow = func(row request.ProductImportRow) error {
+			record := []string{row.SKU, row.ProductName, row.ProductDescription, row.Category, row.Brand,
+				strconv.Itoa(row.Price), strconv.Itoa(row.MRP), strconv.Itoa(row.Stock), strconv.Itoa(row.MaxPerOrder),
+				strings.Join(row.Images, importImageSeparator), strconv.FormatBool(row.IsBlocked)}
+			for i := range record {
+				record[i] = helper.CSVSafe(record[i])
+			}
+			return writer.Write(record)
+		}
+		end = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case "json":
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		first := true
+		writeRow = func(row request.ProductImportRow) error {
+			encoded, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			if !first {
+				encoded = append([]byte(","), encoded...)
+			}
+			first = false
+			_, err = w.Write(append(encoded, '\n'))
+			return err
+		}
+		end = func() error {
+			_, err := io.WriteString(w, "]\n")
+			return err
+		}
+	default:
+		return fmt.Errorf("%w : format must be csv or json", ErrInvalidImportFile)
+	}
+
+	afterID := 0
+	for {
+		products, err := cu.catalogRepo.GetCatalogProducts(ctx, afterID, catalogExportBatch)
+		if err != nil {
+			return fmt.Errorf("Failed to get products :%s", err)
+		}
+
+		for _, product := range products {
+			if err := writeRow(catalogRow(product)); err != nil {
+				return err
+			}
+			afterID = int(product.ID)
+		}
+		if len(products) < catalogExportBatch {
+			return end()
+		}
+	}
+}
+
+// catalogRow is the product in the columns of the import.
+func catalogRow(product response.CatalogProduct) request.ProductImportRow {
+	row := request.ProductImportRow{
+		SKU:                product.SKU,
+		ProductName:        product.ProductName,
+		ProductDescription: product.ProductDescription,
+		Category:           product.Category,
+		Brand:              product.Brand,
+		Price:              product.Price,
+		MRP:                product.MRP,
+		Stock:              product.Stock,
+		MaxPerOrder:        product.MaxPerOrder,
+		Images:             make([]string, 0),
+		IsBlocked:          product.IsBlocked,
+	}
+	if urls, ok := product.Images["urls"].([]interface{}); ok {
+		for _, link := range urls {
+			if link, ok := link.(string); ok {
+				row.Images = append(row.Images, link)
+			}
+		}
+	}
+	return row
+}
@@ -1,7 +1,10 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
@@ -11,23 +14,36 @@ import (
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
 
+var ErrInvalidCouponRule = errors.New("coupon rule is not complete")
+
+const defaultPerUserLimit = 1
+
 type couponUseCase struct {
 	couponRepo interfaces.CouponRepository
+	cartRepo   interfaces.CartRepository
+	orderRepo  interfaces.OrderRepository
 }
 
-func NewCouponUseCase(couponRepo interfaces.CouponRepository) services.CouponUseCase {
+func NewCouponUseCase(couponRepo interfaces.CouponRepository, cartRepo interfaces.CartRepository, orderRepo interfaces.OrderRepository) services.CouponUseCase {
 	return &couponUseCase{
 		couponRepo: couponRepo,
+		cartRepo:   cartRepo,
+		orderRepo:  orderRepo,
 	}
 
 }
 
-func (cu *couponUseCase) CreateCoupons(couponData request.Coupon) error {
+func (cu *couponUseCase) CreateCoupons(ctx context.Context, couponData request.Coupon) error {
+	err := validateCouponRule(&couponData)
+	if err != nil {
+		return err
+	}
+
 	validDays := couponData.ValidityDays
 	couponData.ValidFrom = time.Now()
 	couponData.ValidTill = time.Now().AddDate(0, 0, validDays)
 
-	insertedCoupon, err := cu.couponRepo.CreateCoupon(couponData)
+	insertedCoupon, err := cu.couponRepo.CreateCoupon(ctx, couponData)
 	if err != nil {
 		return fmt.Errorf("Failed to insert coupon :%s", err)
 	}
@@ -35,24 +51,89 @@ func (cu *couponUseCase) CreateCoupons(couponData request.Coupon) error {
 		return fmt.Errorf("Failed to verify inserted coupon ")
 
 	}
+
+	return cu.saveCouponEligibility(ctx, insertedCoupon.ID, couponData)
+}
+
+// validateCouponRule checks that the fields required by the rule type are set.
+func validateCouponRule(couponData *request.Coupon) error {
+	if couponData.RuleType == "" {
+		couponData.RuleType = helper.CouponPercent
+	}
+	if couponData.PerUserLimit == nil {
+		perUserLimit := defaultPerUserLimit
+		couponData.PerUserLimit = &perUserLimit
+	}
+
+	switch couponData.RuleType {
+	case helper.CouponPercent:
+		if couponData.DiscountPercent <= 0 {
+			return fmt.Errorf("%w : percent coupon needs discount_percentage", ErrInvalidCouponRule)
+		}
+	case helper.CouponFlat:
+		if couponData.DiscountAmount <= 0 {
+			return fmt.Errorf("%w : flat coupon needs discount_amount", ErrInvalidCouponRule)
+		}
+	case helper.CouponBuyXGetY:
+		if couponData.BuyQuantity <= 0 || couponData.GetQuantity <= 0 {
+			return fmt.Errorf("%w : buy_x_get_y coupon needs buy_quantity and get_quantity", ErrInvalidCouponRule)
+		}
+	case helper.CouponFreeShipping:
+	default:
+		return fmt.Errorf("%w : unknown rule_type %s", ErrInvalidCouponRule, couponData.RuleType)
+	}
 	return nil
 }
 
-func (cu *couponUseCase) ViewAllCoupons() ([]response.Coupon, error) {
-	coupons, err := cu.couponRepo.GetAllCoupons()
+func (cu *couponUseCase) saveCouponEligibility(ctx context.Context, couponID int, couponData request.Coupon) error {
+	err := cu.couponRepo.DeleteCouponEligibility(ctx, couponID)
+	if err != nil {
+		return fmt.Errorf("Failed to clear coupon eligibility :%s", err)
+	}
+
+	rows := make(map[string][]string)
+	for _, id := range couponData.CategoryIDs {
+		rows[helper.EligibleCategory] = append(rows[helper.EligibleCategory], strconv.Itoa(id))
+	}
+	for _, id := range couponData.ProductIDs {
+		rows[helper.EligibleProduct] = append(rows[helper.EligibleProduct], strconv.Itoa(id))
+	}
+	rows[helper.EligibleBrand] = couponData.Brands
+
+	for scope, values := range rows {
+		for _, value := range values {
+			eligibility, err := cu.couponRepo.InsertCouponEligibility(ctx, couponID, scope, value)
+			if err != nil {
+				return fmt.Errorf("Failed to insert coupon eligibility :%s", err)
+			}
+			if eligibility.ID == 0 {
+				return fmt.Errorf("Failed to verify inserted coupon eligibility")
+			}
+		}
+	}
+	return nil
+}
+
+func (cu *couponUseCase) ViewAllCoupons(ctx context.Context) ([]response.Coupon, error) {
+	coupons, err := cu.couponRepo.GetAllCoupons(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to insert coupon :%s", err)
 	}
 
 	return coupons, nil
 }
-func (cu *couponUseCase) UpdateCoupon(couponData request.Coupon, couponID int) error {
+func (cu *couponUseCase) UpdateCoupon(ctx context.Context, couponData request.Coupon, couponID int) error {
+	err := validateCouponRule(&couponData)
+	if err != nil {
+		return err
+	}
+
 	validDays := couponData.ValidityDays
 	couponData.ValidFrom = time.Now()
 	couponData.ValidTill = time.Now().AddDate(0, 0, validDays)
 	couponData.ID = uint(couponID)
 
-	updatedCoupon, err := cu.couponRepo.UpdateCouponDetails(couponData)
+	updatedCoupon, err := cu.couponRepo.UpdateCouponDetails(ctx, couponData)
 	if err != nil {
 		return fmt.Errorf("Failed to update coupon : %s", err)
 	}
@@ -61,11 +142,11 @@ func (cu *couponUseCase) UpdateCoupon(couponData request.Coupon, couponID int) e
 		return fmt.Errorf("Failed to verify updated coupon by id ")
 	}
 
-	return nil
+	return cu.saveCouponEligibility(ctx, updatedCoupon.ID, couponData)
 }
 
-func (cu *couponUseCase) BlockCoupon(couponID int) error {
-	blockedCoupon, err := cu.couponRepo.BlockCouponByID(couponID)
+func (cu *couponUseCase) BlockCoupon(ctx context.Context, couponID int) error {
+	blockedCoupon, err := cu.couponRepo.BlockCouponByID(ctx, couponID)
 	if err != nil {
 		return fmt.Errorf("Failed to block coupon : %s ", err)
 	}
@@ -75,8 +156,8 @@ func (cu *couponUseCase) BlockCoupon(couponID int) error {
 	return nil
 
 }
-func (cu *couponUseCase) UnBlockCoupon(couponID int) error {
-	unBlockedCoupon, err := cu.couponRepo.UnblockCouponByID(couponID)
+func (cu *couponUseCase) UnBlockCoupon(ctx context.Context, couponID int) error {
+	unBlockedCoupon, err := cu.couponRepo.UnblockCouponByID(ctx, couponID)
 	if err != nil {
 		return fmt.Errorf("Failed to unblock coupon : %s ", err)
 	}
@@ -87,9 +168,9 @@ func (cu *couponUseCase) UnBlockCoupon(couponID int) error {
 
 }
 
-func (cu *couponUseCase) ProcessApplyCoupon(couponCode string, userID int) error {
+func (cu *couponUseCase) ProcessApplyCoupon(ctx context.Context, couponCode string, userID int) error {
 
-	coupon, err := cu.couponRepo.FindCouponByCode(couponCode)
+	coupon, err := cu.couponRepo.FindCouponByCode(ctx, couponCode)
 
 	if err != nil {
 		return fmt.Errorf("Failed to find coupon  :%s", err)
@@ -99,50 +180,122 @@ func (cu *couponUseCase) ProcessApplyCoupon(couponCode string, userID int) error
 		return fmt.Errorf("coupon cant use ,invalid coupon")
 	}
 
-	couponTrackingDetails, err := cu.couponRepo.FindTrackingCoupon(userID, coupon.ID)
+	return applyUserCoupon(ctx, cu.couponRepo, cu.orderRepo, userID, coupon)
+}
 
+// applyUserCoupon tracks the coupon as applied for the user once the usage limits
+// allow it, replacing the applied coupons unless all of them stack.
+func applyUserCoupon(ctx context.Context, couponRepo interfaces.CouponRepository, orderRepo interfaces.OrderRepository, userID int, coupon response.Coupon) error {
+	appliedCoupons, err := couponRepo.GetAppliedCoupons(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("Failed to find coupon tracking details : %s", err)
+		return fmt.Errorf("Failed to find  previous coupon details from coupon tracking ")
 	}
 
-	if couponTrackingDetails.CouponID == coupon.ID && couponTrackingDetails.IsUsed {
-		return fmt.Errorf("Failed coupon already used")
-	} else if couponTrackingDetails.CouponID == coupon.ID && !couponTrackingDetails.IsUsed {
-		return nil
+	for _, applied := range appliedCoupons {
+		if applied.CouponID == coupon.ID {
+			return nil
+		}
 	}
 
-	previousCoupon, err := cu.couponRepo.CheckAppliedCoupon(userID)
-
+	usage, err := couponUsage(ctx, couponRepo, orderRepo, userID, coupon.ID)
 	if err != nil {
-		return fmt.Errorf("Failed to find  previous coupon details from coupon tracking ")
+		return err
+	}
+	if coupon.UsageLimit > 0 && usage.TotalUsed >= coupon.UsageLimit {
+		return fmt.Errorf("Failed coupon usage limit reached")
+	}
+	if coupon.PerUserLimit > 0 && usage.TimesUsed >= coupon.PerUserLimit {
+		return fmt.Errorf("Failed coupon already used")
 	}
-	if previousCoupon.ID != 0 && !previousCoupon.IsUsed {
 
-		changedCoupon, err := cu.couponRepo.ChangeUserCoupon(coupon.ID, userID)
+	// a coupon stacks only when it and every coupon already applied allow it,
+	// otherwise the new coupon replaces the applied ones.
+	stack := coupon.IsStackable
+	for _, applied := range appliedCoupons {
+		previousCoupon, err := couponRepo.FindCouponByID(ctx, applied.CouponID)
 		if err != nil {
-			return fmt.Errorf("Failed to change coupon : %s", err)
-
-		}
-		if changedCoupon.ID == 0 {
-			return fmt.Errorf("Failed to verify changed coupon from coupon tracking")
+			return fmt.Errorf("Failed to find applied coupon :%s", err)
 		}
+		stack = stack && previousCoupon.IsStackable
+	}
 
-	} else {
-		InsertedRecord, err := cu.couponRepo.AddCouponTracking(userID, coupon.ID)
+	if !stack && len(appliedCoupons) != 0 {
+		err = couponRepo.RemoveAppliedCoupons(ctx, userID)
 		if err != nil {
-			return fmt.Errorf("Failed to insert tracking record : %s", err)
+			return fmt.Errorf("Failed to change coupon : %s", err)
 		}
-		if InsertedRecord.ID == 0 {
+	}
 
-			return fmt.Errorf("Failed to verify inserted coupon tracking by id ")
-		}
+	InsertedRecord, err := couponRepo.AddCouponTracking(ctx, userID, coupon.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to insert tracking record : %s", err)
+	}
+	if InsertedRecord.ID == 0 {
+
+		return fmt.Errorf("Failed to verify inserted coupon tracking by id ")
 	}
 	return nil
 }
 
-func (cu *couponUseCase) ListOutAvailableCouponsToUser(userID int) ([]response.Coupon, error) {
+func (cu *couponUseCase) EvaluateCoupon(ctx context.Context, couponCode string, userID int) (response.CouponEvaluation, error) {
+	coupon, err := cu.couponRepo.FindCouponByCode(ctx, couponCode)
+	if err != nil {
+		return response.CouponEvaluation{}, fmt.Errorf("Failed to find coupon  :%s", err)
+	}
+	if coupon.ID == 0 {
+		return response.CouponEvaluation{}, ErrNoRecord
+	}
+
+	cart, err := cu.cartRepo.ViewCart(ctx, userID)
+	if err != nil {
+		return response.CouponEvaluation{}, fmt.Errorf("Failed to fetch user cart :%s", err)
+	}
+
+	return evaluateUserCoupon(ctx, cu.couponRepo, cu.orderRepo, userID, coupon, cart)
+}
+
+// couponUsage collects the order history and redemption counts the rules engine needs.
+func couponUsage(ctx context.Context, couponRepo interfaces.CouponRepository, orderRepo interfaces.OrderRepository, userID, couponID int) (helper.CouponUser, error) {
+	stats, err := orderRepo.GetUserOrderStats(ctx, userID)
+	if err != nil {
+		return helper.CouponUser{}, fmt.Errorf("Failed to find user order stats :%s", err)
+	}
+
+	totalUsed, err := couponRepo.CountCouponUsage(ctx, couponID)
+	if err != nil {
+		return helper.CouponUser{}, fmt.Errorf("Failed to count coupon usage :%s", err)
+	}
+
+	timesUsed, err := couponRepo.CountUserCouponUsage(ctx, userID, couponID)
+	if err != nil {
+		return helper.CouponUser{}, fmt.Errorf("Failed to count user coupon usage :%s", err)
+	}
+
+	return helper.CouponUser{
+		OrderCount:    stats.OrderCount,
+		LifetimeSpend: stats.LifetimeSpend,
+		TimesUsed:     timesUsed,
+		TotalUsed:     totalUsed,
+	}, nil
+}
+
+func evaluateUserCoupon(ctx context.Context, couponRepo interfaces.CouponRepository, orderRepo interfaces.OrderRepository, userID int, coupon response.Coupon, cart []response.Cart) (response.CouponEvaluation, error) {
+	eligibility, err := couponRepo.GetCouponEligibility(ctx, coupon.ID)
+	if err != nil {
+		return response.CouponEvaluation{}, fmt.Errorf("Failed to find coupon eligibility :%s", err)
+	}
+
+	usage, err := couponUsage(ctx, couponRepo, orderRepo, userID, coupon.ID)
+	if err != nil {
+		return response.CouponEvaluation{}, err
+	}
+
+	return helper.EvaluateCoupon(coupon, eligibility, cart, usage), nil
+}
+
+func (cu *couponUseCase) ListOutAvailableCouponsToUser(ctx context.Context, userID int) ([]response.Coupon, error) {
 	currentTime := time.Now()
-	allCoupons, err := cu.couponRepo.GetAvailableCouponsForUser(userID, currentTime)
+	allCoupons, err := cu.couponRepo.GetAvailableCouponsForUser(ctx, userID, currentTime)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to fetch available  coupons")
 	}
@@ -150,8 +303,8 @@ func (cu *couponUseCase) ListOutAvailableCouponsToUser(userID int) ([]response.C
 
 }
 
-func (cu *couponUseCase) RemoveFromCouponTracking(couponID, userID int) error {
-	removedCoupon, err := cu.couponRepo.RemoveCouponFromTracking(couponID, userID)
+func (cu *couponUseCase) RemoveFromCouponTracking(ctx context.Context, couponID, userID int) error {
+	removedCoupon, err := cu.couponRepo.RemoveCouponFromTracking(ctx, couponID, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to remove coupon from coupon tracking :%s", err)
 	}
@@ -160,3 +313,14 @@ func (cu *couponUseCase) RemoveFromCouponTracking(couponID, userID int) error {
 	}
 	return nil
 }
+
+func (cu *couponUseCase) RemoveExpiredCoupons(ctx context.Context) error {
+	now := time.Now()
+	if err := cu.couponRepo.RemoveExpiredCouponTrackings(ctx, now); err != nil {
+		return fmt.Errorf("Failed to remove expired coupons :%s", err)
+	}
+	if err := cu.cartRepo.RemoveExpiredGuestCoupons(ctx, now); err != nil {
+		return fmt.Errorf("Failed to remove expired guest coupons :%s", err)
+	}
+	return nil
+}
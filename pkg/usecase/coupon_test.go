@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+)
+
+func TestValidateCouponRule(t *testing.T) {
+	unlimited, twice := 0, 2
+
+	testCases := []struct {
+		name             string
+		coupon           request.Coupon
+		wantErr          error
+		wantRuleType     string
+		wantPerUserLimit int
+	}{
+		{
+			name:             "defaults to a percent coupon used once per user",
+			coupon:           request.Coupon{DiscountPercent: 10},
+			wantRuleType:     helper.CouponPercent,
+			wantPerUserLimit: defaultPerUserLimit,
+		},
+		{
+			name:             "keeps the unlimited per user limit",
+			coupon:           request.Coupon{RuleType: helper.CouponFlat, DiscountAmount: 100, PerUserLimit: &unlimited},
+			wantRuleType:     helper.CouponFlat,
+			wantPerUserLimit: 0,
+		},
+		{
+			name:             "keeps the per user limit",
+			coupon:           request.Coupon{RuleType: helper.CouponBuyXGetY, BuyQuantity: 2, GetQuantity: 1, PerUserLimit: &twice},
+			wantRuleType:     helper.CouponBuyXGetY,
+			wantPerUserLimit: 2,
+		},
+		{
+			name:             "free shipping needs no discount",
+			coupon:           request.Coupon{RuleType: helper.CouponFreeShipping},
+			wantRuleType:     helper.CouponFreeShipping,
+			wantPerUserLimit: defaultPerUserLimit,
+		},
+		{
+			name:    "percent coupon without a discount",
+			coupon:  request.Coupon{RuleType: helper.CouponPercent},
+			wantErr: ErrInvalidCouponRule,
+		},
+		{
+			name:    "flat coupon without an amount",
+			coupon:  request.Coupon{RuleType: helper.CouponFlat},
+			wantErr: ErrInvalidCouponRule,
+		},
+		{
+			name:    "buy x get y without the quantities",
+			coupon:  request.Coupon{RuleType: helper.CouponBuyXGetY, BuyQuantity: 2},
+			wantErr: ErrInvalidCouponRule,
+		},
+		{
+			name:    "unknown rule type",
+			coupon:  request.Coupon{RuleType: "cashback", DiscountPercent: 10},
+			wantErr: ErrInvalidCouponRule,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			coupon := tc.coupon
+			err := validateCouponRule(&coupon)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("validateCouponRule() error = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+
+			if coupon.RuleType != tc.wantRuleType {
+				t.Errorf("rule type = %s, want %s", coupon.RuleType, tc.wantRuleType)
+			}
+			if coupon.PerUserLimit == nil || *coupon.PerUserLimit != tc.wantPerUserLimit {
+				t.Errorf("per user limit = %v, want %d", coupon.PerUserLimit, tc.wantPerUserLimit)
+			}
+		})
+	}
+}
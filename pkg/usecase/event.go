@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+const (
+	EventOrderPlaced        = "OrderPlaced"
+	EventOrderStatusChanged = "OrderStatusChanged"
+	EventPaymentCaptured    = "PaymentCaptured"
+	EventRefundIssued       = "RefundIssued"
+	EventUserRegistered     = "UserRegistered"
+)
+
+const (
+	eventPending = "pending"
+	eventDead    = "dead"
+
+	maxEventAttempts = 8
+	eventBatch       = 100
+	eventLease       = 5 * time.Minute
+	eventBackoff     = 30 * time.Second // doubled on every failed attempt
+)
+
+// publishEvent writes the event to the outbox through the event repository, use
+// the repository of the transaction making the change so both commit together.
+func publishEvent(ctx context.Context, eventRepo interfaces.EventRepository, eventType string, aggregateID int, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("Failed to encode %s event :%s", eventType, err)
+	}
+
+	now := time.Now()
+	event, err := eventRepo.InsertEvent(ctx, request.DomainEvent{
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		NextAttemptAt: now,
+	}, now)
+	if err != nil {
+		return fmt.Errorf("Failed to publish %s event :%s", eventType, err)
+	}
+	if event.ID == 0 {
+		return fmt.Errorf("Failed to verify published %s event", eventType)
+	}
+	return nil
+}
+
+// decodeEvent reads the payload of the event into the event type.
+func decodeEvent(event response.DomainEvent, payload interface{}) error {
+	if err := json.Unmarshal([]byte(event.Payload), payload); err != nil {
+		return fmt.Errorf("Failed to decode %s event %d :%s", event.Type, event.ID, err)
+	}
+	return nil
+}
+
+type subscription struct {
+	subscriber string
+	handler    services.EventHandler
+}
+
+type eventUseCase struct {
+	eventRepo interfaces.EventRepository
+
+	mu            sync.RWMutex
+	subscriptions map[string][]subscription
+}
+
+func NewEventUseCase(eventRepo interfaces.EventRepository) services.EventUseCase {
+	return &eventUseCase{
+		eventRepo:     eventRepo,
+		subscriptions: make(map[string][]subscription),
+	}
+}
+
+func (eu *eventUseCase) Subscribe(eventType, subscriber string, handler services.EventHandler) {
+	eu.mu.Lock()
+	defer eu.mu.Unlock()
+
+	eu.subscriptions[eventType] = append(eu.subscriptions[eventType], subscription{subscriber: subscriber, handler: handler})
+}
+
+// DispatchEvents delivers the due events to their subscribers. The subscribers
+// that handled an event are recorded, a retry only runs the ones that failed.
+// An event still failing after maxEventAttempts is dead-lettered.
+func (eu *eventUseCase) DispatchEvents(ctx context.Context) error {
+	now := time.Now()
+	events, err := eu.eventRepo.ClaimDueEvents(ctx, now, now.Add(eventLease), eventBatch)
+	if err != nil {
+		return fmt.Errorf("Failed to claim events :%s", err)
+	}
+
+	for _, event := range events {
+		failures, err := eu.deliver(ctx, event)
+		if err != nil {
+			return err
+		}
+
+		attempts := event.Attempts + 1
+		if len(failures) == 0 {
+			err = eu.eventRepo.MarkEventProcessed(ctx, int(event.ID), attempts, time.Now())
+			if err != nil {
+				return fmt.Errorf("Failed to mark event processed :%s", err)
+			}
+			continue
+		}
+
+		status := eventPending
+		if attempts >= maxEventAttempts {
+			status = eventDead
+		}
+		nextAttemptAt := time.Now().Add(eventBackoff * time.Duration(math.Pow(2, float64(attempts-1))))
+		lastError := strings.Join(failures, "; ")
+
+		helper.LoggerFrom(ctx).Warn("event not dispatched", "event_id", event.ID, "event_type", event.Type, "attempts", attempts, "error", lastError)
+		err = eu.eventRepo.MarkEventFailed(ctx, int(event.ID), attempts, lastError, status, nextAttemptAt)
+		if err != nil {
+			return fmt.Errorf("Failed to update event :%s", err)
+		}
+	}
+
+	return nil
+}
+
+// deliver runs the subscribers of the event that did not handle it yet and
+// returns the errors of the ones that failed.
+func (eu *eventUseCase) deliver(ctx context.Context, event response.DomainEvent) ([]string, error) {
+	eu.mu.RLock()
+	subscriptions := eu.subscriptions[event.Type]
+	eu.mu.RUnlock()
+
+	delivered, err := eu.eventRepo.GetEventDeliveries(ctx, int(event.ID))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get event deliveries :%s", err)
+	}
+	done := make(map[string]bool, len(delivered))
+	for _, subscriber := range delivered {
+		done[subscriber] = true
+	}
+
+	var failures []string
+	for _, sub := range subscriptions {
+		if done[sub.subscriber] {
+			continue
+		}
+
+		if err := runSubscriber(ctx, sub.handler, event); err != nil {
+			failures = append(failures, sub.subscriber+": "+err.Error())
+			continue
+		}
+
+		err = eu.eventRepo.InsertEventDelivery(ctx, int(event.ID), sub.subscriber, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to record event delivery :%s", err)
+		}
+	}
+	return failures, nil
+}
+
+// runSubscriber turns a panic of the subscriber into an error so it is retried
+// like any other failure.
+func runSubscriber(ctx context.Context, handler services.EventHandler, event response.DomainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+func (eu *eventUseCase) GetDeadEvents(ctx context.Context, page, count int) ([]response.DomainEvent, error) {
+	startIndex, endIndex := helper.Paginate(page, count)
+
+	events, err := eu.eventRepo.GetEventsByStatus(ctx, eventDead, startIndex, endIndex)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get dead events :%s", err)
+	}
+	return events, nil
+}
+
+func (eu *eventUseCase) RetryDeadEvent(ctx context.Context, eventID int) error {
+	event, err := eu.eventRepo.RequeueEvent(ctx, eventID, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to requeue event :%s", err)
+	}
+	if event.ID == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
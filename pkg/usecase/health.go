@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+var ErrUnhealthy = errors.New("a health check failed")
+
+const (
+	healthOK   = "ok"
+	healthDown = "down"
+)
+
+type healthUseCase struct {
+	healthRepo interfaces.HealthRepository
+	tables     []string
+}
+
+// NewHealthUseCase checks the database, tables are the tables the migrations create.
+func NewHealthUseCase(healthRepo interfaces.HealthRepository, tables []string) services.HealthUseCase {
+	return &healthUseCase{
+		healthRepo: healthRepo,
+		tables:     tables,
+	}
+}
+
+func (hu *healthUseCase) Live(ctx context.Context) response.Health {
+	return response.Health{Status: healthOK, Checks: map[string]string{"process": healthOK}}
+}
+
+func (hu *healthUseCase) Ready(ctx context.Context) (response.Health, error) {
+	health := response.Health{Status: healthOK, Checks: make(map[string]string)}
+	if !hu.checkPostgres(ctx, &health) {
+		health.Checks["migrations"] = "unknown, postgres is down"
+		return health, healthError(health)
+	}
+
+	count, err := hu.healthRepo.CountTables(ctx, hu.tables)
+	switch {
+	case err != nil:
+		health.Status = healthDown
+		health.Checks["migrations"] = fmt.Sprintf("Failed to check migrations :%s", err)
+	case count < len(hu.tables):
+		health.Status = healthDown
+		health.Checks["migrations"] = fmt.Sprintf("%d of %d tables missing", len(hu.tables)-count, len(hu.tables))
+	default:
+		health.Checks["migrations"] = healthOK
+	}
+	return health, healthError(health)
+}
+
+func (hu *healthUseCase) checkPostgres(ctx context.Context, health *response.Health) bool {
+	if err := hu.healthRepo.Ping(ctx); err != nil {
+		health.Status = healthDown
+		health.Checks["postgres"] = err.Error()
+		return false
+	}
+	health.Checks["postgres"] = healthOK
+	return true
+}
+
+func healthError(health response.Health) error {
+	if health.Status != healthOK {
+		return ErrUnhealthy
+	}
+	return nil
+}
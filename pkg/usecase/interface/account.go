@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type AccountUseCase interface {
+	// ExportData collects the profile, addresses, orders, wallet, ratings and
+	// wishlist of the user.
+	ExportData(ctx context.Context, userID int) (response.AccountExport, error)
+
+	// WriteExportArchive writes the export of the user to w as a zip with one
+	// json file per section.
+	WriteExportArchive(ctx context.Context, userID int, w io.Writer) error
+
+	// RequestDeletion schedules the account for deletion after the grace period.
+	RequestDeletion(ctx context.Context, userID int, body request.DeleteAccount) (response.AccountDeletion, error)
+
+	// GetDeletion returns the latest deletion request of the user.
+	GetDeletion(ctx context.Context, userID int) (response.AccountDeletion, error)
+
+	// CancelDeletion cancels the pending deletion of the user.
+	CancelDeletion(ctx context.Context, userID int) (response.AccountDeletion, error)
+
+	// DeleteDueAccounts anonymises the accounts whose grace period is over.
+	DeleteDueAccounts(ctx context.Context) error
+}
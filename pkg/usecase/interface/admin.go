@@ -1,17 +1,35 @@
 package interfaces
 
 import (
+	"context"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
 
 type AdminUseCase interface {
 
-	// GetAllUserData retrieves a list of user data.
-	GetAllUserData() ([]response.UserData, error)
+	// GetAllUserData retrieves a page of the users matching the filter.
+	GetAllUserData(ctx context.Context, filter request.UserFilter, page, count int) ([]response.UserData, error)
 
 	// BlockUserByID blocks a user by their ID.
-	BlockUserByID(userID int) error
+	BlockUserByID(ctx context.Context, userID int) error
 
 	// UnBlockUserByID unblocks a user by their ID.
-	UnBlockUserByID(userID int) error
+	UnBlockUserByID(ctx context.Context, userID int) error
+
+	// FindUsersByName retrieves the users whose name contains name.
+	FindUsersByName(ctx context.Context, name string) ([]response.UserData, error)
+
+	// GetCustomer retrieves the user with the addresses, orders, wallet,
+	// referrals, ratings and admin notes of the account.
+	GetCustomer(ctx context.Context, userID int) (response.Customer, error)
+
+	// AddNote adds an admin note on the account of the user.
+	AddNote(ctx context.Context, userID int, note string) (response.AdminNote, error)
+
+	// GetNotes retrieves the admin notes of the user, the latest first.
+	GetNotes(ctx context.Context, userID int) ([]response.AdminNote, error)
+
+	// DeleteNote deletes an admin note.
+	DeleteNote(ctx context.Context, noteID int) error
 }
@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type AuditUseCase interface {
+	// EntityFromParams returns the entity the route params point to, like the
+	// user of userID. It is empty when no param names an entity.
+	EntityFromParams(params map[string]string) (entityType, entityID string)
+
+	// Snapshot returns the entity as json, empty when it doesn't exist.
+	Snapshot(ctx context.Context, entityType, entityID string) string
+
+	// RedactBody returns the json request body with the secrets replaced, empty
+	// when the body is not json.
+	RedactBody(body []byte) string
+
+	// Record stores the log with the fields that changed between Before and After.
+	Record(ctx context.Context, log request.AuditLog) error
+
+	// GetAuditLogs lists a page of the logs matching the filter, the latest first.
+	GetAuditLogs(ctx context.Context, filter request.AuditLogFilter, page, count int) ([]response.AuditLog, error)
+
+	// WriteAuditCSV writes every log matching the filter to w as csv.
+	WriteAuditCSV(ctx context.Context, filter request.AuditLogFilter, w io.Writer) error
+}
@@ -1,13 +1,25 @@
 package interfaces
 
 import (
+	"context"
+
 	request "github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
 
 type AuthUseCase interface {
-	AdminLogin(sudoData request.AdminLogin) error
-	SignUp(user request.SignUpData) error
-	ValidateSignUpRequest(phone request.Phone) (int, error)
-	ValidateUserLoginCredentials(user request.LoginData) (response.UserData, error)
+	AdminLogin(ctx context.Context, sudoData request.AdminLogin) error
+	SignUp(ctx context.Context, user request.SignUpData) (response.UserData, error)
+	ValidateSignUpRequest(ctx context.Context, phone request.Phone) (int, error)
+	ValidateUserLoginCredentials(ctx context.Context, user request.LoginData) (response.UserData, error)
+	Subscribe(bus EventUseCase)
+	SendEmailVerification(ctx context.Context, userID int) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, body request.ForgotPassword) (response.PasswordResetChallenge, error)
+	ResetPassword(ctx context.Context, body request.ResetPassword) error
+
+	AdminLoginChallenge(ctx context.Context) (response.TwoFactorChallenge, error)
+	VerifyAdminTwoFactor(ctx context.Context, body request.TwoFactorLogin) ([]string, error)
+	UserLoginChallenge(ctx context.Context, userID int) (response.TwoFactorChallenge, bool, error)
+	VerifyUserTwoFactor(ctx context.Context, body request.TwoFactorLogin) (int, error)
 }
@@ -1,23 +1,69 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+
+	repo "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type CartUseCase interface {
+	// WithTransaction returns a copy working on the repositories of the
+	// transaction, its writes commit or roll back with the transaction.
+	WithTransaction(repos repo.TxRepositories) CartUseCase
+
 	// AddToCart adds a product to the user's shopping cart.
-	AddToCart(userID, ProductID int) error
+	AddToCart(ctx context.Context, userID, ProductID int) error
 
 	// ViewCart retrieves the items in the user's shopping cart.
-	ViewCart(userID int) (response.CartItems, error)
+	ViewCart(ctx context.Context, userID int) (response.CartItems, error)
 
 	// RemoveFromCart removes a product from the user's shopping cart.
-	RemoveFromCart(userID, productID int) error
+	RemoveFromCart(ctx context.Context, userID, productID int) error
 
 	// IncrementQuantity increases the quantity of a product in the user's cart.
-	IncrementQuantity(userID, productID int) error
+	IncrementQuantity(ctx context.Context, userID, productID int) error
 
 	// DecrementQuantity decreases the quantity of a product in th user's cart.
-	DecrementQuantity(userID, productID int) error
+	DecrementQuantity(ctx context.Context, userID, productID int) error
 
 	// DeleteUserCart deletes the entire shopping cart of a user.
-	DeleteUserCart(userID int) error
+	DeleteUserCart(ctx context.Context, userID int) error
+
+	// UpdateCartQuantities sets the quantity of several cart lines at once.
+	UpdateCartQuantities(ctx context.Context, userID int, items []request.CartQuantity) ([]response.CartIssue, error)
+
+	// SaveForLater moves a cart line to the user's wishlist.
+	SaveForLater(ctx context.Context, userID, productID int) error
+
+	// MoveToCart moves a product from the user's wishlist to the cart.
+	MoveToCart(ctx context.Context, userID, productID int) error
+
+	// ValidateCart removes or flags the cart lines that changed since they were added.
+	ValidateCart(ctx context.Context, userID int) ([]response.CartIssue, error)
+
+	// AddToGuestCart adds a product to the guest cart of a visitor.
+	AddToGuestCart(ctx context.Context, cartID string, productID int) error
+
+	// ViewGuestCart retrieves the items in the guest cart priced with promotions and coupons.
+	ViewGuestCart(ctx context.Context, cartID string) (response.CartItems, error)
+
+	// RemoveFromGuestCart removes a product from the guest cart.
+	RemoveFromGuestCart(ctx context.Context, cartID string, productID int) error
+
+	// IncrementGuestQuantity increases the quantity of a product in the guest cart.
+	IncrementGuestQuantity(ctx context.Context, cartID string, productID int) error
+
+	// DecrementGuestQuantity decreases the quantity of a product in the guest cart.
+	DecrementGuestQuantity(ctx context.Context, cartID string, productID int) error
+
+	// ApplyGuestCoupon applies a coupon on the guest cart.
+	ApplyGuestCoupon(ctx context.Context, cartID string, couponCode string) error
+
+	// RemoveGuestCoupon removes an applied coupon from the guest cart.
+	RemoveGuestCoupon(ctx context.Context, cartID string, couponID int) error
+
+	// MergeGuestCart moves the guest cart and its coupons into the user's cart.
+	MergeGuestCart(ctx context.Context, userID int, cartID string) error
 }
@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type CatalogUseCase interface {
+	// ImportProducts validates the csv or json file and queues the import of
+	// its valid rows, a dry run only reports what the import would do.
+	ImportProducts(ctx context.Context, format, fileName string, file io.Reader, dryRun bool) (response.ProductImport, error)
+	ProcessProductImport(ctx context.Context, importID int) error
+	GetProductImport(ctx context.Context, importID int) (response.ProductImport, error)
+	GetProductImports(ctx context.Context, page, count int) ([]response.ProductImport, error)
+	// ExportProducts writes every product to w as csv or json, in the columns of the import.
+	ExportProducts(ctx context.Context, format string, w io.Writer) error
+}
@@ -1,19 +1,25 @@
 package interfaces
 
 import (
+	"context"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 )
 
 type CouponUseCase interface {
-	CreateCoupons(couponData request.Coupon) error
-	ViewAllCoupons() ([]response.Coupon, error)
-	UpdateCoupon(couponData request.Coupon, couponID int) error
-	BlockCoupon(couponID int) error
-	UnBlockCoupon(couponID int) error
-	ProcessApplyCoupon(couponCode string, userID int) error
-	ListOutAvailableCouponsToUser(userID int) ([]response.Coupon, error)
-	RemoveFromCouponTracking(couponID, userID int) error
+	CreateCoupons(ctx context.Context, couponData request.Coupon) error
+	ViewAllCoupons(ctx context.Context) ([]response.Coupon, error)
+	UpdateCoupon(ctx context.Context, couponData request.Coupon, couponID int) error
+	BlockCoupon(ctx context.Context, couponID int) error
+	UnBlockCoupon(ctx context.Context, couponID int) error
+	ProcessApplyCoupon(ctx context.Context, couponCode string, userID int) error
+	ListOutAvailableCouponsToUser(ctx context.Context, userID int) ([]response.Coupon, error)
+	RemoveFromCouponTracking(ctx context.Context, couponID, userID int) error
+	EvaluateCoupon(ctx context.Context, couponCode string, userID int) (response.CouponEvaluation, error)
+
+	// RemoveExpiredCoupons removes the expired coupons applied on the carts, the
+	// cart views also drop them when they are seen.
+	RemoveExpiredCoupons(ctx context.Context) error
 }
 
 // type CouponUseCase interface {
@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+// EventHandler handles a domain event, the payload is the JSON of the event type.
+// An event can be delivered more than once, handlers must be idempotent.
+type EventHandler func(ctx context.Context, event response.DomainEvent) error
+
+type EventUseCase interface {
+	// Subscribe runs the handler on every event of the type, subscriber names the
+	// handler to track its deliveries and must be unique and stable.
+	Subscribe(eventType, subscriber string, handler EventHandler)
+
+	// DispatchEvents delivers the due events of the outbox to their subscribers.
+	DispatchEvents(ctx context.Context) error
+
+	// GetDeadEvents lists the dead-lettered events, the latest first.
+	GetDeadEvents(ctx context.Context, page, count int) ([]response.DomainEvent, error)
+
+	// RetryDeadEvent puts a dead-lettered event back in the outbox.
+	RetryDeadEvent(ctx context.Context, eventID int) error
+}
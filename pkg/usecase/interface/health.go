@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type HealthUseCase interface {
+	// Live reports the process is up, it checks no dependency so an outage of
+	// Postgres doesn't get the pods restarted.
+	Live(ctx context.Context) response.Health
+
+	// Ready checks the connection to Postgres and that the migrations are applied.
+	Ready(ctx context.Context) (response.Health, error)
+}
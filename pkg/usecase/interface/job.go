@@ -0,0 +1,40 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+// JobHandler runs a job, the payload is the JSON given to Enqueue. A failed job is
+// retried and a job can run again when its worker stops, handlers must be idempotent.
+type JobHandler func(ctx context.Context, job response.Job) error
+
+type JobUseCase interface {
+	// Handle runs the handler on the jobs of the type, register it before Start.
+	Handle(jobType string, handler JobHandler)
+
+	// Enqueue queues a job of the type with the payload. It returns ErrJobExists
+	// when a queued or running job holds the unique key of the options.
+	Enqueue(ctx context.Context, jobType string, payload interface{}, options request.JobOptions) (response.Job, error)
+
+	// Schedule runs fn on the cron spec, see helper.ParseSchedule. The name is the
+	// type of the job and must be unique and stable, a scheduled run is not retried.
+	Schedule(name, spec string, fn func(ctx context.Context) error) error
+
+	// Start runs the workers and queues the scheduled jobs.
+	Start()
+
+	// Stop stops claiming jobs and waits for the running ones until ctx is done.
+	Stop(ctx context.Context) error
+
+	// GetJobs lists the jobs matching the filter, the latest first.
+	GetJobs(ctx context.Context, filter request.JobFilter, page, count int) ([]response.Job, error)
+
+	// GetJobStats counts the jobs of each type by status.
+	GetJobStats(ctx context.Context) ([]response.JobStats, error)
+
+	// RetryJob queues a failed job again with fresh attempts.
+	RetryJob(ctx context.Context, jobID int) (response.Job, error)
+}
@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type LockoutUseCase interface {
+	// ReserveLogin counts the login attempt of the account from the IP before the
+	// credentials are checked, an attempt that fails stays counted. It returns a
+	// LoginLockedError while the logins of the account or of the IP are refused.
+	// An empty account key counts the attempt on the IP only.
+	ReserveLogin(ctx context.Context, accountKey, ip string) error
+
+	// LoginSucceeded clears the failures of the account and takes back the
+	// attempt counted on the IP.
+	LoginSucceeded(ctx context.Context, accountKey, ip string) error
+
+	// GetLockouts lists the accounts and IPs with failed logins, activeOnly keeps
+	// the ones locked at the moment.
+	GetLockouts(ctx context.Context, activeOnly bool, page, count int) ([]response.LoginLockout, error)
+
+	// ClearLockout removes the failures of an account or IP so it can log in again.
+	ClearLockout(ctx context.Context, lockoutID int) error
+}
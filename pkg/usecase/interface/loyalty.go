@@ -0,0 +1,57 @@
+package interfaces
+
+import (
+	"context"
+
+	repo "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type LoyaltyUseCase interface {
+	// WithTransaction returns a copy working on the repositories of the
+	// transaction, its writes commit or roll back with the transaction.
+	WithTransaction(repos repo.TxRepositories) LoyaltyUseCase
+
+	// Subscribe handles the points of the order events on the bus.
+	Subscribe(bus EventUseCase)
+
+	// CreateLoyaltyRule creates a points earning rule.
+	CreateLoyaltyRule(ctx context.Context, rule request.LoyaltyRule) (response.LoyaltyRule, error)
+
+	// UpdateLoyaltyRule updates an existing rule by its ID.
+	UpdateLoyaltyRule(ctx context.Context, ruleID int, rule request.LoyaltyRule) error
+
+	// ListLoyaltyRules lists every rule.
+	ListLoyaltyRules(ctx context.Context) ([]response.LoyaltyRule, error)
+
+	// BlockLoyaltyRule stops a rule from applying.
+	BlockLoyaltyRule(ctx context.Context, ruleID int) error
+
+	// UnBlockLoyaltyRule lets a blocked rule apply again.
+	UnBlockLoyaltyRule(ctx context.Context, ruleID int) error
+
+	// GetPointsHistory returns the points balance and the ledger of the user.
+	GetPointsHistory(ctx context.Context, userID, page, count int) (response.LoyaltyHistory, error)
+
+	// ApplyPoints sets the points to redeem on the user's cart.
+	ApplyPoints(ctx context.Context, userID, points int) error
+
+	// RemoveAppliedPoints stops redeeming points on the user's cart.
+	RemoveAppliedPoints(ctx context.Context, userID int) error
+
+	// EarnPoints credits the points of a delivered order line.
+	EarnPoints(ctx context.Context, order response.OrderLine) error
+
+	// RedeemPoints spends points paid for an order line.
+	RedeemPoints(ctx context.Context, userID, orderID, points int) error
+
+	// RefundPoints gives back the points paid for a cancelled or returned order line.
+	RefundPoints(ctx context.Context, order response.OrderLine) error
+
+	// ReversePoints takes back the points earned on a returned order line.
+	ReversePoints(ctx context.Context, order response.OrderLine) error
+
+	// ExpirePoints expires the points past their validity.
+	ExpirePoints(ctx context.Context) error
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type NotificationUseCase interface {
+	// Subscribe notifies the users of the domain events on the bus.
+	Subscribe(bus EventUseCase)
+
+	// Notify puts the messages of the event in the outbox for the channels the user gets notifications on.
+	Notify(ctx context.Context, notification request.Notification) error
+
+	// DispatchNotifications sends the due messages of the outbox and schedules the retries.
+	DispatchNotifications(ctx context.Context) error
+
+	// GetPreferences returns the channels the user gets notifications on.
+	GetPreferences(ctx context.Context, userID int) (response.NotificationPreference, error)
+
+	// UpdatePreferences sets the channels the user gets notifications on.
+	UpdatePreferences(ctx context.Context, userID int, preference request.NotificationPreference) (response.NotificationPreference, error)
+
+	// GetNotifications lists the notifications of the user, the latest first.
+	GetNotifications(ctx context.Context, userID, page, count int) ([]response.Notification, error)
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type OIDCUseCase interface {
+	// Providers lists the names of the configured login providers.
+	Providers(ctx context.Context) []string
+
+	// LoginURL starts a social login with the provider and returns the url the
+	// user is redirected to.
+	LoginURL(ctx context.Context, provider, loginHint string) (string, error)
+
+	// Callback completes the social login with the code and the state of the
+	// provider redirect and returns the logged in user.
+	Callback(ctx context.Context, provider, code, state string) (response.UserData, error)
+}
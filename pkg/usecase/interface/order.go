@@ -1,22 +1,27 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type OrderUseCase interface {
-	CheckOutDetails(userID int) (response.Checkout, error)
-	ConfirmedOrder(userID int, paymentMethodID int) error
-	GetUserOrderHistory(userID, page, count int) ([]response.Orders, error)
-	GetOrderManagement(page, count int) (response.OrderManagement, error)
-	UpdateOrderStatus(statusID, orderID int) error
-	AllOrderOverView(page, count int) ([]response.Orders, error)
-	GetRazorPayDetails(userID int) (response.PaymentDetails, error)
-	VerifyRazorPayPayment(signature string, razorpayOrderId string, paymentId string) error
-	OrderCancellation(orderID int) error
-	ProcessReturnRequest(orderID int) error
-	GetUserWallet(userID int) (response.Wallet, error)
-	CreateUserWallet(userID int) error
-	ValidateWalletPayment(userID int) error
-	CreateInvoice(orderID int) (response.Invoice, error)
-	MonthlySalesReport() (response.MonthlySalesReport, error)
-	GetWalletHistory(userID int) ([]response.WalletTransactionHistory, error)
+	CheckOutDetails(ctx context.Context, userID int) (response.Checkout, error)
+	ConfirmedOrder(ctx context.Context, userID int, paymentMethodID int) error
+	GetUserOrderHistory(ctx context.Context, userID, page, count int) ([]response.Orders, error)
+	GetOrderManagement(ctx context.Context, page, count int) (response.OrderManagement, error)
+	UpdateOrderStatus(ctx context.Context, statusID, orderID int) error
+	AllOrderOverView(ctx context.Context, page, count int) ([]response.Orders, error)
+	GetRazorPayDetails(ctx context.Context, userID int) (response.PaymentDetails, error)
+	VerifyRazorPayPayment(ctx context.Context, signature string, razorpayOrderId string, paymentId string) error
+	OrderCancellation(ctx context.Context, orderID int) error
+	PartialOrderCancellation(ctx context.Context, orderID, qty int) error
+	ProcessReturnRequest(ctx context.Context, orderID int) error
+	GetUserWallet(ctx context.Context, userID int) (response.Wallet, error)
+	CreateUserWallet(ctx context.Context, userID int) error
+	ValidateWalletPayment(ctx context.Context, userID int) error
+	CreateInvoice(ctx context.Context, orderID int) (response.Invoice, error)
+	MonthlySalesReport(ctx context.Context) (response.MonthlySalesReport, error)
+	GetWalletHistory(ctx context.Context, userID int) ([]response.WalletTransactionHistory, error)
 }
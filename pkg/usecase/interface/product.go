@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"context"
 	"mime/multipart"
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
@@ -8,28 +9,28 @@ import (
 )
 
 type ProductUseCase interface {
-	CreateCategory(category request.Category) (response.Category, error)
-	ReadAllCategories(page, count int) ([]response.Category, error)
-	UpdateCategoryByID(categoryID int, category request.Category) error
-	BlockCategoryByID(categoryID int) error
-	UnBlockCategoryByID(categoryID int) error
-	CreateProduct(product request.Product) (response.Product, error)
-	DisplayAllProductsToAdmin(page, count int) ([]response.Product, error)
-	DisplayAllProductsToUser(userID, page, count int) ([]response.Product, error)
-	UpdateProductByID(productID int, updated request.UpdateProduct) error
-	BlockProductByID(productID int) error
-	UnBlockProductByID(productID int) error
-	ValidateProductRatingRequest(userID, productID int) error
-	InsertNewProductRating(userID, productID int, rating request.Rating) error
-	SearchProducts(search string, page, count int) ([]response.Product, error)
-	GetProductsByCategoryUser(userID, categoryID, page, count int) ([]response.Product, error)
-	GetProductsByCategoryAdmin( categoryID, page, count int) ([]response.Product, error)
+	CreateCategory(ctx context.Context, category request.Category) (response.Category, error)
+	ReadAllCategories(ctx context.Context, page, count int) ([]response.Category, error)
+	UpdateCategoryByID(ctx context.Context, categoryID int, category request.Category) error
+	BlockCategoryByID(ctx context.Context, categoryID int) error
+	UnBlockCategoryByID(ctx context.Context, categoryID int) error
+	CreateProduct(ctx context.Context, product request.Product) (response.Product, error)
+	DisplayAllProductsToAdmin(ctx context.Context, page, count int) ([]response.Product, error)
+	DisplayAllProductsToUser(ctx context.Context, userID, page, count int) ([]response.Product, error)
+	UpdateProductByID(ctx context.Context, productID int, updated request.UpdateProduct) error
+	BlockProductByID(ctx context.Context, productID int) error
+	UnBlockProductByID(ctx context.Context, productID int) error
+	ValidateProductRatingRequest(ctx context.Context, userID, productID int) error
+	InsertNewProductRating(ctx context.Context, userID, productID int, rating request.Rating) error
+	SearchProducts(ctx context.Context, search string, page, count int) ([]response.Product, error)
+	GetProductsByCategoryUser(ctx context.Context, userID, categoryID, page, count int) ([]response.Product, error)
+	GetProductsByCategoryAdmin(ctx context.Context, categoryID, page, count int) ([]response.Product, error)
 
-	UploadCategoryImage(files []*multipart.FileHeader, ID int) error
-	UploadProductImage(files []*multipart.FileHeader, ID int) error
-	ViewIndividualProduct(userID, productID int) (response.ProductItem, error)
+	UploadCategoryImage(ctx context.Context, files []*multipart.FileHeader, ID int) error
+	UploadProductImage(ctx context.Context, files []*multipart.FileHeader, ID int) error
+	ViewIndividualProduct(ctx context.Context, userID, productID int) (response.ProductItem, error)
 
-	AddToWishList(userID, productID int) error
-	RemoveFromWishList(userID, productID int) error
-	ShowWishListProducts(userID, page, count int) ([]response.Product, error)
+	AddToWishList(ctx context.Context, userID, productID int) error
+	RemoveFromWishList(ctx context.Context, userID, productID int) error
+	ShowWishListProducts(ctx context.Context, userID, page, count int) ([]response.Product, error)
 }
@@ -0,0 +1,36 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type PromotionUseCase interface {
+	// CreatePromotion creates an automatic promotion.
+	CreatePromotion(ctx context.Context, promotion request.Promotion) (response.Promotion, error)
+
+	// UpdatePromotion updates an existing promotion by its ID.
+	UpdatePromotion(ctx context.Context, promotionID int, promotion request.Promotion) error
+
+	// ListPromotions lists every promotion.
+	ListPromotions(ctx context.Context) ([]response.Promotion, error)
+
+	// BlockPromotion stops a promotion from applying.
+	BlockPromotion(ctx context.Context, promotionID int) error
+
+	// UnBlockPromotion lets a blocked promotion apply again.
+	UnBlockPromotion(ctx context.Context, promotionID int) error
+
+	// SchedulePriceChange schedules a product price change.
+	SchedulePriceChange(ctx context.Context, schedule request.ScheduledPrice) (response.ScheduledPrice, error)
+
+	// ListScheduledPrices lists every scheduled price change.
+	ListScheduledPrices(ctx context.Context) ([]response.ScheduledPrice, error)
+
+	// CancelScheduledPrice cancels a price change that has not started yet.
+	CancelScheduledPrice(ctx context.Context, scheduleID int) error
+
+	// RunScheduler activates and expires promotions and scheduled prices that are due.
+	RunScheduler(ctx context.Context) error
+}
@@ -1,8 +1,12 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type RazorpayUseCase interface {
-	GetRazorPayDetails(userID int) (response.PaymentDetails, error)
-	VerifyRazorPayPayment(signature string, razorpayOrderID string, paymentID string) error
+	GetRazorPayDetails(ctx context.Context, userID int) (response.PaymentDetails, error)
+	VerifyRazorPayPayment(ctx context.Context, signature string, razorpayOrderID string, paymentID string) error
 }
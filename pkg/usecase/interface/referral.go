@@ -1,9 +1,19 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type ReferralUseCase interface {
-	GetUserReferralCode(userID int) (response.Referral, error)
-	ClaimReferralBonus(claimingUserID, codeOwnerID int) error
-	VerifyReferralCode(referralCode string, claimingUserID int) (int, error)
+	GetUserReferralCode(ctx context.Context, userID int, deviceID string) (response.Referral, error)
+	ClaimReferralBonus(ctx context.Context, claimingUserID, codeOwnerID int, deviceID string) error
+	VerifyReferralCode(ctx context.Context, referralCode string, claimingUserID int, deviceID string) (int, error)
+	ReleaseReferralReward(ctx context.Context, refereeID int) error
+	Subscribe(bus EventUseCase)
+	GetReferralStats(ctx context.Context, userID int) (response.ReferralStats, error)
+
+	GetReferralProgramme(ctx context.Context) (response.ReferralProgramme, error)
+	UpdateReferralProgramme(ctx context.Context, programme request.ReferralProgramme) (response.ReferralProgramme, error)
 }
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type ReminderUseCase interface {
+	// SendCartReminders tracks the carts left idle and sends the due reminders of the sequence.
+	SendCartReminders(ctx context.Context) error
+
+	// Subscribe marks the carts recovered by the orders on the bus.
+	Subscribe(bus EventUseCase)
+
+	// MarkCartRecovered closes the open abandoned cart of the user as ordered.
+	MarkCartRecovered(ctx context.Context, userID int, value float32) error
+
+	// Unsubscribe stops the reminders of the user in the signed unsubscribe token.
+	Unsubscribe(ctx context.Context, token string) error
+
+	// GetAbandonedCartReport sums up the abandoned carts and the recovered value between from and to.
+	GetAbandonedCartReport(ctx context.Context, from, to time.Time) (response.AbandonedCartReport, error)
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type ReportUseCase interface {
+	// GetSalesAnalytics sums up the sales of the period per day, week or month
+	// with the top sellers, payment methods, coupons and new and returning customers.
+	GetSalesAnalytics(ctx context.Context, report request.SalesReport) (response.SalesAnalytics, error)
+	// ExportSales writes the sales, tax or refunds report of the period to w, with
+	// the summary first and then the order lines read in batches.
+	ExportSales(ctx context.Context, export request.SalesExport, w io.Writer) error
+}
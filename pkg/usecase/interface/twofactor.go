@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+// TwoFactorUseCase manages the TOTP two factor authentication of a subject, a user
+// (usecase.UserSubject) or the admin (usecase.AdminSubject). The ip is the client
+// ip, wrong codes are throttled per subject and per ip.
+type TwoFactorUseCase interface {
+	GetStatus(ctx context.Context, subject string) (response.TwoFactorStatus, error)
+	IsEnabled(ctx context.Context, subject string) (bool, error)
+
+	// BeginEnrolment returns a new secret and its provisioning uri, the enrolment
+	// is pending until Enable.
+	BeginEnrolment(ctx context.Context, subject string) (response.TwoFactorEnrolment, error)
+
+	// Enable completes the enrolment with a code of the app and returns the
+	// recovery codes.
+	Enable(ctx context.Context, subject, code, ip string) ([]string, error)
+
+	// Verify checks a totp code or a recovery code.
+	Verify(ctx context.Context, subject, code, ip string) error
+
+	// Disable removes the two factor of a user, the admin can not disable it.
+	Disable(ctx context.Context, subject, code, ip string) error
+
+	RegenerateRecoveryCodes(ctx context.Context, subject, code, ip string) ([]string, error)
+}
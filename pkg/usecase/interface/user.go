@@ -1,22 +1,23 @@
 package interfaces
 
 import (
+	"context"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 	"github.com/gin-gonic/gin"
 )
 
 type UserUseCase interface {
-	FindUserById(userID int) (response.UserData, error)
-	AddNewAddress(userID int, address request.Address) error
-	DisplayListOfStates() ([]response.States, error)
-	UpdateUserAddress(address request.Address, addressID int, userID int) error
-	GetUserAddresses(userID int) ([]response.Address, error)
-	DeleteUserAddress(addressID int) error
-	GetProfile(userID int) (response.Profile, error)
-	ForgotPassword(userID int, c *gin.Context) error
-	ChangeUserPassword(password request.ChangePassword, userID int, c *gin.Context) error
-	SetDefaultAddress(userID, addressID int) error
-	CheckUserOldPassword(password request.OldPassword, userID int) error
-	UpdateUserName(username string, userID int) error
+	FindUserById(ctx context.Context, userID int) (response.UserData, error)
+	AddNewAddress(ctx context.Context, userID int, address request.Address) error
+	DisplayListOfStates(ctx context.Context) ([]response.States, error)
+	UpdateUserAddress(ctx context.Context, address request.Address, addressID int, userID int) error
+	GetUserAddresses(ctx context.Context, userID int) ([]response.Address, error)
+	DeleteUserAddress(ctx context.Context, addressID int) error
+	GetProfile(ctx context.Context, userID int) (response.Profile, error)
+	ForgotPassword(ctx context.Context, userID int, c *gin.Context) error
+	ChangeUserPassword(ctx context.Context, password request.ChangePassword, userID int, c *gin.Context) error
+	SetDefaultAddress(ctx context.Context, userID, addressID int) error
+	CheckUserOldPassword(ctx context.Context, password request.OldPassword, userID int) error
+	UpdateUserName(ctx context.Context, username string, userID int) error
 }
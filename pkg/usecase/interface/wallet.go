@@ -1,12 +1,18 @@
 package interfaces
 
-import "github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+import (
+	"context"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
 
 type WalletUseCase interface {
-	GetWalletHistory(userID int) ([]response.WalletTransactionHistory, error)
-	GetUserWallet(userID int) (response.Wallet, error)
-	CreateUserWallet(userID int) error
-	ValidateWalletPayment(userID int) error
-	UpdateWalletHistory(userID int, amount float32, transactionType string) error
-	UpdateWallet(userID int, amount float32, transactionType string) error
+	GetWalletHistory(ctx context.Context, userID int) ([]response.WalletTransactionHistory, error)
+	GetUserWallet(ctx context.Context, userID int) (response.Wallet, error)
+	CreateUserWallet(ctx context.Context, userID int) error
+	ApplyWallet(ctx context.Context, userID int, amount float32) error
+	RemoveAppliedWallet(ctx context.Context, userID int) error
+	ValidateWalletPayment(ctx context.Context, userID int) error
+	UpdateWalletHistory(ctx context.Context, userID int, amount float32, transactionType string) error
+	UpdateWallet(ctx context.Context, userID int, amount float32, transactionType string) error
 }
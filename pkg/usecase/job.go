@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+var ErrJobExists = errors.New("a job with this unique key is already queued or running")
+
+const (
+	jobQueued = "queued"
+	jobFailed = "failed"
+
+	defaultJobWorkers  = 4
+	defaultJobAttempts = 5
+	jobPollInterval    = time.Second
+	jobLease           = 5 * time.Minute // extended while the job runs
+	jobBackoff         = 30 * time.Second
+	maxJobBackoff      = time.Hour
+)
+
+// DecodeJob reads the payload of the job into v.
+func DecodeJob(job response.Job, v interface{}) error {
+	if err := json.Unmarshal([]byte(job.Payload), v); err != nil {
+		return fmt.Errorf("Failed to decode %s job %d :%s", job.Type, job.ID, err)
+	}
+	return nil
+}
+
+type jobSchedule struct {
+	spec     string
+	schedule helper.Schedule
+}
+
+type jobUseCase struct {
+	jobRepo interfaces.JobRepository
+	workers int
+	logger  *slog.Logger
+
+	mu        sync.RWMutex
+	handlers  map[string]services.JobHandler
+	schedules map[string]jobSchedule
+	started   bool
+
+	wake chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewJobUseCase(jobRepo interfaces.JobRepository, workers int, logger *slog.Logger) services.JobUseCase {
+	if workers <= 0 {
+		workers = defaultJobWorkers
+	}
+	return &jobUseCase{
+		jobRepo:   jobRepo,
+		workers:   workers,
+		logger:    logger,
+		handlers:  make(map[string]services.JobHandler),
+		schedules: make(map[string]jobSchedule),
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (ju *jobUseCase) Handle(jobType string, handler services.JobHandler) {
+	ju.mu.Lock()
+	defer ju.mu.Unlock()
+
+	ju.handlers[jobType] = handler
+}
+
+func (ju *jobUseCase) Enqueue(ctx context.Context, jobType string, payload interface{}, options request.JobOptions) (response.Job, error) {
+	data := []byte("{}")
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return response.Job{}, fmt.Errorf("Failed to encode %s job :%s", jobType, err)
+		}
+	}
+
+	job := request.Job{
+		Type:        jobType,
+		Payload:     string(data),
+		MaxAttempts: options.MaxAttempts,
+		RunAt:       options.RunAt,
+	}
+	if options.UniqueKey != "" {
+		job.UniqueKey = &options.UniqueKey
+	}
+	return ju.insert(ctx, job)
+}
+
+func (ju *jobUseCase) insert(ctx context.Context, job request.Job) (response.Job, error) {
+	now := time.Now()
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = defaultJobAttempts
+	}
+
+	insertedJob, err := ju.jobRepo.InsertJob(ctx, job, now)
+	if err != nil {
+		return response.Job{}, fmt.Errorf("Failed to enqueue %s job :%s", job.Type, err)
+	}
+	if insertedJob.ID == 0 {
+		return response.Job{}, ErrJobExists
+	}
+	helper.LoggerFrom(ctx).Debug("job queued", "job_id", insertedJob.ID, "job_type", job.Type, "run_at", job.RunAt)
+
+	if !job.RunAt.After(now) {
+		select {
+		case ju.wake <- struct{}{}:
+		default:
+		}
+	}
+	return insertedJob, nil
+}
+
+// Schedule keeps a single queued job per schedule, the unique key stops a restart
+// or another instance from queueing it again. After each run the job is queued
+// for the next time of the spec.
+func (ju *jobUseCase) Schedule(name, spec string, fn func(ctx context.Context) error) error {
+	schedule, err := helper.ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("Failed to schedule %s :%s", name, err)
+	}
+
+	ju.mu.Lock()
+	ju.handlers[name] = func(ctx context.Context, _ response.Job) error { return fn(ctx) }
+	ju.schedules[name] = jobSchedule{spec: spec, schedule: schedule}
+	started := ju.started
+	ju.mu.Unlock()
+
+	if started {
+		return ju.enqueueSchedule(helper.WithLogger(context.Background(), ju.logger), name, spec)
+	}
+	return nil
+}
+
+func (ju *jobUseCase) enqueueSchedule(ctx context.Context, name, spec string) error {
+	uniqueKey := "schedule:" + name
+	_, err := ju.insert(ctx, request.Job{
+		Type:        name,
+		Payload:     "{}",
+		Schedule:    spec,
+		UniqueKey:   &uniqueKey,
+		MaxAttempts: 1,
+	})
+	if err != nil && err != ErrJobExists {
+		return err
+	}
+	return nil
+}
+
+func (ju *jobUseCase) Start() {
+	ju.mu.Lock()
+	if ju.started {
+		ju.mu.Unlock()
+		return
+	}
+	ju.started = true
+	schedules := make(map[string]string, len(ju.schedules))
+	for name, schedule := range ju.schedules {
+		schedules[name] = schedule.spec
+	}
+	ju.mu.Unlock()
+
+	ctx := helper.WithLogger(context.Background(), ju.logger)
+	for name, spec := range schedules {
+		if err := ju.enqueueSchedule(ctx, name, spec); err != nil {
+			ju.logger.Error("job not scheduled", "schedule", name, "error", err)
+		}
+	}
+
+	for i := 0; i < ju.workers; i++ {
+		ju.wg.Add(1)
+		go ju.work()
+	}
+}
+
+func (ju *jobUseCase) Stop(ctx context.Context) error {
+	ju.mu.Lock()
+	select {
+	case <-ju.stop:
+	default:
+		close(ju.stop)
+	}
+	ju.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		ju.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("Failed to wait for the running jobs :%s", ctx.Err())
+	}
+}
+
+// work claims and runs jobs until Stop, waiting for the poll interval or a newly
+// queued job when none is due.
+func (ju *jobUseCase) work() {
+	defer ju.wg.Done()
+
+	ctx := helper.WithLogger(context.Background(), ju.logger)
+
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ju.stop:
+			return
+		default:
+		}
+
+		now := time.Now()
+		job, err := ju.jobRepo.ClaimJob(ctx, now, now.Add(jobLease))
+		if err != nil {
+			ju.logger.Error("job not claimed", "error", err)
+		}
+		if err == nil && job.ID != 0 {
+			ju.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ju.stop:
+			return
+		case <-ju.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// run runs the handler of the job while extending its lease, then completes the
+// job, queues it again or marks it failed. The handler logs with the job id and
+// type.
+func (ju *jobUseCase) run(ctx context.Context, job response.Job) {
+	logger := helper.LoggerFrom(ctx).With("job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts)
+	ctx = helper.WithLogger(ctx, logger)
+	start := time.Now()
+
+	ju.mu.RLock()
+	handler, ok := ju.handlers[job.Type]
+	schedule, scheduled := ju.schedules[job.Type]
+	ju.mu.RUnlock()
+
+	done := make(chan struct{})
+	go ju.heartbeat(ctx, int(job.ID), done)
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler for %s jobs", job.Type)
+	} else {
+		err = runJob(ctx, handler, job)
+	}
+	close(done)
+
+	now := time.Now()
+	var lastError string
+	if err != nil {
+		lastError = err.Error()
+		logger.Error("job failed", "duration", now.Sub(start), "error", lastError)
+	} else {
+		logger.Debug("job done", "duration", now.Sub(start))
+	}
+
+	switch {
+	case scheduled && job.Schedule != "":
+		err = ju.jobRepo.RescheduleJob(ctx, int(job.ID), lastError, schedule.schedule.Next(now), now)
+	case err == nil:
+		err = ju.jobRepo.CompleteJob(ctx, int(job.ID), now)
+	case job.Attempts >= job.MaxAttempts:
+		err = ju.jobRepo.FailJob(ctx, int(job.ID), jobFailed, lastError, job.RunAt, now)
+	default:
+		err = ju.jobRepo.FailJob(ctx, int(job.ID), jobQueued, lastError, now.Add(jobRetryDelay(job.Attempts)), now)
+	}
+	if err != nil {
+		logger.Error("job not updated", "error", err)
+	}
+}
+
+func (ju *jobUseCase) heartbeat(ctx context.Context, jobID int, done <-chan struct{}) {
+	ticker := time.NewTicker(jobLease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := ju.jobRepo.ExtendJobLease(ctx, jobID, time.Now().Add(jobLease)); err != nil {
+				helper.LoggerFrom(ctx).Warn("job lease not extended", "error", err)
+			}
+		}
+	}
+}
+
+// jobRetryDelay doubles the backoff on every failed attempt up to maxJobBackoff.
+func jobRetryDelay(attempts int) time.Duration {
+	delay := jobBackoff * time.Duration(math.Pow(2, float64(attempts-1)))
+	if delay <= 0 || delay > maxJobBackoff {
+		return maxJobBackoff
+	}
+	return delay
+}
+
+// runJob turns a panic of the handler into an error so it is retried like any
+// other failure.
+func runJob(ctx context.Context, handler services.JobHandler, job response.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+func (ju *jobUseCase) GetJobs(ctx context.Context, filter request.JobFilter, page, count int) ([]response.Job, error) {
+	startIndex, endIndex := helper.Paginate(page, count)
+
+	jobs, err := ju.jobRepo.GetJobs(ctx, filter, startIndex, endIndex)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get jobs :%s", err)
+	}
+	return jobs, nil
+}
+
+func (ju *jobUseCase) GetJobStats(ctx context.Context) ([]response.JobStats, error) {
+	stats, err := ju.jobRepo.GetJobStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get job stats :%s", err)
+	}
+	return stats, nil
+}
+
+func (ju *jobUseCase) RetryJob(ctx context.Context, jobID int) (response.Job, error) {
+	job, err := ju.jobRepo.RetryJob(ctx, jobID, time.Now())
+	if err != nil {
+		return response.Job{}, fmt.Errorf("Failed to retry job :%s", err)
+	}
+	if job.ID == 0 {
+		return response.Job{}, ErrNoRecord
+	}
+
+	select {
+	case ju.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
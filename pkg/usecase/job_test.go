@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/repo"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+// newJobTest returns a queue on the in-memory store, the tests claim and run the
+// jobs themselves unless they start the workers.
+func newJobTest() *jobUseCase {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewJobUseCase(repo.NewMemoryJobRepository(), 1, logger).(*jobUseCase)
+}
+
+// claim claims the job due at the time, the zero job when none is.
+func claim(t *testing.T, ju *jobUseCase, now time.Time) response.Job {
+	t.Helper()
+
+	job, err := ju.jobRepo.ClaimJob(context.Background(), now, now.Add(jobLease))
+	if err != nil {
+		t.Fatalf("ClaimJob() error = %v", err)
+	}
+	return job
+}
+
+// findJob returns the job as it is in the store.
+func findJob(t *testing.T, ju *jobUseCase, jobID uint) response.Job {
+	t.Helper()
+
+	jobs, err := ju.GetJobs(context.Background(), request.JobFilter{}, 1, 100)
+	if err != nil {
+		t.Fatalf("GetJobs() error = %v", err)
+	}
+	for _, job := range jobs {
+		if job.ID == jobID {
+			return job
+		}
+	}
+	t.Fatalf("job %d not found", jobID)
+	return response.Job{}
+}
+
+func TestEnqueueUniqueKey(t *testing.T) {
+	ju := newJobTest()
+	ctx := context.Background()
+	ju.Handle("reminder", func(ctx context.Context, job response.Job) error { return nil })
+
+	first, err := ju.Enqueue(ctx, "reminder", map[string]int{"cart_id": 1}, request.JobOptions{UniqueKey: "reminder:1"})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if first.Payload != `{"cart_id":1}` || first.Status != jobQueued || first.MaxAttempts != defaultJobAttempts {
+		t.Errorf("Enqueue() = %+v, want the queued payload with the default attempts", first)
+	}
+
+	if _, err := ju.Enqueue(ctx, "reminder", nil, request.JobOptions{UniqueKey: "reminder:1"}); err != ErrJobExists {
+		t.Errorf("Enqueue() of a queued key error = %v, want %v", err, ErrJobExists)
+	}
+	if _, err := ju.Enqueue(ctx, "reminder", nil, request.JobOptions{UniqueKey: "reminder:2"}); err != nil {
+		t.Errorf("Enqueue() of another key error = %v", err)
+	}
+
+	// the key is held while the job runs and released once it is done
+	job := claim(t, ju, time.Now())
+	if job.ID != first.ID {
+		t.Fatalf("claimed job %d, want %d", job.ID, first.ID)
+	}
+	if _, err := ju.Enqueue(ctx, "reminder", nil, request.JobOptions{UniqueKey: "reminder:1"}); err != ErrJobExists {
+		t.Errorf("Enqueue() of a running key error = %v, want %v", err, ErrJobExists)
+	}
+	ju.run(ctx, job)
+	if _, err := ju.Enqueue(ctx, "reminder", nil, request.JobOptions{UniqueKey: "reminder:1"}); err != nil {
+		t.Errorf("Enqueue() of a completed key error = %v", err)
+	}
+}
+
+func TestClaimJobLease(t *testing.T) {
+	ju := newJobTest()
+	ctx := context.Background()
+	now := time.Now()
+
+	later, err := ju.Enqueue(ctx, "export", nil, request.JobOptions{RunAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	due, err := ju.Enqueue(ctx, "export", nil, request.JobOptions{})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	job := claim(t, ju, now.Add(time.Second))
+	if job.ID != due.ID || job.Status != "running" || job.Attempts != 1 {
+		t.Fatalf("claimed %+v, want the due job running on its first attempt", job)
+	}
+	if other := claim(t, ju, now.Add(time.Second)); other.ID != 0 {
+		t.Fatalf("claimed job %d while the due one is leased and the other not due", other.ID)
+	}
+
+	// a worker extending the lease keeps the job
+	if err := ju.jobRepo.ExtendJobLease(ctx, int(job.ID), now.Add(2*jobLease)); err != nil {
+		t.Fatalf("ExtendJobLease() error = %v", err)
+	}
+	if other := claim(t, ju, now.Add(jobLease+time.Minute)); other.ID != 0 {
+		t.Fatalf("claimed job %d before the extended lease expired", other.ID)
+	}
+
+	// the job of a worker that stopped is claimed again once its lease expires
+	again := claim(t, ju, now.Add(2*jobLease+time.Minute))
+	if again.ID != due.ID || again.Attempts != 2 {
+		t.Fatalf("claimed %+v after the lease expired, want job %d on its second attempt", again, due.ID)
+	}
+
+	if err := ju.jobRepo.CompleteJob(ctx, int(again.ID), now.Add(2*jobLease+2*time.Minute)); err != nil {
+		t.Fatalf("CompleteJob() error = %v", err)
+	}
+	if next := claim(t, ju, now.Add(time.Hour)); next.ID != later.ID {
+		t.Errorf("claimed job %d at its run time, want %d", next.ID, later.ID)
+	}
+}
+
+func TestRunRetriesFailedJob(t *testing.T) {
+	ju := newJobTest()
+	ctx := context.Background()
+
+	calls := 0
+	ju.Handle("sync", func(ctx context.Context, job response.Job) error {
+		calls++
+		if calls == 2 {
+			panic("lost connection")
+		}
+		return errors.New("provider is down")
+	})
+
+	queued, err := ju.Enqueue(ctx, "sync", nil, request.JobOptions{MaxAttempts: 3, UniqueKey: "sync"})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	wantErrors := []string{"provider is down", "panic: lost connection", "provider is down"}
+	now := time.Now()
+	for attempt := 1; attempt <= 3; attempt++ {
+		job := claim(t, ju, now)
+		if job.ID != queued.ID || job.Attempts != attempt {
+			t.Fatalf("claimed %+v, want job %d on attempt %d", job, queued.ID, attempt)
+		}
+		started := time.Now()
+		ju.run(ctx, job)
+
+		job = findJob(t, ju, queued.ID)
+		if job.LastError != wantErrors[attempt-1] {
+			t.Errorf("attempt %d: last error = %q, want %q", attempt, job.LastError, wantErrors[attempt-1])
+		}
+		if attempt == 3 {
+			if job.Status != jobFailed {
+				t.Fatalf("status after the last attempt = %s, want %s", job.Status, jobFailed)
+			}
+			break
+		}
+
+		delay := jobRetryDelay(attempt)
+		if job.Status != jobQueued || job.RunAt.Before(started.Add(delay)) {
+			t.Fatalf("attempt %d: %s to run at %s, want queued after %s", attempt, job.Status, job.RunAt.Sub(started), delay)
+		}
+		if other := claim(t, ju, started); other.ID != 0 {
+			t.Fatalf("attempt %d: claimed job %d before the backoff", attempt, other.ID)
+		}
+		now = job.RunAt
+	}
+
+	// a failed job does not hold its key, a retry is refused while another job does
+	holder, err := ju.Enqueue(ctx, "sync", nil, request.JobOptions{MaxAttempts: 1, UniqueKey: "sync"})
+	if err != nil {
+		t.Fatalf("Enqueue() with the key of a failed job error = %v", err)
+	}
+	if _, err := ju.RetryJob(ctx, int(queued.ID)); err != ErrNoRecord {
+		t.Errorf("RetryJob() while the key is held error = %v, want %v", err, ErrNoRecord)
+	}
+
+	ju.run(ctx, claim(t, ju, time.Now()))
+	if findJob(t, ju, holder.ID).Status != jobFailed {
+		t.Fatal("the job holding the key did not fail")
+	}
+	retried, err := ju.RetryJob(ctx, int(queued.ID))
+	if err != nil {
+		t.Fatalf("RetryJob() error = %v", err)
+	}
+	if retried.Status != jobQueued || retried.Attempts != 0 {
+		t.Errorf("RetryJob() = %+v, want queued with fresh attempts", retried)
+	}
+}
+
+func TestJobWorkers(t *testing.T) {
+	ju := newJobTest()
+	ctx := context.Background()
+
+	done := make(chan string, 1)
+	ju.Handle("welcome", func(ctx context.Context, job response.Job) error {
+		var payload struct{ Email string }
+		if err := DecodeJob(job, &payload); err != nil {
+			return err
+		}
+		done <- payload.Email
+		return nil
+	})
+
+	ju.Start()
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := ju.Stop(stopCtx); err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	}()
+
+	queued, err := ju.Enqueue(ctx, "welcome", map[string]string{"Email": "user@example.com"}, request.JobOptions{})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	select {
+	case email := <-done:
+		if email != "user@example.com" {
+			t.Errorf("handler got %q, want user@example.com", email)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the worker did not run the queued job")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for findJob(t, ju, queued.ID).Status != "completed" {
+		if time.Now().After(deadline) {
+			t.Fatal("the job was not completed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
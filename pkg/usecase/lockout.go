@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+const (
+	lockoutAccount = "account"
+	lockoutIP      = "ip"
+
+	// an IP gets 4 times the attempts of an account, users behind a NAT share it
+	ipLockoutScale = 4
+)
+
+// LoginLockedError is returned when the logins of the account or of the IP are
+// refused because of the previous failures.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed logins, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+type lockoutUseCase struct {
+	lockoutRepo   interfaces.LockoutRepository
+	transactor    interfaces.Transactor
+	accountPolicy helper.LockoutPolicy
+	ipPolicy      helper.LockoutPolicy
+}
+
+func NewLockoutUseCase(lockoutRepo interfaces.LockoutRepository, transactor interfaces.Transactor, policy helper.LockoutPolicy) services.LockoutUseCase {
+	return &lockoutUseCase{
+		lockoutRepo:   lockoutRepo,
+		transactor:    transactor,
+		accountPolicy: policy,
+		ipPolicy:      policy.Scale(ipLockoutScale),
+	}
+}
+
+// lockKey is the lock key of an account, kind is the login it throttles like
+// user, admin, reset or 2fa.
+func lockKey(kind, value string) string {
+	return kind + ":" + strings.ToLower(value)
+}
+
+func ipKey(ip string) string {
+	return lockoutIP + ":" + ip
+}
+
+// ReserveLogin counts the attempt on the account and on the IP and delays their
+// next logins by the policy, as if the attempt failed. The count and the delay
+// are saved in one transaction holding the rows, so parallel attempts are counted
+// one after the other and refused once the count locks the logins.
+func (lu *lockoutUseCase) ReserveLogin(ctx context.Context, accountKey, ip string) error {
+	now := time.Now()
+
+	return lu.transactor.Transaction(ctx, func(repos interfaces.TxRepositories) error {
+		var retryAfter time.Duration
+		if accountKey != "" {
+			wait, err := reserveAttempt(ctx, repos.Lockout, accountKey, lockoutAccount, lu.accountPolicy, now)
+			if err != nil {
+				return err
+			}
+			retryAfter = wait
+		}
+
+		wait, err := reserveAttempt(ctx, repos.Lockout, ipKey(ip), lockoutIP, lu.ipPolicy, now)
+		if err != nil {
+			return err
+		}
+		if wait > retryAfter {
+			retryAfter = wait
+		}
+
+		// the error rolls back the attempt counted on the other key
+		if retryAfter > 0 {
+			return &LoginLockedError{RetryAfter: retryAfter}
+		}
+		return nil
+	})
+}
+
+// reserveAttempt counts the attempt of the key, it returns how long the logins
+// of the key are still refused when it is locked.
+func reserveAttempt(ctx context.Context, lockoutRepo interfaces.LockoutRepository, key, kind string, policy helper.LockoutPolicy, now time.Time) (time.Duration, error) {
+	// failures older than a day are forgotten
+	lockout, err := lockoutRepo.ReserveLoginAttempt(ctx, key, kind, now, now.Add(-24*time.Hour))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count login attempt :%s", err)
+	}
+
+	if lockout.ID == 0 {
+		locked, err := lockoutRepo.FindLockout(ctx, key)
+		if err != nil {
+			return 0, fmt.Errorf("Failed to find login lockout :%s", err)
+		}
+		if locked.LockedUntil == nil || !locked.LockedUntil.After(now) {
+			return 0, fmt.Errorf("Failed to count login attempt of %s", key)
+		}
+		return locked.LockedUntil.Sub(now), nil
+	}
+
+	delay := policy.Delay(lockout.Failures)
+	if delay == 0 {
+		return 0, nil
+	}
+
+	err = lockoutRepo.SetLockedUntil(ctx, lockout.ID, now.Add(delay))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to lock login :%s", err)
+	}
+	if lockout.Failures >= policy.MaxAttempts {
+		helper.LoggerFrom(ctx).Warn("login locked", "key", key, "locked_for", delay, "failures", lockout.Failures)
+	}
+	return 0, nil
+}
+
+// LoginSucceeded clears the failures of the account and takes back the attempt
+// counted on the IP, the failures of the IP are kept until they are forgotten.
+func (lu *lockoutUseCase) LoginSucceeded(ctx context.Context, accountKey, ip string) error {
+	err := lu.lockoutRepo.DeleteLockout(ctx, accountKey)
+	if err != nil {
+		return fmt.Errorf("Failed to clear login lockout :%s", err)
+	}
+
+	err = lu.lockoutRepo.ReleaseLoginAttempt(ctx, ipKey(ip))
+	if err != nil {
+		return fmt.Errorf("Failed to release login attempt :%s", err)
+	}
+	return nil
+}
+
+func (lu *lockoutUseCase) GetLockouts(ctx context.Context, activeOnly bool, page, count int) ([]response.LoginLockout, error) {
+	startIndex, endIndex := helper.Paginate(page, count)
+
+	lockouts, err := lu.lockoutRepo.GetLockouts(ctx, activeOnly, time.Now(), startIndex, endIndex)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get login lockouts :%s", err)
+	}
+	return lockouts, nil
+}
+
+func (lu *lockoutUseCase) ClearLockout(ctx context.Context, lockoutID int) error {
+	lockout, err := lu.lockoutRepo.DeleteLockoutByID(ctx, lockoutID)
+	if err != nil {
+		return fmt.Errorf("Failed to clear login lockout :%s", err)
+	}
+	if lockout.ID == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
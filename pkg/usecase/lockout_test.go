@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (f *fakeUserRepo) FindUserByPhone(ctx context.Context, phone int) (response.UserData, error) {
+	for _, user := range f.users {
+		if user.Phone == phone {
+			return user, nil
+		}
+	}
+	return response.UserData{}, nil
+}
+
+// fakeLockoutRepo keeps the lockouts in memory like the login_lockouts table.
+type fakeLockoutRepo struct {
+	interfaces.LockoutRepository
+	mu       sync.Mutex
+	lockouts map[string]*response.LoginLockout
+}
+
+func (f *fakeLockoutRepo) FindLockout(ctx context.Context, lockKey string) (response.LoginLockout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if lockout, ok := f.lockouts[lockKey]; ok {
+		return *lockout, nil
+	}
+	return response.LoginLockout{}, nil
+}
+
+func (f *fakeLockoutRepo) ReserveLoginAttempt(ctx context.Context, lockKey, kind string, now, forgetBefore time.Time) (response.LoginLockout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lockout, ok := f.lockouts[lockKey]
+	if !ok {
+		lockout = &response.LoginLockout{ID: len(f.lockouts) + 1, LockKey: lockKey, Kind: kind}
+		f.lockouts[lockKey] = lockout
+	}
+	if lockout.LockedUntil != nil && lockout.LockedUntil.After(now) {
+		return response.LoginLockout{}, nil
+	}
+	if lockout.LastFailureAt.Before(forgetBefore) {
+		lockout.Failures = 0
+	}
+	lockout.Failures++
+	lockout.LastFailureAt = now
+	return *lockout, nil
+}
+
+func (f *fakeLockoutRepo) ReleaseLoginAttempt(ctx context.Context, lockKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if lockout, ok := f.lockouts[lockKey]; ok && lockout.Failures > 0 {
+		lockout.Failures--
+	}
+	return nil
+}
+
+func (f *fakeLockoutRepo) SetLockedUntil(ctx context.Context, lockoutID int, lockedUntil time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, lockout := range f.lockouts {
+		if lockout.ID == lockoutID {
+			lockout.LockedUntil = &lockedUntil
+		}
+	}
+	return nil
+}
+
+func (f *fakeLockoutRepo) DeleteLockout(ctx context.Context, lockKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.lockouts, lockKey)
+	return nil
+}
+
+// lockoutTransactor runs one transaction at a time, like the row locks the
+// reservation holds until the commit, and restores the lockouts on an error.
+type lockoutTransactor struct {
+	mu   sync.Mutex
+	repo *fakeLockoutRepo
+}
+
+func (l *lockoutTransactor) Transaction(ctx context.Context, fn func(repos interfaces.TxRepositories) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.repo.mu.Lock()
+	saved := make(map[string]response.LoginLockout, len(l.repo.lockouts))
+	for key, lockout := range l.repo.lockouts {
+		saved[key] = *lockout
+	}
+	l.repo.mu.Unlock()
+
+	err := fn(interfaces.TxRepositories{Lockout: l.repo})
+	if err != nil {
+		l.repo.mu.Lock()
+		l.repo.lockouts = make(map[string]*response.LoginLockout, len(saved))
+		for key, lockout := range saved {
+			lockout := lockout
+			l.repo.lockouts[key] = &lockout
+		}
+		l.repo.mu.Unlock()
+	}
+	return err
+}
+
+// newLockoutTest returns the login of a user with the password "password", three
+// failed attempts lock the logins for 30 minutes.
+func newLockoutTest(t *testing.T) (*authUseCase, *fakeLockoutRepo) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeUserRepo{users: map[int]response.UserData{
+		1: {ID: 1, UserName: "user", Email: "user@example.com", Phone: 9876543210, Password: string(hash)},
+	}}
+	lockoutRepo := &fakeLockoutRepo{lockouts: map[string]*response.LoginLockout{}}
+	transactor := &lockoutTransactor{repo: lockoutRepo}
+	lockoutUseCase := NewLockoutUseCase(lockoutRepo, transactor, helper.NewLockoutPolicy(2, 3, 30*time.Minute))
+
+	useCase := NewCommonUseCase(users, nil, nil, lockoutUseCase, nil, &helper.PasswordPolicy{MinLength: 8}, nil, "").(*authUseCase)
+	return useCase, lockoutRepo
+}
+
+func TestLoginLockoutSharedByEmailAndPhone(t *testing.T) {
+	useCase, lockoutRepo := newLockoutTest(t)
+	ctx := context.Background()
+
+	attempts := []request.LoginData{
+		{Email: "user@example.com", Password: "wrong", IP: "10.0.0.1"},
+		{Phone: 9876543210, Password: "wrong", IP: "10.0.0.1"},
+		{Email: "user@example.com", Password: "wrong", IP: "10.0.0.2"},
+	}
+	for _, attempt := range attempts {
+		if _, err := useCase.ValidateUserLoginCredentials(ctx, attempt); err == nil || errors.As(err, new(*LoginLockedError)) {
+			t.Fatalf("attempt %+v error = %v, want the incorrect password", attempt, err)
+		}
+	}
+	if failures := lockoutRepo.lockouts[lockKey("user", "1")].Failures; failures != 3 {
+		t.Errorf("account failures = %d, want 3", failures)
+	}
+
+	// the right password is refused on either identifier while the account is locked
+	for _, attempt := range []request.LoginData{
+		{Phone: 9876543210, Password: "password", IP: "10.0.0.3"},
+		{Email: "user@example.com", Password: "password", IP: "10.0.0.3"},
+	} {
+		_, err := useCase.ValidateUserLoginCredentials(ctx, attempt)
+		var locked *LoginLockedError
+		if !errors.As(err, &locked) || locked.RetryAfter <= 0 {
+			t.Errorf("attempt %+v error = %v, want a LoginLockedError", attempt, err)
+		}
+	}
+	if lockout, _ := lockoutRepo.FindLockout(ctx, ipKey("10.0.0.3")); lockout.Failures != 0 {
+		t.Errorf("refused logins counted %d failures on the ip, want 0", lockout.Failures)
+	}
+}
+
+func TestLoginSucceededClearsAccount(t *testing.T) {
+	useCase, lockoutRepo := newLockoutTest(t)
+	ctx := context.Background()
+
+	if _, err := useCase.ValidateUserLoginCredentials(ctx, request.LoginData{Email: "user@example.com", Password: "wrong", IP: "10.0.0.1"}); err == nil {
+		t.Fatal("login with the wrong password succeeded")
+	}
+	userData, err := useCase.ValidateUserLoginCredentials(ctx, request.LoginData{Phone: 9876543210, Password: "password", IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("ValidateUserLoginCredentials() error = %v", err)
+	}
+	if userData.ID != 1 || userData.Password != "" {
+		t.Errorf("ValidateUserLoginCredentials() = %+v, want user 1 without the password", userData)
+	}
+
+	if _, ok := lockoutRepo.lockouts[lockKey("user", "1")]; ok {
+		t.Error("the failures of the account were kept after the login")
+	}
+	if failures := lockoutRepo.lockouts[ipKey("10.0.0.1")].Failures; failures != 1 {
+		t.Errorf("ip failures = %d, want only the failed attempt", failures)
+	}
+}
+
+func TestParallelLoginAttemptsAreCounted(t *testing.T) {
+	useCase, _ := newLockoutTest(t)
+	ctx := context.Background()
+
+	const guesses = 20
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		checked int
+		locked  int
+	)
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := useCase.ValidateUserLoginCredentials(ctx, request.LoginData{Email: "user@example.com", Password: "wrong", IP: "10.0.0.1"})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if errors.As(err, new(*LoginLockedError)) {
+				locked++
+			} else {
+				checked++
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the third attempt locks the account, the rest never reach the password check
+	if checked != 3 || locked != guesses-3 {
+		t.Errorf("%d guesses checked and %d locked, want 3 and %d", checked, locked, guesses-3)
+	}
+}
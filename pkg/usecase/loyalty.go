@@ -0,0 +1,412 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+var ErrInsufficientPoints = errors.New("not enough loyalty points")
+
+const (
+	loyaltyPointValue      = 1 // rupees a point is worth at checkout
+	defaultPointsPercent   = 1 // points per 100 spent when no rule matches
+	maxPointsRedeemPercent = 50
+	pointsValidity         = 365 * 24 * time.Hour
+)
+
+const (
+	pointsEarn    = "earn"
+	pointsRedeem  = "redeem"
+	pointsRefund  = "refund"
+	pointsReverse = "reverse"
+	pointsExpire  = "expire"
+)
+
+type loyaltyUseCase struct {
+	loyaltyRepo interfaces.LoyaltyRepository
+	productRepo interfaces.ProductRepository
+}
+
+func NewLoyaltyUseCase(loyaltyRepo interfaces.LoyaltyRepository, productRepo interfaces.ProductRepository) services.LoyaltyUseCase {
+	return &loyaltyUseCase{
+		loyaltyRepo: loyaltyRepo,
+		productRepo: productRepo,
+	}
+}
+
+func (lu *loyaltyUseCase) WithTransaction(repos interfaces.TxRepositories) services.LoyaltyUseCase {
+	tx := *lu
+	tx.loyaltyRepo = repos.Loyalty
+	tx.productRepo = repos.Product
+	return &tx
+}
+
+// Subscribe earns the points of delivered orders, refunds the points paid for
+// cancelled and returned orders and reverses the points earned on returns.
+func (lu *loyaltyUseCase) Subscribe(bus services.EventUseCase) {
+	bus.Subscribe(EventOrderStatusChanged, "loyalty", func(ctx context.Context, event response.DomainEvent) error {
+		var changed request.OrderStatusChanged
+		if err := decodeEvent(event, &changed); err != nil {
+			return err
+		}
+
+		switch changed.Status {
+		case statusDelivered:
+			return lu.EarnPoints(ctx, changed.Order)
+		case statusCancelled:
+			return lu.RefundPoints(ctx, changed.Order)
+		case statusReturned:
+			if err := lu.RefundPoints(ctx, changed.Order); err != nil {
+				return err
+			}
+			return lu.ReversePoints(ctx, changed.Order)
+		}
+		return nil
+	})
+}
+
+func (lu *loyaltyUseCase) CreateLoyaltyRule(ctx context.Context, rule request.LoyaltyRule) (response.LoyaltyRule, error) {
+	if rule.CategoryID != 0 {
+		category, err := lu.productRepo.FindCategoryByID(ctx, rule.CategoryID)
+		if err != nil {
+			return response.LoyaltyRule{}, fmt.Errorf("Failed to find category :%s", err)
+		}
+		if category.ID == 0 {
+			return response.LoyaltyRule{}, ErrCategoryNotFound
+		}
+	}
+
+	newRule, err := lu.loyaltyRepo.CreateLoyaltyRule(ctx, rule)
+	if err != nil {
+		return response.LoyaltyRule{}, fmt.Errorf("Failed to create loyalty rule :%s", err)
+	}
+	if newRule.ID == 0 {
+		return response.LoyaltyRule{}, fmt.Errorf("Failed to verify created loyalty rule")
+	}
+	return newRule, nil
+}
+
+func (lu *loyaltyUseCase) UpdateLoyaltyRule(ctx context.Context, ruleID int, rule request.LoyaltyRule) error {
+	if rule.CategoryID != 0 {
+		category, err := lu.productRepo.FindCategoryByID(ctx, rule.CategoryID)
+		if err != nil {
+			return fmt.Errorf("Failed to find category :%s", err)
+		}
+		if category.ID == 0 {
+			return ErrCategoryNotFound
+		}
+	}
+
+	updatedRule, err := lu.loyaltyRepo.UpdateLoyaltyRule(ctx, ruleID, rule)
+	if err != nil {
+		return fmt.Errorf("Failed to update loyalty rule :%s", err)
+	}
+	if updatedRule.ID == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+func (lu *loyaltyUseCase) ListLoyaltyRules(ctx context.Context) ([]response.LoyaltyRule, error) {
+	rules, err := lu.loyaltyRepo.GetAllLoyaltyRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get loyalty rules :%s", err)
+	}
+	return rules, nil
+}
+
+func (lu *loyaltyUseCase) BlockLoyaltyRule(ctx context.Context, ruleID int) error {
+	rule, err := lu.loyaltyRepo.BlockLoyaltyRule(ctx, ruleID, true)
+	if err != nil {
+		return fmt.Errorf("Failed to block loyalty rule :%s", err)
+	}
+	if rule.ID == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+func (lu *loyaltyUseCase) UnBlockLoyaltyRule(ctx context.Context, ruleID int) error {
+	rule, err := lu.loyaltyRepo.BlockLoyaltyRule(ctx, ruleID, false)
+	if err != nil {
+		return fmt.Errorf("Failed to unblock loyalty rule :%s", err)
+	}
+	if rule.ID == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+func (lu *loyaltyUseCase) GetPointsHistory(ctx context.Context, userID, page, count int) (response.LoyaltyHistory, error) {
+	balance, err := lu.loyaltyRepo.GetLoyaltyBalance(ctx, userID, time.Now())
+	if err != nil {
+		return response.LoyaltyHistory{}, fmt.Errorf("Failed to get points balance :%s", err)
+	}
+
+	startIndex, endIndex := helper.Paginate(page, count)
+	history, err := lu.loyaltyRepo.GetLoyaltyHistory(ctx, userID, startIndex, endIndex)
+	if err != nil {
+		return response.LoyaltyHistory{}, fmt.Errorf("Failed to get points history :%s", err)
+	}
+
+	return response.LoyaltyHistory{
+		Balance:      balance,
+		PointValue:   loyaltyPointValue,
+		Transactions: history,
+	}, nil
+}
+
+func (lu *loyaltyUseCase) ApplyPoints(ctx context.Context, userID, points int) error {
+	balance, err := lu.loyaltyRepo.GetLoyaltyBalance(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to get points balance :%s", err)
+	}
+	if points > balance {
+		return ErrInsufficientPoints
+	}
+
+	applied, err := lu.loyaltyRepo.SetAppliedPoints(ctx, userID, points)
+	if err != nil {
+		return fmt.Errorf("Failed to apply points :%s", err)
+	}
+	if applied.ID == 0 {
+		return fmt.Errorf("Failed to verify applied points")
+	}
+	return nil
+}
+
+func (lu *loyaltyUseCase) RemoveAppliedPoints(ctx context.Context, userID int) error {
+	err := lu.loyaltyRepo.RemoveAppliedPoints(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to remove applied points :%s", err)
+	}
+	return nil
+}
+
+// EarnPoints credits points on what was paid for the line, points paid with do not earn points.
+func (lu *loyaltyUseCase) EarnPoints(ctx context.Context, order response.OrderLine) error {
+	earned, err := lu.loyaltyRepo.FindLoyaltyTransaction(ctx, int(order.ID), pointsEarn)
+	if err != nil {
+		return fmt.Errorf("Failed to find earned points :%s", err)
+	}
+	if earned.ID != 0 {
+		return nil
+	}
+
+	product, err := lu.productRepo.FindProductByID(ctx, int(order.ProductID))
+	if err != nil {
+		return fmt.Errorf("Failed to find product :%s", err)
+	}
+
+	rules, err := lu.loyaltyRepo.GetAllLoyaltyRules(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get loyalty rules :%s", err)
+	}
+
+	amount := order.Price*float32(order.Qty) - order.Discount - float32(order.PointsRedeemed*loyaltyPointValue)
+	points := helper.LoyaltyPoints(amount, uint(product.CategoryID), rules, defaultPointsPercent)
+	if points == 0 {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(pointsValidity)
+	transaction, err := lu.loyaltyRepo.InsertLoyaltyTransaction(ctx, request.LoyaltyTransaction{
+		UserID:          int(order.UserID),
+		OrderID:         int(order.ID),
+		Points:          points,
+		RemainingPoints: points,
+		Type:            pointsEarn,
+		ExpiresAt:       &expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to credit points :%s", err)
+	}
+	if transaction.ID == 0 {
+		return fmt.Errorf("Failed to verify credited points")
+	}
+	return nil
+}
+
+func (lu *loyaltyUseCase) RedeemPoints(ctx context.Context, userID, orderID, points int) error {
+	if points <= 0 {
+		return nil
+	}
+
+	balance, err := lu.loyaltyRepo.GetLoyaltyBalance(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to get points balance :%s", err)
+	}
+	if points > balance {
+		return ErrInsufficientPoints
+	}
+
+	_, err = lu.consumePoints(ctx, userID, points)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := lu.loyaltyRepo.InsertLoyaltyTransaction(ctx, request.LoyaltyTransaction{
+		UserID:  userID,
+		OrderID: orderID,
+		Points:  -points,
+		Type:    pointsRedeem,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to redeem points :%s", err)
+	}
+	if transaction.ID == 0 {
+		return fmt.Errorf("Failed to verify redeemed points")
+	}
+	return nil
+}
+
+// RefundPoints gives the points back as a new lot with a fresh validity.
+func (lu *loyaltyUseCase) RefundPoints(ctx context.Context, order response.OrderLine) error {
+	if order.PointsRedeemed <= 0 {
+		return nil
+	}
+
+	refunded, err := lu.loyaltyRepo.FindLoyaltyTransaction(ctx, int(order.ID), pointsRefund)
+	if err != nil {
+		return fmt.Errorf("Failed to find refunded points :%s", err)
+	}
+	if refunded.ID != 0 {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(pointsValidity)
+	transaction, err := lu.loyaltyRepo.InsertLoyaltyTransaction(ctx, request.LoyaltyTransaction{
+		UserID:          int(order.UserID),
+		OrderID:         int(order.ID),
+		Points:          order.PointsRedeemed,
+		RemainingPoints: order.PointsRedeemed,
+		Type:            pointsRefund,
+		ExpiresAt:       &expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to refund points :%s", err)
+	}
+	if transaction.ID == 0 {
+		return fmt.Errorf("Failed to verify refunded points")
+	}
+	return nil
+}
+
+// ReversePoints takes back the points earned on the line, first from what is
+// left of that lot and then from the other lots. Points already spent beyond
+// the balance are not taken back.
+func (lu *loyaltyUseCase) ReversePoints(ctx context.Context, order response.OrderLine) error {
+	earned, err := lu.loyaltyRepo.FindLoyaltyTransaction(ctx, int(order.ID), pointsEarn)
+	if err != nil {
+		return fmt.Errorf("Failed to find earned points :%s", err)
+	}
+	if earned.ID == 0 {
+		return nil
+	}
+
+	reversed, err := lu.loyaltyRepo.FindLoyaltyTransaction(ctx, int(order.ID), pointsReverse)
+	if err != nil {
+		return fmt.Errorf("Failed to find reversed points :%s", err)
+	}
+	if reversed.ID != 0 {
+		return nil
+	}
+
+	taken := earned.RemainingPoints
+	if taken > 0 {
+		_, err = lu.loyaltyRepo.UpdateRemainingPoints(ctx, int(earned.ID), 0)
+		if err != nil {
+			return fmt.Errorf("Failed to update earned points :%s", err)
+		}
+	}
+
+	if taken < earned.Points {
+		consumed, err := lu.consumePoints(ctx, int(order.UserID), earned.Points-taken)
+		if err != nil {
+			return err
+		}
+		taken += consumed
+	}
+
+	if taken == 0 {
+		return nil
+	}
+
+	transaction, err := lu.loyaltyRepo.InsertLoyaltyTransaction(ctx, request.LoyaltyTransaction{
+		UserID:  int(order.UserID),
+		OrderID: int(order.ID),
+		Points:  -taken,
+		Type:    pointsReverse,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to reverse points :%s", err)
+	}
+	if transaction.ID == 0 {
+		return fmt.Errorf("Failed to verify reversed points")
+	}
+	return nil
+}
+
+// consumePoints takes up to points from the user's lots, the ones expiring first
+// are used first. It returns the points taken.
+func (lu *loyaltyUseCase) consumePoints(ctx context.Context, userID, points int) (int, error) {
+	lots, err := lu.loyaltyRepo.GetPointLots(ctx, userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get points :%s", err)
+	}
+
+	var consumed int
+	for _, lot := range lots {
+		if consumed == points {
+			break
+		}
+
+		take := lot.RemainingPoints
+		if take > points-consumed {
+			take = points - consumed
+		}
+
+		updatedLot, err := lu.loyaltyRepo.UpdateRemainingPoints(ctx, int(lot.ID), lot.RemainingPoints-take)
+		if err != nil {
+			return consumed, fmt.Errorf("Failed to update points :%s", err)
+		}
+		if updatedLot.ID == 0 {
+			return consumed, fmt.Errorf("Failed to verify updated points")
+		}
+		consumed += take
+	}
+	return consumed, nil
+}
+
+func (lu *loyaltyUseCase) ExpirePoints(ctx context.Context) error {
+	lots, err := lu.loyaltyRepo.GetExpiredPointLots(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to get expired points :%s", err)
+	}
+
+	for _, lot := range lots {
+		_, err = lu.loyaltyRepo.UpdateRemainingPoints(ctx, int(lot.ID), 0)
+		if err != nil {
+			return fmt.Errorf("Failed to expire points :%s", err)
+		}
+
+		_, err = lu.loyaltyRepo.InsertLoyaltyTransaction(ctx, request.LoyaltyTransaction{
+			UserID:  int(lot.UserID),
+			OrderID: int(lot.OrderID),
+			Points:  -lot.RemainingPoints,
+			Type:    pointsExpire,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to record expired points :%s", err)
+		}
+	}
+	return nil
+}
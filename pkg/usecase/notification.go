@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"text/template"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+const (
+	notifyOrderPlaced        = "order_placed"
+	notifyOrderStatusChanged = "order_status_changed"
+	notifyOrderCancelled     = "order_cancelled"
+	notifyRefundIssued       = "refund_issued"
+	notifyPaymentCaptured    = "payment_captured"
+	notifyUserRegistered     = "user_registered"
+)
+
+const (
+	notificationPending = "pending"
+	notificationFailed  = "failed"
+
+	maxNotificationAttempts = 5
+	notificationBatch       = 50
+	notificationLease       = 5 * time.Minute // time a claimed notification has to be sent before it is claimed again
+	notificationBackoff     = time.Minute     // doubled on every failed attempt
+)
+
+// notificationTemplate is the message of an event, the sms is kept to a line.
+type notificationTemplate struct {
+	subject *template.Template
+	email   *template.Template
+	sms     *template.Template
+}
+
+func newNotificationTemplate(subject, email, sms string) notificationTemplate {
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		email:   template.Must(template.New("email").Parse(email)),
+		sms:     template.Must(template.New("sms").Parse(sms)),
+	}
+}
+
+var notificationTemplates = map[string]notificationTemplate{
+	notifyOrderPlaced: newNotificationTemplate(
+		`Your order #{{.OrderID}} is placed`,
+		`Hi {{.UserName}},
+
+Thank you for shopping with Device Mart, your order #{{.OrderID}} is placed.
+{{range .Items}}
+  {{.ProductName}} x {{.Qty}}{{end}}
+
+Total: {{printf "%.2f" .Amount}}`,
+		`Device Mart: your order #{{.OrderID}} of {{len .Items}} item(s) worth {{printf "%.2f" .Amount}} is placed.`,
+	),
+	notifyOrderStatusChanged: newNotificationTemplate(
+		`Your order #{{.OrderID}} is {{.Status}}`,
+		`Hi {{.UserName}},
+
+Your order #{{.OrderID}} of {{.ProductName}} is now {{.Status}}.`,
+		`Device Mart: your order #{{.OrderID}} of {{.ProductName}} is now {{.Status}}.`,
+	),
+	notifyOrderCancelled: newNotificationTemplate(
+		`Your order #{{.OrderID}} is cancelled`,
+		`Hi {{.UserName}},
+
+Your order #{{.OrderID}} of {{.ProductName}} is cancelled.`,
+		`Device Mart: your order #{{.OrderID}} of {{.ProductName}} is cancelled.`,
+	),
+	notifyRefundIssued: newNotificationTemplate(
+		`Refund for your order #{{.OrderID}}`,
+		`Hi {{.UserName}},
+
+{{printf "%.2f" .Amount}} for your order #{{.OrderID}} of {{.ProductName}} is refunded to your wallet.`,
+		`Device Mart: {{printf "%.2f" .Amount}} for order #{{.OrderID}} is refunded to your wallet.`,
+	),
+	notifyPaymentCaptured: newNotificationTemplate(
+		`Payment received for your order #{{.OrderID}}`,
+		`Hi {{.UserName}},
+
+We received your payment of {{printf "%.2f" .Amount}} for the order #{{.OrderID}}.`,
+		`Device Mart: payment of {{printf "%.2f" .Amount}} received for order #{{.OrderID}}.`,
+	),
+	notifyUserRegistered: newNotificationTemplate(
+		`Welcome to Device Mart`,
+		`Hi {{.UserName}},
+
+Welcome to Device Mart, your account is ready.`,
+		`Welcome to Device Mart {{.UserName}}, your account is ready.`,
+	),
+}
+
+type notificationUseCase struct {
+	notificationRepo interfaces.NotificationRepository
+	userRepo         interfaces.UserRepository
+	productRepo      interfaces.ProductRepository
+	senders          map[string]helper.Sender
+}
+
+func NewNotificationUseCase(notificationRepo interfaces.NotificationRepository, userRepo interfaces.UserRepository, productRepo interfaces.ProductRepository, senders []helper.Sender) services.NotificationUseCase {
+	senderByChannel := make(map[string]helper.Sender, len(senders))
+	for _, sender := range senders {
+		senderByChannel[sender.Channel()] = sender
+	}
+
+	return &notificationUseCase{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		productRepo:      productRepo,
+		senders:          senderByChannel,
+	}
+}
+
+// Subscribe notifies the users of the order, payment and sign up events.
+func (nu *notificationUseCase) Subscribe(bus services.EventUseCase) {
+	bus.Subscribe(EventOrderPlaced, "notification", func(ctx context.Context, event response.DomainEvent) error {
+		var placed request.OrderPlaced
+		if err := decodeEvent(event, &placed); err != nil {
+			return err
+		}
+		return nu.Notify(ctx, request.Notification{
+			Event:   notifyOrderPlaced,
+			UserID:  placed.UserID,
+			OrderID: firstOrderID(placed.OrderIDs),
+			Items:   placed.Items,
+			Amount:  placed.Amount,
+		})
+	})
+
+	bus.Subscribe(EventOrderStatusChanged, "notification", func(ctx context.Context, event response.DomainEvent) error {
+		var changed request.OrderStatusChanged
+		if err := decodeEvent(event, &changed); err != nil {
+			return err
+		}
+		notifyEvent := notifyOrderStatusChanged
+		if changed.Status == statusCancelled {
+			notifyEvent = notifyOrderCancelled
+		}
+		return nu.notifyOrderLine(ctx, notifyEvent, int(changed.Order.UserID), int(changed.Order.ID), int(changed.Order.ProductID), changed.Status, 0)
+	})
+
+	bus.Subscribe(EventRefundIssued, "notification", func(ctx context.Context, event response.DomainEvent) error {
+		var refund request.RefundIssued
+		if err := decodeEvent(event, &refund); err != nil {
+			return err
+		}
+		return nu.notifyOrderLine(ctx, notifyRefundIssued, refund.UserID, refund.OrderID, refund.ProductID, "", refund.Amount)
+	})
+
+	bus.Subscribe(EventPaymentCaptured, "notification", func(ctx context.Context, event response.DomainEvent) error {
+		var payment request.PaymentCaptured
+		if err := decodeEvent(event, &payment); err != nil {
+			return err
+		}
+		return nu.Notify(ctx, request.Notification{
+			Event:   notifyPaymentCaptured,
+			UserID:  payment.UserID,
+			OrderID: firstOrderID(payment.OrderIDs),
+			Amount:  payment.Amount,
+		})
+	})
+
+	bus.Subscribe(EventUserRegistered, "notification", func(ctx context.Context, event response.DomainEvent) error {
+		var registered request.UserRegistered
+		if err := decodeEvent(event, &registered); err != nil {
+			return err
+		}
+		return nu.Notify(ctx, request.Notification{
+			Event:  notifyUserRegistered,
+			UserID: registered.UserID,
+		})
+	})
+}
+
+// notifyOrderLine notifies the user of an event of the order line.
+func (nu *notificationUseCase) notifyOrderLine(ctx context.Context, event string, userID, orderID, productID int, status string, amount float32) error {
+	product, err := nu.productRepo.FindProductByID(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("Failed to find product :%s", err)
+	}
+
+	return nu.Notify(ctx, request.Notification{
+		Event:       event,
+		UserID:      userID,
+		OrderID:     orderID,
+		ProductName: product.ProductName,
+		Status:      status,
+		Amount:      amount,
+	})
+}
+
+func firstOrderID(orderIDs []int) int {
+	if len(orderIDs) == 0 {
+		return 0
+	}
+	return orderIDs[0]
+}
+
+// Notify renders the event for every channel the user gets notifications on
+// and puts the messages in the outbox, the dispatcher sends them.
+func (nu *notificationUseCase) Notify(ctx context.Context, notification request.Notification) error {
+	tmpl, ok := notificationTemplates[notification.Event]
+	if !ok {
+		return fmt.Errorf("no template for notification event %s", notification.Event)
+	}
+
+	user, err := nu.userRepo.FindUserByID(ctx, notification.UserID)
+	if err != nil {
+		return fmt.Errorf("Failed to find user :%s", err)
+	}
+	if user.ID == 0 {
+		return ErrNoRecord
+	}
+	notification.UserName = user.UserName
+
+	preference, err := nu.GetPreferences(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for channel := range nu.senders {
+		var recipient string
+		var body *template.Template
+		switch channel {
+		case helper.ChannelEmail:
+			recipient, body = user.Email, tmpl.email
+			if !preference.Email {
+				continue
+			}
+		case helper.ChannelSMS:
+			if user.Phone != 0 {
+				recipient = fmt.Sprint(user.Phone)
+			}
+			body = tmpl.sms
+			if !preference.SMS {
+				continue
+			}
+		}
+		if recipient == "" {
+			continue
+		}
+
+		subject, err := renderTemplate(tmpl.subject, notification)
+		if err != nil {
+			return err
+		}
+		message, err := renderTemplate(body, notification)
+		if err != nil {
+			return err
+		}
+
+		outboxed, err := nu.notificationRepo.InsertNotification(ctx, request.OutboxNotification{
+			UserID:        notification.UserID,
+			Event:         notification.Event,
+			Channel:       channel,
+			Recipient:     recipient,
+			Subject:       subject,
+			Body:          message,
+			NextAttemptAt: now,
+		}, now)
+		if err != nil {
+			return fmt.Errorf("Failed to insert notification :%s", err)
+		}
+		if outboxed.ID == 0 {
+			return fmt.Errorf("Failed to verify inserted notification")
+		}
+	}
+
+	return nil
+}
+
+func renderTemplate(tmpl *template.Template, data request.Notification) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("Failed to render %s template :%s", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// DispatchNotifications sends the due notifications of the outbox. A failed send
+// is retried with a doubling backoff and given up after maxNotificationAttempts.
+func (nu *notificationUseCase) DispatchNotifications(ctx context.Context) error {
+	now := time.Now()
+	notifications, err := nu.notificationRepo.ClaimDueNotifications(ctx, now, now.Add(notificationLease), notificationBatch)
+	if err != nil {
+		return fmt.Errorf("Failed to claim notifications :%s", err)
+	}
+
+	for _, notification := range notifications {
+		sender, ok := nu.senders[notification.Channel]
+		if !ok {
+			err = fmt.Errorf("no sender for channel %s", notification.Channel)
+		} else {
+			err = sender.Send(ctx, notification.Recipient, notification.Subject, notification.Body)
+		}
+
+		if err == nil {
+			if err := nu.notificationRepo.MarkNotificationSent(ctx, int(notification.ID), time.Now()); err != nil {
+				return fmt.Errorf("Failed to mark notification sent :%s", err)
+			}
+			continue
+		}
+
+		attempts := notification.Attempts + 1
+		status := notificationPending
+		if attempts >= maxNotificationAttempts {
+			status = notificationFailed
+		}
+		nextAttemptAt := time.Now().Add(notificationBackoff * time.Duration(math.Pow(2, float64(attempts-1))))
+
+		helper.LoggerFrom(ctx).Warn("notification not sent", "notification_id", notification.ID, "attempts", attempts, "error", err)
+		if err := nu.notificationRepo.MarkNotificationFailed(ctx, int(notification.ID), attempts, err.Error(), status, nextAttemptAt); err != nil {
+			return fmt.Errorf("Failed to update notification :%s", err)
+		}
+	}
+
+	return nil
+}
+
+func (nu *notificationUseCase) GetPreferences(ctx context.Context, userID int) (response.NotificationPreference, error) {
+	preference, found, err := nu.notificationRepo.FindNotificationPreference(ctx, userID)
+	if err != nil {
+		return response.NotificationPreference{}, fmt.Errorf("Failed to find notification preference :%s", err)
+	}
+	if !found {
+		return response.NotificationPreference{Email: true, SMS: true}, nil
+	}
+	return preference, nil
+}
+
+func (nu *notificationUseCase) UpdatePreferences(ctx context.Context, userID int, preference request.NotificationPreference) (response.NotificationPreference, error) {
+	updated, err := nu.notificationRepo.UpsertNotificationPreference(ctx, userID, preference, time.Now())
+	if err != nil {
+		return response.NotificationPreference{}, fmt.Errorf("Failed to update notification preference :%s", err)
+	}
+	return updated, nil
+}
+
+func (nu *notificationUseCase) GetNotifications(ctx context.Context, userID, page, count int) ([]response.Notification, error) {
+	startIndex, endIndex := helper.Paginate(page, count)
+
+	notifications, err := nu.notificationRepo.GetUserNotifications(ctx, userID, startIndex, endIndex)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get notifications :%s", err)
+	}
+	return notifications, nil
+}
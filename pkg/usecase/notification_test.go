@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+// fakeNotificationRepo keeps the outbox in memory and claims like the repository,
+// by moving the next attempt of the claimed notifications to the end of the lease.
+type fakeNotificationRepo struct {
+	interfaces.NotificationRepository
+	notifications []response.Notification
+	preferences   map[int]response.NotificationPreference
+}
+
+func (f *fakeNotificationRepo) InsertNotification(ctx context.Context, notification request.OutboxNotification, now time.Time) (response.Notification, error) {
+	inserted := response.Notification{
+		ID:            uint(len(f.notifications) + 1),
+		UserID:        notification.UserID,
+		Event:         notification.Event,
+		Channel:       notification.Channel,
+		Recipient:     notification.Recipient,
+		Subject:       notification.Subject,
+		Body:          notification.Body,
+		Status:        notificationPending,
+		NextAttemptAt: notification.NextAttemptAt,
+		CreatedAt:     now,
+	}
+	f.notifications = append(f.notifications, inserted)
+	return inserted, nil
+}
+
+func (f *fakeNotificationRepo) ClaimDueNotifications(ctx context.Context, now, leaseUntil time.Time, limit int) ([]response.Notification, error) {
+	var claimed []response.Notification
+	for i := range f.notifications {
+		notification := &f.notifications[i]
+		if notification.Status != notificationPending || notification.NextAttemptAt.After(now) || len(claimed) == limit {
+			continue
+		}
+		notification.NextAttemptAt = leaseUntil
+		claimed = append(claimed, *notification)
+	}
+	return claimed, nil
+}
+
+func (f *fakeNotificationRepo) MarkNotificationSent(ctx context.Context, notificationID int, now time.Time) error {
+	notification := &f.notifications[notificationID-1]
+	notification.Status = "sent"
+	notification.Attempts++
+	notification.SentAt = &now
+	return nil
+}
+
+func (f *fakeNotificationRepo) MarkNotificationFailed(ctx context.Context, notificationID, attempts int, lastError, status string, nextAttemptAt time.Time) error {
+	notification := &f.notifications[notificationID-1]
+	notification.Status = status
+	notification.Attempts = attempts
+	notification.LastError = lastError
+	notification.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (f *fakeNotificationRepo) FindNotificationPreference(ctx context.Context, userID int) (response.NotificationPreference, bool, error) {
+	preference, ok := f.preferences[userID]
+	return preference, ok, nil
+}
+
+// failingSender refuses every message, like a provider that is down.
+type failingSender struct {
+	channel string
+}
+
+func (f failingSender) Channel() string {
+	return f.channel
+}
+
+func (f failingSender) Send(ctx context.Context, to, subject, body string) error {
+	return errors.New("provider is down")
+}
+
+func newNotificationTest(senders ...helper.Sender) (*notificationUseCase, *fakeNotificationRepo) {
+	users := &fakeUserRepo{users: map[int]response.UserData{
+		1: {ID: 1, UserName: "user", Email: "user@example.com", Phone: 9876543210},
+	}}
+	notificationRepo := &fakeNotificationRepo{preferences: map[int]response.NotificationPreference{}}
+	useCase := NewNotificationUseCase(notificationRepo, users, nil, senders).(*notificationUseCase)
+	return useCase, notificationRepo
+}
+
+var testOrderPlaced = request.Notification{
+	Event:   notifyOrderPlaced,
+	UserID:  1,
+	OrderID: 7,
+	Items:   []request.NotificationItem{{ProductName: "Phone", Qty: 2}},
+	Amount:  1999.5,
+}
+
+func TestNotificationsSentByLocalProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	sms := helper.NewCaptureSender(helper.ChannelSMS)
+	useCase, notificationRepo := newNotificationTest(helper.NewFileSender(helper.ChannelEmail, path), sms)
+	ctx := context.Background()
+
+	if err := useCase.Notify(ctx, testOrderPlaced); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(notificationRepo.notifications) != 2 {
+		t.Fatalf("outboxed %d notifications, want one per channel", len(notificationRepo.notifications))
+	}
+
+	if err := useCase.DispatchNotifications(ctx); err != nil {
+		t.Fatalf("DispatchNotifications() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"to: user@example.com subject: Your order #7 is placed", "Hi user,", "Phone x 2", "Total: 1999.50"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("email %q does not have %q", content, want)
+		}
+	}
+
+	message, ok := sms.Last("9876543210")
+	if !ok || message.Body != "Device Mart: your order #7 of 1 item(s) worth 1999.50 is placed." {
+		t.Errorf("sms = %+v, %v, want the order placed sms", message, ok)
+	}
+
+	for _, notification := range notificationRepo.notifications {
+		if notification.Status != "sent" || notification.Attempts != 1 || notification.SentAt == nil {
+			t.Errorf("notification %d is %s after %d attempts, want sent after 1", notification.ID, notification.Status, notification.Attempts)
+		}
+	}
+
+	// a sent notification is not sent again
+	if err := useCase.DispatchNotifications(ctx); err != nil {
+		t.Fatalf("second DispatchNotifications() error = %v", err)
+	}
+	if len(sms.Messages()) != 1 {
+		t.Errorf("sent %d sms, want 1", len(sms.Messages()))
+	}
+}
+
+func TestNotifyFollowsPreferences(t *testing.T) {
+	useCase, notificationRepo := newNotificationTest(helper.NewCaptureSender(helper.ChannelEmail), helper.NewCaptureSender(helper.ChannelSMS))
+	notificationRepo.preferences[1] = response.NotificationPreference{Email: true, SMS: false}
+
+	if err := useCase.Notify(context.Background(), testOrderPlaced); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(notificationRepo.notifications) != 1 || notificationRepo.notifications[0].Channel != helper.ChannelEmail {
+		t.Errorf("outboxed %+v, want only the email", notificationRepo.notifications)
+	}
+}
+
+func TestDispatchNotificationsRetriesFailedSend(t *testing.T) {
+	useCase, notificationRepo := newNotificationTest(failingSender{channel: helper.ChannelEmail})
+	ctx := context.Background()
+
+	if err := useCase.Notify(ctx, testOrderPlaced); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	for attempt := 1; attempt <= maxNotificationAttempts; attempt++ {
+		started := time.Now()
+		if err := useCase.DispatchNotifications(ctx); err != nil {
+			t.Fatalf("DispatchNotifications() error = %v", err)
+		}
+
+		notification := notificationRepo.notifications[0]
+		if notification.Attempts != attempt || notification.LastError != "provider is down" {
+			t.Fatalf("attempt %d: notification has %d attempts and error %q", attempt, notification.Attempts, notification.LastError)
+		}
+		if attempt == maxNotificationAttempts {
+			if notification.Status != notificationFailed {
+				t.Errorf("status after %d attempts = %s, want %s", attempt, notification.Status, notificationFailed)
+			}
+			break
+		}
+
+		backoff := notificationBackoff << (attempt - 1)
+		if notification.Status != notificationPending || notification.NextAttemptAt.Before(started.Add(backoff)) {
+			t.Fatalf("attempt %d: status %s, next attempt in %s, want pending after %s",
+				attempt, notification.Status, notification.NextAttemptAt.Sub(started), backoff)
+		}
+
+		// not due until the backoff is over
+		if err := useCase.DispatchNotifications(ctx); err != nil {
+			t.Fatalf("DispatchNotifications() error = %v", err)
+		}
+		if notificationRepo.notifications[0].Attempts != attempt {
+			t.Fatalf("attempt %d: retried before the backoff", attempt)
+		}
+		notificationRepo.notifications[0].NextAttemptAt = time.Now()
+	}
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUnknownProvider         = errors.New("unknown login provider")
+	ErrProviderEmailUnverified = errors.New("the email of the provider account is not verified")
+	ErrAccountEmailUnverified  = errors.New("an account with this email exists but its email is not verified, log in with its password and verify the email first")
+)
+
+// a social login must come back from the provider within this time
+const oidcStateMaxAge = 10 * time.Minute
+
+type oidcUseCase struct {
+	oidcRepo   interfaces.OIDCRepository
+	userRepo   interfaces.UserRepository
+	transactor interfaces.Transactor
+	providers  map[string]*helper.OIDCProvider
+}
+
+func NewOIDCUseCase(oidcRepo interfaces.OIDCRepository, userRepo interfaces.UserRepository, transactor interfaces.Transactor, providers []*helper.OIDCProvider) services.OIDCUseCase {
+	byName := make(map[string]*helper.OIDCProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name] = provider
+	}
+
+	return &oidcUseCase{
+		oidcRepo:   oidcRepo,
+		userRepo:   userRepo,
+		transactor: transactor,
+		providers:  byName,
+	}
+}
+
+func (ou *oidcUseCase) Providers(ctx context.Context) []string {
+	names := make([]string, 0, len(ou.providers))
+	for name := range ou.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoginURL starts a social login and returns the url of the provider, the state,
+// the nonce and the PKCE verifier are kept until the callback.
+func (ou *oidcUseCase) LoginURL(ctx context.Context, providerName, loginHint string) (string, error) {
+	provider, ok := ou.providers[providerName]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	loginState := response.OIDCLoginState{Provider: providerName, CreatedAt: time.Now()}
+	var err error
+	if loginState.State, err = helper.RandomToken(24); err != nil {
+		return "", err
+	}
+	if loginState.Nonce, err = helper.RandomToken(24); err != nil {
+		return "", err
+	}
+	if loginState.CodeVerifier, err = helper.RandomToken(32); err != nil {
+		return "", err
+	}
+
+	if err := ou.oidcRepo.DeleteLoginStatesBefore(ctx, time.Now().Add(-oidcStateMaxAge)); err != nil {
+		helper.LoggerFrom(ctx).Warn("old oidc login states not deleted", "error", err)
+	}
+
+	loginState, err = ou.oidcRepo.InsertLoginState(ctx, loginState)
+	if err != nil {
+		return "", fmt.Errorf("Failed to save login state :%s", err)
+	}
+	if loginState.ID == 0 {
+		return "", fmt.Errorf("Failed to verify saved login state")
+	}
+
+	return provider.AuthCodeURL(loginState.State, loginState.Nonce, loginState.CodeVerifier, loginHint)
+}
+
+// Callback completes the social login and returns the user. The provider account
+// is linked to the user with the same verified email, or a new user is created.
+func (ou *oidcUseCase) Callback(ctx context.Context, providerName, code, state string) (response.UserData, error) {
+	provider, ok := ou.providers[providerName]
+	if !ok {
+		return response.UserData{}, ErrUnknownProvider
+	}
+
+	loginState, err := ou.oidcRepo.ConsumeLoginState(ctx, state)
+	if err != nil {
+		return response.UserData{}, fmt.Errorf("Failed to find login state :%s", err)
+	}
+	if loginState.ID == 0 || loginState.Provider != providerName || time.Since(loginState.CreatedAt) > oidcStateMaxAge {
+		return response.UserData{}, ErrInvalidToken
+	}
+
+	idToken, err := provider.Exchange(code, loginState.CodeVerifier)
+	if err != nil {
+		return response.UserData{}, err
+	}
+	claims, err := provider.VerifyIDToken(idToken, loginState.Nonce)
+	if err != nil {
+		return response.UserData{}, err
+	}
+
+	userData, err := ou.findOrCreateUser(ctx, providerName, claims)
+	if err != nil {
+		return response.UserData{}, err
+	}
+	if userData.IsBlocked {
+		return response.UserData{}, fmt.Errorf("User has been blocked")
+	}
+
+	userData.Password = ""
+	return userData, nil
+}
+
+func (ou *oidcUseCase) findOrCreateUser(ctx context.Context, providerName string, claims helper.OIDCClaims) (response.UserData, error) {
+	identity, err := ou.oidcRepo.FindIdentity(ctx, providerName, claims.Subject)
+	if err != nil {
+		return response.UserData{}, fmt.Errorf("Failed to find identity :%s", err)
+	}
+	if identity.ID != 0 {
+		userData, err := ou.userRepo.FindUserByID(ctx, identity.UserID)
+		if err != nil {
+			return response.UserData{}, fmt.Errorf("Failed to find user :%s", err)
+		}
+		return userData, nil
+	}
+
+	// a provider account is linked only by an email the provider verified
+	if claims.Email == "" || !claims.EmailVerified {
+		return response.UserData{}, ErrProviderEmailUnverified
+	}
+
+	userData, err := ou.userRepo.FindUserByEmail(ctx, claims.Email)
+	if err != nil {
+		return response.UserData{}, fmt.Errorf("Failed to find user by email :%s", err)
+	}
+
+	// an account whose owner never proved the email may have been signed up by
+	// someone else with a password they know, linking it would hand it over
+	if userData.ID != 0 && !userData.EmailVerified {
+		return response.UserData{}, ErrAccountEmailUnverified
+	}
+
+	now := time.Now()
+	err = ou.transactor.Transaction(ctx, func(repos interfaces.TxRepositories) error {
+		if userData.ID == 0 {
+			userData, err = createProviderUser(ctx, repos, claims)
+			if err != nil {
+				return err
+			}
+
+			// the provider verified the email of the new account
+			if err := repos.User.MarkEmailVerified(ctx, userData.ID, now); err != nil {
+				return fmt.Errorf("Failed to verify email :%s", err)
+			}
+			userData.EmailVerified = true
+		}
+
+		identity, err := repos.OIDC.InsertIdentity(ctx, response.UserIdentity{
+			UserID:    userData.ID,
+			Provider:  providerName,
+			Subject:   claims.Subject,
+			Email:     claims.Email,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to link identity :%s", err)
+		}
+		if identity.ID == 0 {
+			return fmt.Errorf("Failed to verify linked identity")
+		}
+		return nil
+	})
+	if err != nil {
+		return response.UserData{}, err
+	}
+
+	return userData, nil
+}
+
+// createProviderUser signs up the user of the provider account. The account has
+// no phone number and an unusable password, the user can set one with the forgot
+// password flow.
+func createProviderUser(ctx context.Context, repos interfaces.TxRepositories, claims helper.OIDCClaims) (response.UserData, error) {
+	randomPassword, err := helper.RandomToken(32)
+	if err != nil {
+		return response.UserData{}, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), 10)
+	if err != nil {
+		return response.UserData{}, fmt.Errorf("failed to generate hash from password :%s", err)
+	}
+
+	userName := claims.Name
+	if userName == "" {
+		userName = strings.Split(claims.Email, "@")[0]
+	}
+
+	userData, err := repos.User.CreateUser(ctx, request.SignUpData{
+		UserName: userName,
+		Email:    claims.Email,
+		Password: string(hashedPassword),
+	})
+	if err != nil {
+		return response.UserData{}, fmt.Errorf("Failed to save user on db, user sign up failed :%s", err)
+	}
+	if userData.ID == 0 {
+		return response.UserData{}, fmt.Errorf("Failed to verify saved user")
+	}
+
+	err = publishEvent(ctx, repos.Event, EventUserRegistered, userData.ID, request.UserRegistered{
+		UserID:   userData.ID,
+		UserName: userData.UserName,
+		Email:    userData.Email,
+	})
+	return userData, err
+}
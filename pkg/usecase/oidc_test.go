@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/oidcmock"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeUserRepo keeps the users in memory, only the methods of the social login
+// are implemented.
+type fakeUserRepo struct {
+	interfaces.UserRepository
+	users map[int]response.UserData
+}
+
+func (f *fakeUserRepo) FindUserByID(ctx context.Context, id int) (response.UserData, error) {
+	return f.users[id], nil
+}
+
+func (f *fakeUserRepo) FindUserByEmail(ctx context.Context, email string) (response.UserData, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return response.UserData{}, nil
+}
+
+func (f *fakeUserRepo) CreateUser(ctx context.Context, user request.SignUpData) (response.UserData, error) {
+	created := response.UserData{ID: len(f.users) + 1, UserName: user.UserName, Email: user.Email, Password: user.Password}
+	f.users[created.ID] = created
+	return created, nil
+}
+
+func (f *fakeUserRepo) MarkEmailVerified(ctx context.Context, userID int, verifiedAt time.Time) error {
+	user := f.users[userID]
+	user.EmailVerified = true
+	f.users[userID] = user
+	return nil
+}
+
+type fakeOIDCRepo struct {
+	states     map[string]response.OIDCLoginState
+	identities []response.UserIdentity
+}
+
+func (f *fakeOIDCRepo) InsertLoginState(ctx context.Context, state response.OIDCLoginState) (response.OIDCLoginState, error) {
+	state.ID = len(f.states) + 1
+	f.states[state.State] = state
+	return state, nil
+}
+
+func (f *fakeOIDCRepo) ConsumeLoginState(ctx context.Context, state string) (response.OIDCLoginState, error) {
+	loginState := f.states[state]
+	delete(f.states, state)
+	return loginState, nil
+}
+
+func (f *fakeOIDCRepo) DeleteLoginStatesBefore(ctx context.Context, before time.Time) error {
+	return nil
+}
+
+func (f *fakeOIDCRepo) FindIdentity(ctx context.Context, provider, subject string) (response.UserIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return response.UserIdentity{}, nil
+}
+
+func (f *fakeOIDCRepo) InsertIdentity(ctx context.Context, identity response.UserIdentity) (response.UserIdentity, error) {
+	identity.ID = len(f.identities) + 1
+	f.identities = append(f.identities, identity)
+	return identity, nil
+}
+
+// fakeTransactor runs the function on the same fake repositories, without a rollback.
+type fakeTransactor struct {
+	repos interfaces.TxRepositories
+}
+
+func (f fakeTransactor) Transaction(ctx context.Context, fn func(repos interfaces.TxRepositories) error) error {
+	return fn(f.repos)
+}
+
+type oidcTest struct {
+	useCase  *oidcUseCase
+	users    *fakeUserRepo
+	oidcRepo *fakeOIDCRepo
+}
+
+// newOIDCTest runs the mock provider on a local server and a use case with the
+// "mock" provider pointing at it.
+func newOIDCTest(t *testing.T) *oidcTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	mock, err := oidcmock.NewProvider(server.URL + "/oidc-mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.Register(router.Group("/oidc-mock"))
+
+	users := &fakeUserRepo{users: map[int]response.UserData{}}
+	oidcRepo := &fakeOIDCRepo{states: map[string]response.OIDCLoginState{}}
+	provider := helper.NewOIDCProvider("mock", mock.Issuer, "device-mart", "", "http://localhost:3000/api/v1/auth/oidc/mock/callback")
+	transactor := fakeTransactor{repos: interfaces.TxRepositories{User: users, OIDC: oidcRepo, Event: fakeEventRepo{}}}
+
+	useCase := NewOIDCUseCase(oidcRepo, users, transactor, []*helper.OIDCProvider{provider}).(*oidcUseCase)
+	return &oidcTest{useCase: useCase, users: users, oidcRepo: oidcRepo}
+}
+
+// authorize starts a login and follows the provider to the redirect back, it
+// returns the code and the state of the callback.
+func (o *oidcTest) authorize(t *testing.T, loginHint string, emailVerified bool) (string, string) {
+	t.Helper()
+
+	loginURL, err := o.useCase.LoginURL(context.Background(), "mock", loginHint)
+	if err != nil {
+		t.Fatalf("LoginURL() error = %v", err)
+	}
+	if !emailVerified {
+		loginURL += "&email_verified=false"
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(loginURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestOIDCCallbackCreatesAndLinksUser(t *testing.T) {
+	o := newOIDCTest(t)
+	ctx := context.Background()
+
+	code, state := o.authorize(t, "new.user@example.com", true)
+	userData, err := o.useCase.Callback(ctx, "mock", code, state)
+	if err != nil {
+		t.Fatalf("Callback() error = %v", err)
+	}
+	if userData.ID == 0 || userData.Email != "new.user@example.com" || !userData.EmailVerified {
+		t.Fatalf("Callback() user = %+v, want a new verified user", userData)
+	}
+	if userData.Password != "" {
+		t.Error("Callback() returned the password hash")
+	}
+	if len(o.oidcRepo.identities) != 1 || o.oidcRepo.identities[0].UserID != userData.ID {
+		t.Fatalf("identities = %+v, want one linked to user %d", o.oidcRepo.identities, userData.ID)
+	}
+
+	// the next login finds the user by the linked identity
+	code, state = o.authorize(t, "new.user@example.com", true)
+	again, err := o.useCase.Callback(ctx, "mock", code, state)
+	if err != nil {
+		t.Fatalf("second Callback() error = %v", err)
+	}
+	if again.ID != userData.ID || len(o.users.users) != 1 || len(o.oidcRepo.identities) != 1 {
+		t.Errorf("second login got user %d with %d users and %d identities, want user %d, 1 and 1",
+			again.ID, len(o.users.users), len(o.oidcRepo.identities), userData.ID)
+	}
+}
+
+func TestOIDCCallbackAccountLinking(t *testing.T) {
+	testCases := []struct {
+		name          string
+		emailVerified bool
+		wantErr       error
+	}{
+		{name: "links the account with the verified email", emailVerified: true},
+		{name: "refuses the account whose email was never verified", emailVerified: false, wantErr: ErrAccountEmailUnverified},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOIDCTest(t)
+			o.users.users[1] = response.UserData{ID: 1, UserName: "victim", Email: "victim@example.com", EmailVerified: tc.emailVerified, Password: "hash"}
+
+			code, state := o.authorize(t, "victim@example.com", true)
+			userData, err := o.useCase.Callback(context.Background(), "mock", code, state)
+			if err != tc.wantErr {
+				t.Fatalf("Callback() error = %v, want %v", err, tc.wantErr)
+			}
+
+			if tc.wantErr != nil {
+				if len(o.oidcRepo.identities) != 0 || o.users.users[1].EmailVerified {
+					t.Errorf("refused login linked %d identities or verified the email", len(o.oidcRepo.identities))
+				}
+				return
+			}
+			if userData.ID != 1 || len(o.oidcRepo.identities) != 1 || o.oidcRepo.identities[0].UserID != 1 {
+				t.Errorf("Callback() user %d with identities %+v, want user 1 linked", userData.ID, o.oidcRepo.identities)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackRefusesProviderEmailUnverified(t *testing.T) {
+	o := newOIDCTest(t)
+
+	code, state := o.authorize(t, "unverified@example.com", false)
+	_, err := o.useCase.Callback(context.Background(), "mock", code, state)
+	if err != ErrProviderEmailUnverified {
+		t.Fatalf("Callback() error = %v, want %v", err, ErrProviderEmailUnverified)
+	}
+	if len(o.users.users) != 0 {
+		t.Errorf("created %d users, want none", len(o.users.users))
+	}
+}
+
+func TestOIDCCallbackState(t *testing.T) {
+	testCases := []struct {
+		name    string
+		prepare func(o *oidcTest, state string) (provider, callbackState string)
+	}{
+		{
+			name: "unknown state",
+			prepare: func(o *oidcTest, state string) (string, string) {
+				return "mock", "unknown"
+			},
+		},
+		{
+			name: "state used twice",
+			prepare: func(o *oidcTest, state string) (string, string) {
+				o.oidcRepo.ConsumeLoginState(context.Background(), state)
+				return "mock", state
+			},
+		},
+		{
+			name: "state of another provider",
+			prepare: func(o *oidcTest, state string) (string, string) {
+				loginState := o.oidcRepo.states[state]
+				loginState.Provider = "google"
+				o.oidcRepo.states[state] = loginState
+				return "mock", state
+			},
+		},
+		{
+			name: "expired state",
+			prepare: func(o *oidcTest, state string) (string, string) {
+				loginState := o.oidcRepo.states[state]
+				loginState.CreatedAt = time.Now().Add(-oidcStateMaxAge - time.Minute)
+				o.oidcRepo.states[state] = loginState
+				return "mock", state
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOIDCTest(t)
+			code, state := o.authorize(t, "user@example.com", true)
+
+			provider, callbackState := tc.prepare(o, state)
+			_, err := o.useCase.Callback(context.Background(), provider, code, callbackState)
+			if err != ErrInvalidToken {
+				t.Errorf("Callback() error = %v, want %v", err, ErrInvalidToken)
+			}
+			if len(o.users.users) != 0 {
+				t.Errorf("created %d users, want none", len(o.users.users))
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackPKCE(t *testing.T) {
+	o := newOIDCTest(t)
+	code, state := o.authorize(t, "user@example.com", true)
+
+	// a code intercepted by another client is redeemed without the verifier of the login
+	loginState := o.oidcRepo.states[state]
+	loginState.CodeVerifier = "not-the-verifier"
+	o.oidcRepo.states[state] = loginState
+
+	_, err := o.useCase.Callback(context.Background(), "mock", code, state)
+	if err == nil {
+		t.Fatal("Callback() with the wrong code verifier succeeded")
+	}
+	if len(o.users.users) != 0 {
+		t.Errorf("created %d users, want none", len(o.users.users))
+	}
+}
+
+func TestOIDCCallbackUnknownProvider(t *testing.T) {
+	o := newOIDCTest(t)
+
+	_, err := o.useCase.Callback(context.Background(), "unknown", "code", "state")
+	if err != ErrUnknownProvider {
+		t.Errorf("Callback() error = %v, want %v", err, ErrUnknownProvider)
+	}
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -17,12 +18,41 @@ const (
 	credit = "credit"
 )
 const walletPaymentID = 3
+const onlinePaymentID = 2
+
+const cashOnDelivery = "cash on delivery"
 
 var (
 	ErrNoOrders = errors.New("no orders created yet")
 	ErrNoWallet = errors.New("user does not have a wallet")
+
+	ErrInsufficientBalance = errors.New("insufficient wallet balance")
+	ErrInvalidQuantity     = errors.New("quantity to cancel must be above zero")
+	ErrNotCancellable      = errors.New("order is already delivered, cancelled or returned, a delivered order is returned instead")
 )
 
+// CartValidationError is returned when the cart changed since the user reviewed it,
+// the order is not placed and the issues tell what changed.
+type CartValidationError struct {
+	Issues []response.CartIssue
+}
+
+func (e *CartValidationError) Error() string {
+	return "cart changed, review the cart before placing the order"
+}
+
+// validateCart returns a CartValidationError when any cart line changed.
+func validateCart(ctx context.Context, cartUseCase services.CartUseCase, userID int) error {
+	issues, err := cartUseCase.ValidateCart(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to validate cart :%s", err)
+	}
+	if len(issues) != 0 {
+		return &CartValidationError{Issues: issues}
+	}
+	return nil
+}
+
 type orderUseCase struct {
 	userRepo    interfaces.UserRepository
 	cartUseCase services.CartUseCase
@@ -30,9 +60,15 @@ type orderUseCase struct {
 	orderRepo   interfaces.OrderRepository
 	couponRepo  interfaces.CouponRepository
 	productRepo interfaces.ProductRepository
+
+	loyaltyUseCase services.LoyaltyUseCase
+	walletRepo     interfaces.WalletRepository
+	eventRepo      interfaces.EventRepository
+	transactor     interfaces.Transactor
+	inTx           bool // the repositories are bound to a transaction
 }
 
-func NewOrderUseCase(UserUseCase interfaces.UserRepository, CartUseCase services.CartUseCase, paymentUseCase interfaces.PaymentRepository, OrderUseCase interfaces.OrderRepository, CouponUseCase interfaces.CouponRepository, productUseCase interfaces.ProductRepository) services.OrderUseCase {
+func NewOrderUseCase(UserUseCase interfaces.UserRepository, CartUseCase services.CartUseCase, paymentUseCase interfaces.PaymentRepository, OrderUseCase interfaces.OrderRepository, CouponUseCase interfaces.CouponRepository, productUseCase interfaces.ProductRepository, loyaltyUseCase services.LoyaltyUseCase, walletRepo interfaces.WalletRepository, eventRepo interfaces.EventRepository, transactor interfaces.Transactor) services.OrderUseCase {
 	return &orderUseCase{
 		userRepo:    UserUseCase,
 		cartUseCase: CartUseCase,
@@ -40,12 +76,41 @@ func NewOrderUseCase(UserUseCase interfaces.UserRepository, CartUseCase services
 		orderRepo:   OrderUseCase,
 		couponRepo:  CouponUseCase,
 		productRepo: productUseCase,
+
+		loyaltyUseCase: loyaltyUseCase,
+		walletRepo:     walletRepo,
+		eventRepo:      eventRepo,
+		transactor:     transactor,
 	}
 }
 
-func (ou *orderUseCase) CheckOutDetails(userID int) (response.Checkout, error) {
+// inTransaction runs fn with the repositories and the cart and loyalty use cases
+// bound to one database transaction, so the changes of fn and the events it
+// publishes commit together. A call made within a transaction joins it.
+func (ou *orderUseCase) inTransaction(ctx context.Context, fn func(tx *orderUseCase) error) error {
+	if ou.inTx {
+		return fn(ou)
+	}
+
+	return ou.transactor.Transaction(ctx, func(repos interfaces.TxRepositories) error {
+		tx := *ou
+		tx.userRepo = repos.User
+		tx.paymentRepo = repos.Payment
+		tx.orderRepo = repos.Order
+		tx.couponRepo = repos.Coupon
+		tx.productRepo = repos.Product
+		tx.walletRepo = repos.Wallet
+		tx.eventRepo = repos.Event
+		tx.cartUseCase = ou.cartUseCase.WithTransaction(repos)
+		tx.loyaltyUseCase = ou.loyaltyUseCase.WithTransaction(repos)
+		tx.inTx = true
+		return fn(&tx)
+	})
+}
+
+func (ou *orderUseCase) CheckOutDetails(ctx context.Context, userID int) (response.Checkout, error) {
 
-	addresses, err := ou.userRepo.GetAllUserAddresses(userID)
+	addresses, err := ou.userRepo.GetAllUserAddresses(ctx, userID)
 	if err != nil {
 		return response.Checkout{}, fmt.Errorf("Failed to retrieve checkout details %s", err)
 	}
@@ -54,12 +119,18 @@ func (ou *orderUseCase) CheckOutDetails(userID int) (response.Checkout, error) {
 		return response.Checkout{}, fmt.Errorf("User don't have an address")
 	}
 
-	cartItems, err := ou.cartUseCase.ViewCart(userID)
+	// unavailable lines are removed before pricing the cart
+	issues, err := ou.cartUseCase.ValidateCart(ctx, userID)
+	if err != nil {
+		return response.Checkout{}, fmt.Errorf("Failed to validate cart :%s", err)
+	}
+
+	cartItems, err := ou.cartUseCase.ViewCart(ctx, userID)
 	if err != nil {
 		return response.Checkout{}, fmt.Errorf("Failed to retrieve cart items %s", err)
 	}
 
-	paymentMethods, err := ou.paymentRepo.GetPaymentMethods()
+	paymentMethods, err := ou.paymentRepo.GetPaymentMethods(ctx)
 	if err != nil || paymentMethods == nil {
 		return response.Checkout{}, fmt.Errorf("Failed to get payment methods %s", err)
 	}
@@ -67,24 +138,39 @@ func (ou *orderUseCase) CheckOutDetails(userID int) (response.Checkout, error) {
 	return response.Checkout{
 		Address:        addresses,
 		Cart:           cartItems.Cart,
+		Subtotal:       cartItems.Subtotal,
+		ShippingFee:    cartItems.ShippingFee,
+		AppliedCoupons: cartItems.AppliedCoupons,
 		Total:          cartItems.Total,
 		Discount:       cartItems.Discount,
+		PointsApplied:  cartItems.PointsApplied,
+		PointsValue:    cartItems.PointsValue,
+		WalletAmount:   cartItems.WalletAmount,
+		Payable:        cartItems.Payable,
 		PaymentOptions: paymentMethods,
+		Issues:         issues,
 	}, nil
 }
 
-func (ou *orderUseCase) GetRazorPayDetails(userID int) (response.PaymentDetails, error) {
-	userCart, err := ou.cartUseCase.ViewCart(userID)
+func (ou *orderUseCase) GetRazorPayDetails(ctx context.Context, userID int) (response.PaymentDetails, error) {
+	err := validateCart(ctx, ou.cartUseCase, userID)
+	if err != nil {
+		return response.PaymentDetails{}, err
+	}
+
+	userCart, err := ou.cartUseCase.ViewCart(ctx, userID)
 	if err != nil {
 		return response.PaymentDetails{}, fmt.Errorf("Failed to retrieve userCart :%s", err)
 	}
 
-	userData, err := ou.userRepo.FindUserByID(userID)
+	userData, err := ou.userRepo.FindUserByID(ctx, userID)
 	if err != nil {
 		return response.PaymentDetails{}, fmt.Errorf("Failed to find user  %s", err)
 	}
 
-	razorPayOrderID, err := helper.MakeRazorPayPaymentId(int(userCart.Total * 100))
+	// the wallet amount applied on the cart is not charged online
+	amount := int(helper.ToPaise(userCart.Payable))
+	razorPayOrderID, err := helper.MakeRazorPayPaymentId(amount)
 	if err != nil {
 		return response.PaymentDetails{}, fmt.Errorf("Failed to get razorpay id %s", err)
 	}
@@ -92,11 +178,11 @@ func (ou *orderUseCase) GetRazorPayDetails(userID int) (response.PaymentDetails,
 	return response.PaymentDetails{
 		Username:        userData.UserName,
 		RazorPayOrderID: razorPayOrderID,
-		Amount:          int(userCart.Total),
+		Amount:          amount,
 	}, nil
 }
 
-func (ou *orderUseCase) VerifyRazorPayPayment(signature string, razorpayOrderID string, paymentID string) error {
+func (ou *orderUseCase) VerifyRazorPayPayment(ctx context.Context, signature string, razorpayOrderID string, paymentID string) error {
 	err := helper.VerifyRazorPayPayment(signature, razorpayOrderID, paymentID)
 	if err != nil {
 		return fmt.Errorf("Failed payment not success : %s", err)
@@ -104,36 +190,53 @@ func (ou *orderUseCase) VerifyRazorPayPayment(signature string, razorpayOrderID
 	return nil
 }
 
-func (ou *orderUseCase) ConfirmedOrder(userID int, paymentMethodID int) error {
-	address, err := ou.userRepo.FindDefaultAddress(userID)
+func (ou *orderUseCase) ConfirmedOrder(ctx context.Context, userID int, paymentMethodID int) error {
+	return ou.inTransaction(ctx, func(tx *orderUseCase) error {
+		return tx.confirmOrder(ctx, userID, paymentMethodID)
+	})
+}
+
+// confirmOrder places the cart as an order. The cart recovery, the notifications
+// and the other side effects of the order are handled by the subscribers of
+// OrderPlaced and PaymentCaptured.
+func (ou *orderUseCase) confirmOrder(ctx context.Context, userID int, paymentMethodID int) error {
+	address, err := ou.userRepo.FindDefaultAddress(ctx, userID)
 	if err != nil || address.ID == 0 {
 		return fmt.Errorf("Failed to find default address : %s", err)
 	}
 
 	addressID := address.ID
 
-	cartData, err := ou.cartUseCase.ViewCart(userID)
+	err = validateCart(ctx, ou.cartUseCase, userID)
 	if err != nil {
-		return fmt.Errorf("Failed to get Cart data :  %s", err)
+		return err
 	}
 
-	couponDetails, err := ou.couponRepo.CheckAppliedCoupon(userID)
+	cartData, err := ou.cartUseCase.ViewCart(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("Failed to retrieve the coupon tracking details  ;%s", err)
+		return fmt.Errorf("Failed to get Cart data :  %s", err)
+	}
+	if len(cartData.Cart) == 0 {
+		return fmt.Errorf("Failed, cart is empty")
 	}
 
-	if couponDetails.ID != 0 {
-		Coupon, err := ou.couponRepo.FindCouponByID(couponDetails.CouponID)
-		if err != nil {
-			return fmt.Errorf("Failed to find coupon by id : %s", err)
+	var couponID int
+	for _, applied := range cartData.AppliedCoupons {
+		// coupons whose rules no longer hold are dropped instead of being spent
+		if !applied.Applicable {
+			_, err := ou.couponRepo.RemoveCouponFromTracking(ctx, applied.CouponID, userID)
+			if err != nil {
+				return fmt.Errorf("Failed to remove coupon from tracking :%s", err)
+			}
+			continue
 		}
-		if !helper.IsCouponValid(Coupon.ValidTill) {
-			return fmt.Errorf("Failed applied Coupon is expired")
+		if couponID == 0 {
+			couponID = applied.CouponID
 		}
 	}
 
-	if couponDetails.CouponID != 0 {
-		UpdatedCouponTracking, err := ou.couponRepo.UpdateCouponUsage(userID)
+	if couponID != 0 {
+		UpdatedCouponTracking, err := ou.couponRepo.UpdateCouponUsage(ctx, userID)
 		if err != nil {
 			return fmt.Errorf("Failed to update coupon usage :%s", err)
 		}
@@ -142,28 +245,41 @@ func (ou *orderUseCase) ConfirmedOrder(userID int, paymentMethodID int) error {
 		}
 	}
 
-	status, err := ou.orderRepo.GetStatusPending()
+	status, err := ou.orderRepo.GetStatusPending(ctx)
 	if err != nil {
 		return fmt.Errorf("Failed to get order status :%s", err)
 	}
 
 	statusID := status.ID
 
-	for _, productData := range cartData.Cart {
+	// the wallet amount applied on the cart is paid from the wallet and the rest with
+	// the chosen method, paying with the wallet alone puts the whole cart on it
+	walletAmount := cartData.WalletAmount
+	if paymentMethodID == walletPaymentID {
+		walletAmount = cartData.Total
+	} else if walletAmount > 0 && cartData.Payable == 0 {
+		paymentMethodID = walletPaymentID
+	}
+
+	walletLeft := helper.ToPaise(walletAmount)
+	var orderIDs []int
+	for i, productData := range cartData.Cart {
 
 		createdAt := time.Now()
 		updatedAt := time.Now()
 
-		newOrderLine, err := ou.orderRepo.InsertOrder(request.NewOrder{
+		newOrderLine, err := ou.orderRepo.InsertOrder(ctx, request.NewOrder{
 
 			UserID:          userID,
 			ProductID:       int(productData.ProductID),
 			AddressID:       int(addressID),
 			Qty:             productData.Qty,
-			Price:           productData.Price,
+			Price:           float32(productData.Price),
+			Discount:        productData.Discount,
+			PointsRedeemed:  productData.PointsRedeemed,
 			PaymentMethodID: paymentMethodID,
 			OrderStatusID:   int(statusID),
-			CouponID:        couponDetails.CouponID,
+			CouponID:        couponID,
 			CreatedAt:       createdAt,
 			UpdatedAt:       updatedAt,
 		})
@@ -171,55 +287,177 @@ func (ou *orderUseCase) ConfirmedOrder(userID int, paymentMethodID int) error {
 		if err != nil || newOrderLine.ID == 0 {
 			return fmt.Errorf("Failed to insert order line : %s", err)
 		}
+		orderIDs = append(orderIDs, int(newOrderLine.ID))
+
+		product, err := ou.productRepo.DecrementStock(ctx, int(productData.ProductID), productData.Qty)
+		if err != nil {
+			return fmt.Errorf("Failed to update product stock :%s", err)
+		}
+		if product.ID == 0 {
+			return fmt.Errorf("Failed, %s is out of stock", productData.ProductName)
+		}
+
+		err = ou.insertOrderLineCoupons(ctx, int(newOrderLine.ID), i, productData.Discount, cartData.AppliedCoupons)
+		if err != nil {
+			return err
+		}
+
+		err = ou.loyaltyUseCase.RedeemPoints(ctx, userID, int(newOrderLine.ID), productData.PointsRedeemed)
+		if err != nil {
+			return err
+		}
+
+		lineAmount := orderLineAmount(float32(productData.Price)*float32(productData.Qty), productData.Discount, productData.PointsRedeemed)
+		walletShare := helper.ToPaise(productData.WalletAmount)
+		if paymentMethodID == walletPaymentID || walletShare > lineAmount {
+			walletShare = lineAmount
+		}
+		walletLeft -= walletShare
+
+		err = ou.insertOrderPayment(ctx, int(newOrderLine.ID), walletPaymentID, walletShare, false)
+		if err != nil {
+			return err
+		}
+		err = ou.insertOrderPayment(ctx, int(newOrderLine.ID), paymentMethodID, lineAmount-walletShare, false)
+		if err != nil {
+			return err
+		}
 	}
 
-	if paymentMethodID == walletPaymentID {
+	// the shipping fee is a tender of its own on the first line, paid from what is
+	// left of the wallet amount and the rest with the chosen method, so it is
+	// refunded with that line
+	if shipping := helper.ToPaise(cartData.ShippingFee); shipping > 0 {
+		walletShare := min(max(walletLeft, 0), shipping)
+		err = ou.insertOrderPayment(ctx, orderIDs[0], walletPaymentID, walletShare, true)
+		if err != nil {
+			return err
+		}
+		err = ou.insertOrderPayment(ctx, orderIDs[0], paymentMethodID, shipping-walletShare, true)
+		if err != nil {
+			return err
+		}
+	}
 
-		err = ou.UpdateWallet(userID, cartData.Total, debit)
+	if cartData.PointsApplied != 0 {
+		err = ou.loyaltyUseCase.RemoveAppliedPoints(ctx, userID)
 		if err != nil {
 			return err
 		}
+	}
+
+	if walletAmount > 0 {
+		err = ou.UpdateWallet(ctx, userID, walletAmount, debit)
+		if err != nil {
+			return err
+		}
+	}
 
+	if cartData.WalletAmount != 0 {
+		err = ou.walletRepo.RemoveAppliedWallet(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("Failed to remove applied wallet :%s", err)
+		}
 	}
 
-	err = ou.cartUseCase.DeleteUserCart(userID)
+	items := make([]request.NotificationItem, len(cartData.Cart))
+	for i, productData := range cartData.Cart {
+		items[i] = request.NotificationItem{ProductName: productData.ProductName, Qty: productData.Qty}
+	}
+	err = ou.publish(ctx, EventOrderPlaced, orderIDs[0], request.OrderPlaced{
+		UserID:   userID,
+		OrderIDs: orderIDs,
+		Items:    items,
+		Amount:   cartData.Total,
+	})
 	if err != nil {
-		return fmt.Errorf("Failed to delete user cart :%s", err)
+		return err
 	}
 
-	return nil
-}
+	if walletAmount > 0 {
+		err = ou.publish(ctx, EventPaymentCaptured, orderIDs[0], request.PaymentCaptured{
+			UserID:          userID,
+			OrderIDs:        orderIDs,
+			PaymentMethodID: walletPaymentID,
+			Amount:          walletAmount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if paymentMethodID == onlinePaymentID && cartData.Payable > 0 {
+		err = ou.publish(ctx, EventPaymentCaptured, orderIDs[0], request.PaymentCaptured{
+			UserID:          userID,
+			OrderIDs:        orderIDs,
+			PaymentMethodID: onlinePaymentID,
+			Amount:          cartData.Payable,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-func (ou *orderUseCase) UpdateWallet(userID int, amount float32, transactionType string) error {
-	wallet, err := ou.orderRepo.FindUserWalletByID(userID)
+	err = ou.cartUseCase.DeleteUserCart(ctx, userID)
 	if err != nil {
-		return err
+		return fmt.Errorf("Failed to delete user cart :%s", err)
 	}
 
-	var updtAmount float32
+	return nil
+}
 
-	if transactionType == credit {
-		updtAmount = (wallet.Amount + amount)
+// publish writes the event to the outbox of the transaction.
+func (ou *orderUseCase) publish(ctx context.Context, eventType string, aggregateID int, payload interface{}) error {
+	return publishEvent(ctx, ou.eventRepo, eventType, aggregateID, payload)
+}
 
+// insertOrderPayment records a tender of the order line, amount is in paise.
+// isShipping marks the tender of the shipping fee.
+func (ou *orderUseCase) insertOrderPayment(ctx context.Context, orderLineID, paymentMethodID int, amount int64, isShipping bool) error {
+	if amount <= 0 {
+		return nil
 	}
 
-	if transactionType == debit {
-		updtAmount = (wallet.Amount - amount)
+	payment, err := ou.paymentRepo.InsertOrderPayment(ctx, request.OrderPayment{
+		OrderLineID:     orderLineID,
+		PaymentMethodID: paymentMethodID,
+		Amount:          helper.FromPaise(amount),
+		IsShipping:      isShipping,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to insert order payment :%s", err)
+	}
+	if payment.ID == 0 {
+		return fmt.Errorf("Failed to verify order payment")
+	}
+	return nil
+}
 
+func (ou *orderUseCase) UpdateWallet(ctx context.Context, userID int, amount float32, transactionType string) error {
+	change := amount
+	if transactionType == debit {
+		change = -amount
 	}
 
-	wallet, err = ou.orderRepo.UpdateUserWalletBalance(userID, updtAmount)
+	// the balance is checked and changed in one statement, concurrent debits
+	// cannot take the wallet below zero
+	wallet, err := ou.orderRepo.AdjustUserWalletBalance(ctx, userID, change)
 	if err != nil {
 		return err
 	}
-	err = ou.UpdateWalletHistory(userID, amount, transactionType)
+	if wallet.ID == 0 {
+		if transactionType == debit {
+			return ErrInsufficientBalance
+		}
+		return ErrNoWallet
+	}
+	err = ou.UpdateWalletHistory(ctx, userID, amount, transactionType)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (ou *orderUseCase) GetUserOrderHistory(userID, page, count int) ([]response.Orders, error) {
+func (ou *orderUseCase) GetUserOrderHistory(ctx context.Context, userID, page, count int) ([]response.Orders, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -229,7 +467,7 @@ func (ou *orderUseCase) GetUserOrderHistory(userID, page, count int) ([]response
 	startIndex := (page - 1) * count
 	endIndex := startIndex + count
 
-	orderHistory, err := ou.orderRepo.GetUserOrderHistory(userID, startIndex, endIndex)
+	orderHistory, err := ou.orderRepo.GetUserOrderHistory(ctx, userID, startIndex, endIndex)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get order history : %s", err)
 	}
@@ -237,14 +475,14 @@ func (ou *orderUseCase) GetUserOrderHistory(userID, page, count int) ([]response
 	return orderHistory, nil
 }
 
-func (ou *orderUseCase) GetOrderManagement(page, count int) (response.OrderManagement, error) {
+func (ou *orderUseCase) GetOrderManagement(ctx context.Context, page, count int) (response.OrderManagement, error) {
 	startIndex, endIndex := helper.Paginate(page, count)
 
-	orderHistory, err := ou.orderRepo.GetAllOrderData(startIndex, endIndex)
+	orderHistory, err := ou.orderRepo.GetAllOrderData(ctx, startIndex, endIndex)
 	if err != nil {
 		return response.OrderManagement{}, fmt.Errorf("Failed to get order history : %s", err)
 	}
-	orderStatuses, err := ou.orderRepo.GetOrderStatuses()
+	orderStatuses, err := ou.orderRepo.GetOrderStatuses(ctx)
 	if err != nil {
 		return response.OrderManagement{}, fmt.Errorf("Failed to retrieve order statuses : %s", err)
 	}
@@ -255,30 +493,65 @@ func (ou *orderUseCase) GetOrderManagement(page, count int) (response.OrderManag
 	}, nil
 }
 
-func (ou *orderUseCase) AllOrderOverView(page, count int) ([]response.Orders, error) {
+func (ou *orderUseCase) AllOrderOverView(ctx context.Context, page, count int) ([]response.Orders, error) {
 	startIndex, endIndex := helper.Paginate(page, count)
 
-	allOrders, err := ou.orderRepo.GetAllOrderData(startIndex, endIndex)
+	allOrders, err := ou.orderRepo.GetAllOrderData(ctx, startIndex, endIndex)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get order history : %s", err)
 	}
 	return allOrders, nil
 }
 
-func (ou *orderUseCase) UpdateOrderStatus(statusID int, orderID int) error {
-	updatedOrder, err := ou.orderRepo.ChangeOrderStatusByID(statusID, orderID)
-	if err != nil {
-		return fmt.Errorf("Failed to update order : %s", err)
-	}
-	if updatedOrder.ID == 0 {
-		return fmt.Errorf("Failed to verify order status by id ")
-	}
+// UpdateOrderStatus moves the order line to the status. The referral rewards and
+// the loyalty points of a delivered order are handled by the subscribers of
+// OrderStatusChanged.
+func (ou *orderUseCase) UpdateOrderStatus(ctx context.Context, statusID int, orderID int) error {
+	return ou.inTransaction(ctx, func(tx *orderUseCase) error {
+		order, err := tx.orderRepo.FindOrderByID(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("Failed to find order : %s", err)
+		}
+		if order.ID == 0 {
+			return fmt.Errorf("Failed to verify order by id")
+		}
 
-	return nil
+		previousStatus, err := tx.orderRepo.FindOrderStatusByID(ctx, order.OrderStatusID)
+		if err != nil {
+			return fmt.Errorf("Failed to find order status : %s", err)
+		}
+
+		updatedOrder, err := tx.orderRepo.ChangeOrderStatusByID(ctx, statusID, orderID)
+		if err != nil {
+			return fmt.Errorf("Failed to update order : %s", err)
+		}
+		if updatedOrder.ID == 0 {
+			return fmt.Errorf("Failed to verify order status by id ")
+		}
+
+		status, err := tx.orderRepo.FindOrderStatusByID(ctx, statusID)
+		if err != nil {
+			return fmt.Errorf("Failed to find order status : %s", err)
+		}
+
+		return tx.publish(ctx, EventOrderStatusChanged, orderID, request.OrderStatusChanged{
+			Order:          updatedOrder,
+			PreviousStatus: previousStatus,
+			Status:         status,
+		})
+	})
+}
+
+// ProcessReturnRequest returns the order line and refunds it. The loyalty points
+// are reversed by the subscribers of OrderStatusChanged.
+func (ou *orderUseCase) ProcessReturnRequest(ctx context.Context, orderID int) error {
+	return ou.inTransaction(ctx, func(tx *orderUseCase) error {
+		return tx.processReturnRequest(ctx, orderID)
+	})
 }
 
-func (ou *orderUseCase) ProcessReturnRequest(orderID int) error {
-	order, err := ou.orderRepo.FindOrderByID(orderID)
+func (ou *orderUseCase) processReturnRequest(ctx context.Context, orderID int) error {
+	order, err := ou.orderRepo.FindOrderByID(ctx, orderID)
 	if err != nil {
 		return fmt.Errorf("Failed to fetch order details: %s", err)
 	}
@@ -286,15 +559,25 @@ func (ou *orderUseCase) ProcessReturnRequest(orderID int) error {
 		return fmt.Errorf("Failed to verify order by id")
 	}
 
+	previousStatus, err := ou.orderRepo.FindOrderStatusByID(ctx, order.OrderStatusID)
+	if err != nil {
+		return fmt.Errorf("Failed to find order status :%s", err)
+	}
+	// only delivered lines can be returned, a cash on delivery line is refunded
+	// on return so it must have been collected
+	if previousStatus != statusDelivered {
+		return fmt.Errorf("Failed, only delivered orders can be returned, order is %s", previousStatus)
+	}
+
 	if !helper.IsValidReturn(order.CreatedAt) {
 		return fmt.Errorf("Failed, order return period is ended")
 	}
-	status, err := ou.orderRepo.GetStatusReturned()
+	status, err := ou.orderRepo.GetStatusReturned(ctx)
 	if err != nil {
 		return fmt.Errorf("Failed to get return status :%s", err)
 	}
 
-	updatedOrder, err := ou.orderRepo.ChangeOrderStatusByID(int(status.ID), orderID)
+	updatedOrder, err := ou.orderRepo.ChangeOrderStatusByID(ctx, int(status.ID), orderID)
 	if err != nil {
 		return fmt.Errorf("Failed to update order status to returned :%s", err)
 	}
@@ -302,16 +585,35 @@ func (ou *orderUseCase) ProcessReturnRequest(orderID int) error {
 		return fmt.Errorf("Failed to verify returned order")
 	}
 
-	err = ou.OrderCancellation(orderID)
+	err = ou.publish(ctx, EventOrderStatusChanged, orderID, request.OrderStatusChanged{
+		Order:          updatedOrder,
+		PreviousStatus: previousStatus,
+		Status:         statusReturned,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ou.OrderCancellation(ctx, orderID)
 	if err != nil {
 		return fmt.Errorf("Failed to return order :%s", err)
 	}
+
 	return nil
 }
 
-func (ou *orderUseCase) OrderCancellation(orderID int) error {
+// OrderCancellation cancels the order line and refunds what was paid to the wallet,
+// in one transaction with the OrderStatusChanged and RefundIssued events. The
+// loyalty points are refunded by the subscribers.
+func (ou *orderUseCase) OrderCancellation(ctx context.Context, orderID int) error {
+	return ou.inTransaction(ctx, func(tx *orderUseCase) error {
+		return tx.orderCancellation(ctx, orderID)
+	})
+}
+
+func (ou *orderUseCase) orderCancellation(ctx context.Context, orderID int) error {
 	//find the order by provided orderID
-	order, err := ou.orderRepo.FindOrderByID(orderID)
+	order, err := ou.orderRepo.FindOrderByID(ctx, orderID)
 	if err != nil {
 		return fmt.Errorf("Failed to find order  :%s ", err)
 	}
@@ -319,76 +621,99 @@ func (ou *orderUseCase) OrderCancellation(orderID int) error {
 		return fmt.Errorf("Failed to verify order by id")
 	}
 
-	// find payment method
-	paymentMethodUsed, err := ou.paymentRepo.FindPaymentMethodById(order.PaymentMethodID)
-	if err != nil {
-		return fmt.Errorf("Failed to fetch payment method :%s", err)
-	}
-
 	// current order status
-	orderStatus, err := ou.orderRepo.FindOrderStatusByID(order.OrderStatusID)
+	orderStatus, err := ou.orderRepo.FindOrderStatusByID(ctx, order.OrderStatusID)
 	if err != nil {
 		return fmt.Errorf("Failed to find order statuses :%s", err)
 	}
+	// a delivered line is refunded only after the return flow marks it returned
+	if orderStatus == statusDelivered || orderStatus == statusCancelled {
+		return ErrNotCancellable
+	}
 
-	//checking user has used coupon or not
-	//reduce coupon percentage and insert money into wallet
-	if paymentMethodUsed.MethodName == "online payment" || paymentMethodUsed.MethodName == "Wallet" || orderStatus == "Returned" {
-		var discountedAmountPerOrderUsingCoupon float32
-
-		if order.CouponID != 0 {
-			couponClaimedOrders, err := ou.orderRepo.FindOrdersBoughtUsingCoupon(int(order.CouponID))
-			if err != nil {
-				return fmt.Errorf("Failed to fetch orders purchased using coupon : %s", err)
-			}
-			if len(couponClaimedOrders) == 0 {
-				return fmt.Errorf("Failed to get coupon used orders")
-			}
-
-			var totalPriceOfOrder float32
-			for _, order := range couponClaimedOrders {
-				totalPriceOfOrder += order.Price
-			}
+	if orderStatus != statusReturned {
+		status, err := ou.orderRepo.GetStatusCancelled(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to proceed cancellation :%s", err)
+		}
+		if status.ID == 0 {
+			return fmt.Errorf("Failed to verify the status")
+		}
 
-			coupon, err := ou.couponRepo.FindCouponByID(int(order.CouponID))
+		updatedOrder, err := ou.orderRepo.ChangeOrderStatusByID(ctx, int(status.ID), orderID)
+		if err != nil {
+			return fmt.Errorf("Failed to update order status :%s", err)
+		}
+		if updatedOrder.ID == 0 {
+			return fmt.Errorf("Failed to verify updated order")
+		}
 
+		if orderStatus != statusCancelled {
+			err = ou.publish(ctx, EventOrderStatusChanged, orderID, request.OrderStatusChanged{
+				Order:          updatedOrder,
+				PreviousStatus: orderStatus,
+				Status:         statusCancelled,
+			})
 			if err != nil {
-				return fmt.Errorf("Failed to fetch coupon details :%s", err)
+				return err
 			}
+		}
+	}
 
-			if coupon.ID != 0 {
-
-				TotalDiscountedAmountOnOrders := (totalPriceOfOrder * float32(coupon.DiscountPercent)) / 100
-				discountedAmountPerOrderUsingCoupon = TotalDiscountedAmountOnOrders / float32(len(couponClaimedOrders))
-			}
+	// cancelled units go back to stock, returned units are restocked by the admin
+	if orderStatus != statusReturned && orderStatus != statusCancelled {
+		err = ou.productRepo.IncrementStock(ctx, int(order.ProductID), order.Qty)
+		if err != nil {
+			return fmt.Errorf("Failed to restock product :%s", err)
 		}
+	}
 
-		if orderStatus != "Returned" {
-			Status, err := ou.orderRepo.GetStatusCancelled()
-			if err != nil {
-				return fmt.Errorf("Failed to proceed cancellation : %s", err)
-			}
-			if Status.ID == 0 {
-				return fmt.Errorf("Failed to verify the status")
-			}
+	payments, err := ou.orderPayments(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	//refund every tender of the line to the wallet, cash on delivery is only collected once the order is delivered
+	var refundingAmount int64
+	for _, payment := range payments {
+		if payment.IsRefunded || (payment.PaymentMethod == cashOnDelivery && orderStatus != statusReturned) {
+			continue
+		}
 
-			updatedOrder, err := ou.orderRepo.ChangeOrderStatusByID(int(Status.ID), orderID)
+		// the tender of a line placed before the tenders were recorded is saved
+		// first, so it is marked refunded and a second cancellation skips it
+		if payment.ID == 0 {
+			recordedPayment, err := ou.paymentRepo.InsertOrderPayment(ctx, request.OrderPayment{
+				OrderLineID:     int(payment.OrderLineID),
+				PaymentMethodID: payment.PaymentMethodID,
+				Amount:          payment.Amount,
+			})
 			if err != nil {
-				return fmt.Errorf("Failed to update order status :%s", err)
+				return fmt.Errorf("Failed to insert order payment :%s", err)
 			}
-			if updatedOrder.ID == 0 {
-				return fmt.Errorf("Failed to verify the order line")
+			if recordedPayment.ID == 0 {
+				return fmt.Errorf("Failed to verify order payment")
 			}
+			payment.ID = recordedPayment.ID
+		}
 
+		refundedPayment, err := ou.paymentRepo.MarkOrderPaymentRefunded(ctx, int(payment.ID))
+		if err != nil {
+			return fmt.Errorf("Failed to update order payment :%s", err)
 		}
+		if refundedPayment.ID == 0 {
+			continue
+		}
+		refundingAmount += helper.ToPaise(payment.Amount)
+	}
 
-		refundingAmount := (order.Price - discountedAmountPerOrderUsingCoupon)
-		wallet, err := ou.orderRepo.FindUserWalletByID(int(order.UserID))
+	if refundingAmount > 0 {
+		wallet, err := ou.orderRepo.FindUserWalletByID(ctx, int(order.UserID))
 		if err != nil {
 			return fmt.Errorf("Failed to find user wallet : %s", err)
 		}
 		if wallet.ID == 0 {
-			newWallet, err := ou.orderRepo.InitializeNewUserWallet(int(order.UserID))
+			newWallet, err := ou.orderRepo.InitializeNewUserWallet(ctx, int(order.UserID))
 			if err != nil {
 				return fmt.Errorf("Failed to initialize wallet for user id %d", order.UserID)
 			}
@@ -397,38 +722,211 @@ func (ou *orderUseCase) OrderCancellation(orderID int) error {
 			}
 		}
 
-		err = ou.UpdateWallet(wallet.UserID, refundingAmount, credit)
+		err = ou.UpdateWallet(ctx, int(order.UserID), helper.FromPaise(refundingAmount), credit)
 		if err != nil {
 			return fmt.Errorf("Failed to update wallet %s:", err)
+		}
 
+		err = ou.publish(ctx, EventRefundIssued, orderID, request.RefundIssued{
+			UserID:    int(order.UserID),
+			OrderID:   orderID,
+			ProductID: int(order.ProductID),
+			Amount:    helper.FromPaise(refundingAmount),
+		})
+		if err != nil {
+			return err
 		}
-		// end
 	}
 
-	if paymentMethodUsed.MethodName == "cash on delivery" && orderStatus != "Returned" {
-		status, err := ou.orderRepo.GetStatusCancelled()
+	return nil
+}
+
+// orderPayments returns the tenders of the order line. Lines placed before the
+// tenders were recorded are paid in full with their payment method.
+func (ou *orderUseCase) orderPayments(ctx context.Context, order response.OrderLine) ([]response.OrderPayment, error) {
+	payments, err := ou.paymentRepo.GetOrderPayments(ctx, int(order.ID))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch order payments :%s", err)
+	}
+	if len(payments) != 0 {
+		return payments, nil
+	}
+
+	paymentMethod, err := ou.paymentRepo.FindPaymentMethodById(ctx, order.PaymentMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch payment method :%s", err)
+	}
+
+	return []response.OrderPayment{{
+		OrderLineID:     order.ID,
+		PaymentMethodID: order.PaymentMethodID,
+		PaymentMethod:   paymentMethod.MethodName,
+		Amount:          helper.FromPaise(orderLineAmount(order.Price*float32(order.Qty), order.Discount, order.PointsRedeemed)),
+	}}, nil
+}
+
+// orderLineAmount is what is paid for a line in paise, after the coupon discount and the points.
+func orderLineAmount(lineTotal, discount float32, pointsRedeemed int) int64 {
+	return helper.ToPaise(lineTotal) - helper.ToPaise(discount) - helper.ToPaise(float32(pointsRedeemed*loyaltyPointValue))
+}
+
+// PartialOrderCancellation cancels qty units of an order line, the whole line when
+// qty is its quantity or more. The cancelled units move to a new line with their
+// share of the line discount and that line is cancelled.
+func (ou *orderUseCase) PartialOrderCancellation(ctx context.Context, orderID, qty int) error {
+	return ou.inTransaction(ctx, func(tx *orderUseCase) error {
+		return tx.partialOrderCancellation(ctx, orderID, qty)
+	})
+}
+
+func (ou *orderUseCase) partialOrderCancellation(ctx context.Context, orderID, qty int) error {
+	if qty <= 0 {
+		return ErrInvalidQuantity
+	}
+
+	order, err := ou.orderRepo.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("Failed to find order  :%s ", err)
+	}
+	if order.ID == 0 {
+		return fmt.Errorf("Failed to verify order by id")
+	}
+
+	// the status is checked before the whole line is cancelled, a delivered line
+	// goes through the return flow
+	orderStatus, err := ou.orderRepo.FindOrderStatusByID(ctx, order.OrderStatusID)
+	if err != nil {
+		return fmt.Errorf("Failed to find order statuses :%s", err)
+	}
+	if orderStatus == statusCancelled || orderStatus == statusReturned || orderStatus == statusDelivered {
+		return ErrNotCancellable
+	}
+
+	if qty >= order.Qty {
+		return ou.orderCancellation(ctx, orderID)
+	}
+
+	remainingQty := order.Qty - qty
+	shares := helper.ApportionDiscount(helper.ToPaise(order.Discount), []int64{
+		helper.ToPaise(order.Price * float32(qty)),
+		helper.ToPaise(order.Price * float32(remainingQty)),
+	})
+
+	points := helper.ApportionDiscount(int64(order.PointsRedeemed), []int64{
+		helper.ToPaise(order.Price * float32(qty)),
+		helper.ToPaise(order.Price * float32(remainingQty)),
+	})
+
+	updatedOrder, err := ou.orderRepo.UpdateOrderQuantity(ctx, orderID, remainingQty, helper.FromPaise(shares[1]), int(points[1]))
+	if err != nil {
+		return fmt.Errorf("Failed to update order quantity :%s", err)
+	}
+	if updatedOrder.ID == 0 {
+		return fmt.Errorf("Failed to verify updated order")
+	}
+
+	cancelledLine, err := ou.orderRepo.InsertOrder(ctx, request.NewOrder{
+		UserID:          int(order.UserID),
+		ProductID:       int(order.ProductID),
+		AddressID:       int(order.AddressesID),
+		Qty:             qty,
+		Price:           order.Price,
+		Discount:        helper.FromPaise(shares[0]),
+		PointsRedeemed:  int(points[0]),
+		PaymentMethodID: order.PaymentMethodID,
+		OrderStatusID:   order.OrderStatusID,
+		CouponID:        int(order.CouponID),
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       time.Now(),
+	})
+	if err != nil || cancelledLine.ID == 0 {
+		return fmt.Errorf("Failed to insert order line : %s", err)
+	}
+
+	// every tender is split between the lines the same way, the shipping fee
+	// stays with the line that is not cancelled
+	payments, err := ou.paymentRepo.GetOrderPayments(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch order payments :%s", err)
+	}
+	for _, payment := range payments {
+		if payment.IsShipping {
+			continue
+		}
+		paymentShares := helper.ApportionDiscount(helper.ToPaise(payment.Amount), []int64{
+			helper.ToPaise(order.Price * float32(qty)),
+			helper.ToPaise(order.Price * float32(remainingQty)),
+		})
+
+		updatedPayment, err := ou.paymentRepo.UpdateOrderPaymentAmount(ctx, int(payment.ID), helper.FromPaise(paymentShares[1]))
 		if err != nil {
-			return fmt.Errorf("Failed to proceed cancellation :%s", err)
+			return fmt.Errorf("Failed to update order payment :%s", err)
 		}
-		if status.ID == 0 {
-			return fmt.Errorf("Failed to verify the status")
+		if updatedPayment.ID == 0 {
+			return fmt.Errorf("Failed to verify updated order payment")
 		}
 
-		updatedOrder, err := ou.orderRepo.ChangeOrderStatusByID(int(status.ID), orderID)
+		err = ou.insertOrderPayment(ctx, int(cancelledLine.ID), payment.PaymentMethodID, paymentShares[0], false)
 		if err != nil {
-			return fmt.Errorf("Failed to update order status :%s", err)
+			return err
 		}
-		if updatedOrder.ID == 0 {
-			return fmt.Errorf("Failed to verify updated order")
+	}
+
+	// the coupon discounts are split like the line discount so each coupon keeps
+	// its share on both lines
+	lineCoupons, err := ou.couponRepo.GetOrderLineCoupons(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch order line coupons :%s", err)
+	}
+	for _, lineCoupon := range lineCoupons {
+		couponShares := helper.ApportionDiscount(helper.ToPaise(lineCoupon.Discount), []int64{
+			helper.ToPaise(order.Price * float32(qty)),
+			helper.ToPaise(order.Price * float32(remainingQty)),
+		})
+
+		updatedCoupon, err := ou.couponRepo.UpdateOrderLineCouponDiscount(ctx, lineCoupon.ID, helper.FromPaise(couponShares[1]))
+		if err != nil {
+			return fmt.Errorf("Failed to update order line coupon :%s", err)
+		}
+		if updatedCoupon.ID == 0 {
+			return fmt.Errorf("Failed to verify updated order line coupon")
+		}
+
+		insertedCoupon, err := ou.couponRepo.InsertOrderLineCoupon(ctx, int(cancelledLine.ID), lineCoupon.CouponID, helper.FromPaise(couponShares[0]))
+		if err != nil || insertedCoupon.ID == 0 {
+			return fmt.Errorf("Failed to insert order line coupon :%s", err)
 		}
 	}
 
+	return ou.OrderCancellation(ctx, int(cancelledLine.ID))
+}
+
+// insertOrderLineCoupons records the discount every applied coupon gave the
+// line at index i of the cart. The stacked discounts are capped at the line
+// discount in the order the coupons were applied, like the cart caps them.
+func (ou *orderUseCase) insertOrderLineCoupons(ctx context.Context, orderLineID, i int, lineDiscount float32, appliedCoupons []response.CouponEvaluation) error {
+	remaining := helper.ToPaise(lineDiscount)
+	for _, applied := range appliedCoupons {
+		if !applied.Applicable || i >= len(applied.Lines) {
+			continue
+		}
+		discount := min(helper.ToPaise(applied.Lines[i].Discount), remaining)
+		if discount <= 0 {
+			continue
+		}
+		remaining -= discount
+
+		lineCoupon, err := ou.couponRepo.InsertOrderLineCoupon(ctx, orderLineID, applied.CouponID, helper.FromPaise(discount))
+		if err != nil || lineCoupon.ID == 0 {
+			return fmt.Errorf("Failed to insert order line coupon :%s", err)
+		}
+	}
 	return nil
 }
 
-func (ou *orderUseCase) UpdateWalletHistory(userID int, amount float32, transactionType string) error {
+func (ou *orderUseCase) UpdateWalletHistory(ctx context.Context, userID int, amount float32, transactionType string) error {
 
-	walletHistory, err := ou.orderRepo.UpdateWalletTransactionHistory(
+	walletHistory, err := ou.orderRepo.UpdateWalletTransactionHistory(ctx,
 		request.WalletTransactionHistory{
 			TransactionTime: time.Now(),
 			UserID:          userID,
@@ -449,8 +947,8 @@ func (ou *orderUseCase) UpdateWalletHistory(userID int, amount float32, transact
 	return nil
 }
 
-func (ou *orderUseCase) GetWalletHistory(userID int) ([]response.WalletTransactionHistory, error) {
-	walletHistory, err := ou.orderRepo.GetWalletHistoryByUserID(userID)
+func (ou *orderUseCase) GetWalletHistory(ctx context.Context, userID int) ([]response.WalletTransactionHistory, error) {
+	walletHistory, err := ou.orderRepo.GetWalletHistoryByUserID(ctx, userID)
 	if err != nil {
 		return walletHistory, err
 	}
@@ -458,8 +956,8 @@ func (ou *orderUseCase) GetWalletHistory(userID int) ([]response.WalletTransacti
 	return walletHistory, nil
 }
 
-func (ou *orderUseCase) GetUserWallet(userID int) (response.Wallet, error) {
-	wallet, err := ou.orderRepo.FindUserWalletByID(userID)
+func (ou *orderUseCase) GetUserWallet(ctx context.Context, userID int) (response.Wallet, error) {
+	wallet, err := ou.orderRepo.FindUserWalletByID(ctx, userID)
 	if err != nil {
 		return response.Wallet{}, fmt.Errorf("Failed to get user wallet :%s", err)
 	}
@@ -469,9 +967,9 @@ func (ou *orderUseCase) GetUserWallet(userID int) (response.Wallet, error) {
 	return wallet, nil
 }
 
-func (ou *orderUseCase) CreateUserWallet(userID int) error {
+func (ou *orderUseCase) CreateUserWallet(ctx context.Context, userID int) error {
 
-	wallet, err := ou.orderRepo.FindUserWalletByID(userID)
+	wallet, err := ou.orderRepo.FindUserWalletByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to check user wallet :%s", err)
 	}
@@ -480,7 +978,7 @@ func (ou *orderUseCase) CreateUserWallet(userID int) error {
 		return fmt.Errorf("User already have wallet")
 	}
 
-	newWallet, err := ou.orderRepo.InitializeNewUserWallet(userID)
+	newWallet, err := ou.orderRepo.InitializeNewUserWallet(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to initialize wallet for user %d : %s", userID, err)
 	}
@@ -490,8 +988,8 @@ func (ou *orderUseCase) CreateUserWallet(userID int) error {
 	return nil
 }
 
-func (ou *orderUseCase) ValidateWalletPayment(userID int) error {
-	wallet, err := ou.orderRepo.FindUserWalletByID(userID)
+func (ou *orderUseCase) ValidateWalletPayment(ctx context.Context, userID int) error {
+	wallet, err := ou.orderRepo.FindUserWalletByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to find user wallet : %s", err)
 	}
@@ -499,18 +997,18 @@ func (ou *orderUseCase) ValidateWalletPayment(userID int) error {
 		return fmt.Errorf("User don't have a wallet ")
 	}
 
-	userCart, err := ou.cartUseCase.ViewCart(userID)
+	userCart, err := ou.cartUseCase.ViewCart(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to fetch user cart : %s", err)
 	}
 	if userCart.Total > wallet.Amount {
-		return fmt.Errorf("Insufficient balance")
+		return fmt.Errorf("Insufficient balance, apply the wallet to pay the rest online or on delivery")
 	}
 	return nil
 }
 
-func (ou *orderUseCase) CreateInvoice(orderID int) (response.Invoice, error) {
-	order, err := ou.orderRepo.FindOrderByID(orderID)
+func (ou *orderUseCase) CreateInvoice(ctx context.Context, orderID int) (response.Invoice, error) {
+	order, err := ou.orderRepo.FindOrderByID(ctx, orderID)
 	if err != nil {
 		return response.Invoice{}, fmt.Errorf("Failed to get order details:%s", err)
 	}
@@ -518,7 +1016,7 @@ func (ou *orderUseCase) CreateInvoice(orderID int) (response.Invoice, error) {
 		return response.Invoice{}, fmt.Errorf("Failed to fetch order by id")
 	}
 
-	invoiceData, err := ou.orderRepo.GetInvoiceDataByID(orderID)
+	invoiceData, err := ou.orderRepo.GetInvoiceDataByID(ctx, orderID)
 	if err != nil {
 		return response.Invoice{}, fmt.Errorf("Failed to get invoice data :%s", err)
 	}
@@ -526,7 +1024,7 @@ func (ou *orderUseCase) CreateInvoice(orderID int) (response.Invoice, error) {
 		return response.Invoice{}, fmt.Errorf("Failed to fetch order by id")
 	}
 
-	product, err := ou.productRepo.FindProductByID(invoiceData.ProductID)
+	product, err := ou.productRepo.FindProductByID(ctx, invoiceData.ProductID)
 	if err != nil {
 		return response.Invoice{}, fmt.Errorf("Failed to get product data :%s", err)
 	}
@@ -534,24 +1032,31 @@ func (ou *orderUseCase) CreateInvoice(orderID int) (response.Invoice, error) {
 		return response.Invoice{}, fmt.Errorf("Failed to fetch product by id")
 	}
 
+	payments, err := ou.orderPayments(ctx, order)
+	if err != nil {
+		return response.Invoice{}, err
+	}
+
 	return response.Invoice{
 		OrderDate:       order.CreatedAt.String(),
 		OrderID:         orderID,
 		DeliveryAddress: invoiceData.DeliveryAddress,
 		ProductName:     product.ProductName,
 		PaymentMethod:   invoiceData.PaymentMethod,
-		ProductPrice:    product.Price,
-		Discount:        (float32(product.Price) - order.Price),
-		TotalAmount:     order.Price,
+		ProductPrice:    order.Price,
+		Qty:             order.Qty,
+		Discount:        order.Discount,
+		TotalAmount:     helper.FromPaise(helper.ToPaise(order.Price*float32(order.Qty)) - helper.ToPaise(order.Discount)),
+		Payments:        payments,
 	}, nil
 }
 
-func (ou *orderUseCase) MonthlySalesReport() (response.MonthlySalesReport, error) {
+func (ou *orderUseCase) MonthlySalesReport(ctx context.Context) (response.MonthlySalesReport, error) {
 
 	startDate := time.Now().AddDate(0, 0, -30)
 	endDate := time.Now()
 
-	orders, err := ou.orderRepo.GetAllOrderData(0, 20)
+	orders, err := ou.orderRepo.GetAllOrderData(ctx, 0, 20)
 	if err != nil {
 		return response.MonthlySalesReport{}, fmt.Errorf("Failed to get order details :%s", err)
 	}
@@ -563,37 +1068,37 @@ func (ou *orderUseCase) MonthlySalesReport() (response.MonthlySalesReport, error
 		}, ErrNoOrders
 	}
 
-	topSelling, err := ou.orderRepo.TopSellingProduct(startDate, endDate)
+	topSelling, err := ou.orderRepo.TopSellingProduct(ctx, startDate, endDate)
 	if err != nil {
 		return response.MonthlySalesReport{}, fmt.Errorf("Failed to find product :%s", err)
 	}
 
-	product, err := ou.productRepo.FindProductByID(topSelling.ProductID)
+	product, err := ou.productRepo.FindProductByID(ctx, topSelling.ProductID)
 	if err != nil {
 		return response.MonthlySalesReport{}, fmt.Errorf("Failed to find product :%s", err)
 	}
 
-	category, err := ou.productRepo.FindCategoryByID(product.CategoryID)
+	category, err := ou.productRepo.FindCategoryByID(ctx, product.CategoryID)
 	if err != nil {
 		return response.MonthlySalesReport{}, fmt.Errorf("Failed to find category :%s", err)
 	}
 
-	totalSalesCount, err := ou.orderRepo.GetTotalSaleCount(startDate, endDate)
+	totalSalesCount, err := ou.orderRepo.GetTotalSaleCount(ctx, startDate, endDate)
 	if err != nil {
 		return response.MonthlySalesReport{}, fmt.Errorf("Failed to find total sales :%s", err)
 	}
 
-	avgOrderValue, err := ou.orderRepo.GetAverageOrderValue(startDate, endDate)
+	avgOrderValue, err := ou.orderRepo.GetAverageOrderValue(ctx, startDate, endDate)
 	if err != nil {
 		return response.MonthlySalesReport{}, fmt.Errorf("Failed to find average order value :%s", err)
 	}
 
-	status, err := ou.orderRepo.GetStatusReturned()
+	status, err := ou.orderRepo.GetStatusReturned(ctx)
 	if err != nil {
 		return response.MonthlySalesReport{}, fmt.Errorf("Failed to find returned products :%s", err)
 	}
 
-	revenueData, err := ou.orderRepo.GetTotalRevenue(int(status.ID), startDate, endDate)
+	revenueData, err := ou.orderRepo.GetTotalRevenue(ctx, int(status.ID), startDate, endDate)
 	if err != nil {
 		return response.MonthlySalesReport{}, fmt.Errorf("Failed to find total revenue :%s", err)
 	}
@@ -0,0 +1,396 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+const (
+	testStatusPending   = 1
+	testStatusCancelled = 2
+	testStatusDelivered = 3
+)
+
+// orderStore keeps the order lines, tenders and coupon shares of the fake
+// repositories below, only the methods used by the cancellation are implemented.
+type orderStore struct {
+	lines     map[int]response.OrderLine
+	payments  []response.OrderPayment
+	coupons   []response.OrderLineCoupon
+	wallet    float32
+	restocked int
+}
+
+type fakeOrderRepo struct {
+	interfaces.OrderRepository
+	store *orderStore
+}
+
+func (f fakeOrderRepo) FindOrderByID(ctx context.Context, orderID int) (response.OrderLine, error) {
+	return f.store.lines[orderID], nil
+}
+
+func (f fakeOrderRepo) FindOrderStatusByID(ctx context.Context, statusID int) (string, error) {
+	switch statusID {
+	case testStatusCancelled:
+		return statusCancelled, nil
+	case testStatusDelivered:
+		return statusDelivered, nil
+	}
+	return "Pending", nil
+}
+
+func (f fakeOrderRepo) GetStatusCancelled(ctx context.Context) (response.OrderStatus, error) {
+	return response.OrderStatus{ID: testStatusCancelled, Status: statusCancelled}, nil
+}
+
+func (f fakeOrderRepo) ChangeOrderStatusByID(ctx context.Context, statusID int, orderID int) (response.OrderLine, error) {
+	line := f.store.lines[orderID]
+	line.OrderStatusID = statusID
+	f.store.lines[orderID] = line
+	return line, nil
+}
+
+func (f fakeOrderRepo) UpdateOrderQuantity(ctx context.Context, orderID, qty int, discount float32, pointsRedeemed int) (response.OrderLine, error) {
+	line := f.store.lines[orderID]
+	line.Qty, line.Discount, line.PointsRedeemed = qty, discount, pointsRedeemed
+	f.store.lines[orderID] = line
+	return line, nil
+}
+
+func (f fakeOrderRepo) InsertOrder(ctx context.Context, order request.NewOrder) (response.OrderLine, error) {
+	line := response.OrderLine{
+		ID:              uint(len(f.store.lines) + 1),
+		UserID:          uint(order.UserID),
+		AddressesID:     uint(order.AddressID),
+		ProductID:       uint(order.ProductID),
+		PaymentMethodID: order.PaymentMethodID,
+		OrderStatusID:   order.OrderStatusID,
+		Qty:             order.Qty,
+		Price:           order.Price,
+		Discount:        order.Discount,
+		PointsRedeemed:  order.PointsRedeemed,
+		CouponID:        uint(order.CouponID),
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+	}
+	f.store.lines[int(line.ID)] = line
+	return line, nil
+}
+
+func (f fakeOrderRepo) FindUserWalletByID(ctx context.Context, userID int) (response.Wallet, error) {
+	return response.Wallet{ID: 1, UserID: userID, Amount: f.store.wallet}, nil
+}
+
+func (f fakeOrderRepo) AdjustUserWalletBalance(ctx context.Context, userID int, amount float32) (response.Wallet, error) {
+	f.store.wallet += amount
+	return response.Wallet{ID: 1, UserID: userID, Amount: f.store.wallet}, nil
+}
+
+func (f fakeOrderRepo) UpdateWalletTransactionHistory(ctx context.Context, update request.WalletTransactionHistory) (response.WalletTransactionHistory, error) {
+	return response.WalletTransactionHistory{ID: 1, UserID: update.UserID}, nil
+}
+
+type fakePaymentRepo struct {
+	interfaces.PaymentRepository
+	store *orderStore
+}
+
+func (f fakePaymentRepo) GetOrderPayments(ctx context.Context, orderLineID int) ([]response.OrderPayment, error) {
+	var payments []response.OrderPayment
+	for _, payment := range f.store.payments {
+		if int(payment.OrderLineID) == orderLineID {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
+
+func (f fakePaymentRepo) InsertOrderPayment(ctx context.Context, payment request.OrderPayment) (response.OrderPayment, error) {
+	inserted := response.OrderPayment{
+		ID:              uint(len(f.store.payments) + 1),
+		OrderLineID:     uint(payment.OrderLineID),
+		PaymentMethodID: payment.PaymentMethodID,
+		PaymentMethod:   testPaymentMethods[payment.PaymentMethodID],
+		Amount:          payment.Amount,
+		IsShipping:      payment.IsShipping,
+	}
+	f.store.payments = append(f.store.payments, inserted)
+	return inserted, nil
+}
+
+func (f fakePaymentRepo) UpdateOrderPaymentAmount(ctx context.Context, paymentID int, amount float32) (response.OrderPayment, error) {
+	f.store.payments[paymentID-1].Amount = amount
+	return f.store.payments[paymentID-1], nil
+}
+
+func (f fakePaymentRepo) MarkOrderPaymentRefunded(ctx context.Context, paymentID int) (response.OrderPayment, error) {
+	f.store.payments[paymentID-1].IsRefunded = true
+	return f.store.payments[paymentID-1], nil
+}
+
+type fakeCouponRepo struct {
+	interfaces.CouponRepository
+	store *orderStore
+}
+
+func (f fakeCouponRepo) GetOrderLineCoupons(ctx context.Context, orderLineID int) ([]response.OrderLineCoupon, error) {
+	var coupons []response.OrderLineCoupon
+	for _, coupon := range f.store.coupons {
+		if coupon.OrderLineID == orderLineID {
+			coupons = append(coupons, coupon)
+		}
+	}
+	return coupons, nil
+}
+
+func (f fakeCouponRepo) InsertOrderLineCoupon(ctx context.Context, orderLineID, couponID int, discount float32) (response.OrderLineCoupon, error) {
+	inserted := response.OrderLineCoupon{ID: len(f.store.coupons) + 1, OrderLineID: orderLineID, CouponID: couponID, Discount: discount}
+	f.store.coupons = append(f.store.coupons, inserted)
+	return inserted, nil
+}
+
+func (f fakeCouponRepo) UpdateOrderLineCouponDiscount(ctx context.Context, id int, discount float32) (response.OrderLineCoupon, error) {
+	f.store.coupons[id-1].Discount = discount
+	return f.store.coupons[id-1], nil
+}
+
+type fakeProductRepo struct {
+	interfaces.ProductRepository
+	store *orderStore
+}
+
+func (f fakeProductRepo) IncrementStock(ctx context.Context, productID, qty int) error {
+	f.store.restocked += qty
+	return nil
+}
+
+type fakeEventRepo struct {
+	interfaces.EventRepository
+}
+
+func (fakeEventRepo) InsertEvent(ctx context.Context, event request.DomainEvent, now time.Time) (response.DomainEvent, error) {
+	return response.DomainEvent{ID: 1, Type: event.Type}, nil
+}
+
+var testPaymentMethods = map[int]string{1: cashOnDelivery, 2: "razorpay", walletPaymentID: "wallet"}
+
+type testPayment struct {
+	methodID   int
+	amount     float32
+	isShipping bool
+	refunded   bool
+}
+
+type testLine struct {
+	qty            int
+	discount       float32
+	pointsRedeemed int
+	statusID       int
+	payments       []testPayment
+	coupons        map[int]float32
+}
+
+func TestPartialOrderCancellation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		line      response.OrderLine
+		payments  []testPayment
+		coupons   map[int]float32
+		qty       int
+		cancelled testLine
+		remaining testLine
+		refund    float32
+	}{
+		{
+			name:     "card payment with points keeps the paise of the price",
+			line:     response.OrderLine{Qty: 3, Price: 999.5, Discount: 150, PointsRedeemed: 9, PaymentMethodID: 2},
+			payments: []testPayment{{methodID: 2, amount: 2839.5}},
+			qty:      1,
+			cancelled: testLine{qty: 1, discount: 50, pointsRedeemed: 3, statusID: testStatusCancelled,
+				payments: []testPayment{{methodID: 2, amount: 946.5, refunded: true}}},
+			remaining: testLine{qty: 2, discount: 100, pointsRedeemed: 6, statusID: testStatusPending,
+				payments: []testPayment{{methodID: 2, amount: 1893}}},
+			refund: 946.5,
+		},
+		{
+			name:     "wallet and card split, shipping stays on the remaining line",
+			line:     response.OrderLine{Qty: 4, Price: 500, PaymentMethodID: 2},
+			payments: []testPayment{{methodID: walletPaymentID, amount: 800}, {methodID: 2, amount: 1200}, {methodID: 2, amount: 40, isShipping: true}},
+			qty:      3,
+			cancelled: testLine{qty: 3, statusID: testStatusCancelled,
+				payments: []testPayment{{methodID: walletPaymentID, amount: 600, refunded: true}, {methodID: 2, amount: 900, refunded: true}}},
+			remaining: testLine{qty: 1, statusID: testStatusPending,
+				payments: []testPayment{{methodID: walletPaymentID, amount: 200}, {methodID: 2, amount: 300}, {methodID: 2, amount: 40, isShipping: true}}},
+			refund: 1500,
+		},
+		{
+			name:     "stacked coupons are split per coupon",
+			line:     response.OrderLine{Qty: 2, Price: 1000, Discount: 200, PaymentMethodID: 2},
+			payments: []testPayment{{methodID: 2, amount: 1800}},
+			coupons:  map[int]float32{5: 120, 6: 80},
+			qty:      1,
+			cancelled: testLine{qty: 1, discount: 100, statusID: testStatusCancelled,
+				payments: []testPayment{{methodID: 2, amount: 900, refunded: true}},
+				coupons:  map[int]float32{5: 60, 6: 40}},
+			remaining: testLine{qty: 1, discount: 100, statusID: testStatusPending,
+				payments: []testPayment{{methodID: 2, amount: 900}},
+				coupons:  map[int]float32{5: 60, 6: 40}},
+			refund: 900,
+		},
+		{
+			name:     "cash on delivery is not refunded",
+			line:     response.OrderLine{Qty: 2, Price: 250, PaymentMethodID: 1},
+			payments: []testPayment{{methodID: 1, amount: 500}},
+			qty:      1,
+			cancelled: testLine{qty: 1, statusID: testStatusCancelled,
+				payments: []testPayment{{methodID: 1, amount: 250}}},
+			remaining: testLine{qty: 1, statusID: testStatusPending,
+				payments: []testPayment{{methodID: 1, amount: 250}}},
+			refund: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &orderStore{lines: map[int]response.OrderLine{}}
+			line := tc.line
+			line.ID, line.UserID, line.ProductID, line.OrderStatusID = 1, 1, 1, testStatusPending
+			store.lines[1] = line
+			for _, payment := range tc.payments {
+				store.payments = append(store.payments, response.OrderPayment{
+					ID:              uint(len(store.payments) + 1),
+					OrderLineID:     1,
+					PaymentMethodID: payment.methodID,
+					PaymentMethod:   testPaymentMethods[payment.methodID],
+					Amount:          payment.amount,
+					IsShipping:      payment.isShipping,
+				})
+			}
+			for couponID := 5; couponID <= 6; couponID++ {
+				if discount, ok := tc.coupons[couponID]; ok {
+					store.coupons = append(store.coupons, response.OrderLineCoupon{ID: len(store.coupons) + 1, OrderLineID: 1, CouponID: couponID, Discount: discount})
+				}
+			}
+
+			ou := &orderUseCase{
+				orderRepo:   fakeOrderRepo{store: store},
+				paymentRepo: fakePaymentRepo{store: store},
+				couponRepo:  fakeCouponRepo{store: store},
+				productRepo: fakeProductRepo{store: store},
+				eventRepo:   fakeEventRepo{},
+				inTx:        true,
+			}
+
+			err := ou.PartialOrderCancellation(context.Background(), 1, tc.qty)
+			if err != nil {
+				t.Fatalf("PartialOrderCancellation() error = %v", err)
+			}
+
+			if len(store.lines) != 2 {
+				t.Fatalf("got %d order lines, want 2", len(store.lines))
+			}
+			checkLine(t, "remaining", store, store.lines[1], tc.line.Price, tc.remaining)
+			checkLine(t, "cancelled", store, store.lines[2], tc.line.Price, tc.cancelled)
+
+			if helper.ToPaise(store.wallet) != helper.ToPaise(tc.refund) {
+				t.Errorf("refunded %.2f, want %.2f", store.wallet, tc.refund)
+			}
+			if store.restocked != tc.qty {
+				t.Errorf("restocked %d units, want %d", store.restocked, tc.qty)
+			}
+		})
+	}
+}
+
+func TestPartialOrderCancellationRejected(t *testing.T) {
+	testCases := []struct {
+		name     string
+		statusID int
+		qty      int
+		wantErr  error
+	}{
+		{name: "zero quantity", statusID: testStatusPending, qty: 0, wantErr: ErrInvalidQuantity},
+		{name: "negative quantity", statusID: testStatusPending, qty: -1, wantErr: ErrInvalidQuantity},
+		{name: "part of a delivered line", statusID: testStatusDelivered, qty: 1, wantErr: ErrNotCancellable},
+		{name: "whole delivered line", statusID: testStatusDelivered, qty: 2, wantErr: ErrNotCancellable},
+		{name: "whole cancelled line", statusID: testStatusCancelled, qty: 2, wantErr: ErrNotCancellable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &orderStore{lines: map[int]response.OrderLine{}}
+			store.lines[1] = response.OrderLine{ID: 1, UserID: 1, ProductID: 1, Qty: 2, Price: 500, PaymentMethodID: 2, OrderStatusID: tc.statusID}
+			store.payments = []response.OrderPayment{{ID: 1, OrderLineID: 1, PaymentMethodID: 2, PaymentMethod: testPaymentMethods[2], Amount: 1000}}
+
+			ou := &orderUseCase{
+				orderRepo:   fakeOrderRepo{store: store},
+				paymentRepo: fakePaymentRepo{store: store},
+				couponRepo:  fakeCouponRepo{store: store},
+				productRepo: fakeProductRepo{store: store},
+				eventRepo:   fakeEventRepo{},
+				inTx:        true,
+			}
+
+			err := ou.PartialOrderCancellation(context.Background(), 1, tc.qty)
+			if err != tc.wantErr {
+				t.Fatalf("PartialOrderCancellation() error = %v, want %v", err, tc.wantErr)
+			}
+			if len(store.lines) != 1 || store.lines[1].OrderStatusID != tc.statusID {
+				t.Errorf("order lines changed to %+v", store.lines)
+			}
+			if store.wallet != 0 || store.restocked != 0 || store.payments[0].IsRefunded {
+				t.Errorf("refunded %.2f and restocked %d units, want nothing", store.wallet, store.restocked)
+			}
+		})
+	}
+}
+
+func checkLine(t *testing.T, name string, store *orderStore, got response.OrderLine, price float32, want testLine) {
+	t.Helper()
+
+	if got.Qty != want.qty || got.Price != price || helper.ToPaise(got.Discount) != helper.ToPaise(want.discount) ||
+		got.PointsRedeemed != want.pointsRedeemed || got.OrderStatusID != want.statusID {
+		t.Errorf("%s line = qty %d price %.2f discount %.2f points %d status %d, want qty %d price %.2f discount %.2f points %d status %d",
+			name, got.Qty, got.Price, got.Discount, got.PointsRedeemed, got.OrderStatusID,
+			want.qty, price, want.discount, want.pointsRedeemed, want.statusID)
+	}
+
+	var payments []testPayment
+	for _, payment := range store.payments {
+		if payment.OrderLineID == got.ID {
+			payments = append(payments, testPayment{payment.PaymentMethodID, payment.Amount, payment.IsShipping, payment.IsRefunded})
+		}
+	}
+	if len(payments) != len(want.payments) {
+		t.Fatalf("%s line has %d payments, want %d", name, len(payments), len(want.payments))
+	}
+	for i, payment := range payments {
+		wantPayment := want.payments[i]
+		if payment.methodID != wantPayment.methodID || helper.ToPaise(payment.amount) != helper.ToPaise(wantPayment.amount) ||
+			payment.isShipping != wantPayment.isShipping || payment.refunded != wantPayment.refunded {
+			t.Errorf("%s line payment %d = %+v, want %+v", name, i, payment, wantPayment)
+		}
+	}
+
+	coupons := map[int]float32{}
+	for _, coupon := range store.coupons {
+		if coupon.OrderLineID == int(got.ID) {
+			coupons[coupon.CouponID] = coupon.Discount
+		}
+	}
+	if len(coupons) != len(want.coupons) {
+		t.Fatalf("%s line has %d coupons, want %d", name, len(coupons), len(want.coupons))
+	}
+	for couponID, discount := range want.coupons {
+		if helper.ToPaise(coupons[couponID]) != helper.ToPaise(discount) {
+			t.Errorf("%s line coupon %d discount %.2f, want %.2f", name, couponID, coupons[couponID], discount)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"time"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
@@ -33,25 +35,38 @@ const (
 )
 
 type productUseCase struct {
-	productRepo interfaces.ProductRepository
-	orderRepo   interfaces.OrderRepository
+	productRepo   interfaces.ProductRepository
+	orderRepo     interfaces.OrderRepository
+	promotionRepo interfaces.PromotionRepository
 }
 
-func NewProductUseCase(productRepo interfaces.ProductRepository, orderRepo interfaces.OrderRepository) services.ProductUseCase {
+func NewProductUseCase(productRepo interfaces.ProductRepository, orderRepo interfaces.OrderRepository, promotionRepo interfaces.PromotionRepository) services.ProductUseCase {
 	return &productUseCase{
-		productRepo: productRepo,
-		orderRepo:   orderRepo,
+		productRepo:   productRepo,
+		orderRepo:     orderRepo,
+		promotionRepo: promotionRepo,
 	}
 
 }
 
-func (pu *productUseCase) CreateCategory(category request.Category) (response.Category, error) {
-	existingCategory, err := pu.productRepo.FindCategoryByName(category.CategoryName)
+// applyPromotions sets the selling price of the products from the running promotions.
+func (pu *productUseCase) applyPromotions(ctx context.Context, products []response.Product) error {
+	promotions, err := pu.promotionRepo.GetActivePromotions(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to fetch active promotions :%s", err)
+	}
+
+	helper.ApplyPromotions(products, promotions)
+	return nil
+}
+
+func (pu *productUseCase) CreateCategory(ctx context.Context, category request.Category) (response.Category, error) {
+	existingCategory, err := pu.productRepo.FindCategoryByName(ctx, category.CategoryName)
 	if existingCategory.ID != 0 {
 		return response.Category{}, ErrRecordAlreadyExist
 	}
 
-	result, err := pu.productRepo.CreateCategory(category)
+	result, err := pu.productRepo.CreateCategory(ctx, category)
 	if err != nil {
 		return response.Category{}, fmt.Errorf("Failed to create category : %s", err)
 	}
@@ -59,11 +74,11 @@ func (pu *productUseCase) CreateCategory(category request.Category) (response.Ca
 	return result, nil
 }
 
-func (pu *productUseCase) ReadAllCategories(page, count int) ([]response.Category, error) {
+func (pu *productUseCase) ReadAllCategories(ctx context.Context, page, count int) ([]response.Category, error) {
 
 	startIndex, endIndex := helper.Paginate(page, count)
 
-	listOfAllCategories, err := pu.productRepo.ReadCategory(startIndex, endIndex)
+	listOfAllCategories, err := pu.productRepo.ReadCategory(ctx, startIndex, endIndex)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to find categories :%s", err)
 	}
@@ -71,8 +86,8 @@ func (pu *productUseCase) ReadAllCategories(page, count int) ([]response.Categor
 	return listOfAllCategories, nil
 }
 
-func (pu *productUseCase) UpdateCategoryByID(productID int, category request.Category) error {
-	err := pu.productRepo.UpdateCategory(productID, category)
+func (pu *productUseCase) UpdateCategoryByID(ctx context.Context, productID int, category request.Category) error {
+	err := pu.productRepo.UpdateCategory(ctx, productID, category)
 	if err != nil {
 		return fmt.Errorf("Failed to update category :%s", err)
 	}
@@ -80,8 +95,8 @@ func (pu *productUseCase) UpdateCategoryByID(productID int, category request.Cat
 	return nil
 }
 
-func (pu *productUseCase) BlockCategoryByID(categoryID int) error {
-	err := pu.productRepo.BlockCategoryByID(categoryID)
+func (pu *productUseCase) BlockCategoryByID(ctx context.Context, categoryID int) error {
+	err := pu.productRepo.BlockCategoryByID(ctx, categoryID)
 	if err != nil {
 		return fmt.Errorf("Failed to block category :%s", err)
 	}
@@ -89,8 +104,8 @@ func (pu *productUseCase) BlockCategoryByID(categoryID int) error {
 	return nil
 }
 
-func (pu *productUseCase) UnBlockCategoryByID(categoryID int) error {
-	err := pu.productRepo.UnBlockCategoryByID(categoryID)
+func (pu *productUseCase) UnBlockCategoryByID(ctx context.Context, categoryID int) error {
+	err := pu.productRepo.UnBlockCategoryByID(ctx, categoryID)
 	if err != nil {
 		return fmt.Errorf("Failed to block category :%s", err)
 	}
@@ -98,8 +113,8 @@ func (pu *productUseCase) UnBlockCategoryByID(categoryID int) error {
 	return nil
 }
 
-func (pu *productUseCase) CreateProduct(product request.Product) (response.Product, error) {
-	category, err := pu.productRepo.FindCategoryByID(product.CategoryID)
+func (pu *productUseCase) CreateProduct(ctx context.Context, product request.Product) (response.Product, error) {
+	category, err := pu.productRepo.FindCategoryByID(ctx, product.CategoryID)
 	if err != nil {
 		return response.Product{}, fmt.Errorf("failed to find category: %s", err)
 	}
@@ -110,7 +125,7 @@ func (pu *productUseCase) CreateProduct(product request.Product) (response.Produ
 	product.Brand = category.Category_Name
 	product.SKU = helper.MakeSKU(product.ProductName)
 
-	existingProduct, err := pu.productRepo.FindProductByName(product.ProductName)
+	existingProduct, err := pu.productRepo.FindProductByName(ctx, product.ProductName)
 	if err != nil {
 		return response.Product{}, fmt.Errorf("failed to find product by name: %s", err)
 	}
@@ -118,7 +133,7 @@ func (pu *productUseCase) CreateProduct(product request.Product) (response.Produ
 		return response.Product{}, ErrRecordAlreadyExist
 	}
 
-	result, err := pu.productRepo.CreateProduct(product)
+	result, err := pu.productRepo.CreateProduct(ctx, product)
 	if err != nil {
 		return response.Product{}, fmt.Errorf("failed to create new product: %s", err)
 	}
@@ -126,30 +141,30 @@ func (pu *productUseCase) CreateProduct(product request.Product) (response.Produ
 	return result, nil
 }
 
-func (pu *productUseCase) DisplayAllProductsToAdmin(page, count int) ([]response.Product, error) {
+func (pu *productUseCase) DisplayAllProductsToAdmin(ctx context.Context, page, count int) ([]response.Product, error) {
 	startIndex, endIndex := helper.Paginate(page, count)
 
-	products, err := pu.productRepo.ViewAllProductsToAdmin(startIndex, endIndex)
+	products, err := pu.productRepo.ViewAllProductsToAdmin(ctx, startIndex, endIndex)
 	if err != nil {
 		return []response.Product{}, err
 	}
 
-	return products, nil
+	return products, pu.applyPromotions(ctx, products)
 }
 
-func (pu *productUseCase) DisplayAllProductsToUser(userID, page, count int) ([]response.Product, error) {
+func (pu *productUseCase) DisplayAllProductsToUser(ctx context.Context, userID, page, count int) ([]response.Product, error) {
 	startIndex, endIndex := helper.Paginate(page, count)
 
-	listOfAllProducts, err := pu.productRepo.ViewAllProductsToUser(userID, startIndex, endIndex)
+	listOfAllProducts, err := pu.productRepo.ViewAllProductsToUser(ctx, userID, startIndex, endIndex)
 	if err != nil {
 		return []response.Product{}, err
 	}
 
-	return listOfAllProducts, nil
+	return listOfAllProducts, pu.applyPromotions(ctx, listOfAllProducts)
 }
 
-func (pu *productUseCase) UpdateProductByID(productID int, update request.UpdateProduct) error {
-	err := pu.productRepo.UpdateProduct(productID, update)
+func (pu *productUseCase) UpdateProductByID(ctx context.Context, productID int, update request.UpdateProduct) error {
+	err := pu.productRepo.UpdateProduct(ctx, productID, update)
 	if err != nil {
 		return fmt.Errorf("Failed to update product :%s", err)
 	}
@@ -157,8 +172,8 @@ func (pu *productUseCase) UpdateProductByID(productID int, update request.Update
 	return nil
 }
 
-func (pu *productUseCase) BlockProductByID(productID int) error {
-	err := pu.productRepo.BlockProduct(productID)
+func (pu *productUseCase) BlockProductByID(ctx context.Context, productID int) error {
+	err := pu.productRepo.BlockProduct(ctx, productID)
 	if err != nil {
 		return fmt.Errorf("Failed to block product :%s", err)
 	}
@@ -166,31 +181,41 @@ func (pu *productUseCase) BlockProductByID(productID int) error {
 	return nil
 }
 
-func (pu *productUseCase) UnBlockProductByID(productID int) error {
-	err := pu.productRepo.UnblockProduct(productID)
+func (pu *productUseCase) UnBlockProductByID(ctx context.Context, productID int) error {
+	err := pu.productRepo.UnblockProduct(ctx, productID)
 	if err != nil {
 		return fmt.Errorf("Failed unblock product :%s", err)
 	}
 	return nil
 }
 
-func (pd *productUseCase) ViewIndividualProduct(userID, productID int) (response.ProductItem, error) {
+func (pd *productUseCase) ViewIndividualProduct(ctx context.Context, userID, productID int) (response.ProductItem, error) {
 
-	product, err := pd.productRepo.ViewIndividualProduct(userID, productID)
+	product, err := pd.productRepo.ViewIndividualProduct(ctx, userID, productID)
 	if err != nil {
 		return response.ProductItem{}, fmt.Errorf("Failed to find product :%s", err)
 	}
 
-	ratings, err := pd.productRepo.GetProductReviews(productID)
+	ratings, err := pd.productRepo.GetProductReviews(ctx, productID)
 	if err != nil {
 		return response.ProductItem{}, fmt.Errorf("Failed to find product reviews :%s", err)
 	}
 
+	promoted := []response.Product{product}
+	err = pd.applyPromotions(ctx, promoted)
+	if err != nil {
+		return response.ProductItem{}, err
+	}
+	product = promoted[0]
+
 	return response.ProductItem{
 		ID:                  product.ID,
 		CategoryID:          product.CategoryID,
 		Product_Name:        product.ProductName,
 		Price:               product.Price,
+		MRP:                 product.MRP,
+		SellingPrice:        product.SellingPrice,
+		Promotion:           product.Promotion,
 		SKU:                 product.SKU,
 		Brand:               product.Brand,
 		Product_Description: product.Product_Description,
@@ -202,8 +227,8 @@ func (pd *productUseCase) ViewIndividualProduct(userID, productID int) (response
 
 }
 
-func (pu *productUseCase) ValidateProductRatingRequest(userID, productID int) error {
-	rating, err := pu.productRepo.FindUserRatingOnProduct(userID, productID)
+func (pu *productUseCase) ValidateProductRatingRequest(ctx context.Context, userID, productID int) error {
+	rating, err := pu.productRepo.FindUserRatingOnProduct(ctx, userID, productID)
 	if err != nil {
 		return fmt.Errorf("Failed to find user rating")
 	}
@@ -211,7 +236,7 @@ func (pu *productUseCase) ValidateProductRatingRequest(userID, productID int) er
 		return ErrRecordAlreadyExist
 	}
 
-	orderData, err := pu.orderRepo.FindOrderByUserIDAndProductID(userID, productID)
+	orderData, err := pu.orderRepo.FindOrderByUserIDAndProductID(ctx, userID, productID)
 	if err != nil {
 		return fmt.Errorf("Failed to find order details")
 	}
@@ -220,27 +245,27 @@ func (pu *productUseCase) ValidateProductRatingRequest(userID, productID int) er
 		return ErrNoRecord
 	}
 
-	status, err := pu.orderRepo.FindOrderStatusByID(orderData.OrderStatusID)
+	status, err := pu.orderRepo.FindOrderStatusByID(ctx, orderData.OrderStatusID)
 	if err != nil {
 		return fmt.Errorf("Failed to find order status")
 	}
 
-	if status != statusDelivered || status != statusReturned {
+	if status != statusDelivered && status != statusReturned {
 		return ErrInProcessing
 	}
 
 	return nil
 }
 
-func (pu *productUseCase) InsertNewProductRating(userID int, productID int, rating request.Rating) error {
+func (pu *productUseCase) InsertNewProductRating(ctx context.Context, userID int, productID int, rating request.Rating) error {
 	rating.UserID = userID
 	rating.ProductID = productID
-	err := pu.productRepo.InsertProductRating(rating)
+	err := pu.productRepo.InsertProductRating(ctx, rating)
 	if err != nil {
 		return fmt.Errorf("Failed to insert product rating :%s", err)
 	}
 
-	userRating, err := pu.productRepo.FindUserRatingOnProduct(userID, productID)
+	userRating, err := pu.productRepo.FindUserRatingOnProduct(ctx, userID, productID)
 	if err != nil {
 		return fmt.Errorf("Failed to find product rating :%s", err)
 	}
@@ -251,59 +276,59 @@ func (pu *productUseCase) InsertNewProductRating(userID int, productID int, rati
 	return nil
 }
 
-func (pu *productUseCase) SearchProducts(search string, page, count int) ([]response.Product, error) {
+func (pu *productUseCase) SearchProducts(ctx context.Context, search string, page, count int) ([]response.Product, error) {
 	startIndex, endIndex := helper.Paginate(page, count)
 
-	products, err := pu.productRepo.SearchProducts(search, startIndex, endIndex)
+	products, err := pu.productRepo.SearchProducts(ctx, search, startIndex, endIndex)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to search products  :%s", err)
 	}
 
-	return products, nil
+	return products, pu.applyPromotions(ctx, products)
 }
 
-func (pu *productUseCase) GetProductsByCategoryUser(userID, categoryID, page, count int) ([]response.Product, error) {
+func (pu *productUseCase) GetProductsByCategoryUser(ctx context.Context, userID, categoryID, page, count int) ([]response.Product, error) {
 	startIndex, endIndex := helper.Paginate(page, count)
 
-	products, err := pu.productRepo.GetProductsByCategoryUser(userID, categoryID, startIndex, endIndex)
+	products, err := pu.productRepo.GetProductsByCategoryUser(ctx, userID, categoryID, startIndex, endIndex)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get products by category : %s", err)
 	}
 
-	return products, nil
+	return products, pu.applyPromotions(ctx, products)
 
 }
 
-func (pu *productUseCase) GetProductsByCategoryAdmin( categoryID, page, count int) ([]response.Product, error) {
+func (pu *productUseCase) GetProductsByCategoryAdmin(ctx context.Context,  categoryID, page, count int) ([]response.Product, error) {
 	startIndex, endIndex := helper.Paginate(page, count)
 
-	products, err := pu.productRepo.GetProductsByCategoryAdmin( categoryID, startIndex, endIndex)
+	products, err := pu.productRepo.GetProductsByCategoryAdmin(ctx,  categoryID, startIndex, endIndex)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get products by category : %s", err)
 	}
 
-	return products, nil
+	return products, pu.applyPromotions(ctx, products)
 
 }
-func (pu *productUseCase) UploadCategoryImage(files []*multipart.FileHeader, categoryID int) error {
+func (pu *productUseCase) UploadCategoryImage(ctx context.Context, files []*multipart.FileHeader, categoryID int) error {
 
-	err := pu.uploadImage(files, category, categoryID)
+	err := pu.uploadImage(ctx, files, category, categoryID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (pu *productUseCase) UploadProductImage(files []*multipart.FileHeader, productID int) error {
+func (pu *productUseCase) UploadProductImage(ctx context.Context, files []*multipart.FileHeader, productID int) error {
 
-	err := pu.uploadImage(files, product, productID)
+	err := pu.uploadImage(ctx, files, product, productID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (pu *productUseCase) uploadImage(files []*multipart.FileHeader, imageFor string, ID int) error {
+func (pu *productUseCase) uploadImage(ctx context.Context, files []*multipart.FileHeader, imageFor string, ID int) error {
 
 	imageURLs := make([]string, len(files))
 	for i, f := range files {
@@ -325,14 +350,14 @@ func (pu *productUseCase) uploadImage(files []*multipart.FileHeader, imageFor st
 	}
 
 	if imageFor == category {
-		err := pu.productRepo.InsertCategoryIMG(imageURLs, ID)
+		err := pu.productRepo.InsertCategoryIMG(ctx, imageURLs, ID)
 		if err != nil {
 			return err
 		}
 	}
 
 	if imageFor == product {
-		err := pu.productRepo.InsertProductIMG(imageURLs, ID)
+		err := pu.productRepo.InsertProductIMG(ctx, imageURLs, ID)
 		if err != nil {
 			return err
 		}
@@ -340,15 +365,20 @@ func (pu *productUseCase) uploadImage(files []*multipart.FileHeader, imageFor st
 	return nil
 }
 
-func (pu *productUseCase) AddToWishList(userID, productID int) error {
-	return pu.productRepo.AddToWishList(userID, productID)
+func (pu *productUseCase) AddToWishList(ctx context.Context, userID, productID int) error {
+	return pu.productRepo.AddToWishList(ctx, userID, productID)
 }
 
-func (pu *productUseCase) RemoveFromWishList(userID, productID int) error {
-	return pu.productRepo.RemoveFromWishList(userID, productID)
+func (pu *productUseCase) RemoveFromWishList(ctx context.Context, userID, productID int) error {
+	return pu.productRepo.RemoveFromWishList(ctx, userID, productID)
 }
 
-func (pu *productUseCase) ShowWishListProducts(userID, page, count int) ([]response.Product, error) {
+func (pu *productUseCase) ShowWishListProducts(ctx context.Context, userID, page, count int) ([]response.Product, error) {
 	startIndex, endIndex := helper.Paginate(page, count)
-	return pu.productRepo.ShowWishListProducts(userID, startIndex, endIndex)
+	products, err := pu.productRepo.ShowWishListProducts(ctx, userID, startIndex, endIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, pu.applyPromotions(ctx, products)
 }
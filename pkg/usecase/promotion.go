@@ -0,0 +1,291 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type promotionUseCase struct {
+	promotionRepo interfaces.PromotionRepository
+	productRepo   interfaces.ProductRepository
+	transactor    interfaces.Transactor
+}
+
+func NewPromotionUseCase(promotionRepo interfaces.PromotionRepository, productRepo interfaces.ProductRepository, transactor interfaces.Transactor) services.PromotionUseCase {
+	return &promotionUseCase{
+		promotionRepo: promotionRepo,
+		productRepo:   productRepo,
+		transactor:    transactor,
+	}
+}
+
+func (pu *promotionUseCase) validatePromotion(ctx context.Context, promotion request.Promotion) error {
+	if promotion.Type == helper.PromotionCategorySale && promotion.CategoryID == 0 {
+		return fmt.Errorf("Failed, category sale needs a category")
+	}
+
+	if promotion.CategoryID != 0 {
+		category, err := pu.productRepo.FindCategoryByID(ctx, promotion.CategoryID)
+		if err != nil {
+			return fmt.Errorf("Failed to find category :%s", err)
+		}
+		if category.ID == 0 {
+			return ErrCategoryNotFound
+		}
+	}
+
+	if promotion.ProductID != 0 {
+		product, err := pu.productRepo.FindProductByID(ctx, promotion.ProductID)
+		if err != nil {
+			return fmt.Errorf("Failed to find product :%s", err)
+		}
+		if product.ID == 0 {
+			return ErrNoRecord
+		}
+	}
+	return nil
+}
+
+func (pu *promotionUseCase) CreatePromotion(ctx context.Context, promotion request.Promotion) (response.Promotion, error) {
+	err := pu.validatePromotion(ctx, promotion)
+	if err != nil {
+		return response.Promotion{}, err
+	}
+
+	insertedPromotion, err := pu.promotionRepo.CreatePromotion(ctx, promotion)
+	if err != nil {
+		return response.Promotion{}, fmt.Errorf("Failed to insert promotion :%s", err)
+	}
+	if insertedPromotion.ID == 0 {
+		return response.Promotion{}, fmt.Errorf("Failed to verify inserted promotion")
+	}
+
+	// a promotion starting now should not wait for the next scheduler run
+	err = pu.promotionRepo.RefreshPromotionStatus(ctx, time.Now())
+	if err != nil {
+		return response.Promotion{}, fmt.Errorf("Failed to refresh promotion status :%s", err)
+	}
+
+	return insertedPromotion, nil
+}
+
+func (pu *promotionUseCase) UpdatePromotion(ctx context.Context, promotionID int, promotion request.Promotion) error {
+	err := pu.validatePromotion(ctx, promotion)
+	if err != nil {
+		return err
+	}
+
+	updatedPromotion, err := pu.promotionRepo.UpdatePromotion(ctx, promotionID, promotion)
+	if err != nil {
+		return fmt.Errorf("Failed to update promotion :%s", err)
+	}
+	if updatedPromotion.ID == 0 {
+		return ErrNoRecord
+	}
+
+	err = pu.promotionRepo.RefreshPromotionStatus(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to refresh promotion status :%s", err)
+	}
+	return nil
+}
+
+func (pu *promotionUseCase) ListPromotions(ctx context.Context) ([]response.Promotion, error) {
+	promotions, err := pu.promotionRepo.GetAllPromotions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch promotions :%s", err)
+	}
+	return promotions, nil
+}
+
+func (pu *promotionUseCase) BlockPromotion(ctx context.Context, promotionID int) error {
+	blockedPromotion, err := pu.promotionRepo.ChangePromotionBlockStatus(ctx, promotionID, true)
+	if err != nil {
+		return fmt.Errorf("Failed to block promotion :%s", err)
+	}
+	if blockedPromotion.ID == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+func (pu *promotionUseCase) UnBlockPromotion(ctx context.Context, promotionID int) error {
+	unBlockedPromotion, err := pu.promotionRepo.ChangePromotionBlockStatus(ctx, promotionID, false)
+	if err != nil {
+		return fmt.Errorf("Failed to unblock promotion :%s", err)
+	}
+	if unBlockedPromotion.ID == 0 {
+		return ErrNoRecord
+	}
+
+	err = pu.promotionRepo.RefreshPromotionStatus(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to refresh promotion status :%s", err)
+	}
+	return nil
+}
+
+func (pu *promotionUseCase) SchedulePriceChange(ctx context.Context, schedule request.ScheduledPrice) (response.ScheduledPrice, error) {
+	if schedule.EndsAt != nil && !schedule.EndsAt.After(schedule.StartsAt) {
+		return response.ScheduledPrice{}, fmt.Errorf("Failed, price change must end after it starts")
+	}
+
+	product, err := pu.productRepo.FindProductByID(ctx, schedule.ProductID)
+	if err != nil {
+		return response.ScheduledPrice{}, fmt.Errorf("Failed to find product :%s", err)
+	}
+	if product.ID == 0 {
+		return response.ScheduledPrice{}, ErrNoRecord
+	}
+
+	insertedSchedule, err := pu.promotionRepo.InsertScheduledPrice(ctx, schedule)
+	if err != nil {
+		return response.ScheduledPrice{}, fmt.Errorf("Failed to schedule price change :%s", err)
+	}
+	if insertedSchedule.ID == 0 {
+		return response.ScheduledPrice{}, fmt.Errorf("Failed to verify scheduled price change")
+	}
+	return insertedSchedule, nil
+}
+
+func (pu *promotionUseCase) ListScheduledPrices(ctx context.Context) ([]response.ScheduledPrice, error) {
+	schedules, err := pu.promotionRepo.GetScheduledPrices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch scheduled prices :%s", err)
+	}
+	return schedules, nil
+}
+
+func (pu *promotionUseCase) CancelScheduledPrice(ctx context.Context, scheduleID int) error {
+	cancelledSchedule, err := pu.promotionRepo.CancelScheduledPrice(ctx, scheduleID)
+	if err != nil {
+		return fmt.Errorf("Failed to cancel scheduled price :%s", err)
+	}
+	if cancelledSchedule.ID == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+func (pu *promotionUseCase) RunScheduler(ctx context.Context) error {
+	now := time.Now()
+
+	err := pu.promotionRepo.RefreshPromotionStatus(ctx, now)
+	if err != nil {
+		return fmt.Errorf("Failed to refresh promotion status :%s", err)
+	}
+
+	dueSchedules, err := pu.promotionRepo.GetDueScheduledPrices(ctx, now)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch due price changes :%s", err)
+	}
+
+	for _, schedule := range dueSchedules {
+		err = pu.transactor.Transaction(ctx, func(repos interfaces.TxRepositories) error {
+			return applyScheduledPrice(ctx, repos, schedule)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	endedSchedules, err := pu.promotionRepo.GetEndedScheduledPrices(ctx, now)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch ended price changes :%s", err)
+	}
+
+	for _, schedule := range endedSchedules {
+		err = pu.transactor.Transaction(ctx, func(repos interfaces.TxRepositories) error {
+			return endScheduledPrice(ctx, repos, schedule)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyScheduledPrice activates a due schedule and sets its price on the
+// product. The activation stores the base price before the product is changed,
+// a permanent change becomes the new base of the temporary schedules running
+// on the product and only goes live when none is.
+func applyScheduledPrice(ctx context.Context, repos interfaces.TxRepositories, schedule response.ScheduledPrice) error {
+	activated, err := repos.Promotion.ActivateScheduledPrice(ctx, schedule.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to activate scheduled price %d :%s", schedule.ID, err)
+	}
+	if activated.ID == 0 {
+		// cancelled or applied since it was fetched
+		return nil
+	}
+
+	mrp := schedule.MRP
+	if mrp == 0 {
+		mrp = activated.PreviousMRP
+	}
+
+	if schedule.EndsAt == nil {
+		_, err = repos.Promotion.CompleteScheduledPrice(ctx, schedule.ID)
+		if err != nil {
+			return fmt.Errorf("Failed to complete scheduled price :%s", err)
+		}
+
+		err = repos.Promotion.UpdateActiveBasePrice(ctx, schedule.ProductID, schedule.Price, mrp)
+		if err != nil {
+			return fmt.Errorf("Failed to update base price :%s", err)
+		}
+
+		running, err := repos.Promotion.FindActiveScheduledPrice(ctx, schedule.ProductID)
+		if err != nil {
+			return fmt.Errorf("Failed to find running price change :%s", err)
+		}
+		if running.ID != 0 {
+			return nil
+		}
+	}
+
+	err = repos.Product.UpdateProductPrice(ctx, schedule.ProductID, schedule.Price, mrp)
+	if err != nil {
+		return fmt.Errorf("Failed to update product price :%s", err)
+	}
+	return nil
+}
+
+// endScheduledPrice completes an ended temporary schedule and puts back the
+// price of the temporary schedule still running on the product, or the stored
+// base price when none is.
+func endScheduledPrice(ctx context.Context, repos interfaces.TxRepositories, schedule response.ScheduledPrice) error {
+	completed, err := repos.Promotion.CompleteScheduledPrice(ctx, schedule.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to complete scheduled price :%s", err)
+	}
+	if completed.ID == 0 {
+		return nil
+	}
+
+	price, mrp := completed.PreviousPrice, completed.PreviousMRP
+	running, err := repos.Promotion.FindActiveScheduledPrice(ctx, schedule.ProductID)
+	if err != nil {
+		return fmt.Errorf("Failed to find running price change :%s", err)
+	}
+	if running.ID != 0 {
+		price = running.Price
+		if running.MRP != 0 {
+			mrp = running.MRP
+		}
+	}
+
+	err = repos.Product.UpdateProductPrice(ctx, schedule.ProductID, price, mrp)
+	if err != nil {
+		return fmt.Errorf("Failed to restore product price :%s", err)
+	}
+	return nil
+}
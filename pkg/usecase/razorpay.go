@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
@@ -26,18 +27,25 @@ func NewRazorpayUseCase(paymentRepo interfaces.PaymentRepository,
 	}
 }
 
-func (ou *razorpayUseCase) GetRazorPayDetails(userID int) (response.PaymentDetails, error) {
-	userCart, err := ou.cartUseCase.ViewCart(userID)
+func (ou *razorpayUseCase) GetRazorPayDetails(ctx context.Context, userID int) (response.PaymentDetails, error) {
+	err := validateCart(ctx, ou.cartUseCase, userID)
+	if err != nil {
+		return response.PaymentDetails{}, err
+	}
+
+	userCart, err := ou.cartUseCase.ViewCart(ctx, userID)
 	if err != nil {
 		return response.PaymentDetails{}, fmt.Errorf("Failed to retrieve userCart :%s", err)
 	}
 
-	userData, err := ou.userRepo.FindUserByID(userID)
+	userData, err := ou.userRepo.FindUserByID(ctx, userID)
 	if err != nil {
 		return response.PaymentDetails{}, fmt.Errorf("Failed to find user  %s", err)
 	}
 
-	razorPayOrderID, err := helper.MakeRazorPayPaymentId(int(userCart.Total * 100))
+	// the wallet amount applied on the cart is not charged online
+	amount := int(helper.ToPaise(userCart.Payable))
+	razorPayOrderID, err := helper.MakeRazorPayPaymentId(amount)
 	if err != nil {
 		return response.PaymentDetails{}, fmt.Errorf("Failed to get razorpay id %s", err)
 	}
@@ -45,11 +53,11 @@ func (ou *razorpayUseCase) GetRazorPayDetails(userID int) (response.PaymentDetai
 	return response.PaymentDetails{
 		Username:        userData.UserName,
 		RazorPayOrderID: razorPayOrderID,
-		Amount:          int(userCart.Total),
+		Amount:          amount,
 	}, nil
 }
 
-func (ou *razorpayUseCase) VerifyRazorPayPayment(signature string, razorpayOrderID string, paymentID string) error {
+func (ou *razorpayUseCase) VerifyRazorPayPayment(ctx context.Context, signature string, razorpayOrderID string, paymentID string) error {
 	err := helper.VerifyRazorPayPayment(signature, razorpayOrderID, paymentID)
 	if err != nil {
 		return fmt.Errorf("Failed payment not success : %s", err)
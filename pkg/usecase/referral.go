@@ -1,56 +1,82 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
 	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
 
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
 )
 
+// rewards used until the admin configures the referral programme
+const (
+	defaultReferrerReward = 50
+	defaultRefereeReward  = 50
+)
+
 type referralUseCase struct {
 	referralRepo interfaces.ReferralRepository
-	orderUseCase interfaces.OrderRepository
+	orderRepo    interfaces.OrderRepository
+	userRepo     interfaces.UserRepository
 }
 
-func NewReferralUseCase(referraluseCase interfaces.ReferralRepository, orderUseCase interfaces.OrderRepository) services.ReferralUseCase {
+func NewReferralUseCase(referralRepo interfaces.ReferralRepository, orderRepo interfaces.OrderRepository, userRepo interfaces.UserRepository) services.ReferralUseCase {
 	return &referralUseCase{
-		referralRepo: referraluseCase,
-		orderUseCase: orderUseCase,
+		referralRepo: referralRepo,
+		orderRepo:    orderRepo,
+		userRepo:     userRepo,
 	}
 }
 
-func (ru *referralUseCase) GetUserReferralCode(userID int) (response.Referral, error) {
-	referralCode, err := ru.referralRepo.FindReferralCodeByUserID(userID)
+func (ru *referralUseCase) GetUserReferralCode(ctx context.Context, userID int, deviceID string) (response.Referral, error) {
+	referralCode, err := ru.referralRepo.FindReferralCodeByUserID(ctx, userID)
 	if err != nil {
 		return response.Referral{}, fmt.Errorf("Failed to find referral code by user id : %s", err)
 	}
-	if referralCode.ID != 0 {
-		return referralCode, nil
-	}
 
-	code := helper.GenerateReferralCode()
+	if referralCode.ID == 0 {
+		code := helper.GenerateReferralCode()
 
-	newReferralCode, err := ru.referralRepo.InsertNewReferralCode(userID, code)
-	if err != nil {
-		return response.Referral{}, fmt.Errorf("Failed to create new refferal code :%s", err)
+		referralCode, err = ru.referralRepo.InsertNewReferralCode(ctx, userID, code)
+		if err != nil {
+			return response.Referral{}, fmt.Errorf("Failed to create new refferal code :%s", err)
+		}
+		if referralCode.ID == 0 || referralCode.Code == "" {
+			return response.Referral{}, fmt.Errorf("Failed to verify the refferal code ")
+		}
 	}
-	if newReferralCode.ID == 0 || newReferralCode.Code == "" {
-		return response.Referral{}, fmt.Errorf("Failed to verify the refferal code ")
 
+	// remember the owner's device so the code can't be claimed from it
+	if deviceID != "" && referralCode.DeviceID != deviceID {
+		referralCode, err = ru.referralRepo.UpdateReferralDevice(ctx, int(referralCode.ID), deviceID)
+		if err != nil {
+			return response.Referral{}, fmt.Errorf("Failed to update referral device :%s", err)
+		}
 	}
-	return newReferralCode, nil
 
+	return referralCode, nil
 }
 
-func (ru *referralUseCase) VerifyReferralCode(referralCode string, claimingUserID int) (int, error) {
+func (ru *referralUseCase) VerifyReferralCode(ctx context.Context, referralCode string, claimingUserID int, deviceID string) (int, error) {
 	if referralCode == "" {
 		return -1, fmt.Errorf("No referral code provided")
 	}
 
-	referralCodeDetails, err := ru.referralRepo.FindReferralCodeByCode(referralCode)
+	programme, err := ru.GetReferralProgramme(ctx)
+	if err != nil {
+		return -1, err
+	}
+	if !programme.IsActive {
+		return -1, fmt.Errorf("Referral programme is not active")
+	}
+
+	referralCodeDetails, err := ru.referralRepo.FindReferralCodeByCode(ctx, referralCode)
 	if err != nil {
 		return -1, fmt.Errorf("Failed to find referral code : %s", err)
 	}
@@ -59,60 +85,263 @@ func (ru *referralUseCase) VerifyReferralCode(referralCode string, claimingUserI
 		return -1, fmt.Errorf("Invalid, referral code doesn't exist")
 	}
 
-	if referralCodeDetails.ID == uint(claimingUserID) {
-		return -1, fmt.Errorf("Not allowed to use this coupon")
+	if referralCodeDetails.UserID == uint(claimingUserID) {
+		return -1, fmt.Errorf("Not allowed to use your own referral code")
 	}
 
-	referralCodeOwnerID := referralCodeDetails.UserID
-	return int(referralCodeOwnerID), nil
-}
+	claim, err := ru.referralRepo.FindReferralClaimByRefereeID(ctx, claimingUserID)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to find referral claim : %s", err)
+	}
+	if claim.ID != 0 {
+		return -1, fmt.Errorf("Referral code already claimed")
+	}
 
-func (ru *referralUseCase) ClaimReferralBonus(claimingUserID, codeOwnerID int) error {
-	referredUserWallet, err := ru.orderUseCase.FindUserWalletByID(int(codeOwnerID))
+	stats, err := ru.orderRepo.GetUserOrderStats(ctx, claimingUserID)
 	if err != nil {
-		return fmt.Errorf("Failed to find user wallet : %s", err)
+		return -1, fmt.Errorf("Failed to find user orders : %s", err)
 	}
-	if referredUserWallet.ID == 0 {
+	if stats.OrderCount > 0 {
+		return -1, fmt.Errorf("Referral code can only be claimed before the first order")
+	}
+
+	codeOwnerID := int(referralCodeDetails.UserID)
 
-		newReferredUserWallet, err := ru.orderUseCase.InitializeNewUserWallet(int(codeOwnerID))
+	samePhone, err := ru.sharePhone(ctx, codeOwnerID, claimingUserID)
+	if err != nil {
+		return -1, err
+	}
+	if samePhone {
+		return -1, fmt.Errorf("Not allowed to use this referral code")
+	}
+
+	if deviceID != "" {
+		if deviceID == referralCodeDetails.DeviceID {
+			return -1, fmt.Errorf("Not allowed to use this referral code")
+		}
+
+		count, err := ru.referralRepo.CountReferralClaimsByDevice(ctx, deviceID)
 		if err != nil {
-			return fmt.Errorf("Failed to initialize wallet for code owner: %s", err)
+			return -1, fmt.Errorf("Failed to check referral device : %s", err)
+		}
+		if count > 0 {
+			return -1, fmt.Errorf("Referral code already claimed from this device")
 		}
-		if newReferredUserWallet.ID == 0 {
-			return fmt.Errorf("Failed to verify code owner new wallet")
+	}
+
+	return codeOwnerID, nil
+}
+
+// sharePhone reports whether the two users have a phone number in common,
+// either as the account phone or on one of their addresses.
+func (ru *referralUseCase) sharePhone(ctx context.Context, ownerID, claimingUserID int) (bool, error) {
+	ownerPhones, err := ru.userPhones(ctx, ownerID)
+	if err != nil {
+		return false, err
+	}
+	claimingUserPhones, err := ru.userPhones(ctx, claimingUserID)
+	if err != nil {
+		return false, err
+	}
+
+	for phone := range claimingUserPhones {
+		if ownerPhones[phone] {
+			return true, nil
 		}
 	}
+	return false, nil
+}
 
-	bonusClaimingUserWallet, err := ru.orderUseCase.FindUserWalletByID(int(claimingUserID))
+func (ru *referralUseCase) userPhones(ctx context.Context, userID int) (map[string]bool, error) {
+	user, err := ru.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find user : %s", err)
+	}
+
+	addresses, err := ru.userRepo.GetAllUserAddresses(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find user addresses : %s", err)
+	}
+
+	phones := make(map[string]bool)
+	if user.Phone != 0 {
+		phones[strconv.Itoa(user.Phone)] = true
+	}
+	for _, address := range addresses {
+		for _, phone := range []string{address.PhoneNumber, address.AlternativePhone} {
+			if phone != "" {
+				phones[phone] = true
+			}
+		}
+	}
+	return phones, nil
+}
+
+// ClaimReferralBonus records a pending claim, the rewards are credited on the
+// referee's first delivered order.
+func (ru *referralUseCase) ClaimReferralBonus(ctx context.Context, claimingUserID, codeOwnerID int, deviceID string) error {
+	referral, err := ru.referralRepo.FindReferralCodeByUserID(ctx, codeOwnerID)
+	if err != nil {
+		return fmt.Errorf("Failed to find referral code : %s", err)
+	}
+	if referral.ID == 0 {
+		return fmt.Errorf("Failed to verify referral code")
+	}
+
+	programme, err := ru.GetReferralProgramme(ctx)
+	if err != nil {
+		return err
+	}
+
+	claim, err := ru.referralRepo.InsertReferralClaim(ctx, request.ReferralClaim{
+		ReferralID:     referral.ID,
+		ReferrerID:     codeOwnerID,
+		RefereeID:      claimingUserID,
+		ReferrerReward: programme.ReferrerReward,
+		RefereeReward:  programme.RefereeReward,
+		DeviceID:       deviceID,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to claim referral code : %s", err)
+	}
+	if claim.ID == 0 {
+		return fmt.Errorf("Failed to verify referral claim")
+	}
+
+	return nil
+}
+
+// Subscribe releases the referral rewards on the referee's first delivered order.
+func (ru *referralUseCase) Subscribe(bus services.EventUseCase) {
+	bus.Subscribe(EventOrderStatusChanged, "referral", func(ctx context.Context, event response.DomainEvent) error {
+		var changed request.OrderStatusChanged
+		if err := decodeEvent(event, &changed); err != nil {
+			return err
+		}
+		if changed.Status != statusDelivered {
+			return nil
+		}
+		return ru.ReleaseReferralReward(ctx, int(changed.Order.UserID))
+	})
+}
+
+func (ru *referralUseCase) ReleaseReferralReward(ctx context.Context, refereeID int) error {
+	claim, err := ru.referralRepo.FindReferralClaimByRefereeID(ctx, refereeID)
+	if err != nil {
+		return fmt.Errorf("Failed to find referral claim : %s", err)
+	}
+	if claim.ID == 0 || claim.Status != "pending" {
+		return nil
+	}
+
+	releasedClaim, err := ru.referralRepo.ReleaseReferralClaim(ctx, int(claim.ID), time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to release referral claim : %s", err)
+	}
+	if releasedClaim.ID == 0 {
+		return nil // released by someone else
+	}
+
+	err = ru.creditWallet(ctx, int(releasedClaim.ReferrerID), releasedClaim.ReferrerReward)
+	if err != nil {
+		return fmt.Errorf("Failed to credit referral reward to code owner : %s", err)
+	}
+
+	err = ru.creditWallet(ctx, int(releasedClaim.RefereeID), releasedClaim.RefereeReward)
+	if err != nil {
+		return fmt.Errorf("Failed to credit referral reward : %s", err)
+	}
+
+	return nil
+}
+
+func (ru *referralUseCase) creditWallet(ctx context.Context, userID int, amount float32) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	wallet, err := ru.orderRepo.FindUserWalletByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to find user wallet : %s", err)
 	}
-	if bonusClaimingUserWallet.ID == 0 {
-		bonusClaimingUserWallet, err := ru.orderUseCase.InitializeNewUserWallet(claimingUserID)
+	if wallet.ID == 0 {
+		wallet, err = ru.orderRepo.InitializeNewUserWallet(ctx, userID)
 		if err != nil {
-			return fmt.Errorf("Failed to initialize wallet for code owner: %s", err)
+			return fmt.Errorf("Failed to initialize wallet : %s", err)
 		}
-		if bonusClaimingUserWallet.ID == 0 {
-			return fmt.Errorf("Failed to verify code owner new wallet")
+		if wallet.ID == 0 {
+			return fmt.Errorf("Failed to verify new wallet")
 		}
-
 	}
 
-	referredUserWallet, err = ru.orderUseCase.UpdateUserWalletBalance(int(codeOwnerID), (referredUserWallet.Amount + 50))
+	wallet, err = ru.orderRepo.UpdateUserWalletBalance(ctx, userID, wallet.Amount+amount)
 	if err != nil {
-		return fmt.Errorf("Failed update bonus on  : %s", err)
+		return fmt.Errorf("Failed to update wallet balance : %s", err)
 	}
-	if referredUserWallet.ID == 0 {
-		return fmt.Errorf("Failed to verify bonus updated wallet")
+	if wallet.ID == 0 {
+		return fmt.Errorf("Failed to verify updated wallet")
 	}
 
-	bonusClaimingUserWallet, err = ru.orderUseCase.UpdateUserWalletBalance(int(claimingUserID), (bonusClaimingUserWallet.Amount + 50))
+	walletHistory, err := ru.orderRepo.UpdateWalletTransactionHistory(ctx, request.WalletTransactionHistory{
+		TransactionTime: time.Now(),
+		UserID:          userID,
+		Amount:          amount,
+		TransactionType: credit,
+	})
 	if err != nil {
-		return fmt.Errorf("Failed update bonus  : %s", err)
+		return fmt.Errorf("Failed to update wallet history : %s", err)
 	}
-	if referredUserWallet.ID == 0 {
-		return fmt.Errorf("Failed to verify bonus updated wallet")
+	if walletHistory.ID == 0 {
+		return fmt.Errorf("Failed to verify the updated history")
 	}
 
 	return nil
 }
+
+func (ru *referralUseCase) GetReferralStats(ctx context.Context, userID int) (response.ReferralStats, error) {
+	referral, err := ru.referralRepo.FindReferralCodeByUserID(ctx, userID)
+	if err != nil {
+		return response.ReferralStats{}, fmt.Errorf("Failed to find referral code by user id : %s", err)
+	}
+
+	stats, err := ru.referralRepo.GetReferralStats(ctx, userID)
+	if err != nil {
+		return response.ReferralStats{}, fmt.Errorf("Failed to get referral stats : %s", err)
+	}
+
+	programme, err := ru.GetReferralProgramme(ctx)
+	if err != nil {
+		return response.ReferralStats{}, err
+	}
+
+	stats.Code = referral.Code
+	stats.ReferrerReward = programme.ReferrerReward
+	stats.RefereeReward = programme.RefereeReward
+	return stats, nil
+}
+
+func (ru *referralUseCase) GetReferralProgramme(ctx context.Context) (response.ReferralProgramme, error) {
+	programme, err := ru.referralRepo.GetReferralProgramme(ctx)
+	if err != nil {
+		return response.ReferralProgramme{}, fmt.Errorf("Failed to get referral programme : %s", err)
+	}
+	if programme.ID == 0 {
+		return response.ReferralProgramme{
+			ReferrerReward: defaultReferrerReward,
+			RefereeReward:  defaultRefereeReward,
+			IsActive:       true,
+		}, nil
+	}
+	return programme, nil
+}
+
+func (ru *referralUseCase) UpdateReferralProgramme(ctx context.Context, programme request.ReferralProgramme) (response.ReferralProgramme, error) {
+	updatedProgramme, err := ru.referralRepo.InsertReferralProgramme(ctx, programme)
+	if err != nil {
+		return response.ReferralProgramme{}, fmt.Errorf("Failed to update referral programme : %s", err)
+	}
+	if updatedProgramme.ID == 0 {
+		return response.ReferralProgramme{}, fmt.Errorf("Failed to verify referral programme")
+	}
+	return updatedProgramme, nil
+}
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+const (
+	reminderSent   = "sent"
+	reminderFailed = "failed"
+
+	defaultReportDays = 30
+)
+
+type reminderUseCase struct {
+	reminderRepo interfaces.ReminderRepository
+	userRepo     interfaces.UserRepository
+	senders      []helper.Sender
+	steps        []time.Duration // idle period before each reminder of the sequence
+	baseURL      string
+}
+
+func NewReminderUseCase(reminderRepo interfaces.ReminderRepository, userRepo interfaces.UserRepository, senders []helper.Sender, steps []time.Duration, baseURL string) services.ReminderUseCase {
+	return &reminderUseCase{
+		reminderRepo: reminderRepo,
+		userRepo:     userRepo,
+		senders:      senders,
+		steps:        steps,
+		baseURL:      baseURL,
+	}
+}
+
+func (ru *reminderUseCase) SendCartReminders(ctx context.Context) error {
+	now := time.Now()
+	if len(ru.steps) == 0 {
+		return nil
+	}
+
+	err := ru.reminderRepo.CloseEmptyAbandonedCarts(ctx, now)
+	if err != nil {
+		return fmt.Errorf("Failed to close emptied carts :%s", err)
+	}
+
+	idleCarts, err := ru.reminderRepo.GetIdleCarts(ctx, now.Add(-ru.steps[0]))
+	if err != nil {
+		return fmt.Errorf("Failed to get idle carts :%s", err)
+	}
+
+	for _, idleCart := range idleCarts {
+		abandonedCart, err := ru.trackAbandonedCart(ctx, idleCart, now)
+		if err != nil {
+			return err
+		}
+
+		step := abandonedCart.RemindersSent
+		if step >= len(ru.steps) || now.Sub(idleCart.LastActivity) < ru.steps[step] {
+			continue
+		}
+
+		unsubscribed, err := ru.reminderRepo.IsUnsubscribed(ctx, idleCart.UserID)
+		if err != nil {
+			return fmt.Errorf("Failed to check reminder subscription :%s", err)
+		}
+		if unsubscribed {
+			continue
+		}
+
+		err = ru.sendReminder(ctx, abandonedCart, step+1, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// trackAbandonedCart records the idle cart, or refreshes the record of a cart
+// already being reminded.
+func (ru *reminderUseCase) trackAbandonedCart(ctx context.Context, idleCart response.IdleCart, now time.Time) (response.AbandonedCart, error) {
+	abandonedCart, err := ru.reminderRepo.FindOpenAbandonedCart(ctx, idleCart.UserID)
+	if err != nil {
+		return response.AbandonedCart{}, fmt.Errorf("Failed to find abandoned cart :%s", err)
+	}
+
+	if abandonedCart.ID == 0 {
+		abandonedCart, err = ru.reminderRepo.InsertAbandonedCart(ctx, idleCart, now)
+	} else {
+		abandonedCart, err = ru.reminderRepo.UpdateAbandonedCart(ctx, int(abandonedCart.ID), idleCart)
+	}
+	if err != nil {
+		return response.AbandonedCart{}, fmt.Errorf("Failed to save abandoned cart :%s", err)
+	}
+	if abandonedCart.ID == 0 {
+		return response.AbandonedCart{}, fmt.Errorf("Failed to verify abandoned cart")
+	}
+	return abandonedCart, nil
+}
+
+// sendReminder sends the reminder on every channel the user can be reached on.
+// A failed channel is recorded and does not stop the others.
+func (ru *reminderUseCase) sendReminder(ctx context.Context, abandonedCart response.AbandonedCart, step int, now time.Time) error {
+	user, err := ru.userRepo.FindUserByID(ctx, abandonedCart.UserID)
+	if err != nil {
+		return fmt.Errorf("Failed to find user :%s", err)
+	}
+	if user.ID == 0 || user.IsBlocked {
+		return nil
+	}
+
+	token, err := helper.GenerateUnsubscribeToken(user.ID)
+	if err != nil {
+		return err
+	}
+
+	subject := "You left something in your cart"
+	body := fmt.Sprintf("Hi %s,\n\nYour cart of %d item(s) worth %.2f is waiting for you.\n\nTo stop these reminders visit %s/api/v1/cart-reminders/unsubscribe?token=%s",
+		user.UserName, abandonedCart.ItemCount, abandonedCart.CartValue, ru.baseURL, token)
+
+	for _, sender := range ru.senders {
+		var to string
+		switch sender.Channel() {
+		case helper.ChannelEmail:
+			to = user.Email
+		case helper.ChannelSMS:
+			if user.Phone != 0 {
+				to = fmt.Sprint(user.Phone)
+			}
+		}
+		if to == "" {
+			continue
+		}
+
+		reminder := response.CartReminder{
+			AbandonedCartID: abandonedCart.ID,
+			Step:            step,
+			Channel:         sender.Channel(),
+			Status:          reminderSent,
+			SentAt:          now,
+		}
+		if err := sender.Send(ctx, to, subject, body); err != nil {
+			helper.LoggerFrom(ctx).Warn("cart reminder not sent", "abandoned_cart_id", abandonedCart.ID, "step", step, "error", err)
+			reminder.Status = reminderFailed
+			reminder.Error = err.Error()
+		}
+
+		insertedReminder, err := ru.reminderRepo.InsertCartReminder(ctx, reminder)
+		if err != nil {
+			return fmt.Errorf("Failed to record cart reminder :%s", err)
+		}
+		if insertedReminder.ID == 0 {
+			return fmt.Errorf("Failed to verify cart reminder")
+		}
+	}
+
+	err = ru.reminderRepo.UpdateRemindersSent(ctx, int(abandonedCart.ID), step)
+	if err != nil {
+		return fmt.Errorf("Failed to update reminders sent :%s", err)
+	}
+	return nil
+}
+
+// Subscribe counts an order placed from a cart that was being reminded as recovered.
+func (ru *reminderUseCase) Subscribe(bus services.EventUseCase) {
+	bus.Subscribe(EventOrderPlaced, "cart-recovery", func(ctx context.Context, event response.DomainEvent) error {
+		var placed request.OrderPlaced
+		if err := decodeEvent(event, &placed); err != nil {
+			return err
+		}
+		return ru.MarkCartRecovered(ctx, placed.UserID, placed.Amount)
+	})
+}
+
+func (ru *reminderUseCase) MarkCartRecovered(ctx context.Context, userID int, value float32) error {
+	err := ru.reminderRepo.MarkCartRecovered(ctx, userID, value, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to mark cart recovered :%s", err)
+	}
+	return nil
+}
+
+func (ru *reminderUseCase) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := helper.ParseUnsubscribeToken(token)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	err = ru.reminderRepo.Unsubscribe(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to unsubscribe from reminders :%s", err)
+	}
+	return nil
+}
+
+func (ru *reminderUseCase) GetAbandonedCartReport(ctx context.Context, from, to time.Time) (response.AbandonedCartReport, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultReportDays)
+	}
+
+	report, err := ru.reminderRepo.GetAbandonedCartReport(ctx, from, to)
+	if err != nil {
+		return response.AbandonedCartReport{}, fmt.Errorf("Failed to get abandoned cart report :%s", err)
+	}
+
+	report.From, report.To = from, to
+	if report.AbandonedCarts != 0 {
+		report.RecoveryRate = float32(report.RecoveredCarts) * 100 / float32(report.AbandonedCarts)
+	}
+	return report, nil
+}
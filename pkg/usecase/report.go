@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/request"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+const (
+	defaultSalesTop  = 5
+	maxSalesTop      = 50
+	defaultTaxRate   = 18
+	salesExportBatch = 500
+)
+
+var (
+	ErrInvalidGranularity = errors.New("granularity must be day, week or month")
+	ErrInvalidPeriod      = errors.New("the start of the period must be before the end")
+	ErrInvalidReport      = errors.New("report must be sales, tax or refunds")
+)
+
+// salesGranularities are the periods the sales series is summed up by.
+var salesGranularities = map[string]bool{"day": true, "week": true, "month": true}
+
+type reportUseCase struct {
+	reportRepo interfaces.ReportRepository
+	taxRate    float64 // percent included in the prices
+}
+
+// NewReportUseCase takes the tax rate included in the prices in percent, zero
+// uses 18.
+func NewReportUseCase(reportRepo interfaces.ReportRepository, taxRate float64) services.ReportUseCase {
+	if taxRate <= 0 {
+		taxRate = defaultTaxRate
+	}
+	return &reportUseCase{
+		reportRepo: reportRepo,
+		taxRate:    taxRate,
+	}
+}
+
+func (ru *reportUseCase) GetSalesAnalytics(ctx context.Context, report request.SalesReport) (response.SalesAnalytics, error) {
+	var err error
+	if report.From, report.To, err = reportPeriod(report.From, report.To); err != nil {
+		return response.SalesAnalytics{}, err
+	}
+
+	if report.Granularity == "" {
+		report.Granularity = "day"
+	}
+	if !salesGranularities[report.Granularity] {
+		return response.SalesAnalytics{}, ErrInvalidGranularity
+	}
+
+	if report.Top <= 0 {
+		report.Top = defaultSalesTop
+	}
+	if report.Top > maxSalesTop {
+		report.Top = maxSalesTop
+	}
+
+	from, to, pointValue := report.From, report.To, float32(loyaltyPointValue)
+	analytics := response.SalesAnalytics{
+		From:        from,
+		To:          to,
+		Granularity: report.Granularity,
+	}
+
+	if analytics.Summary, err = ru.reportRepo.GetSalesSummary(ctx, from, to, pointValue); err != nil {
+		return response.SalesAnalytics{}, fmt.Errorf("Failed to get sales summary :%s", err)
+	}
+	analytics.Summary.AverageOrderValue = average(analytics.Summary.NetRevenue, analytics.Summary.Orders)
+
+	if analytics.Series, err = ru.reportRepo.GetSalesSeries(ctx, from, to, pointValue, report.Granularity); err != nil {
+		return response.SalesAnalytics{}, fmt.Errorf("Failed to get sales series :%s", err)
+	}
+
+	if analytics.TopProducts, err = ru.reportRepo.GetTopSales(ctx, "product", from, to, pointValue, report.Top); err != nil {
+		return response.SalesAnalytics{}, fmt.Errorf("Failed to get top products :%s", err)
+	}
+	if analytics.TopCategories, err = ru.reportRepo.GetTopSales(ctx, "category", from, to, pointValue, report.Top); err != nil {
+		return response.SalesAnalytics{}, fmt.Errorf("Failed to get top categories :%s", err)
+	}
+	if analytics.TopBrands, err = ru.reportRepo.GetTopSales(ctx, "brand", from, to, pointValue, report.Top); err != nil {
+		return response.SalesAnalytics{}, fmt.Errorf("Failed to get top brands :%s", err)
+	}
+
+	if analytics.PaymentMix, err = ru.reportRepo.GetPaymentMix(ctx, from, to, pointValue); err != nil {
+		return response.SalesAnalytics{}, fmt.Errorf("Failed to get payment mix :%s", err)
+	}
+	var paid float32
+	for _, method := range analytics.PaymentMix {
+		paid += method.Amount
+	}
+	for i := range analytics.PaymentMix {
+		if paid > 0 {
+			analytics.PaymentMix[i].Share = analytics.PaymentMix[i].Amount * 100 / paid
+		}
+	}
+
+	if analytics.Coupons, err = ru.reportRepo.GetCouponPerformance(ctx, from, to, pointValue); err != nil {
+		return response.SalesAnalytics{}, fmt.Errorf("Failed to get coupon performance :%s", err)
+	}
+	for i, coupon := range analytics.Coupons {
+		analytics.Coupons[i].AverageOrderValue = average(coupon.NetRevenue, coupon.Orders)
+		if coupon.Discount > 0 {
+			analytics.Coupons[i].RevenuePerRupee = coupon.NetRevenue / coupon.Discount
+		}
+	}
+
+	if analytics.Customers, err = ru.reportRepo.GetCustomerSegments(ctx, from, to, pointValue); err != nil {
+		return response.SalesAnalytics{}, fmt.Errorf("Failed to get customer segments :%s", err)
+	}
+	for i, segment := range analytics.Customers {
+		analytics.Customers[i].AverageOrderValue = average(segment.NetRevenue, segment.Orders)
+	}
+
+	return analytics, nil
+}
+
+// average is the amount per order, zero without orders.
+func average(amount float32, orders int) float32 {
+	if orders == 0 {
+		return 0
+	}
+	return amount / float32(orders)
+}
+
+// reportPeriod defaults the period to the last 30 days.
+func reportPeriod(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultReportDays)
+	}
+	if !from.Before(to) {
+		return from, to, ErrInvalidPeriod
+	}
+	return from, to, nil
+}
+
+func (ru *reportUseCase) ExportSales(ctx context.Context, export request.SalesExport, w io.Writer) error {
+	var err error
+	if export.From, export.To, err = reportPeriod(export.From, export.To); err != nil {
+		return err
+	}
+
+	var write func(context.Context, request.SalesExport, helper.ReportWriter) error
+	switch export.Report {
+	case "sales":
+		write = ru.writeSalesReport
+	case "tax":
+		write = ru.writeTaxReport
+	case "refunds":
+		write = ru.writeRefundReport
+	default:
+		return ErrInvalidReport
+	}
+
+	title := fmt.Sprintf("Device mart %s report, %s to %s", export.Report,
+		export.From.Format("January 2, 2006"), export.To.Add(-time.Nanosecond).Format("January 2, 2006"))
+	writer, err := helper.NewReportWriter(export.Format, title, w)
+	if err != nil {
+		return err
+	}
+	if err := write(ctx, export, writer); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func (ru *reportUseCase) writeSalesReport(ctx context.Context, export request.SalesExport, writer helper.ReportWriter) error {
+	pointValue := float32(loyaltyPointValue)
+	summary, err := ru.reportRepo.GetSalesSummary(ctx, export.From, export.To, pointValue)
+	if err != nil {
+		return fmt.Errorf("Failed to get sales summary :%s", err)
+	}
+
+	if err := writer.Sheet("Summary", []string{"Metric", "Value"}); err != nil {
+		return err
+	}
+	rows := []struct {
+		metric string
+		value  interface{}
+	}{
+		{"Placed orders", summary.PlacedOrders},
+		{"Gross sales", summary.GrossSales},
+		{"Orders", summary.Orders},
+		{"Units", summary.Units},
+		{"Coupon discounts", summary.Discounts},
+		{"Points redeemed", summary.PointsRedeemed},
+		{"Net revenue", summary.NetRevenue},
+		{"Average order value", average(summary.NetRevenue, summary.Orders)},
+		{"Cancelled orders", summary.CancelledOrders},
+		{"Cancelled value", summary.CancelledValue},
+		{"Returned orders", summary.ReturnedOrders},
+		{"Returned value", summary.ReturnedValue},
+		{"Customers", summary.Customers},
+	}
+	for _, row := range rows {
+		if err := writer.Row(row.metric, row.value); err != nil {
+			return err
+		}
+	}
+
+	columns := []string{"Order ID", "Date", "Status", "User ID", "Customer", "Product ID", "Product", "Brand", "Category",
+		"Qty", "Price", "Gross sales", "Coupon", "Discount", "Points redeemed", "Net revenue", "Payment method", "State", "Pincode"}
+	if err := writer.Sheet("Orders", columns); err != nil {
+		return err
+	}
+	return ru.eachSalesLine(ctx, export, func(line response.SalesLine) error {
+		return writer.Row(line.OrderID, line.CreatedAt, line.Status, line.UserID, line.Customer, line.ProductID, line.ProductName,
+			line.Brand, line.Category, line.Qty, line.Price, line.GrossSales, line.CouponCode, line.Discount, line.PointsRedeemed,
+			line.NetRevenue, line.PaymentMethod, line.State, line.Pincode)
+	})
+}
+
+// writeTaxReport splits the net revenue of the orders not cancelled or returned
+// into the taxable value and the tax included in the prices.
+func (ru *reportUseCase) writeTaxReport(ctx context.Context, export request.SalesExport, writer helper.ReportWriter) error {
+	states, err := ru.reportRepo.GetTaxByState(ctx, export.From, export.To, float32(loyaltyPointValue))
+	if err != nil {
+		return fmt.Errorf("Failed to get tax by state :%s", err)
+	}
+
+	if err := writer.Sheet(fmt.Sprintf("Tax by state at %s%%", strconv.FormatFloat(ru.taxRate, 'f', -1, 64)),
+		[]string{"State", "Orders", "Net revenue", "Taxable value", "Tax"}); err != nil {
+		return err
+	}
+	var total response.TaxSummary
+	for _, state := range states {
+		state.TaxableValue, state.Tax = ru.splitTax(state.NetRevenue)
+		if err := writer.Row(state.State, state.Orders, state.NetRevenue, state.TaxableValue, state.Tax); err != nil {
+			return err
+		}
+		total.Orders += state.Orders
+		total.NetRevenue = helper.FromPaise(helper.ToPaise(total.NetRevenue) + helper.ToPaise(state.NetRevenue))
+		total.TaxableValue = helper.FromPaise(helper.ToPaise(total.TaxableValue) + helper.ToPaise(state.TaxableValue))
+		total.Tax = helper.FromPaise(helper.ToPaise(total.Tax) + helper.ToPaise(state.Tax))
+	}
+	if err := writer.Row("Total", total.Orders, total.NetRevenue, total.TaxableValue, total.Tax); err != nil {
+		return err
+	}
+
+	columns := []string{"Order ID", "Date", "State", "Pincode", "Product", "Category", "Qty", "Net revenue", "Taxable value", "Tax"}
+	if err := writer.Sheet("Orders", columns); err != nil {
+		return err
+	}
+	return ru.eachSalesLine(ctx, export, func(line response.SalesLine) error {
+		if line.Lost {
+			return nil
+		}
+		taxable, tax := ru.splitTax(line.NetRevenue)
+		return writer.Row(line.OrderID, line.CreatedAt, line.State, line.Pincode, line.ProductName, line.Category,
+			line.Qty, line.NetRevenue, taxable, tax)
+	})
+}
+
+// splitTax returns the taxable value and the tax of an amount including the tax.
+func (ru *reportUseCase) splitTax(amount float32) (float32, float32) {
+	paise := helper.ToPaise(amount)
+	taxable := int64(math.Round(float64(paise) * 100 / (100 + ru.taxRate)))
+	return helper.FromPaise(taxable), helper.FromPaise(paise - taxable)
+}
+
+func (ru *reportUseCase) writeRefundReport(ctx context.Context, export request.SalesExport, writer helper.ReportWriter) error {
+	summary, err := ru.reportRepo.GetRefundSummary(ctx, export.From, export.To)
+	if err != nil {
+		return fmt.Errorf("Failed to get refund summary :%s", err)
+	}
+
+	if err := writer.Sheet("Summary", []string{"Status", "Payment method", "Refunds", "Amount"}); err != nil {
+		return err
+	}
+	for _, row := range summary {
+		if err := writer.Row(row.Status, row.PaymentMethod, row.Refunds, row.Amount); err != nil {
+			return err
+		}
+	}
+
+	columns := []string{"Payment ID", "Order ID", "Refunded at", "Status", "User ID", "Customer", "Product", "Payment method", "Amount"}
+	if err := writer.Sheet("Refunds", columns); err != nil {
+		return err
+	}
+	afterID := 0
+	for {
+		refunds, err := ru.reportRepo.GetRefundLines(ctx, export.From, export.To, afterID, salesExportBatch)
+		if err != nil {
+			return fmt.Errorf("Failed to get refunds :%s", err)
+		}
+
+		for _, refund := range refunds {
+			if err := writer.Row(refund.PaymentID, refund.OrderID, refund.RefundedAt, refund.Status, refund.UserID,
+				refund.Customer, refund.ProductName, refund.PaymentMethod, refund.Amount); err != nil {
+				return err
+			}
+			afterID = int(refund.PaymentID)
+		}
+		if len(refunds) < salesExportBatch {
+			return nil
+		}
+	}
+}
+
+// eachSalesLine calls fn with the order lines of the period, read in batches.
+func (ru *reportUseCase) eachSalesLine(ctx context.Context, export request.SalesExport, fn func(response.SalesLine) error) error {
+	afterID := 0
+	for {
+		lines, err := ru.reportRepo.GetSalesLines(ctx, export.From, export.To, float32(loyaltyPointValue), afterID, salesExportBatch)
+		if err != nil {
+			return fmt.Errorf("Failed to get order lines :%s", err)
+		}
+
+		for _, line := range lines {
+			if err := fn(line); err != nil {
+				return err
+			}
+			afterID = int(line.OrderID)
+		}
+		if len(lines) < salesExportBatch {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
+	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+var (
+	ErrInvalidTwoFactorCode = errors.New("invalid two factor code")
+	ErrTwoFactorEnabled     = errors.New("two factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two factor authentication is not enabled")
+	ErrTwoFactorRequired    = errors.New("two factor authentication is required for the admin")
+)
+
+const (
+	// AdminSubject is the two factor subject of the admin, there is one admin account.
+	AdminSubject = "admin"
+
+	userSubjectPrefix = "user:"
+	recoveryCodeCount = 10
+)
+
+// UserSubject is the two factor subject of the user.
+func UserSubject(userID int) string {
+	return userSubjectPrefix + strconv.Itoa(userID)
+}
+
+// subjectUserID returns the user id of a user subject.
+func subjectUserID(subject string) (int, bool) {
+	if !strings.HasPrefix(subject, userSubjectPrefix) {
+		return 0, false
+	}
+	userID, err := strconv.Atoi(strings.TrimPrefix(subject, userSubjectPrefix))
+	return userID, err == nil
+}
+
+type twoFactorUseCase struct {
+	twoFactorRepo  interfaces.TwoFactorRepository
+	userRepo       interfaces.UserRepository
+	lockoutUseCase services.LockoutUseCase
+}
+
+func NewTwoFactorUseCase(twoFactorRepo interfaces.TwoFactorRepository, userRepo interfaces.UserRepository, lockoutUseCase services.LockoutUseCase) services.TwoFactorUseCase {
+	return &twoFactorUseCase{
+		twoFactorRepo:  twoFactorRepo,
+		userRepo:       userRepo,
+		lockoutUseCase: lockoutUseCase,
+	}
+}
+
+func (tu *twoFactorUseCase) GetStatus(ctx context.Context, subject string) (response.TwoFactorStatus, error) {
+	twoFactor, err := tu.twoFactorRepo.FindTwoFactor(ctx, subject)
+	if err != nil {
+		return response.TwoFactorStatus{}, fmt.Errorf("Failed to find two factor :%s", err)
+	}
+	if twoFactor.ID == 0 || !twoFactor.Enabled {
+		return response.TwoFactorStatus{}, nil
+	}
+
+	left, err := tu.twoFactorRepo.CountUnusedRecoveryCodes(ctx, twoFactor.ID)
+	if err != nil {
+		return response.TwoFactorStatus{}, fmt.Errorf("Failed to count recovery codes :%s", err)
+	}
+
+	return response.TwoFactorStatus{
+		Enabled:           true,
+		EnabledAt:         twoFactor.EnabledAt,
+		RecoveryCodesLeft: left,
+	}, nil
+}
+
+func (tu *twoFactorUseCase) IsEnabled(ctx context.Context, subject string) (bool, error) {
+	twoFactor, err := tu.twoFactorRepo.FindTwoFactor(ctx, subject)
+	if err != nil {
+		return false, fmt.Errorf("Failed to find two factor :%s", err)
+	}
+	return twoFactor.Enabled, nil
+}
+
+// BeginEnrolment saves a new secret for the subject, it is enabled by the first
+// code of the app.
+func (tu *twoFactorUseCase) BeginEnrolment(ctx context.Context, subject string) (response.TwoFactorEnrolment, error) {
+	twoFactor, err := tu.twoFactorRepo.FindTwoFactor(ctx, subject)
+	if err != nil {
+		return response.TwoFactorEnrolment{}, fmt.Errorf("Failed to find two factor :%s", err)
+	}
+	if twoFactor.Enabled {
+		return response.TwoFactorEnrolment{}, ErrTwoFactorEnabled
+	}
+
+	accountName, err := tu.accountName(ctx, subject)
+	if err != nil {
+		return response.TwoFactorEnrolment{}, err
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return response.TwoFactorEnrolment{}, err
+	}
+	encrypted, err := helper.EncryptSecret(secret)
+	if err != nil {
+		return response.TwoFactorEnrolment{}, fmt.Errorf("Failed to encrypt secret :%s", err)
+	}
+
+	twoFactor, err = tu.twoFactorRepo.UpsertPendingTwoFactor(ctx, subject, encrypted, time.Now())
+	if err != nil {
+		return response.TwoFactorEnrolment{}, fmt.Errorf("Failed to save two factor :%s", err)
+	}
+	if twoFactor.ID == 0 {
+		return response.TwoFactorEnrolment{}, fmt.Errorf("Failed to verify saved two factor")
+	}
+
+	return response.TwoFactorEnrolment{
+		Secret:          secret,
+		ProvisioningURI: helper.TOTPProvisioningURI(accountName, secret),
+	}, nil
+}
+
+// accountName labels the account in the authenticator app.
+func (tu *twoFactorUseCase) accountName(ctx context.Context, subject string) (string, error) {
+	userID, ok := subjectUserID(subject)
+	if !ok {
+		return subject, nil
+	}
+
+	userData, err := tu.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("Failed to find user :%s", err)
+	}
+	if userData.ID == 0 {
+		return "", ErrNoRecord
+	}
+	return userData.Email, nil
+}
+
+// Enable completes the pending enrolment with the first code of the app and returns
+// the recovery codes, they are shown only this once.
+func (tu *twoFactorUseCase) Enable(ctx context.Context, subject, code, ip string) ([]string, error) {
+	twoFactor, err := tu.twoFactorRepo.FindTwoFactor(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find two factor :%s", err)
+	}
+	if twoFactor.ID == 0 {
+		return nil, ErrNoRecord
+	}
+	if twoFactor.Enabled {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	err = tu.guard(ctx, subject, ip, func() (bool, error) {
+		return tu.checkTOTP(ctx, twoFactor, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tu.twoFactorRepo.EnableTwoFactor(ctx, twoFactor.ID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to enable two factor :%s", err)
+	}
+	return tu.newRecoveryCodes(ctx, twoFactor.ID)
+}
+
+// Verify checks the totp code or a recovery code of the enabled subject, repeated
+// failures are throttled like the password logins.
+func (tu *twoFactorUseCase) Verify(ctx context.Context, subject, code, ip string) error {
+	twoFactor, err := tu.twoFactorRepo.FindTwoFactor(ctx, subject)
+	if err != nil {
+		return fmt.Errorf("Failed to find two factor :%s", err)
+	}
+	if !twoFactor.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	return tu.guard(ctx, subject, ip, func() (bool, error) {
+		ok, err := tu.checkTOTP(ctx, twoFactor, code)
+		if ok || err != nil {
+			return ok, err
+		}
+		return tu.twoFactorRepo.UseRecoveryCode(ctx, twoFactor.ID, helper.HashRecoveryCode(code), time.Now())
+	})
+}
+
+func (tu *twoFactorUseCase) Disable(ctx context.Context, subject, code, ip string) error {
+	if subject == AdminSubject {
+		return ErrTwoFactorRequired
+	}
+
+	if err := tu.Verify(ctx, subject, code, ip); err != nil {
+		return err
+	}
+
+	twoFactor, err := tu.twoFactorRepo.FindTwoFactor(ctx, subject)
+	if err != nil {
+		return fmt.Errorf("Failed to find two factor :%s", err)
+	}
+	err = tu.twoFactorRepo.DeleteTwoFactor(ctx, twoFactor.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to disable two factor :%s", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all the recovery codes of the subject.
+func (tu *twoFactorUseCase) RegenerateRecoveryCodes(ctx context.Context, subject, code, ip string) ([]string, error) {
+	if err := tu.Verify(ctx, subject, code, ip); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := tu.twoFactorRepo.FindTwoFactor(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find two factor :%s", err)
+	}
+	return tu.newRecoveryCodes(ctx, twoFactor.ID)
+}
+
+// guard runs the code check with the lockout of the subject and the ip.
+func (tu *twoFactorUseCase) guard(ctx context.Context, subject, ip string, check func() (bool, error)) error {
+	accountKey := lockKey("2fa", subject)
+	if err := tu.lockoutUseCase.ReserveLogin(ctx, accountKey, ip); err != nil {
+		return err
+	}
+
+	ok, err := check()
+	if err != nil {
+		return fmt.Errorf("Failed to verify two factor code :%s", err)
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return tu.lockoutUseCase.LoginSucceeded(ctx, accountKey, ip)
+}
+
+// checkTOTP validates the code and spends its time step, a code is accepted once.
+func (tu *twoFactorUseCase) checkTOTP(ctx context.Context, twoFactor response.TwoFactor, code string) (bool, error) {
+	secret, err := helper.DecryptSecret(twoFactor.Secret)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := helper.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return tu.twoFactorRepo.UseTimeStep(ctx, twoFactor.ID, step)
+}
+
+func (tu *twoFactorUseCase) newRecoveryCodes(ctx context.Context, twoFactorID int) ([]string, error) {
+	codes, err := helper.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, helper.HashRecoveryCode(code))
+	}
+
+	err = tu.twoFactorRepo.ReplaceRecoveryCodes(ctx, twoFactorID, hashes, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to save recovery codes :%s", err)
+	}
+	return codes, nil
+}
@@ -1,9 +1,9 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 
 	interfaces "github.com/anazibinurasheed/project-device-mart/pkg/repo/interface"
 	services "github.com/anazibinurasheed/project-device-mart/pkg/usecase/interface"
@@ -19,25 +19,27 @@ var (
 )
 
 type userUseCase struct {
-	userRepo interfaces.UserRepository
+	userRepo       interfaces.UserRepository
+	passwordPolicy *helper.PasswordPolicy
 }
 
-func NewUserUseCase(repo interfaces.UserRepository) services.UserUseCase {
+func NewUserUseCase(repo interfaces.UserRepository, passwordPolicy *helper.PasswordPolicy) services.UserUseCase {
 	return &userUseCase{
-		userRepo: repo,
+		userRepo:       repo,
+		passwordPolicy: passwordPolicy,
 	}
 }
 
-func (u *userUseCase) FindUserById(userID int) (response.UserData, error) {
-	userData, err := u.userRepo.FindUserByID(userID)
+func (u *userUseCase) FindUserById(ctx context.Context, userID int) (response.UserData, error) {
+	userData, err := u.userRepo.FindUserByID(ctx, userID)
 	if err != nil {
 		return response.UserData{}, err
 	}
 	return userData, nil
 }
 
-func (u *userUseCase) ReadAllCategories() ([]response.Category, error) {
-	listOfAllCategories, err := u.userRepo.ReadCategories()
+func (u *userUseCase) ReadAllCategories(ctx context.Context) ([]response.Category, error) {
+	listOfAllCategories, err := u.userRepo.ReadCategories(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +47,8 @@ func (u *userUseCase) ReadAllCategories() ([]response.Category, error) {
 
 }
 
-func (u *userUseCase) DisplayListOfStates() ([]response.States, error) {
-	listOfStates, err := u.userRepo.GetListOfStates()
+func (u *userUseCase) DisplayListOfStates(ctx context.Context) ([]response.States, error) {
+	listOfStates, err := u.userRepo.GetListOfStates(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -57,8 +59,8 @@ func (u *userUseCase) DisplayListOfStates() ([]response.States, error) {
 	return listOfStates, nil
 }
 
-func (u *userUseCase) AddNewAddress(userID int, address request.Address) error {
-	createdAddress, err := u.userRepo.AddAddress(userID, address)
+func (u *userUseCase) AddNewAddress(ctx context.Context, userID int, address request.Address) error {
+	createdAddress, err := u.userRepo.AddAddress(ctx, userID, address)
 	if err != nil {
 		return fmt.Errorf("Failed to add address to db :%s", err)
 	}
@@ -68,14 +70,14 @@ func (u *userUseCase) AddNewAddress(userID int, address request.Address) error {
 
 	}
 
-	defaultAddress, err := u.userRepo.FindDefaultAddress(userID)
+	defaultAddress, err := u.userRepo.FindDefaultAddress(ctx, userID)
 	if err != nil {
 		return err
 	}
 
 	if defaultAddress.ID == 0 {
 
-		setDefaultAddress, err := u.userRepo.SetDefaultAddressStatus(true, int(createdAddress.ID), userID)
+		setDefaultAddress, err := u.userRepo.SetDefaultAddressStatus(ctx, true, int(createdAddress.ID), userID)
 		if err != nil {
 			return fmt.Errorf("Failed to set default address :%s", err)
 		}
@@ -87,8 +89,8 @@ func (u *userUseCase) AddNewAddress(userID int, address request.Address) error {
 	return nil
 }
 
-func (u *userUseCase) FindDefaultAddress(userID int) (response.Address, error) {
-	defaultAddress, err := u.userRepo.FindDefaultAddress(userID)
+func (u *userUseCase) FindDefaultAddress(ctx context.Context, userID int) (response.Address, error) {
+	defaultAddress, err := u.userRepo.FindDefaultAddress(ctx, userID)
 
 	if err != nil {
 		return response.Address{}, fmt.Errorf("Failed to find default address :%s ", err)
@@ -100,8 +102,8 @@ func (u *userUseCase) FindDefaultAddress(userID int) (response.Address, error) {
 	return defaultAddress, nil
 }
 
-func (u *userUseCase) UpdateUserAddress(address request.Address, addressID int, userID int) error {
-	updatedAddress, err := u.userRepo.UpdateAddress(address, addressID, userID)
+func (u *userUseCase) UpdateUserAddress(ctx context.Context, address request.Address, addressID int, userID int) error {
+	updatedAddress, err := u.userRepo.UpdateAddress(ctx, address, addressID, userID)
 
 	if err != nil {
 		return err
@@ -113,8 +115,8 @@ func (u *userUseCase) UpdateUserAddress(address request.Address, addressID int,
 
 }
 
-func (u *userUseCase) GetUserAddresses(userID int) ([]response.Address, error) {
-	listOfAddresses, err := u.userRepo.GetAllUserAddresses(userID)
+func (u *userUseCase) GetUserAddresses(ctx context.Context, userID int) ([]response.Address, error) {
+	listOfAddresses, err := u.userRepo.GetAllUserAddresses(ctx, userID)
 
 	if err != nil {
 		return nil, err
@@ -122,8 +124,8 @@ func (u *userUseCase) GetUserAddresses(userID int) ([]response.Address, error) {
 	return listOfAddresses, nil
 }
 
-func (u *userUseCase) DeleteUserAddress(addressID int) error {
-	deletedAddress, err := u.userRepo.DeleteAddress(addressID)
+func (u *userUseCase) DeleteUserAddress(ctx context.Context, addressID int) error {
+	deletedAddress, err := u.userRepo.DeleteAddress(ctx, addressID)
 	if err != nil {
 		return err
 	}
@@ -133,12 +135,12 @@ func (u *userUseCase) DeleteUserAddress(addressID int) error {
 
 	userID := deletedAddress.UserID
 
-	userAddress, err := u.userRepo.FindUserAddress(int(userID))
+	userAddress, err := u.userRepo.FindUserAddress(ctx, int(userID))
 	if err != nil {
 		return fmt.Errorf("Failed to set default address %s", err)
 	}
 	if userAddress.ID != 0 {
-		_, err := u.userRepo.SetDefaultAddressStatus(true, int(userAddress.ID), int(userAddress.UserID))
+		_, err := u.userRepo.SetDefaultAddressStatus(ctx, true, int(userAddress.ID), int(userAddress.UserID))
 		if err != nil {
 			return fmt.Errorf("Failed to update default address :%s", err)
 		}
@@ -146,8 +148,8 @@ func (u *userUseCase) DeleteUserAddress(addressID int) error {
 	return nil
 }
 
-func (u *userUseCase) SetDefaultAddress(userID, addressID int) error {
-	defaultAddress, err := u.userRepo.FindDefaultAddress(userID)
+func (u *userUseCase) SetDefaultAddress(ctx context.Context, userID, addressID int) error {
+	defaultAddress, err := u.userRepo.FindDefaultAddress(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to find default address :%s", err)
 	}
@@ -156,20 +158,20 @@ func (u *userUseCase) SetDefaultAddress(userID, addressID int) error {
 		return ErrNoAddress
 	}
 	
-	address, err := u.userRepo.SetDefaultAddressStatus(false, int(defaultAddress.ID), userID)
+	address, err := u.userRepo.SetDefaultAddressStatus(ctx, false, int(defaultAddress.ID), userID)
 	if address.ID == 0 || address.IsDefault || err != nil {
 		return fmt.Errorf("Failed to change default address : %s", err)
 	}
 
-	newDefaultAddress, err := u.userRepo.SetDefaultAddressStatus(true, addressID, userID)
+	newDefaultAddress, err := u.userRepo.SetDefaultAddressStatus(ctx, true, addressID, userID)
 	if err != nil || !newDefaultAddress.IsDefault {
 		return fmt.Errorf("Failed to set  address to default : %s", err)
 	}
 	return nil
 }
 
-func (u *userUseCase) GetProfile(userID int) (response.Profile, error) {
-	userData, err := u.userRepo.FindUserByID(userID)
+func (u *userUseCase) GetProfile(ctx context.Context, userID int) (response.Profile, error) {
+	userData, err := u.userRepo.FindUserByID(ctx, userID)
 	if err != nil {
 		return response.Profile{}, fmt.Errorf("Failed to find user : %s", err)
 	}
@@ -178,7 +180,7 @@ func (u *userUseCase) GetProfile(userID int) (response.Profile, error) {
 		return response.Profile{}, fmt.Errorf("User not found")
 	}
 
-	userAddresses, err := u.userRepo.GetAllUserAddresses(userID)
+	userAddresses, err := u.userRepo.GetAllUserAddresses(ctx, userID)
 	if err != nil {
 		return response.Profile{}, fmt.Errorf("Failed to find user addresses :%s", err)
 	}
@@ -192,8 +194,8 @@ func (u *userUseCase) GetProfile(userID int) (response.Profile, error) {
 	}, nil
 }
 
-func (u *userUseCase) ForgotPassword(userID int, c *gin.Context) error {
-	userData, err := u.userRepo.FindUserByID(userID)
+func (u *userUseCase) ForgotPassword(ctx context.Context, userID int, c *gin.Context) error {
+	userData, err := u.userRepo.FindUserByID(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -212,26 +214,29 @@ func (u *userUseCase) ForgotPassword(userID int, c *gin.Context) error {
 	return nil
 }
 
-func (u *userUseCase) ChangeUserPassword(password request.ChangePassword, userID int, c *gin.Context) error {
+func (u *userUseCase) ChangeUserPassword(ctx context.Context, password request.ChangePassword, userID int, c *gin.Context) error {
 	if password.NewPassword != password.ReNewPassword {
 		return fmt.Errorf("Password is not matching")
 	}
+	if err := checkPassword(u.passwordPolicy, password.NewPassword); err != nil {
+		return err
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password.NewPassword), 10)
 	if err != nil {
-		log.Println("FAILED TO HASH PASSWORD", err)
+		helper.LoggerFrom(ctx).Error("password not hashed", "error", err)
 		return fmt.Errorf("Unable to process the request ")
 	}
 
-	err = u.userRepo.ChangePassword(userID, string(hashedPassword))
+	err = u.userRepo.ChangePassword(ctx, userID, string(hashedPassword))
 	if err != nil {
 		return fmt.Errorf("Failed to change password :%s", err)
 	}
 	return nil
 }
 
-func (u *userUseCase) CheckUserOldPassword(password request.OldPassword, userID int) error {
-	userData, err := u.userRepo.FindUserByID(userID)
+func (u *userUseCase) CheckUserOldPassword(ctx context.Context, password request.OldPassword, userID int) error {
+	userData, err := u.userRepo.FindUserByID(ctx, userID)
 
 	err = bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(password.Password))
 	if err != nil {
@@ -241,8 +246,8 @@ func (u *userUseCase) CheckUserOldPassword(password request.OldPassword, userID
 	return nil
 }
 
-func (u *userUseCase) UpdateUserName(username string, userID int) error {
-	userData, err := u.userRepo.UpdateUserName(username, userID)
+func (u *userUseCase) UpdateUserName(ctx context.Context, username string, userID int) error {
+	userData, err := u.userRepo.UpdateUserName(ctx, username, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to update username : %s", err)
 	}
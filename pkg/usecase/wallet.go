@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -27,8 +28,8 @@ func NewWalletUseCase(walletRepo interfaces.WalletRepository,
 	}
 }
 
-func (ou *walletUseCase) GetWalletHistory(userID int) ([]response.WalletTransactionHistory, error) {
-	walletHistory, err := ou.orderRepo.GetWalletHistoryByUserID(userID)
+func (ou *walletUseCase) GetWalletHistory(ctx context.Context, userID int) ([]response.WalletTransactionHistory, error) {
+	walletHistory, err := ou.orderRepo.GetWalletHistoryByUserID(ctx, userID)
 	if err != nil {
 		return walletHistory, err
 	}
@@ -36,8 +37,8 @@ func (ou *walletUseCase) GetWalletHistory(userID int) ([]response.WalletTransact
 	return walletHistory, nil
 }
 
-func (ou *walletUseCase) GetUserWallet(userID int) (response.Wallet, error) {
-	wallet, err := ou.orderRepo.FindUserWalletByID(userID)
+func (ou *walletUseCase) GetUserWallet(ctx context.Context, userID int) (response.Wallet, error) {
+	wallet, err := ou.orderRepo.FindUserWalletByID(ctx, userID)
 	if err != nil {
 		return response.Wallet{}, fmt.Errorf("Failed to get user wallet :%s", err)
 	}
@@ -47,9 +48,9 @@ func (ou *walletUseCase) GetUserWallet(userID int) (response.Wallet, error) {
 	return wallet, nil
 }
 
-func (ou *walletUseCase) CreateUserWallet(userID int) error {
+func (ou *walletUseCase) CreateUserWallet(ctx context.Context, userID int) error {
 
-	wallet, err := ou.orderRepo.FindUserWalletByID(userID)
+	wallet, err := ou.orderRepo.FindUserWalletByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to check user wallet :%s", err)
 	}
@@ -58,7 +59,7 @@ func (ou *walletUseCase) CreateUserWallet(userID int) error {
 		return fmt.Errorf("User already have a wallet")
 	}
 
-	newWallet, err := ou.orderRepo.InitializeNewUserWallet(userID)
+	newWallet, err := ou.orderRepo.InitializeNewUserWallet(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to initialize wallet for user %d : %s", userID, err)
 	}
@@ -68,8 +69,39 @@ func (ou *walletUseCase) CreateUserWallet(userID int) error {
 	return nil
 }
 
-func (ou *walletUseCase) ValidateWalletPayment(userID int) error {
-	wallet, err := ou.orderRepo.FindUserWalletByID(userID)
+// ApplyWallet uses the wallet for part of the cart, the rest is paid online or on delivery.
+func (ou *walletUseCase) ApplyWallet(ctx context.Context, userID int, amount float32) error {
+	wallet, err := ou.orderRepo.FindUserWalletByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to find user wallet : %s", err)
+	}
+	if wallet.ID == 0 {
+		return ErrNoWallet
+	}
+	if wallet.Amount <= 0 || amount > wallet.Amount {
+		return fmt.Errorf("Insufficient balance")
+	}
+
+	applied, err := ou.walletRepo.SetAppliedWallet(ctx, userID, amount)
+	if err != nil {
+		return fmt.Errorf("Failed to apply wallet :%s", err)
+	}
+	if applied.ID == 0 {
+		return fmt.Errorf("Failed to verify applied wallet")
+	}
+	return nil
+}
+
+func (ou *walletUseCase) RemoveAppliedWallet(ctx context.Context, userID int) error {
+	err := ou.walletRepo.RemoveAppliedWallet(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to remove applied wallet :%s", err)
+	}
+	return nil
+}
+
+func (ou *walletUseCase) ValidateWalletPayment(ctx context.Context, userID int) error {
+	wallet, err := ou.orderRepo.FindUserWalletByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to find user wallet : %s", err)
 	}
@@ -77,19 +109,19 @@ func (ou *walletUseCase) ValidateWalletPayment(userID int) error {
 		return fmt.Errorf("User don't have a wallet ")
 	}
 
-	userCart, err := ou.cartUseCase.ViewCart(userID)
+	userCart, err := ou.cartUseCase.ViewCart(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("Failed to fetch user cart : %s", err)
 	}
 	if userCart.Total > wallet.Amount {
-		return fmt.Errorf("Insufficient balance")
+		return fmt.Errorf("Insufficient balance, apply the wallet to pay the rest online or on delivery")
 	}
 	return nil
 }
 
-func (ou *walletUseCase) UpdateWalletHistory(userID int, amount float32, transactionType string) error {
+func (ou *walletUseCase) UpdateWalletHistory(ctx context.Context, userID int, amount float32, transactionType string) error {
 
-	walletHistory, err := ou.orderRepo.UpdateWalletTransactionHistory(
+	walletHistory, err := ou.orderRepo.UpdateWalletTransactionHistory(ctx,
 		request.WalletTransactionHistory{
 			TransactionTime: time.Now(),
 			UserID:          userID,
@@ -110,29 +142,25 @@ func (ou *walletUseCase) UpdateWalletHistory(userID int, amount float32, transac
 	return nil
 }
 
-func (ou *walletUseCase) UpdateWallet(userID int, amount float32, transactionType string) error {
-	wallet, err := ou.orderRepo.FindUserWalletByID(userID)
-	if err != nil {
-		return err
-	}
-
-	var updtAmount float32
-
-	if transactionType == credit {
-		updtAmount = (wallet.Amount + amount)
-
-	}
-
+func (ou *walletUseCase) UpdateWallet(ctx context.Context, userID int, amount float32, transactionType string) error {
+	change := amount
 	if transactionType == debit {
-		updtAmount = (wallet.Amount - amount)
-
+		change = -amount
 	}
 
-	wallet, err = ou.orderRepo.UpdateUserWalletBalance(userID, updtAmount)
+	// the balance is checked and changed in one statement, concurrent debits
+	// cannot take the wallet below zero
+	wallet, err := ou.orderRepo.AdjustUserWalletBalance(ctx, userID, change)
 	if err != nil {
 		return err
 	}
-	err = ou.UpdateWalletHistory(userID, amount, transactionType)
+	if wallet.ID == 0 {
+		if transactionType == debit {
+			return ErrInsufficientBalance
+		}
+		return ErrNoWallet
+	}
+	err = ou.UpdateWalletHistory(ctx, userID, amount, transactionType)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,201 @@
+package helper
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+const (
+	CouponPercent      = "percent"
+	CouponFlat         = "flat"
+	CouponBuyXGetY     = "buy_x_get_y"
+	CouponFreeShipping = "free_shipping"
+)
+
+const (
+	EligibleCategory = "category"
+	EligibleProduct  = "product"
+	EligibleBrand    = "brand"
+)
+
+const (
+	SegmentNew       = "new"
+	SegmentReturning = "returning"
+	SegmentHighValue = "high_value"
+)
+
+// HighValueSpend is the lifetime spend from which a user counts as high value.
+const HighValueSpend = 50000
+
+// CouponUser is what the engine needs to know about the user and the coupon usage.
+type CouponUser struct {
+	OrderCount    int
+	LifetimeSpend float64
+	TimesUsed     int // redemptions of this coupon by the user
+	TotalUsed     int // redemptions of this coupon by everyone
+}
+
+// EvaluateCoupon runs every rule of the coupon against the cart and reports
+// the discount along with the result of each check.
+func EvaluateCoupon(coupon response.Coupon, eligibility []response.CouponEligibility, cart []response.Cart, user CouponUser) response.CouponEvaluation {
+	evaluation := response.CouponEvaluation{
+		CouponID:    coupon.ID,
+		Code:        coupon.Code,
+		RuleType:    coupon.RuleType,
+		IsStackable: coupon.IsStackable,
+		Applicable:  true,
+	}
+	if evaluation.RuleType == "" {
+		evaluation.RuleType = CouponPercent
+	}
+
+	check := func(rule string, passed bool, detail string) {
+		evaluation.Checks = append(evaluation.Checks, response.CouponCheck{Rule: rule, Passed: passed, Detail: detail})
+		if !passed {
+			evaluation.Applicable = false
+		}
+	}
+
+	now := time.Now()
+	switch {
+	case coupon.IsBlocked:
+		check("active", false, "coupon is blocked")
+	case now.Before(coupon.ValidFrom):
+		check("active", false, "coupon is valid from "+coupon.ValidFrom.Format("02-01-2006"))
+	case !IsCouponValid(coupon.ValidTill):
+		check("active", false, "coupon expired on "+coupon.ValidTill.Format("02-01-2006"))
+	default:
+		check("active", true, "coupon is valid till "+coupon.ValidTill.Format("02-01-2006"))
+	}
+
+	if coupon.UsageLimit > 0 {
+		check("usage_limit", user.TotalUsed < coupon.UsageLimit,
+			fmt.Sprintf("used %d of %d times", user.TotalUsed, coupon.UsageLimit))
+	}
+	if coupon.PerUserLimit > 0 {
+		check("per_user_limit", user.TimesUsed < coupon.PerUserLimit,
+			fmt.Sprintf("you used it %d of %d times", user.TimesUsed, coupon.PerUserLimit))
+	}
+
+	if coupon.FirstOrderOnly {
+		check("first_order", user.OrderCount == 0, fmt.Sprintf("you have %d previous orders", user.OrderCount))
+	}
+
+	switch coupon.UserSegment {
+	case SegmentNew:
+		check("user_segment", user.OrderCount == 0, "only for new customers")
+	case SegmentReturning:
+		check("user_segment", user.OrderCount > 0, "only for returning customers")
+	case SegmentHighValue:
+		check("user_segment", user.LifetimeSpend >= HighValueSpend,
+			fmt.Sprintf("only for customers who spent at least %d", HighValueSpend))
+	}
+
+	var subtotal float32
+	var eligibleUnits []float32
+	for _, item := range cart {
+		lineTotal := float32(item.Price) * float32(item.Qty)
+		subtotal += lineTotal
+		if !IsCartItemEligible(item, eligibility) {
+			continue
+		}
+		evaluation.EligibleSubtotal += lineTotal
+		for i := 0; i < item.Qty; i++ {
+			eligibleUnits = append(eligibleUnits, float32(item.Price))
+		}
+	}
+
+	check("min_order_value", subtotal >= float32(coupon.MinOrderValue),
+		fmt.Sprintf("cart subtotal %.2f, required %.2f", subtotal, coupon.MinOrderValue))
+
+	if len(eligibility) > 0 {
+		check("eligible_items", len(eligibleUnits) > 0, describeEligibility(eligibility))
+	}
+
+	var discount float32
+	switch evaluation.RuleType {
+	case CouponPercent:
+		discount = evaluation.EligibleSubtotal * float32(coupon.DiscountPercent) / 100
+	case CouponFlat:
+		discount = float32(coupon.DiscountAmount)
+	case CouponBuyXGetY:
+		groupSize := coupon.BuyQuantity + coupon.GetQuantity
+		if coupon.GetQuantity == 0 || len(eligibleUnits) < groupSize {
+			check("buy_x_get_y", false, fmt.Sprintf("add %d eligible items to get %d free", groupSize, coupon.GetQuantity))
+			break
+		}
+		// the cheapest units of every complete group are free
+		sort.Slice(eligibleUnits, func(i, j int) bool { return eligibleUnits[i] < eligibleUnits[j] })
+		freeUnits := (len(eligibleUnits) / groupSize) * coupon.GetQuantity
+		for _, price := range eligibleUnits[:freeUnits] {
+			discount += price
+		}
+		check("buy_x_get_y", true, fmt.Sprintf("%d items free", freeUnits))
+	case CouponFreeShipping:
+		evaluation.FreeShipping = evaluation.Applicable
+	}
+
+	if coupon.DiscountMaxAmount > 0 && discount > float32(coupon.DiscountMaxAmount) {
+		discount = float32(coupon.DiscountMaxAmount)
+	}
+	if discount > evaluation.EligibleSubtotal {
+		discount = evaluation.EligibleSubtotal
+	}
+
+	if !evaluation.Applicable {
+		return evaluation
+	}
+
+	// spread the discount over the eligible lines so it can be stored per order line
+	weights := make([]int64, len(cart))
+	for i, item := range cart {
+		if IsCartItemEligible(item, eligibility) {
+			weights[i] = ToPaise(float32(item.Price) * float32(item.Qty))
+		}
+	}
+	shares := ApportionDiscount(ToPaise(discount), weights)
+	for i, item := range cart {
+		evaluation.Lines = append(evaluation.Lines, response.CouponLineDiscount{ProductID: item.ProductID, Discount: FromPaise(shares[i])})
+		evaluation.Discount += FromPaise(shares[i])
+	}
+
+	return evaluation
+}
+
+// IsCartItemEligible reports whether the coupon eligibility covers the cart item.
+// Without any eligibility rows every item is eligible.
+func IsCartItemEligible(item response.Cart, eligibility []response.CouponEligibility) bool {
+	if len(eligibility) == 0 {
+		return true
+	}
+	for _, e := range eligibility {
+		switch e.Scope {
+		case EligibleCategory:
+			if e.Value == strconv.Itoa(int(item.CategoryID)) {
+				return true
+			}
+		case EligibleProduct:
+			if e.Value == strconv.Itoa(int(item.ProductID)) {
+				return true
+			}
+		case EligibleBrand:
+			if strings.EqualFold(e.Value, item.Brand) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describeEligibility(eligibility []response.CouponEligibility) string {
+	scopes := make([]string, 0, len(eligibility))
+	for _, e := range eligibility {
+		scopes = append(scopes, e.Scope+" "+e.Value)
+	}
+	return "applies only to " + strings.Join(scopes, ", ")
+}
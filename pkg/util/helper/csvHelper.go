@@ -0,0 +1,12 @@
+package helper
+
+import "strings"
+
+// CSVSafe keeps a spreadsheet from reading the cell as a formula, cells starting
+// with = + - @ or a control character get a leading quote.
+func CSVSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,61 @@
+package helper
+
+import (
+	"math"
+	"sort"
+)
+
+// ApportionDiscount splits a discount in paise across lines in proportion to
+// their weights using the largest remainder method, so the shares always add
+// up to the discount and no share is bigger than its own weight.
+func ApportionDiscount(discount int64, weights []int64) []int64 {
+	shares := make([]int64, len(weights))
+
+	var total int64
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	if discount <= 0 || total == 0 {
+		return shares
+	}
+	if discount > total {
+		discount = total
+	}
+
+	type remainder struct {
+		index int
+		value int64
+	}
+	remainders := make([]remainder, 0, len(weights))
+
+	var allocated int64
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		product := discount * w
+		shares[i] = product / total
+		allocated += shares[i]
+		remainders = append(remainders, remainder{index: i, value: product % total})
+	}
+
+	sort.SliceStable(remainders, func(i, j int) bool { return remainders[i].value > remainders[j].value })
+	for i := 0; allocated < discount; i++ {
+		shares[remainders[i].index]++
+		allocated++
+	}
+
+	return shares
+}
+
+// ToPaise converts a rupee amount to paise.
+func ToPaise(amount float32) int64 {
+	return int64(math.Round(float64(amount) * 100))
+}
+
+// FromPaise converts paise back to a rupee amount.
+func FromPaise(paise int64) float32 {
+	return float32(paise) / 100
+}
@@ -0,0 +1,108 @@
+package helper
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// apportionInput keeps the generated values in a realistic paise range.
+func apportionInput(discount uint32, weights []uint32) (int64, []int64) {
+	w := make([]int64, len(weights))
+	for i, v := range weights {
+		w[i] = int64(v % 10000000)
+	}
+	return int64(discount % 100000000), w
+}
+
+func TestApportionDiscountSumsToDiscount(t *testing.T) {
+	property := func(d uint32, ws []uint32) bool {
+		discount, weights := apportionInput(d, ws)
+
+		var total, sum int64
+		for _, w := range weights {
+			total += w
+		}
+		for _, share := range ApportionDiscount(discount, weights) {
+			sum += share
+		}
+
+		if discount > total {
+			return sum == total
+		}
+		return sum == discount
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApportionDiscountSharesWithinLine(t *testing.T) {
+	property := func(d uint32, ws []uint32) bool {
+		discount, weights := apportionInput(d, ws)
+
+		shares := ApportionDiscount(discount, weights)
+		if len(shares) != len(weights) {
+			return false
+		}
+		for i, share := range shares {
+			if share < 0 || share > weights[i] {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApportionDiscountProportional(t *testing.T) {
+	// every share is within one paisa of its exact proportional value
+	property := func(d uint32, ws []uint32) bool {
+		discount, weights := apportionInput(d, ws)
+
+		var total int64
+		for _, w := range weights {
+			total += w
+		}
+		if total == 0 || discount > total {
+			return true
+		}
+		for i, share := range ApportionDiscount(discount, weights) {
+			exact := float64(discount) * float64(weights[i]) / float64(total)
+			if float64(share) < exact-1 || float64(share) > exact+1 {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApportionDiscountPartialCancellation(t *testing.T) {
+	// splitting a line discount between cancelled and kept units never loses a paisa
+	property := func(d uint32, price uint16, qty, cancel uint8) bool {
+		q := int64(qty%20) + 1
+		c := int64(cancel) % q
+		lineTotal := int64(price) * 100 * q
+		discount := int64(d) % (lineTotal + 1)
+
+		shares := ApportionDiscount(discount, []int64{int64(price) * 100 * c, int64(price) * 100 * (q - c)})
+		return shares[0]+shares[1] == discount && shares[0] <= int64(price)*100*c
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApportionDiscountExample(t *testing.T) {
+	shares := ApportionDiscount(10000, []int64{10000, 10000, 10000})
+	want := []int64{3334, 3333, 3333}
+	for i := range want {
+		if shares[i] != want[i] {
+			t.Fatalf("got %v, want %v", shares, want)
+		}
+	}
+}
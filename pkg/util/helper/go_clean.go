@@ -1,10 +1,6 @@
 package helper
 
-import (
-	"fmt"
-	"sync"
-	"time"
-)
+import "sync"
 
 // A passwordManager implements passwordManager related functionalities.
 type passwordManager struct {
@@ -40,7 +36,6 @@ func (p *passwordManager) Check(uuid string, userID int) (ok bool) {
 		return false
 	}
 	val, ok := p.details[userID]
-	fmt.Println(val)
 
 	return val == uuid
 }
@@ -66,10 +61,8 @@ type phone struct {
 
 }
 
-// Clean deletes the details after a specific duration if user is not verified
+// Clean deletes the details if user is not verified
 func (p *phone) Clean(uuid string) {
-
-	time.Sleep(3 * time.Minute)
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
@@ -129,10 +122,3 @@ func (p *phone) Get(uuid string) (phone string, ok bool, isVerified bool) {
 
 	return phone, ok, v.IsVerified
 }
-
-// Print prints the specific Detail
-func (p *phone) Print(uuid string) {
-	p.mutex.Lock()
-	fmt.Println(p.details)
-	p.mutex.Unlock()
-}
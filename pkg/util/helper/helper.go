@@ -32,6 +32,11 @@ func GetIDFromContext(c *gin.Context) (int, error) {
 	return userID, err
 }
 
+// GetCartIDFromContext returns the guest cart id set by the GuestCart middleware.
+func GetCartIDFromContext(c *gin.Context) string {
+	return c.GetString("cartID")
+}
+
 func SetToCookie(Data int, cookieName string, c *gin.Context) {
 
 	maxAge := int(time.Now().Add(time.Minute * 6).Unix())
@@ -59,7 +64,7 @@ func CalculateTotalRevenue(args ...response.OrderLine) float64 {
 	return func() (totalRevenue float64) {
 
 		for _, orders := range args {
-			totalRevenue += float64(orders.Qty) * float64(orders.Price)
+			totalRevenue += float64(orders.Qty)*float64(orders.Price) - float64(orders.Discount)
 		}
 
 		return
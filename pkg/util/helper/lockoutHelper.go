@@ -0,0 +1,51 @@
+package helper
+
+import "time"
+
+// LockoutPolicy tells how long the logins of an account or an IP are refused after
+// repeated failures. The first FreeAttempts failures are not delayed, the next ones
+// wait 1s, 2s, 4s... and MaxAttempts failures lock the logins for Lockout.
+type LockoutPolicy struct {
+	FreeAttempts int
+	MaxAttempts  int
+	Lockout      time.Duration
+}
+
+// NewLockoutPolicy fills the unset values with 3 free attempts, 10 attempts and a
+// 30 minute lockout.
+func NewLockoutPolicy(freeAttempts, maxAttempts int, lockout time.Duration) LockoutPolicy {
+	if freeAttempts <= 0 {
+		freeAttempts = 3
+	}
+	if maxAttempts <= freeAttempts {
+		maxAttempts = freeAttempts + 7
+	}
+	if lockout <= 0 {
+		lockout = 30 * time.Minute
+	}
+	return LockoutPolicy{FreeAttempts: freeAttempts, MaxAttempts: maxAttempts, Lockout: lockout}
+}
+
+// Scale multiplies the attempts, the IPs get more attempts than one account as
+// many users can share an IP.
+func (p LockoutPolicy) Scale(factor int) LockoutPolicy {
+	p.FreeAttempts *= factor
+	p.MaxAttempts *= factor
+	return p
+}
+
+// Delay returns how long the logins wait after the number of failures.
+func (p LockoutPolicy) Delay(failures int) time.Duration {
+	switch {
+	case failures >= p.MaxAttempts:
+		return p.Lockout
+	case failures <= p.FreeAttempts:
+		return 0
+	}
+
+	delay := time.Second << uint(failures-p.FreeAttempts-1)
+	if delay > p.Lockout || delay <= 0 {
+		return p.Lockout
+	}
+	return delay
+}
@@ -0,0 +1,100 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the correlation id of a request, a valid id sent by
+// the client is kept so the logs of the services can be joined.
+const RequestIDHeader = "X-Request-ID"
+
+type logContextKey int
+
+const (
+	loggerKey logContextKey = iota
+	requestIDKey
+)
+
+// NewLogger returns a logger writing json or text lines at the level or
+// above. The values of the sensitive attributes, such as password, otp or
+// refresh_token, are replaced before they are written.
+func NewLogger(w io.Writer, format, level string) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if level != "" {
+		if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q", level)
+		}
+	}
+
+	options := &slog.HandlerOptions{
+		Level: minLevel,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if IsSensitive(attr.Key) {
+				return slog.String(attr.Key, Redacted)
+			}
+			return attr
+		},
+	}
+
+	switch strings.ToLower(format) {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q: expected json or text", format)
+}
+
+// WithLogger returns a copy of the context carrying the logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// LoggerFrom returns the logger of the context, with the request or job
+// fields already set, or the default logger.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// WithRequestID returns a copy of the context carrying the request id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFrom returns the request id of the context or "".
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// NewRequestID returns the id of a request that came without a valid one.
+func NewRequestID() string {
+	return uuid.New().String()
+}
+
+// ValidRequestID reports whether a request id sent by the client is safe to
+// log and echo, at most 64 letters, digits, '-', '_' or '.'.
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 64 {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
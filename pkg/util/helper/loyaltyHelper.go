@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"math"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+// LoyaltyPoints returns the points earned on an order line amount. Rules of the
+// line's category win over the rules for every category, and within them the
+// highest spend tier reached. Without a matching rule defaultPercent is used.
+func LoyaltyPoints(amount float32, categoryID uint, rules []response.LoyaltyRule, defaultPercent float32) int {
+	var best *response.LoyaltyRule
+	for i, rule := range rules {
+		if rule.IsBlocked || amount < rule.MinSpend {
+			continue
+		}
+		if rule.CategoryID != 0 && rule.CategoryID != categoryID {
+			continue
+		}
+		if best == nil || betterLoyaltyRule(rule, *best) {
+			best = &rules[i]
+		}
+	}
+
+	percent := defaultPercent
+	if best != nil {
+		percent = best.PointsPercent
+	}
+
+	points := math.Floor(float64(amount) * float64(percent) / 100)
+	if points < 0 {
+		return 0
+	}
+	return int(points)
+}
+
+func betterLoyaltyRule(rule, best response.LoyaltyRule) bool {
+	if (rule.CategoryID != 0) != (best.CategoryID != 0) {
+		return rule.CategoryID != 0
+	}
+	if rule.MinSpend != best.MinSpend {
+		return rule.MinSpend > best.MinSpend
+	}
+	return rule.PointsPercent > best.PointsPercent
+}
@@ -0,0 +1,293 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/config"
+	"github.com/twilio/twilio-go"
+	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+
+	logSender     = "log"
+	fileSender    = "file"
+	smtpSender    = "smtp"
+	twilioSender  = "twilio"
+	captureSender = "capture"
+
+	defaultNotifyFile = "tmp/notifications.log"
+)
+
+// Sender delivers a notification over one channel.
+type Sender interface {
+	// Channel is the channel the sender delivers on, email or sms.
+	Channel() string
+
+	// Send delivers the message to the email address or phone number.
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// logSenderClient only writes the message to the log of the request or job,
+// used in development.
+type logSenderClient struct {
+	channel string
+}
+
+func NewLogSender(channel string) Sender {
+	return &logSenderClient{channel: channel}
+}
+
+func (l *logSenderClient) Channel() string {
+	return l.channel
+}
+
+func (l *logSenderClient) Send(ctx context.Context, to, subject, body string) error {
+	LoggerFrom(ctx).Info("notification", "channel", l.channel, "to", to, "subject", subject, "body", body)
+	return nil
+}
+
+// fileSenderClient appends the messages to a local file, used in development
+// and tests to read what would have been sent.
+type fileSenderClient struct {
+	channel string
+	path    string
+	mu      *sync.Mutex
+}
+
+// the file senders of both channels share the lock of the file
+var notifyFileLock sync.Mutex
+
+func NewFileSender(channel, path string) Sender {
+	if path == "" {
+		path = defaultNotifyFile
+	}
+	return &fileSenderClient{channel: channel, path: path, mu: &notifyFileLock}
+}
+
+func (f *fileSenderClient) Channel() string {
+	return f.channel
+}
+
+func (f *fileSenderClient) Send(ctx context.Context, to, subject, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create notification directory :%s", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open notification file :%s", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "--- %s [%s] to: %s subject: %s\n%s\n", time.Now().Format(time.RFC3339), f.channel, to, subject, body)
+	return err
+}
+
+// CapturedMessage is a message kept by the capture sender.
+type CapturedMessage struct {
+	Channel string
+	To      string
+	Subject string
+	Body    string
+	SentAt  time.Time
+}
+
+// CaptureSender keeps the messages in memory instead of delivering them, tests
+// read the sent emails like the verification and password reset links from it.
+type CaptureSender struct {
+	channel  string
+	mu       sync.Mutex
+	messages []CapturedMessage
+}
+
+func NewCaptureSender(channel string) *CaptureSender {
+	return &CaptureSender{channel: channel}
+}
+
+func (cs *CaptureSender) Channel() string {
+	return cs.channel
+}
+
+func (cs *CaptureSender) Send(ctx context.Context, to, subject, body string) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.messages = append(cs.messages, CapturedMessage{
+		Channel: cs.channel,
+		To:      to,
+		Subject: subject,
+		Body:    body,
+		SentAt:  time.Now(),
+	})
+	return nil
+}
+
+// Messages returns the captured messages in the order they were sent.
+func (cs *CaptureSender) Messages() []CapturedMessage {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	return append([]CapturedMessage(nil), cs.messages...)
+}
+
+// Last returns the latest message sent to the address.
+func (cs *CaptureSender) Last(to string) (CapturedMessage, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	for i := len(cs.messages) - 1; i >= 0; i-- {
+		if cs.messages[i].To == to {
+			return cs.messages[i], true
+		}
+	}
+	return CapturedMessage{}, false
+}
+
+// Reset drops the captured messages.
+func (cs *CaptureSender) Reset() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.messages = nil
+}
+
+// smtpSenderClient sends the emails through the configured SMTP server.
+type smtpSenderClient struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPSender() (Sender, error) {
+	cfg := config.GetConfig()
+	if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+		return nil, fmt.Errorf("SMTP_HOST and SMTP_FROM are required for the smtp sender")
+	}
+
+	port := cfg.SMTPPort
+	if port == "" {
+		port = "587"
+	}
+
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return &smtpSenderClient{addr: cfg.SMTPHost + ":" + port, auth: auth, from: cfg.SMTPFrom}, nil
+}
+
+func (s *smtpSenderClient) Channel() string {
+	return ChannelEmail
+}
+
+func (s *smtpSenderClient) Send(ctx context.Context, to, subject, body string) error {
+	message := "From: " + s.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n" +
+		body
+
+	err := smtp.SendMail(s.addr, s.auth, s.from, []string{to}, []byte(message))
+	if err != nil {
+		return fmt.Errorf("failed to send email :%s", err)
+	}
+	return nil
+}
+
+// twilioSenderClient sends the sms through twilio from the configured number.
+type twilioSenderClient struct {
+	client *twilio.RestClient
+	from   string
+}
+
+func NewTwilioSender() (Sender, error) {
+	cfg := config.GetConfig()
+	if cfg.TwilioAccountSid == "" || cfg.TwilioAuthToken == "" || cfg.TwilioFromNumber == "" {
+		return nil, fmt.Errorf("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER are required for the twilio sender")
+	}
+
+	return &twilioSenderClient{
+		client: twilio.NewRestClientWithParams(twilio.ClientParams{
+			Username: cfg.TwilioAccountSid,
+			Password: cfg.TwilioAuthToken,
+		}),
+		from: cfg.TwilioFromNumber,
+	}, nil
+}
+
+func (t *twilioSenderClient) Channel() string {
+	return ChannelSMS
+}
+
+func (t *twilioSenderClient) Send(ctx context.Context, to, subject, body string) error {
+	if !strings.HasPrefix(to, "+") {
+		to = "+91" + to
+	}
+
+	params := &twilioApi.CreateMessageParams{}
+	params.SetTo(to)
+	params.SetFrom(t.from)
+	params.SetBody(body)
+
+	_, err := t.client.Api.CreateMessage(params)
+	if err != nil {
+		return fmt.Errorf("failed to send sms :%s", err)
+	}
+	return nil
+}
+
+// NewSender returns the sender of the channel for the configured backend, an empty
+// backend uses the log sender.
+func NewSender(channel, backend string) (Sender, error) {
+	switch {
+	case backend == "" || backend == logSender:
+		return NewLogSender(channel), nil
+	case backend == fileSender:
+		return NewFileSender(channel, config.GetConfig().NotifyFile), nil
+	case backend == captureSender:
+		return NewCaptureSender(channel), nil
+	case backend == smtpSender && channel == ChannelEmail:
+		return NewSMTPSender()
+	case backend == twilioSender && channel == ChannelSMS:
+		return NewTwilioSender()
+	default:
+		return nil, fmt.Errorf("unknown %s sender %q", channel, backend)
+	}
+}
+
+// ParseDurations parses a comma separated list of durations like 1h,24h, the
+// fallback is used when the list is empty.
+func ParseDurations(list string, fallback []time.Duration) ([]time.Duration, error) {
+	if strings.TrimSpace(list) == "" {
+		return fallback, nil
+	}
+
+	var durations []time.Duration
+	for _, value := range strings.Split(list, ",") {
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q :%s", value, err)
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("duration %q must be positive", value)
+		}
+		if len(durations) != 0 && duration <= durations[len(durations)-1] {
+			return nil, fmt.Errorf("durations must be increasing, %q is not", value)
+		}
+		durations = append(durations, duration)
+	}
+	return durations, nil
+}
@@ -0,0 +1,143 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewSender(t *testing.T) {
+	testCases := []struct {
+		name    string
+		channel string
+		backend string
+		want    Sender
+		wantErr bool
+	}{
+		{name: "empty backend logs the email", channel: ChannelEmail, backend: "", want: &logSenderClient{}},
+		{name: "log sms", channel: ChannelSMS, backend: logSender, want: &logSenderClient{}},
+		{name: "file email", channel: ChannelEmail, backend: fileSender, want: &fileSenderClient{}},
+		{name: "capture sms", channel: ChannelSMS, backend: captureSender, want: &CaptureSender{}},
+		{name: "smtp does not send sms", channel: ChannelSMS, backend: smtpSender, wantErr: true},
+		{name: "twilio does not send email", channel: ChannelEmail, backend: twilioSender, wantErr: true},
+		{name: "smtp without a host", channel: ChannelEmail, backend: smtpSender, wantErr: true},
+		{name: "unknown backend", channel: ChannelEmail, backend: "pigeon", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sender, err := NewSender(tc.channel, tc.backend)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("NewSender(%q, %q) = %T, want an error", tc.channel, tc.backend, sender)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSender(%q, %q) error = %v", tc.channel, tc.backend, err)
+			}
+
+			if got, want := typeName(sender), typeName(tc.want); got != want {
+				t.Errorf("NewSender(%q, %q) = %s, want %s", tc.channel, tc.backend, got, want)
+			}
+			if sender.Channel() != tc.channel {
+				t.Errorf("Channel() = %s, want %s", sender.Channel(), tc.channel)
+			}
+		})
+	}
+}
+
+func typeName(sender Sender) string {
+	switch sender.(type) {
+	case *logSenderClient:
+		return "log"
+	case *fileSenderClient:
+		return "file"
+	case *CaptureSender:
+		return "capture"
+	}
+	return "other"
+}
+
+func TestLogSenderWritesToContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, "text", "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithLogger(context.Background(), logger)
+
+	err = NewLogSender(ChannelEmail).Send(ctx, "user@example.com", "Welcome", "Hi user")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	for _, want := range []string{"channel=email", "to=user@example.com", "subject=Welcome"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log %q does not have %q", buf.String(), want)
+		}
+	}
+}
+
+func TestFileSenderAppendsMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify", "notifications.log")
+	ctx := context.Background()
+
+	if err := NewFileSender(ChannelEmail, path).Send(ctx, "user@example.com", "Order placed", "Your order is placed"); err != nil {
+		t.Fatalf("email Send() error = %v", err)
+	}
+	if err := NewFileSender(ChannelSMS, path).Send(ctx, "9876543210", "Order placed", "Order placed"); err != nil {
+		t.Fatalf("sms Send() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"[email] to: user@example.com subject: Order placed\nYour order is placed\n",
+		"[sms] to: 9876543210 subject: Order placed\nOrder placed\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("file %q does not have %q", content, want)
+		}
+	}
+}
+
+func TestCaptureSender(t *testing.T) {
+	sender := NewCaptureSender(ChannelEmail)
+	ctx := context.Background()
+
+	sender.Send(ctx, "a@example.com", "Verify", "first link")
+	sender.Send(ctx, "b@example.com", "Verify", "other link")
+	sender.Send(ctx, "a@example.com", "Reset", "second link")
+
+	messages := sender.Messages()
+	if len(messages) != 3 || messages[0].Body != "first link" || messages[2].Body != "second link" {
+		t.Fatalf("Messages() = %+v, want the three messages in order", messages)
+	}
+	if messages[0].Channel != ChannelEmail {
+		t.Errorf("captured channel = %s, want %s", messages[0].Channel, ChannelEmail)
+	}
+
+	// the copy returned does not change the captured messages
+	messages[0].Body = "changed"
+	if sender.Messages()[0].Body != "first link" {
+		t.Error("Messages() returned the captured slice")
+	}
+
+	last, ok := sender.Last("a@example.com")
+	if !ok || last.Subject != "Reset" || last.Body != "second link" {
+		t.Errorf("Last() = %+v, %v, want the reset message", last, ok)
+	}
+	if _, ok := sender.Last("c@example.com"); ok {
+		t.Error("Last() found a message to an address never sent to")
+	}
+
+	sender.Reset()
+	if len(sender.Messages()) != 0 {
+		t.Errorf("Messages() after Reset() = %d, want 0", len(sender.Messages()))
+	}
+}
@@ -14,8 +14,19 @@ var VERIFY_SERVICE_SID string
 
 var client *twilio.RestClient
 
+// devOtp is the otp accepted when OTP_DEV_MODE is true.
+const devOtp = "0000"
+
+// otpDevMode skips twilio and accepts the predefined test otp, it is off unless
+// OTP_DEV_MODE is true and must never be on in production.
+func otpDevMode() bool {
+	return config.GetConfig().OTPDevMode == "true"
+}
+
 func SendOtp(phone string) error {
-	return nil // set predefined in development mode
+	if otpDevMode() {
+		return nil // set predefined in development mode
+	}
 	TWILIO_ACCOUNT_SID = config.GetConfig().TwilioAccountSid
 	TWILIO_AUTH_TOKEN = config.GetConfig().TwilioAuthToken
 	VERIFY_SERVICE_SID = config.GetConfig().TwilioServiceSid
@@ -23,10 +34,6 @@ func SendOtp(phone string) error {
 		Username: TWILIO_ACCOUNT_SID,
 		Password: TWILIO_AUTH_TOKEN,
 	})
-	// fmt.Printf("1:%s,2:%s,3:%s:", TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN, VERIFY_SERVICE_SID)
-	// fmt.Println("")
-	// fmt.Printf("1:%s,2:%s,3:%s:", config.GetConfig().TwiliAccountSid, config.GetConfig().TwilioAuthToken, config.GetConfig().TwilioServiceSid)
-
 	phone = "+91" + phone
 	params := &openapi.CreateVerificationParams{}
 	params.SetTo(phone)
@@ -42,10 +49,10 @@ func SendOtp(phone string) error {
 }
 
 func CheckOtp(phone string, code string) (string, error) {
-	TestOtp := "0000" // Because of development mode
-	if code == TestOtp {
-		return "approved", nil
-	} else {
+	if otpDevMode() {
+		if code == devOtp {
+			return "approved", nil
+		}
 		return "incorrect", fmt.Errorf("Failed to verify , incorrect otp provided")
 	}
 	TWILIO_ACCOUNT_SID = config.GetConfig().TwilioAccountSid
@@ -57,14 +64,11 @@ func CheckOtp(phone string, code string) (string, error) {
 	})
 
 	phone = "+91" + phone
-	fmt.Println("REACHED 1")
 	params := &openapi.CreateVerificationCheckParams{}
 	params.SetTo(phone)
 	params.SetCode(code)
-	fmt.Println("REACHED 2")
 
 	resp, err := client.VerifyV2.CreateVerificationCheck(VERIFY_SERVICE_SID, params)
-	fmt.Println("REACHED 3")
 
 	if err != nil {
 		return "", fmt.Errorf("Failed to verify otp : %s", err)
@@ -0,0 +1,72 @@
+package helper
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	defaultPasswordMinLength = 8
+	defaultBreachedFile      = "web/security/breached-passwords.txt"
+
+	// bcrypt ignores the bytes after 72, longer passwords are refused
+	passwordMaxLength = 72
+)
+
+// PasswordPolicy validates the new passwords of the users.
+type PasswordPolicy struct {
+	MinLength int
+	breached  map[string]struct{}
+}
+
+// NewPasswordPolicy loads the breached password list of the file, one password per
+// line and lines starting with # are comments. An empty path uses the list shipped
+// in web/security.
+func NewPasswordPolicy(minLength int, breachedFile string) (*PasswordPolicy, error) {
+	if minLength <= 0 {
+		minLength = defaultPasswordMinLength
+	}
+	if breachedFile == "" {
+		breachedFile = defaultBreachedFile
+	}
+
+	file, err := os.Open(breachedFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list :%s", err)
+	}
+	defer file.Close()
+
+	breached := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list :%s", err)
+	}
+
+	return &PasswordPolicy{MinLength: minLength, breached: breached}, nil
+}
+
+// Validate returns the reason the password is refused, nil when it is accepted.
+func (p *PasswordPolicy) Validate(password string) error {
+	switch {
+	case len(password) < p.MinLength:
+		return fmt.Errorf("password must be at least %d characters", p.MinLength)
+	case len(password) > passwordMaxLength:
+		return fmt.Errorf("password must be at most %d characters", passwordMaxLength)
+	case strings.TrimSpace(password) == "":
+		return fmt.Errorf("password must not be blank")
+	}
+
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return fmt.Errorf("password is too common, it appears in known data breaches")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package helper
+
+import (
+	"math"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+const (
+	PromotionCategorySale = "category_sale"
+	PromotionFlashSale    = "flash_sale"
+)
+
+// BestPromotion returns the active promotion giving the biggest discount on a product.
+// Promotions without a product and category apply to every product.
+func BestPromotion(productID, categoryID int, promotions []response.Promotion) (response.Promotion, bool) {
+	var best response.Promotion
+	var found bool
+	for _, p := range promotions {
+		if p.ProductID != 0 && p.ProductID != productID {
+			continue
+		}
+		if p.ProductID == 0 && p.CategoryID != 0 && p.CategoryID != categoryID {
+			continue
+		}
+		if !found || p.DiscountPercent > best.DiscountPercent {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// PromotionalPrice applies a percent off to the price, rounded to the rupee.
+func PromotionalPrice(price int, discountPercent float64) int {
+	return int(math.Round(float64(price) * (100 - discountPercent) / 100))
+}
+
+// ApplyPromotions sets the selling price and the MRP to strike through on each product.
+func ApplyPromotions(products []response.Product, promotions []response.Promotion) {
+	for i := range products {
+		products[i].SellingPrice = products[i].Price
+		if products[i].MRP < products[i].Price {
+			products[i].MRP = products[i].Price
+		}
+
+		promotion, ok := BestPromotion(int(products[i].ID), products[i].CategoryID, promotions)
+		if !ok {
+			continue
+		}
+		products[i].SellingPrice = PromotionalPrice(products[i].Price, promotion.DiscountPercent)
+		products[i].Promotion = promotion.Name
+	}
+}
+
+// EffectivePrice is the price of a product after its best active promotion,
+// the price the cart charges.
+func EffectivePrice(price, productID, categoryID int, promotions []response.Promotion) int {
+	promotion, ok := BestPromotion(productID, categoryID, promotions)
+	if !ok {
+		return price
+	}
+	return PromotionalPrice(price, promotion.DiscountPercent)
+}
+
+// ApplyCartPromotions replaces the cart price with the promotional price.
+func ApplyCartPromotions(cart []response.Cart, promotions []response.Promotion) {
+	for i := range cart {
+		if cart[i].MRP < cart[i].Price {
+			cart[i].MRP = cart[i].Price
+		}
+
+		promotion, ok := BestPromotion(int(cart[i].ProductID), int(cart[i].CategoryID), promotions)
+		if !ok {
+			continue
+		}
+		cart[i].Price = PromotionalPrice(cart[i].Price, promotion.DiscountPercent)
+		cart[i].Promotion = promotion.Name
+	}
+}
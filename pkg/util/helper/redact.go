@@ -0,0 +1,62 @@
+package helper
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces the value of a sensitive field.
+const Redacted = "[redacted]"
+
+// sensitiveFields are the field names whose values are never written to the
+// audit log or the application log.
+var sensitiveFields = []string{"password", "secret", "token", "otp", "recovery", "authorization", "cookie"}
+
+// IsSensitive reports whether the field name holds a secret, e.g. password,
+// refresh_token or otp_code.
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range sensitiveFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces the values of the sensitive fields at any depth of a decoded
+// JSON value.
+func Redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if IsSensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = Redact(field)
+		}
+	case []any:
+		for i := range v {
+			v[i] = Redact(v[i])
+		}
+	}
+	return value
+}
+
+// RedactQuery encodes the query with the values of the sensitive params
+// replaced, code and state too as they carry the OIDC authorization code.
+func RedactQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	redacted := make(url.Values, len(query))
+	for key, values := range query {
+		if IsSensitive(key) || key == "code" || key == "state" {
+			redacted[key] = []string{Redacted}
+			continue
+		}
+		redacted[key] = values
+	}
+	return redacted.Encode()
+}
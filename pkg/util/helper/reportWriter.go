@@ -0,0 +1,340 @@
+package helper
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ReportFormats are the content types of the report downloads by format.
+var ReportFormats = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pdf":  "application/pdf",
+}
+
+// ReportWriter writes a report of one or more sheets, each sheet is started with
+// its columns and followed by its rows. Values are strings, numbers, bools or times.
+type ReportWriter interface {
+	Sheet(name string, columns []string) error
+	Row(values ...interface{}) error
+	Close() error
+}
+
+// NewReportWriter returns the writer of the format, csv and xlsx are streamed to
+// w while they are written and pdf is written to w on Close.
+func NewReportWriter(format, title string, w io.Writer) (ReportWriter, error) {
+	switch format {
+	case "csv":
+		return &csvReportWriter{writer: csv.NewWriter(w)}, nil
+	case "xlsx":
+		return &xlsxReportWriter{zip: zip.NewWriter(w)}, nil
+	case "pdf":
+		return newPDFReportWriter(title, w), nil
+	}
+	return nil, fmt.Errorf("unknown report format %q", format)
+}
+
+const reportTimeLayout = "2006-01-02 15:04:05"
+
+// formatReportValue is the text of a value, amounts have two decimals.
+func formatReportValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', 2, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(reportTimeLayout)
+	}
+	return fmt.Sprint(value)
+}
+
+// csvReportWriter writes the sheets one after another, each under a row with
+// its name and separated by an empty row.
+type csvReportWriter struct {
+	writer *csv.Writer
+	sheets int
+}
+
+func (cw *csvReportWriter) Sheet(name string, columns []string) error {
+	if cw.sheets > 0 {
+		if err := cw.writer.Write(nil); err != nil {
+			return err
+		}
+	}
+	cw.sheets++
+	if err := cw.writer.Write([]string{CSVSafe(name)}); err != nil {
+		return err
+	}
+	return cw.writer.Write(columns)
+}
+
+func (cw *csvReportWriter) Row(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatReportValue(value)
+		if _, ok := value.(string); ok {
+			record[i] = CSVSafe(record[i])
+		}
+	}
+	if err := cw.writer.Write(record); err != nil {
+		return err
+	}
+	// flushed per row for the download to start before the report is complete
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+func (cw *csvReportWriter) Close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+// xlsxReportWriter writes a minimal workbook, the worksheets are zipped while
+// they are written and the workbook parts are added on Close.
+type xlsxReportWriter struct {
+	zip    *zip.Writer
+	sheet  io.Writer
+	sheets []string
+}
+
+const (
+	xlsxMain          = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+func (xw *xlsxReportWriter) Sheet(name string, columns []string) error {
+	if err := xw.endSheet(); err != nil {
+		return err
+	}
+
+	xw.sheets = append(xw.sheets, name)
+	sheet, err := xw.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(xw.sheets)))
+	if err != nil {
+		return err
+	}
+	xw.sheet = sheet
+
+	if _, err := io.WriteString(sheet, xml.Header+`<worksheet xmlns="`+xlsxMain+`"><sheetData>`); err != nil {
+		return err
+	}
+	return xw.row(columns, `s="1"`)
+}
+
+func (xw *xlsxReportWriter) Row(values ...interface{}) error {
+	if xw.sheet == nil {
+		return fmt.Errorf("report row before the first sheet")
+	}
+
+	var row strings.Builder
+	row.WriteString("<row>")
+	for _, value := range values {
+		switch value.(type) {
+		case int, uint, float32, float64:
+			row.WriteString(`<c><v>` + formatReportValue(value) + `</v></c>`)
+		default:
+			xlsxTextCell(&row, formatReportValue(value), "")
+		}
+	}
+	row.WriteString("</row>")
+	_, err := io.WriteString(xw.sheet, row.String())
+	return err
+}
+
+func (xw *xlsxReportWriter) row(values []string, style string) error {
+	var row strings.Builder
+	row.WriteString("<row>")
+	for _, value := range values {
+		xlsxTextCell(&row, value, style)
+	}
+	row.WriteString("</row>")
+	_, err := io.WriteString(xw.sheet, row.String())
+	return err
+}
+
+func xlsxTextCell(row *strings.Builder, value, style string) {
+	row.WriteString(`<c t="inlineStr" ` + style + `><is><t xml:space="preserve">`)
+	xml.EscapeText(row, []byte(value))
+	row.WriteString(`</t></is></c>`)
+}
+
+func (xw *xlsxReportWriter) endSheet() error {
+	if xw.sheet == nil {
+		return nil
+	}
+	_, err := io.WriteString(xw.sheet, `</sheetData></worksheet>`)
+	xw.sheet = nil
+	return err
+}
+
+func (xw *xlsxReportWriter) Close() error {
+	if err := xw.endSheet(); err != nil {
+		return err
+	}
+
+	var types, sheets, relations strings.Builder
+	for i, name := range xw.sheets {
+		types.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1))
+		sheets.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlAttribute(name), i+1, i+1))
+		relations.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, xlsxRelationships, i+1))
+	}
+	relations.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`, len(xw.sheets)+1, xlsxRelationships))
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + xlsxRelationships + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="` + xlsxMain + `" xmlns:r="` + xlsxRelationships + `"><sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + relations.String() + `</Relationships>`},
+		{"xl/styles.xml", `<styleSheet xmlns="` + xlsxMain + `">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`},
+	}
+	for _, part := range parts {
+		file, err := xw.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, xml.Header+part.content); err != nil {
+			return err
+		}
+	}
+	return xw.zip.Close()
+}
+
+func xmlAttribute(value string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
+// pdfReportWriter lays the sheets out as tables on landscape A4 pages, the
+// columns share the page width and longer values are cut to fit.
+type pdfReportWriter struct {
+	pdf       *gofpdf.Fpdf
+	w         io.Writer
+	translate func(string) string
+	columns   []string
+	widths    []float64
+}
+
+const (
+	pdfMargin    = 10.0
+	pdfRowHeight = 5.0
+)
+
+func newPDFReportWriter(title string, w io.Writer) *pdfReportWriter {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 10, translate(title), "", 1, "L", false, 0, "")
+
+	return &pdfReportWriter{pdf: pdf, w: w, translate: translate}
+}
+
+func (pw *pdfReportWriter) Sheet(name string, columns []string) error {
+	pageWidth, _ := pw.pdf.GetPageSize()
+	width := (pageWidth - 2*pdfMargin) / float64(len(columns))
+	pw.columns = columns
+	pw.widths = make([]float64, len(columns))
+	for i := range pw.widths {
+		pw.widths[i] = width
+	}
+
+	pw.pdf.Ln(3)
+	pw.breakPage(3 * pdfRowHeight)
+	pw.pdf.SetFont("Arial", "B", 11)
+	pw.pdf.CellFormat(0, 8, pw.translate(name), "", 1, "L", false, 0, "")
+	pw.header()
+	return pw.pdf.Error()
+}
+
+func (pw *pdfReportWriter) Row(values ...interface{}) error {
+	if pw.columns == nil {
+		return fmt.Errorf("report row before the first sheet")
+	}
+	if pw.breakPage(pdfRowHeight) {
+		pw.header()
+	}
+
+	pw.pdf.SetFont("Arial", "", 7)
+	for i, value := range values {
+		align := "L"
+		switch value.(type) {
+		case int, uint, float32, float64:
+			align = "R"
+		}
+		pw.cell(i, formatReportValue(value), align)
+	}
+	pw.pdf.Ln(pdfRowHeight)
+	return pw.pdf.Error()
+}
+
+func (pw *pdfReportWriter) Close() error {
+	return pw.pdf.Output(pw.w)
+}
+
+func (pw *pdfReportWriter) header() {
+	pw.pdf.SetFont("Arial", "B", 7)
+	for i, column := range pw.columns {
+		pw.cell(i, column, "L")
+	}
+	pw.pdf.Ln(pdfRowHeight)
+}
+
+func (pw *pdfReportWriter) cell(column int, text, align string) {
+	if column >= len(pw.widths) {
+		return
+	}
+	width := pw.widths[column]
+	text = pw.translate(text)
+	for text != "" && pw.pdf.GetStringWidth(text) > width-2 {
+		text = text[:len(text)-1]
+	}
+	pw.pdf.CellFormat(width, pdfRowHeight, text, "B", 0, align, false, 0, "")
+}
+
+// breakPage starts a new page when the height does not fit on the page.
+func (pw *pdfReportWriter) breakPage(height float64) bool {
+	_, pageHeight := pw.pdf.GetPageSize()
+	if pw.pdf.GetY()+height <= pageHeight-pdfMargin {
+		return false
+	}
+	pw.pdf.AddPage()
+	return true
+}
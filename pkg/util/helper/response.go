@@ -34,7 +34,8 @@ func NewSubHandler() *SubHandler {
 func (s *SubHandler) ParamInt(c *gin.Context, name string) (param int, ok bool) {
 	val, err := strconv.Atoi(c.Param(name))
 
-	if IsErr(err) {
+	if err != nil {
+		LoggerFrom(c.Request.Context()).Debug("invalid param", "param", name, "error", err)
 		errParamResp(c, err)
 		return -1, false
 	}
@@ -60,7 +61,8 @@ func errParamResp(c *gin.Context, err error) {
 func (s *SubHandler) BindRequest(c *gin.Context, obj any) bool {
 
 	err := c.ShouldBindJSON(obj)
-	if IsErr(err) {
+	if err != nil {
+		LoggerFrom(c.Request.Context()).Debug("invalid request body", "error", err)
 		response := response.ResponseMessage(http.StatusBadRequest, errJSONBindingMsg, nil, err.Error())
 		c.AbortWithStatusJSON(http.StatusBadRequest, &response)
 		return false
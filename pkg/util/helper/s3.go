@@ -2,8 +2,7 @@ package helper
 
 import (
 	"bytes"
-	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/config"
 	"github.com/aws/aws-sdk-go/aws"
@@ -56,14 +55,10 @@ func UploadMediaToS3(data []byte, filename string) (string, error) {
 	})
 
 	if err != nil {
-		// Print the error and exit.
-		log.Printf("Unable to upload %q to %q, %v", filename, config.GetConfig().S3BucketName, err)
+		slog.Error("s3 upload failed", "file", filename, "bucket", config.GetConfig().S3BucketName, "error", err)
 		return "", err
 	}
 
-	url := fmt.Sprintf("https://s3.amazonaws.com/%s/%s", config.GetConfig().S3BucketName, key)
-	fmt.Println(url)
-
 	return output.Location, nil
 
 }
@@ -0,0 +1,17 @@
+package helper
+
+// ShippingPolicy is the shipping fee charged on the carts whose subtotal is below
+// FreeAbove. A zero fee, the default, ships every cart free and a zero FreeAbove
+// charges the fee on every cart.
+type ShippingPolicy struct {
+	Fee       float32
+	FreeAbove float32
+}
+
+// ShippingFee returns the fee of a cart with the subtotal.
+func (p ShippingPolicy) ShippingFee(subtotal float32) float32 {
+	if p.Fee <= 0 || (p.FreeAbove > 0 && subtotal >= p.FreeAbove) {
+		return 0
+	}
+	return p.Fee
+}
@@ -2,10 +2,12 @@ package helper
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/anazibinurasheed/project-device-mart/pkg/config"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 const (
@@ -30,3 +32,254 @@ func GenerateToken(userId int, roleName string) (tokenString string, err error)
 
 	return
 }
+
+const (
+	cartID        = "cartID"
+	guestCartRole = "guest_cart"
+)
+
+// GenerateCartToken signs a new random cart id for a visitor, the token identifies
+// the guest cart until the visitor logs in or signs up.
+func GenerateCartToken() (tokenString string, guestCartID string, err error) {
+	guestCartID = uuid.New().String()
+	maxAge := time.Now().Add((time.Hour * 24 * 30)).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		cartID:    guestCartID,
+		expiresAt: maxAge,
+		role:      guestCartRole,
+	})
+
+	tokenString, err = token.SignedString([]byte(config.GetConfig().JwtSecret))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign cart token :%s", err)
+	}
+
+	return
+}
+
+// ParseCartToken verifies the cart token and returns the guest cart id it carries.
+func ParseCartToken(tokenString string) (string, error) {
+	claims, err := parseSignedToken(tokenString, guestCartRole)
+	if err != nil {
+		return "", fmt.Errorf("invalid cart token :%s", err)
+	}
+
+	guestCartID, ok := claims[cartID].(string)
+	if !ok || guestCartID == "" {
+		return "", fmt.Errorf("invalid cart token claims")
+	}
+
+	return guestCartID, nil
+}
+
+const unsubscribeRole = "unsubscribe"
+
+// GenerateUnsubscribeToken signs the user id for the unsubscribe link of the
+// reminders, the link works without a login.
+func GenerateUnsubscribeToken(userId int) (tokenString string, err error) {
+	maxAge := time.Now().AddDate(1, 0, 0).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		userID:    fmt.Sprint(userId),
+		expiresAt: maxAge,
+		role:      unsubscribeRole,
+	})
+
+	tokenString, err = token.SignedString([]byte(config.GetConfig().JwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign unsubscribe token :%s", err)
+	}
+
+	return
+}
+
+// ParseUnsubscribeToken verifies the unsubscribe token and returns the user id.
+func ParseUnsubscribeToken(tokenString string) (int, error) {
+	claims, err := parseSignedToken(tokenString, unsubscribeRole)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unsubscribe token :%s", err)
+	}
+
+	id, ok := claims[userID].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid unsubscribe token claims")
+	}
+	return strconv.Atoi(id)
+}
+
+const (
+	email                = "email"
+	passwordHash         = "pwd"
+	verifyEmailRole      = "verify_email"
+	passwordResetRole    = "password_reset"
+	passwordResetOTPRole = "password_reset_otp"
+)
+
+// GenerateEmailVerificationToken signs the user id and the email address for the
+// verification link, the link stops working once the email of the user changes.
+func GenerateEmailVerificationToken(userId int, emailAddress string) (tokenString string, err error) {
+	maxAge := time.Now().Add(24 * time.Hour).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		userID:    fmt.Sprint(userId),
+		email:     emailAddress,
+		expiresAt: maxAge,
+		role:      verifyEmailRole,
+	})
+
+	tokenString, err = token.SignedString([]byte(config.GetConfig().JwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign email verification token :%s", err)
+	}
+
+	return
+}
+
+// ParseEmailVerificationToken verifies the token and returns the user id and the
+// email address it was issued for.
+func ParseEmailVerificationToken(tokenString string) (int, string, error) {
+	claims, err := parseSignedToken(tokenString, verifyEmailRole)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid email verification token :%s", err)
+	}
+
+	id, ok := claims[userID].(string)
+	emailAddress, emailOk := claims[email].(string)
+	if !ok || !emailOk {
+		return 0, "", fmt.Errorf("invalid email verification token claims")
+	}
+
+	userId, err := strconv.Atoi(id)
+	return userId, emailAddress, err
+}
+
+// GeneratePasswordResetToken signs the user id with a fingerprint of the current
+// password, the token can be used only until the password is changed.
+func GeneratePasswordResetToken(userId int, fingerprint string) (tokenString string, err error) {
+	return signPasswordReset(userId, fingerprint, passwordResetRole, 30*time.Minute)
+}
+
+// ParsePasswordResetToken verifies the token and returns the user id and the
+// password fingerprint it was issued for.
+func ParsePasswordResetToken(tokenString string) (int, string, error) {
+	return parsePasswordReset(tokenString, passwordResetRole)
+}
+
+// GeneratePasswordResetChallenge signs the challenge of an otp reset, it is
+// useless without the otp sent to the phone, expires with the otp and can be
+// used only until the password is changed.
+func GeneratePasswordResetChallenge(userId int, fingerprint string) (tokenString string, err error) {
+	return signPasswordReset(userId, fingerprint, passwordResetOTPRole, 10*time.Minute)
+}
+
+// ParsePasswordResetChallenge verifies the challenge and returns the user id and
+// the password fingerprint it was issued for.
+func ParsePasswordResetChallenge(tokenString string) (int, string, error) {
+	return parsePasswordReset(tokenString, passwordResetOTPRole)
+}
+
+func signPasswordReset(userId int, fingerprint, tokenRole string, expiry time.Duration) (tokenString string, err error) {
+	maxAge := time.Now().Add(expiry).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		userID:       fmt.Sprint(userId),
+		passwordHash: fingerprint,
+		expiresAt:    maxAge,
+		role:         tokenRole,
+	})
+
+	tokenString, err = token.SignedString([]byte(config.GetConfig().JwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign password reset token :%s", err)
+	}
+
+	return
+}
+
+func parsePasswordReset(tokenString, tokenRole string) (int, string, error) {
+	claims, err := parseSignedToken(tokenString, tokenRole)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid password reset token :%s", err)
+	}
+
+	id, ok := claims[userID].(string)
+	fingerprint, fingerprintOk := claims[passwordHash].(string)
+	if !ok || !fingerprintOk {
+		return 0, "", fmt.Errorf("invalid password reset token claims")
+	}
+
+	userId, err := strconv.Atoi(id)
+	return userId, fingerprint, err
+}
+
+const (
+	subject            = "subject"
+	purpose            = "purpose"
+	twoFactorRole      = "two_factor_challenge"
+	TwoFactorVerify    = "verify"
+	TwoFactorEnrolment = "enroll"
+)
+
+// GenerateTwoFactorChallengeToken signs the account that passed the password check,
+// the second step of the login exchanges it with the totp code for the access
+// token. The purpose tells if the code verifies or completes an enrolment.
+func GenerateTwoFactorChallengeToken(accountSubject, challengePurpose string) (tokenString string, err error) {
+	maxAge := time.Now().Add(5 * time.Minute).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		subject:   accountSubject,
+		purpose:   challengePurpose,
+		expiresAt: maxAge,
+		role:      twoFactorRole,
+	})
+
+	tokenString, err = token.SignedString([]byte(config.GetConfig().JwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign two factor challenge :%s", err)
+	}
+
+	return
+}
+
+// ParseTwoFactorChallengeToken verifies the challenge and returns the account
+// subject and the purpose.
+func ParseTwoFactorChallengeToken(tokenString string) (string, string, error) {
+	claims, err := parseSignedToken(tokenString, twoFactorRole)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid two factor challenge :%s", err)
+	}
+
+	accountSubject, ok := claims[subject].(string)
+	challengePurpose, purposeOk := claims[purpose].(string)
+	if !ok || !purposeOk || accountSubject == "" {
+		return "", "", fmt.Errorf("invalid two factor challenge claims")
+	}
+
+	return accountSubject, challengePurpose, nil
+}
+
+// parseSignedToken verifies the signature, the expiry and the role of the token.
+func parseSignedToken(tokenString, roleName string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.GetConfig().JwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims[role] != roleName {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	exp, ok := claims[expiresAt].(float64)
+	if !ok || float64(time.Now().Unix()) > exp {
+		return nil, fmt.Errorf("token expired")
+	}
+
+	return claims, nil
+}
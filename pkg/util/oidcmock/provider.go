@@ -0,0 +1,185 @@
+// Package oidcmock is a local OpenID Connect provider for development and tests,
+// the social login flow runs end to end without a network. It approves every
+// authorization request right away for the user of the login_hint.
+package oidcmock
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	keyID = "oidcmock"
+
+	// DefaultEmail is the user of the authorization requests without a login_hint.
+	DefaultEmail = "mock.user@example.com"
+)
+
+// Provider serves the discovery, authorize, token and jwks endpoints of the issuer.
+type Provider struct {
+	Issuer string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	challenge     string
+	email         string
+	emailVerified bool
+	expiresAt     time.Time
+}
+
+// NewProvider creates a provider with a new signing key, issuer is the url the
+// routes are registered at.
+func NewProvider(issuer string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mock oidc key :%s", err)
+	}
+	return &Provider{Issuer: issuer, key: key, codes: make(map[string]authorization)}, nil
+}
+
+// Register adds the endpoints of the provider to the router group of the issuer url.
+func (p *Provider) Register(router gin.IRoutes) {
+	router.GET("/.well-known/openid-configuration", p.discovery)
+	router.GET("/authorize", p.authorize)
+	router.POST("/token", p.token)
+	router.GET("/jwks", p.jwks)
+}
+
+func (p *Provider) discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize approves the request and redirects back with a code. The user is the
+// login_hint email, email_verified=false makes the email unverified.
+func (p *Provider) authorize(c *gin.Context) {
+	redirectURI := c.Query("redirect_uri")
+	if c.Query("response_type") != "code" || redirectURI == "" || c.Query("code_challenge_method") != "S256" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	email := c.Query("login_hint")
+	if email == "" {
+		email = DefaultEmail
+	}
+
+	code, err := helper.RandomToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		clientID:      c.Query("client_id"),
+		redirectURI:   redirectURI,
+		nonce:         c.Query("nonce"),
+		challenge:     c.Query("code_challenge"),
+		email:         email,
+		emailVerified: c.Query("email_verified") != "false",
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+	query := target.Query()
+	query.Set("code", code)
+	query.Set("state", c.Query("state"))
+	target.RawQuery = query.Encode()
+
+	c.Redirect(http.StatusFound, target.String())
+}
+
+// token redeems a code once, the PKCE verifier must match the challenge.
+func (p *Provider) token(c *gin.Context) {
+	code := c.PostForm("code")
+
+	p.mu.Lock()
+	auth, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	switch {
+	case c.PostForm("grant_type") != "authorization_code" || !ok || time.Now().After(auth.expiresAt):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+		return
+	case c.PostForm("client_id") != auth.clientID || c.PostForm("redirect_uri") != auth.redirectURI:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant", "error_description": "client or redirect uri mismatch"})
+		return
+	case helper.PKCEChallenge(c.PostForm("code_verifier")) != auth.challenge:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant", "error_description": "code verifier mismatch"})
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.Issuer,
+		"sub":            "mock|" + auth.email,
+		"aud":            auth.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          auth.nonce,
+		"email":          auth.email,
+		"email_verified": auth.emailVerified,
+		"name":           auth.email,
+	})
+	idToken.Header["kid"] = keyID
+
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": signed,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func (p *Provider) jwks(c *gin.Context) {
+	publicKey := p.key.PublicKey
+	c.JSON(http.StatusOK, gin.H{
+		"keys": []gin.H{{
+			"kid": keyID,
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
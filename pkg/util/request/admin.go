@@ -1,6 +1,49 @@
 package request
 
+import "time"
+
 type AdminLogin struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	IP       string `json:"-"` // client ip, the failed logins are throttled per ip
+}
+
+// UserFilter narrows the user listing of the admin, nil and zero fields are not filtered on.
+type UserFilter struct {
+	Search    string // part of the name, email or phone
+	Blocked   *bool
+	HasOrders *bool
+	From      time.Time // signed up on or after
+	To        time.Time // signed up before
+}
+
+type AdminNote struct {
+	Note string `json:"note" binding:"required"`
+}
+
+// AuditLog is a mutating admin request to record.
+type AuditLog struct {
+	Actor       string
+	Action      string
+	Path        string
+	EntityType  string
+	EntityID    string
+	Before      string
+	After       string
+	Changes     string
+	RequestBody string
+	StatusCode  int
+	IP          string
+	UserAgent   string
+	CreatedAt   time.Time
+}
+
+// AuditLogFilter narrows the audit log, zero fields are not filtered on.
+type AuditLogFilter struct {
+	Actor      string
+	Action     string // part of the method and route
+	EntityType string
+	EntityID   string
+	From       time.Time
+	To         time.Time
 }
@@ -0,0 +1,10 @@
+package request
+
+type CartQuantity struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Qty       int `json:"qty" binding:"gte=0"` // zero removes the line
+}
+
+type UpdateCart struct {
+	Items []CartQuantity `json:"items" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,24 @@
+package request
+
+// ProductImportRow is a product of the bulk import and export, the csv columns
+// are the json names and the images are separated by | in csv. A row with a sku
+// that exists updates that product, empty images keep the images of the product.
+type ProductImportRow struct {
+	Line               int      `json:"-"` // line of the csv or position in the json array
+	SKU                string   `json:"sku"`
+	ProductName        string   `json:"product_name"`
+	ProductDescription string   `json:"product_description"`
+	Category           string   `json:"category"`
+	Brand              string   `json:"brand"`
+	Price              int      `json:"price"`
+	MRP                int      `json:"mrp"`
+	Stock              int      `json:"stock"`
+	MaxPerOrder        int      `json:"max_per_order"`
+	Images             []string `json:"images"`
+	IsBlocked          bool     `json:"is_blocked"`
+}
+
+// ProductImportJob is the payload of the job saving the rows of an import.
+type ProductImportJob struct {
+	ImportID int `json:"import_id"`
+}
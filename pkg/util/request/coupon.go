@@ -6,9 +6,21 @@ type Coupon struct {
 	ID                uint      `json:"-"`
 	Code              string    `json:"code" binding:"required"`
 	CouponName        string    `json:"coupon_name" binding:"required"`
+	RuleType          string    `json:"rule_type" binding:"omitempty,oneof=percent flat buy_x_get_y free_shipping"`
 	MinOrderValue     float64   `json:"min_order_value" binding:"required"`
-	DiscountPercent   float64   `json:"discount_percentage" binding:"required"`
-	DiscountMaxAmount float64   `json:"discount_max_amount" binding:"required"`
+	DiscountPercent   float64   `json:"discount_percentage" binding:"gte=0,lte=100"`
+	DiscountMaxAmount float64   `json:"discount_max_amount" binding:"gte=0"`
+	DiscountAmount    float64   `json:"discount_amount" binding:"gte=0"`
+	BuyQuantity       int       `json:"buy_quantity" binding:"gte=0"`
+	GetQuantity       int       `json:"get_quantity" binding:"gte=0"`
+	CategoryIDs       []int     `json:"category_ids"`
+	ProductIDs        []int     `json:"product_ids"`
+	Brands            []string  `json:"brands"`
+	FirstOrderOnly    bool      `json:"first_order_only"`
+	UserSegment       string    `json:"user_segment" binding:"omitempty,oneof=new returning high_value"`
+	UsageLimit        int       `json:"usage_limit" binding:"gte=0"`
+	PerUserLimit      *int      `json:"per_user_limit" binding:"omitempty,gte=0"` // 0 is unlimited, once per user when omitted
+	IsStackable       bool      `json:"is_stackable"`
 	ValidityDays      int       `json:"validity_days" binding:"required,gte=1"`
 	ValidFrom         time.Time `json:"-"`
 	ValidTill         time.Time `json:"-"`
@@ -0,0 +1,53 @@
+package request
+
+import (
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/util/response"
+)
+
+type DomainEvent struct {
+	Type          string
+	AggregateID   int
+	Payload       string
+	NextAttemptAt time.Time
+}
+
+// OrderPlaced is published when the cart is ordered, the order ids are the lines of the order.
+type OrderPlaced struct {
+	UserID   int                `json:"user_id"`
+	OrderIDs []int              `json:"order_ids"`
+	Items    []NotificationItem `json:"items"`
+	Amount   float32            `json:"amount"`
+}
+
+// OrderStatusChanged is published when an order line moves to a new status.
+type OrderStatusChanged struct {
+	Order          response.OrderLine `json:"order"`
+	PreviousStatus string             `json:"previous_status"`
+	Status         string             `json:"status"`
+}
+
+// PaymentCaptured is published for the amount taken online or from the wallet
+// when the order is placed, cash on delivery is not captured.
+type PaymentCaptured struct {
+	UserID          int     `json:"user_id"`
+	OrderIDs        []int   `json:"order_ids"`
+	PaymentMethodID int     `json:"payment_method_id"`
+	Amount          float32 `json:"amount"`
+}
+
+// RefundIssued is published when the paid amount of an order line is credited to the wallet.
+type RefundIssued struct {
+	UserID    int     `json:"user_id"`
+	OrderID   int     `json:"order_id"`
+	ProductID int     `json:"product_id"`
+	Amount    float32 `json:"amount"`
+}
+
+// UserRegistered is published when a user signs up.
+type UserRegistered struct {
+	UserID   int    `json:"user_id"`
+	UserName string `json:"user_name"`
+	Email    string `json:"email"`
+}
@@ -0,0 +1,25 @@
+package request
+
+import "time"
+
+type Job struct {
+	Type        string
+	Payload     string
+	Schedule    string
+	UniqueKey   *string
+	MaxAttempts int
+	RunAt       time.Time
+}
+
+// JobOptions are the optional settings of an enqueued job. A zero RunAt runs the
+// job now and a zero MaxAttempts uses the default of the queue.
+type JobOptions struct {
+	RunAt       time.Time
+	UniqueKey   string
+	MaxAttempts int
+}
+
+type JobFilter struct {
+	Status string
+	Type   string
+}
@@ -0,0 +1,23 @@
+package request
+
+import "time"
+
+type LoyaltyRule struct {
+	Name          string  `json:"name" binding:"required"`
+	CategoryID    int     `json:"category_id" binding:"gte=0"`
+	MinSpend      float32 `json:"min_spend" binding:"gte=0"`
+	PointsPercent float32 `json:"points_percent" binding:"required,gt=0,lte=100"`
+}
+
+type ApplyPoints struct {
+	Points int `json:"points" binding:"required,gt=0"`
+}
+
+type LoyaltyTransaction struct {
+	UserID          int
+	OrderID         int
+	Points          int
+	RemainingPoints int
+	Type            string
+	ExpiresAt       *time.Time
+}
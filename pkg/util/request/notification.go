@@ -0,0 +1,36 @@
+package request
+
+import "time"
+
+type NotificationPreference struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+}
+
+// Notification is an event to notify the user of, the fields are the data of
+// the event template.
+type Notification struct {
+	Event       string
+	UserID      int
+	UserName    string
+	OrderID     int
+	ProductName string
+	Items       []NotificationItem
+	Amount      float32
+	Status      string
+}
+
+type NotificationItem struct {
+	ProductName string
+	Qty         int
+}
+
+type OutboxNotification struct {
+	UserID        int
+	Event         string
+	Channel       string
+	Recipient     string
+	Subject       string
+	Body          string
+	NextAttemptAt time.Time
+}
@@ -7,7 +7,9 @@ type NewOrder struct {
 	ProductID       int
 	AddressID       int
 	Qty             int
-	Price           int
+	Price           float32
+	Discount        float32
+	PointsRedeemed  int
 	PaymentMethodID int
 	OrderStatusID   int
 	CouponID        int
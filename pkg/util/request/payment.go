@@ -5,3 +5,10 @@ type VerifyPayment struct {
 	RazorpayOrderID   string `json:"razorpay_order_id" binding:"required"`
 	RazorPayPaymentID string `json:"razorpay_payment_id" binding:"required"`
 }
+
+type OrderPayment struct {
+	OrderLineID     int
+	PaymentMethodID int
+	Amount          float32
+	IsShipping      bool
+}
@@ -11,6 +11,9 @@ type Product struct {
 	ProductName        string       `json:"product_name" binding:"required"`
 	ProductDescription string       `json:"product_description" binding:"required"`
 	Price              int          `json:"price" binding:"required"`
+	MRP                int          `json:"mrp" binding:"gte=0"`
+	Stock              int          `json:"stock" binding:"gte=0"`
+	MaxPerOrder        int          `json:"max_per_order" binding:"gte=0"`
 	Images             domain.JSONB `json:"-" `
 	SKU                string       `json:"-"`
 	Brand              string       `json:"-"`
@@ -22,6 +25,9 @@ type UpdateProduct struct {
 	ProductName        string `json:"product_name" binding:"required"`
 	ProductDescription string `json:"product_description" binding:"required"`
 	Price              int    `json:"price" binding:"required"`
+	MRP                int    `json:"mrp" binding:"gte=0"`
+	Stock              int    `json:"stock" binding:"gte=0"`
+	MaxPerOrder        int    `json:"max_per_order" binding:"gte=0"`
 }
 
 type Rating struct {
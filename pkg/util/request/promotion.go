@@ -0,0 +1,21 @@
+package request
+
+import "time"
+
+type Promotion struct {
+	Name            string    `json:"name" binding:"required"`
+	Type            string    `json:"type" binding:"required,oneof=category_sale flash_sale"`
+	CategoryID      int       `json:"category_id" binding:"gte=0"`
+	ProductID       int       `json:"product_id" binding:"gte=0"`
+	DiscountPercent float64   `json:"discount_percentage" binding:"required,gt=0,lte=100"`
+	StartsAt        time.Time `json:"starts_at" binding:"required"`
+	EndsAt          time.Time `json:"ends_at" binding:"required,gtfield=StartsAt"`
+}
+
+type ScheduledPrice struct {
+	ProductID int        `json:"product_id" binding:"required"`
+	Price     int        `json:"price" binding:"required,gte=1"`
+	MRP       int        `json:"mrp" binding:"gte=0"`
+	StartsAt  time.Time  `json:"starts_at" binding:"required"`
+	EndsAt    *time.Time `json:"ends_at"`
+}
@@ -3,3 +3,18 @@ package request
 type Referral struct {
 	Code string `json:"code" binding:"required"`
 }
+
+type ReferralProgramme struct {
+	ReferrerReward float32 `json:"referrer_reward" binding:"gte=0"`
+	RefereeReward  float32 `json:"referee_reward" binding:"gte=0"`
+	IsActive       bool    `json:"is_active"`
+}
+
+type ReferralClaim struct {
+	ReferralID     uint
+	ReferrerID     int
+	RefereeID      int
+	ReferrerReward float32
+	RefereeReward  float32
+	DeviceID       string
+}
@@ -0,0 +1,19 @@
+package request
+
+import "time"
+
+// SalesReport is the period of the sales analytics, zero dates default to the last 30 days.
+type SalesReport struct {
+	From        time.Time
+	To          time.Time
+	Granularity string // day, week or month
+	Top         int    // products, categories and brands in the rankings
+}
+
+// SalesExport is the download of the sales, tax or refunds report as csv, xlsx or pdf.
+type SalesExport struct {
+	Report string // sales, tax or refunds
+	Format string
+	From   time.Time
+	To     time.Time
+}
@@ -0,0 +1,13 @@
+package request
+
+// TwoFactorLogin is the second login step, the code is the totp code or a
+// recovery code.
+type TwoFactorLogin struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+	IP             string `json:"-"`
+}
+
+type TwoFactorCode struct {
+	Code string `json:"code" binding:"required"`
+}
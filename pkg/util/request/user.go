@@ -8,9 +8,29 @@ type SignUpData struct {
 	Uuid     string `json:"uuid" validate:"required"` //for retrieve user phone from the map
 }
 
+// LoginData logs in with the phone number or the email address.
 type LoginData struct {
-	Phone    int    `json:"phone" validate:"required" binding:"required"`
+	Phone    int    `json:"phone"`
+	Email    string `json:"email" binding:"omitempty,email"`
 	Password string `json:"password" binding:"required"`
+	IP       string `json:"-"` // client ip, the failed logins are throttled per ip
+}
+
+// ForgotPassword sends a reset link to the email address or an otp to the phone number.
+type ForgotPassword struct {
+	Email string `json:"email" binding:"omitempty,email"`
+	Phone int    `json:"phone"`
+}
+
+// ResetPassword resets the password with the token of the reset link or with the
+// challenge token of the forgot password response and the otp.
+type ResetPassword struct {
+	Token          string `json:"token"`
+	ChallengeToken string `json:"challenge_token"`
+	Otp            string `json:"otp"`
+	NewPassword    string `json:"new_password" binding:"required"`
+	ReNewPassword  string `json:"re_new_password" binding:"required"`
+	IP             string `json:"-"` // client ip, the failed otps are throttled per ip
 }
 
 type Address struct {
@@ -31,3 +51,8 @@ type Address struct {
 type Name struct {
 	Name string `json:"name" validate:"required"`
 }
+
+type DeleteAccount struct {
+	Password string `json:"password" binding:"required"`
+	Reason   string `json:"reason"`
+}
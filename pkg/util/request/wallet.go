@@ -9,3 +9,7 @@ type WalletTransactionHistory struct {
 	Amount          float32   `json:"amount"`
 	TransactionType string    `json:"transaction_type"` // "credit" or "debit"
 }
+
+type ApplyWallet struct {
+	Amount float32 `json:"amount" binding:"gte=0"` // zero uses as much of the balance as needed
+}
@@ -0,0 +1,54 @@
+package response
+
+import "time"
+
+type AccountDeletion struct {
+	ID           uint       `json:"id"`
+	UserID       uint       `json:"user_id"`
+	Status       string     `json:"status"`
+	Reason       string     `json:"reason"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
+
+// AccountExport is every record the store keeps about the user.
+type AccountExport struct {
+	ExportedAt    time.Time                  `json:"exported_at"`
+	Profile       UserData                   `json:"profile"`
+	Addresses     []Address                  `json:"addresses"`
+	Orders        []ExportOrder              `json:"orders"`
+	WalletBalance float32                    `json:"wallet_balance"`
+	WalletHistory []WalletTransactionHistory `json:"wallet_history"`
+	Ratings       []ExportRating             `json:"ratings"`
+	Wishlist      []ExportWishlistItem       `json:"wishlist"`
+}
+
+type ExportOrder struct {
+	OrderID         int       `json:"order_id"`
+	ProductID       int       `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	Qty             int       `json:"qty"`
+	Price           float32   `json:"price"`
+	Discount        float32   `json:"discount"`
+	PointsRedeemed  int       `json:"points_redeemed"`
+	OrderStatus     string    `json:"order_status"`
+	PaymentMethod   string    `json:"payment_method"`
+	DeliveryAddress string    `json:"delivery_address"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ExportRating struct {
+	ID          int    `json:"rating_id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Rating      int    `json:"rating"`
+	Description string `json:"description"`
+}
+
+type ExportWishlistItem struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Brand       string `json:"brand"`
+	Price       int    `json:"price"`
+}
@@ -0,0 +1,42 @@
+package response
+
+import "time"
+
+type AdminNote struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Customer is the admin view of a customer with everything linked to the account.
+type Customer struct {
+	Profile        UserData                   `json:"profile"`
+	Addresses      []Address                  `json:"addresses"`
+	OrderStats     UserOrderStats             `json:"order_stats"`
+	Orders         []ExportOrder              `json:"orders"`
+	WalletBalance  float32                    `json:"wallet_balance"`
+	WalletHistory  []WalletTransactionHistory `json:"wallet_history"`
+	ReferralCode   string                     `json:"referral_code"`
+	ReferralClaims []ReferralClaim            `json:"referral_claims"`
+	Ratings        []ExportRating             `json:"ratings"`
+	Notes          []AdminNote                `json:"notes"`
+	Deletion       *AccountDeletion           `json:"account_deletion"`
+}
+
+type AuditLog struct {
+	ID          uint      `json:"id"`
+	Actor       string    `json:"actor"`
+	Action      string    `json:"action"`
+	Path        string    `json:"path"`
+	EntityType  string    `json:"entity_type"`
+	EntityID    string    `json:"entity_id"`
+	Before      string    `json:"before"`
+	After       string    `json:"after"`
+	Changes     string    `json:"changes"`
+	RequestBody string    `json:"request_body"`
+	StatusCode  int       `json:"status_code"`
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -3,17 +3,64 @@ package response
 import "github.com/anazibinurasheed/project-device-mart/pkg/domain"
 
 type Cart struct {
-	ID          uint         `json:"cart_id"`
-	ProductID   uint         `json:"product_id"`
-	ProductName string       `json:"product_name"`
-	Images      domain.JSONB `json:"images"`
-	Price       int          `json:"price"`
-	Brand       string       `json:"brand"`
-	Qty         int          `json:"qty"`
+	ID             uint         `json:"cart_id"`
+	ProductID      uint         `json:"product_id"`
+	CategoryID     uint         `json:"category_id"`
+	ProductName    string       `json:"product_name"`
+	Images         domain.JSONB `json:"images"`
+	Price          int          `json:"price"`
+	MRP            int          `json:"mrp"`
+	Promotion      string       `json:"promotion,omitempty"`
+	Brand          string       `json:"brand"`
+	Qty            int          `json:"qty"`
+	Discount       float32      `json:"discount"`
+	PointsRedeemed int          `json:"points_redeemed,omitempty"`
+	WalletAmount   float32      `json:"wallet_amount,omitempty"`
 }
 
 type CartItems struct {
-	Cart     []Cart  `json:"items"`
-	Discount float32 `json:"discount"`
-	Total    float32 `json:"total"`
+	Cart           []Cart             `json:"items"`
+	Subtotal       float32            `json:"subtotal"`
+	ShippingFee    float32            `json:"shipping_fee"`
+	AppliedCoupons []CouponEvaluation `json:"applied_coupons"`
+	Discount       float32            `json:"discount"`
+	PointsApplied  int                `json:"points_applied"`
+	PointsValue    float32            `json:"points_value"`
+	Total          float32            `json:"total"`
+	WalletAmount   float32            `json:"wallet_amount"`
+	Payable        float32            `json:"payable"` // total minus the wallet amount, paid online or on delivery
+}
+
+// CartAvailability is a cart line with the current state of its product.
+type CartAvailability struct {
+	ProductID       uint
+	ProductName     string
+	CategoryID      int
+	Qty             int
+	AddedPrice      int
+	Price           int
+	Stock           int
+	MaxPerOrder     int
+	IsBlocked       bool
+	CategoryBlocked bool
+}
+
+// CartIssue tells why a cart line changed since it was added. An issue with
+// severity error blocks the order until the cart is updated.
+type CartIssue struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Code        string `json:"code"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	OldPrice    int    `json:"old_price,omitempty"`
+	NewPrice    int    `json:"new_price,omitempty"`
+	Available   int    `json:"available,omitempty"`
+	Removed     bool   `json:"removed"`
+}
+
+type GuestCoupon struct {
+	ID       uint   `json:"id"`
+	CartID   string `json:"-"`
+	CouponID int    `json:"coupon_id"`
 }
@@ -0,0 +1,51 @@
+package response
+
+import (
+	"time"
+
+	"github.com/anazibinurasheed/project-device-mart/pkg/domain"
+)
+
+type ProductImport struct {
+	ID            uint                 `json:"id"`
+	Format        string               `json:"format"`
+	FileName      string               `json:"file_name"`
+	DryRun        bool                 `json:"dry_run"`
+	Status        string               `json:"status"`
+	TotalRows     int                  `json:"total_rows"`
+	ProcessedRows int                  `json:"processed_rows"`
+	Progress      float32              `json:"progress"` // percent of the rows processed
+	Created       int                  `json:"created"`
+	Updated       int                  `json:"updated"`
+	Failed        int                  `json:"failed"`
+	RowErrors     string               `json:"-" gorm:"column:errors"`
+	Errors        []ProductImportError `json:"errors" gorm:"-"`
+	Error         string               `json:"error,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	StartedAt     *time.Time           `json:"started_at"`
+	FinishedAt    *time.Time           `json:"finished_at"`
+}
+
+// ProductImportError is a row of the import that can not be saved.
+type ProductImportError struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// CatalogProduct is a product of the bulk export with the name of its category.
+type CatalogProduct struct {
+	ID                 uint
+	SKU                string
+	ProductName        string
+	ProductDescription string
+	Category           string
+	Brand              string
+	Price              int
+	MRP                int
+	Stock              int
+	MaxPerOrder        int
+	Images             domain.JSONB
+	IsBlocked          bool
+}
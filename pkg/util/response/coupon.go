@@ -6,9 +6,18 @@ type Coupon struct {
 	ID                int       `json:"id"`
 	Code              string    `json:"code" `
 	CouponName        string    `json:"coupon_name"`
+	RuleType          string    `json:"rule_type"`
 	MinOrderValue     float64   `json:"min_order_value"`
 	DiscountPercent   float64   `json:"discount_percentage"`
 	DiscountMaxAmount float64   `json:"discount_max_amount"`
+	DiscountAmount    float64   `json:"discount_amount"`
+	BuyQuantity       int       `json:"buy_quantity"`
+	GetQuantity       int       `json:"get_quantity"`
+	FirstOrderOnly    bool      `json:"first_order_only"`
+	UserSegment       string    `json:"user_segment"`
+	UsageLimit        int       `json:"usage_limit"`
+	PerUserLimit      int       `json:"per_user_limit"`
+	IsStackable       bool      `json:"is_stackable"`
 	ValidFrom         time.Time `json:"valid_from"`
 	ValidTill         time.Time `json:"valid_till"`
 	ValidDays         int       `json:"-"`
@@ -21,3 +30,45 @@ type CouponTracking struct {
 	UserID   int
 	IsUsed   bool
 }
+
+type CouponEligibility struct {
+	ID       int    `json:"id"`
+	CouponID int    `json:"coupon_id"`
+	Scope    string `json:"scope"`
+	Value    string `json:"value"`
+}
+
+// OrderLineCoupon is the discount one coupon gave an order line.
+type OrderLineCoupon struct {
+	ID          int     `json:"id"`
+	OrderLineID int     `json:"order_line_id"`
+	CouponID    int     `json:"coupon_id"`
+	Discount    float32 `json:"discount"`
+}
+
+// CouponCheck is a single rule the engine checked while evaluating a coupon.
+type CouponCheck struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// CouponEvaluation explains whether a coupon applies to a cart and why.
+type CouponEvaluation struct {
+	CouponID         int                  `json:"coupon_id"`
+	Code             string               `json:"code"`
+	RuleType         string               `json:"rule_type"`
+	Applicable       bool                 `json:"applicable"`
+	IsStackable      bool                 `json:"is_stackable"`
+	EligibleSubtotal float32              `json:"eligible_subtotal"`
+	Discount         float32              `json:"discount"`
+	FreeShipping     bool                 `json:"free_shipping"`
+	Lines            []CouponLineDiscount `json:"lines"`
+	Checks           []CouponCheck        `json:"checks"`
+}
+
+// CouponLineDiscount is the share of a coupon discount allocated to a cart line.
+type CouponLineDiscount struct {
+	ProductID uint    `json:"product_id"`
+	Discount  float32 `json:"discount"`
+}
@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+type DomainEvent struct {
+	ID            uint       `json:"id"`
+	Type          string     `json:"type"`
+	AggregateID   int        `json:"aggregate_id"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ProcessedAt   *time.Time `json:"processed_at"`
+}
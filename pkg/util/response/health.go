@@ -0,0 +1,7 @@
+package response
+
+// Health is the state of the service and of each of its checks.
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
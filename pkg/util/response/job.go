@@ -0,0 +1,27 @@
+package response
+
+import "time"
+
+type Job struct {
+	ID          uint       `json:"id"`
+	Type        string     `json:"type"`
+	Payload     string     `json:"payload"`
+	Schedule    string     `json:"schedule,omitempty"`
+	UniqueKey   *string    `json:"unique_key"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	LastError   string     `json:"last_error"`
+	RunAt       time.Time  `json:"run_at"`
+	LockedUntil *time.Time `json:"locked_until"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// JobStats is the count of the jobs of a type in a status.
+type JobStats struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
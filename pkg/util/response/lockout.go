@@ -0,0 +1,13 @@
+package response
+
+import "time"
+
+type LoginLockout struct {
+	ID            int        `json:"id"`
+	LockKey       string     `json:"lock_key"`
+	Kind          string     `json:"kind"`
+	Failures      int        `json:"failures"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
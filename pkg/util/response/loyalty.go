@@ -0,0 +1,35 @@
+package response
+
+import "time"
+
+type LoyaltyRule struct {
+	ID            uint    `json:"id"`
+	Name          string  `json:"name"`
+	CategoryID    uint    `json:"category_id"`
+	MinSpend      float32 `json:"min_spend"`
+	PointsPercent float32 `json:"points_percent"`
+	IsBlocked     bool    `json:"is_blocked"`
+}
+
+type LoyaltyTransaction struct {
+	ID              uint       `json:"id"`
+	UserID          uint       `json:"user_id"`
+	OrderID         uint       `json:"order_id"`
+	Points          int        `json:"points"`
+	RemainingPoints int        `json:"remaining_points"`
+	Type            string     `json:"type"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type AppliedPoints struct {
+	ID     uint `json:"id"`
+	UserID uint `json:"user_id"`
+	Points int  `json:"points"`
+}
+
+type LoyaltyHistory struct {
+	Balance      int                  `json:"balance"`
+	PointValue   float32              `json:"point_value"`
+	Transactions []LoyaltyTransaction `json:"transactions"`
+}
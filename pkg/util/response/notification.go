@@ -0,0 +1,24 @@
+package response
+
+import "time"
+
+type NotificationPreference struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+}
+
+type Notification struct {
+	ID            uint       `json:"id"`
+	UserID        int        `json:"user_id"`
+	Event         string     `json:"event"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at"`
+}
@@ -16,6 +16,8 @@ type OrderLine struct {
 	OrderStatusID   int       `json:"order_status_id"`
 	Qty             int       `json:"qty"`
 	Price           float32   `json:"price"`
+	Discount        float32   `json:"discount"`
+	PointsRedeemed  int       `json:"points_redeemed"`
 	CouponID        uint      `json:"coupon_id"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
@@ -36,15 +38,17 @@ type Orders struct {
 }
 
 type Invoice struct {
-	Date            time.Time `json:"date"`
-	OrderDate       string    `json:"order_date"`
-	OrderID         int       `json:"order_id"`
-	DeliveryAddress string    `json:"delivery_address"`
-	ProductName     string    `json:"product_name"`
-	PaymentMethod   string    `json:"payment_method"`
-	ProductPrice    int       `json:"product_price"`
-	Discount        float32   `json:"discount"`
-	TotalAmount     float32   `json:"total_amount"`
+	Date            time.Time      `json:"date"`
+	OrderDate       string         `json:"order_date"`
+	OrderID         int            `json:"order_id"`
+	DeliveryAddress string         `json:"delivery_address"`
+	ProductName     string         `json:"product_name"`
+	PaymentMethod   string         `json:"payment_method"`
+	ProductPrice    float32        `json:"product_price"`
+	Qty             int            `json:"qty"`
+	Discount        float32        `json:"discount"`
+	TotalAmount     float32        `json:"total_amount"`
+	Payments        []OrderPayment `json:"payments"`
 }
 
 type MonthlySalesReport struct {
@@ -61,11 +65,19 @@ type MonthlySalesReport struct {
 }
 
 type Checkout struct {
-	Address        []Address       `json:"delivery_address"`
-	Cart           []Cart          `json:"items"`
-	Discount       float32         `json:"discount"`
-	Total          float32         `json:"total"`
-	PaymentOptions []PaymentMethod `json:"payment_options"`
+	Address        []Address          `json:"delivery_address"`
+	Cart           []Cart             `json:"items"`
+	Subtotal       float32            `json:"subtotal"`
+	ShippingFee    float32            `json:"shipping_fee"`
+	AppliedCoupons []CouponEvaluation `json:"applied_coupons"`
+	Discount       float32            `json:"discount"`
+	PointsApplied  int                `json:"points_applied"`
+	PointsValue    float32            `json:"points_value"`
+	Total          float32            `json:"total"`
+	WalletAmount   float32            `json:"wallet_amount"`
+	Payable        float32            `json:"payable"`
+	PaymentOptions []PaymentMethod    `json:"payment_options"`
+	Issues         []CartIssue        `json:"issues"`
 }
 
 type OrderManagement struct {
@@ -78,6 +90,17 @@ type PaymentMethod struct {
 	MethodName string `json:"method"`
 }
 
+type OrderPayment struct {
+	ID              uint      `json:"id"`
+	OrderLineID     uint      `json:"order_id"`
+	PaymentMethodID int       `json:"payment_method_id"`
+	PaymentMethod   string    `json:"payment_method"`
+	Amount          float32   `json:"amount"`
+	IsRefunded      bool      `json:"is_refunded"`
+	IsShipping      bool      `json:"is_shipping"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
 type OrderStatus struct {
 	ID     uint   `json:"id"`
 	Status string `json:"order_status"`
@@ -87,3 +110,8 @@ type TopSelling struct {
 	ProductID int
 	Quantity  int
 }
+
+type UserOrderStats struct {
+	OrderCount    int     `json:"order_count"`
+	LifetimeSpend float64 `json:"lifetime_spend"`
+}
@@ -3,5 +3,5 @@ package response
 type PaymentDetails struct {
 	Username        string `json:"username"`
 	RazorPayOrderID string `json:"razorpay_order_id"`
-	Amount          int    `json:"amount"`
+	Amount          int    `json:"amount"` // in paise, the amount of the razorpay order
 }
\ No newline at end of file
@@ -15,12 +15,17 @@ type Product struct {
 	CategoryID          int          `json:"category_id"`
 	ProductName         string       `json:"product_name"`
 	Price               int          `json:"price"`
+	MRP                 int          `json:"mrp,omitempty"`
+	SellingPrice        int          `json:"selling_price"`
+	Promotion           string       `json:"promotion,omitempty"`
 	SKU                 string       `json:"sku,omitempty"`
 	Brand               string       `json:"brand"`
 	Product_Description string       `json:"product_description,omitempty"`
 	Images              domain.JSONB `json:"images,omitempty"`
 	IsWishlisted        bool         `json:"is_wishlisted,omitempty"`
 	IsBlocked           bool         `json:"is_blocked,omitempty"`
+	Stock               int          `json:"stock"`
+	MaxPerOrder         int          `json:"max_per_order,omitempty"`
 }
 
 type ProductItem struct {
@@ -28,6 +33,9 @@ type ProductItem struct {
 	CategoryID          int          `json:"category_id"`
 	Product_Name        string       `json:"product_name"`
 	Price               int          `json:"price"`
+	MRP                 int          `json:"mrp"`
+	SellingPrice        int          `json:"selling_price"`
+	Promotion           string       `json:"promotion,omitempty"`
 	SKU                 string       `json:"sku"`
 	Brand               string       `json:"brand"`
 	Product_Description string       `json:"product_description"`
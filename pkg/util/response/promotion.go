@@ -0,0 +1,28 @@
+package response
+
+import "time"
+
+type Promotion struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	Type            string    `json:"type"`
+	CategoryID      int       `json:"category_id"`
+	ProductID       int       `json:"product_id"`
+	DiscountPercent float64   `json:"discount_percentage"`
+	StartsAt        time.Time `json:"starts_at"`
+	EndsAt          time.Time `json:"ends_at"`
+	IsActive        bool      `json:"is_active"`
+	IsBlocked       bool      `json:"is_blocked"`
+}
+
+type ScheduledPrice struct {
+	ID            int        `json:"id"`
+	ProductID     int        `json:"product_id"`
+	Price         int        `json:"price"`
+	MRP           int        `json:"mrp"`
+	StartsAt      time.Time  `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	Status        string     `json:"status"`
+	PreviousPrice int        `json:"previous_price"`
+	PreviousMRP   int        `json:"previous_mrp"`
+}
@@ -1,7 +1,43 @@
 package response
 
+import "time"
+
 type Referral struct {
-	ID     uint   `json:"id"`
-	UserID uint   `json:"user_id"`
-	Code   string `json:"refferal_code"`
+	ID       uint   `json:"id"`
+	UserID   uint   `json:"user_id"`
+	Code     string `json:"refferal_code"`
+	DeviceID string `json:"-"`
+}
+
+type ReferralProgramme struct {
+	ID             uint      `json:"id"`
+	ReferrerReward float32   `json:"referrer_reward"`
+	RefereeReward  float32   `json:"referee_reward"`
+	IsActive       bool      `json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ReferralClaim struct {
+	ID             uint       `json:"id"`
+	ReferralID     uint       `json:"referral_id"`
+	ReferrerID     uint       `json:"referrer_id"`
+	RefereeID      uint       `json:"referee_id"`
+	ReferrerReward float32    `json:"referrer_reward"`
+	RefereeReward  float32    `json:"referee_reward"`
+	Status         string     `json:"status"`
+	DeviceID       string     `json:"-"`
+	ClaimedAt      time.Time  `json:"claimed_at"`
+	ReleasedAt     *time.Time `json:"released_at"`
+}
+
+// ReferralStats is the summary shown to the owner of a referral code.
+type ReferralStats struct {
+	Code            string  `json:"refferal_code"`
+	TotalReferrals  int     `json:"total_referrals"`
+	Pending         int     `json:"pending"`
+	Released        int     `json:"released"`
+	TotalEarned     float32 `json:"total_earned"`
+	PendingEarnings float32 `json:"pending_earnings"`
+	ReferrerReward  float32 `json:"referrer_reward"`
+	RefereeReward   float32 `json:"referee_reward"`
 }
@@ -0,0 +1,46 @@
+package response
+
+import "time"
+
+type IdleCart struct {
+	UserID       int       `json:"user_id"`
+	CartValue    float32   `json:"cart_value"`
+	ItemCount    int       `json:"item_count"`
+	LastActivity time.Time `json:"last_activity"`
+}
+
+type AbandonedCart struct {
+	ID             uint       `json:"id"`
+	UserID         int        `json:"user_id"`
+	CartValue      float32    `json:"cart_value"`
+	ItemCount      int        `json:"item_count"`
+	LastActivity   time.Time  `json:"last_activity"`
+	RemindersSent  int        `json:"reminders_sent"`
+	Status         string     `json:"status"`
+	RecoveredValue float32    `json:"recovered_value"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+}
+
+type CartReminder struct {
+	ID              uint      `json:"id"`
+	AbandonedCartID uint      `json:"abandoned_cart_id"`
+	Step            int       `json:"step"`
+	Channel         string    `json:"channel"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error"`
+	SentAt          time.Time `json:"sent_at"`
+}
+
+type AbandonedCartReport struct {
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	AbandonedCarts int       `json:"abandoned_carts"`
+	AbandonedValue float32   `json:"abandoned_value"`
+	RecoveredCarts int       `json:"recovered_carts"`
+	RecoveredValue float32   `json:"recovered_value"`
+	LostCarts      int       `json:"lost_carts"`
+	OpenCarts      int       `json:"open_carts"`
+	RecoveryRate   float32   `json:"recovery_rate"` // percent of the abandoned carts recovered
+	RemindersSent  int       `json:"reminders_sent"`
+}
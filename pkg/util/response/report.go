@@ -0,0 +1,139 @@
+package response
+
+import "time"
+
+// SalesAnalytics is the sales of a period. An order is an order line, revenue is
+// net of the coupon discounts and the redeemed points, and the cancelled and
+// returned orders only count in the totals of the cancellations and returns.
+type SalesAnalytics struct {
+	From          time.Time           `json:"from"`
+	To            time.Time           `json:"to"`
+	Granularity   string              `json:"granularity"`
+	Summary       SalesSummary        `json:"summary"`
+	Series        []SalesPeriod       `json:"series"`
+	TopProducts   []SalesRank         `json:"top_products"`
+	TopCategories []SalesRank         `json:"top_categories"`
+	TopBrands     []SalesRank         `json:"top_brands"`
+	PaymentMix    []PaymentMix        `json:"payment_mix"`
+	Coupons       []CouponPerformance `json:"coupons"`
+	Customers     []CustomerSegment   `json:"customers"`
+}
+
+type SalesSummary struct {
+	PlacedOrders      int     `json:"placed_orders"`
+	GrossSales        float32 `json:"gross_sales"`
+	Orders            int     `json:"orders"` // placed orders not cancelled or returned
+	Units             int     `json:"units"`
+	Discounts         float32 `json:"discounts"`
+	PointsRedeemed    float32 `json:"points_redeemed"`
+	NetRevenue        float32 `json:"net_revenue"`
+	AverageOrderValue float32 `json:"average_order_value"`
+	CancelledOrders   int     `json:"cancelled_orders"`
+	CancelledValue    float32 `json:"cancelled_value"`
+	ReturnedOrders    int     `json:"returned_orders"`
+	ReturnedValue     float32 `json:"returned_value"`
+	Customers         int     `json:"customers"`
+}
+
+type SalesPeriod struct {
+	Period          time.Time `json:"period"`
+	Orders          int       `json:"orders"`
+	Units           int       `json:"units"`
+	GrossSales      float32   `json:"gross_sales"`
+	NetRevenue      float32   `json:"net_revenue"`
+	CancelledOrders int       `json:"cancelled_orders"`
+	ReturnedOrders  int       `json:"returned_orders"`
+}
+
+// SalesRank is a product, category or brand ranked by net revenue.
+type SalesRank struct {
+	Key        string  `json:"key"` // product or category id, brand name
+	Name       string  `json:"name"`
+	Orders     int     `json:"orders"`
+	Units      int     `json:"units"`
+	NetRevenue float32 `json:"net_revenue"`
+}
+
+type PaymentMix struct {
+	PaymentMethod string  `json:"payment_method"`
+	Orders        int     `json:"orders"`
+	Amount        float32 `json:"amount"`
+	Share         float32 `json:"share"` // percent of the amount paid
+}
+
+type CouponPerformance struct {
+	CouponID          int     `json:"coupon_id"`
+	Code              string  `json:"code"`
+	CouponName        string  `json:"coupon_name"`
+	Orders            int     `json:"orders"`
+	Customers         int     `json:"customers"`
+	Units             int     `json:"units"`
+	Discount          float32 `json:"discount"`
+	NetRevenue        float32 `json:"net_revenue"`
+	AverageOrderValue float32 `json:"average_order_value"`
+	RevenuePerRupee   float32 `json:"revenue_per_rupee"` // net revenue per rupee of discount
+}
+
+// CustomerSegment splits the customers of the period into the ones whose first
+// order is in the period (new) and the ones who ordered before (returning).
+type CustomerSegment struct {
+	Segment           string  `json:"segment"`
+	Customers         int     `json:"customers"`
+	Orders            int     `json:"orders"`
+	NetRevenue        float32 `json:"net_revenue"`
+	AverageOrderValue float32 `json:"average_order_value"`
+}
+
+// SalesLine is an order line of the sales export.
+type SalesLine struct {
+	OrderID        uint      `json:"order_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Status         string    `json:"status"`
+	UserID         uint      `json:"user_id"`
+	Customer       string    `json:"customer"`
+	ProductID      uint      `json:"product_id"`
+	ProductName    string    `json:"product_name"`
+	Brand          string    `json:"brand"`
+	Category       string    `json:"category"`
+	Qty            int       `json:"qty"`
+	Price          float32   `json:"price"`
+	GrossSales     float32   `json:"gross_sales"`
+	CouponCode     string    `json:"coupon_code"`
+	Discount       float32   `json:"discount"`
+	PointsRedeemed float32   `json:"points_redeemed"`
+	NetRevenue     float32   `json:"net_revenue"`
+	PaymentMethod  string    `json:"payment_method"`
+	State          string    `json:"state"`
+	Pincode        string    `json:"pincode"`
+	Lost           bool      `json:"lost"` // cancelled or returned
+}
+
+// TaxSummary is the tax collected on the orders shipped to a state.
+type TaxSummary struct {
+	State        string  `json:"state"`
+	Orders       int     `json:"orders"`
+	NetRevenue   float32 `json:"net_revenue"` // the prices include the tax
+	TaxableValue float32 `json:"taxable_value"`
+	Tax          float32 `json:"tax"`
+}
+
+// RefundLine is a refunded payment of a cancelled or returned order line.
+type RefundLine struct {
+	PaymentID     uint      `json:"payment_id"`
+	OrderID       uint      `json:"order_id"`
+	RefundedAt    time.Time `json:"refunded_at"` // the order line update that cancelled or returned it
+	Status        string    `json:"status"`
+	UserID        uint      `json:"user_id"`
+	Customer      string    `json:"customer"`
+	ProductName   string    `json:"product_name"`
+	PaymentMethod string    `json:"payment_method"`
+	Amount        float32   `json:"amount"`
+}
+
+// RefundSummary sums up the refunds per order status and payment method.
+type RefundSummary struct {
+	Status        string  `json:"status"`
+	PaymentMethod string  `json:"payment_method"`
+	Refunds       int     `json:"refunds"`
+	Amount        float32 `json:"amount"`
+}
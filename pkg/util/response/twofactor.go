@@ -0,0 +1,44 @@
+package response
+
+import "time"
+
+type TwoFactor struct {
+	ID           int        `json:"-"`
+	Subject      string     `json:"-"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	CreatedAt    time.Time  `json:"-"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrolment is shown once to add the account to the authenticator app,
+// by scanning the provisioning uri as a QR code or typing the secret.
+type TwoFactorEnrolment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorChallenge is the answer of a login that needs the totp code, the
+// enrolment is set when the account must enrol first.
+type TwoFactorChallenge struct {
+	ChallengeToken string              `json:"challenge_token"`
+	Enrolment      *TwoFactorEnrolment `json:"enrolment,omitempty"`
+}
+
+// TwoFactorLogin is the answer of the second login step, the recovery codes are set
+// when the step completed an enrolment.
+type TwoFactorLogin struct {
+	Token         string   `json:"token"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
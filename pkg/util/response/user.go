@@ -3,14 +3,21 @@ package response
 import "time"
 
 type UserData struct {
-	ID        int       `json:"user_id"`
-	UserName  string    `json:"user_name"`
-	Email     string    `json:"email"`
-	Phone     int       `json:"phone"`
-	Password  string    `json:"-"`
-	IsAdmin   bool      `json:"-"`
-	IsBlocked bool      `json:"is_blocked"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            int       `json:"user_id"`
+	UserName      string    `json:"user_name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Phone         int       `json:"phone"`
+	Password      string    `json:"-"`
+	IsAdmin       bool      `json:"-"`
+	IsBlocked     bool      `json:"is_blocked"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PasswordResetChallenge is sent back when the otp of a reset goes to a phone,
+// the reset needs the challenge token and the otp.
+type PasswordResetChallenge struct {
+	ChallengeToken string `json:"challenge_token,omitempty"`
 }
 
 type Address struct {
@@ -15,3 +15,9 @@ type WalletTransactionHistory struct {
 	Amount          float32   `json:"amount"`
 	TransactionType string    `json:"transaction_type"` // "credit" or "debit"
 }
+
+type AppliedWallet struct {
+	ID     uint    `json:"id"`
+	UserID uint    `json:"user_id"`
+	Amount float32 `json:"amount"`
+}